@@ -0,0 +1,83 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/service"
+)
+
+type CostsController struct {
+	*AppContext
+}
+
+// GetCostReport reports the estimated cost of the resources requested by all projects
+func (c *CostsController) GetCostReport(r *http.Request, _ map[string]string, _ interface{}) *ApiResponse {
+	query, err := parseCostReportQuery(r)
+	if err != nil {
+		return BadRequest(err.Error())
+	}
+	return c.getCostReport(r, query)
+}
+
+// GetProjectCostReport reports the estimated cost of the resources requested by a project
+func (c *CostsController) GetProjectCostReport(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	query, err := parseCostReportQuery(r)
+	if err != nil {
+		return BadRequest(err.Error())
+	}
+
+	projectId, _ := models.ParseId(vars["project_id"])
+	project, err := c.ProjectsService.GetByID(r.Context(), int32(projectId))
+	if err != nil {
+		return NotFound(err.Error())
+	}
+
+	query.ProjectId = models.Id(project.Id)
+	return c.getCostReport(r, query)
+}
+
+func (c *CostsController) getCostReport(r *http.Request, query *service.CostReportQuery) *ApiResponse {
+	report, err := c.CostService.GetCostReport(r.Context(), query)
+	if err != nil {
+		log.Errorf("Error estimating costs, reason: %v", err)
+		return InternalServerError("Error while estimating costs")
+	}
+	return Ok(report)
+}
+
+func parseCostReportQuery(r *http.Request) (*service.CostReportQuery, error) {
+	// group_by is a comma separated list which is parsed separately
+	values := r.URL.Query()
+	groupBy, err := models.ParseCostGroupBy(values.Get("group_by"))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid group_by: %s", err)
+	}
+	values.Del("group_by")
+
+	query := service.CostReportQuery{GroupBy: groupBy}
+	if err := decoder.Decode(&query, values); err != nil {
+		return nil, fmt.Errorf("Unable to parse query string: %s", err)
+	}
+
+	if !query.Start.IsZero() && !query.End.IsZero() && !query.End.After(query.Start) {
+		return nil, fmt.Errorf("End of the time range must be after its start")
+	}
+	return &query, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gojek/merlin/mlp"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/service"
+	"github.com/gojek/merlin/service/mocks"
+)
+
+func TestGetCostReport(t *testing.T) {
+	report := &models.CostReport{
+		Items: []*models.CostItem{
+			{Team: "team-1", ResourceUsage: models.ResourceUsage{CpuHours: 24, MemoryGbHours: 48}, Cost: 3.6},
+		},
+		TotalCost: 3.6,
+	}
+
+	testCases := []struct {
+		desc      string
+		url       string
+		wantQuery *service.CostReportQuery
+		reportErr error
+		expected  *ApiResponse
+	}{
+		{
+			desc: "Should success get cost report",
+			url:  "/costs?group_by=team,environment&start=2020-10-01T00:00:00Z&end=2020-10-02T00:00:00Z",
+			wantQuery: &service.CostReportQuery{
+				GroupBy: []string{models.CostGroupByTeam, models.CostGroupByEnvironment},
+				Start:   time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+				End:     time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC),
+			},
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: report,
+			},
+		},
+		{
+			desc: "Should group by all dimensions by default",
+			url:  "/costs",
+			wantQuery: &service.CostReportQuery{
+				GroupBy: []string{models.CostGroupByProject, models.CostGroupByTeam, models.CostGroupByModel, models.CostGroupByEnvironment},
+			},
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: report,
+			},
+		},
+		{
+			desc: "Should return 400 if group by dimension is unknown",
+			url:  "/costs?group_by=stream",
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid group_by: unknown dimension stream, it must be one of project, team, model, environment"},
+			},
+		},
+		{
+			desc: "Should return 400 if time range is reversed",
+			url:  "/costs?start=2020-10-02T00:00:00Z&end=2020-10-01T00:00:00Z",
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "End of the time range must be after its start"},
+			},
+		},
+		{
+			desc: "Should return 500 if estimating costs failed",
+			url:  "/costs",
+			wantQuery: &service.CostReportQuery{
+				GroupBy: []string{models.CostGroupByProject, models.CostGroupByTeam, models.CostGroupByModel, models.CostGroupByEnvironment},
+			},
+			reportErr: fmt.Errorf("db is down"),
+			expected: &ApiResponse{
+				code: http.StatusInternalServerError,
+				data: Error{Message: "Error while estimating costs"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			costSvc := &mocks.CostService{}
+			if tC.reportErr != nil {
+				costSvc.On("GetCostReport", mock.Anything, tC.wantQuery).Return(nil, tC.reportErr)
+			} else {
+				costSvc.On("GetCostReport", mock.Anything, tC.wantQuery).Return(report, nil)
+			}
+
+			ctl := &CostsController{
+				AppContext: &AppContext{
+					CostService: costSvc,
+				},
+			}
+			resp := ctl.GetCostReport(httptest.NewRequest(http.MethodGet, tC.url, nil), map[string]string{}, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
+
+func TestGetProjectCostReport(t *testing.T) {
+	report := &models.CostReport{Items: []*models.CostItem{}}
+
+	testCases := []struct {
+		desc        string
+		findProject error
+		expected    *ApiResponse
+	}{
+		{
+			desc: "Should success get cost report of the project",
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: report,
+			},
+		},
+		{
+			desc:        "Should return 404 if project is not found",
+			findProject: fmt.Errorf("project not found"),
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "project not found"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			projectSvc := &mocks.ProjectsService{}
+			projectSvc.On("GetByID", mock.Anything, int32(1)).Return(mlp.Project{Id: 1, Name: "project"}, tC.findProject)
+			costSvc := &mocks.CostService{}
+			costSvc.On("GetCostReport", mock.Anything, &service.CostReportQuery{
+				ProjectId: 1,
+				GroupBy:   []string{models.CostGroupByModel},
+			}).Return(report, nil)
+
+			ctl := &CostsController{
+				AppContext: &AppContext{
+					ProjectsService: projectSvc,
+					CostService:     costSvc,
+				},
+			}
+			resp := ctl.GetProjectCostReport(httptest.NewRequest(http.MethodGet, "/projects/1/costs?group_by=model", nil), map[string]string{"project_id": "1"}, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/service"
+)
+
+func init() {
+	// time range of the queries are RFC 3339 timestamps, e.g. 2020-10-01T00:00:00Z
+	decoder.RegisterConverter(time.Time{}, func(value string) reflect.Value {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return reflect.Value{}
+		}
+		return reflect.ValueOf(t)
+	})
+}
+
+type DeploymentsController struct {
+	*AppContext
+}
+
+// ListModelDeployments lists the deployments of the version endpoints of a model
+func (c *DeploymentsController) ListModelDeployments(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	query, err := parseListDeploymentQuery(r)
+	if err != nil {
+		return BadRequest(err.Error())
+	}
+
+	modelId, _ := models.ParseId(vars["model_id"])
+	model, err := c.ModelsService.FindById(ctx, modelId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Model with given `model_id: %d` not found", modelId))
+		}
+		return InternalServerError(fmt.Sprintf("Error while getting model with id %d", modelId))
+	}
+
+	query.ModelId = model.Id
+	return c.listDeployments(query)
+}
+
+// ListProjectDeployments lists the deployments of the version endpoints of all models in a project
+func (c *DeploymentsController) ListProjectDeployments(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	query, err := parseListDeploymentQuery(r)
+	if err != nil {
+		return BadRequest(err.Error())
+	}
+
+	projectId, _ := models.ParseId(vars["project_id"])
+	project, err := c.ProjectsService.GetByID(ctx, int32(projectId))
+	if err != nil {
+		return NotFound(err.Error())
+	}
+
+	query.ProjectId = models.Id(project.Id)
+	return c.listDeployments(query)
+}
+
+func (c *DeploymentsController) listDeployments(query *service.ListDeploymentQuery) *ApiResponse {
+	history, err := c.DeploymentService.ListDeployments(query)
+	if err != nil {
+		log.Errorf("Error listing deployments, reason: %v", err)
+		return InternalServerError("Error while listing deployments")
+	}
+	return Ok(history)
+}
+
+func parseListDeploymentQuery(r *http.Request) (*service.ListDeploymentQuery, error) {
+	var query service.ListDeploymentQuery
+	if err := decoder.Decode(&query, r.URL.Query()); err != nil {
+		return nil, fmt.Errorf("Unable to parse query string: %s", err)
+	}
+
+	if !query.Start.IsZero() && !query.End.IsZero() && !query.End.After(query.Start) {
+		return nil, fmt.Errorf("End of the time range must be after its start")
+	}
+	if query.Limit < 0 || query.Limit > service.MaxDeploymentsLimit {
+		return nil, fmt.Errorf("Limit must be between 0 and %d", service.MaxDeploymentsLimit)
+	}
+	if query.Offset < 0 {
+		return nil, fmt.Errorf("Offset must not be negative")
+	}
+	return &query, nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gojek/merlin/mlp"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/service"
+	"github.com/gojek/merlin/service/mocks"
+)
+
+func TestListModelDeployments(t *testing.T) {
+	history := &models.DeploymentHistory{
+		Deployments: []*models.Deployment{
+			{
+				Id:              2,
+				VersionModelId:  1,
+				VersionId:       2,
+				EnvironmentName: "staging",
+				Status:          models.EndpointFailed,
+				Error:           "timeout",
+				DeployedBy:      "user@example.com",
+				DurationSeconds: 600,
+			},
+		},
+		Stats: &models.DeploymentStats{Total: 1, Failed: 1},
+	}
+
+	testCases := []struct {
+		desc      string
+		url       string
+		findModel error
+		wantQuery *service.ListDeploymentQuery
+		expected  *ApiResponse
+	}{
+		{
+			desc: "Should success list deployments with filters",
+			url:  "/models/1/deployments?version_id=2&environment_name=staging&status=failed&start=2020-10-01T00:00:00Z&end=2020-10-02T00:00:00Z",
+			wantQuery: &service.ListDeploymentQuery{
+				ModelId:         1,
+				VersionId:       2,
+				EnvironmentName: "staging",
+				Status:          models.EndpointFailed,
+				Start:           time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+				End:             time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC),
+			},
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: history,
+			},
+		},
+		{
+			desc: "Should success list a page of deployments",
+			url:  "/models/1/deployments?limit=10&offset=20",
+			wantQuery: &service.ListDeploymentQuery{
+				ModelId: 1,
+				Limit:   10,
+				Offset:  20,
+			},
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: history,
+			},
+		},
+		{
+			desc: "Should return 400 if limit is too large",
+			url:  "/models/1/deployments?limit=1001",
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Limit must be between 0 and 1000"},
+			},
+		},
+		{
+			desc: "Should return 400 if offset is negative",
+			url:  "/models/1/deployments?offset=-1",
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Offset must not be negative"},
+			},
+		},
+		{
+			desc: "Should return 400 if time range is not a timestamp",
+			url:  "/models/1/deployments?start=yesterday",
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Unable to parse query string: schema: error converting value for \"start\""},
+			},
+		},
+		{
+			desc: "Should return 400 if time range is reversed",
+			url:  "/models/1/deployments?start=2020-10-02T00:00:00Z&end=2020-10-01T00:00:00Z",
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "End of the time range must be after its start"},
+			},
+		},
+		{
+			desc:      "Should return 404 if model is not found",
+			url:       "/models/1/deployments",
+			findModel: gorm.ErrRecordNotFound,
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "Model with given `model_id: 1` not found"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			modelSvc := &mocks.ModelsService{}
+			if tC.findModel != nil {
+				modelSvc.On("FindById", mock.Anything, models.Id(1)).Return(nil, tC.findModel)
+			} else {
+				modelSvc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{Id: models.Id(1), Name: "model-1"}, nil)
+			}
+			deploymentSvc := &mocks.DeploymentService{}
+			deploymentSvc.On("ListDeployments", mock.Anything).Return(history, nil)
+
+			ctl := &DeploymentsController{
+				AppContext: &AppContext{
+					ModelsService:     modelSvc,
+					DeploymentService: deploymentSvc,
+				},
+			}
+			resp := ctl.ListModelDeployments(httptest.NewRequest(http.MethodGet, tC.url, nil), map[string]string{"model_id": "1"}, nil)
+			assert.Equal(t, tC.expected, resp)
+
+			if tC.wantQuery != nil {
+				deploymentSvc.AssertCalled(t, "ListDeployments", tC.wantQuery)
+			} else {
+				deploymentSvc.AssertNotCalled(t, "ListDeployments", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestListProjectDeployments(t *testing.T) {
+	history := &models.DeploymentHistory{
+		Deployments: []*models.Deployment{},
+		Stats:       &models.DeploymentStats{},
+	}
+
+	testCases := []struct {
+		desc        string
+		findProject error
+		listErr     error
+		expected    *ApiResponse
+	}{
+		{
+			desc: "Should success list deployments",
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: history,
+			},
+		},
+		{
+			desc:        "Should return 404 if project is not found",
+			findProject: fmt.Errorf("project not found"),
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "project not found"},
+			},
+		},
+		{
+			desc:    "Should return 500 if listing deployments failed",
+			listErr: fmt.Errorf("db is down"),
+			expected: &ApiResponse{
+				code: http.StatusInternalServerError,
+				data: Error{Message: "Error while listing deployments"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			projectSvc := &mocks.ProjectsService{}
+			projectSvc.On("GetByID", mock.Anything, int32(1)).Return(mlp.Project{Id: 1, Name: "project"}, tC.findProject)
+			deploymentSvc := &mocks.DeploymentService{}
+			if tC.listErr != nil {
+				deploymentSvc.On("ListDeployments", &service.ListDeploymentQuery{ProjectId: 1}).Return(nil, tC.listErr)
+			} else {
+				deploymentSvc.On("ListDeployments", &service.ListDeploymentQuery{ProjectId: 1}).Return(history, nil)
+			}
+
+			ctl := &DeploymentsController{
+				AppContext: &AppContext{
+					ProjectsService:   projectSvc,
+					DeploymentService: deploymentSvc,
+				},
+			}
+			resp := ctl.ListProjectDeployments(httptest.NewRequest(http.MethodGet, "/projects/1/deployments", nil), map[string]string{"project_id": "1"}, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/metrics"
+	"github.com/gojek/merlin/models"
+)
+
+const (
+	defaultLatencyPercentile = 99
+)
+
+// ModelEndpointMetricsController exposes live SLIs of model endpoints
+type ModelEndpointMetricsController struct {
+	*AppContext
+}
+
+// ModelEndpointMetrics contains SLIs of a model endpoint and of each of its destinations
+type ModelEndpointMetrics struct {
+	ModelEndpointId models.Id                 `json:"model_endpoint_id"`
+	Window          string                    `json:"window"`
+	SLIs            []metrics.SLI             `json:"slis"`
+	Destinations    []*VersionEndpointMetrics `json:"destinations"`
+}
+
+// VersionEndpointMetrics contains SLIs of a version endpoint
+type VersionEndpointMetrics struct {
+	VersionEndpointId uuid.UUID     `json:"version_endpoint_id"`
+	SLIs              []metrics.SLI `json:"slis"`
+}
+
+// GetModelEndpointMetrics evaluates throughput, latency, error rate, CPU and memory SLIs of a model endpoint.
+// Optional query parameters: `window` (e.g. 5m) and `percentile` of the latency SLI (e.g. 99).
+func (c *ModelEndpointMetricsController) GetModelEndpointMetrics(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	modelId, _ := models.ParseId(vars["model_id"])
+	modelEndpointId, _ := models.ParseId(vars["model_endpoint_id"])
+
+	window := metrics.DefaultWindow
+	if vars["window"] != "" {
+		var err error
+		window, err = time.ParseDuration(vars["window"])
+		if err != nil || window < time.Minute {
+			return BadRequest(fmt.Sprintf("Invalid window %s, it must be a duration of at least 1m", vars["window"]))
+		}
+	}
+
+	percentile := float64(defaultLatencyPercentile)
+	if vars["percentile"] != "" {
+		var err error
+		percentile, err = strconv.ParseFloat(vars["percentile"], 64)
+		if err != nil || percentile <= 0 || percentile > 100 {
+			return BadRequest(fmt.Sprintf("Invalid percentile %s, it must be between 0 and 100", vars["percentile"]))
+		}
+	}
+
+	model, err := c.ModelsService.FindById(ctx, modelId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Model ID %s not found", modelId))
+		}
+		return InternalServerError(fmt.Sprintf("Error while getting Model ID %s", modelId))
+	}
+
+	modelEndpoint, err := c.ModelEndpointsService.FindById(ctx, modelEndpointId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Model endpoint with id %s not found", modelEndpointId))
+		}
+		return InternalServerError(fmt.Sprintf("Error while getting model endpoint with id %s", modelEndpointId))
+	}
+
+	if modelEndpoint.ModelId != model.Id {
+		return NotFound(fmt.Sprintf("Model endpoint with id %s not found", modelEndpointId))
+	}
+
+	queries := metrics.DefaultQueries(percentile, window)
+
+	slis, err := metrics.Evaluate(queries, func(query metrics.Query) (float64, error) {
+		return c.MetricsProvider.ModelEndpointSLI(ctx, model, modelEndpoint, query)
+	})
+	if err != nil {
+		log.Errorf("Error evaluating metrics of model endpoint %s, reason: %v", modelEndpointId, err)
+		return InternalServerError(fmt.Sprintf("Error while evaluating metrics of model endpoint %s: %s", modelEndpointId, err))
+	}
+
+	result := &ModelEndpointMetrics{
+		ModelEndpointId: modelEndpoint.Id,
+		Window:          window.String(),
+		SLIs:            slis,
+		Destinations:    []*VersionEndpointMetrics{},
+	}
+
+	if modelEndpoint.Rule != nil {
+		for _, dest := range modelEndpoint.Rule.AllDestinations() {
+			versionEndpoint, err := c.EndpointsService.FindById(dest.VersionEndpointID)
+			if err != nil {
+				return InternalServerError(fmt.Sprintf("Error while getting version endpoint %s", dest.VersionEndpointID))
+			}
+
+			slis, err := metrics.Evaluate(queries, func(query metrics.Query) (float64, error) {
+				return c.MetricsProvider.VersionEndpointSLI(ctx, model, versionEndpoint, query)
+			})
+			if err != nil {
+				log.Errorf("Error evaluating metrics of version endpoint %s, reason: %v", versionEndpoint.Id, err)
+				return InternalServerError(fmt.Sprintf("Error while evaluating metrics of version endpoint %s: %s", versionEndpoint.Id, err))
+			}
+
+			result.Destinations = append(result.Destinations, &VersionEndpointMetrics{
+				VersionEndpointId: versionEndpoint.Id,
+				SLIs:              slis,
+			})
+		}
+	}
+
+	return Ok(result)
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gojek/merlin/metrics"
+	metricsMock "github.com/gojek/merlin/metrics/mocks"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/service/mocks"
+)
+
+func TestGetModelEndpointMetrics(t *testing.T) {
+	versionEndpoint := &models.VersionEndpoint{Id: uuid.New(), InferenceServiceName: "model-1-1"}
+	model := &models.Model{Id: 1, Name: "model-1"}
+	modelEndpoint := &models.ModelEndpoint{
+		Id:      1,
+		ModelId: 1,
+		Rule: &models.ModelEndpointRule{
+			Destination: []*models.ModelEndpointRuleDestination{{VersionEndpointID: versionEndpoint.Id, Weight: 100}},
+		},
+	}
+
+	one := 1.0
+	slis := func(window time.Duration, percentile float64, value *float64) []metrics.SLI {
+		var result []metrics.SLI
+		for _, query := range metrics.DefaultQueries(percentile, window) {
+			result = append(result, metrics.SLI{Query: query, Value: value})
+		}
+		return result
+	}
+
+	testCases := []struct {
+		desc     string
+		vars     map[string]string
+		provider func() *metricsMock.Provider
+		expected *ApiResponse
+	}{
+		{
+			desc: "Should success get metrics with default window and percentile",
+			vars: map[string]string{"model_id": "1", "model_endpoint_id": "1"},
+			provider: func() *metricsMock.Provider {
+				provider := &metricsMock.Provider{}
+				provider.On("ModelEndpointSLI", mock.Anything, model, modelEndpoint, mock.Anything).Return(1.0, nil)
+				provider.On("VersionEndpointSLI", mock.Anything, model, versionEndpoint, mock.Anything).Return(0.0, metrics.ErrNoData)
+				return provider
+			},
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: &ModelEndpointMetrics{
+					ModelEndpointId: 1,
+					Window:          "5m0s",
+					SLIs:            slis(5*time.Minute, 99, &one),
+					Destinations: []*VersionEndpointMetrics{
+						{VersionEndpointId: versionEndpoint.Id, SLIs: slis(5*time.Minute, 99, nil)},
+					},
+				},
+			},
+		},
+		{
+			desc: "Should return 400 if window is invalid",
+			vars: map[string]string{"model_id": "1", "model_endpoint_id": "1", "window": "5s"},
+			provider: func() *metricsMock.Provider {
+				return &metricsMock.Provider{}
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid window 5s, it must be a duration of at least 1m"},
+			},
+		},
+		{
+			desc: "Should return 400 if percentile is invalid",
+			vars: map[string]string{"model_id": "1", "model_endpoint_id": "1", "percentile": "120"},
+			provider: func() *metricsMock.Provider {
+				return &metricsMock.Provider{}
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid percentile 120, it must be between 0 and 100"},
+			},
+		},
+		{
+			desc: "Should return 500 if prometheus is unavailable",
+			vars: map[string]string{"model_id": "1", "model_endpoint_id": "1", "window": "10m", "percentile": "95"},
+			provider: func() *metricsMock.Provider {
+				provider := &metricsMock.Provider{}
+				provider.On("ModelEndpointSLI", mock.Anything, model, modelEndpoint, mock.Anything).Return(0.0, fmt.Errorf("connection refused"))
+				return provider
+			},
+			expected: &ApiResponse{
+				code: http.StatusInternalServerError,
+				data: Error{Message: "Error while evaluating metrics of model endpoint 1: unable to evaluate throughput: connection refused"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			modelsService := &mocks.ModelsService{}
+			modelsService.On("FindById", mock.Anything, models.Id(1)).Return(model, nil)
+
+			modelEndpointsService := &mocks.ModelEndpointsService{}
+			modelEndpointsService.On("FindById", mock.Anything, models.Id(1)).Return(modelEndpoint, nil)
+
+			endpointsService := &mocks.EndpointsService{}
+			endpointsService.On("FindById", versionEndpoint.Id).Return(versionEndpoint, nil)
+
+			ctl := &ModelEndpointMetricsController{
+				AppContext: &AppContext{
+					ModelsService:         modelsService,
+					ModelEndpointsService: modelEndpointsService,
+					EndpointsService:      endpointsService,
+					MetricsProvider:       tC.provider(),
+				},
+			}
+			resp := ctl.GetModelEndpointMetrics(&http.Request{}, tC.vars, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/models"
+)
+
+// ModelEndpointRolloutsController controls canary rollouts of model endpoints
+type ModelEndpointRolloutsController struct {
+	*AppContext
+}
+
+// ListRollouts list all rollouts of a model endpoint, latest first
+func (c *ModelEndpointRolloutsController) ListRollouts(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	modelId, _ := models.ParseId(vars["model_id"])
+	modelEndpointId, _ := models.ParseId(vars["model_endpoint_id"])
+
+	modelEndpoint, err := c.ModelEndpointsService.FindById(ctx, modelEndpointId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Model endpoint with id %s not found", modelEndpointId))
+		}
+		return InternalServerError(fmt.Sprintf("Error while getting model endpoint with id %s", modelEndpointId))
+	}
+
+	if modelEndpoint.ModelId != modelId {
+		return NotFound(fmt.Sprintf("Model endpoint with id %s not found", modelEndpointId))
+	}
+
+	rollouts, err := c.ModelEndpointRolloutService.ListRollouts(ctx, modelEndpointId)
+	if err != nil {
+		log.Errorf("Error listing rollouts of model endpoint %s, reason: %v", modelEndpointId, err)
+		return InternalServerError(fmt.Sprintf("Error while getting rollouts of model endpoint %s", modelEndpointId))
+	}
+
+	return Ok(rollouts)
+}
+
+// GetRollout get rollout of a model endpoint given an ID
+func (c *ModelEndpointRolloutsController) GetRollout(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	modelId, _ := models.ParseId(vars["model_id"])
+	modelEndpointId, _ := models.ParseId(vars["model_endpoint_id"])
+	rolloutId, _ := models.ParseId(vars["rollout_id"])
+
+	rollout, err := c.ModelEndpointRolloutService.FindById(ctx, rolloutId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Rollout with id %s not found", rolloutId))
+		}
+		return InternalServerError(fmt.Sprintf("Error while getting rollout with id %s", rolloutId))
+	}
+
+	if rollout.ModelId != modelId || rollout.ModelEndpointId != modelEndpointId {
+		return NotFound(fmt.Sprintf("Rollout with id %s not found", rolloutId))
+	}
+
+	return Ok(rollout)
+}
+
+// CreateRollout starts a canary rollout which gradually shifts traffic of a model endpoint to a version endpoint.
+// The rollout is promoted once the last step is reached or rolled back as soon as one of its gates fails.
+func (c *ModelEndpointRolloutsController) CreateRollout(r *http.Request, vars map[string]string, body interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	modelId, _ := models.ParseId(vars["model_id"])
+	modelEndpointId, _ := models.ParseId(vars["model_endpoint_id"])
+
+	model, err := c.ModelsService.FindById(ctx, modelId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Model ID %s not found", modelId))
+		}
+		return InternalServerError(fmt.Sprintf("Error while getting Model ID %s", modelId))
+	}
+
+	modelEndpoint, err := c.ModelEndpointsService.FindById(ctx, modelEndpointId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Model endpoint with id %s not found", modelEndpointId))
+		}
+		return InternalServerError(fmt.Sprintf("Error while getting model endpoint with id %s", modelEndpointId))
+	}
+
+	if modelEndpoint.ModelId != model.Id {
+		return NotFound(fmt.Sprintf("Model endpoint with id %s not found", modelEndpointId))
+	}
+
+	rollout, ok := body.(*models.ModelEndpointRollout)
+	if !ok {
+		return BadRequest("Invalid request body")
+	}
+
+	rollout, err = c.ModelEndpointRolloutService.StartRollout(ctx, model, modelEndpoint, rollout)
+	if err != nil {
+		var invalidErr *models.InvalidRolloutError
+		if errors.As(err, &invalidErr) {
+			return BadRequest(fmt.Sprintf("Invalid rollout: %s", invalidErr.Error()))
+		}
+		return InternalServerError(fmt.Sprintf("Unable to start rollout: %s", err))
+	}
+
+	return Created(rollout)
+}
+
+// RollbackRollout stops a progressing rollout and restores the model endpoint's previous traffic rule
+func (c *ModelEndpointRolloutsController) RollbackRollout(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	modelId, _ := models.ParseId(vars["model_id"])
+	modelEndpointId, _ := models.ParseId(vars["model_endpoint_id"])
+	rolloutId, _ := models.ParseId(vars["rollout_id"])
+
+	model, err := c.ModelsService.FindById(ctx, modelId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Model ID %s not found", modelId))
+		}
+		return InternalServerError(fmt.Sprintf("Error while getting Model ID %s", modelId))
+	}
+
+	rollout, err := c.ModelEndpointRolloutService.FindById(ctx, rolloutId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Rollout with id %s not found", rolloutId))
+		}
+		return InternalServerError(fmt.Sprintf("Error while getting rollout with id %s", rolloutId))
+	}
+
+	if rollout.ModelId != model.Id || rollout.ModelEndpointId != modelEndpointId {
+		return NotFound(fmt.Sprintf("Rollout with id %s not found", rolloutId))
+	}
+
+	if rollout.Status.IsTerminal() {
+		return BadRequest(fmt.Sprintf("Rollout %s is already %s", rolloutId, rollout.Status))
+	}
+
+	reason := "rolled back manually"
+	if vars["user"] != "" {
+		reason = fmt.Sprintf("rolled back manually by %s", vars["user"])
+	}
+
+	rollout, err = c.ModelEndpointRolloutService.RollbackRollout(ctx, model, rollout, reason)
+	if err != nil {
+		var invalidErr *models.InvalidRolloutError
+		if errors.As(err, &invalidErr) {
+			return BadRequest(fmt.Sprintf("Unable to rollback rollout: %s", invalidErr.Error()))
+		}
+		return InternalServerError(fmt.Sprintf("Unable to rollback rollout: %s", err))
+	}
+
+	return Ok(rollout)
+}
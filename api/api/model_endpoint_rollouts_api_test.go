@@ -0,0 +1,316 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/service/mocks"
+)
+
+func TestListModelEndpointRollouts(t *testing.T) {
+	rollouts := []*models.ModelEndpointRollout{{Id: 1, ModelId: 1, ModelEndpointId: 1}}
+
+	testCases := []struct {
+		desc     string
+		vars     map[string]string
+		expected *ApiResponse
+	}{
+		{
+			desc: "Should success list rollouts",
+			vars: map[string]string{"model_id": "1", "model_endpoint_id": "1"},
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: rollouts,
+			},
+		},
+		{
+			desc: "Should return 404 if model endpoint belongs to another model",
+			vars: map[string]string{"model_id": "2", "model_endpoint_id": "1"},
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "Model endpoint with id 1 not found"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			modelEndpointsService := &mocks.ModelEndpointsService{}
+			modelEndpointsService.On("FindById", mock.Anything, models.Id(1)).Return(&models.ModelEndpoint{Id: 1, ModelId: 1}, nil)
+
+			rolloutService := &mocks.ModelEndpointRolloutService{}
+			rolloutService.On("ListRollouts", mock.Anything, models.Id(1)).Return(rollouts, nil)
+
+			ctl := &ModelEndpointRolloutsController{
+				AppContext: &AppContext{
+					ModelEndpointsService:       modelEndpointsService,
+					ModelEndpointRolloutService: rolloutService,
+				},
+			}
+			resp := ctl.ListRollouts(&http.Request{}, tC.vars, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
+
+func TestGetModelEndpointRollout(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		vars     map[string]string
+		service  func() *mocks.ModelEndpointRolloutService
+		expected *ApiResponse
+	}{
+		{
+			desc: "Should success get rollout",
+			vars: map[string]string{"model_id": "1", "model_endpoint_id": "1", "rollout_id": "1"},
+			service: func() *mocks.ModelEndpointRolloutService {
+				svc := &mocks.ModelEndpointRolloutService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.ModelEndpointRollout{Id: 1, ModelId: 1, ModelEndpointId: 1}, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: &models.ModelEndpointRollout{Id: 1, ModelId: 1, ModelEndpointId: 1},
+			},
+		},
+		{
+			desc: "Should return 404 if rollout belongs to another model",
+			vars: map[string]string{"model_id": "2", "model_endpoint_id": "1", "rollout_id": "1"},
+			service: func() *mocks.ModelEndpointRolloutService {
+				svc := &mocks.ModelEndpointRolloutService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.ModelEndpointRollout{Id: 1, ModelId: 1, ModelEndpointId: 1}, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "Rollout with id 1 not found"},
+			},
+		},
+		{
+			desc: "Should return 404 if rollout belongs to another model endpoint",
+			vars: map[string]string{"model_id": "1", "model_endpoint_id": "2", "rollout_id": "1"},
+			service: func() *mocks.ModelEndpointRolloutService {
+				svc := &mocks.ModelEndpointRolloutService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.ModelEndpointRollout{Id: 1, ModelId: 1, ModelEndpointId: 1}, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "Rollout with id 1 not found"},
+			},
+		},
+		{
+			desc: "Should return 404 if rollout not found",
+			vars: map[string]string{"model_id": "1", "model_endpoint_id": "1", "rollout_id": "1"},
+			service: func() *mocks.ModelEndpointRolloutService {
+				svc := &mocks.ModelEndpointRolloutService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(nil, gorm.ErrRecordNotFound)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "Rollout with id 1 not found"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			ctl := &ModelEndpointRolloutsController{
+				AppContext: &AppContext{
+					ModelEndpointRolloutService: tC.service(),
+				},
+			}
+			resp := ctl.GetRollout(&http.Request{}, tC.vars, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
+
+func TestCreateModelEndpointRollout(t *testing.T) {
+	versionEndpointId := uuid.New()
+	model := &models.Model{Id: 1, Name: "model-1"}
+	servingEndpoint := &models.ModelEndpoint{Id: 1, ModelId: 1, Status: models.EndpointServing}
+
+	testCases := []struct {
+		desc           string
+		body           *models.ModelEndpointRollout
+		modelEndpoint  *models.ModelEndpoint
+		rolloutService func() *mocks.ModelEndpointRolloutService
+		expected       *ApiResponse
+	}{
+		{
+			desc:          "Should success start rollout",
+			body:          &models.ModelEndpointRollout{VersionEndpointId: versionEndpointId, Steps: models.RolloutSteps{10, 100}, StepInterval: "5m"},
+			modelEndpoint: servingEndpoint,
+			rolloutService: func() *mocks.ModelEndpointRolloutService {
+				svc := &mocks.ModelEndpointRolloutService{}
+				svc.On("StartRollout", mock.Anything, model, servingEndpoint, mock.Anything).
+					Return(&models.ModelEndpointRollout{Id: 2, VersionEndpointId: versionEndpointId, Status: models.RolloutProgressing}, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusCreated,
+				data: &models.ModelEndpointRollout{Id: 2, VersionEndpointId: versionEndpointId, Status: models.RolloutProgressing},
+			},
+		},
+		{
+			desc:          "Should return 400 if steps are invalid",
+			body:          &models.ModelEndpointRollout{VersionEndpointId: versionEndpointId, Steps: models.RolloutSteps{10, 50}, StepInterval: "5m"},
+			modelEndpoint: servingEndpoint,
+			rolloutService: func() *mocks.ModelEndpointRolloutService {
+				svc := &mocks.ModelEndpointRolloutService{}
+				svc.On("StartRollout", mock.Anything, model, servingEndpoint, mock.Anything).
+					Return(nil, models.NewInvalidRolloutError("last rollout step must route 100%% of traffic, got 50"))
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid rollout: last rollout step must route 100% of traffic, got 50"},
+			},
+		},
+		{
+			desc:          "Should return 400 if another rollout is progressing",
+			body:          &models.ModelEndpointRollout{VersionEndpointId: versionEndpointId, Steps: models.RolloutSteps{100}, StepInterval: "5m"},
+			modelEndpoint: servingEndpoint,
+			rolloutService: func() *mocks.ModelEndpointRolloutService {
+				svc := &mocks.ModelEndpointRolloutService{}
+				svc.On("StartRollout", mock.Anything, model, servingEndpoint, mock.Anything).
+					Return(nil, models.NewInvalidRolloutError("rollout 1 is still progressing for model endpoint 1"))
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid rollout: rollout 1 is still progressing for model endpoint 1"},
+			},
+		},
+		{
+			desc:          "Should return 500 if rollout fails to start",
+			body:          &models.ModelEndpointRollout{VersionEndpointId: versionEndpointId, Steps: models.RolloutSteps{100}, StepInterval: "5m"},
+			modelEndpoint: servingEndpoint,
+			rolloutService: func() *mocks.ModelEndpointRolloutService {
+				svc := &mocks.ModelEndpointRolloutService{}
+				svc.On("StartRollout", mock.Anything, model, servingEndpoint, mock.Anything).Return(nil, fmt.Errorf("istio is down"))
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusInternalServerError,
+				data: Error{Message: "Unable to start rollout: istio is down"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			modelsService := &mocks.ModelsService{}
+			modelsService.On("FindById", mock.Anything, models.Id(1)).Return(model, nil)
+
+			modelEndpointsService := &mocks.ModelEndpointsService{}
+			modelEndpointsService.On("FindById", mock.Anything, models.Id(1)).Return(tC.modelEndpoint, nil)
+
+			ctl := &ModelEndpointRolloutsController{
+				AppContext: &AppContext{
+					ModelsService:               modelsService,
+					ModelEndpointsService:       modelEndpointsService,
+					ModelEndpointRolloutService: tC.rolloutService(),
+				},
+			}
+			resp := ctl.CreateRollout(&http.Request{}, map[string]string{"model_id": "1", "model_endpoint_id": "1"}, tC.body)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
+
+func TestRollbackModelEndpointRollout(t *testing.T) {
+	model := &models.Model{Id: 1, Name: "model-1"}
+	progressing := &models.ModelEndpointRollout{Id: 1, ModelId: 1, ModelEndpointId: 1, Status: models.RolloutProgressing}
+
+	testCases := []struct {
+		desc           string
+		vars           map[string]string
+		rolloutService func() *mocks.ModelEndpointRolloutService
+		expected       *ApiResponse
+	}{
+		{
+			desc: "Should success rollback rollout",
+			vars: map[string]string{"model_id": "1", "model_endpoint_id": "1", "rollout_id": "1"},
+			rolloutService: func() *mocks.ModelEndpointRolloutService {
+				svc := &mocks.ModelEndpointRolloutService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(progressing, nil)
+				svc.On("RollbackRollout", mock.Anything, model, progressing, "rolled back manually").
+					Return(&models.ModelEndpointRollout{Id: 1, Status: models.RolloutRolledBack}, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: &models.ModelEndpointRollout{Id: 1, Status: models.RolloutRolledBack},
+			},
+		},
+		{
+			desc: "Should return 404 if rollout belongs to another model",
+			vars: map[string]string{"model_id": "2", "model_endpoint_id": "1", "rollout_id": "1"},
+			rolloutService: func() *mocks.ModelEndpointRolloutService {
+				svc := &mocks.ModelEndpointRolloutService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(progressing, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "Rollout with id 1 not found"},
+			},
+		},
+		{
+			desc: "Should return 400 if rollout changed concurrently",
+			vars: map[string]string{"model_id": "1", "model_endpoint_id": "1", "rollout_id": "1"},
+			rolloutService: func() *mocks.ModelEndpointRolloutService {
+				svc := &mocks.ModelEndpointRolloutService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(progressing, nil)
+				svc.On("RollbackRollout", mock.Anything, model, progressing, "rolled back manually").
+					Return(nil, models.NewInvalidRolloutError("rollout 1 changed while rolling it back, please retry"))
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Unable to rollback rollout: rollout 1 changed while rolling it back, please retry"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			modelsService := &mocks.ModelsService{}
+			modelsService.On("FindById", mock.Anything, models.Id(1)).Return(model, nil)
+			modelsService.On("FindById", mock.Anything, models.Id(2)).Return(&models.Model{Id: 2, Name: "model-2"}, nil)
+
+			rolloutService := tC.rolloutService()
+			ctl := &ModelEndpointRolloutsController{
+				AppContext: &AppContext{
+					ModelsService:               modelsService,
+					ModelEndpointRolloutService: rolloutService,
+				},
+			}
+			resp := ctl.RollbackRollout(&http.Request{}, tC.vars, nil)
+			assert.Equal(t, tC.expected, resp)
+			if tC.expected.code == http.StatusNotFound {
+				rolloutService.AssertNotCalled(t, "RollbackRollout", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -130,12 +130,32 @@ func (c *ModelEndpointsController) CreateModelEndpoint(r *http.Request, vars map
 	}
 	endpoint.Environment = env
 
+	if err := endpoint.Rule.ValidateMatches(); err != nil {
+		return BadRequest(fmt.Sprintf("Invalid model endpoint rule: %s", err))
+	}
+	if err := endpoint.Rule.ValidateMirrors(); err != nil {
+		return BadRequest(fmt.Sprintf("Invalid model endpoint rule: %s", err))
+	}
+	if endpoint.TrafficPolicy != nil {
+		if err := endpoint.TrafficPolicy.Validate(); err != nil {
+			return BadRequest(fmt.Sprintf("Invalid model endpoint traffic policy: %s", err))
+		}
+	}
+
 	// Fetch version endpoint as model endpoint destination
-	endpoint, err = c.assignVersionEndpoint(ctx, endpoint)
+	endpoint, err = c.assignVersionEndpoint(ctx, model, endpoint)
 	if err != nil {
 		return BadRequest(fmt.Sprintf("Invalid version endpoints destination: %s", err))
 	}
 
+	if isDryRun(vars) {
+		result, err := c.ModelEndpointsService.RenderEndpoint(ctx, model, endpoint)
+		if err != nil {
+			return InternalServerError(fmt.Sprintf("Unable to create model endpoint: %s", err.Error()))
+		}
+		return Ok(result)
+	}
+
 	// Deploy model endpoint as Istio's VirtualService
 	endpoint, err = c.ModelEndpointsService.DeployEndpoint(ctx, model, endpoint)
 	if err != nil {
@@ -179,8 +199,20 @@ func (c *ModelEndpointsController) UpdateModelEndpoint(r *http.Request, vars map
 	newEndpoint.EnvironmentName = env.Name
 	newEndpoint.Environment = env
 
+	if err := newEndpoint.Rule.ValidateMatches(); err != nil {
+		return BadRequest(fmt.Sprintf("Invalid model endpoint rule: %s", err))
+	}
+	if err := newEndpoint.Rule.ValidateMirrors(); err != nil {
+		return BadRequest(fmt.Sprintf("Invalid model endpoint rule: %s", err))
+	}
+	if newEndpoint.TrafficPolicy != nil {
+		if err := newEndpoint.TrafficPolicy.Validate(); err != nil {
+			return BadRequest(fmt.Sprintf("Invalid model endpoint traffic policy: %s", err))
+		}
+	}
+
 	// Fetch version endpoint as model endpoint destination
-	newEndpoint, err = c.assignVersionEndpoint(ctx, newEndpoint)
+	newEndpoint, err = c.assignVersionEndpoint(ctx, model, newEndpoint)
 	if err != nil {
 		return BadRequest(fmt.Sprintf("Invalid version endpoints destination: %s", err))
 	}
@@ -194,6 +226,23 @@ func (c *ModelEndpointsController) UpdateModelEndpoint(r *http.Request, vars map
 		return BadRequest("Invalid request model endpoint id")
 	}
 
+	// the traffic rule of a model endpoint is owned by its rollout until the rollout finishes
+	rollout, err := c.ModelEndpointRolloutService.FindProgressingRollout(ctx, modelEndpointId)
+	if err != nil {
+		return InternalServerError(fmt.Sprintf("Error while getting rollouts of model endpoint %s", modelEndpointId))
+	}
+	if rollout != nil {
+		return BadRequest(fmt.Sprintf("Rollout %s of model endpoint %s is still progressing, please wait until it finishes or roll it back", rollout.Id, modelEndpointId))
+	}
+
+	if isDryRun(vars) {
+		result, err := c.ModelEndpointsService.RenderEndpoint(ctx, model, newEndpoint)
+		if err != nil {
+			return InternalServerError(fmt.Sprintf("Unable to update model endpoint: %s", err.Error()))
+		}
+		return Ok(result)
+	}
+
 	if currentEndpoint.Status == models.EndpointTerminated {
 		newEndpoint, err = c.ModelEndpointsService.DeployEndpoint(ctx, model, newEndpoint)
 	} else {
@@ -227,7 +276,7 @@ func (c *ModelEndpointsController) saveDB(ctx context.Context, newModelEndpoint,
 
 	// Update version and version endpoints from previous model endpoint
 	if prevModelEndpoint != nil {
-		for _, ruleDestination := range prevModelEndpoint.Rule.Destination {
+		for _, ruleDestination := range prevModelEndpoint.Rule.AllDestinations() {
 			versionEndpoint, err := c.EndpointsService.FindById(ruleDestination.VersionEndpointID)
 			if err != nil {
 				return err
@@ -242,7 +291,7 @@ func (c *ModelEndpointsController) saveDB(ctx context.Context, newModelEndpoint,
 	}
 
 	// Update version and version endpoints from new model endpoint
-	for _, ruleDestination := range newModelEndpoint.Rule.Destination {
+	for _, ruleDestination := range newModelEndpoint.Rule.AllDestinations() {
 		versionEndpoint, err := c.EndpointsService.FindById(ruleDestination.VersionEndpointID)
 		if err != nil {
 			return err
@@ -269,9 +318,9 @@ func (c *ModelEndpointsController) saveDB(ctx context.Context, newModelEndpoint,
 
 // assignVersionEndpoint fetches destination version endpoints from database and assign to model endpoint.
 // assignVersionEndpoint validates version endpoint status and returns error if find no running version endpoint.
-func (c *ModelEndpointsController) assignVersionEndpoint(ctx context.Context, endpoint *models.ModelEndpoint) (*models.ModelEndpoint, error) {
-	for k := range endpoint.Rule.Destination {
-		versionEndpointID := endpoint.Rule.Destination[k].VersionEndpointID
+func (c *ModelEndpointsController) assignVersionEndpoint(ctx context.Context, model *models.Model, endpoint *models.ModelEndpoint) (*models.ModelEndpoint, error) {
+	for _, destination := range endpoint.Rule.AllDestinations() {
+		versionEndpointID := destination.VersionEndpointID
 
 		versionEndpoint, err := c.EndpointsService.FindById(versionEndpointID)
 		if err != nil {
@@ -281,13 +330,83 @@ func (c *ModelEndpointsController) assignVersionEndpoint(ctx context.Context, en
 		if !versionEndpoint.IsRunning() {
 			return nil, fmt.Errorf("Version Endpoint %s is not running, but %s", versionEndpoint.Id, versionEndpoint.Status)
 		}
+		if versionEndpoint.IsClusterLocal() {
+			return nil, fmt.Errorf("Version Endpoint %s is only reachable inside its cluster", versionEndpoint.Id)
+		}
+
+		destination.VersionEndpoint = versionEndpoint
+	}
+
+	// Shadow version endpoints don't serve the responses, hence they aren't required to be running.
+	// They receive copies of the live requests though, so they must be versions of the same model in the same environment.
+	for _, mirror := range endpoint.Rule.MirrorTargets() {
+		versionEndpoint, err := c.EndpointsService.FindById(mirror.VersionEndpointID)
+		if err != nil {
+			return nil, fmt.Errorf("Mirror Version Endpoint with given `version_endpoint_id: %s` not found", mirror.VersionEndpointID)
+		}
+		if versionEndpoint.VersionModelId != model.Id || versionEndpoint.EnvironmentName != endpoint.EnvironmentName {
+			return nil, fmt.Errorf("Mirror Version Endpoint %s doesn't belong to model %s in environment %s", versionEndpoint.Id, model.Name, endpoint.EnvironmentName)
+		}
+		if versionEndpoint.Url == "" {
+			return nil, fmt.Errorf("Mirror Version Endpoint %s has never been deployed", versionEndpoint.Id)
+		}
+		if versionEndpoint.IsClusterLocal() {
+			return nil, fmt.Errorf("Mirror Version Endpoint %s is only reachable inside its cluster", versionEndpoint.Id)
+		}
 
-		endpoint.Rule.Destination[k].VersionEndpoint = versionEndpoint
+		mirror.VersionEndpoint = versionEndpoint
 	}
 
 	return endpoint, nil
 }
 
+// ListModelEndpointShadows lists the version endpoints shadowing a model endpoint.
+func (c *ModelEndpointsController) ListModelEndpointShadows(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	modelId, _ := models.ParseId(vars["model_id"])
+	modelEndpointId, _ := models.ParseId(vars["model_endpoint_id"])
+	modelEndpoint, err := c.ModelEndpointsService.FindById(ctx, modelEndpointId)
+	if err != nil {
+		log.Errorf("Error finding model endpoint with id %s, reason: %v", modelEndpointId, err)
+
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Model endpoint with id %s not found", modelEndpointId))
+		}
+
+		return InternalServerError(fmt.Sprintf("Error while getting model endpoint with id %s", modelEndpointId))
+	}
+
+	if modelEndpoint.ModelId != modelId {
+		return NotFound(fmt.Sprintf("Model endpoint with id %s not found", modelEndpointId))
+	}
+
+	shadows := []*models.ModelEndpointShadow{}
+	if modelEndpoint.Rule == nil || modelEndpoint.Status != models.EndpointServing {
+		return Ok(shadows)
+	}
+
+	for _, mirror := range modelEndpoint.Rule.MirrorTargets() {
+		versionEndpoint, err := c.EndpointsService.FindById(mirror.VersionEndpointID)
+		if err != nil {
+			if gorm.IsRecordNotFoundError(err) {
+				continue
+			}
+			return InternalServerError(fmt.Sprintf("Error while getting version endpoint %s", mirror.VersionEndpointID))
+		}
+
+		shadows = append(shadows, &models.ModelEndpointShadow{
+			VersionId:         versionEndpoint.VersionId,
+			VersionEndpointId: versionEndpoint.Id,
+			Percentage:        mirror.Percentage,
+			Status:            versionEndpoint.Status,
+			Active:            versionEndpoint.Status == models.EndpointRunning || versionEndpoint.IsServing(),
+		})
+	}
+
+	return Ok(shadows)
+}
+
 // DeleteModelEndpoint stops model endpoint for serving.
 // To be more precise, it will do the following:
 // 1. Delete the corresponding Istio's VirtualService
@@ -321,6 +440,15 @@ func (c *ModelEndpointsController) DeleteModelEndpoint(r *http.Request, vars map
 		return InternalServerError(fmt.Sprintf("Error while getting model endpoint with id %s", modelEndpointId))
 	}
 
+	// the rollout would keep patching the deleted VirtualService, so it must be finished first
+	rollout, err := c.ModelEndpointRolloutService.FindProgressingRollout(ctx, modelEndpointId)
+	if err != nil {
+		return InternalServerError(fmt.Sprintf("Error while getting rollouts of model endpoint %s", modelEndpointId))
+	}
+	if rollout != nil {
+		return BadRequest(fmt.Sprintf("Rollout %s of model endpoint %s is still progressing, please wait until it finishes or roll it back", rollout.Id, modelEndpointId))
+	}
+
 	modelEndpoint, err = c.ModelEndpointsService.UndeployEndpoint(ctx, model, modelEndpoint)
 	if err != nil {
 		return InternalServerError(fmt.Sprintf("Unable to delete model endpoint: %s", err.Error()))
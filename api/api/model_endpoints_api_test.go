@@ -22,6 +22,7 @@ import (
 	"github.com/gojek/merlin/mlp"
 	"github.com/gojek/merlin/models"
 	"github.com/gojek/merlin/service/mocks"
+	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -319,3 +320,254 @@ func TestGetModelEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateModelEndpointWithAmbiguousMatches(t *testing.T) {
+	match := &models.ModelEndpointRuleMatch{
+		Conditions: []*models.ModelEndpointRuleMatchCondition{
+			{Type: models.RuleMatchHeader, Name: "x-variant", Value: "beta"},
+		},
+		Destination: []*models.ModelEndpointRuleDestination{{Weight: 100}},
+	}
+	endpoint := &models.ModelEndpoint{
+		ModelId:         models.Id(1),
+		EnvironmentName: "dev",
+		Rule: &models.ModelEndpointRule{
+			Destination: []*models.ModelEndpointRuleDestination{{Weight: 100}},
+			Matches:     []*models.ModelEndpointRuleMatch{match, match},
+		},
+	}
+
+	modelsSvc := &mocks.ModelsService{}
+	modelsSvc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{Id: models.Id(1), Name: "model-1"}, nil)
+
+	envSvc := &mocks.EnvironmentService{}
+	envSvc.On("GetEnvironment", "dev").Return(&models.Environment{Name: "dev"}, nil)
+
+	ctl := &ModelEndpointsController{
+		AppContext: &AppContext{
+			ModelsService:      modelsSvc,
+			EnvironmentService: envSvc,
+		},
+	}
+	resp := ctl.CreateModelEndpoint(&http.Request{}, map[string]string{"model_id": "1"}, endpoint)
+	assert.Equal(t, &ApiResponse{
+		code: http.StatusBadRequest,
+		data: Error{Message: "Invalid model endpoint rule: ambiguous matches: match 2 is never reached because match 1 accepts all of its requests"},
+	}, resp)
+}
+
+func TestCreateModelEndpointDryRun(t *testing.T) {
+	versionEndpoint := &models.VersionEndpoint{Id: uuid.New(), VersionId: models.Id(1), Status: models.EndpointRunning}
+	endpoint := &models.ModelEndpoint{
+		ModelId:         models.Id(1),
+		EnvironmentName: "dev",
+		Rule: &models.ModelEndpointRule{
+			Destination: []*models.ModelEndpointRuleDestination{{VersionEndpointID: versionEndpoint.Id, Weight: 100}},
+		},
+	}
+	result := &models.DryRunResult{
+		Manifests: []*models.Manifest{
+			{Kind: "VirtualService", Name: "model-1", Namespace: "sample", Yaml: "kind: VirtualService\n"},
+		},
+	}
+
+	modelsSvc := &mocks.ModelsService{}
+	modelsSvc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{Id: models.Id(1), Name: "model-1"}, nil)
+
+	envSvc := &mocks.EnvironmentService{}
+	envSvc.On("GetEnvironment", "dev").Return(&models.Environment{Name: "dev"}, nil)
+
+	endpointSvc := &mocks.EndpointsService{}
+	endpointSvc.On("FindById", versionEndpoint.Id).Return(versionEndpoint, nil)
+
+	modelEndpointSvc := &mocks.ModelEndpointsService{}
+	modelEndpointSvc.On("RenderEndpoint", mock.Anything, mock.Anything, endpoint).Return(result, nil)
+
+	ctl := &ModelEndpointsController{
+		AppContext: &AppContext{
+			ModelsService:         modelsSvc,
+			EnvironmentService:    envSvc,
+			EndpointsService:      endpointSvc,
+			ModelEndpointsService: modelEndpointSvc,
+		},
+	}
+	resp := ctl.CreateModelEndpoint(&http.Request{}, map[string]string{"model_id": "1", "dry_run": "true"}, endpoint)
+	assert.Equal(t, &ApiResponse{code: http.StatusOK, data: result}, resp)
+	modelEndpointSvc.AssertNotCalled(t, "DeployEndpoint", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateModelEndpointWithInvalidMirror(t *testing.T) {
+	destination := &models.VersionEndpoint{Id: uuid.New(), VersionModelId: models.Id(1), EnvironmentName: "dev", Status: models.EndpointRunning}
+	mirrorId := uuid.New()
+
+	testCases := []struct {
+		desc     string
+		mirror   *models.VersionEndpoint
+		expected string
+	}{
+		{
+			desc:     "Should return 400 if mirror belongs to another model",
+			mirror:   &models.VersionEndpoint{Id: mirrorId, VersionModelId: models.Id(2), EnvironmentName: "dev", Url: "http://model-2-1.sample.models.id.merlin.dev/v1/models/model-2-1"},
+			expected: fmt.Sprintf("Invalid version endpoints destination: Mirror Version Endpoint %s doesn't belong to model model-1 in environment dev", mirrorId),
+		},
+		{
+			desc:     "Should return 400 if mirror is in another environment",
+			mirror:   &models.VersionEndpoint{Id: mirrorId, VersionModelId: models.Id(1), EnvironmentName: "staging", Url: "http://model-1-2.sample.models.id.merlin.staging/v1/models/model-1-2"},
+			expected: fmt.Sprintf("Invalid version endpoints destination: Mirror Version Endpoint %s doesn't belong to model model-1 in environment dev", mirrorId),
+		},
+		{
+			desc:     "Should return 400 if mirror has never been deployed",
+			mirror:   &models.VersionEndpoint{Id: mirrorId, VersionModelId: models.Id(1), EnvironmentName: "dev", Status: models.EndpointPending},
+			expected: fmt.Sprintf("Invalid version endpoints destination: Mirror Version Endpoint %s has never been deployed", mirrorId),
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			endpoint := &models.ModelEndpoint{
+				ModelId:         models.Id(1),
+				EnvironmentName: "dev",
+				Rule: &models.ModelEndpointRule{
+					Destination: []*models.ModelEndpointRuleDestination{{VersionEndpointID: destination.Id, Weight: 100}},
+					Mirrors:     []*models.ModelEndpointRuleMirror{{VersionEndpointID: mirrorId, Percentage: 10}},
+				},
+			}
+
+			modelsSvc := &mocks.ModelsService{}
+			modelsSvc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{Id: models.Id(1), Name: "model-1"}, nil)
+
+			envSvc := &mocks.EnvironmentService{}
+			envSvc.On("GetEnvironment", "dev").Return(&models.Environment{Name: "dev"}, nil)
+
+			endpointSvc := &mocks.EndpointsService{}
+			endpointSvc.On("FindById", destination.Id).Return(destination, nil)
+			endpointSvc.On("FindById", mirrorId).Return(tC.mirror, nil)
+
+			modelEndpointSvc := &mocks.ModelEndpointsService{}
+
+			ctl := &ModelEndpointsController{
+				AppContext: &AppContext{
+					ModelsService:         modelsSvc,
+					EnvironmentService:    envSvc,
+					EndpointsService:      endpointSvc,
+					ModelEndpointsService: modelEndpointSvc,
+				},
+			}
+			resp := ctl.CreateModelEndpoint(&http.Request{}, map[string]string{"model_id": "1"}, endpoint)
+			assert.Equal(t, &ApiResponse{code: http.StatusBadRequest, data: Error{Message: tC.expected}}, resp)
+			modelEndpointSvc.AssertNotCalled(t, "DeployEndpoint", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateModelEndpointDuringRollout(t *testing.T) {
+	versionEndpoint := &models.VersionEndpoint{Id: uuid.New(), VersionId: models.Id(1), Status: models.EndpointRunning}
+	endpoint := &models.ModelEndpoint{
+		Id:              models.Id(1),
+		ModelId:         models.Id(1),
+		EnvironmentName: "dev",
+		Rule: &models.ModelEndpointRule{
+			Destination: []*models.ModelEndpointRuleDestination{{VersionEndpointID: versionEndpoint.Id, Weight: 100}},
+		},
+	}
+
+	modelsSvc := &mocks.ModelsService{}
+	modelsSvc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{Id: models.Id(1), Name: "model-1"}, nil)
+
+	envSvc := &mocks.EnvironmentService{}
+	envSvc.On("GetEnvironment", "dev").Return(&models.Environment{Name: "dev"}, nil)
+
+	endpointSvc := &mocks.EndpointsService{}
+	endpointSvc.On("FindById", versionEndpoint.Id).Return(versionEndpoint, nil)
+
+	modelEndpointSvc := &mocks.ModelEndpointsService{}
+	modelEndpointSvc.On("FindById", mock.Anything, models.Id(1)).Return(&models.ModelEndpoint{Id: models.Id(1), Status: models.EndpointServing}, nil)
+
+	rolloutSvc := &mocks.ModelEndpointRolloutService{}
+	rolloutSvc.On("FindProgressingRollout", mock.Anything, models.Id(1)).Return(&models.ModelEndpointRollout{Id: models.Id(2), Status: models.RolloutProgressing}, nil)
+
+	ctl := &ModelEndpointsController{
+		AppContext: &AppContext{
+			ModelsService:               modelsSvc,
+			EnvironmentService:          envSvc,
+			EndpointsService:            endpointSvc,
+			ModelEndpointsService:       modelEndpointSvc,
+			ModelEndpointRolloutService: rolloutSvc,
+		},
+	}
+	resp := ctl.UpdateModelEndpoint(&http.Request{}, map[string]string{"model_id": "1", "model_endpoint_id": "1"}, endpoint)
+	assert.Equal(t, &ApiResponse{
+		code: http.StatusBadRequest,
+		data: Error{Message: "Rollout 2 of model endpoint 1 is still progressing, please wait until it finishes or roll it back"},
+	}, resp)
+	modelEndpointSvc.AssertNotCalled(t, "UpdateEndpoint", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteModelEndpointDuringRollout(t *testing.T) {
+	modelsSvc := &mocks.ModelsService{}
+	modelsSvc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{Id: models.Id(1), Name: "model-1"}, nil)
+
+	modelEndpointSvc := &mocks.ModelEndpointsService{}
+	modelEndpointSvc.On("FindById", mock.Anything, models.Id(1)).Return(&models.ModelEndpoint{Id: models.Id(1), ModelId: models.Id(1), Status: models.EndpointServing}, nil)
+
+	rolloutSvc := &mocks.ModelEndpointRolloutService{}
+	rolloutSvc.On("FindProgressingRollout", mock.Anything, models.Id(1)).Return(&models.ModelEndpointRollout{Id: models.Id(2), Status: models.RolloutProgressing}, nil)
+
+	ctl := &ModelEndpointsController{
+		AppContext: &AppContext{
+			ModelsService:               modelsSvc,
+			ModelEndpointsService:       modelEndpointSvc,
+			ModelEndpointRolloutService: rolloutSvc,
+		},
+	}
+	resp := ctl.DeleteModelEndpoint(&http.Request{}, map[string]string{"model_id": "1", "model_endpoint_id": "1"}, nil)
+	assert.Equal(t, &ApiResponse{
+		code: http.StatusBadRequest,
+		data: Error{Message: "Rollout 2 of model endpoint 1 is still progressing, please wait until it finishes or roll it back"},
+	}, resp)
+	modelEndpointSvc.AssertNotCalled(t, "UndeployEndpoint", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListModelEndpointShadows(t *testing.T) {
+	running := &models.VersionEndpoint{Id: uuid.New(), VersionId: models.Id(2), Status: models.EndpointRunning}
+	failed := &models.VersionEndpoint{Id: uuid.New(), VersionId: models.Id(3), Status: models.EndpointFailed}
+	deleted := uuid.New()
+
+	modelEndpoint := &models.ModelEndpoint{
+		Id:      models.Id(1),
+		ModelId: models.Id(1),
+		Status:  models.EndpointServing,
+		Rule: &models.ModelEndpointRule{
+			Mirrors: []*models.ModelEndpointRuleMirror{
+				{VersionEndpointID: running.Id, Percentage: 20},
+				{VersionEndpointID: failed.Id, Percentage: 10},
+				{VersionEndpointID: deleted, Percentage: 5},
+			},
+		},
+	}
+
+	modelEndpointSvc := &mocks.ModelEndpointsService{}
+	modelEndpointSvc.On("FindById", mock.Anything, models.Id(1)).Return(modelEndpoint, nil)
+
+	endpointSvc := &mocks.EndpointsService{}
+	endpointSvc.On("FindById", running.Id).Return(running, nil)
+	endpointSvc.On("FindById", failed.Id).Return(failed, nil)
+	endpointSvc.On("FindById", deleted).Return(nil, gorm.ErrRecordNotFound)
+
+	ctl := &ModelEndpointsController{
+		AppContext: &AppContext{
+			ModelEndpointsService: modelEndpointSvc,
+			EndpointsService:      endpointSvc,
+		},
+	}
+	resp := ctl.ListModelEndpointShadows(&http.Request{}, map[string]string{"model_id": "1", "model_endpoint_id": "1"}, nil)
+	assert.Equal(t, &ApiResponse{
+		code: http.StatusOK,
+		data: []*models.ModelEndpointShadow{
+			{VersionId: models.Id(2), VersionEndpointId: running.Id, Percentage: 20, Status: models.EndpointRunning, Active: true},
+			{VersionId: models.Id(3), VersionEndpointId: failed.Id, Percentage: 10, Status: models.EndpointFailed, Active: false},
+		},
+	}, resp)
+
+	resp = ctl.ListModelEndpointShadows(&http.Request{}, map[string]string{"model_id": "2", "model_endpoint_id": "1"}, nil)
+	assert.Equal(t, http.StatusNotFound, resp.code)
+}
@@ -53,6 +53,15 @@ func (c *PredictionJobController) Create(r *http.Request, vars map[string]string
 		return InternalServerError("Unable to find default environment, specify environment target for deployment")
 	}
 
+	if isDryRun(vars) {
+		result, err := c.PredictionJobService.RenderPredictionJob(env, model, version, data)
+		if err != nil {
+			log.Errorf("failed rendering prediction job %v", err)
+			return BadRequest(fmt.Sprintf("Failed creating prediction job %s", err))
+		}
+		return Ok(result)
+	}
+
 	predictionJob, err := c.PredictionJobService.CreatePredictionJob(env, model, version, data)
 	if err != nil {
 		log.Errorf("failed creating prediction job %v", err)
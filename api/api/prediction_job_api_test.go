@@ -1144,6 +1144,84 @@ func TestCreate(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "Should render prediction job manifests on dry run",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+				"job_id":     "1",
+				"dry_run":    "true",
+			},
+			requestBody: &models.PredictionJob{
+				Name:            "prediction-job-1",
+				ProjectId:       models.Id(1),
+				VersionId:       models.Id(1),
+				VersionModelId:  models.Id(1),
+				EnvironmentName: "dev",
+			},
+			modelService: func() *mocks.ModelsService {
+				svc := &mocks.ModelsService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{
+					Id:           models.Id(1),
+					Name:         "model-1",
+					ProjectId:    models.Id(1),
+					Project:      mlp.Project{},
+					ExperimentId: 1,
+					Type:         "pyfunc",
+					MlflowUrl:    "",
+					Endpoints:    nil,
+				}, nil)
+				return svc
+			},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{
+					Id:      models.Id(1),
+					ModelId: models.Id(1),
+					Model: &models.Model{
+						Id:           models.Id(1),
+						Name:         "model-1",
+						ProjectId:    models.Id(1),
+						Project:      mlp.Project{},
+						ExperimentId: 1,
+						Type:         "pyfunc",
+						MlflowUrl:    "",
+						Endpoints:    nil,
+					},
+				}, nil)
+				return svc
+			},
+			envService: func() *mocks.EnvironmentService {
+				svc := &mocks.EnvironmentService{}
+				svc.On("GetDefaultPredictionJobEnvironment").Return(&models.Environment{
+					Id:                     models.Id(1),
+					Name:                   "dev",
+					Cluster:                "dev",
+					Region:                 "id",
+					GcpProject:             "id-proj",
+					IsPredictionJobEnabled: true,
+					IsDefaultPredictionJob: &trueBoolean,
+				}, nil)
+				return svc
+			},
+			predictionJobService: func() *mocks.PredictionJobService {
+				svc := &mocks.PredictionJobService{}
+				svc.On("RenderPredictionJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.DryRunResult{
+					Manifests: []*models.Manifest{
+						{Kind: "SparkApplication", Name: "prediction-job-1", Namespace: "sample", Yaml: "kind: SparkApplication\n"},
+					},
+				}, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: &models.DryRunResult{
+					Manifests: []*models.Manifest{
+						{Kind: "SparkApplication", Name: "prediction-job-1", Namespace: "sample", Yaml: "kind: SparkApplication\n"},
+					},
+				},
+			},
+		},
 		{
 			desc: "Should return 500 if error fetching model",
 			vars: map[string]string{
@@ -0,0 +1,141 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/models"
+)
+
+type QuotasController struct {
+	*AppContext
+}
+
+// ListQuotas lists the quotas of a project in every environment along with their usage
+func (c *QuotasController) ListQuotas(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	projectID, _ := models.ParseId(vars["project_id"])
+	_, err := c.ProjectsService.GetByID(ctx, int32(projectID))
+	if err != nil {
+		log.Warnf("Project with id: %d not found", projectID)
+		return NotFound(fmt.Sprintf("Project with given `project_id: %d` not found", projectID))
+	}
+
+	envs, err := c.EnvironmentService.ListEnvironments("")
+	if err != nil {
+		return InternalServerError(err.Error())
+	}
+
+	quotas := make([]*models.QuotaStatus, 0, len(envs))
+	for _, env := range envs {
+		quota, err := c.QuotaService.GetQuota(projectID, env.Name)
+		if err != nil {
+			log.Errorf("Error getting quota of project %d in environment %s, reason: %v", projectID, env.Name, err)
+			return InternalServerError(fmt.Sprintf("Error while getting quota in environment %s", env.Name))
+		}
+		quotas = append(quotas, quota)
+	}
+	return Ok(quotas)
+}
+
+// GetQuota gets the quota of a project in an environment along with its usage
+func (c *QuotasController) GetQuota(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	projectID, env, resp := c.getProjectAndEnvironment(r, vars)
+	if resp != nil {
+		return resp
+	}
+
+	quota, err := c.QuotaService.GetQuota(projectID, env.Name)
+	if err != nil {
+		log.Errorf("Error getting quota of project %d in environment %s, reason: %v", projectID, env.Name, err)
+		return InternalServerError(fmt.Sprintf("Error while getting quota in environment %s", env.Name))
+	}
+	return Ok(quota)
+}
+
+// SetQuota creates or replaces the quota of a project in an environment, a limit left empty is unlimited
+func (c *QuotasController) SetQuota(r *http.Request, vars map[string]string, body interface{}) *ApiResponse {
+	if !c.isAdmin(vars["user"]) {
+		return Forbidden("Only administrators can change project quotas")
+	}
+
+	projectID, env, resp := c.getProjectAndEnvironment(r, vars)
+	if resp != nil {
+		return resp
+	}
+
+	limits, ok := body.(*models.QuotaLimits)
+	if !ok {
+		return BadRequest("Unable to parse body as quota limits")
+	}
+	if err := limits.Validate(); err != nil {
+		return BadRequest(fmt.Sprintf("Invalid quota limits: %s", err))
+	}
+
+	quota, err := c.QuotaService.SetQuota(projectID, env.Name, *limits)
+	if err != nil {
+		log.Errorf("Error setting quota of project %d in environment %s, reason: %v", projectID, env.Name, err)
+		return InternalServerError(fmt.Sprintf("Error while setting quota in environment %s", env.Name))
+	}
+	return Ok(quota)
+}
+
+// DeleteQuota deletes the quota of a project in an environment so that the default quota of the environment applies
+func (c *QuotasController) DeleteQuota(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	if !c.isAdmin(vars["user"]) {
+		return Forbidden("Only administrators can change project quotas")
+	}
+
+	projectID, env, resp := c.getProjectAndEnvironment(r, vars)
+	if resp != nil {
+		return resp
+	}
+
+	if err := c.QuotaService.DeleteQuota(projectID, env.Name); err != nil {
+		log.Errorf("Error deleting quota of project %d in environment %s, reason: %v", projectID, env.Name, err)
+		return InternalServerError(fmt.Sprintf("Error while deleting quota in environment %s", env.Name))
+	}
+	return NoContent()
+}
+
+// isAdmin checks the user explicitly since the admin routes are only protected by the authorization policy when
+// authorization is enabled
+func (c *QuotasController) isAdmin(user string) bool {
+	for _, admin := range c.AdminUsers {
+		if user == admin {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *QuotasController) getProjectAndEnvironment(r *http.Request, vars map[string]string) (models.Id, *models.Environment, *ApiResponse) {
+	projectID, _ := models.ParseId(vars["project_id"])
+	_, err := c.ProjectsService.GetByID(r.Context(), int32(projectID))
+	if err != nil {
+		log.Warnf("Project with id: %d not found", projectID)
+		return 0, nil, NotFound(fmt.Sprintf("Project with given `project_id: %d` not found", projectID))
+	}
+
+	env, err := c.EnvironmentService.GetEnvironment(vars["environment_name"])
+	if err != nil {
+		return 0, nil, NotFound(fmt.Sprintf("Environment not found: %s", vars["environment_name"]))
+	}
+	return projectID, env, nil
+}
@@ -0,0 +1,251 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gojek/merlin/mlp"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/service/mocks"
+)
+
+func TestListQuotas(t *testing.T) {
+	maxEndpoints := 5
+	env1Quota := &models.QuotaStatus{
+		EnvironmentName: "env1",
+		Limits:          models.QuotaLimits{Endpoints: &maxEndpoints},
+		Usage:           &models.QuotaUsage{Endpoints: 2},
+	}
+	env2Quota := &models.QuotaStatus{
+		EnvironmentName: "env2",
+		Usage:           &models.QuotaUsage{},
+		IsDefault:       true,
+	}
+
+	testCases := []struct {
+		desc        string
+		findProject error
+		getQuotaErr error
+		expected    *ApiResponse
+	}{
+		{
+			desc: "Should success list quotas",
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: []*models.QuotaStatus{env1Quota, env2Quota},
+			},
+		},
+		{
+			desc:        "Should return 404 if project is not found",
+			findProject: fmt.Errorf("project not found"),
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "Project with given `project_id: 1` not found"},
+			},
+		},
+		{
+			desc:        "Should return 500 if getting quota failed",
+			getQuotaErr: fmt.Errorf("db is down"),
+			expected: &ApiResponse{
+				code: http.StatusInternalServerError,
+				data: Error{Message: "Error while getting quota in environment env1"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			projectSvc := &mocks.ProjectsService{}
+			projectSvc.On("GetByID", mock.Anything, int32(1)).Return(mlp.Project{Id: 1, Name: "project"}, tC.findProject)
+			envSvc := &mocks.EnvironmentService{}
+			envSvc.On("ListEnvironments", "").Return([]*models.Environment{{Name: "env1"}, {Name: "env2"}}, nil)
+			quotaSvc := &mocks.QuotaService{}
+			if tC.getQuotaErr != nil {
+				quotaSvc.On("GetQuota", models.Id(1), "env1").Return(nil, tC.getQuotaErr)
+			} else {
+				quotaSvc.On("GetQuota", models.Id(1), "env1").Return(env1Quota, nil)
+				quotaSvc.On("GetQuota", models.Id(1), "env2").Return(env2Quota, nil)
+			}
+
+			ctl := &QuotasController{
+				AppContext: &AppContext{
+					ProjectsService:    projectSvc,
+					EnvironmentService: envSvc,
+					QuotaService:       quotaSvc,
+				},
+			}
+			resp := ctl.ListQuotas(httptest.NewRequest(http.MethodGet, "/projects/1/quotas", nil), map[string]string{"project_id": "1"}, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
+
+func TestSetQuota(t *testing.T) {
+	maxEndpoints := 5
+	negative := -1
+	limits := &models.QuotaLimits{Endpoints: &maxEndpoints}
+
+	testCases := []struct {
+		desc     string
+		vars     map[string]string
+		body     interface{}
+		findEnv  error
+		setErr   error
+		expected *ApiResponse
+	}{
+		{
+			desc: "Should success set quota",
+			vars: map[string]string{"project_id": "1", "environment_name": "env1", "user": "admin@example.com"},
+			body: limits,
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: &models.ProjectQuota{ProjectId: 1, EnvironmentName: "env1", Limits: *limits},
+			},
+		},
+		{
+			desc:    "Should return 404 if environment is not found",
+			vars:    map[string]string{"project_id": "1", "environment_name": "env3", "user": "admin@example.com"},
+			body:    limits,
+			findEnv: fmt.Errorf("environment not found"),
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "Environment not found: env3"},
+			},
+		},
+		{
+			desc: "Should return 400 if body is invalid",
+			vars: map[string]string{"project_id": "1", "environment_name": "env1", "user": "admin@example.com"},
+			body: &models.VersionEndpoint{},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Unable to parse body as quota limits"},
+			},
+		},
+		{
+			desc: "Should return 400 if limits are negative",
+			vars: map[string]string{"project_id": "1", "environment_name": "env1", "user": "admin@example.com"},
+			body: &models.QuotaLimits{Replicas: &negative},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid quota limits: quota limits can't be negative"},
+			},
+		},
+		{
+			desc: "Should return 403 if user is not an administrator",
+			vars: map[string]string{"project_id": "1", "environment_name": "env1", "user": "user@example.com"},
+			body: limits,
+			expected: &ApiResponse{
+				code: http.StatusForbidden,
+				data: Error{Message: "Only administrators can change project quotas"},
+			},
+		},
+		{
+			desc:   "Should return 500 if saving quota failed",
+			vars:   map[string]string{"project_id": "1", "environment_name": "env1", "user": "admin@example.com"},
+			body:   limits,
+			setErr: fmt.Errorf("db is down"),
+			expected: &ApiResponse{
+				code: http.StatusInternalServerError,
+				data: Error{Message: "Error while setting quota in environment env1"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			projectSvc := &mocks.ProjectsService{}
+			projectSvc.On("GetByID", mock.Anything, int32(1)).Return(mlp.Project{Id: 1, Name: "project"}, nil)
+			envSvc := &mocks.EnvironmentService{}
+			envSvc.On("GetEnvironment", tC.vars["environment_name"]).Return(&models.Environment{Name: tC.vars["environment_name"]}, tC.findEnv)
+			quotaSvc := &mocks.QuotaService{}
+			if tC.setErr != nil {
+				quotaSvc.On("SetQuota", models.Id(1), "env1", *limits).Return(nil, tC.setErr)
+			} else {
+				quotaSvc.On("SetQuota", models.Id(1), "env1", *limits).Return(&models.ProjectQuota{ProjectId: 1, EnvironmentName: "env1", Limits: *limits}, nil)
+			}
+
+			ctl := &QuotasController{
+				AppContext: &AppContext{
+					ProjectsService:    projectSvc,
+					EnvironmentService: envSvc,
+					QuotaService:       quotaSvc,
+					AdminUsers:         []string{"admin@example.com"},
+				},
+			}
+			resp := ctl.SetQuota(httptest.NewRequest(http.MethodPut, "/admin/projects/1/quotas/env1", nil), tC.vars, tC.body)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
+
+func TestDeleteQuota(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		user      string
+		deleteErr error
+		expected  *ApiResponse
+	}{
+		{
+			desc: "Should success delete quota",
+			user: "admin@example.com",
+			expected: &ApiResponse{
+				code: http.StatusNoContent,
+			},
+		},
+		{
+			desc: "Should return 403 if user is not an administrator",
+			user: "user@example.com",
+			expected: &ApiResponse{
+				code: http.StatusForbidden,
+				data: Error{Message: "Only administrators can change project quotas"},
+			},
+		},
+		{
+			desc:      "Should return 500 if deleting quota failed",
+			user:      "admin@example.com",
+			deleteErr: fmt.Errorf("db is down"),
+			expected: &ApiResponse{
+				code: http.StatusInternalServerError,
+				data: Error{Message: "Error while deleting quota in environment env1"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			projectSvc := &mocks.ProjectsService{}
+			projectSvc.On("GetByID", mock.Anything, int32(1)).Return(mlp.Project{Id: 1, Name: "project"}, nil)
+			envSvc := &mocks.EnvironmentService{}
+			envSvc.On("GetEnvironment", "env1").Return(&models.Environment{Name: "env1"}, nil)
+			quotaSvc := &mocks.QuotaService{}
+			quotaSvc.On("DeleteQuota", models.Id(1), "env1").Return(tC.deleteErr)
+
+			ctl := &QuotasController{
+				AppContext: &AppContext{
+					ProjectsService:    projectSvc,
+					EnvironmentService: envSvc,
+					QuotaService:       quotaSvc,
+					AdminUsers:         []string{"admin@example.com"},
+				},
+			}
+			resp := ctl.DeleteQuota(httptest.NewRequest(http.MethodDelete, "/admin/projects/1/quotas/env1", nil), map[string]string{"project_id": "1", "environment_name": "env1", "user": tC.user}, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/gojek/mlp/api/pkg/instrumentation/newrelic"
 	"github.com/gojek/mlp/api/pkg/instrumentation/sentry"
 
+	"github.com/gojek/merlin/metrics"
 	"github.com/gojek/merlin/middleware"
 	"github.com/gojek/merlin/mlp"
 	"github.com/gojek/merlin/models"
@@ -37,21 +38,28 @@ import (
 )
 
 type AppContext struct {
-	EnvironmentService        service.EnvironmentService
-	ProjectsService           service.ProjectsService
-	ModelsService             service.ModelsService
-	ModelEndpointsService     service.ModelEndpointsService
-	VersionsService           service.VersionsService
-	EndpointsService          service.EndpointsService
-	LogService                service.LogService
-	PredictionJobService      service.PredictionJobService
-	SecretService             service.SecretService
-	ModelEndpointAlertService service.ModelEndpointAlertService
-	DB                        *gorm.DB
-	AuthorizationEnabled      bool
-	MonitoringConfig          config.MonitoringConfig
-	AlertEnabled              bool
-	Enforcer                  enforcer.Enforcer
+	EnvironmentService          service.EnvironmentService
+	ProjectsService             service.ProjectsService
+	ModelsService               service.ModelsService
+	ModelEndpointsService       service.ModelEndpointsService
+	VersionsService             service.VersionsService
+	EndpointsService            service.EndpointsService
+	DeploymentService           service.DeploymentService
+	QuotaService                service.QuotaService
+	CostService                 service.CostService
+	LogService                  service.LogService
+	PredictionJobService        service.PredictionJobService
+	SecretService               service.SecretService
+	ModelEndpointAlertService   service.ModelEndpointAlertService
+	ModelEndpointRolloutService service.ModelEndpointRolloutService
+	ScalingScheduleService      service.ScalingScheduleService
+	MetricsProvider             metrics.Provider
+	DB                          *gorm.DB
+	AuthorizationEnabled        bool
+	AdminUsers                  []string
+	MonitoringConfig            config.MonitoringConfig
+	AlertEnabled                bool
+	Enforcer                    enforcer.Enforcer
 }
 
 type ApiHandler func(r *http.Request, vars map[string]string, body interface{}) *ApiResponse
@@ -125,10 +133,16 @@ func NewRouter(appCtx AppContext) *mux.Router {
 	modelEndpointsController := ModelEndpointsController{&appCtx}
 	versionsController := VersionsController{&appCtx}
 	endpointsController := EndpointsController{&appCtx}
+	deploymentsController := DeploymentsController{&appCtx}
 	predictionJobController := PredictionJobController{&appCtx}
 	logController := LogController{&appCtx}
 	secretController := SecretsController{&appCtx}
+	quotasController := QuotasController{&appCtx}
+	costsController := CostsController{&appCtx}
 	alertsController := AlertsController{&appCtx}
+	rolloutsController := ModelEndpointRolloutsController{&appCtx}
+	metricsController := ModelEndpointMetricsController{&appCtx}
+	scalingSchedulesController := ScalingSchedulesController{&appCtx}
 
 	routes := []Route{
 		// Environment API
@@ -144,6 +158,12 @@ func NewRouter(appCtx AppContext) *mux.Router {
 		{http.MethodPatch, "/projects/{project_id:[0-9]+}/secrets/{secret_id}", mlp.Secret{}, secretController.UpdateSecret, "UpdateSecret"},
 		{http.MethodDelete, "/projects/{project_id:[0-9]+}/secrets/{secret_id}", nil, secretController.DeleteSecret, "DeleteSecret"},
 
+		// Quota API, quotas are changed through the admin resources by the admin users so that project members can't raise their own quotas
+		{http.MethodGet, "/projects/{project_id:[0-9]+}/quotas", nil, quotasController.ListQuotas, "ListQuotas"},
+		{http.MethodGet, "/projects/{project_id:[0-9]+}/quotas/{environment_name}", nil, quotasController.GetQuota, "GetQuota"},
+		{http.MethodPut, "/admin/projects/{project_id:[0-9]+}/quotas/{environment_name}", models.QuotaLimits{}, quotasController.SetQuota, "SetQuota"},
+		{http.MethodDelete, "/admin/projects/{project_id:[0-9]+}/quotas/{environment_name}", nil, quotasController.DeleteQuota, "DeleteQuota"},
+
 		// Model API
 		{http.MethodGet, "/projects/{project_id:[0-9]+}/models/{model_id:[0-9]+}", nil, modelsController.GetModel, "GetModel"},
 		{http.MethodGet, "/projects/{project_id:[0-9]+}/models", nil, modelsController.ListModels, "ListModels"},
@@ -156,6 +176,13 @@ func NewRouter(appCtx AppContext) *mux.Router {
 		{http.MethodGet, "/models/{model_id:[0-9]+}/endpoints/{model_endpoint_id}", nil, modelEndpointsController.GetModelEndpoint, "GetModelEndpoint"},
 		{http.MethodPut, "/models/{model_id:[0-9]+}/endpoints/{model_endpoint_id}", models.ModelEndpoint{}, modelEndpointsController.UpdateModelEndpoint, "UpdateModelEndpoint"},
 		{http.MethodDelete, "/models/{model_id:[0-9]+}/endpoints/{model_endpoint_id}", nil, modelEndpointsController.DeleteModelEndpoint, "DeleteModelEndpoint"},
+		{http.MethodGet, "/models/{model_id:[0-9]+}/endpoints/{model_endpoint_id}/shadows", nil, modelEndpointsController.ListModelEndpointShadows, "ListModelEndpointShadows"},
+
+		// Model Endpoint Rollouts API
+		{http.MethodGet, "/models/{model_id:[0-9]+}/endpoints/{model_endpoint_id}/rollouts", nil, rolloutsController.ListRollouts, "ListModelEndpointRollouts"},
+		{http.MethodPost, "/models/{model_id:[0-9]+}/endpoints/{model_endpoint_id}/rollouts", models.ModelEndpointRollout{}, rolloutsController.CreateRollout, "CreateModelEndpointRollout"},
+		{http.MethodGet, "/models/{model_id:[0-9]+}/endpoints/{model_endpoint_id}/rollouts/{rollout_id:[0-9]+}", nil, rolloutsController.GetRollout, "GetModelEndpointRollout"},
+		{http.MethodPut, "/models/{model_id:[0-9]+}/endpoints/{model_endpoint_id}/rollouts/{rollout_id:[0-9]+}/rollback", nil, rolloutsController.RollbackRollout, "RollbackModelEndpointRollout"},
 
 		// Version API
 		{http.MethodGet, "/models/{model_id:[0-9]+}/versions", nil, versionsController.ListVersions, "ListVersions"},
@@ -173,6 +200,23 @@ func NewRouter(appCtx AppContext) *mux.Router {
 		{http.MethodPut, "/models/{model_id:[0-9]+}/versions/{version_id:[0-9]+}/endpoint/{endpoint_id}", models.VersionEndpoint{}, endpointsController.UpdateEndpoint, "UpdateEndpoint"},
 		{http.MethodDelete, "/models/{model_id:[0-9]+}/versions/{version_id:[0-9]+}/endpoint/{endpoint_id}", nil, endpointsController.DeleteEndpoint, "DeleteEndpoint"},
 		{http.MethodGet, "/models/{model_id:[0-9]+}/versions/{version_id:[0-9]+}/endpoint/{endpoint_id}/containers", nil, endpointsController.ListContainers, "ListContainers"},
+		{http.MethodGet, "/models/{model_id:[0-9]+}/versions/{version_id:[0-9]+}/endpoint/{endpoint_id}/events", nil, endpointsController.ListEndpointEvents, "ListEndpointEvents"},
+		{http.MethodGet, "/models/{model_id:[0-9]+}/versions/{version_id:[0-9]+}/endpoint/{endpoint_id}/revisions", nil, endpointsController.ListEndpointRevisions, "ListEndpointRevisions"},
+		{http.MethodPut, "/models/{model_id:[0-9]+}/versions/{version_id:[0-9]+}/endpoint/{endpoint_id}/revisions/{revision:[0-9]+}/rollback", nil, endpointsController.RollbackEndpoint, "RollbackEndpoint"},
+
+		// Scaling Schedule API
+		{http.MethodGet, "/models/{model_id:[0-9]+}/versions/{version_id:[0-9]+}/endpoint/{endpoint_id}/scaling_schedules", nil, scalingSchedulesController.ListScalingSchedules, "ListScalingSchedules"},
+		{http.MethodPost, "/models/{model_id:[0-9]+}/versions/{version_id:[0-9]+}/endpoint/{endpoint_id}/scaling_schedules", models.ScalingSchedule{}, scalingSchedulesController.CreateScalingSchedule, "CreateScalingSchedule"},
+		{http.MethodPut, "/models/{model_id:[0-9]+}/versions/{version_id:[0-9]+}/endpoint/{endpoint_id}/scaling_schedules/{schedule_id:[0-9]+}", models.ScalingSchedule{}, scalingSchedulesController.UpdateScalingSchedule, "UpdateScalingSchedule"},
+		{http.MethodDelete, "/models/{model_id:[0-9]+}/versions/{version_id:[0-9]+}/endpoint/{endpoint_id}/scaling_schedules/{schedule_id:[0-9]+}", nil, scalingSchedulesController.DeleteScalingSchedule, "DeleteScalingSchedule"},
+
+		// Deployment History API
+		{http.MethodGet, "/models/{model_id:[0-9]+}/deployments", nil, deploymentsController.ListModelDeployments, "ListModelDeployments"},
+		{http.MethodGet, "/projects/{project_id:[0-9]+}/deployments", nil, deploymentsController.ListProjectDeployments, "ListProjectDeployments"},
+
+		// Cost Report API
+		{http.MethodGet, "/costs", nil, costsController.GetCostReport, "GetCostReport"},
+		{http.MethodGet, "/projects/{project_id:[0-9]+}/costs", nil, costsController.GetProjectCostReport, "GetProjectCostReport"},
 
 		// Prediction Job API
 		{http.MethodGet, "/projects/{project_id:[0-9]+}/jobs", nil, predictionJobController.ListAllInProject, "ListAllPredictionJobInProject"},
@@ -194,6 +238,13 @@ func NewRouter(appCtx AppContext) *mux.Router {
 		}...)
 	}
 
+	if appCtx.MetricsProvider != nil {
+		routes = append(routes, []Route{
+			// Model Endpoint Metrics API
+			{http.MethodGet, "/models/{model_id:[0-9]+}/endpoints/{model_endpoint_id}/metrics", nil, metricsController.GetModelEndpointMetrics, "GetModelEndpointMetrics"},
+		}...)
+	}
+
 	rawRoutes := []RawRoutes{
 		{
 			http.MethodGet, "/logs", http.HandlerFunc(logController.ReadLog), "ReadLogs",
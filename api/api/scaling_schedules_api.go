@@ -0,0 +1,185 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/models"
+)
+
+// ScalingSchedulesController controls the scaling schedules of version endpoints
+type ScalingSchedulesController struct {
+	*AppContext
+}
+
+// ListScalingSchedules list all scaling schedules of a version endpoint
+func (c *ScalingSchedulesController) ListScalingSchedules(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	_, endpoint, resp := c.getEndpoint(ctx, vars)
+	if resp != nil {
+		return resp
+	}
+
+	schedules, err := c.ScalingScheduleService.ListSchedules(ctx, endpoint.Id)
+	if err != nil {
+		log.Errorf("Error listing scaling schedules of version endpoint %s, reason: %v", endpoint.Id, err)
+		return InternalServerError(fmt.Sprintf("Error while getting scaling schedules of version endpoint %s", endpoint.Id))
+	}
+
+	return Ok(schedules)
+}
+
+// CreateScalingSchedule adds a schedule setting the min and max replicas of a version endpoint whenever its cron
+// expression matches
+func (c *ScalingSchedulesController) CreateScalingSchedule(r *http.Request, vars map[string]string, body interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	model, endpoint, resp := c.getEndpoint(ctx, vars)
+	if resp != nil {
+		return resp
+	}
+
+	schedule, ok := body.(*models.ScalingSchedule)
+	if !ok {
+		return BadRequest("Invalid request body")
+	}
+	schedule.Id = 0
+	schedule.LastAppliedAt = nil
+
+	return c.saveSchedule(ctx, model, endpoint, schedule, Created)
+}
+
+// UpdateScalingSchedule updates the cron expression, the time zone or the replicas of a scaling schedule
+func (c *ScalingSchedulesController) UpdateScalingSchedule(r *http.Request, vars map[string]string, body interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	model, endpoint, resp := c.getEndpoint(ctx, vars)
+	if resp != nil {
+		return resp
+	}
+
+	schedule, resp := c.getSchedule(ctx, endpoint, vars)
+	if resp != nil {
+		return resp
+	}
+
+	newSchedule, ok := body.(*models.ScalingSchedule)
+	if !ok {
+		return BadRequest("Invalid request body")
+	}
+	schedule.Schedule = newSchedule.Schedule
+	schedule.Timezone = newSchedule.Timezone
+	schedule.MinReplica = newSchedule.MinReplica
+	schedule.MaxReplica = newSchedule.MaxReplica
+
+	return c.saveSchedule(ctx, model, endpoint, schedule, Ok)
+}
+
+// DeleteScalingSchedule deletes a scaling schedule, the replicas of the version endpoint are left as is
+func (c *ScalingSchedulesController) DeleteScalingSchedule(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	_, endpoint, resp := c.getEndpoint(ctx, vars)
+	if resp != nil {
+		return resp
+	}
+
+	schedule, resp := c.getSchedule(ctx, endpoint, vars)
+	if resp != nil {
+		return resp
+	}
+
+	if err := c.ScalingScheduleService.DeleteSchedule(ctx, schedule); err != nil {
+		log.Errorf("Error deleting scaling schedule %s, reason: %v", schedule.Id, err)
+		return InternalServerError(fmt.Sprintf("Error while deleting scaling schedule %s", schedule.Id))
+	}
+
+	return NoContent()
+}
+
+func (c *ScalingSchedulesController) saveSchedule(ctx context.Context, model *models.Model, endpoint *models.VersionEndpoint, schedule *models.ScalingSchedule, response func(interface{}) *ApiResponse) *ApiResponse {
+	if err := schedule.Validate(); err != nil {
+		return BadRequest(fmt.Sprintf("Invalid scaling schedule: %s", err))
+	}
+
+	schedule, err := c.ScalingScheduleService.SaveSchedule(ctx, model, endpoint, schedule)
+	if err != nil {
+		var quotaErr *models.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			return BadRequest(fmt.Sprintf("Invalid scaling schedule: %s", quotaErr.Error()))
+		}
+		log.Errorf("Error saving scaling schedule of version endpoint %s, reason: %v", endpoint.Id, err)
+		return InternalServerError(fmt.Sprintf("Unable to save scaling schedule: %s", err))
+	}
+
+	return response(schedule)
+}
+
+// getEndpoint returns the model and the version endpoint of the request, or the response if they can't be found
+func (c *ScalingSchedulesController) getEndpoint(ctx context.Context, vars map[string]string) (*models.Model, *models.VersionEndpoint, *ApiResponse) {
+	modelId, _ := models.ParseId(vars["model_id"])
+	versionId, _ := models.ParseId(vars["version_id"])
+	endpointId, _ := uuid.Parse(vars["endpoint_id"])
+
+	model, err := c.ModelsService.FindById(ctx, modelId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil, NotFound(fmt.Sprintf("Model ID %s not found", modelId))
+		}
+		return nil, nil, InternalServerError(fmt.Sprintf("Error while getting Model ID %s", modelId))
+	}
+
+	endpoint, err := c.EndpointsService.FindById(endpointId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil, NotFound(fmt.Sprintf("Version endpoint with id %s not found", endpointId))
+		}
+		return nil, nil, InternalServerError(fmt.Sprintf("Error while getting version endpoint with id %s", endpointId))
+	}
+
+	if endpoint.VersionModelId != modelId || endpoint.VersionId != versionId {
+		return nil, nil, NotFound(fmt.Sprintf("Version endpoint with id %s not found", endpointId))
+	}
+
+	return model, endpoint, nil
+}
+
+// getSchedule returns the scaling schedule of the request, or the response if it can't be found
+func (c *ScalingSchedulesController) getSchedule(ctx context.Context, endpoint *models.VersionEndpoint, vars map[string]string) (*models.ScalingSchedule, *ApiResponse) {
+	scheduleId, _ := models.ParseId(vars["schedule_id"])
+
+	schedule, err := c.ScalingScheduleService.FindById(ctx, scheduleId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, NotFound(fmt.Sprintf("Scaling schedule with id %s not found", scheduleId))
+		}
+		return nil, InternalServerError(fmt.Sprintf("Error while getting scaling schedule with id %s", scheduleId))
+	}
+
+	if schedule.VersionEndpointId != endpoint.Id {
+		return nil, NotFound(fmt.Sprintf("Scaling schedule with id %s not found", scheduleId))
+	}
+
+	return schedule, nil
+}
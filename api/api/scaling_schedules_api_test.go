@@ -0,0 +1,228 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/service/mocks"
+)
+
+func TestListScalingSchedules(t *testing.T) {
+	endpointId := uuid.New()
+	model := &models.Model{Id: 1, Name: "model-1"}
+	endpoint := &models.VersionEndpoint{Id: endpointId, VersionId: 1, VersionModelId: 1}
+
+	testCases := []struct {
+		desc     string
+		vars     map[string]string
+		service  func() *mocks.ScalingScheduleService
+		expected *ApiResponse
+	}{
+		{
+			desc: "Should success list scaling schedules",
+			vars: map[string]string{"model_id": "1", "version_id": "1", "endpoint_id": endpointId.String()},
+			service: func() *mocks.ScalingScheduleService {
+				svc := &mocks.ScalingScheduleService{}
+				svc.On("ListSchedules", mock.Anything, endpointId).Return([]*models.ScalingSchedule{{Id: 1, VersionEndpointId: endpointId}}, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: []*models.ScalingSchedule{{Id: 1, VersionEndpointId: endpointId}},
+			},
+		},
+		{
+			desc: "Should return 404 if version endpoint belongs to another version",
+			vars: map[string]string{"model_id": "1", "version_id": "2", "endpoint_id": endpointId.String()},
+			service: func() *mocks.ScalingScheduleService {
+				return &mocks.ScalingScheduleService{}
+			},
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: fmt.Sprintf("Version endpoint with id %s not found", endpointId)},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			modelsService := &mocks.ModelsService{}
+			modelsService.On("FindById", mock.Anything, models.Id(1)).Return(model, nil)
+
+			endpointsService := &mocks.EndpointsService{}
+			endpointsService.On("FindById", endpointId).Return(endpoint, nil)
+
+			ctl := &ScalingSchedulesController{
+				AppContext: &AppContext{
+					ModelsService:          modelsService,
+					EndpointsService:       endpointsService,
+					ScalingScheduleService: tC.service(),
+				},
+			}
+			resp := ctl.ListScalingSchedules(&http.Request{}, tC.vars, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
+
+func TestCreateScalingSchedule(t *testing.T) {
+	endpointId := uuid.New()
+	model := &models.Model{Id: 1, Name: "model-1"}
+	endpoint := &models.VersionEndpoint{Id: endpointId, VersionId: 1, VersionModelId: 1}
+
+	testCases := []struct {
+		desc     string
+		body     *models.ScalingSchedule
+		service  func() *mocks.ScalingScheduleService
+		expected *ApiResponse
+	}{
+		{
+			desc: "Should success create scaling schedule",
+			body: &models.ScalingSchedule{Schedule: "0 7 * * 1-5", Timezone: "Asia/Jakarta", MinReplica: 4, MaxReplica: 10},
+			service: func() *mocks.ScalingScheduleService {
+				svc := &mocks.ScalingScheduleService{}
+				svc.On("SaveSchedule", mock.Anything, model, endpoint, mock.Anything).
+					Return(&models.ScalingSchedule{Id: 1, VersionEndpointId: endpointId, Schedule: "0 7 * * 1-5", Timezone: "Asia/Jakarta", MinReplica: 4, MaxReplica: 10}, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusCreated,
+				data: &models.ScalingSchedule{Id: 1, VersionEndpointId: endpointId, Schedule: "0 7 * * 1-5", Timezone: "Asia/Jakarta", MinReplica: 4, MaxReplica: 10},
+			},
+		},
+		{
+			desc: "Should return 400 if schedule is an interval",
+			body: &models.ScalingSchedule{Schedule: "@every 1h", MinReplica: 4, MaxReplica: 10},
+			service: func() *mocks.ScalingScheduleService {
+				return &mocks.ScalingScheduleService{}
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid scaling schedule: interval schedules aren't supported, use a cron expression instead"},
+			},
+		},
+		{
+			desc: "Should return 400 if quota is exceeded",
+			body: &models.ScalingSchedule{Schedule: "0 7 * * 1-5", MinReplica: 4, MaxReplica: 10},
+			service: func() *mocks.ScalingScheduleService {
+				svc := &mocks.ScalingScheduleService{}
+				svc.On("SaveSchedule", mock.Anything, model, endpoint, mock.Anything).
+					Return(nil, &models.QuotaExceededError{Resource: models.QuotaCpu, Usage: "2", RequestedUsage: "10", Limit: "8"})
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid scaling schedule: " + (&models.QuotaExceededError{Resource: models.QuotaCpu, Usage: "2", RequestedUsage: "10", Limit: "8"}).Error()},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			modelsService := &mocks.ModelsService{}
+			modelsService.On("FindById", mock.Anything, models.Id(1)).Return(model, nil)
+
+			endpointsService := &mocks.EndpointsService{}
+			endpointsService.On("FindById", endpointId).Return(endpoint, nil)
+
+			ctl := &ScalingSchedulesController{
+				AppContext: &AppContext{
+					ModelsService:          modelsService,
+					EndpointsService:       endpointsService,
+					ScalingScheduleService: tC.service(),
+				},
+			}
+			resp := ctl.CreateScalingSchedule(&http.Request{}, map[string]string{"model_id": "1", "version_id": "1", "endpoint_id": endpointId.String()}, tC.body)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
+
+func TestDeleteScalingSchedule(t *testing.T) {
+	endpointId := uuid.New()
+	model := &models.Model{Id: 1, Name: "model-1"}
+	endpoint := &models.VersionEndpoint{Id: endpointId, VersionId: 1, VersionModelId: 1}
+	schedule := &models.ScalingSchedule{Id: 1, VersionEndpointId: endpointId}
+
+	testCases := []struct {
+		desc     string
+		service  func() *mocks.ScalingScheduleService
+		expected *ApiResponse
+	}{
+		{
+			desc: "Should success delete scaling schedule",
+			service: func() *mocks.ScalingScheduleService {
+				svc := &mocks.ScalingScheduleService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(schedule, nil)
+				svc.On("DeleteSchedule", mock.Anything, schedule).Return(nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusNoContent,
+			},
+		},
+		{
+			desc: "Should return 404 if scaling schedule belongs to another version endpoint",
+			service: func() *mocks.ScalingScheduleService {
+				svc := &mocks.ScalingScheduleService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.ScalingSchedule{Id: 1, VersionEndpointId: uuid.New()}, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "Scaling schedule with id 1 not found"},
+			},
+		},
+		{
+			desc: "Should return 404 if scaling schedule not found",
+			service: func() *mocks.ScalingScheduleService {
+				svc := &mocks.ScalingScheduleService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(nil, gorm.ErrRecordNotFound)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: "Scaling schedule with id 1 not found"},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			modelsService := &mocks.ModelsService{}
+			modelsService.On("FindById", mock.Anything, models.Id(1)).Return(model, nil)
+
+			endpointsService := &mocks.EndpointsService{}
+			endpointsService.On("FindById", endpointId).Return(endpoint, nil)
+
+			ctl := &ScalingSchedulesController{
+				AppContext: &AppContext{
+					ModelsService:          modelsService,
+					EndpointsService:       endpointsService,
+					ScalingScheduleService: tC.service(),
+				},
+			}
+			vars := map[string]string{"model_id": "1", "version_id": "1", "endpoint_id": endpointId.String(), "schedule_id": "1"}
+			resp := ctl.DeleteScalingSchedule(&http.Request{}, vars, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
@@ -17,6 +17,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/jinzhu/gorm"
 
@@ -45,3 +46,9 @@ func (c *AppContext) getModelAndVersion(ctx context.Context, modelId models.Id,
 
 	return model, version, nil
 }
+
+// isDryRun returns true if the request only asks to render the resources it would apply, with ?dry_run=true
+func isDryRun(vars map[string]string) bool {
+	dryRun, _ := strconv.ParseBool(vars["dry_run"])
+	return dryRun
+}
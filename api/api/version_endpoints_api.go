@@ -15,15 +15,17 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
 	"github.com/prometheus/common/log"
 
-	"github.com/gojek/merlin/config"
 	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
 )
 
 type EndpointsController struct {
@@ -144,6 +146,44 @@ func (c *EndpointsController) CreateEndpoint(r *http.Request, vars map[string]st
 		}
 
 		newEndpoint.EnvironmentName = env.Name
+
+		if newEndpoint.AutoscalingPolicy != nil {
+			if err := newEndpoint.AutoscalingPolicy.Validate(); err != nil {
+				return BadRequest(fmt.Sprintf("Invalid autoscaling policy: %s", err))
+			}
+		}
+
+		if err := newEndpoint.EnvVars.Validate(); err != nil {
+			return BadRequest(fmt.Sprintf("Invalid environment variables: %s", err))
+		}
+
+		if newEndpoint.Transformer != nil {
+			if err := newEndpoint.Transformer.Validate(); err != nil {
+				return BadRequest(fmt.Sprintf("Invalid transformer: %s", err))
+			}
+		}
+
+		if newEndpoint.Explainer != nil {
+			if err := newEndpoint.Explainer.Validate(); err != nil {
+				return BadRequest(fmt.Sprintf("Invalid explainer: %s", err))
+			}
+		}
+
+		if err := c.validateLifetime(env, newEndpoint); err != nil {
+			return BadRequest(fmt.Sprintf("Invalid lifetime: %s", err))
+		}
+	}
+
+	handler, err := modeltype.Get(model.Type)
+	if err != nil {
+		return BadRequest(fmt.Sprintf("Unsupported model type: %s", model.Type))
+	}
+	if !handler.SupportsOnline() {
+		return BadRequest(fmt.Sprintf("Model type %s can't be deployed as a version endpoint", model.Type))
+	}
+
+	if err := handler.ValidateOptions(handler.ModelOption(version)); err != nil {
+		return BadRequest(fmt.Sprintf("Invalid model version: %s", err))
 	}
 
 	// check that the endpoint is not deployed nor deploying
@@ -153,15 +193,17 @@ func (c *EndpointsController) CreateEndpoint(r *http.Request, vars map[string]st
 			fmt.Sprintf("There is `%s` deployment for the model version", endpoint.Status))
 	}
 
-	// check that the model version quota
-	deployedModelVersionCount, err := c.EndpointsService.CountEndpoints(env, model)
-	if deployedModelVersionCount >= config.MaxDeployedVersion {
-		return BadRequest(fmt.Sprintf("Max deployed endpoint reached. Max: %d Current: %d ", config.MaxDeployedVersion, deployedModelVersionCount))
+	if isDryRun(vars) {
+		result, err := c.EndpointsService.RenderEndpoint(env, model, version, newEndpoint)
+		if err != nil {
+			return deployEndpointError(err)
+		}
+		return Ok(result)
 	}
 
-	endpoint, err = c.EndpointsService.DeployEndpoint(env, model, version, newEndpoint)
+	endpoint, err = c.EndpointsService.DeployEndpoint(env, model, version, newEndpoint, vars["user"])
 	if err != nil {
-		return InternalServerError(fmt.Sprintf("Unable to deploy model version: %s", err.Error()))
+		return deployEndpointError(err)
 	}
 
 	return Created(endpoint)
@@ -210,9 +252,21 @@ func (c *EndpointsController) UpdateEndpoint(r *http.Request, vars map[string]st
 	}
 
 	if newEndpoint.Status == models.EndpointRunning || newEndpoint.Status == models.EndpointServing {
-		endpoint, err = c.EndpointsService.DeployEndpoint(env, model, version, newEndpoint)
+		if err := c.validateLifetime(env, newEndpoint); err != nil {
+			return BadRequest(fmt.Sprintf("Invalid lifetime: %s", err))
+		}
+
+		if isDryRun(vars) {
+			result, err := c.EndpointsService.RenderEndpoint(env, model, version, newEndpoint)
+			if err != nil {
+				return deployEndpointError(err)
+			}
+			return Ok(result)
+		}
+
+		endpoint, err = c.EndpointsService.DeployEndpoint(env, model, version, newEndpoint, vars["user"])
 		if err != nil {
-			return InternalServerError(fmt.Sprintf("Unable to deploy model version: %s", err.Error()))
+			return deployEndpointError(err)
 		}
 	} else if newEndpoint.Status == models.EndpointTerminated {
 		endpoint, err = c.EndpointsService.UndeployEndpoint(env, model, version, endpoint)
@@ -312,6 +366,148 @@ func (c *EndpointsController) ListContainers(r *http.Request, vars map[string]st
 	return Ok(endpoint)
 }
 
+func (c *EndpointsController) ListEndpointEvents(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	modelId, _ := models.ParseId(vars["model_id"])
+	versionId, _ := models.ParseId(vars["version_id"])
+	endpointId, _ := uuid.Parse(vars["endpoint_id"])
+
+	_, err := c.ModelsService.FindById(ctx, modelId)
+	if err != nil {
+		return NotFound(fmt.Sprintf("Model with given `model_id: %d` not found", modelId))
+	}
+
+	_, err = c.VersionsService.FindById(ctx, modelId, versionId, c.MonitoringConfig)
+	if err != nil {
+		return NotFound(fmt.Sprintf("Version with given `version_id: %d` not found", versionId))
+	}
+
+	_, err = c.EndpointsService.FindById(endpointId)
+	if err != nil {
+		log.Errorf("Error finding version endpoint with id %s, reason: %v", endpointId, err)
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Version endpoint with id %s not found", endpointId))
+		}
+		return InternalServerError(fmt.Sprintf("Error while getting version endpoint with id %s", endpointId))
+	}
+
+	events, err := c.EndpointsService.ListEndpointEvents(endpointId)
+	if err != nil {
+		log.Errorf("Error listing events of endpoint %s, reason: %v", endpointId, err)
+		return InternalServerError(fmt.Sprintf("Error listing events of endpoint with id %s", endpointId))
+	}
+	return Ok(events)
+}
+
+func (c *EndpointsController) ListEndpointRevisions(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	modelId, _ := models.ParseId(vars["model_id"])
+	versionId, _ := models.ParseId(vars["version_id"])
+	endpointId, _ := uuid.Parse(vars["endpoint_id"])
+
+	_, _, err := c.getModelAndVersion(ctx, modelId, versionId)
+	if err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			return InternalServerError(err.Error())
+		}
+		return NotFound(err.Error())
+	}
+
+	endpoint, err := c.EndpointsService.FindById(endpointId)
+	if err != nil {
+		log.Errorf("Error finding version endpoint with id %s, reason: %v", endpointId, err)
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Version endpoint with id %s not found", endpointId))
+		}
+		return InternalServerError(fmt.Sprintf("Error while getting version endpoint with id %s", endpointId))
+	}
+	if endpoint.VersionModelId != modelId || endpoint.VersionId != versionId {
+		return NotFound(fmt.Sprintf("Version endpoint with id %s not found", endpointId))
+	}
+
+	revisions, err := c.EndpointsService.ListRevisions(endpointId)
+	if err != nil {
+		log.Errorf("Error listing revisions of endpoint %s, reason: %v", endpointId, err)
+		return InternalServerError(fmt.Sprintf("Error listing revisions of endpoint with id %s", endpointId))
+	}
+	return Ok(revisions)
+}
+
+// RollbackEndpoint redeploys the version endpoint with the spec of one of its revisions.
+func (c *EndpointsController) RollbackEndpoint(r *http.Request, vars map[string]string, _ interface{}) *ApiResponse {
+	ctx := r.Context()
+
+	modelId, _ := models.ParseId(vars["model_id"])
+	versionId, _ := models.ParseId(vars["version_id"])
+	endpointId, _ := uuid.Parse(vars["endpoint_id"])
+	revision, _ := strconv.Atoi(vars["revision"])
+
+	model, version, err := c.getModelAndVersion(ctx, modelId, versionId)
+	if err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			return InternalServerError(err.Error())
+		}
+		return NotFound(err.Error())
+	}
+
+	endpoint, err := c.EndpointsService.FindById(endpointId)
+	if err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			return InternalServerError(fmt.Sprintf("Error finding endpoint with ID: %s", endpointId))
+		}
+		return NotFound(fmt.Sprintf("Version endpoint with id %s not found", endpointId))
+	}
+	if endpoint.VersionModelId != modelId || endpoint.VersionId != versionId {
+		return NotFound(fmt.Sprintf("Version endpoint with id %s not found", endpointId))
+	}
+
+	if endpoint.Status == models.EndpointTerminated {
+		return BadRequest(fmt.Sprintf("Version endpoint %s is terminated, please redeploy it instead", endpointId))
+	}
+
+	deploying, err := c.EndpointsService.IsDeploying(endpoint)
+	if err != nil {
+		return InternalServerError(fmt.Sprintf("Unable to check deployment of version endpoint %s", endpointId))
+	}
+	if deploying {
+		return BadRequest(fmt.Sprintf("Version endpoint %s is being deployed, please wait until the deployment is finished", endpointId))
+	}
+
+	endpoint, err = c.EndpointsService.RollbackEndpoint(model, version, endpoint, revision, vars["user"])
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return NotFound(fmt.Sprintf("Revision %d of version endpoint %s not found", revision, endpointId))
+		}
+		log.Errorf("Error rolling back endpoint %s to revision %d, reason: %v", endpointId, revision, err)
+		return deployEndpointError(err)
+	}
+	return Ok(endpoint)
+}
+
+// deployEndpointError returns the response to a failed deployment, or dry run, of a version endpoint.
+func deployEndpointError(err error) *ApiResponse {
+	var quotaErr *models.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		return BadRequest(fmt.Sprintf("Unable to deploy model version: %s", quotaErr.Error()))
+	}
+	var invalidErr *models.InvalidEndpointError
+	if errors.As(err, &invalidErr) {
+		return BadRequest(fmt.Sprintf("Unable to deploy model version: %s", invalidErr.Error()))
+	}
+	return InternalServerError(fmt.Sprintf("Unable to deploy model version: %s", err.Error()))
+}
+
+// validateLifetime checks the ttl and the idle timeout of the endpoint against the environment. The idle timeout is
+// rejected when no metrics provider is configured, since the traffic of the endpoint can't be measured to enforce it.
+func (c *EndpointsController) validateLifetime(env *models.Environment, endpoint *models.VersionEndpoint) error {
+	if endpoint.IdleTimeout != "" && c.MetricsProvider == nil {
+		return fmt.Errorf("idle timeout %s can't be enforced without a metrics provider", endpoint.IdleTimeout)
+	}
+	return env.EndpointLifetime.Validate(endpoint)
+}
+
 func validateUpdateRequest(prev *models.VersionEndpoint, new *models.VersionEndpoint) error {
 	if prev.EnvironmentName != new.EnvironmentName {
 		return fmt.Errorf("Updating environment is not allowed, previous: %s, new: %s", prev.EnvironmentName, new.EnvironmentName)
@@ -327,5 +523,27 @@ func validateUpdateRequest(prev *models.VersionEndpoint, new *models.VersionEndp
 		}
 	}
 
+	if new.AutoscalingPolicy != nil {
+		if err := new.AutoscalingPolicy.Validate(); err != nil {
+			return fmt.Errorf("Invalid autoscaling policy: %s", err)
+		}
+	}
+
+	if err := new.EnvVars.Validate(); err != nil {
+		return fmt.Errorf("Invalid environment variables: %s", err)
+	}
+
+	if new.Transformer != nil {
+		if err := new.Transformer.Validate(); err != nil {
+			return fmt.Errorf("Invalid transformer: %s", err)
+		}
+	}
+
+	if new.Explainer != nil {
+		if err := new.Explainer.Validate(); err != nil {
+			return fmt.Errorf("Invalid explainer: %s", err)
+		}
+	}
+
 	return nil
 }
@@ -21,6 +21,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/resource"
 
+	"github.com/gojek/merlin/cluster"
 	"github.com/gojek/merlin/config"
 	"github.com/gojek/merlin/mlp"
 	"github.com/gojek/merlin/models"
@@ -774,8 +775,7 @@ func TestCreateEndpoint(t *testing.T) {
 			},
 			endpointService: func() *mocks.EndpointsService {
 				svc := &mocks.EndpointsService{}
-				svc.On("CountEndpoints", mock.Anything, mock.Anything).Return(0, nil)
-				svc.On("DeployEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.VersionEndpoint{
+				svc.On("DeployEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.VersionEndpoint{
 					Id:                   uuid,
 					VersionId:            models.Id(1),
 					VersionModelId:       models.Id(1),
@@ -932,8 +932,7 @@ func TestCreateEndpoint(t *testing.T) {
 			},
 			endpointService: func() *mocks.EndpointsService {
 				svc := &mocks.EndpointsService{}
-				svc.On("CountEndpoints", mock.Anything, mock.Anything).Return(0, nil)
-				svc.On("DeployEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.VersionEndpoint{
+				svc.On("DeployEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.VersionEndpoint{
 					Id:                   uuid,
 					VersionId:            models.Id(1),
 					VersionModelId:       models.Id(1),
@@ -1272,11 +1271,913 @@ func TestCreateEndpoint(t *testing.T) {
 			},
 		},
 		{
-			desc: "Should return 400 if deployed endpoint is more than limit",
+			desc: "Should return 400 if autoscaling policy is invalid",
 			vars: map[string]string{
 				"model_id":   "1",
 				"version_id": "1",
 			},
+			requestBody: &models.VersionEndpoint{
+				Id:              uuid,
+				VersionId:       models.Id(1),
+				VersionModelId:  models.Id(1),
+				ServiceName:     "sample",
+				Namespace:       "sample",
+				EnvironmentName: "dev",
+				Message:         "",
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+				AutoscalingPolicy: &models.AutoscalingPolicy{
+					MetricType:  models.AutoscalingMetricCPU,
+					TargetValue: 50,
+					ScaleToZero: true,
+				},
+			},
+			modelService: func() *mocks.ModelsService {
+				svc := &mocks.ModelsService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{
+					Id:           models.Id(1),
+					Name:         "model-1",
+					ProjectId:    models.Id(1),
+					Project:      mlp.Project{},
+					ExperimentId: 1,
+					Type:         "pyfunc",
+					MlflowUrl:    "",
+					Endpoints:    nil,
+				}, nil)
+				return svc
+			},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{
+					Id:      models.Id(1),
+					ModelId: models.Id(1),
+					Model: &models.Model{
+						Id:           models.Id(1),
+						Name:         "model-1",
+						ProjectId:    models.Id(1),
+						Project:      mlp.Project{},
+						ExperimentId: 1,
+						Type:         "pyfunc",
+						MlflowUrl:    "",
+						Endpoints:    nil,
+					},
+				}, nil)
+				return svc
+			},
+			envService: func() *mocks.EnvironmentService {
+				svc := &mocks.EnvironmentService{}
+				svc.On("GetDefaultEnvironment").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				svc.On("GetEnvironment", "dev").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				return svc
+			},
+			endpointService: func() *mocks.EndpointsService {
+				svc := &mocks.EndpointsService{}
+				return svc
+			},
+			monitoringConfig: config.MonitoringConfig{
+				MonitoringEnabled: true,
+				MonitoringBaseURL: "http://grafana",
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid autoscaling policy: scale to zero is not supported with cpu metric"},
+			},
+		},
+		{
+			desc: "Should return 400 if transformer is invalid",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+			},
+			requestBody: &models.VersionEndpoint{
+				Id:              uuid,
+				VersionId:       models.Id(1),
+				VersionModelId:  models.Id(1),
+				ServiceName:     "sample",
+				Namespace:       "sample",
+				EnvironmentName: "dev",
+				Message:         "",
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+				Transformer: &models.Transformer{
+					Enabled:         true,
+					TransformerType: models.CustomTransformerType,
+				},
+			},
+			modelService: func() *mocks.ModelsService {
+				svc := &mocks.ModelsService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{
+					Id:           models.Id(1),
+					Name:         "model-1",
+					ProjectId:    models.Id(1),
+					Project:      mlp.Project{},
+					ExperimentId: 1,
+					Type:         "pyfunc",
+					MlflowUrl:    "",
+					Endpoints:    nil,
+				}, nil)
+				return svc
+			},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{
+					Id:      models.Id(1),
+					ModelId: models.Id(1),
+					Model: &models.Model{
+						Id:           models.Id(1),
+						Name:         "model-1",
+						ProjectId:    models.Id(1),
+						Project:      mlp.Project{},
+						ExperimentId: 1,
+						Type:         "pyfunc",
+						MlflowUrl:    "",
+						Endpoints:    nil,
+					},
+				}, nil)
+				return svc
+			},
+			envService: func() *mocks.EnvironmentService {
+				svc := &mocks.EnvironmentService{}
+				svc.On("GetDefaultEnvironment").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				svc.On("GetEnvironment", "dev").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				return svc
+			},
+			endpointService: func() *mocks.EndpointsService {
+				svc := &mocks.EndpointsService{}
+				return svc
+			},
+			monitoringConfig: config.MonitoringConfig{
+				MonitoringEnabled: true,
+				MonitoringBaseURL: "http://grafana",
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid transformer: custom transformer requires an image"},
+			},
+		},
+		{
+			desc: "Should return 400 if custom model version has no custom predictor",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+			},
+			requestBody: &models.VersionEndpoint{
+				Id:              uuid,
+				VersionId:       models.Id(1),
+				VersionModelId:  models.Id(1),
+				ServiceName:     "sample",
+				Namespace:       "sample",
+				EnvironmentName: "dev",
+				Message:         "",
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+			},
+			modelService: func() *mocks.ModelsService {
+				svc := &mocks.ModelsService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{
+					Id:           models.Id(1),
+					Name:         "model-1",
+					ProjectId:    models.Id(1),
+					Project:      mlp.Project{},
+					ExperimentId: 1,
+					Type:         "custom",
+					MlflowUrl:    "",
+					Endpoints:    nil,
+				}, nil)
+				return svc
+			},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{
+					Id:      models.Id(1),
+					ModelId: models.Id(1),
+					Model: &models.Model{
+						Id:           models.Id(1),
+						Name:         "model-1",
+						ProjectId:    models.Id(1),
+						Project:      mlp.Project{},
+						ExperimentId: 1,
+						Type:         "custom",
+						MlflowUrl:    "",
+						Endpoints:    nil,
+					},
+				}, nil)
+				return svc
+			},
+			envService: func() *mocks.EnvironmentService {
+				svc := &mocks.EnvironmentService{}
+				svc.On("GetDefaultEnvironment").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				svc.On("GetEnvironment", "dev").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				return svc
+			},
+			endpointService: func() *mocks.EndpointsService {
+				svc := &mocks.EndpointsService{}
+				return svc
+			},
+			monitoringConfig: config.MonitoringConfig{
+				MonitoringEnabled: true,
+				MonitoringBaseURL: "http://grafana",
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid model version: custom model version requires a custom predictor"},
+			},
+		},
+		{
+			desc: "Should return 400 if model type is batch only",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+			},
+			requestBody: &models.VersionEndpoint{
+				Id:              uuid,
+				VersionId:       models.Id(1),
+				VersionModelId:  models.Id(1),
+				ServiceName:     "sample",
+				Namespace:       "sample",
+				EnvironmentName: "dev",
+				Message:         "",
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+			},
+			modelService: func() *mocks.ModelsService {
+				svc := &mocks.ModelsService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{
+					Id:           models.Id(1),
+					Name:         "model-1",
+					ProjectId:    models.Id(1),
+					Project:      mlp.Project{},
+					ExperimentId: 1,
+					Type:         "pyfunc_v2",
+					MlflowUrl:    "",
+					Endpoints:    nil,
+				}, nil)
+				return svc
+			},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{
+					Id:      models.Id(1),
+					ModelId: models.Id(1),
+					Model: &models.Model{
+						Id:           models.Id(1),
+						Name:         "model-1",
+						ProjectId:    models.Id(1),
+						Project:      mlp.Project{},
+						ExperimentId: 1,
+						Type:         "pyfunc_v2",
+						MlflowUrl:    "",
+						Endpoints:    nil,
+					},
+				}, nil)
+				return svc
+			},
+			envService: func() *mocks.EnvironmentService {
+				svc := &mocks.EnvironmentService{}
+				svc.On("GetDefaultEnvironment").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				svc.On("GetEnvironment", "dev").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				return svc
+			},
+			endpointService: func() *mocks.EndpointsService {
+				svc := &mocks.EndpointsService{}
+				return svc
+			},
+			monitoringConfig: config.MonitoringConfig{
+				MonitoringEnabled: true,
+				MonitoringBaseURL: "http://grafana",
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Model type pyfunc_v2 can't be deployed as a version endpoint"},
+			},
+		},
+		{
+			desc: "Should return 400 if explainer is invalid",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+			},
+			requestBody: &models.VersionEndpoint{
+				Id:              uuid,
+				VersionId:       models.Id(1),
+				VersionModelId:  models.Id(1),
+				ServiceName:     "sample",
+				Namespace:       "sample",
+				EnvironmentName: "dev",
+				Message:         "",
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+				Explainer: &models.Explainer{
+					Enabled:       true,
+					ExplainerType: models.AnchorTabularExplainerType,
+					Image:         "gojek/explainer:1.0.0",
+				},
+			},
+			modelService: func() *mocks.ModelsService {
+				svc := &mocks.ModelsService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{
+					Id:           models.Id(1),
+					Name:         "model-1",
+					ProjectId:    models.Id(1),
+					Project:      mlp.Project{},
+					ExperimentId: 1,
+					Type:         "pyfunc",
+					MlflowUrl:    "",
+					Endpoints:    nil,
+				}, nil)
+				return svc
+			},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{
+					Id:      models.Id(1),
+					ModelId: models.Id(1),
+					Model: &models.Model{
+						Id:           models.Id(1),
+						Name:         "model-1",
+						ProjectId:    models.Id(1),
+						Project:      mlp.Project{},
+						ExperimentId: 1,
+						Type:         "pyfunc",
+						MlflowUrl:    "",
+						Endpoints:    nil,
+					},
+				}, nil)
+				return svc
+			},
+			envService: func() *mocks.EnvironmentService {
+				svc := &mocks.EnvironmentService{}
+				svc.On("GetDefaultEnvironment").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				svc.On("GetEnvironment", "dev").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				return svc
+			},
+			endpointService: func() *mocks.EndpointsService {
+				svc := &mocks.EndpointsService{}
+				return svc
+			},
+			monitoringConfig: config.MonitoringConfig{
+				MonitoringEnabled: true,
+				MonitoringBaseURL: "http://grafana",
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid explainer: alibi explainer image can't be specified"},
+			},
+		},
+		{
+			desc: "Should return 400 if ttl exceeds the maximum of the environment",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+			},
+			requestBody: &models.VersionEndpoint{
+				Id:              uuid,
+				VersionId:       models.Id(1),
+				VersionModelId:  models.Id(1),
+				ServiceName:     "sample",
+				Namespace:       "sample",
+				EnvironmentName: "dev",
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+				TTL: "100h",
+			},
+			modelService: func() *mocks.ModelsService {
+				svc := &mocks.ModelsService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{
+					Id:        models.Id(1),
+					Name:      "model-1",
+					ProjectId: models.Id(1),
+					Type:      "pyfunc",
+				}, nil)
+				return svc
+			},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{
+					Id:      models.Id(1),
+					ModelId: models.Id(1),
+				}, nil)
+				return svc
+			},
+			envService: func() *mocks.EnvironmentService {
+				svc := &mocks.EnvironmentService{}
+				svc.On("GetDefaultEnvironment").Return(&models.Environment{
+					Id:        models.Id(1),
+					Name:      "dev",
+					Cluster:   "dev",
+					IsDefault: &trueBoolean,
+				}, nil)
+				svc.On("GetEnvironment", "dev").Return(&models.Environment{
+					Id:               models.Id(1),
+					Name:             "dev",
+					Cluster:          "dev",
+					IsDefault:        &trueBoolean,
+					MaxCpu:           "1",
+					MaxMemory:        "1Gi",
+					EndpointLifetime: &models.EndpointLifetime{MaxTTL: "72h"},
+				}, nil)
+				return svc
+			},
+			endpointService: func() *mocks.EndpointsService {
+				svc := &mocks.EndpointsService{}
+				return svc
+			},
+			monitoringConfig: config.MonitoringConfig{},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid lifetime: ttl 100h exceeds the maximum of the environment: 72h"},
+			},
+		},
+		{
+			desc: "Should return 400 if idle timeout is set without a metrics provider",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+			},
+			requestBody: &models.VersionEndpoint{
+				Id:              uuid,
+				VersionId:       models.Id(1),
+				VersionModelId:  models.Id(1),
+				ServiceName:     "sample",
+				Namespace:       "sample",
+				EnvironmentName: "dev",
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+				IdleTimeout: "2h",
+			},
+			modelService: func() *mocks.ModelsService {
+				svc := &mocks.ModelsService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{
+					Id:        models.Id(1),
+					Name:      "model-1",
+					ProjectId: models.Id(1),
+					Type:      "pyfunc",
+				}, nil)
+				return svc
+			},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{
+					Id:      models.Id(1),
+					ModelId: models.Id(1),
+				}, nil)
+				return svc
+			},
+			envService: func() *mocks.EnvironmentService {
+				svc := &mocks.EnvironmentService{}
+				svc.On("GetDefaultEnvironment").Return(&models.Environment{
+					Id:        models.Id(1),
+					Name:      "dev",
+					Cluster:   "dev",
+					IsDefault: &trueBoolean,
+				}, nil)
+				svc.On("GetEnvironment", "dev").Return(&models.Environment{
+					Id:        models.Id(1),
+					Name:      "dev",
+					Cluster:   "dev",
+					IsDefault: &trueBoolean,
+					MaxCpu:    "1",
+					MaxMemory: "1Gi",
+				}, nil)
+				return svc
+			},
+			endpointService: func() *mocks.EndpointsService {
+				svc := &mocks.EndpointsService{}
+				return svc
+			},
+			monitoringConfig: config.MonitoringConfig{},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid lifetime: idle timeout 2h can't be enforced without a metrics provider"},
+			},
+		},
+		{
+			desc: "Should return 400 if the project quota is exceeded",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+			},
+			requestBody: &models.VersionEndpoint{
+				Id:              uuid,
+				VersionId:       models.Id(1),
+				VersionModelId:  models.Id(1),
+				ServiceName:     "sample",
+				Namespace:       "sample",
+				EnvironmentName: "dev",
+				Message:         "",
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+				EnvVars: models.EnvVars([]models.EnvVar{
+					{
+						Name:  "WORKER",
+						Value: "1",
+					},
+				}),
+			},
+			modelService: func() *mocks.ModelsService {
+				svc := &mocks.ModelsService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{
+					Id:           models.Id(1),
+					Name:         "model-1",
+					ProjectId:    models.Id(1),
+					Project:      mlp.Project{},
+					ExperimentId: 1,
+					Type:         "pyfunc",
+					MlflowUrl:    "",
+					Endpoints:    nil,
+				}, nil)
+				return svc
+			},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{
+					Id:      models.Id(1),
+					ModelId: models.Id(1),
+					Model: &models.Model{
+						Id:           models.Id(1),
+						Name:         "model-1",
+						ProjectId:    models.Id(1),
+						Project:      mlp.Project{},
+						ExperimentId: 1,
+						Type:         "pyfunc",
+						MlflowUrl:    "",
+						Endpoints:    nil,
+					},
+				}, nil)
+				return svc
+			},
+			envService: func() *mocks.EnvironmentService {
+				svc := &mocks.EnvironmentService{}
+				svc.On("GetDefaultEnvironment").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				svc.On("GetEnvironment", "dev").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				return svc
+			},
+			endpointService: func() *mocks.EndpointsService {
+				svc := &mocks.EndpointsService{}
+				svc.On("DeployEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, &models.QuotaExceededError{Resource: models.QuotaEndpoints, Usage: "5", RequestedUsage: "6", Limit: "5"})
+				return svc
+			},
+			monitoringConfig: config.MonitoringConfig{
+				MonitoringEnabled: true,
+				MonitoringBaseURL: "http://grafana",
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Unable to deploy model version: project quota of endpoints exceeded: current usage is 5, requested usage is 6, limit is 5"},
+			},
+		},
+		{
+			desc: "Should return 400 if the endpoint exceeds the resources of the environment",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+			},
+			requestBody: &models.VersionEndpoint{
+				Id:              uuid,
+				VersionId:       models.Id(1),
+				VersionModelId:  models.Id(1),
+				ServiceName:     "sample",
+				Namespace:       "sample",
+				EnvironmentName: "dev",
+				Message:         "",
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+				EnvVars: models.EnvVars([]models.EnvVar{
+					{
+						Name:  "WORKER",
+						Value: "1",
+					},
+				}),
+			},
+			modelService: func() *mocks.ModelsService {
+				svc := &mocks.ModelsService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{
+					Id:           models.Id(1),
+					Name:         "model-1",
+					ProjectId:    models.Id(1),
+					Project:      mlp.Project{},
+					ExperimentId: 1,
+					Type:         "pyfunc",
+					MlflowUrl:    "",
+					Endpoints:    nil,
+				}, nil)
+				return svc
+			},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{
+					Id:      models.Id(1),
+					ModelId: models.Id(1),
+					Model: &models.Model{
+						Id:           models.Id(1),
+						Name:         "model-1",
+						ProjectId:    models.Id(1),
+						Project:      mlp.Project{},
+						ExperimentId: 1,
+						Type:         "pyfunc",
+						MlflowUrl:    "",
+						Endpoints:    nil,
+					},
+				}, nil)
+				return svc
+			},
+			envService: func() *mocks.EnvironmentService {
+				svc := &mocks.EnvironmentService{}
+				svc.On("GetDefaultEnvironment").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				svc.On("GetEnvironment", "dev").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				return svc
+			},
+			endpointService: func() *mocks.EndpointsService {
+				svc := &mocks.EndpointsService{}
+				svc.On("DeployEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, models.NewInvalidEndpointError("invalid endpoint configuration: %v", cluster.ErrInsufficientCpu))
+				return svc
+			},
+			monitoringConfig: config.MonitoringConfig{
+				MonitoringEnabled: true,
+				MonitoringBaseURL: "http://grafana",
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Unable to deploy model version: invalid endpoint configuration: CPU request is too large"},
+			},
+		},
+		{
+			desc: "Should render manifests without deploying on dry run",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+				"dry_run":    "true",
+			},
+			requestBody: &models.VersionEndpoint{
+				Id:              uuid,
+				VersionId:       models.Id(1),
+				VersionModelId:  models.Id(1),
+				ServiceName:     "sample",
+				Namespace:       "sample",
+				EnvironmentName: "dev",
+				Message:         "",
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+				EnvVars: models.EnvVars([]models.EnvVar{
+					{
+						Name:  "WORKER",
+						Value: "1",
+					},
+				}),
+			},
+			modelService: func() *mocks.ModelsService {
+				svc := &mocks.ModelsService{}
+				svc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{
+					Id:           models.Id(1),
+					Name:         "model-1",
+					ProjectId:    models.Id(1),
+					Project:      mlp.Project{},
+					ExperimentId: 1,
+					Type:         "pyfunc",
+					MlflowUrl:    "",
+					Endpoints:    nil,
+				}, nil)
+				return svc
+			},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{
+					Id:      models.Id(1),
+					ModelId: models.Id(1),
+					Model: &models.Model{
+						Id:           models.Id(1),
+						Name:         "model-1",
+						ProjectId:    models.Id(1),
+						Project:      mlp.Project{},
+						ExperimentId: 1,
+						Type:         "pyfunc",
+						MlflowUrl:    "",
+						Endpoints:    nil,
+					},
+				}, nil)
+				return svc
+			},
+			envService: func() *mocks.EnvironmentService {
+				svc := &mocks.EnvironmentService{}
+				svc.On("GetDefaultEnvironment").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				svc.On("GetEnvironment", "dev").Return(&models.Environment{
+					Id:         models.Id(1),
+					Name:       "dev",
+					Cluster:    "dev",
+					IsDefault:  &trueBoolean,
+					Region:     "id",
+					GcpProject: "dev-proj",
+					MaxCpu:     "1",
+					MaxMemory:  "1Gi",
+				}, nil)
+				return svc
+			},
+			endpointService: func() *mocks.EndpointsService {
+				svc := &mocks.EndpointsService{}
+				svc.On("RenderEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.DryRunResult{
+					Manifests: []*models.Manifest{
+						{Kind: "InferenceService", Name: "model-1-1", Namespace: "sample", Yaml: "kind: InferenceService\n"},
+					},
+				}, nil)
+				return svc
+			},
+			monitoringConfig: config.MonitoringConfig{
+				MonitoringEnabled: true,
+				MonitoringBaseURL: "http://grafana",
+			},
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: &models.DryRunResult{
+					Manifests: []*models.Manifest{
+						{Kind: "InferenceService", Name: "model-1-1", Namespace: "sample", Yaml: "kind: InferenceService\n"},
+					},
+				},
+			},
+		},
+		{
+			desc: "Should return 400 if dry run validation failed",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+				"dry_run":    "true",
+			},
 			requestBody: &models.VersionEndpoint{
 				Id:              uuid,
 				VersionId:       models.Id(1),
@@ -1356,7 +2257,8 @@ func TestCreateEndpoint(t *testing.T) {
 			},
 			endpointService: func() *mocks.EndpointsService {
 				svc := &mocks.EndpointsService{}
-				svc.On("CountEndpoints", mock.Anything, mock.Anything).Return(5, nil)
+				svc.On("RenderEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil,
+					models.NewInvalidEndpointError("invalid endpoint configuration: %v", fmt.Errorf("CPU request is too large")))
 				return svc
 			},
 			monitoringConfig: config.MonitoringConfig{
@@ -1365,7 +2267,7 @@ func TestCreateEndpoint(t *testing.T) {
 			},
 			expected: &ApiResponse{
 				code: http.StatusBadRequest,
-				data: Error{Message: "Max deployed endpoint reached. Max: 2 Current: 5 "},
+				data: Error{Message: "Unable to deploy model version: invalid endpoint configuration: CPU request is too large"},
 			},
 		},
 		{
@@ -1453,8 +2355,7 @@ func TestCreateEndpoint(t *testing.T) {
 			},
 			endpointService: func() *mocks.EndpointsService {
 				svc := &mocks.EndpointsService{}
-				svc.On("CountEndpoints", mock.Anything, mock.Anything).Return(0, nil)
-				svc.On("DeployEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("Something went wrong"))
+				svc.On("DeployEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("Something went wrong"))
 				return svc
 			},
 			monitoringConfig: config.MonitoringConfig{
@@ -1608,7 +2509,7 @@ func TestUpdateEndpoint(t *testing.T) {
 							Value: "1",
 						},
 					})}, nil)
-				svc.On("DeployEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.VersionEndpoint{
+				svc.On("DeployEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&models.VersionEndpoint{
 					Id:                   uuid,
 					VersionId:            models.Id(1),
 					VersionModelId:       models.Id(1),
@@ -2993,3 +3894,258 @@ func TestDeleteEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestListEndpointEvents(t *testing.T) {
+	endpointId := uuid.New()
+	events := []*models.VersionEndpointEvent{
+		{
+			Id:                2,
+			VersionEndpointId: endpointId,
+			Type:              models.EventTypeNormal,
+			Reason:            models.EventReasonReapplied,
+			Message:           "re-applying the deployed configuration",
+		},
+		{
+			Id:                1,
+			VersionEndpointId: endpointId,
+			Type:              models.EventTypeWarning,
+			Reason:            models.EventReasonInferenceServiceDeleted,
+			Message:           "inference service model-1 was deleted outside of merlin",
+		},
+	}
+	vars := map[string]string{
+		"model_id":    "1",
+		"version_id":  "1",
+		"endpoint_id": endpointId.String(),
+	}
+
+	testCases := []struct {
+		desc            string
+		findEndpointErr error
+		listEventsErr   error
+		expected        *ApiResponse
+	}{
+		{
+			desc: "Should success list events",
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: events,
+			},
+		},
+		{
+			desc:            "Should return 404 if endpoint is not found",
+			findEndpointErr: gorm.ErrRecordNotFound,
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: fmt.Sprintf("Version endpoint with id %s not found", endpointId)},
+			},
+		},
+		{
+			desc:          "Should return 500 if listing events failed",
+			listEventsErr: fmt.Errorf("db is down"),
+			expected: &ApiResponse{
+				code: http.StatusInternalServerError,
+				data: Error{Message: fmt.Sprintf("Error listing events of endpoint with id %s", endpointId)},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			modelSvc := &mocks.ModelsService{}
+			modelSvc.On("FindById", mock.Anything, models.Id(1)).Return(&models.Model{Id: models.Id(1), Name: "model-1"}, nil)
+			versionSvc := &mocks.VersionsService{}
+			versionSvc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(&models.Version{Id: models.Id(1), ModelId: models.Id(1)}, nil)
+			endpointSvc := &mocks.EndpointsService{}
+			if tC.findEndpointErr != nil {
+				endpointSvc.On("FindById", endpointId).Return(nil, tC.findEndpointErr)
+			} else {
+				endpointSvc.On("FindById", endpointId).Return(&models.VersionEndpoint{Id: endpointId, VersionId: 1, VersionModelId: 1}, nil)
+			}
+			if tC.listEventsErr != nil {
+				endpointSvc.On("ListEndpointEvents", endpointId).Return(nil, tC.listEventsErr)
+			} else {
+				endpointSvc.On("ListEndpointEvents", endpointId).Return(events, nil)
+			}
+
+			ctl := &EndpointsController{
+				AppContext: &AppContext{
+					ModelsService:    modelSvc,
+					VersionsService:  versionSvc,
+					EndpointsService: endpointSvc,
+				},
+			}
+			resp := ctl.ListEndpointEvents(&http.Request{}, vars, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
+
+func TestRollbackEndpoint(t *testing.T) {
+	endpointId := uuid.New()
+	vars := map[string]string{
+		"model_id":    "1",
+		"version_id":  "1",
+		"endpoint_id": endpointId.String(),
+		"revision":    "2",
+	}
+
+	testCases := []struct {
+		desc           string
+		endpointStatus models.EndpointStatus
+		versionId      models.Id
+		deploying      bool
+		rollbackErr    error
+		expected       *ApiResponse
+	}{
+		{
+			desc:           "Should success rollback endpoint",
+			endpointStatus: models.EndpointServing,
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: &models.VersionEndpoint{Id: endpointId, Status: models.EndpointPending},
+			},
+		},
+		{
+			desc:           "Should return 400 if endpoint is terminated",
+			endpointStatus: models.EndpointTerminated,
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: fmt.Sprintf("Version endpoint %s is terminated, please redeploy it instead", endpointId)},
+			},
+		},
+		{
+			desc:           "Should return 400 if endpoint is being deployed",
+			endpointStatus: models.EndpointPending,
+			deploying:      true,
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: fmt.Sprintf("Version endpoint %s is being deployed, please wait until the deployment is finished", endpointId)},
+			},
+		},
+		{
+			desc:           "Should return 404 if revision is not found",
+			endpointStatus: models.EndpointRunning,
+			rollbackErr:    gorm.ErrRecordNotFound,
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: fmt.Sprintf("Revision 2 of version endpoint %s not found", endpointId)},
+			},
+		},
+		{
+			desc:           "Should return 400 if revision is not valid in the environment anymore",
+			endpointStatus: models.EndpointRunning,
+			rollbackErr:    models.NewInvalidEndpointError("invalid endpoint configuration: %s", "requested cpu exceeds the maximum"),
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Unable to deploy model version: invalid endpoint configuration: requested cpu exceeds the maximum"},
+			},
+		},
+		{
+			desc:           "Should return 404 if endpoint belongs to another version",
+			endpointStatus: models.EndpointRunning,
+			versionId:      models.Id(2),
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: fmt.Sprintf("Version endpoint with id %s not found", endpointId)},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			model := &models.Model{Id: models.Id(1), Name: "model-1"}
+			version := &models.Version{Id: models.Id(1), ModelId: models.Id(1)}
+			endpointVersionId := models.Id(1)
+			if tC.versionId != 0 {
+				endpointVersionId = tC.versionId
+			}
+			endpoint := &models.VersionEndpoint{Id: endpointId, VersionModelId: models.Id(1), VersionId: endpointVersionId, Status: tC.endpointStatus}
+
+			modelSvc := &mocks.ModelsService{}
+			modelSvc.On("FindById", mock.Anything, models.Id(1)).Return(model, nil)
+			versionSvc := &mocks.VersionsService{}
+			versionSvc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(version, nil)
+			endpointSvc := &mocks.EndpointsService{}
+			endpointSvc.On("FindById", endpointId).Return(endpoint, nil)
+			endpointSvc.On("IsDeploying", endpoint).Return(tC.deploying, nil)
+			if tC.rollbackErr != nil {
+				endpointSvc.On("RollbackEndpoint", model, version, endpoint, 2, "").Return(nil, tC.rollbackErr)
+			} else {
+				endpointSvc.On("RollbackEndpoint", model, version, endpoint, 2, "").
+					Return(&models.VersionEndpoint{Id: endpointId, Status: models.EndpointPending}, nil)
+			}
+
+			ctl := &EndpointsController{
+				AppContext: &AppContext{
+					ModelsService:    modelSvc,
+					VersionsService:  versionSvc,
+					EndpointsService: endpointSvc,
+				},
+			}
+			resp := ctl.RollbackEndpoint(&http.Request{}, vars, nil)
+			assert.Equal(t, tC.expected, resp)
+		})
+	}
+}
+
+func TestListEndpointRevisions(t *testing.T) {
+	endpointId := uuid.New()
+	vars := map[string]string{
+		"model_id":    "1",
+		"version_id":  "1",
+		"endpoint_id": endpointId.String(),
+	}
+	revisions := []*models.DeploymentRevision{
+		{Deployment: &models.Deployment{Revision: 1, Status: models.EndpointRunning}},
+	}
+
+	testCases := []struct {
+		desc      string
+		versionId models.Id
+		expected  *ApiResponse
+	}{
+		{
+			desc:      "Should success list revisions of endpoint",
+			versionId: models.Id(1),
+			expected: &ApiResponse{
+				code: http.StatusOK,
+				data: revisions,
+			},
+		},
+		{
+			desc:      "Should return 404 if endpoint belongs to another version",
+			versionId: models.Id(2),
+			expected: &ApiResponse{
+				code: http.StatusNotFound,
+				data: Error{Message: fmt.Sprintf("Version endpoint with id %s not found", endpointId)},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			model := &models.Model{Id: models.Id(1), Name: "model-1"}
+			version := &models.Version{Id: models.Id(1), ModelId: models.Id(1)}
+			endpoint := &models.VersionEndpoint{Id: endpointId, VersionModelId: models.Id(1), VersionId: tC.versionId}
+
+			modelSvc := &mocks.ModelsService{}
+			modelSvc.On("FindById", mock.Anything, models.Id(1)).Return(model, nil)
+			versionSvc := &mocks.VersionsService{}
+			versionSvc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(version, nil)
+			endpointSvc := &mocks.EndpointsService{}
+			endpointSvc.On("FindById", endpointId).Return(endpoint, nil)
+			endpointSvc.On("ListRevisions", endpointId).Return(revisions, nil)
+
+			ctl := &EndpointsController{
+				AppContext: &AppContext{
+					ModelsService:    modelSvc,
+					VersionsService:  versionSvc,
+					EndpointsService: endpointSvc,
+				},
+			}
+			resp := ctl.ListEndpointRevisions(&http.Request{}, vars, nil)
+			assert.Equal(t, tC.expected, resp)
+			if tC.expected.code != http.StatusOK {
+				endpointSvc.AssertNotCalled(t, "ListRevisions", mock.Anything)
+			}
+		})
+	}
+}
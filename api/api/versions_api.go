@@ -23,6 +23,7 @@ import (
 	"github.com/gojek/merlin/log"
 	"github.com/gojek/merlin/mlflow"
 	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
 )
 
 type VersionsController struct {
@@ -68,6 +69,22 @@ func (c *VersionsController) PatchVersion(r *http.Request, vars map[string]strin
 	}
 
 	v.Patch(versionPatch)
+
+	if versionPatch.CustomPredictor != nil {
+		handler, err := modeltype.Get(v.Model.Type)
+		if err != nil {
+			return BadRequest(fmt.Sprintf("Unsupported model type: %s", v.Model.Type))
+		}
+		// the model types that don't use a custom predictor don't pass it in their options
+		options := handler.ModelOption(v)
+		if options.CustomPredictor == nil {
+			return BadRequest("Custom predictor can only be set on versions of custom models")
+		}
+		if err := handler.ValidateOptions(options); err != nil {
+			return BadRequest(fmt.Sprintf("Invalid custom predictor: %s", err))
+		}
+	}
+
 	patchedVersion, err := c.VersionsService.Save(ctx, v, c.MonitoringConfig)
 	if err != nil {
 		return InternalServerError(fmt.Sprintf("Error patching model version for given model %s version %s", modelId, versionId))
@@ -387,6 +387,73 @@ func TestPatchVersion(t *testing.T) {
 				data: Error{Message: "Unable to parse request body"},
 			},
 		},
+		{
+			desc: "Should return 400 if custom predictor is invalid",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+			},
+			requestBody: &models.VersionPatch{CustomPredictor: &models.CustomPredictor{
+				Image:       "gojek/triton-model:1",
+				PredictPath: "v2/models/model-1/infer",
+			}},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(
+					&models.Version{
+						Id:      models.Id(1),
+						ModelId: models.Id(1),
+						Model: &models.Model{
+							Id:           models.Id(1),
+							Name:         "model-1",
+							ProjectId:    models.Id(1),
+							Project:      mlp.Project{},
+							ExperimentId: 1,
+							Type:         "custom",
+							MlflowUrl:    "http://mlflow.com",
+						},
+						MlflowUrl: "http://mlflow.com",
+					}, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Invalid custom predictor: predict path must start with /, got v2/models/model-1/infer"},
+			},
+		},
+		{
+			desc: "Should return 400 if custom predictor is set on a non custom model",
+			vars: map[string]string{
+				"model_id":   "1",
+				"version_id": "1",
+			},
+			requestBody: &models.VersionPatch{CustomPredictor: &models.CustomPredictor{
+				Image: "gojek/triton-model:1",
+			}},
+			versionService: func() *mocks.VersionsService {
+				svc := &mocks.VersionsService{}
+				svc.On("FindById", mock.Anything, models.Id(1), models.Id(1), mock.Anything).Return(
+					&models.Version{
+						Id:      models.Id(1),
+						ModelId: models.Id(1),
+						Model: &models.Model{
+							Id:           models.Id(1),
+							Name:         "model-1",
+							ProjectId:    models.Id(1),
+							Project:      mlp.Project{},
+							ExperimentId: 1,
+							Type:         "pyfunc",
+							MlflowUrl:    "http://mlflow.com",
+						},
+						MlflowUrl: "http://mlflow.com",
+					}, nil)
+				return svc
+			},
+			expected: &ApiResponse{
+				code: http.StatusBadRequest,
+				data: Error{Message: "Custom predictor can only be set on versions of custom models"},
+			},
+		},
 		{
 			desc: "Should return 500 if save is failing",
 			vars: map[string]string{
@@ -25,13 +25,16 @@ import (
 	"github.com/GoogleCloudPlatform/spark-on-k8s-operator/pkg/client/informers/externalversions"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/gojek/merlin/cluster"
+	"github.com/gojek/merlin/config"
 	"github.com/gojek/merlin/log"
 	"github.com/gojek/merlin/mlp"
 	"github.com/gojek/merlin/models"
@@ -82,6 +85,10 @@ func init() {
 // processNextItem() will do necessary jobs for updated Spark application (saving, cleaning)
 type Controller interface {
 	Submit(predictionJob *models.PredictionJob, namespace string) error
+	// Render validates the prediction job and returns the resources that Submit would create, without creating them
+	Render(predictionJob *models.PredictionJob, namespace string) ([]runtime.Object, error)
+	// Validate checks that the prediction job can be submitted to the environment, without rendering nor submitting it
+	Validate(predictionJob *models.PredictionJob) error
 	Run(stopCh <-chan struct{})
 	Stop(predictionJob *models.PredictionJob, namespace string) error
 	cluster.ContainerFetcher
@@ -96,11 +103,12 @@ type controller struct {
 	manifestManager  ManifestManager
 	informer         cache.SharedIndexInformer
 	queue            workqueue.RateLimitingInterface
+	scheduling       config.SchedulingConfig
 
 	cluster.ContainerFetcher
 }
 
-func NewController(store storage.PredictionJobStorage, mlpApiClient mlp.APIClient, sparkClient versioned.Interface, kubeClient kubernetes.Interface, manifestManager ManifestManager, envMetaData cluster.Metadata) Controller {
+func NewController(store storage.PredictionJobStorage, mlpApiClient mlp.APIClient, sparkClient versioned.Interface, kubeClient kubernetes.Interface, manifestManager ManifestManager, envMetaData cluster.Metadata, scheduling config.SchedulingConfig) Controller {
 	informerFactory := externalversions.NewSharedInformerFactory(sparkClient, resyncPeriod)
 	informer := informerFactory.Sparkoperator().V1beta2().SparkApplications().Informer()
 	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
@@ -114,6 +122,7 @@ func NewController(store storage.PredictionJobStorage, mlpApiClient mlp.APIClien
 		namespaceCreator: cluster.NewNamespaceCreator(kubeClient.CoreV1(), time.Second*5),
 		informer:         informer,
 		queue:            queue,
+		scheduling:       scheduling,
 
 		ContainerFetcher: cluster.NewContainerFetcher(kubeClient.CoreV1(), envMetaData),
 	}
@@ -127,7 +136,11 @@ func NewController(store storage.PredictionJobStorage, mlpApiClient mlp.APIClien
 func (c *controller) Submit(predictionJob *models.PredictionJob, namespace string) error {
 	ctx := context.Background()
 
-	var err error
+	nodePool, err := c.nodePool(predictionJob)
+	if err != nil {
+		return err
+	}
+
 	defer func() {
 		if err != nil {
 			// Directly cleanup if error happens during submission
@@ -160,7 +173,7 @@ func (c *controller) Submit(predictionJob *models.PredictionJob, namespace strin
 		return fmt.Errorf("failed creating job specification configmap for job %s in namespace %s: %v", predictionJob.Name, namespace, err)
 	}
 
-	sparkResource, err := CreateSparkApplicationResource(predictionJob)
+	sparkResource, err := CreateSparkApplicationResource(predictionJob, nodePool)
 	if err != nil {
 		return fmt.Errorf("failed creating spark application resource for job %s in namespace %s: %v", predictionJob.Name, namespace, err)
 	}
@@ -174,6 +187,55 @@ func (c *controller) Submit(predictionJob *models.PredictionJob, namespace strin
 	return c.store.Save(predictionJob)
 }
 
+func (c *controller) Render(predictionJob *models.PredictionJob, namespace string) ([]runtime.Object, error) {
+	nodePool, err := c.nodePool(predictionJob)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := c.mlpApiClient.GetPlainSecretByNameAndProjectID(context.Background(), predictionJob.Config.ServiceAccountName, int32(predictionJob.ProjectId))
+	if err != nil {
+		return nil, fmt.Errorf("service account %s is not found within %s project: %s", predictionJob.Config.ServiceAccountName, namespace, err)
+	}
+	secretSpec := createSecretSpec(predictionJob.Name, namespace, secret.Data)
+	secretSpec.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+
+	jobSpec, err := createJobSpecConfigMap(predictionJob.Name, namespace, predictionJob.Config.JobConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating job specification configmap for job %s in namespace %s: %v", predictionJob.Name, namespace, err)
+	}
+	jobSpec.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+
+	sparkResource, err := CreateSparkApplicationResource(predictionJob, nodePool)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating spark application resource for job %s in namespace %s: %v", predictionJob.Name, namespace, err)
+	}
+	driverServiceAccount, _, _ := createAuthorizationResourceNames(namespace)
+	sparkResource.Spec.Driver.ServiceAccount = &driverServiceAccount
+	sparkResource.Namespace = namespace
+	sparkResource.SetGroupVersionKind(v1beta2.SchemeGroupVersion.WithKind("SparkApplication"))
+
+	return []runtime.Object{secretSpec, jobSpec, sparkResource}, nil
+}
+
+func (c *controller) Validate(predictionJob *models.PredictionJob) error {
+	_, err := c.nodePool(predictionJob)
+	return err
+}
+
+// nodePool returns the node pool requested by the prediction job, or the default one of the environment.
+func (c *controller) nodePool(predictionJob *models.PredictionJob) (config.NodePoolConfig, error) {
+	if predictionJob.Config.NodePool == "" && c.scheduling.DefaultBatchNodePool == "" {
+		return defaultNodePool, nil
+	}
+
+	nodePool, ok := c.scheduling.NodePool(predictionJob.Config.NodePool, c.scheduling.DefaultBatchNodePool)
+	if !ok {
+		return config.NodePoolConfig{}, fmt.Errorf("node pool %s is not available in the environment", predictionJob.Config.NodePool)
+	}
+	return nodePool, nil
+}
+
 func (c *controller) Run(stopCh <-chan struct{}) {
 	defer c.queue.ShutDown()
 
@@ -37,6 +37,7 @@ import (
 
 	batchMock "github.com/gojek/merlin/batch/mocks"
 	"github.com/gojek/merlin/cluster"
+	"github.com/gojek/merlin/config"
 	"github.com/gojek/merlin/mlp"
 	mlpMock "github.com/gojek/merlin/mlp/mocks"
 	"github.com/gojek/merlin/models"
@@ -108,7 +109,7 @@ var (
 		},
 	}
 
-	sparkApp, _ = CreateSparkApplicationResource(predictionJob)
+	sparkApp, _ = CreateSparkApplicationResource(predictionJob, defaultNodePool)
 	namespace   = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: defaultNamespace}}
 	e           = errors.New("error")
 )
@@ -317,7 +318,7 @@ func TestSubmit(t *testing.T) {
 			mockKubeClient := &fake2.Clientset{}
 			mockManifestManager := &batchMock.ManifestManager{}
 			clusterMetadata := cluster.Metadata{GcpProject: "my-gcp", ClusterName: "my-cluster"}
-			ctl := NewController(mockStorage, mockMlpApiClient, mockSparkClient, mockKubeClient, mockManifestManager, clusterMetadata)
+			ctl := NewController(mockStorage, mockMlpApiClient, mockSparkClient, mockKubeClient, mockManifestManager, clusterMetadata, config.SchedulingConfig{})
 
 			mockKubeClient.PrependReactor("get", "namespaces", func(action ktesting.Action) (handled bool, ret runtime.Object, err error) {
 				return true, nil, kerrors.NewNotFound(schema.GroupResource{}, action.(ktesting.GetAction).GetName())
@@ -355,7 +356,7 @@ func TestSubmit(t *testing.T) {
 			assert.NoError(t, err)
 
 			// validate spark application submitted to spark client
-			expectedSparkApp, _ := CreateSparkApplicationResource(predictionJob)
+			expectedSparkApp, _ := CreateSparkApplicationResource(predictionJob, defaultNodePool)
 			expectedSparkApp.Spec.Driver.ServiceAccount = &test.driverAuthzCreationResult.serviceAccountName
 			actions := mockSparkClient.Fake.Actions()
 			createAction := actions[0].(ktesting.CreateAction)
@@ -369,6 +370,75 @@ func TestSubmit(t *testing.T) {
 	}
 }
 
+func TestSubmit_NodePoolNotAllowed(t *testing.T) {
+	mockSparkClient := &batchMock.Clientset{}
+	mockKubeClient := &fake2.Clientset{}
+	scheduling := config.SchedulingConfig{
+		NodePools: []config.NodePoolConfig{{Name: "highmem"}},
+	}
+	ctl := NewController(&mocks.PredictionJobStorage{}, &mlpMock.APIClient{}, mockSparkClient, mockKubeClient, &batchMock.ManifestManager{}, cluster.Metadata{}, scheduling)
+
+	job := &models.PredictionJob{
+		Name:   jobName,
+		Config: &models.Config{NodePool: "gpu"},
+	}
+	err := ctl.Submit(job, defaultNamespace)
+	assert.EqualError(t, err, "node pool gpu is not available in the environment")
+	assert.Empty(t, mockKubeClient.Actions())
+	assert.Empty(t, mockSparkClient.Actions())
+}
+
+func TestValidate(t *testing.T) {
+	scheduling := config.SchedulingConfig{
+		NodePools:            []config.NodePoolConfig{{Name: "highmem"}},
+		DefaultBatchNodePool: "highmem",
+	}
+	ctl := NewController(&mocks.PredictionJobStorage{}, &mlpMock.APIClient{}, &batchMock.Clientset{}, &fake2.Clientset{}, &batchMock.ManifestManager{}, cluster.Metadata{}, scheduling)
+
+	assert.NoError(t, ctl.Validate(&models.PredictionJob{Config: &models.Config{}}))
+	assert.NoError(t, ctl.Validate(&models.PredictionJob{Config: &models.Config{NodePool: "highmem"}}))
+	assert.EqualError(t, ctl.Validate(&models.PredictionJob{Config: &models.Config{NodePool: "gpu"}}), "node pool gpu is not available in the environment")
+}
+
+func TestRender(t *testing.T) {
+	mockMlpApiClient := &mlpMock.APIClient{}
+	mockMlpApiClient.On("GetPlainSecretByNameAndProjectID", context.Background(), secret.Name, int32(1)).Return(secret, nil)
+	mockSparkClient := &batchMock.Clientset{}
+	mockKubeClient := &fake2.Clientset{}
+	mockManifestManager := &batchMock.ManifestManager{}
+	ctl := NewController(&mocks.PredictionJobStorage{}, mockMlpApiClient, mockSparkClient, mockKubeClient, mockManifestManager, cluster.Metadata{}, config.SchedulingConfig{})
+
+	objs, err := ctl.Render(predictionJob, defaultNamespace)
+	assert.NoError(t, err)
+	assert.Len(t, objs, 3)
+
+	renderedSecret := objs[0].(*corev1.Secret)
+	assert.Equal(t, "Secret", renderedSecret.Kind)
+	assert.Equal(t, secret.Data, renderedSecret.StringData[serviceAccountFileName])
+	renderedJobSpec := objs[1].(*corev1.ConfigMap)
+	assert.Equal(t, "ConfigMap", renderedJobSpec.Kind)
+	assert.Contains(t, renderedJobSpec.Data[jobSpecFileName], "table_iris_result")
+	renderedSparkApp := objs[2].(*v1beta2.SparkApplication)
+	assert.Equal(t, "SparkApplication", renderedSparkApp.Kind)
+	assert.Equal(t, defaultNamespace, renderedSparkApp.Namespace)
+	assert.Equal(t, imageRef, *renderedSparkApp.Spec.Image)
+	assert.Equal(t, fmt.Sprintf("%s-driver-sa", defaultNamespace), *renderedSparkApp.Spec.Driver.ServiceAccount)
+
+	// nothing is created in the cluster
+	assert.Empty(t, mockKubeClient.Actions())
+	assert.Empty(t, mockSparkClient.Actions())
+	mockManifestManager.AssertNotCalled(t, "CreateSecret", jobName, defaultNamespace, secret.Data)
+}
+
+func TestRender_ServiceAccountNotFound(t *testing.T) {
+	mockMlpApiClient := &mlpMock.APIClient{}
+	mockMlpApiClient.On("GetPlainSecretByNameAndProjectID", context.Background(), secret.Name, int32(1)).Return(mlp.Secret{}, errors.New("not found"))
+	ctl := NewController(&mocks.PredictionJobStorage{}, mockMlpApiClient, &batchMock.Clientset{}, &fake2.Clientset{}, &batchMock.ManifestManager{}, cluster.Metadata{}, config.SchedulingConfig{})
+
+	_, err := ctl.Render(predictionJob, defaultNamespace)
+	assert.EqualError(t, err, fmt.Sprintf("service account %s is not found within %s project: not found", secret.Name, defaultNamespace))
+}
+
 func TestCleanupAfterSubmitFailed(t *testing.T) {
 	mockStorage := &mocks.PredictionJobStorage{}
 	mockStorage.On("Save", predictionJob).Return(nil)
@@ -383,7 +453,7 @@ func TestCleanupAfterSubmitFailed(t *testing.T) {
 	mockKubeClient := &fake2.Clientset{}
 	mockManifestManager := &batchMock.ManifestManager{}
 	clusterMetadata := cluster.Metadata{GcpProject: "my-gcp", ClusterName: "my-cluster"}
-	ctl := NewController(mockStorage, mockMlpApiClient, mockSparkClient, mockKubeClient, mockManifestManager, clusterMetadata)
+	ctl := NewController(mockStorage, mockMlpApiClient, mockSparkClient, mockKubeClient, mockManifestManager, clusterMetadata, config.SchedulingConfig{})
 
 	mockManifestManager.On("DeleteSecret", jobName, defaultNamespace).Return(nil)
 	mockManifestManager.On("DeleteJobSpec", jobName, defaultNamespace).Return(nil)
@@ -407,7 +477,7 @@ func TestOnUpdate(t *testing.T) {
 	mockKubeClient := &fake2.Clientset{}
 	mockManifestManager := &batchMock.ManifestManager{}
 	clusterMetadata := cluster.Metadata{GcpProject: "my-gcp", ClusterName: "my-cluster"}
-	ctl := NewController(mockStorage, mockMlpApiClient, mockSparkClient, mockKubeClient, mockManifestManager, clusterMetadata).(*controller)
+	ctl := NewController(mockStorage, mockMlpApiClient, mockSparkClient, mockKubeClient, mockManifestManager, clusterMetadata, config.SchedulingConfig{}).(*controller)
 	stopCh := make(chan struct{})
 	defer close(stopCh)
 	go ctl.Run(stopCh)
@@ -482,7 +552,7 @@ func TestUpdateStatus(t *testing.T) {
 			mockKubeClient := &fake2.Clientset{}
 			mockManifestManager := &batchMock.ManifestManager{}
 			clusterMetadata := cluster.Metadata{GcpProject: "my-gcp", ClusterName: "my-cluster"}
-			ctl := NewController(mockStorage, mockMlpApiClient, mockSparkClient, mockKubeClient, mockManifestManager, clusterMetadata).(*controller)
+			ctl := NewController(mockStorage, mockMlpApiClient, mockSparkClient, mockKubeClient, mockManifestManager, clusterMetadata, config.SchedulingConfig{}).(*controller)
 			stopCh := make(chan struct{})
 			defer close(stopCh)
 			go ctl.Run(stopCh)
@@ -570,7 +640,7 @@ func TestStop(t *testing.T) {
 			mockKubeClient := &fake2.Clientset{}
 			mockManifestManager := &batchMock.ManifestManager{}
 			clusterMetadata := cluster.Metadata{GcpProject: "my-gcp", ClusterName: "my-cluster"}
-			ctl := NewController(mockStorage, mockMlpApiClient, mockSparkClient, mockKubeClient, mockManifestManager, clusterMetadata)
+			ctl := NewController(mockStorage, mockMlpApiClient, mockSparkClient, mockKubeClient, mockManifestManager, clusterMetadata, config.SchedulingConfig{})
 
 			mockKubeClient.PrependReactor("get", "namespaces", func(action ktesting.Action) (handled bool, ret runtime.Object, err error) {
 				return true, nil, kerrors.NewNotFound(schema.GroupResource{}, action.(ktesting.GetAction).GetName())
@@ -80,13 +80,29 @@ func NewManifestManager(kubeClient kubernetes.Interface) ManifestManager {
 }
 
 func (m *manifestManager) CreateJobSpec(predictionJobName string, namespace string, spec *spec.PredictionJob) (string, error) {
+	configMap, err := createJobSpecConfigMap(predictionJobName, namespace, spec)
+	if err != nil {
+		return "", err
+	}
+
+	cm, err := m.kubeClient.CoreV1().ConfigMaps(namespace).Create(configMap)
+	if err != nil {
+		log.Errorf("failed creating job specification config map %s in namespace %s: %v", predictionJobName, namespace, err)
+		return "", errors.New("failed creating job specification config map")
+	}
+
+	return cm.Name, nil
+}
+
+// createJobSpecConfigMap returns the ConfigMap holding the job specification read by the spark application.
+func createJobSpecConfigMap(predictionJobName string, namespace string, spec *spec.PredictionJob) (*corev1.ConfigMap, error) {
 	configYaml, err := toYamlString(spec)
 	if err != nil {
 		log.Errorf("failed converting prediction job spec to yaml: %v", err)
-		return "", errors.New("failed converting prediction job spec to yaml")
+		return nil, errors.New("failed converting prediction job spec to yaml")
 	}
 
-	cm, err := m.kubeClient.CoreV1().ConfigMaps(namespace).Create(&corev1.ConfigMap{
+	return &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      predictionJobName,
 			Namespace: namespace,
@@ -94,14 +110,7 @@ func (m *manifestManager) CreateJobSpec(predictionJobName string, namespace stri
 		Data: map[string]string{
 			jobSpecFileName: configYaml,
 		},
-	})
-
-	if err != nil {
-		log.Errorf("failed creating job specification config map %s in namespace %s: %v", predictionJobName, namespace, err)
-		return "", errors.New("failed creating job specification config map")
-	}
-
-	return cm.Name, nil
+	}, nil
 }
 
 func (m *manifestManager) DeleteJobSpec(predictionJobName string, namespace string) error {
@@ -206,7 +215,18 @@ func (m *manifestManager) DeleteDriverAuthorization(namespace string) error {
 }
 
 func (m *manifestManager) CreateSecret(predictionJobName string, namespace string, data string) (string, error) {
-	secret, err := m.kubeClient.CoreV1().Secrets(namespace).Create(&corev1.Secret{
+	secret, err := m.kubeClient.CoreV1().Secrets(namespace).Create(createSecretSpec(predictionJobName, namespace, data))
+	if err != nil {
+		log.Errorf("failed creating secret %s in namespace %s: %v", predictionJobName, namespace, err)
+		return "", errors.Errorf("failed creating secret %s in namespace %s", predictionJobName, namespace)
+	}
+
+	return secret.Name, nil
+}
+
+// createSecretSpec returns the Secret holding the service account key of the prediction job.
+func createSecretSpec(predictionJobName string, namespace string, data string) *corev1.Secret {
+	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      predictionJobName,
 			Namespace: namespace,
@@ -215,14 +235,7 @@ func (m *manifestManager) CreateSecret(predictionJobName string, namespace strin
 			serviceAccountFileName: data,
 		},
 		Type: corev1.SecretTypeOpaque,
-	})
-
-	if err != nil {
-		log.Errorf("failed creating secret %s in namespace %s: %v", predictionJobName, namespace, err)
-		return "", errors.Errorf("failed creating secret %s in namespace %s", predictionJobName, namespace)
 	}
-
-	return secret.Name, nil
 }
 
 func (m *manifestManager) DeleteSecret(predictionJobName string, namespace string) error {
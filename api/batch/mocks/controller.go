@@ -5,6 +5,8 @@ package mocks
 import (
 	models "github.com/gojek/merlin/models"
 	mock "github.com/stretchr/testify/mock"
+
+	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // Controller is an autogenerated mock type for the Controller type
@@ -35,6 +37,29 @@ func (_m *Controller) GetContainers(namespace string, labelSelector string) ([]*
 	return r0, r1
 }
 
+// Render provides a mock function with given fields: predictionJob, namespace
+func (_m *Controller) Render(predictionJob *models.PredictionJob, namespace string) ([]runtime.Object, error) {
+	ret := _m.Called(predictionJob, namespace)
+
+	var r0 []runtime.Object
+	if rf, ok := ret.Get(0).(func(*models.PredictionJob, string) []runtime.Object); ok {
+		r0 = rf(predictionJob, namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]runtime.Object)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*models.PredictionJob, string) error); ok {
+		r1 = rf(predictionJob, namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Run provides a mock function with given fields: stopCh
 func (_m *Controller) Run(stopCh <-chan struct{}) {
 	_m.Called(stopCh)
@@ -67,3 +92,17 @@ func (_m *Controller) Submit(predictionJob *models.PredictionJob, namespace stri
 
 	return r0
 }
+
+// Validate provides a mock function with given fields: predictionJob
+func (_m *Controller) Validate(predictionJob *models.PredictionJob) error {
+	ret := _m.Called(predictionJob)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.PredictionJob) error); ok {
+		r0 = rf(predictionJob)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
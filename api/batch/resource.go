@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/gojek/merlin/config"
 	"github.com/gojek/merlin/models"
 )
 
@@ -95,10 +96,17 @@ var (
 	defaultNodeSelector = map[string]string{
 		"node-workload-type": "batch",
 	}
+
+	// defaultNodePool schedules the prediction jobs of the environments without default batch node pool
+	defaultNodePool = config.NodePoolConfig{
+		Name:         "batch",
+		NodeSelector: defaultNodeSelector,
+		Tolerations:  []corev1.Toleration{defaultToleration},
+	}
 )
 
-func CreateSparkApplicationResource(job *models.PredictionJob) (*v1beta2.SparkApplication, error) {
-	spec, err := createSpec(job)
+func CreateSparkApplicationResource(job *models.PredictionJob, nodePool config.NodePoolConfig) (*v1beta2.SparkApplication, error) {
+	spec, err := createSpec(job, nodePool)
 	if err != nil {
 		return nil, err
 	}
@@ -112,18 +120,18 @@ func CreateSparkApplicationResource(job *models.PredictionJob) (*v1beta2.SparkAp
 	}, nil
 }
 
-func createSpec(job *models.PredictionJob) (v1beta2.SparkApplicationSpec, error) {
-	driverSpec, err := createDriverSpec(job)
+func createSpec(job *models.PredictionJob, nodePool config.NodePoolConfig) (v1beta2.SparkApplicationSpec, error) {
+	driverSpec, err := createDriverSpec(job, nodePool)
 	if err != nil {
 		return v1beta2.SparkApplicationSpec{}, err
 	}
 
-	executorSpec, err := createExecutorSpec(job)
+	executorSpec, err := createExecutorSpec(job, nodePool)
 	if err != nil {
 		return v1beta2.SparkApplicationSpec{}, err
 	}
 
-	return v1beta2.SparkApplicationSpec{
+	spec := v1beta2.SparkApplicationSpec{
 		Type:                sparkType,
 		SparkVersion:        sparkVersion,
 		Mode:                sparkMode,
@@ -138,14 +146,22 @@ func createSpec(job *models.PredictionJob) (v1beta2.SparkApplicationSpec, error)
 		HadoopConf:        defaultHadoopConf,
 		Driver:            driverSpec,
 		Executor:          executorSpec,
-		NodeSelector:      defaultNodeSelector,
+		NodeSelector:      nodePool.NodeSelector,
 		RestartPolicy:     defaultRetryPolicy,
 		PythonVersion:     &pythonVersion,
 		TimeToLiveSeconds: &ttlSecond,
-	}, nil
+	}
+
+	// the spark operator only sets the priority class through the batch scheduler, e.g. Volcano
+	if nodePool.PriorityClassName != "" {
+		spec.BatchSchedulerOptions = &v1beta2.BatchSchedulerConfiguration{
+			PriorityClassName: &nodePool.PriorityClassName,
+		}
+	}
+	return spec, nil
 }
 
-func createDriverSpec(job *models.PredictionJob) (v1beta2.DriverSpec, error) {
+func createDriverSpec(job *models.PredictionJob, nodePool config.NodePoolConfig) (v1beta2.DriverSpec, error) {
 	userCpuRequest, err := resource.ParseQuantity(job.Config.ResourceRequest.DriverCpuRequest)
 	if err != nil {
 		return v1beta2.DriverSpec{}, fmt.Errorf("invalid driver cpu request: %s", job.Config.ResourceRequest.DriverCpuRequest)
@@ -182,17 +198,16 @@ func createDriverSpec(job *models.PredictionJob) (v1beta2.DriverSpec, error) {
 					Path: serviceAccountMount,
 				},
 			},
-			Env:    envVars,
-			Labels: createLabel(job),
-			Tolerations: []corev1.Toleration{
-				defaultToleration,
-			},
+			Env:         envVars,
+			Labels:      createLabel(job),
+			Tolerations: nodePool.Tolerations,
+			Affinity:    nodePool.PodAffinity(map[string]string{labelPredictionJobId: job.Id.String()}),
 		},
 		ServiceAccount: &job.Name,
 	}, nil
 }
 
-func createExecutorSpec(job *models.PredictionJob) (v1beta2.ExecutorSpec, error) {
+func createExecutorSpec(job *models.PredictionJob, nodePool config.NodePoolConfig) (v1beta2.ExecutorSpec, error) {
 	userCpuRequest, err := resource.ParseQuantity(job.Config.ResourceRequest.ExecutorCpuRequest)
 	if err != nil {
 		return v1beta2.ExecutorSpec{}, fmt.Errorf("invalid executor cpu request: %s", job.Config.ResourceRequest.ExecutorCpuRequest)
@@ -230,11 +245,10 @@ func createExecutorSpec(job *models.PredictionJob) (v1beta2.ExecutorSpec, error)
 					Path: serviceAccountMount,
 				},
 			},
-			Env:    envVars,
-			Labels: createLabel(job),
-			Tolerations: []corev1.Toleration{
-				defaultToleration,
-			},
+			Env:         envVars,
+			Labels:      createLabel(job),
+			Tolerations: nodePool.Tolerations,
+			Affinity:    nodePool.PodAffinity(map[string]string{labelPredictionJobId: job.Id.String()}),
 		},
 	}, nil
 }
@@ -23,6 +23,7 @@ import (
 	v12 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/gojek/merlin/config"
 	"github.com/gojek/merlin/mlp"
 	"github.com/gojek/merlin/models"
 )
@@ -614,7 +615,7 @@ func TestCreateSparkApplicationResource(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			sp, err := CreateSparkApplicationResource(test.arg)
+			sp, err := CreateSparkApplicationResource(test.arg, defaultNodePool)
 			if test.wantErr {
 				assert.Equal(t, test.wantErrMessage, err.Error())
 				return
@@ -624,3 +625,59 @@ func TestCreateSparkApplicationResource(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateSparkApplicationResource_NodePool(t *testing.T) {
+	job := &models.PredictionJob{
+		Name:           jobName,
+		Id:             jobId,
+		VersionModelId: modelId,
+		VersionId:      versionId,
+		Config: &models.Config{
+			ImageRef: imageRef,
+			ResourceRequest: &models.PredictionJobResourceRequest{
+				DriverCpuRequest:      driverCpuRequest,
+				DriverMemoryRequest:   driverMemory,
+				ExecutorReplica:       executorReplica,
+				ExecutorCpuRequest:    executorCpuRequest,
+				ExecutorMemoryRequest: executorMemory,
+			},
+			NodePool: "highmem",
+		},
+	}
+	toleration := v12.Toleration{
+		Key:      "highmem",
+		Operator: v12.TolerationOpEqual,
+		Value:    "true",
+		Effect:   v12.TaintEffectNoSchedule,
+	}
+	nodePool := config.NodePoolConfig{
+		Name:               "highmem",
+		NodeSelector:       map[string]string{"pool": "highmem"},
+		Tolerations:        []v12.Toleration{toleration},
+		PriorityClassName:  "batch-high",
+		SpreadTopologyKeys: []string{"topology.kubernetes.io/zone"},
+	}
+
+	sp, err := CreateSparkApplicationResource(job, nodePool)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"pool": "highmem"}, sp.Spec.NodeSelector)
+	assert.Equal(t, "batch-high", *sp.Spec.BatchSchedulerOptions.PriorityClassName)
+	wantAffinity := &v12.Affinity{
+		PodAntiAffinity: &v12.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []v12.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: v12.PodAffinityTerm{
+						LabelSelector: &v1.LabelSelector{MatchLabels: map[string]string{labelPredictionJobId: jobId.String()}},
+						TopologyKey:   "topology.kubernetes.io/zone",
+					},
+				},
+			},
+		},
+	}
+	for _, podSpec := range []v1beta2.SparkPodSpec{sp.Spec.Driver.SparkPodSpec, sp.Spec.Executor.SparkPodSpec} {
+		assert.Equal(t, []v12.Toleration{toleration}, podSpec.Tolerations)
+		assert.Equal(t, wantAffinity, podSpec.Affinity)
+	}
+}
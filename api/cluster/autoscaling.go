@@ -0,0 +1,118 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/models"
+)
+
+const (
+	annotationAutoscalingClass    = "autoscaling.knative.dev/class"
+	annotationAutoscalingMetric   = "autoscaling.knative.dev/metric"
+	annotationAutoscalingTarget   = "autoscaling.knative.dev/target"
+	annotationAutoscalingMinScale = "autoscaling.knative.dev/minScale"
+
+	autoscalingClassKPA = "kpa.autoscaling.knative.dev"
+	autoscalingClassHPA = "hpa.autoscaling.knative.dev"
+)
+
+var autoscalingAnnotations = []string{
+	annotationAutoscalingClass,
+	annotationAutoscalingMetric,
+	annotationAutoscalingTarget,
+	annotationAutoscalingMinScale,
+}
+
+// setAutoscalingAnnotations replaces the knative autoscaling annotations with the ones of the policy.
+// Knative's defaults apply when the policy is nil.
+func setAutoscalingAnnotations(annotations map[string]string, policy *models.AutoscalingPolicy) map[string]string {
+	for _, key := range autoscalingAnnotations {
+		delete(annotations, key)
+	}
+
+	if policy == nil {
+		return annotations
+	}
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	// Knative pod autoscaler only supports concurrency and rps, cpu requires the HPA-based autoscaler
+	class := autoscalingClassKPA
+	if policy.MetricType == models.AutoscalingMetricCPU {
+		class = autoscalingClassHPA
+	}
+
+	annotations[annotationAutoscalingClass] = class
+	annotations[annotationAutoscalingMetric] = string(policy.MetricType)
+	annotations[annotationAutoscalingTarget] = strconv.FormatFloat(policy.TargetValue, 'f', -1, 64)
+	if policy.ScaleToZero {
+		annotations[annotationAutoscalingMinScale] = "0"
+	}
+	return annotations
+}
+
+// minReplica returns the minimum number of replica of the inference service according to its autoscaling policy.
+func minReplica(modelService *models.Service) int {
+	policy := modelService.AutoscalingPolicy
+	switch {
+	case policy == nil:
+		return modelService.ResourceRequest.MinReplica
+	case policy.ScaleToZero:
+		return 0
+	case modelService.ResourceRequest.MinReplica < 1:
+		return 1
+	default:
+		return modelService.ResourceRequest.MinReplica
+	}
+}
+
+func validateAutoscalingPolicy(policy *models.AutoscalingPolicy, cfg config.AutoscalingConfig) error {
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("%v: %v", ErrInvalidAutoscalingPolicy, err)
+	}
+
+	if policy.ScaleToZero && !cfg.AllowScaleToZero {
+		return ErrScaleToZeroNotAllowed
+	}
+
+	if len(cfg.Targets) == 0 {
+		return nil
+	}
+
+	bounds, ok := cfg.Targets[string(policy.MetricType)]
+	if !ok {
+		return fmt.Errorf("%v: %s", ErrAutoscalingMetricNotAllowed, policy.MetricType)
+	}
+	if policy.TargetValue < bounds.Min || (bounds.Max > 0 && policy.TargetValue > bounds.Max) {
+		return fmt.Errorf("%v: %s target must be between %v and %v", ErrInvalidAutoscalingPolicy, policy.MetricType, bounds.Min, bounds.Max)
+	}
+	return nil
+}
+
+// replicasPatch returns the merge patch setting the min and max replicas of the object found at the path.
+func replicasPatch(minReplicas, maxReplicas int, path ...string) ([]byte, error) {
+	var patch interface{} = map[string]int{"minReplicas": minReplicas, "maxReplicas": maxReplicas}
+	for i := len(path) - 1; i >= 0; i-- {
+		patch = map[string]interface{}{path[i]: patch}
+	}
+	return json.Marshal(patch)
+}
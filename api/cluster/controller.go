@@ -15,25 +15,33 @@
 package cluster
 
 import (
+	"fmt"
 	"time"
 
-	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
-	kfservice "github.com/kubeflow/kfserving/pkg/client/clientset/versioned/typed/serving/v1alpha2"
-	"github.com/kubeflow/kfserving/pkg/constants"
 	"github.com/pkg/errors"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 
 	"github.com/gojek/merlin/config"
 	"github.com/gojek/merlin/log"
 	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
 )
 
 type Controller interface {
 	Deploy(modelService *models.Service) (*models.Service, error)
+	// Render validates the model service and returns the resources that Deploy would apply, without applying them
+	Render(modelService *models.Service) ([]runtime.Object, error)
 	Delete(modelService *models.Service) (*models.Service, error)
+	// Scale sets the min and max replicas of the deployed model service to the ones of its resource request, without redeploying it
+	Scale(modelService *models.Service) error
+	// Validate checks that the model service can be deployed to the environment, without rendering nor applying it
+	Validate(modelService *models.Service) error
 	ContainerFetcher
 }
 
@@ -57,31 +65,35 @@ type ClusterConfig struct {
 const (
 	tickDurationSecond        = 1
 	deletionGracePeriodSecond = 30
+
+	// Suffix appended to the inference service's URL to request explanations
+	explainPathSuffix = ":explain"
 )
 
 type controller struct {
-	servingClient    kfservice.ServingV1alpha2Interface
+	servingAPI       inferenceServiceAPI
 	clusterClient    corev1.CoreV1Interface
 	namespaceCreator NamespaceCreator
 	config           *config.DeploymentConfig
 	ContainerFetcher
 }
 
-func NewController(clusterConfig ClusterConfig, deployConfig config.DeploymentConfig) (Controller, error) {
-	cfg := &rest.Config{
-		Host: clusterConfig.Host,
+func (c ClusterConfig) restConfig() *rest.Config {
+	return &rest.Config{
+		Host: c.Host,
 		TLSClientConfig: rest.TLSClientConfig{
 			Insecure: false,
-			CAData:   []byte(clusterConfig.CACert),
-			CertData: []byte(clusterConfig.ClientCert),
-			KeyData:  []byte(clusterConfig.ClientKey),
+			CAData:   []byte(c.CACert),
+			CertData: []byte(c.ClientCert),
+			KeyData:  []byte(c.ClientKey),
 		},
 	}
+}
+
+// NewController returns the controller of the deployment backend of the environment.
+func NewController(clusterConfig ClusterConfig, deployConfig config.DeploymentConfig) (Controller, error) {
+	cfg := clusterConfig.restConfig()
 
-	servingClient, err := kfservice.NewForConfig(cfg)
-	if err != nil {
-		return nil, err
-	}
 	coreV1Client, err := corev1.NewForConfig(cfg)
 	if err != nil {
 		return nil, err
@@ -92,12 +104,27 @@ func NewController(clusterConfig ClusterConfig, deployConfig config.DeploymentCo
 		GcpProject:  clusterConfig.GcpProject,
 	})
 
-	return newController(servingClient, coreV1Client, deployConfig, containerFetcher)
+	switch deployConfig.DeploymentBackend {
+	case "", config.DeploymentBackendKFServing:
+		servingAPI, err := newInferenceServiceAPI(deployConfig.KFServingAPIVersion, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newController(servingAPI, coreV1Client, deployConfig, containerFetcher)
+	case config.DeploymentBackendKubernetes:
+		kubeClient, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newDeploymentController(kubeClient, deployConfig, containerFetcher), nil
+	default:
+		return nil, fmt.Errorf("unsupported deployment backend: %s", deployConfig.DeploymentBackend)
+	}
 }
 
-func newController(kfservingClient kfservice.ServingV1alpha2Interface, nsClient corev1.CoreV1Interface, deploymentConfig config.DeploymentConfig, containerFetcher ContainerFetcher) (Controller, error) {
+func newController(servingAPI inferenceServiceAPI, nsClient corev1.CoreV1Interface, deploymentConfig config.DeploymentConfig, containerFetcher ContainerFetcher) (Controller, error) {
 	return &controller{
-		servingClient:    kfservingClient,
+		servingAPI:       servingAPI,
 		clusterClient:    nsClient,
 		namespaceCreator: NewNamespaceCreator(nsClient, deploymentConfig.NamespaceTimeout),
 		config:           &deploymentConfig,
@@ -106,19 +133,8 @@ func newController(kfservingClient kfservice.ServingV1alpha2Interface, nsClient
 }
 
 func (k *controller) Deploy(modelService *models.Service) (*models.Service, error) {
-	if modelService.ResourceRequest != nil {
-		cpuRequest, _ := modelService.ResourceRequest.CpuRequest.AsInt64()
-		maxCpu, _ := k.config.MaxCpu.AsInt64()
-		if cpuRequest > maxCpu {
-			log.Errorf("insufficient available cpu resource to fulfil user request of %d", cpuRequest)
-			return nil, ErrInsufficientCpu
-		}
-		memRequest, _ := modelService.ResourceRequest.MemoryRequest.AsInt64()
-		maxMem, _ := k.config.MaxMemory.AsInt64()
-		if memRequest > maxMem {
-			log.Errorf("insufficient available memory resource to fulfil user request of %d", memRequest)
-			return nil, ErrInsufficientMem
-		}
+	if err := k.Validate(modelService); err != nil {
+		return nil, err
 	}
 
 	_, err := k.namespaceCreator.CreateNamespace(modelService.Namespace)
@@ -127,8 +143,13 @@ func (k *controller) Deploy(modelService *models.Service) (*models.Service, erro
 		return nil, ErrUnableToCreateNamespace
 	}
 
+	if err := applySecret(k.clusterClient, modelService); err != nil {
+		log.Errorf("unable to apply secret of inference service %s %v", modelService.Name, err)
+		return nil, ErrUnableToApplySecret
+	}
+
 	svcName := modelService.Name
-	s, err := k.servingClient.InferenceServices(modelService.Namespace).Get(svcName, metav1.GetOptions{})
+	s, err := k.servingAPI.get(modelService.Namespace, svcName)
 	if err != nil {
 		if !kerrors.IsNotFound(err) {
 			log.Errorf("unable to check inference service %s %v", svcName, err)
@@ -136,14 +157,14 @@ func (k *controller) Deploy(modelService *models.Service) (*models.Service, erro
 		}
 
 		// create new resource
-		s, err = k.servingClient.InferenceServices(modelService.Namespace).Create(createInferenceServiceSpec(modelService, k.config))
+		s, err = k.servingAPI.create(modelService, k.config)
 		if err != nil {
 			log.Errorf("unable to create inference service %s %v", svcName, err)
 			return nil, ErrUnableToCreateInferenceService
 		}
 	} else {
 		// existing resource found, do update
-		s, err = k.servingClient.InferenceServices(modelService.Namespace).Update(patchInferenceServiceSpec(s, modelService, k.config))
+		s, err = k.servingAPI.update(s, modelService, k.config)
 		if err != nil {
 			log.Errorf("unable to update inference service %s %v", svcName, err)
 			return nil, ErrUnableToUpdateInferenceService
@@ -155,51 +176,246 @@ func (k *controller) Deploy(modelService *models.Service) (*models.Service, erro
 		return nil, err
 	}
 
-	return &models.Service{
-		Name:        s.Name,
-		Namespace:   s.Namespace,
-		ServiceName: (*s.Status.Default)[constants.Predictor].Hostname,
-		Url:         s.Status.URL,
-	}, nil
+	svc := &models.Service{
+		Name:        s.GetName(),
+		Namespace:   s.GetNamespace(),
+		ServiceName: s.predictorHostname(),
+		Url:         s.modelURL(),
+	}
+	svc.Url, err = modelServiceURL(s.modelURL(), modelService)
+	if err != nil {
+		log.Errorf("invalid url of inference service %s: %v", svcName, err)
+		return nil, err
+	}
+	if s.hasExplainer() {
+		svc.ExplainerUrl = s.modelURL() + explainPathSuffix
+	}
+	return svc, nil
+}
+
+func (k *controller) Render(modelService *models.Service) ([]runtime.Object, error) {
+	if err := k.Validate(modelService); err != nil {
+		return nil, err
+	}
+
+	var objs []runtime.Object
+	if len(modelService.Secrets) > 0 {
+		objs = append(objs, renderSecretSpec(modelService))
+	}
+	return append(objs, k.servingAPI.render(modelService, k.config)), nil
+}
+
+func (k *controller) Validate(modelService *models.Service) error {
+	return validateModelService(modelService, k.config)
+}
+
+// validateModelService checks the model service against the resources and autoscaling bounds of the environment.
+func validateModelService(modelService *models.Service, config *config.DeploymentConfig) error {
+	if handler, err := modeltype.Get(modelService.Type); err == nil {
+		if err := handler.ValidateOptions(modelService.Options); err != nil {
+			log.Errorf("unable to deploy inference service %s: %v", modelService.Name, err)
+			return err
+		}
+	}
+
+	if modelService.ResourceRequest != nil {
+		if err := validateResourceRequest(modelService.ResourceRequest, config); err != nil {
+			log.Errorf("invalid resource request of inference service %s: %v", modelService.Name, err)
+			return err
+		}
+	}
+
+	if transformer := modelService.Transformer; transformer != nil && transformer.Enabled && transformer.ResourceRequest != nil {
+		if err := validateResourceRequest(transformer.ResourceRequest, config); err != nil {
+			log.Errorf("invalid resource request of transformer of inference service %s: %v", modelService.Name, err)
+			return err
+		}
+	}
+
+	if explainer := modelService.Explainer; explainer != nil && explainer.Enabled && explainer.ResourceRequest != nil {
+		if err := validateResourceRequest(explainer.ResourceRequest, config); err != nil {
+			log.Errorf("invalid resource request of explainer of inference service %s: %v", modelService.Name, err)
+			return err
+		}
+	}
+
+	if modelService.AutoscalingPolicy != nil {
+		if err := validateAutoscalingPolicy(modelService.AutoscalingPolicy, config.Autoscaling); err != nil {
+			log.Errorf("unable to deploy inference service %s with autoscaling policy %+v: %v", modelService.Name, *modelService.AutoscalingPolicy, err)
+			return err
+		}
+	}
+
+	if hasMountedSecret(modelService) && !supportsPodSpec(config) {
+		log.Errorf("unable to deploy inference service %s: %v", modelService.Name, ErrSecretMountNotSupported)
+		return ErrSecretMountNotSupported
+	}
+
+	if err := validateNodePool(modelService.NodePool, config); err != nil {
+		log.Errorf("unable to deploy inference service %s to node pool %s: %v", modelService.Name, modelService.NodePool, err)
+		return err
+	}
+	return nil
+}
+
+// validateNodePool checks that the requested node pool is allowed in the environment and can be set on the pods.
+func validateNodePool(nodePool string, config *config.DeploymentConfig) error {
+	if nodePool == "" {
+		return nil
+	}
+
+	if _, ok := config.Scheduling.NodePool(nodePool, config.Scheduling.DefaultOnlineNodePool); !ok {
+		return ErrNodePoolNotAllowed
+	}
+	if !supportsPodSpec(config) {
+		return ErrNodePoolNotSupported
+	}
+	return nil
+}
+
+func validateResourceRequest(resourceRequest *models.ResourceRequest, config *config.DeploymentConfig) error {
+	cpuRequest, _ := resourceRequest.CpuRequest.AsInt64()
+	maxCpu, _ := config.MaxCpu.AsInt64()
+	if cpuRequest > maxCpu {
+		log.Errorf("insufficient available cpu resource to fulfil user request of %d", cpuRequest)
+		return ErrInsufficientCpu
+	}
+	memRequest, _ := resourceRequest.MemoryRequest.AsInt64()
+	maxMem, _ := config.MaxMemory.AsInt64()
+	if memRequest > maxMem {
+		log.Errorf("insufficient available memory resource to fulfil user request of %d", memRequest)
+		return ErrInsufficientMem
+	}
+	if err := validateResourceLimits(resourceRequest, config); err != nil {
+		return err
+	}
+	return validateGPURequest(resourceRequest.GPURequest, config)
+}
+
+// validateResourceLimits checks that the explicit limits are not lower than the requests
+// and do not exceed the max limit ratio of the environment.
+func validateResourceLimits(resourceRequest *models.ResourceRequest, config *config.DeploymentConfig) error {
+	if limit := resourceRequest.CpuLimit; limit != nil && !limit.IsZero() {
+		if limit.Cmp(resourceRequest.CpuRequest) < 0 {
+			return ErrCpuLimitBelowRequest
+		}
+		if exceedsLimitRatio(*limit, resourceRequest.CpuRequest, config.MaxCpuLimitRatio) {
+			return ErrCpuLimitRatioTooLarge
+		}
+	}
+
+	if limit := resourceRequest.MemoryLimit; limit != nil && !limit.IsZero() {
+		if limit.Cmp(resourceRequest.MemoryRequest) < 0 {
+			return ErrMemLimitBelowRequest
+		}
+		if exceedsLimitRatio(*limit, resourceRequest.MemoryRequest, config.MaxMemoryLimitRatio) {
+			return ErrMemLimitRatioTooLarge
+		}
+	}
+	return nil
+}
+
+// validateGPURequest checks that the GPU type is allowed in the environment and the count is within its bounds.
+func validateGPURequest(gpuRequest *models.GPURequest, config *config.DeploymentConfig) error {
+	if gpuRequest == nil {
+		return nil
+	}
+
+	gpu, ok := config.GPU(gpuRequest.Name)
+	if !ok {
+		return ErrGPUNotAllowed
+	}
+	if gpuRequest.Count < 1 || (gpu.MaxCount > 0 && gpuRequest.Count > gpu.MaxCount) {
+		return ErrInvalidGPUCount
+	}
+	if !supportsPodSpec(config) && !supportsAnnotationScheduling(gpu) {
+		return ErrGPUSchedulingNotSupported
+	}
+	return nil
+}
+
+// supportsAnnotationScheduling returns whether the node pool of the GPU type can be selected with the GKE accelerator
+// annotation alone, which is the only scheduling constraint of the KFServing v1alpha2 inference services. The taint
+// of the GPU resource is tolerated by the pods requesting it without setting the toleration.
+func supportsAnnotationScheduling(gpu config.GPUConfig) bool {
+	for _, toleration := range gpu.Tolerations {
+		if toleration.Key != string(gpu.ResourceName()) {
+			return false
+		}
+	}
+	for key := range gpu.NodeSelector {
+		if key != gkeAcceleratorNodeSelector {
+			return false
+		}
+	}
+	return true
+}
+
+func exceedsLimitRatio(limit, request resource.Quantity, maxRatio float64) bool {
+	if maxRatio <= 0 || request.IsZero() {
+		return false
+	}
+	return float64(limit.MilliValue()) > float64(request.MilliValue())*maxRatio
 }
 
 func (k *controller) Delete(modelService *models.Service) (*models.Service, error) {
-	infSvc, err := k.servingClient.InferenceServices(modelService.Namespace).Get(modelService.Name, metav1.GetOptions{})
+	infSvc, err := k.servingAPI.get(modelService.Namespace, modelService.Name)
 	if err != nil {
 		if !kerrors.IsNotFound(err) {
-			return nil, errors.Wrapf(err, "unable to check status of inference service: %s", infSvc.Name)
+			return nil, errors.Wrapf(err, "unable to check status of inference service: %s", modelService.Name)
+		}
+	} else {
+		gracePeriod := int64(deletionGracePeriodSecond)
+		err = k.servingAPI.delete(modelService.Namespace, modelService.Name, &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to delete inference service: %s", infSvc.GetName())
 		}
-		return modelService, nil
-	}
-	gracePeriod := int64(deletionGracePeriodSecond)
-	err = k.servingClient.InferenceServices(modelService.Namespace).Delete(modelService.Name, &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
-	if err != nil {
-		return nil, errors.Wrapf(err, "unable to delete inference service: %s", infSvc.Name)
 	}
 
+	if err := deleteSecret(k.clusterClient, modelService); err != nil {
+		return nil, errors.Wrapf(err, "unable to delete secret: %s", secretResourceName(modelService))
+	}
 	return modelService, nil
 }
 
-func (k *controller) waitInferenceServiceReady(service *kfsv1alpha2.InferenceService) (*kfsv1alpha2.InferenceService, error) {
+func (k *controller) Scale(modelService *models.Service) error {
+	err := k.servingAPI.scale(modelService.Namespace, modelService.Name, minReplica(modelService), modelService.ResourceRequest.MaxReplica)
+	if err != nil {
+		log.Errorf("unable to scale inference service %s %v", modelService.Name, err)
+		return ErrUnableToScaleInferenceService
+	}
+	return nil
+}
+
+func (k *controller) waitInferenceServiceReady(service inferenceService) (inferenceService, error) {
 	timeout := time.After(k.config.DeploymentTimeout)
 	ticker := time.Tick(time.Second * tickDurationSecond)
 
 	for {
 		select {
 		case <-timeout:
-			log.Errorf("timeout waiting for inference service to be ready %s", service.Name)
+			log.Errorf("timeout waiting for inference service to be ready %s", service.GetName())
 			return nil, ErrTimeoutCreateInferenceService
 		case <-ticker:
-			s, err := k.servingClient.InferenceServices(service.Namespace).Get(service.Name, metav1.GetOptions{})
+			s, err := k.servingAPI.get(service.GetNamespace(), service.GetName())
 			if err != nil {
-				log.Errorf("unable to get inference service status %s %v", service.Name, err)
+				log.Errorf("unable to get inference service status %s %v", service.GetName(), err)
 				return nil, ErrUnableToGetInferenceServiceStatus
 			}
 
-			if s.Status.IsReady() {
+			if isInferenceServiceReady(s) {
 				// Inference service is completely ready
 				return s, nil
 			}
 		}
 	}
 }
+
+// modelServiceURL returns the URL of the version endpoint of the model service from the URL of its KFServing model,
+// as defined by its model type.
+func modelServiceURL(modelURL string, modelService *models.Service) (string, error) {
+	if handler, err := modeltype.Get(modelService.Type); err == nil {
+		return handler.URL(modelURL, modelService.Options)
+	}
+	return modelURL, nil
+}
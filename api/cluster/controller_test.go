@@ -47,6 +47,7 @@ const (
 	getMethod    = "get"
 	createMethod = "create"
 	updateMethod = "update"
+	patchMethod  = "patch"
 
 	kfservingGroup           = "kubeflow.com/kfserving"
 	inferenceServiceResource = "inferenceservices"
@@ -265,7 +266,7 @@ func TestController_DeployInferenceService_NamespaceCreation(t *testing.T) {
 			}
 
 			containerFetcher := NewContainerFetcher(v1Client, clusterMetadata)
-			ctl, _ := newController(kfClient, v1Client, deployConfig, containerFetcher)
+			ctl, _ := newController(&v1alpha2API{servingClient: kfClient}, v1Client, deployConfig, containerFetcher)
 			iSvc, err := ctl.Deploy(modelSvc)
 
 			if tt.wantError {
@@ -380,6 +381,347 @@ func TestController_DeployInferenceService(t *testing.T) {
 			deployTimeout,
 			false,
 		},
+		{
+			"success: deploying service with autoscaling policy",
+			&models.Service{
+				Name:              svcName,
+				Namespace:         project.Name,
+				Options:           modelOpt,
+				AutoscalingPolicy: &models.AutoscalingPolicy{MetricType: models.AutoscalingMetricConcurrency, TargetValue: 10},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			false,
+		},
+		{
+			"error: autoscaling metric not allowed",
+			&models.Service{
+				Name:              svcName,
+				Namespace:         project.Name,
+				Options:           modelOpt,
+				AutoscalingPolicy: &models.AutoscalingPolicy{MetricType: models.AutoscalingMetricRPS, TargetValue: 10},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			true,
+		},
+		{
+			"error: autoscaling target out of bounds",
+			&models.Service{
+				Name:              svcName,
+				Namespace:         project.Name,
+				Options:           modelOpt,
+				AutoscalingPolicy: &models.AutoscalingPolicy{MetricType: models.AutoscalingMetricConcurrency, TargetValue: 500},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			true,
+		},
+		{
+			"error: scale to zero not allowed",
+			&models.Service{
+				Name:              svcName,
+				Namespace:         project.Name,
+				Options:           modelOpt,
+				AutoscalingPolicy: &models.AutoscalingPolicy{MetricType: models.AutoscalingMetricConcurrency, TargetValue: 10, ScaleToZero: true},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			true,
+		},
+		{
+			"success: deploying service with resource limits",
+			&models.Service{
+				Name:      svcName,
+				Namespace: project.Name,
+				Options:   modelOpt,
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    2,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+					CpuLimit:      quantity("1"),
+					MemoryLimit:   quantity("4Gi"),
+				},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			false,
+		},
+		{
+			"error: cpu limit lower than request",
+			&models.Service{
+				Name:      svcName,
+				Namespace: project.Name,
+				Options:   modelOpt,
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    2,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+					CpuLimit:      quantity("500m"),
+					MemoryLimit:   quantity("1Gi"),
+				},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			true,
+		},
+		{
+			"error: memory limit lower than request",
+			&models.Service{
+				Name:      svcName,
+				Namespace: project.Name,
+				Options:   modelOpt,
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    2,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+					CpuLimit:      quantity("1"),
+					MemoryLimit:   quantity("512Mi"),
+				},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			true,
+		},
+		{
+			"error: cpu limit ratio too large",
+			&models.Service{
+				Name:      svcName,
+				Namespace: project.Name,
+				Options:   modelOpt,
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    2,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+					CpuLimit:      quantity("5"),
+					MemoryLimit:   quantity("1Gi"),
+				},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			true,
+		},
+		{
+			"error: memory limit ratio too large",
+			&models.Service{
+				Name:      svcName,
+				Namespace: project.Name,
+				Options:   modelOpt,
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    2,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+					CpuLimit:      quantity("1"),
+					MemoryLimit:   quantity("8Gi"),
+				},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			true,
+		},
+		{
+			"success: deploying service with gpu",
+			&models.Service{
+				Name:      svcName,
+				Namespace: project.Name,
+				Options:   modelOpt,
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    1,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+					GPURequest:    &models.GPURequest{Name: "nvidia-tesla-t4", Count: 1},
+				},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			false,
+		},
+		{
+			"error: gpu type not allowed",
+			&models.Service{
+				Name:      svcName,
+				Namespace: project.Name,
+				Options:   modelOpt,
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    1,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+					GPURequest:    &models.GPURequest{Name: "nvidia-tesla-v100", Count: 1},
+				},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			true,
+		},
+		{
+			"error: gpu count too large",
+			&models.Service{
+				Name:      svcName,
+				Namespace: project.Name,
+				Options:   modelOpt,
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    1,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+					GPURequest:    &models.GPURequest{Name: "nvidia-tesla-t4", Count: 8},
+				},
+			},
+			&inferenceServiceReactor{
+				nil,
+				kerrors.NewNotFound(schema.GroupResource{Group: kfservingGroup, Resource: inferenceServiceResource}, svcName)},
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name}},
+				nil},
+			nil,
+			&inferenceServiceReactor{
+				&v1alpha2.InferenceService{
+					ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: project.Name},
+					Status:     statusReady},
+				nil,
+			},
+			deployTimeout,
+			true,
+		},
 		{
 			"error: failed get",
 			modelSvc,
@@ -589,14 +931,24 @@ func TestController_DeployInferenceService(t *testing.T) {
 			})
 
 			deployConfig := config.DeploymentConfig{
-				DeploymentTimeout: tt.deployTimeout,
-				NamespaceTimeout:  2 * tickDurationSecond * time.Second,
-				MaxCpu:            resource.MustParse("8"),
-				MaxMemory:         resource.MustParse("8Gi"),
+				DeploymentTimeout:   tt.deployTimeout,
+				NamespaceTimeout:    2 * tickDurationSecond * time.Second,
+				MaxCpu:              resource.MustParse("8"),
+				MaxMemory:           resource.MustParse("8Gi"),
+				MaxCpuLimitRatio:    4,
+				MaxMemoryLimitRatio: 4,
+				GPUs: []config.GPUConfig{
+					{Name: "nvidia-tesla-t4", MaxCount: 2},
+				},
+				Autoscaling: config.AutoscalingConfig{
+					Targets: map[string]config.AutoscalingTargetBounds{
+						"concurrency": {Min: 1, Max: 100},
+					},
+				},
 			}
 
 			containerFetcher := NewContainerFetcher(v1Client, clusterMetadata)
-			ctl, _ := newController(kfClient, v1Client, deployConfig, containerFetcher)
+			ctl, _ := newController(&v1alpha2API{servingClient: kfClient}, v1Client, deployConfig, containerFetcher)
 			iSvc, err := ctl.Deploy(tt.modelService)
 
 			if tt.wantError {
@@ -610,6 +962,164 @@ func TestController_DeployInferenceService(t *testing.T) {
 	}
 }
 
+func TestController_Render(t *testing.T) {
+	svcName := models.CreateInferenceServiceName("my-model", "1")
+	deployConfig := config.DeploymentConfig{
+		MaxCpu:    resource.MustParse("8"),
+		MaxMemory: resource.MustParse("8Gi"),
+	}
+
+	tests := []struct {
+		name         string
+		modelService *models.Service
+		wantKinds    []string
+		wantErr      error
+	}{
+		{
+			name: "inference service",
+			modelService: &models.Service{
+				Name:      svcName,
+				Namespace: "my-project",
+				Options:   &models.ModelOption{},
+			},
+			wantKinds: []string{"InferenceService"},
+		},
+		{
+			name: "inference service with project secrets",
+			modelService: &models.Service{
+				Name:      svcName,
+				Namespace: "my-project",
+				Options:   &models.ModelOption{},
+				Secrets:   map[string]string{"db-password": "hunter2"},
+			},
+			wantKinds: []string{"Secret", "InferenceService"},
+		},
+		{
+			name: "insufficient cpu",
+			modelService: &models.Service{
+				Name:      svcName,
+				Namespace: "my-project",
+				Options:   &models.ModelOption{},
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    1,
+					CpuRequest:    resource.MustParse("10"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+			},
+			wantErr: ErrInsufficientCpu,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kfClient := fakeserving.NewSimpleClientset().ServingV1alpha2().(*fakeservingv1alpha2.FakeServingV1alpha2)
+			v1Client := fake.NewSimpleClientset().CoreV1()
+
+			ctl, _ := newController(&v1alpha2API{servingClient: kfClient}, v1Client, deployConfig, NewContainerFetcher(v1Client, clusterMetadata))
+			objs, err := ctl.Render(tt.modelService)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			var kinds []string
+			for _, obj := range objs {
+				kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+			}
+			assert.Equal(t, tt.wantKinds, kinds)
+			assert.Equal(t, svcName, objs[len(objs)-1].(*v1alpha2.InferenceService).Name)
+			// nothing is applied to the cluster
+			assert.Empty(t, kfClient.Actions())
+			assert.Empty(t, v1Client.(*fakecorev1.FakeCoreV1).Actions())
+		})
+	}
+}
+
+func TestController_Scale(t *testing.T) {
+	svcName := models.CreateInferenceServiceName("my-model", "1")
+	isvc := &v1alpha2.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: "my-project"},
+		Spec: v1alpha2.InferenceServiceSpec{
+			Default: v1alpha2.EndpointSpec{
+				Predictor: v1alpha2.PredictorSpec{
+					DeploymentSpec: v1alpha2.DeploymentSpec{MinReplicas: 1, MaxReplicas: 2},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		modelService    *models.Service
+		wantMinReplicas int
+		wantMaxReplicas int
+		patchErr        error
+		wantErr         error
+	}{
+		{
+			name: "scale up",
+			modelService: &models.Service{
+				Name:            svcName,
+				Namespace:       "my-project",
+				ResourceRequest: &models.ResourceRequest{MinReplica: 3, MaxReplica: 5},
+			},
+			wantMinReplicas: 3,
+			wantMaxReplicas: 5,
+		},
+		{
+			name: "scale to zero policy",
+			modelService: &models.Service{
+				Name:              svcName,
+				Namespace:         "my-project",
+				ResourceRequest:   &models.ResourceRequest{MinReplica: 2, MaxReplica: 4},
+				AutoscalingPolicy: &models.AutoscalingPolicy{MetricType: models.AutoscalingMetricConcurrency, TargetValue: 10, ScaleToZero: true},
+			},
+			wantMinReplicas: 0,
+			wantMaxReplicas: 4,
+		},
+		{
+			name: "patch failure",
+			modelService: &models.Service{
+				Name:            svcName,
+				Namespace:       "my-project",
+				ResourceRequest: &models.ResourceRequest{MinReplica: 3, MaxReplica: 5},
+			},
+			patchErr: errors.New("connection refused"),
+			wantErr:  ErrUnableToScaleInferenceService,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kfClient := fakeserving.NewSimpleClientset(isvc.DeepCopy()).ServingV1alpha2().(*fakeservingv1alpha2.FakeServingV1alpha2)
+			kfClient.PrependReactor(patchMethod, inferenceServiceResource, func(action ktesting.Action) (bool, runtime.Object, error) {
+				return tt.patchErr != nil, nil, tt.patchErr
+			})
+			v1Client := fake.NewSimpleClientset().CoreV1()
+
+			ctl, _ := newController(&v1alpha2API{servingClient: kfClient}, v1Client, config.DeploymentConfig{}, NewContainerFetcher(v1Client, clusterMetadata))
+			err := ctl.Scale(tt.modelService)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			scaled, err := kfClient.InferenceServices("my-project").Get(svcName, metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantMinReplicas, scaled.Spec.Default.Predictor.MinReplicas)
+			assert.Equal(t, tt.wantMaxReplicas, scaled.Spec.Default.Predictor.MaxReplicas)
+		})
+	}
+}
+
+func quantity(value string) *resource.Quantity {
+	q := resource.MustParse(value)
+	return &q
+}
+
 func fakeInferenceService(svcName string, namespace string, status v1alpha2.InferenceServiceStatus) *v1alpha2.InferenceService {
 	return &v1alpha2.InferenceService{ObjectMeta: metav1.ObjectMeta{Name: svcName, Namespace: namespace}, Status: status}
 }
@@ -691,3 +1201,150 @@ func isIn(container v1.Container, containers []*models.Container, podName string
 	}
 	return false
 }
+
+func TestModelServiceURL(t *testing.T) {
+	modelURL := "http://my-model-1.project.example.com/v1/models/my-model-1"
+
+	tests := []struct {
+		name         string
+		modelService *models.Service
+		want         string
+	}{
+		{
+			name:         "kfserving model server",
+			modelService: &models.Service{Type: models.ModelTypeTensorflow, Options: &models.ModelOption{}},
+			want:         modelURL,
+		},
+		{
+			name: "custom model with default predict path",
+			modelService: &models.Service{Type: models.ModelTypeCustom, Options: &models.ModelOption{
+				CustomPredictor: &models.CustomPredictor{Image: "gojek/my-model:1"},
+			}},
+			want: "http://my-model-1.project.example.com/v1/models/my-model-1:predict",
+		},
+		{
+			name: "custom model with custom predict path",
+			modelService: &models.Service{Type: models.ModelTypeCustom, Options: &models.ModelOption{
+				CustomPredictor: &models.CustomPredictor{Image: "gojek/my-model:1", PredictPath: "/v2/models/my-model/infer"},
+			}},
+			want: "http://my-model-1.project.example.com/v2/models/my-model/infer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := modelServiceURL(modelURL, tt.modelService)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateGPURequest(t *testing.T) {
+	gpus := []config.GPUConfig{
+		{
+			Name:         "nvidia-tesla-t4",
+			MaxCount:     2,
+			NodeSelector: map[string]string{gkeAcceleratorNodeSelector: "nvidia-tesla-t4"},
+			Tolerations:  []v1.Toleration{{Key: "nvidia.com/gpu", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}},
+		},
+		{
+			Name:         "nvidia-tesla-a100",
+			MaxCount:     2,
+			NodeSelector: map[string]string{"node-pool": "gpu-a100"},
+			Tolerations:  []v1.Toleration{{Key: "gpu-a100", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		gpuRequest *models.GPURequest
+		apiVersion string
+		wantErr    error
+	}{
+		{
+			name:       "gke accelerator on v1alpha2",
+			gpuRequest: &models.GPURequest{Name: "nvidia-tesla-t4", Count: 1},
+			apiVersion: config.KFServingV1alpha2,
+		},
+		{
+			name:       "tolerations on v1alpha2",
+			gpuRequest: &models.GPURequest{Name: "nvidia-tesla-a100", Count: 1},
+			apiVersion: config.KFServingV1alpha2,
+			wantErr:    ErrGPUSchedulingNotSupported,
+		},
+		{
+			name:       "tolerations on v1beta1",
+			gpuRequest: &models.GPURequest{Name: "nvidia-tesla-a100", Count: 1},
+			apiVersion: config.KFServingV1beta1,
+		},
+		{
+			name:       "gpu type not allowed",
+			gpuRequest: &models.GPURequest{Name: "nvidia-tesla-v100", Count: 1},
+			apiVersion: config.KFServingV1beta1,
+			wantErr:    ErrGPUNotAllowed,
+		},
+		{
+			name:       "gpu count too large",
+			gpuRequest: &models.GPURequest{Name: "nvidia-tesla-t4", Count: 4},
+			apiVersion: config.KFServingV1beta1,
+			wantErr:    ErrInvalidGPUCount,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGPURequest(tt.gpuRequest, &config.DeploymentConfig{KFServingAPIVersion: tt.apiVersion, GPUs: gpus})
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestValidateModelService_SecretMount(t *testing.T) {
+	mountedSecret := models.EnvVars{{Name: "CREDENTIALS", SecretName: "credentials", MountPath: "/mnt/credentials"}}
+
+	tests := []struct {
+		name       string
+		modelSvc   *models.Service
+		apiVersion string
+		wantErr    error
+	}{
+		{
+			name:       "secret mounted by model on v1alpha2",
+			modelSvc:   &models.Service{Name: "model-1", EnvVars: mountedSecret},
+			apiVersion: config.KFServingV1alpha2,
+			wantErr:    ErrSecretMountNotSupported,
+		},
+		{
+			name: "secret mounted by explainer on v1alpha2",
+			modelSvc: &models.Service{
+				Name: "model-1",
+				Explainer: &models.Explainer{
+					Enabled:       true,
+					ExplainerType: models.CustomExplainerType,
+					Image:         "gojek/my-explainer:1",
+					EnvVars:       mountedSecret,
+				},
+			},
+			apiVersion: config.KFServingV1alpha2,
+			wantErr:    ErrSecretMountNotSupported,
+		},
+		{
+			name: "secret mounted by explainer on v1beta1",
+			modelSvc: &models.Service{
+				Name: "model-1",
+				Explainer: &models.Explainer{
+					Enabled:       true,
+					ExplainerType: models.CustomExplainerType,
+					Image:         "gojek/my-explainer:1",
+					EnvVars:       mountedSecret,
+				},
+			},
+			apiVersion: config.KFServingV1beta1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateModelService(tt.modelSvc, &config.DeploymentConfig{KFServingAPIVersion: tt.apiVersion})
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
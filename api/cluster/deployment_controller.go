@@ -0,0 +1,294 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/models"
+)
+
+// deploymentController deploys version endpoints as a Deployment, a Service and a HorizontalPodAutoscaler,
+// for clusters without Knative and KFServing. Only the model types served by a container, i.e. pyfunc and custom,
+// can be deployed, without transformer nor explainer.
+type deploymentController struct {
+	kubeClient       kubernetes.Interface
+	namespaceCreator NamespaceCreator
+	config           *config.DeploymentConfig
+	ContainerFetcher
+}
+
+func newDeploymentController(kubeClient kubernetes.Interface, deploymentConfig config.DeploymentConfig, containerFetcher ContainerFetcher) Controller {
+	return &deploymentController{
+		kubeClient:       kubeClient,
+		namespaceCreator: NewNamespaceCreator(kubeClient.CoreV1(), deploymentConfig.NamespaceTimeout),
+		config:           &deploymentConfig,
+		ContainerFetcher: containerFetcher,
+	}
+}
+
+func (k *deploymentController) Deploy(modelService *models.Service) (*models.Service, error) {
+	if err := k.Validate(modelService); err != nil {
+		return nil, err
+	}
+
+	deployment, err := createDeploymentSpec(modelService, k.config)
+	if err != nil {
+		log.Errorf("unable to deploy %s of type %s: %v", modelService.Name, modelService.Type, err)
+		return nil, err
+	}
+
+	_, err = k.namespaceCreator.CreateNamespace(modelService.Namespace)
+	if err != nil {
+		log.Errorf("unable to create namespace %s %v", modelService.Namespace, err)
+		return nil, ErrUnableToCreateNamespace
+	}
+
+	if err := applySecret(k.kubeClient.CoreV1(), modelService); err != nil {
+		log.Errorf("unable to apply secret %s %v", modelService.Name, err)
+		return nil, ErrUnableToApplySecret
+	}
+	if err := k.applyDeployment(deployment); err != nil {
+		log.Errorf("unable to apply deployment %s %v", modelService.Name, err)
+		return nil, ErrUnableToApplyDeployment
+	}
+	if err := k.applyService(createServiceSpec(modelService, deployment)); err != nil {
+		log.Errorf("unable to apply service %s %v", modelService.Name, err)
+		return nil, ErrUnableToApplyDeployment
+	}
+	if err := k.applyHorizontalPodAutoscaler(createHorizontalPodAutoscalerSpec(modelService)); err != nil {
+		log.Errorf("unable to apply horizontal pod autoscaler %s %v", modelService.Name, err)
+		return nil, ErrUnableToApplyDeployment
+	}
+
+	if err := k.waitDeploymentReady(modelService.Namespace, modelService.Name); err != nil {
+		return nil, err
+	}
+
+	hostname := serviceHostname(modelService)
+	modelURL := fmt.Sprintf("http://%s/v1/models/%s", hostname, modelService.Name)
+	url, err := modelServiceURL(modelURL, modelService)
+	if err != nil {
+		log.Errorf("invalid url of deployment %s: %v", modelService.Name, err)
+		return nil, err
+	}
+	return &models.Service{
+		Name:        modelService.Name,
+		Namespace:   modelService.Namespace,
+		ServiceName: hostname,
+		Url:         url,
+	}, nil
+}
+
+func (k *deploymentController) Render(modelService *models.Service) ([]runtime.Object, error) {
+	if err := k.Validate(modelService); err != nil {
+		return nil, err
+	}
+
+	deployment, err := createDeploymentSpec(modelService, k.config)
+	if err != nil {
+		log.Errorf("unable to deploy %s of type %s: %v", modelService.Name, modelService.Type, err)
+		return nil, err
+	}
+	service := createServiceSpec(modelService, deployment)
+	hpa := createHorizontalPodAutoscalerSpec(modelService)
+
+	deployment.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	service.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Service"))
+	hpa.SetGroupVersionKind(autoscalingv1.SchemeGroupVersion.WithKind("HorizontalPodAutoscaler"))
+
+	var objs []runtime.Object
+	if len(modelService.Secrets) > 0 {
+		objs = append(objs, renderSecretSpec(modelService))
+	}
+	return append(objs, deployment, service, hpa), nil
+}
+
+func (k *deploymentController) Validate(modelService *models.Service) error {
+	if err := validateModelService(modelService, k.config); err != nil {
+		return err
+	}
+
+	if err := validateDeploymentBackendSupport(modelService); err != nil {
+		log.Errorf("unable to deploy %s of type %s: %v", modelService.Name, modelService.Type, err)
+		return err
+	}
+	return nil
+}
+
+// validateDeploymentBackendSupport checks that the model service only uses the features supported by the backend.
+func validateDeploymentBackendSupport(modelService *models.Service) error {
+	if (modelService.Transformer != nil && modelService.Transformer.Enabled) ||
+		(modelService.Explainer != nil && modelService.Explainer.Enabled) {
+		return ErrComponentNotSupported
+	}
+
+	if policy := modelService.AutoscalingPolicy; policy != nil {
+		if policy.ScaleToZero {
+			return ErrScaleToZeroNotSupported
+		}
+		// the horizontal pod autoscaler only scales on resource metrics
+		if policy.MetricType != models.AutoscalingMetricCPU {
+			return ErrAutoscalingMetricNotSupported
+		}
+	}
+	return nil
+}
+
+func (k *deploymentController) applyDeployment(deployment *appsv1.Deployment) error {
+	deployments := k.kubeClient.AppsV1().Deployments(deployment.Namespace)
+	orig, err := deployments.Get(deployment.Name, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+		_, err = deployments.Create(deployment)
+		return err
+	}
+
+	// the number of replicas is managed by the horizontal pod autoscaler
+	deployment.Spec.Replicas = orig.Spec.Replicas
+	orig.Labels = deployment.Labels
+	orig.Spec = deployment.Spec
+	_, err = deployments.Update(orig)
+	return err
+}
+
+func (k *deploymentController) applyService(service *v1.Service) error {
+	services := k.kubeClient.CoreV1().Services(service.Namespace)
+	orig, err := services.Get(service.Name, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+		_, err = services.Create(service)
+		return err
+	}
+
+	// the cluster IP of the existing service is kept
+	orig.Labels = service.Labels
+	orig.Spec.Selector = service.Spec.Selector
+	orig.Spec.Ports = service.Spec.Ports
+	_, err = services.Update(orig)
+	return err
+}
+
+func (k *deploymentController) applyHorizontalPodAutoscaler(hpa *autoscalingv1.HorizontalPodAutoscaler) error {
+	hpas := k.kubeClient.AutoscalingV1().HorizontalPodAutoscalers(hpa.Namespace)
+	orig, err := hpas.Get(hpa.Name, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+		_, err = hpas.Create(hpa)
+		return err
+	}
+
+	orig.Labels = hpa.Labels
+	orig.Spec = hpa.Spec
+	_, err = hpas.Update(orig)
+	return err
+}
+
+func (k *deploymentController) Delete(modelService *models.Service) (*models.Service, error) {
+	gracePeriod := int64(deletionGracePeriodSecond)
+	options := &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod}
+
+	err := k.kubeClient.AutoscalingV1().HorizontalPodAutoscalers(modelService.Namespace).Delete(modelService.Name, options)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "unable to delete horizontal pod autoscaler: %s", modelService.Name)
+	}
+	err = k.kubeClient.CoreV1().Services(modelService.Namespace).Delete(modelService.Name, options)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "unable to delete service: %s", modelService.Name)
+	}
+	err = k.kubeClient.AppsV1().Deployments(modelService.Namespace).Delete(modelService.Name, options)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "unable to delete deployment: %s", modelService.Name)
+	}
+	if err := deleteSecret(k.kubeClient.CoreV1(), modelService); err != nil {
+		return nil, errors.Wrapf(err, "unable to delete secret: %s", secretResourceName(modelService))
+	}
+
+	return modelService, nil
+}
+
+// Scale patches the replicas of the horizontal pod autoscaler of the deployment.
+func (k *deploymentController) Scale(modelService *models.Service) error {
+	hpa := createHorizontalPodAutoscalerSpec(modelService)
+	patch, err := replicasPatch(int(*hpa.Spec.MinReplicas), int(hpa.Spec.MaxReplicas), "spec")
+	if err != nil {
+		return err
+	}
+
+	_, err = k.kubeClient.AutoscalingV1().HorizontalPodAutoscalers(modelService.Namespace).Patch(modelService.Name, types.MergePatchType, patch)
+	if err != nil {
+		log.Errorf("unable to scale deployment %s %v", modelService.Name, err)
+		return ErrUnableToScaleDeployment
+	}
+	return nil
+}
+
+func (k *deploymentController) waitDeploymentReady(namespace, name string) error {
+	timeout := time.After(k.config.DeploymentTimeout)
+	ticker := time.Tick(time.Second * tickDurationSecond)
+
+	for {
+		select {
+		case <-timeout:
+			log.Errorf("timeout waiting for deployment to be ready %s", name)
+			return ErrTimeoutCreateDeployment
+		case <-ticker:
+			deployment, err := k.kubeClient.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+			if err != nil {
+				log.Errorf("unable to get deployment status %s %v", name, err)
+				return ErrUnableToGetDeploymentStatus
+			}
+
+			if isDeploymentReady(deployment) {
+				return nil
+			}
+		}
+	}
+}
+
+// isDeploymentReady returns true when the rollout of the deployment is complete, the same way as kubectl rollout status.
+func isDeploymentReady(deployment *appsv1.Deployment) bool {
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return false
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	return status.UpdatedReplicas >= replicas &&
+		status.Replicas == status.UpdatedReplicas &&
+		status.AvailableReplicas >= status.UpdatedReplicas
+}
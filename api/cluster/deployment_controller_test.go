@@ -0,0 +1,373 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+// +build unit
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/models"
+)
+
+const deploymentResource = "deployments"
+
+func TestDeploymentController_Deploy(t *testing.T) {
+	namespace := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "project"},
+		Status:     v1.NamespaceStatus{Phase: v1.NamespaceActive},
+	}
+	existingReplicas := int32(3)
+	targetCPUUtilization := int32(50)
+
+	tests := []struct {
+		name         string
+		existing     []runtime.Object
+		modelSvc     *models.Service
+		want         *models.Service
+		wantErr      error
+		wantReplicas int32
+		wantPort     int32
+		wantCPU      *int32
+	}{
+		{
+			name: "pyfunc model",
+			modelSvc: &models.Service{
+				Name:      "model-1",
+				Namespace: "project",
+				Type:      models.ModelTypePyFunc,
+				Options:   &models.ModelOption{PyFuncImageName: "gojek/my-model:1"},
+			},
+			want: &models.Service{
+				Name:        "model-1",
+				Namespace:   "project",
+				ServiceName: "model-1.project.svc.cluster.local",
+				Url:         "http://model-1.project.svc.cluster.local/v1/models/model-1",
+			},
+			wantReplicas: 1,
+			wantPort:     defaultModelContainerPort,
+		},
+		{
+			name: "custom model with cpu autoscaling",
+			modelSvc: &models.Service{
+				Name:      "model-1",
+				Namespace: "project",
+				Type:      models.ModelTypeCustom,
+				Options: &models.ModelOption{
+					CustomPredictor: &models.CustomPredictor{
+						Image:       "gojek/my-model:1",
+						Ports:       []models.ContainerPort{{Name: "http", Port: 9000}},
+						PredictPath: "/v2/models/model/infer",
+					},
+				},
+				AutoscalingPolicy: &models.AutoscalingPolicy{
+					MetricType:  models.AutoscalingMetricCPU,
+					TargetValue: 50,
+				},
+			},
+			want: &models.Service{
+				Name:        "model-1",
+				Namespace:   "project",
+				ServiceName: "model-1.project.svc.cluster.local",
+				Url:         "http://model-1.project.svc.cluster.local/v2/models/model/infer",
+			},
+			wantReplicas: 1,
+			wantPort:     9000,
+			wantCPU:      &targetCPUUtilization,
+		},
+		{
+			name: "update keeps the replicas of the autoscaler",
+			existing: []runtime.Object{
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: "model-1", Namespace: "project"},
+					Spec:       appsv1.DeploymentSpec{Replicas: &existingReplicas},
+				},
+				&v1.Service{
+					ObjectMeta: metav1.ObjectMeta{Name: "model-1", Namespace: "project"},
+					Spec:       v1.ServiceSpec{ClusterIP: "10.0.0.1"},
+				},
+			},
+			modelSvc: &models.Service{
+				Name:      "model-1",
+				Namespace: "project",
+				Type:      models.ModelTypePyFunc,
+				Options:   &models.ModelOption{PyFuncImageName: "gojek/my-model:2"},
+			},
+			want: &models.Service{
+				Name:        "model-1",
+				Namespace:   "project",
+				ServiceName: "model-1.project.svc.cluster.local",
+				Url:         "http://model-1.project.svc.cluster.local/v1/models/model-1",
+			},
+			wantReplicas: existingReplicas,
+			wantPort:     defaultModelContainerPort,
+		},
+		{
+			name: "model served by kfserving",
+			modelSvc: &models.Service{
+				Name:        "model-1",
+				Namespace:   "project",
+				ArtifactUri: "gs://my-artifact",
+				Type:        models.ModelTypeSkLearn,
+				Options:     &models.ModelOption{},
+			},
+			wantErr: ErrModelTypeNotSupported,
+		},
+		{
+			name: "transformer",
+			modelSvc: &models.Service{
+				Name:        "model-1",
+				Namespace:   "project",
+				Type:        models.ModelTypePyFunc,
+				Options:     &models.ModelOption{PyFuncImageName: "gojek/my-model:1"},
+				Transformer: &models.Transformer{Enabled: true, Image: "gojek/my-transformer:1"},
+			},
+			wantErr: ErrComponentNotSupported,
+		},
+		{
+			name: "concurrency autoscaling",
+			modelSvc: &models.Service{
+				Name:      "model-1",
+				Namespace: "project",
+				Type:      models.ModelTypePyFunc,
+				Options:   &models.ModelOption{PyFuncImageName: "gojek/my-model:1"},
+				AutoscalingPolicy: &models.AutoscalingPolicy{
+					MetricType:  models.AutoscalingMetricConcurrency,
+					TargetValue: 1,
+				},
+			},
+			wantErr: ErrAutoscalingMetricNotSupported,
+		},
+		{
+			name: "scale to zero",
+			modelSvc: &models.Service{
+				Name:      "model-1",
+				Namespace: "project",
+				Type:      models.ModelTypePyFunc,
+				Options:   &models.ModelOption{PyFuncImageName: "gojek/my-model:1"},
+				AutoscalingPolicy: &models.AutoscalingPolicy{
+					MetricType:  models.AutoscalingMetricConcurrency,
+					TargetValue: 1,
+					ScaleToZero: true,
+				},
+			},
+			wantErr: ErrScaleToZeroNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset(append(tt.existing, namespace)...)
+			// the applied deployment completes its rollout
+			var applied *appsv1.Deployment
+			applyReactor := func(action ktesting.Action) (bool, runtime.Object, error) {
+				applied = action.(ktesting.CreateAction).GetObject().(*appsv1.Deployment).DeepCopy()
+				return false, nil, nil
+			}
+			kubeClient.PrependReactor(createMethod, deploymentResource, applyReactor)
+			kubeClient.PrependReactor(updateMethod, deploymentResource, applyReactor)
+			kubeClient.PrependReactor(getMethod, deploymentResource, func(action ktesting.Action) (bool, runtime.Object, error) {
+				if applied == nil {
+					return false, nil, nil
+				}
+				ready := applied.DeepCopy()
+				ready.Status = appsv1.DeploymentStatus{
+					Replicas:          *ready.Spec.Replicas,
+					UpdatedReplicas:   *ready.Spec.Replicas,
+					AvailableReplicas: *ready.Spec.Replicas,
+				}
+				return true, ready, nil
+			})
+
+			deployConfig := config.DeploymentConfig{
+				DeploymentTimeout: 2 * tickDurationSecond * time.Second,
+				NamespaceTimeout:  2 * tickDurationSecond * time.Second,
+				MinReplica:        1,
+				MaxReplica:        2,
+				CpuRequest:        resource.MustParse("1"),
+				MemoryRequest:     resource.MustParse("1Gi"),
+				MaxCpu:            resource.MustParse("8"),
+				MaxMemory:         resource.MustParse("8Gi"),
+				Autoscaling:       config.AutoscalingConfig{AllowScaleToZero: true},
+			}
+
+			ctl := newDeploymentController(kubeClient, deployConfig, NewContainerFetcher(kubeClient.CoreV1(), clusterMetadata))
+			svc, err := ctl.Deploy(tt.modelSvc)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, svc)
+
+			assert.Equal(t, tt.wantReplicas, *applied.Spec.Replicas)
+			assert.Equal(t, "model-1", applied.Spec.Selector.MatchLabels[labelInferenceServiceName])
+			assert.Equal(t, "merlin", applied.Spec.Template.Labels[labelOrchestratorName])
+			container := applied.Spec.Template.Spec.Containers[0]
+			assert.Equal(t, modelContainerName, container.Name)
+			assert.Equal(t, tt.wantPort, container.Ports[0].ContainerPort)
+
+			service, err := kubeClient.CoreV1().Services("project").Get("model-1", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, int32(modelServicePort), service.Spec.Ports[0].Port)
+			assert.Equal(t, int(tt.wantPort), service.Spec.Ports[0].TargetPort.IntValue())
+			if len(tt.existing) > 0 {
+				assert.Equal(t, "10.0.0.1", service.Spec.ClusterIP)
+			}
+
+			hpa, err := kubeClient.AutoscalingV1().HorizontalPodAutoscalers("project").Get("model-1", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, int32(1), *hpa.Spec.MinReplicas)
+			assert.Equal(t, int32(2), hpa.Spec.MaxReplicas)
+			assert.Equal(t, tt.wantCPU, hpa.Spec.TargetCPUUtilizationPercentage)
+		})
+	}
+}
+
+func TestDeploymentController_Render(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	deployConfig := config.DeploymentConfig{
+		MinReplica:    1,
+		MaxReplica:    2,
+		CpuRequest:    resource.MustParse("1"),
+		MemoryRequest: resource.MustParse("1Gi"),
+		MaxCpu:        resource.MustParse("8"),
+		MaxMemory:     resource.MustParse("8Gi"),
+	}
+	ctl := newDeploymentController(kubeClient, deployConfig, NewContainerFetcher(kubeClient.CoreV1(), clusterMetadata))
+
+	objs, err := ctl.Render(&models.Service{
+		Name:      "model-1",
+		Namespace: "project",
+		Type:      models.ModelTypePyFunc,
+		Options:   &models.ModelOption{PyFuncImageName: "gojek/my-model:1"},
+		Secrets:   map[string]string{"db-password": "hunter2"},
+	})
+	assert.NoError(t, err)
+
+	var kinds []string
+	for _, obj := range objs {
+		kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+	}
+	assert.Equal(t, []string{"Secret", "Deployment", "Service", "HorizontalPodAutoscaler"}, kinds)
+	assert.Equal(t, "gojek/my-model:1", objs[1].(*appsv1.Deployment).Spec.Template.Spec.Containers[0].Image)
+	// nothing is applied to the cluster
+	assert.Empty(t, kubeClient.Actions())
+
+	_, err = ctl.Render(&models.Service{
+		Name:      "model-1",
+		Namespace: "project",
+		Type:      models.ModelTypePyFunc,
+		Options:   &models.ModelOption{PyFuncImageName: "gojek/my-model:1"},
+		Transformer: &models.Transformer{
+			Enabled: true,
+		},
+	})
+	assert.Equal(t, ErrComponentNotSupported, err)
+}
+
+func TestDeploymentController_Delete(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "model-1", Namespace: "project"}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "model-1", Namespace: "project"}},
+	)
+	ctl := newDeploymentController(kubeClient, config.DeploymentConfig{}, NewContainerFetcher(kubeClient.CoreV1(), clusterMetadata))
+
+	modelSvc := &models.Service{Name: "model-1", Namespace: "project"}
+	svc, err := ctl.Delete(modelSvc)
+	assert.NoError(t, err)
+	assert.Equal(t, modelSvc, svc)
+
+	_, err = kubeClient.AppsV1().Deployments("project").Get("model-1", metav1.GetOptions{})
+	assert.Error(t, err)
+	_, err = kubeClient.CoreV1().Services("project").Get("model-1", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestDeploymentController_Scale(t *testing.T) {
+	minReplicas := int32(1)
+	kubeClient := fake.NewSimpleClientset(&autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "model-1", Namespace: "project"},
+		Spec:       autoscalingv1.HorizontalPodAutoscalerSpec{MinReplicas: &minReplicas, MaxReplicas: 2},
+	})
+	ctl := newDeploymentController(kubeClient, config.DeploymentConfig{}, NewContainerFetcher(kubeClient.CoreV1(), clusterMetadata))
+
+	// deployments can't scale down to zero
+	err := ctl.Scale(&models.Service{
+		Name:            "model-1",
+		Namespace:       "project",
+		ResourceRequest: &models.ResourceRequest{MinReplica: 0, MaxReplica: 4},
+	})
+	assert.NoError(t, err)
+
+	hpa, err := kubeClient.AutoscalingV1().HorizontalPodAutoscalers("project").Get("model-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), *hpa.Spec.MinReplicas)
+	assert.Equal(t, int32(4), hpa.Spec.MaxReplicas)
+}
+
+func TestIsDeploymentReady(t *testing.T) {
+	replicas := int32(2)
+	tests := []struct {
+		name   string
+		status appsv1.DeploymentStatus
+		want   bool
+	}{
+		{
+			name:   "rolled out",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			want:   true,
+		},
+		{
+			name:   "generation not observed",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			want:   false,
+		},
+		{
+			name:   "old replicas terminating",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 3},
+			want:   false,
+		},
+		{
+			name:   "updated replicas unavailable",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     tt.status,
+			}
+			assert.Equal(t, tt.want, isDeploymentReady(deployment))
+		})
+	}
+}
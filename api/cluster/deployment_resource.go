@@ -0,0 +1,180 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
+)
+
+const (
+	// Pod label of KFServing inference services, the pods of the deployments have it as well
+	// so that their containers are listed the same way
+	labelInferenceServiceName = "serving.kubeflow.org/inferenceservice"
+
+	modelContainerName        = "model"
+	modelPortName             = "http"
+	defaultModelContainerPort = 8080
+	modelServicePort          = 80
+)
+
+// createDeploymentSpec returns the deployment running the predictor container of the model type.
+func createDeploymentSpec(modelService *models.Service, config *config.DeploymentConfig) (*appsv1.Deployment, error) {
+	handler, err := modeltype.Get(modelService.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if modelService.ResourceRequest == nil {
+		modelService.ResourceRequest = defaultResourceRequest(config)
+	}
+
+	predictor := handler.PredictorSpec(modelService, createResourceRequirements(modelService.ResourceRequest, config))
+	if predictor.Custom == nil {
+		// the other model types are served by the model servers of KFServing
+		return nil, ErrModelTypeNotSupported
+	}
+
+	container := predictor.Custom.Container
+	container.Name = modelContainerName
+	injectSecretEnvVars(&container, modelService.EnvVars, modelService)
+	if len(container.Ports) == 0 {
+		container.Ports = []v1.ContainerPort{{Name: modelPortName, ContainerPort: defaultModelContainerPort}}
+	}
+
+	labels := createDeploymentLabels(modelService)
+	replicas := int32(minDeploymentReplica(modelService))
+	scheduling := createPodScheduling(modelService, config, deploymentSelector(modelService), true)
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      modelService.Name,
+			Namespace: modelService.Namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: deploymentSelector(modelService),
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      labels,
+					Annotations: handler.Annotations(modelService),
+				},
+				Spec: v1.PodSpec{
+					Containers:        []v1.Container{container},
+					Volumes:           secretVolumes(modelService),
+					NodeSelector:      scheduling.nodeSelector,
+					Tolerations:       scheduling.tolerations,
+					Affinity:          scheduling.spreadAffinity,
+					PriorityClassName: scheduling.priorityClassName,
+				},
+			},
+		},
+	}, nil
+}
+
+// createServiceSpec returns the service exposing the first port of the deployment's container.
+func createServiceSpec(modelService *models.Service, deployment *appsv1.Deployment) *v1.Service {
+	containerPort := deployment.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort
+
+	return &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      modelService.Name,
+			Namespace: modelService.Namespace,
+			Labels:    createDeploymentLabels(modelService),
+		},
+		Spec: v1.ServiceSpec{
+			Selector: deploymentSelector(modelService),
+			Ports: []v1.ServicePort{
+				{
+					Name:       modelPortName,
+					Port:       modelServicePort,
+					TargetPort: intstr.FromInt(int(containerPort)),
+				},
+			},
+		},
+	}
+}
+
+// createHorizontalPodAutoscalerSpec returns the autoscaler of the deployment, the CPU target defaults to
+// the one of Kubernetes when there is no autoscaling policy.
+func createHorizontalPodAutoscalerSpec(modelService *models.Service) *autoscalingv1.HorizontalPodAutoscaler {
+	minReplicas := int32(minDeploymentReplica(modelService))
+	maxReplicas := int32(modelService.ResourceRequest.MaxReplica)
+	if maxReplicas < minReplicas {
+		maxReplicas = minReplicas
+	}
+
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      modelService.Name,
+			Namespace: modelService.Namespace,
+			Labels:    createDeploymentLabels(modelService),
+		},
+		Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "Deployment",
+				Name:       modelService.Name,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: maxReplicas,
+		},
+	}
+
+	if policy := modelService.AutoscalingPolicy; policy != nil {
+		targetCPUUtilization := int32(policy.TargetValue)
+		hpa.Spec.TargetCPUUtilizationPercentage = &targetCPUUtilization
+	}
+	return hpa
+}
+
+func createDeploymentLabels(modelService *models.Service) map[string]string {
+	labels := createLabels(modelService)
+	for key, value := range deploymentSelector(modelService) {
+		labels[key] = value
+	}
+	return labels
+}
+
+func deploymentSelector(modelService *models.Service) map[string]string {
+	return map[string]string{
+		labelInferenceServiceName: modelService.Name,
+	}
+}
+
+// minDeploymentReplica returns the minimum number of replica of the deployment, which can't scale down to zero.
+func minDeploymentReplica(modelService *models.Service) int {
+	if modelService.ResourceRequest.MinReplica < 1 {
+		return 1
+	}
+	return modelService.ResourceRequest.MinReplica
+}
+
+// serviceHostname returns the cluster-local hostname of the service of the deployment.
+func serviceHostname(modelService *models.Service) string {
+	return fmt.Sprintf("%s.%s.%s", modelService.Name, modelService.Namespace, models.ClusterLocalDomain)
+}
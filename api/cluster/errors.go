@@ -19,11 +19,34 @@ import "errors"
 var (
 	ErrInsufficientCpu                   = errors.New("CPU request is too large")
 	ErrInsufficientMem                   = errors.New("memory request too large")
+	ErrCpuLimitBelowRequest              = errors.New("CPU limit is lower than CPU request")
+	ErrMemLimitBelowRequest              = errors.New("memory limit is lower than memory request")
+	ErrCpuLimitRatioTooLarge             = errors.New("CPU limit to request ratio is too large")
+	ErrMemLimitRatioTooLarge             = errors.New("memory limit to request ratio is too large")
+	ErrGPUNotAllowed                     = errors.New("GPU type is not available in the environment")
+	ErrInvalidGPUCount                   = errors.New("GPU count is out of the allowed range")
+	ErrGPUSchedulingNotSupported         = errors.New("GPU type requires tolerations or node selectors that can't be set on KFServing v1alpha2 inference services")
+	ErrNodePoolNotAllowed                = errors.New("node pool is not available in the environment")
+	ErrNodePoolNotSupported              = errors.New("node pools are not supported by KFServing v1alpha2 inference services")
+	ErrSecretMountNotSupported           = errors.New("secrets can't be mounted as files to KFServing v1alpha2 inference services")
+	ErrUnableToApplySecret               = errors.New("error applying secret")
+	ErrInvalidAutoscalingPolicy          = errors.New("invalid autoscaling policy")
+	ErrAutoscalingMetricNotAllowed       = errors.New("autoscaling metric is not allowed in the environment")
+	ErrScaleToZeroNotAllowed             = errors.New("scale to zero is not allowed in the environment")
 	ErrTimeoutNamespace                  = errors.New("timeout creating namespace")
 	ErrUnableToCreateNamespace           = errors.New("error creating namespace")
 	ErrUnableToGetNamespaceStatus        = errors.New("error retrieving namespace status")
 	ErrUnableToGetInferenceServiceStatus = errors.New("error retrieving inference service status")
 	ErrUnableToCreateInferenceService    = errors.New("error creating inference service")
 	ErrUnableToUpdateInferenceService    = errors.New("error updating inference service")
+	ErrUnableToScaleInferenceService     = errors.New("error scaling inference service")
 	ErrTimeoutCreateInferenceService     = errors.New("timeout creating inference service")
+	ErrModelTypeNotSupported             = errors.New("model type is not supported by the deployment backend")
+	ErrComponentNotSupported             = errors.New("transformer and explainer are not supported by the deployment backend")
+	ErrAutoscalingMetricNotSupported     = errors.New("autoscaling metric is not supported by the deployment backend")
+	ErrScaleToZeroNotSupported           = errors.New("scale to zero is not supported by the deployment backend")
+	ErrUnableToGetDeploymentStatus       = errors.New("error retrieving deployment status")
+	ErrUnableToApplyDeployment           = errors.New("error applying deployment")
+	ErrUnableToScaleDeployment           = errors.New("error scaling deployment")
+	ErrTimeoutCreateDeployment           = errors.New("timeout creating deployment")
 )
@@ -0,0 +1,135 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/client/clientset/versioned"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+
+	"github.com/gojek/merlin/config"
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	kfservicev1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/clientset/versioned/typed/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+)
+
+// inferenceService is the view of an inference service shared by the KFServing API versions.
+type inferenceService interface {
+	metav1.Object
+	// modelURL returns the URL of the model served by the inference service without the predict or explain suffix,
+	// i.e. http://<host>/v1/models/<name>
+	modelURL() string
+	// predictorHostname returns the hostname of the predictor component
+	predictorHostname() string
+	hasExplainer() bool
+	// notReadyCondition returns the first condition of the inference service that isn't true, or nil if it's ready
+	notReadyCondition() *apis.Condition
+}
+
+// inferenceServiceAPI creates and updates the inference services of a version of the KFServing API
+// from the same models.Service.
+type inferenceServiceAPI interface {
+	get(namespace, name string) (inferenceService, error)
+	create(modelService *models.Service, config *config.DeploymentConfig) (inferenceService, error)
+	update(orig inferenceService, modelService *models.Service, config *config.DeploymentConfig) (inferenceService, error)
+	delete(namespace, name string, options *metav1.DeleteOptions) error
+	// scale patches the min and max replicas of the predictor of the inference service
+	scale(namespace, name string, minReplicas, maxReplicas int) error
+	// render returns the inference service that create would submit, along with its kind
+	render(modelService *models.Service, config *config.DeploymentConfig) runtime.Object
+}
+
+func newInferenceServiceAPI(apiVersion string, cfg *rest.Config) (inferenceServiceAPI, error) {
+	switch apiVersion {
+	case "", config.KFServingV1alpha2:
+		servingClient, err := versioned.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &v1alpha2API{servingClient: servingClient.ServingV1alpha2()}, nil
+	case config.KFServingV1beta1:
+		servingClient, err := kfservicev1beta1.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &v1beta1API{servingClient: servingClient}, nil
+	default:
+		return nil, fmt.Errorf("unsupported KFServing API version: %s", apiVersion)
+	}
+}
+
+// newInferenceServiceInformer returns an informer of the inference services created by Merlin.
+func newInferenceServiceInformer(apiVersion string, cfg *rest.Config, resyncPeriod time.Duration) (cache.SharedIndexInformer, error) {
+	switch apiVersion {
+	case "", config.KFServingV1alpha2:
+		servingClient, err := versioned.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newV1alpha2Informer(servingClient, resyncPeriod), nil
+	case config.KFServingV1beta1:
+		servingClient, err := kfservicev1beta1.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newV1beta1Informer(servingClient, resyncPeriod), nil
+	default:
+		return nil, fmt.Errorf("unsupported KFServing API version: %s", apiVersion)
+	}
+}
+
+// asInferenceService returns the inference service of an informer's object.
+func asInferenceService(obj interface{}) (inferenceService, bool) {
+	switch s := obj.(type) {
+	case *kfsv1alpha2.InferenceService:
+		return v1alpha2InferenceService{s}, true
+	case *servingv1beta1.InferenceService:
+		return v1beta1InferenceService{s}, true
+	default:
+		return nil, false
+	}
+}
+
+type conditionGetter interface {
+	GetCondition(conditionType apis.ConditionType) *apis.Condition
+}
+
+// firstNotReadyCondition returns the first of the conditions that isn't true, a missing condition is reported with
+// an unknown status.
+func firstNotReadyCondition(status conditionGetter, conditionTypes []apis.ConditionType) *apis.Condition {
+	for _, conditionType := range conditionTypes {
+		condition := status.GetCondition(conditionType)
+		if condition == nil {
+			return &apis.Condition{Type: conditionType, Status: v1.ConditionUnknown}
+		}
+		if !condition.IsTrue() {
+			return condition
+		}
+	}
+	return nil
+}
+
+// isInferenceServiceReady returns true if the inference service and all of its components are ready.
+func isInferenceServiceReady(inferenceService inferenceService) bool {
+	return inferenceService.notReadyCondition() == nil
+}
@@ -0,0 +1,313 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/storage"
+)
+
+const (
+	orchestratorName      = "merlin"
+	reconcilerMaxRetries  = 3
+	notReadyMessagePrefix = "inference service is not ready"
+	deletedMessageSuffix  = "was deleted outside of merlin"
+)
+
+var driftCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:      "inference_service_drift_count",
+		Namespace: "merlin_api",
+		Help:      "Number of inference services found deleted or not ready outside of deployment",
+	},
+	[]string{"environment", "reason"},
+)
+
+func init() {
+	prometheus.MustRegister(driftCounter)
+}
+
+// EndpointRedeployer tracks and re-applies deployments of version endpoints.
+type EndpointRedeployer interface {
+	// IsDeploying returns true if the endpoint has an unfinished deployment
+	IsDeploying(endpoint *models.VersionEndpoint) (bool, error)
+	// RedeployEndpoint re-applies the last deployed configuration of the endpoint
+	RedeployEndpoint(endpoint *models.VersionEndpoint) error
+}
+
+// LeaderElector tells whether this API replica is elected to reconcile the inference services.
+type LeaderElector interface {
+	// IsLeader returns true if this replica currently holds the leader lease
+	IsLeader() bool
+}
+
+// InferenceServiceReconciler keeps the status of version endpoints in sync with their inference services.
+//
+// Each environment has one reconciler which watches the inference services created by Merlin in its cluster.
+// Every change of an inference service, as well as a periodic resync, is added to the queue and processed by processNextItem().
+// When the inference service of a running or serving endpoint is deleted or is not ready, while there is no deployment
+// in progress, the drift is recorded as a version endpoint event and the endpoint status and message are updated.
+// Optionally, the last deployed configuration of the endpoint is re-applied, at most maxReapplies times in a row
+// until the inference service is ready again.
+// Every replica watches the inference services, but only the leader reconciles them.
+type InferenceServiceReconciler interface {
+	Run(stopCh <-chan struct{})
+}
+
+type inferenceServiceReconciler struct {
+	environmentName string
+	reapply         bool
+	maxReapplies    int
+	leader          LeaderElector
+	store           storage.VersionEndpointStorage
+	eventStore      storage.VersionEndpointEventStorage
+	redeployer      EndpointRedeployer
+	informer        cache.SharedIndexInformer
+	queue           workqueue.RateLimitingInterface
+}
+
+func NewInferenceServiceReconciler(clusterConfig ClusterConfig, kfservingAPIVersion string, environmentName string, reapply bool, reconcilerConfig config.ReconcilerConfig, leader LeaderElector, store storage.VersionEndpointStorage, eventStore storage.VersionEndpointEventStorage, redeployer EndpointRedeployer) (InferenceServiceReconciler, error) {
+	informer, err := newInferenceServiceInformer(kfservingAPIVersion, clusterConfig.restConfig(), reconcilerConfig.ResyncPeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInferenceServiceReconciler(informer, environmentName, reapply, reconcilerConfig.MaxReapplies, leader, store, eventStore, redeployer), nil
+}
+
+func newInferenceServiceReconciler(informer cache.SharedIndexInformer, environmentName string, reapply bool, maxReapplies int, leader LeaderElector, store storage.VersionEndpointStorage, eventStore storage.VersionEndpointEventStorage, redeployer EndpointRedeployer) *inferenceServiceReconciler {
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+	reconciler := &inferenceServiceReconciler{
+		environmentName: environmentName,
+		reapply:         reapply,
+		maxReapplies:    maxReapplies,
+		leader:          leader,
+		store:           store,
+		eventStore:      eventStore,
+		redeployer:      redeployer,
+		informer:        informer,
+		queue:           queue,
+	}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: reconciler.enqueue,
+		// resync is delivered as update as well, so every inference service is checked periodically
+		UpdateFunc: func(_, new interface{}) {
+			reconciler.enqueue(new)
+		},
+		DeleteFunc: reconciler.enqueue,
+	})
+	return reconciler
+}
+
+func (r *inferenceServiceReconciler) Run(stopCh <-chan struct{}) {
+	defer r.queue.ShutDown()
+
+	go r.informer.Run(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh, r.informer.HasSynced) {
+		log.Errorf("timed out while waiting for inference service cache of environment %s to sync", r.environmentName)
+		return
+	}
+
+	wait.Until(r.runWorker, time.Second, stopCh)
+}
+
+func (r *inferenceServiceReconciler) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Warnf("unable to get key of inference service: %v", err)
+		return
+	}
+	r.queue.Add(key)
+}
+
+func (r *inferenceServiceReconciler) runWorker() {
+	for r.processNextItem() {
+		// continue looping
+	}
+}
+
+func (r *inferenceServiceReconciler) processNextItem() bool {
+	// Get will block until next event is received
+	key, quit := r.queue.Get()
+	if quit {
+		return false
+	}
+	defer r.queue.Done(key)
+
+	err := r.reconcile(key.(string))
+	if err == nil {
+		r.queue.Forget(key)
+	} else if r.queue.NumRequeues(key) < reconcilerMaxRetries {
+		log.Warnf("error reconciling inference service %s, retry attempt %d: %v", key, r.queue.NumRequeues(key), err)
+		r.queue.AddRateLimited(key)
+	} else {
+		log.Warnf("error reconciling inference service %s, discarding event: %v", key, err)
+		r.queue.Forget(key)
+	}
+
+	return true
+}
+
+func (r *inferenceServiceReconciler) reconcile(key string) error {
+	if !r.leader.IsLeader() {
+		// the inference service is checked again on the next resync, in case this replica becomes the leader
+		return nil
+	}
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	obj, exists, err := r.informer.GetIndexer().GetByKey(key)
+	if err != nil {
+		return fmt.Errorf("error fetching object with key %s from store: %v", key, err)
+	}
+
+	endpoint, err := r.store.GetByInferenceService(r.environmentName, namespace, name)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			// not deployed as a version endpoint of this environment
+			return nil
+		}
+		return errors.Wrapf(err, "unable to find version endpoint of inference service %s", key)
+	}
+
+	// pending endpoints are being deployed while failed and terminated endpoints are not expected to be running
+	if endpoint.Status != models.EndpointRunning && endpoint.Status != models.EndpointServing {
+		return nil
+	}
+
+	deploying, err := r.redeployer.IsDeploying(endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "unable to check deployment of version endpoint %s", endpoint.Id)
+	}
+	if deploying {
+		return nil
+	}
+
+	if !exists {
+		return r.handleDrift(endpoint, models.EventReasonInferenceServiceDeleted,
+			fmt.Sprintf("inference service %s %s", name, deletedMessageSuffix))
+	}
+
+	inferenceService, ok := asInferenceService(obj)
+	if !ok {
+		return fmt.Errorf("unexpected object with key %s: %T", key, obj)
+	}
+
+	if !isInferenceServiceReady(inferenceService) {
+		return r.handleDrift(endpoint, models.EventReasonInferenceServiceNotReady,
+			fmt.Sprintf("%s: %s", notReadyMessagePrefix, readyConditionMessage(inferenceService)))
+	}
+
+	if strings.HasPrefix(endpoint.Message, notReadyMessagePrefix) || strings.HasSuffix(endpoint.Message, deletedMessageSuffix) {
+		endpoint.Message = ""
+		r.recordEvent(endpoint, models.EventTypeNormal, models.EventReasonInferenceServiceReady, "inference service is ready")
+		return r.store.Save(endpoint)
+	}
+	return nil
+}
+
+// handleDrift records the drift and updates the endpoint, or re-applies the deployed configuration if enabled.
+func (r *inferenceServiceReconciler) handleDrift(endpoint *models.VersionEndpoint, reason, message string) error {
+	if endpoint.Message == message {
+		// the drift has been handled
+		return nil
+	}
+
+	log.Warnf("version endpoint %s in environment %s drifted: %s", endpoint.Id, r.environmentName, message)
+	driftCounter.WithLabelValues(r.environmentName, reason).Inc()
+	r.recordEvent(endpoint, models.EventTypeWarning, reason, message)
+
+	endpoint.Message = message
+	if r.reapply {
+		reapplies, err := r.countReapplies(endpoint)
+		if err != nil {
+			return err
+		}
+
+		if reapplies >= r.maxReapplies {
+			// re-applying doesn't fix the inference service, so it's left to the user to avoid redeploying it forever
+			r.recordEvent(endpoint, models.EventTypeWarning, models.EventReasonReapplyFailed,
+				fmt.Sprintf("inference service is still not ready after re-applying %d times", reapplies))
+		} else if err := r.redeployer.RedeployEndpoint(endpoint); err != nil {
+			r.recordEvent(endpoint, models.EventTypeWarning, models.EventReasonReapplyFailed, err.Error())
+		} else {
+			r.recordEvent(endpoint, models.EventTypeNormal, models.EventReasonReapplied, "re-applying the deployed configuration")
+			return r.store.Save(endpoint)
+		}
+	}
+
+	if reason == models.EventReasonInferenceServiceDeleted {
+		endpoint.Status = models.EndpointFailed
+	}
+	return r.store.Save(endpoint)
+}
+
+// countReapplies returns the number of times the endpoint has been re-applied since its inference service was last ready.
+func (r *inferenceServiceReconciler) countReapplies(endpoint *models.VersionEndpoint) (int, error) {
+	events, err := r.eventStore.ListEvents(endpoint.Id)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to list events of version endpoint %s", endpoint.Id)
+	}
+
+	reapplies := 0
+	for _, event := range events {
+		if event.Reason == models.EventReasonInferenceServiceReady {
+			break
+		}
+		if event.Reason == models.EventReasonReapplied {
+			reapplies++
+		}
+	}
+	return reapplies, nil
+}
+
+func (r *inferenceServiceReconciler) recordEvent(endpoint *models.VersionEndpoint, eventType models.EventType, reason, message string) {
+	if err := r.eventStore.Save(models.NewVersionEndpointEvent(endpoint, eventType, reason, message)); err != nil {
+		log.Warnf("unable to record event %s of version endpoint %s: %v", reason, endpoint.Id, err)
+	}
+}
+
+func readyConditionMessage(inferenceService inferenceService) string {
+	condition := inferenceService.notReadyCondition()
+	if condition == nil {
+		return "unknown"
+	}
+	if condition.Message != "" {
+		return condition.Message
+	}
+	if condition.Reason != "" {
+		return condition.Reason
+	}
+	return string(condition.Status)
+}
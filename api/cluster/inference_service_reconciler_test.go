@@ -0,0 +1,312 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+// +build unit
+
+package cluster
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
+
+	"github.com/gojek/merlin/cluster/mocks"
+	"github.com/gojek/merlin/models"
+	storageMocks "github.com/gojek/merlin/storage/mocks"
+)
+
+func createTestInferenceService(ready bool) *kfsv1alpha2.InferenceService {
+	status := corev1.ConditionTrue
+	message := ""
+	if !ready {
+		status = corev1.ConditionFalse
+		message = "Revision failed"
+	}
+
+	return &kfsv1alpha2.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "model-1",
+			Namespace: "project",
+			Labels: map[string]string{
+				labelOrchestratorName: orchestratorName,
+			},
+		},
+		Status: kfsv1alpha2.InferenceServiceStatus{
+			Status: duckv1beta1.Status{
+				Conditions: duckv1beta1.Conditions{
+					{Type: kfsv1alpha2.DefaultPredictorReady, Status: status, Message: message},
+					{Type: kfsv1alpha2.RoutesReady, Status: status, Message: message},
+					{Type: apis.ConditionReady, Status: status, Message: message},
+				},
+			},
+		},
+	}
+}
+
+func createTestInferenceServiceWithTransformer(transformerReady bool) *kfsv1alpha2.InferenceService {
+	inferenceService := createTestInferenceService(true)
+	inferenceService.Spec.Default.Transformer = &kfsv1alpha2.TransformerSpec{}
+
+	status := corev1.ConditionTrue
+	message := ""
+	if !transformerReady {
+		status = corev1.ConditionFalse
+		message = "Transformer failed"
+	}
+	inferenceService.Status.Conditions = append(inferenceService.Status.Conditions,
+		apis.Condition{Type: kfsv1alpha2.DefaultTransformerReady, Status: status, Message: message})
+	return inferenceService
+}
+
+func createTestInferenceServiceWithExplainer() *kfsv1alpha2.InferenceService {
+	inferenceService := createTestInferenceService(true)
+	inferenceService.Spec.Default.Explainer = &kfsv1alpha2.ExplainerSpec{}
+	return inferenceService
+}
+
+func TestInferenceServiceReconciler_reconcile(t *testing.T) {
+	tests := []struct {
+		name             string
+		inferenceService *kfsv1alpha2.InferenceService
+		endpointStatus   models.EndpointStatus
+		endpointMessage  string
+		notFound         bool
+		notLeader        bool
+		deploying        bool
+		reapply          bool
+		pastEvents       []*models.VersionEndpointEvent
+		redeployErr      error
+		wantStatus       models.EndpointStatus
+		wantMessage      string
+		wantEvents       []string
+		wantRedeploy     bool
+	}{
+		{
+			name:             "ready inference service",
+			inferenceService: createTestInferenceService(true),
+			endpointStatus:   models.EndpointRunning,
+			wantStatus:       models.EndpointRunning,
+		},
+		{
+			name:           "inference service not deployed by merlin",
+			notFound:       true,
+			endpointStatus: models.EndpointRunning,
+		},
+		{
+			name:           "deleted inference service",
+			endpointStatus: models.EndpointServing,
+			wantStatus:     models.EndpointFailed,
+			wantMessage:    "inference service model-1 was deleted outside of merlin",
+			wantEvents:     []string{models.EventReasonInferenceServiceDeleted},
+		},
+		{
+			name:           "deleted inference service is re-applied",
+			endpointStatus: models.EndpointServing,
+			reapply:        true,
+			wantStatus:     models.EndpointServing,
+			wantMessage:    "inference service model-1 was deleted outside of merlin",
+			wantEvents:     []string{models.EventReasonInferenceServiceDeleted, models.EventReasonReapplied},
+			wantRedeploy:   true,
+		},
+		{
+			name:           "deleted inference service is re-applied again after being ready",
+			endpointStatus: models.EndpointServing,
+			reapply:        true,
+			pastEvents: []*models.VersionEndpointEvent{
+				{Reason: models.EventReasonInferenceServiceReady},
+				{Reason: models.EventReasonReapplied},
+				{Reason: models.EventReasonReapplied},
+				{Reason: models.EventReasonReapplied},
+			},
+			wantStatus:   models.EndpointServing,
+			wantMessage:  "inference service model-1 was deleted outside of merlin",
+			wantEvents:   []string{models.EventReasonInferenceServiceDeleted, models.EventReasonReapplied},
+			wantRedeploy: true,
+		},
+		{
+			name:             "not ready inference service is not re-applied after too many attempts",
+			inferenceService: createTestInferenceService(false),
+			endpointStatus:   models.EndpointServing,
+			reapply:          true,
+			pastEvents: []*models.VersionEndpointEvent{
+				{Reason: models.EventReasonReapplied},
+				{Reason: models.EventReasonInferenceServiceNotReady},
+				{Reason: models.EventReasonReapplied},
+				{Reason: models.EventReasonInferenceServiceNotReady},
+				{Reason: models.EventReasonReapplied},
+				{Reason: models.EventReasonInferenceServiceReady},
+			},
+			wantStatus:  models.EndpointServing,
+			wantMessage: "inference service is not ready: Revision failed",
+			wantEvents:  []string{models.EventReasonInferenceServiceNotReady, models.EventReasonReapplyFailed},
+		},
+		{
+			name:           "failed to re-apply deleted inference service",
+			endpointStatus: models.EndpointServing,
+			reapply:        true,
+			redeployErr:    errors.New("no deployment recorded"),
+			wantStatus:     models.EndpointFailed,
+			wantMessage:    "inference service model-1 was deleted outside of merlin",
+			wantEvents:     []string{models.EventReasonInferenceServiceDeleted, models.EventReasonReapplyFailed},
+			wantRedeploy:   true,
+		},
+		{
+			name:           "deleted inference service is reconciled by the leader only",
+			endpointStatus: models.EndpointServing,
+			notLeader:      true,
+			reapply:        true,
+			wantStatus:     models.EndpointServing,
+		},
+		{
+			name:           "deleted inference service of terminated endpoint",
+			endpointStatus: models.EndpointTerminated,
+			wantStatus:     models.EndpointTerminated,
+		},
+		{
+			name:           "deleted inference service while deploying",
+			endpointStatus: models.EndpointRunning,
+			deploying:      true,
+			wantStatus:     models.EndpointRunning,
+		},
+		{
+			name:             "not ready inference service",
+			inferenceService: createTestInferenceService(false),
+			endpointStatus:   models.EndpointRunning,
+			wantStatus:       models.EndpointRunning,
+			wantMessage:      "inference service is not ready: Revision failed",
+			wantEvents:       []string{models.EventReasonInferenceServiceNotReady},
+		},
+		{
+			name:             "ready inference service with ready transformer",
+			inferenceService: createTestInferenceServiceWithTransformer(true),
+			endpointStatus:   models.EndpointRunning,
+			wantStatus:       models.EndpointRunning,
+		},
+		{
+			name:             "ready inference service with not ready transformer",
+			inferenceService: createTestInferenceServiceWithTransformer(false),
+			endpointStatus:   models.EndpointRunning,
+			wantStatus:       models.EndpointRunning,
+			wantMessage:      "inference service is not ready: Transformer failed",
+			wantEvents:       []string{models.EventReasonInferenceServiceNotReady},
+		},
+		{
+			name:             "ready inference service with missing explainer condition",
+			inferenceService: createTestInferenceServiceWithExplainer(),
+			endpointStatus:   models.EndpointRunning,
+			wantStatus:       models.EndpointRunning,
+			wantMessage:      "inference service is not ready: Unknown",
+			wantEvents:       []string{models.EventReasonInferenceServiceNotReady},
+		},
+		{
+			name:             "not ready inference service has been recorded",
+			inferenceService: createTestInferenceService(false),
+			endpointStatus:   models.EndpointRunning,
+			endpointMessage:  "inference service is not ready: Revision failed",
+			wantStatus:       models.EndpointRunning,
+			wantMessage:      "inference service is not ready: Revision failed",
+		},
+		{
+			name:             "inference service is ready again",
+			inferenceService: createTestInferenceService(true),
+			endpointStatus:   models.EndpointRunning,
+			endpointMessage:  "inference service is not ready: Revision failed",
+			wantStatus:       models.EndpointRunning,
+			wantEvents:       []string{models.EventReasonInferenceServiceReady},
+		},
+		{
+			name:             "inference service is ready again after being re-applied",
+			inferenceService: createTestInferenceService(true),
+			endpointStatus:   models.EndpointServing,
+			endpointMessage:  "inference service model-1 was deleted outside of merlin",
+			wantStatus:       models.EndpointServing,
+			wantEvents:       []string{models.EventReasonInferenceServiceReady},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &models.VersionEndpoint{
+				Id:                   uuid.New(),
+				Status:               tt.endpointStatus,
+				Message:              tt.endpointMessage,
+				Namespace:            "project",
+				InferenceServiceName: "model-1",
+				EnvironmentName:      "env",
+			}
+
+			store := &storageMocks.VersionEndpointStorage{}
+			if tt.notFound {
+				store.On("GetByInferenceService", "env", "project", "model-1").Return(nil, gorm.ErrRecordNotFound)
+			} else {
+				store.On("GetByInferenceService", "env", "project", "model-1").Return(endpoint, nil)
+			}
+			store.On("Save", endpoint).Return(nil)
+
+			eventStore := &storageMocks.VersionEndpointEventStorage{}
+			eventStore.On("Save", mock.Anything).Return(nil)
+			eventStore.On("ListEvents", endpoint.Id).Return(tt.pastEvents, nil)
+
+			leader := &mocks.LeaderElector{}
+			leader.On("IsLeader").Return(!tt.notLeader)
+
+			redeployer := &mocks.EndpointRedeployer{}
+			redeployer.On("IsDeploying", endpoint).Return(tt.deploying, nil)
+			redeployer.On("RedeployEndpoint", endpoint).Return(tt.redeployErr)
+
+			reconciler := newInferenceServiceReconciler(newV1alpha2Informer(fake.NewSimpleClientset(), time.Minute), "env", tt.reapply, 3, leader, store, eventStore, redeployer)
+			if tt.inferenceService != nil {
+				assert.NoError(t, reconciler.informer.GetIndexer().Add(tt.inferenceService))
+			}
+
+			err := reconciler.reconcile("project/model-1")
+			assert.NoError(t, err)
+			if tt.notFound || tt.notLeader {
+				store.AssertNotCalled(t, "Save", mock.Anything)
+				redeployer.AssertNotCalled(t, "RedeployEndpoint", mock.Anything)
+				return
+			}
+
+			assert.Equal(t, tt.wantStatus, endpoint.Status)
+			assert.Equal(t, tt.wantMessage, endpoint.Message)
+
+			var reasons []string
+			for _, call := range eventStore.Calls {
+				if call.Method != "Save" {
+					continue
+				}
+				reasons = append(reasons, call.Arguments[0].(*models.VersionEndpointEvent).Reason)
+			}
+			assert.Equal(t, tt.wantEvents, reasons)
+
+			if tt.wantRedeploy {
+				redeployer.AssertCalled(t, "RedeployEndpoint", endpoint)
+			} else {
+				redeployer.AssertNotCalled(t, "RedeployEndpoint", mock.Anything)
+			}
+		})
+	}
+}
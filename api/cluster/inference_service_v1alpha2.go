@@ -0,0 +1,127 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/client/clientset/versioned"
+	kfservice "github.com/kubeflow/kfserving/pkg/client/clientset/versioned/typed/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/client/informers/externalversions"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/models"
+)
+
+// v1alpha2InferenceService is an inference service of the KFServing v1alpha2 API.
+type v1alpha2InferenceService struct {
+	*kfsv1alpha2.InferenceService
+}
+
+func (s v1alpha2InferenceService) modelURL() string {
+	return s.Status.URL
+}
+
+func (s v1alpha2InferenceService) predictorHostname() string {
+	if s.Status.Default == nil {
+		return ""
+	}
+	return (*s.Status.Default)[constants.Predictor].Hostname
+}
+
+func (s v1alpha2InferenceService) hasExplainer() bool {
+	return s.Spec.Default.Explainer != nil
+}
+
+// notReadyCondition checks the transformer and explainer conditions as well since KFServing doesn't take
+// them into account in the ready condition of the inference service.
+func (s v1alpha2InferenceService) notReadyCondition() *apis.Condition {
+	conditionTypes := []apis.ConditionType{apis.ConditionReady}
+	if s.Spec.Default.Transformer != nil {
+		conditionTypes = append(conditionTypes, kfsv1alpha2.DefaultTransformerReady)
+	}
+	if s.Spec.Default.Explainer != nil {
+		conditionTypes = append(conditionTypes, kfsv1alpha2.DefaultExplainerReady)
+	}
+	return firstNotReadyCondition(&s.Status.Status, conditionTypes)
+}
+
+type v1alpha2API struct {
+	servingClient kfservice.ServingV1alpha2Interface
+}
+
+func (a *v1alpha2API) get(namespace, name string) (inferenceService, error) {
+	s, err := a.servingClient.InferenceServices(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return v1alpha2InferenceService{s}, nil
+}
+
+func (a *v1alpha2API) create(modelService *models.Service, config *config.DeploymentConfig) (inferenceService, error) {
+	s, err := a.servingClient.InferenceServices(modelService.Namespace).Create(createInferenceServiceSpec(modelService, config))
+	if err != nil {
+		return nil, err
+	}
+	return v1alpha2InferenceService{s}, nil
+}
+
+func (a *v1alpha2API) update(orig inferenceService, modelService *models.Service, config *config.DeploymentConfig) (inferenceService, error) {
+	origV1alpha2, ok := orig.(v1alpha2InferenceService)
+	if !ok {
+		return nil, fmt.Errorf("unexpected inference service %s: %T", orig.GetName(), orig)
+	}
+
+	s, err := a.servingClient.InferenceServices(modelService.Namespace).Update(patchInferenceServiceSpec(origV1alpha2.InferenceService, modelService, config))
+	if err != nil {
+		return nil, err
+	}
+	return v1alpha2InferenceService{s}, nil
+}
+
+func (a *v1alpha2API) render(modelService *models.Service, config *config.DeploymentConfig) runtime.Object {
+	s := createInferenceServiceSpec(modelService, config)
+	s.SetGroupVersionKind(kfsv1alpha2.SchemeGroupVersion.WithKind("InferenceService"))
+	return s
+}
+
+func (a *v1alpha2API) delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return a.servingClient.InferenceServices(namespace).Delete(name, options)
+}
+
+func (a *v1alpha2API) scale(namespace, name string, minReplicas, maxReplicas int) error {
+	patch, err := replicasPatch(minReplicas, maxReplicas, "spec", "default", "predictor")
+	if err != nil {
+		return err
+	}
+	_, err = a.servingClient.InferenceServices(namespace).Patch(name, types.MergePatchType, patch)
+	return err
+}
+
+func newV1alpha2Informer(servingClient versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	informerFactory := externalversions.NewSharedInformerFactoryWithOptions(servingClient, resyncPeriod,
+		externalversions.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = fmt.Sprintf("%s=%s", labelOrchestratorName, orchestratorName)
+		}))
+	return informerFactory.Serving().V1alpha2().InferenceServices().Informer()
+}
@@ -0,0 +1,146 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	"knative.dev/pkg/apis"
+
+	"github.com/gojek/merlin/config"
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	kfservicev1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/clientset/versioned/typed/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+)
+
+// v1beta1InferenceService is an inference service of the KFServing v1beta1 API.
+type v1beta1InferenceService struct {
+	*servingv1beta1.InferenceService
+}
+
+// modelURL appends the model path to the URL of the inference service, which only has the host in v1beta1.
+func (s v1beta1InferenceService) modelURL() string {
+	if s.Status.URL == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/v1/models/%s", s.Status.URL.String(), s.Name)
+}
+
+func (s v1beta1InferenceService) predictorHostname() string {
+	predictor, ok := s.Status.Components[servingv1beta1.PredictorComponent]
+	if !ok || predictor.URL == nil {
+		return ""
+	}
+	return predictor.URL.Host
+}
+
+func (s v1beta1InferenceService) hasExplainer() bool {
+	return s.Spec.Explainer != nil
+}
+
+// notReadyCondition checks the transformer and explainer conditions as well since KFServing doesn't take
+// them into account in the ready condition of the inference service.
+func (s v1beta1InferenceService) notReadyCondition() *apis.Condition {
+	conditionTypes := []apis.ConditionType{apis.ConditionReady}
+	if s.Spec.Transformer != nil {
+		conditionTypes = append(conditionTypes, servingv1beta1.TransformerReady)
+	}
+	if s.Spec.Explainer != nil {
+		conditionTypes = append(conditionTypes, servingv1beta1.ExplainerReady)
+	}
+	return firstNotReadyCondition(&s.Status.Status, conditionTypes)
+}
+
+type v1beta1API struct {
+	servingClient kfservicev1beta1.ServingV1beta1Interface
+}
+
+func (a *v1beta1API) get(namespace, name string) (inferenceService, error) {
+	s, err := a.servingClient.InferenceServices(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return v1beta1InferenceService{s}, nil
+}
+
+func (a *v1beta1API) create(modelService *models.Service, config *config.DeploymentConfig) (inferenceService, error) {
+	s, err := a.servingClient.InferenceServices(modelService.Namespace).Create(createV1beta1InferenceServiceSpec(modelService, config))
+	if err != nil {
+		return nil, err
+	}
+	return v1beta1InferenceService{s}, nil
+}
+
+func (a *v1beta1API) update(orig inferenceService, modelService *models.Service, config *config.DeploymentConfig) (inferenceService, error) {
+	origV1beta1, ok := orig.(v1beta1InferenceService)
+	if !ok {
+		return nil, fmt.Errorf("unexpected inference service %s: %T", orig.GetName(), orig)
+	}
+
+	patch, err := v1beta1InferenceServicePatch(origV1beta1.InferenceService, modelService, config)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := a.servingClient.InferenceServices(modelService.Namespace).Patch(origV1beta1.Name, types.MergePatchType, patch)
+	if err != nil {
+		return nil, err
+	}
+	return v1beta1InferenceService{s}, nil
+}
+
+func (a *v1beta1API) render(modelService *models.Service, config *config.DeploymentConfig) runtime.Object {
+	s := createV1beta1InferenceServiceSpec(modelService, config)
+	s.SetGroupVersionKind(servingv1beta1.SchemeGroupVersion.WithKind("InferenceService"))
+	return s
+}
+
+func (a *v1beta1API) delete(namespace, name string, options *metav1.DeleteOptions) error {
+	return a.servingClient.InferenceServices(namespace).Delete(name, options)
+}
+
+func (a *v1beta1API) scale(namespace, name string, minReplicas, maxReplicas int) error {
+	patch, err := replicasPatch(minReplicas, maxReplicas, "spec", "predictor")
+	if err != nil {
+		return err
+	}
+	_, err = a.servingClient.InferenceServices(namespace).Patch(name, types.MergePatchType, patch)
+	return err
+}
+
+func newV1beta1Informer(servingClient kfservicev1beta1.ServingV1beta1Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	labelSelector := fmt.Sprintf("%s=%s", labelOrchestratorName, orchestratorName)
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				options.LabelSelector = labelSelector
+				return servingClient.InferenceServices(metav1.NamespaceAll).List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.LabelSelector = labelSelector
+				return servingClient.InferenceServices(metav1.NamespaceAll).Watch(options)
+			},
+		},
+		&servingv1beta1.InferenceService{},
+		resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+}
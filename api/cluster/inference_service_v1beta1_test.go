@@ -0,0 +1,292 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+// +build unit
+
+package cluster
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	ktesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+
+	"github.com/gojek/merlin/cluster/mocks"
+	"github.com/gojek/merlin/config"
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	fakeservingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/clientset/versioned/typed/serving/v1beta1/fake"
+	"github.com/gojek/merlin/models"
+	storageMocks "github.com/gojek/merlin/storage/mocks"
+)
+
+func createV1beta1ReadyStatus(name, namespace string, conditionTypes ...apis.ConditionType) servingv1beta1.InferenceServiceStatus {
+	url, _ := apis.ParseURL("http://" + name + "." + namespace + ".example.com")
+	predictorURL, _ := apis.ParseURL("http://" + name + "-predictor-default." + namespace + ".example.com")
+
+	conditions := duckv1.Conditions{{Type: apis.ConditionReady, Status: v1.ConditionTrue}}
+	for _, conditionType := range conditionTypes {
+		conditions = append(conditions, apis.Condition{Type: conditionType, Status: v1.ConditionTrue})
+	}
+
+	return servingv1beta1.InferenceServiceStatus{
+		Status: duckv1.Status{Conditions: conditions},
+		URL:    url,
+		Components: map[servingv1beta1.ComponentType]servingv1beta1.ComponentStatusSpec{
+			servingv1beta1.PredictorComponent: {URL: predictorURL},
+		},
+	}
+}
+
+func TestController_DeployInferenceService_V1beta1(t *testing.T) {
+	namespace := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "project"},
+		Status:     v1.NamespaceStatus{Phase: v1.NamespaceActive},
+	}
+
+	tests := []struct {
+		name     string
+		existing *servingv1beta1.InferenceService
+		modelSvc *models.Service
+		want     *models.Service
+	}{
+		{
+			name: "create",
+			modelSvc: &models.Service{
+				Name:        "model-1",
+				Namespace:   "project",
+				ArtifactUri: "gs://my-artifact",
+				Type:        models.ModelTypeSkLearn,
+				Options:     &models.ModelOption{},
+			},
+			want: &models.Service{
+				Name:        "model-1",
+				Namespace:   "project",
+				ServiceName: "model-1-predictor-default.project.example.com",
+				Url:         "http://model-1.project.example.com/v1/models/model-1",
+			},
+		},
+		{
+			name: "update with explainer",
+			existing: &servingv1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "model-1",
+					Namespace: "project",
+					Annotations: map[string]string{
+						"serving.kubeflow.org/gke-accelerator": "nvidia-tesla-t4",
+					},
+				},
+				Spec: servingv1beta1.InferenceServiceSpec{
+					Explainer: &servingv1beta1.ExplainerSpec{},
+				},
+				Status: createV1beta1ReadyStatus("model-1", "project", servingv1beta1.ExplainerReady),
+			},
+			modelSvc: &models.Service{
+				Name:        "model-1",
+				Namespace:   "project",
+				ArtifactUri: "gs://my-artifact",
+				Type:        models.ModelTypeSkLearn,
+				Options:     &models.ModelOption{},
+				Explainer: &models.Explainer{
+					Enabled:       true,
+					ExplainerType: models.AnchorTabularExplainerType,
+				},
+			},
+			want: &models.Service{
+				Name:         "model-1",
+				Namespace:    "project",
+				ServiceName:  "model-1-predictor-default.project.example.com",
+				Url:          "http://model-1.project.example.com/v1/models/model-1",
+				ExplainerUrl: "http://model-1.project.example.com/v1/models/model-1:explain",
+			},
+		},
+		{
+			name: "custom model",
+			modelSvc: &models.Service{
+				Name:      "model-1",
+				Namespace: "project",
+				Type:      models.ModelTypeCustom,
+				Options: &models.ModelOption{
+					CustomPredictor: &models.CustomPredictor{
+						Image:       "gojek/my-model:1",
+						PredictPath: "/v2/models/model/infer",
+					},
+				},
+			},
+			want: &models.Service{
+				Name:        "model-1",
+				Namespace:   "project",
+				ServiceName: "model-1-predictor-default.project.example.com",
+				Url:         "http://model-1.project.example.com/v2/models/model/infer",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var objects []runtime.Object
+			if tt.existing != nil {
+				objects = append(objects, tt.existing)
+			}
+			kfClient := fakeservingv1beta1.NewSimpleServingV1beta1(objects...)
+			// the created inference service becomes ready
+			kfClient.PrependReactor(createMethod, inferenceServiceResource, func(action ktesting.Action) (handled bool, ret runtime.Object, err error) {
+				isvc := action.(ktesting.CreateAction).GetObject().(*servingv1beta1.InferenceService)
+				isvc.Status = createV1beta1ReadyStatus(isvc.Name, isvc.Namespace)
+				return true, isvc, kfClient.Tracker().Create(action.GetResource(), isvc, action.GetNamespace())
+			})
+			// the fake client only supports strategic merge patches, which don't remove the annotations
+			kfClient.PrependReactor(patchMethod, inferenceServiceResource, func(action ktesting.Action) (handled bool, ret runtime.Object, err error) {
+				patchAction := action.(ktesting.PatchAction)
+				orig, err := kfClient.Tracker().Get(action.GetResource(), action.GetNamespace(), patchAction.GetName())
+				if err != nil {
+					return true, nil, err
+				}
+				origJSON, _ := json.Marshal(orig)
+				patchedJSON, err := jsonpatch.MergePatch(origJSON, patchAction.GetPatch())
+				if err != nil {
+					return true, nil, err
+				}
+				isvc := &servingv1beta1.InferenceService{}
+				if err := json.Unmarshal(patchedJSON, isvc); err != nil {
+					return true, nil, err
+				}
+				return true, isvc, kfClient.Tracker().Update(action.GetResource(), isvc, action.GetNamespace())
+			})
+
+			v1Client := fake.NewSimpleClientset(namespace).CoreV1()
+			deployConfig := config.DeploymentConfig{
+				DeploymentTimeout: 2 * tickDurationSecond * time.Second,
+				MinReplica:        1,
+				MaxReplica:        2,
+				CpuRequest:        resource.MustParse("1"),
+				MemoryRequest:     resource.MustParse("1Gi"),
+				MaxCpu:            resource.MustParse("8"),
+				MaxMemory:         resource.MustParse("8Gi"),
+			}
+
+			ctl, _ := newController(&v1beta1API{servingClient: kfClient}, v1Client, deployConfig, NewContainerFetcher(v1Client, clusterMetadata))
+			svc, err := ctl.Deploy(tt.modelSvc)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, svc)
+
+			deployed, err := kfClient.InferenceServices("project").Get("model-1", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.NotContains(t, deployed.Annotations, "serving.kubeflow.org/gke-accelerator")
+			assert.Equal(t, "merlin", deployed.Labels[labelOrchestratorName])
+		})
+	}
+}
+
+func TestV1beta1API_Scale(t *testing.T) {
+	minReplicas := 1
+	isvc := &servingv1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "model-1", Namespace: "project"},
+		Spec: servingv1beta1.InferenceServiceSpec{
+			Predictor: servingv1beta1.PredictorSpec{
+				ComponentExtensionSpec: servingv1beta1.ComponentExtensionSpec{MinReplicas: &minReplicas, MaxReplicas: 2},
+			},
+		},
+	}
+	kfClient := fakeservingv1beta1.NewSimpleServingV1beta1(isvc)
+
+	api := &v1beta1API{servingClient: kfClient}
+	assert.NoError(t, api.scale("project", "model-1", 0, 4))
+
+	scaled, err := kfClient.InferenceServices("project").Get("model-1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, *scaled.Spec.Predictor.MinReplicas)
+	assert.Equal(t, 4, scaled.Spec.Predictor.MaxReplicas)
+}
+
+func TestV1beta1InferenceService_NotReadyCondition(t *testing.T) {
+	isvc := &servingv1beta1.InferenceService{
+		Spec: servingv1beta1.InferenceServiceSpec{
+			Transformer: &servingv1beta1.TransformerSpec{},
+		},
+		Status: createV1beta1ReadyStatus("model-1", "project"),
+	}
+	assert.Equal(t, &apis.Condition{Type: servingv1beta1.TransformerReady, Status: v1.ConditionUnknown},
+		v1beta1InferenceService{isvc}.notReadyCondition())
+
+	isvc.Status = createV1beta1ReadyStatus("model-1", "project", servingv1beta1.TransformerReady)
+	assert.True(t, isInferenceServiceReady(v1beta1InferenceService{isvc}))
+}
+
+func TestInferenceServiceReconciler_ReconcileV1beta1(t *testing.T) {
+	endpoint := &models.VersionEndpoint{
+		Status:               models.EndpointRunning,
+		Namespace:            "project",
+		InferenceServiceName: "model-1",
+		EnvironmentName:      "env",
+	}
+
+	store := &storageMocks.VersionEndpointStorage{}
+	store.On("GetByInferenceService", "env", "project", "model-1").Return(endpoint, nil)
+	store.On("Save", endpoint).Return(nil)
+
+	eventStore := &storageMocks.VersionEndpointEventStorage{}
+	eventStore.On("Save", mock.Anything).Return(nil)
+
+	redeployer := &mocks.EndpointRedeployer{}
+	redeployer.On("IsDeploying", endpoint).Return(false, nil)
+
+	isvc := &servingv1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "model-1", Namespace: "project"},
+		Status: servingv1beta1.InferenceServiceStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					{Type: apis.ConditionReady, Status: v1.ConditionFalse, Message: "Revision failed"},
+				},
+			},
+		},
+	}
+
+	informer := newV1beta1Informer(fakeservingv1beta1.NewSimpleServingV1beta1(), time.Minute)
+	leader := &mocks.LeaderElector{}
+	leader.On("IsLeader").Return(true)
+	reconciler := newInferenceServiceReconciler(informer, "env", false, 3, leader, store, eventStore, redeployer)
+	assert.NoError(t, reconciler.informer.GetIndexer().Add(isvc))
+
+	assert.NoError(t, reconciler.reconcile("project/model-1"))
+	assert.Equal(t, models.EndpointRunning, endpoint.Status)
+	assert.Equal(t, "inference service is not ready: Revision failed", endpoint.Message)
+}
+
+func TestNewInferenceServiceAPI(t *testing.T) {
+	cfg := &rest.Config{Host: "http://localhost"}
+
+	api, err := newInferenceServiceAPI("", cfg)
+	assert.NoError(t, err)
+	assert.IsType(t, &v1alpha2API{}, api)
+
+	api, err = newInferenceServiceAPI(config.KFServingV1beta1, cfg)
+	assert.NoError(t, err)
+	assert.IsType(t, &v1beta1API{}, api)
+
+	_, err = newInferenceServiceAPI("v1", cfg)
+	assert.EqualError(t, err, "unsupported KFServing API version: v1")
+}
@@ -4,6 +4,7 @@ package mocks
 
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/gojek/merlin/models"
+import runtime "k8s.io/apimachinery/pkg/runtime"
 
 // Controller is an autogenerated mock type for the Controller type
 type Controller struct {
@@ -78,3 +79,54 @@ func (_m *Controller) GetContainers(namespace string, labelSelector string) ([]*
 
 	return r0, r1
 }
+
+// Render provides a mock function with given fields: modelService
+func (_m *Controller) Render(modelService *models.Service) ([]runtime.Object, error) {
+	ret := _m.Called(modelService)
+
+	var r0 []runtime.Object
+	if rf, ok := ret.Get(0).(func(*models.Service) []runtime.Object); ok {
+		r0 = rf(modelService)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]runtime.Object)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*models.Service) error); ok {
+		r1 = rf(modelService)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Scale provides a mock function with given fields: modelService
+func (_m *Controller) Scale(modelService *models.Service) error {
+	ret := _m.Called(modelService)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Service) error); ok {
+		r0 = rf(modelService)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Validate provides a mock function with given fields: modelService
+func (_m *Controller) Validate(modelService *models.Service) error {
+	ret := _m.Called(modelService)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.Service) error); ok {
+		r0 = rf(modelService)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
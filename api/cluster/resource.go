@@ -18,12 +18,16 @@ import (
 	"fmt"
 
 	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/constants"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/gojek/merlin/config"
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
 	"github.com/gojek/merlin/models"
-	"github.com/gojek/merlin/utils"
+	"github.com/gojek/merlin/modeltype"
+	_ "github.com/gojek/merlin/modeltype/builtin"
 )
 
 const (
@@ -31,9 +35,10 @@ const (
 	envModelDir  = "MODEL_DIR"
 	envWorkers   = "WORKERS"
 
-	annotationQueueProxyResource   = "queue.sidecar.serving.knative.dev/resourcePercentage"
-	annotationPrometheusScrapeFlag = "prometheus.io/scrape"
-	annotationPrometheusScrapePort = "prometheus.io/port"
+	annotationQueueProxyResource = "queue.sidecar.serving.knative.dev/resourcePercentage"
+
+	// Node label of the GPU type of GKE node pools, KFServing's pod webhook turns the annotation into a node selector
+	gkeAcceleratorNodeSelector = "cloud.google.com/gke-accelerator"
 
 	labelTeamName         = "gojek.com/team"
 	labelStreamName       = "gojek.com/stream"
@@ -42,31 +47,30 @@ const (
 	labelEnvironment      = "gojek.com/environment"
 	labelUsersHeading     = "gojek.com/user-labels/%s"
 
-	prometheusPort = "8080"
-)
+	// Ratio of the limit to the request of the inference service when no limit is specified
+	defaultLimitRatio = 2
 
-func createInferenceServiceSpec(modelService *models.Service, config *config.DeploymentConfig) *kfsv1alpha2.InferenceService {
-	labels := createLabels(modelService)
+	// Directory of the model version's artifact containing the explanation model of Alibi explainers
+	explainerArtifactPath = "explainer"
+)
 
-	objectMeta := metav1.ObjectMeta{
-		Name:      modelService.Name,
-		Namespace: modelService.Namespace,
-		Annotations: map[string]string{
-			annotationQueueProxyResource: config.QueueResourcePercentage,
-		},
-		Labels: labels,
-	}
+var alibiExplainerTypes = map[models.ExplainerType]kfsv1alpha2.AlibiExplainerType{
+	models.AnchorTabularExplainerType: kfsv1alpha2.AlibiAnchorsTabularExplainer,
+	models.AnchorImageExplainerType:   kfsv1alpha2.AlibiAnchorsImageExplainer,
+	models.AnchorTextExplainerType:    kfsv1alpha2.AlibiAnchorsTextExplainer,
+}
 
-	if modelService.Type == models.ModelTypePyFunc {
-		objectMeta.Annotations[annotationPrometheusScrapeFlag] = "true"
-		objectMeta.Annotations[annotationPrometheusScrapePort] = prometheusPort
-	}
+func createInferenceServiceSpec(modelService *models.Service, config *config.DeploymentConfig) *kfsv1alpha2.InferenceService {
+	objectMeta := createObjectMeta(modelService, config)
+	objectMeta.Annotations = setGKEAcceleratorAnnotation(objectMeta.Annotations, modelService.ResourceRequest, config)
 
 	return &kfsv1alpha2.InferenceService{
 		ObjectMeta: objectMeta,
 		Spec: kfsv1alpha2.InferenceServiceSpec{
 			Default: kfsv1alpha2.EndpointSpec{
-				Predictor: createPredictorSpec(modelService, config),
+				Predictor:   createPredictorSpec(modelService, config),
+				Transformer: createTransformerSpec(modelService, config),
+				Explainer:   createExplainerSpec(modelService, config),
 			},
 		},
 	}
@@ -75,32 +79,151 @@ func createInferenceServiceSpec(modelService *models.Service, config *config.Dep
 func patchInferenceServiceSpec(orig *kfsv1alpha2.InferenceService, modelService *models.Service, config *config.DeploymentConfig) *kfsv1alpha2.InferenceService {
 	labels := createLabels(modelService)
 	orig.ObjectMeta.Labels = labels
+	orig.ObjectMeta.Annotations = setAutoscalingAnnotations(orig.ObjectMeta.Annotations, modelService.AutoscalingPolicy)
+	orig.ObjectMeta.Annotations = setGKEAcceleratorAnnotation(orig.ObjectMeta.Annotations, modelService.ResourceRequest, config)
 	orig.Spec.Default.Predictor = createPredictorSpec(modelService, config)
+	orig.Spec.Default.Transformer = createTransformerSpec(modelService, config)
+	orig.Spec.Default.Explainer = createExplainerSpec(modelService, config)
 	return orig
 }
 
+// createObjectMeta returns the metadata of the inference service shared by the KFServing API versions.
+func createObjectMeta(modelService *models.Service, config *config.DeploymentConfig) metav1.ObjectMeta {
+	objectMeta := metav1.ObjectMeta{
+		Name:      modelService.Name,
+		Namespace: modelService.Namespace,
+		Annotations: map[string]string{
+			annotationQueueProxyResource: config.QueueResourcePercentage,
+		},
+		Labels: createLabels(modelService),
+	}
+
+	if handler, err := modeltype.Get(modelService.Type); err == nil {
+		for key, value := range handler.Annotations(modelService) {
+			objectMeta.Annotations[key] = value
+		}
+	}
+
+	objectMeta.Annotations = setAutoscalingAnnotations(objectMeta.Annotations, modelService.AutoscalingPolicy)
+	return objectMeta
+}
+
 func createPredictorSpec(modelService *models.Service, config *config.DeploymentConfig) kfsv1alpha2.PredictorSpec {
 	var predictorSpec kfsv1alpha2.PredictorSpec
 
 	if modelService.ResourceRequest == nil {
-		modelService.ResourceRequest = &models.ResourceRequest{
-			MinReplica:    config.MinReplica,
-			MaxReplica:    config.MaxReplica,
-			CpuRequest:    config.CpuRequest,
-			MemoryRequest: config.MemoryRequest,
+		modelService.ResourceRequest = defaultResourceRequest(config)
+	}
+
+	resources := createResourceRequirements(modelService.ResourceRequest, config)
+
+	if handler, err := modeltype.Get(modelService.Type); err == nil {
+		predictorSpec = handler.PredictorSpec(modelService, resources)
+	}
+	if predictorSpec.Custom != nil {
+		injectSecretEnvVars(&predictorSpec.Custom.Container, modelService.EnvVars, modelService)
+	}
+
+	predictorSpec.DeploymentSpec = kfsv1alpha2.DeploymentSpec{
+		MinReplicas: minReplica(modelService),
+		MaxReplicas: modelService.ResourceRequest.MaxReplica,
+	}
+
+	return predictorSpec
+}
+
+func createTransformerSpec(modelService *models.Service, config *config.DeploymentConfig) *kfsv1alpha2.TransformerSpec {
+	transformer := modelService.Transformer
+	if transformer == nil || !transformer.Enabled {
+		return nil
+	}
+
+	resourceRequest := transformer.ResourceRequest
+	if resourceRequest == nil {
+		resourceRequest = defaultResourceRequest(config)
+	}
+
+	image := transformer.Image
+	if transformer.TransformerType == models.PyFuncTransformerType {
+		image = modelService.Options.TransformerImageName
+	}
+
+	container := v1.Container{
+		Image:     image,
+		Command:   transformer.Command,
+		Args:      transformer.Args,
+		Env:       transformer.EnvVars.ToKubernetesEnvVars(),
+		Resources: createResourceRequirements(resourceRequest, config),
+	}
+	injectSecretEnvVars(&container, transformer.EnvVars, modelService)
+
+	return &kfsv1alpha2.TransformerSpec{
+		Custom: &kfsv1alpha2.CustomSpec{
+			Container: container,
+		},
+		DeploymentSpec: kfsv1alpha2.DeploymentSpec{
+			MinReplicas: resourceRequest.MinReplica,
+			MaxReplicas: resourceRequest.MaxReplica,
+		},
+	}
+}
+
+func createExplainerSpec(modelService *models.Service, config *config.DeploymentConfig) *kfsv1alpha2.ExplainerSpec {
+	explainer := modelService.Explainer
+	if explainer == nil || !explainer.Enabled {
+		return nil
+	}
+
+	resourceRequest := explainer.ResourceRequest
+	if resourceRequest == nil {
+		resourceRequest = defaultResourceRequest(config)
+	}
+
+	explainerSpec := &kfsv1alpha2.ExplainerSpec{
+		DeploymentSpec: kfsv1alpha2.DeploymentSpec{
+			MinReplicas: resourceRequest.MinReplica,
+			MaxReplicas: resourceRequest.MaxReplica,
+		},
+	}
+
+	resources := createResourceRequirements(resourceRequest, config)
+	if explainer.ExplainerType == models.CustomExplainerType {
+		container := v1.Container{
+			Image:     explainer.Image,
+			Env:       explainer.EnvVars.ToKubernetesEnvVars(),
+			Resources: resources,
 		}
+		injectSecretEnvVars(&container, explainer.EnvVars, modelService)
+		explainerSpec.Custom = &kfsv1alpha2.CustomSpec{Container: container}
+		return explainerSpec
+	}
+
+	explainerSpec.Alibi = &kfsv1alpha2.AlibiExplainerSpec{
+		Type:       alibiExplainerTypes[explainer.ExplainerType],
+		StorageURI: fmt.Sprintf("%s/%s", modelService.ArtifactUri, explainerArtifactPath),
+		Resources:  resources,
+		Config:     explainer.Config,
 	}
+	return explainerSpec
+}
+
+func defaultResourceRequest(config *config.DeploymentConfig) *models.ResourceRequest {
+	return &models.ResourceRequest{
+		MinReplica:    config.MinReplica,
+		MaxReplica:    config.MaxReplica,
+		CpuRequest:    config.CpuRequest,
+		MemoryRequest: config.MemoryRequest,
+	}
+}
 
-	// Set cpu limit and memory limit to be 2x of the requests
-	cpuLimit := modelService.ResourceRequest.CpuRequest.DeepCopy()
-	cpuLimit.Add(modelService.ResourceRequest.CpuRequest)
-	memoryLimit := modelService.ResourceRequest.MemoryRequest.DeepCopy()
-	memoryLimit.Add(modelService.ResourceRequest.MemoryRequest)
+func createResourceRequirements(resourceRequest *models.ResourceRequest, config *config.DeploymentConfig) v1.ResourceRequirements {
+	cpuLimit := resourceLimit(resourceRequest.CpuLimit, config.CpuLimit, resourceRequest.CpuRequest, config.MaxCpuLimitRatio)
+	memoryLimit := resourceLimit(resourceRequest.MemoryLimit, config.MemoryLimit, resourceRequest.MemoryRequest, config.MaxMemoryLimitRatio)
 
-	Resources := v1.ResourceRequirements{
+	resources := v1.ResourceRequirements{
 		Requests: v1.ResourceList{
-			v1.ResourceCPU:    modelService.ResourceRequest.CpuRequest,
-			v1.ResourceMemory: modelService.ResourceRequest.MemoryRequest,
+			v1.ResourceCPU:    resourceRequest.CpuRequest,
+			v1.ResourceMemory: resourceRequest.MemoryRequest,
 		},
 		Limits: v1.ResourceList{
 			v1.ResourceCPU:    cpuLimit,
@@ -108,61 +231,141 @@ func createPredictorSpec(modelService *models.Service, config *config.Deployment
 		},
 	}
 
-	switch modelService.Type {
-	case models.ModelTypeTensorflow:
-		predictorSpec = kfsv1alpha2.PredictorSpec{
-			Tensorflow: &kfsv1alpha2.TensorflowSpec{
-				StorageURI: utils.CreateModelLocation(modelService.ArtifactUri),
-				Resources:  Resources,
-			},
-		}
-	case models.ModelTypeOnnx:
-		predictorSpec = kfsv1alpha2.PredictorSpec{
-			ONNX: &kfsv1alpha2.ONNXSpec{
-				StorageURI: utils.CreateModelLocation(modelService.ArtifactUri),
-				Resources:  Resources,
-			},
-		}
-	case models.ModelTypeSkLearn:
-		predictorSpec = kfsv1alpha2.PredictorSpec{
-			SKLearn: &kfsv1alpha2.SKLearnSpec{
-				StorageURI: utils.CreateModelLocation(modelService.ArtifactUri),
-				Resources:  Resources,
-			},
-		}
-	case models.ModelTypeXgboost:
-		predictorSpec = kfsv1alpha2.PredictorSpec{
-			XGBoost: &kfsv1alpha2.XGBoostSpec{
-				StorageURI: utils.CreateModelLocation(modelService.ArtifactUri),
-				Resources:  Resources,
-			},
+	if gpuRequest := resourceRequest.GPURequest; gpuRequest != nil {
+		if gpu, ok := config.GPU(gpuRequest.Name); ok {
+			resources.Limits[gpu.ResourceName()] = *resource.NewQuantity(gpuRequest.Count, resource.DecimalSI)
 		}
-	case models.ModelTypePyTorch:
-		predictorSpec = kfsv1alpha2.PredictorSpec{
-			PyTorch: &kfsv1alpha2.PyTorchSpec{
-				StorageURI:     utils.CreateModelLocation(modelService.ArtifactUri),
-				ModelClassName: modelService.Options.PyTorchModelClassName,
-				Resources:      Resources,
-			},
-		}
-	case models.ModelTypePyFunc:
-		predictorSpec = kfsv1alpha2.PredictorSpec{
-			Custom: &kfsv1alpha2.CustomSpec{
-				Container: v1.Container{
-					Image:     modelService.Options.PyFuncImageName,
-					Env:       modelService.EnvVars.ToKubernetesEnvVars(),
-					Resources: Resources,
-				},
-			},
+	}
+	return resources
+}
+
+// setGKEAcceleratorAnnotation selects the GKE node pool of the requested GPU type.
+func setGKEAcceleratorAnnotation(annotations map[string]string, resourceRequest *models.ResourceRequest, config *config.DeploymentConfig) map[string]string {
+	delete(annotations, constants.InferenceServiceGKEAcceleratorAnnotationKey)
+
+	if resourceRequest == nil || resourceRequest.GPURequest == nil {
+		return annotations
+	}
+
+	gpu, ok := config.GPU(resourceRequest.GPURequest.Name)
+	if !ok {
+		return annotations
+	}
+
+	accelerator, ok := gpu.NodeSelector[gkeAcceleratorNodeSelector]
+	if !ok {
+		return annotations
+	}
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[constants.InferenceServiceGKEAcceleratorAnnotationKey] = accelerator
+	return annotations
+}
+
+// gpuScheduling returns the node selector and tolerations of the node pool of the requested GPU type.
+func gpuScheduling(resourceRequest *models.ResourceRequest, config *config.DeploymentConfig) (map[string]string, []v1.Toleration) {
+	if resourceRequest == nil || resourceRequest.GPURequest == nil {
+		return nil, nil
+	}
+
+	gpu, ok := config.GPU(resourceRequest.GPURequest.Name)
+	if !ok {
+		return nil, nil
+	}
+	return gpu.NodeSelector, gpu.Tolerations
+}
+
+// podScheduling is the scheduling constraints of the pods of a component of the inference service.
+type podScheduling struct {
+	nodeSelector map[string]string
+	tolerations  []v1.Toleration
+	// affinity of the node pool, the pods are spread across its topology domains with the topology spread
+	// constraints, or with the anti-affinity of spreadAffinity when they can't be set
+	affinity                  *v1.Affinity
+	spreadAffinity            *v1.Affinity
+	topologySpreadConstraints []servingv1beta1.TopologySpreadConstraint
+	priorityClassName         string
+}
+
+// createPodScheduling returns the scheduling constraints of the node pool of the model service, or of the default
+// node pool of the environment. The node selector and tolerations of the node pool of the requested GPU type are
+// added for the predictor.
+func createPodScheduling(modelService *models.Service, config *config.DeploymentConfig, podLabels map[string]string, withGPU bool) podScheduling {
+	nodePool, _ := config.Scheduling.NodePool(modelService.NodePool, config.Scheduling.DefaultOnlineNodePool)
+	scheduling := podScheduling{
+		nodeSelector:              map[string]string{},
+		tolerations:               append([]v1.Toleration{}, nodePool.Tolerations...),
+		affinity:                  nodePool.Affinity.DeepCopy(),
+		spreadAffinity:            nodePool.PodAffinity(podLabels),
+		topologySpreadConstraints: topologySpreadConstraints(nodePool.SpreadTopologyKeys, podLabels),
+		priorityClassName:         nodePool.PriorityClassName,
+	}
+	for key, value := range nodePool.NodeSelector {
+		scheduling.nodeSelector[key] = value
+	}
+
+	if withGPU {
+		gpuNodeSelector, gpuTolerations := gpuScheduling(modelService.ResourceRequest, config)
+		for key, value := range gpuNodeSelector {
+			scheduling.nodeSelector[key] = value
 		}
+		scheduling.tolerations = append(scheduling.tolerations, gpuTolerations...)
 	}
 
-	predictorSpec.DeploymentSpec = kfsv1alpha2.DeploymentSpec{
-		MinReplicas: modelService.ResourceRequest.MinReplica,
-		MaxReplicas: modelService.ResourceRequest.MaxReplica,
+	if len(scheduling.nodeSelector) == 0 {
+		scheduling.nodeSelector = nil
+	}
+	if len(scheduling.tolerations) == 0 {
+		scheduling.tolerations = nil
 	}
+	return scheduling
+}
 
-	return predictorSpec
+// topologySpreadConstraints returns the constraints spreading the pods having the given labels across the domains
+// of the topology keys, the pods are still scheduled when the constraints can't be satisfied.
+func topologySpreadConstraints(topologyKeys []string, podLabels map[string]string) []servingv1beta1.TopologySpreadConstraint {
+	var constraints []servingv1beta1.TopologySpreadConstraint
+	for _, topologyKey := range topologyKeys {
+		constraints = append(constraints, servingv1beta1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       topologyKey,
+			WhenUnsatisfiable: servingv1beta1.ScheduleAnyway,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: podLabels},
+		})
+	}
+	return constraints
+}
+
+// supportsPodSpec returns false for the KFServing v1alpha2 inference services, whose pod spec can't be set,
+// e.g. the scheduling constraints or the volumes.
+func supportsPodSpec(deploymentConfig *config.DeploymentConfig) bool {
+	if deploymentConfig.DeploymentBackend == config.DeploymentBackendKubernetes {
+		return true
+	}
+	return deploymentConfig.KFServingAPIVersion == config.KFServingV1beta1
+}
+
+// resourceLimit returns the given limit if it is set, otherwise the default limit of the environment if it is valid
+// for the request, otherwise twice the request capped at the max limit ratio.
+func resourceLimit(limit *resource.Quantity, defaultLimit, request resource.Quantity, maxRatio float64) resource.Quantity {
+	if limit != nil && !limit.IsZero() {
+		return *limit
+	}
+
+	// the default limit only applies to the requests that it doesn't invalidate, e.g. a cpu request raised above it
+	if !defaultLimit.IsZero() && defaultLimit.Cmp(request) >= 0 && !exceedsLimitRatio(defaultLimit, request, maxRatio) {
+		return defaultLimit
+	}
+
+	if maxRatio > 0 && maxRatio < defaultLimitRatio {
+		return *resource.NewMilliQuantity(int64(float64(request.MilliValue())*maxRatio), request.Format)
+	}
+
+	twiceRequest := request.DeepCopy()
+	twiceRequest.Add(request)
+	return twiceRequest
 }
 
 func createLabels(modelService *models.Service) map[string]string {
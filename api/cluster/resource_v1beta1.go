@@ -0,0 +1,187 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/kubeflow/kfserving/pkg/constants"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/gojek/merlin/config"
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
+)
+
+// Name of the container of the components of KFServing v1beta1 inference services
+const kfservingContainerName = "kfserving-container"
+
+// The predictor is created by the handler of the model type. The v1beta1 specs of the transformer and explainer are
+// converted from the v1alpha2 component specs, so that they're deployed the same way with both KFServing API versions.
+
+func createV1beta1InferenceServiceSpec(modelService *models.Service, config *config.DeploymentConfig) *servingv1beta1.InferenceService {
+	return &servingv1beta1.InferenceService{
+		ObjectMeta: createObjectMeta(modelService, config),
+		Spec:       createV1beta1Spec(modelService, config),
+	}
+}
+
+func patchV1beta1InferenceServiceSpec(orig *servingv1beta1.InferenceService, modelService *models.Service, config *config.DeploymentConfig) *servingv1beta1.InferenceService {
+	orig.ObjectMeta.Labels = createLabels(modelService)
+	orig.ObjectMeta.Annotations = setAutoscalingAnnotations(orig.ObjectMeta.Annotations, modelService.AutoscalingPolicy)
+	// inference services deployed with v1alpha2 select the GPU node pool with the annotation
+	delete(orig.ObjectMeta.Annotations, constants.InferenceServiceGKEAcceleratorAnnotationKey)
+	orig.Spec = createV1beta1Spec(modelService, config)
+	return orig
+}
+
+// v1beta1InferenceServicePatch returns the merge patch applying the model service to the inference service. Since the
+// inference service is only decoded into the fields merlin knows about, the patch only sets and removes those fields,
+// and the ones set by the cluster or an operator, e.g. the logger or the container concurrency, are left as is.
+func v1beta1InferenceServicePatch(orig *servingv1beta1.InferenceService, modelService *models.Service, config *config.DeploymentConfig) ([]byte, error) {
+	origJSON, err := json.Marshal(orig)
+	if err != nil {
+		return nil, err
+	}
+
+	patchedJSON, err := json.Marshal(patchV1beta1InferenceServiceSpec(orig.DeepCopy(), modelService, config))
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.CreateMergePatch(origJSON, patchedJSON)
+}
+
+func createV1beta1Spec(modelService *models.Service, config *config.DeploymentConfig) servingv1beta1.InferenceServiceSpec {
+	podLabels := map[string]string{labelInferenceServiceName: modelService.Name}
+
+	predictor := createV1beta1PredictorSpec(modelService, config)
+	setPodScheduling(&predictor.PodSpec, createPodScheduling(modelService, config, podLabels, true))
+	predictor.Volumes = secretVolumes(modelService)
+
+	transformer := convertTransformerSpec(createTransformerSpec(modelService, config))
+	if transformer != nil {
+		setPodScheduling(&transformer.PodSpec, createPodScheduling(modelService, config, podLabels, false))
+		transformer.Volumes = secretVolumes(modelService)
+	}
+
+	explainer := convertExplainerSpec(createExplainerSpec(modelService, config))
+	if explainer != nil {
+		setPodScheduling(&explainer.PodSpec, createPodScheduling(modelService, config, podLabels, false))
+		explainer.Volumes = secretVolumes(modelService)
+	}
+
+	return servingv1beta1.InferenceServiceSpec{
+		Predictor:   predictor,
+		Transformer: transformer,
+		Explainer:   explainer,
+	}
+}
+
+func setPodScheduling(podSpec *servingv1beta1.PodSpec, scheduling podScheduling) {
+	podSpec.NodeSelector = scheduling.nodeSelector
+	podSpec.Tolerations = scheduling.tolerations
+	podSpec.Affinity = scheduling.affinity
+	podSpec.TopologySpreadConstraints = scheduling.topologySpreadConstraints
+	podSpec.PriorityClassName = scheduling.priorityClassName
+}
+
+// createV1beta1PredictorSpec returns the predictor of the model type, serving the model service with its replicas
+// and secrets.
+func createV1beta1PredictorSpec(modelService *models.Service, config *config.DeploymentConfig) servingv1beta1.PredictorSpec {
+	var predictor servingv1beta1.PredictorSpec
+
+	if modelService.ResourceRequest == nil {
+		modelService.ResourceRequest = defaultResourceRequest(config)
+	}
+
+	resources := createResourceRequirements(modelService.ResourceRequest, config)
+
+	if handler, err := modeltype.Get(modelService.Type); err == nil {
+		predictor = handler.V1beta1PredictorSpec(modelService, resources)
+	}
+	for i := range predictor.Containers {
+		predictor.Containers[i].Name = kfservingContainerName
+		injectSecretEnvVars(&predictor.Containers[i], modelService.EnvVars, modelService)
+	}
+
+	minReplicas := minReplica(modelService)
+	predictor.ComponentExtensionSpec = servingv1beta1.ComponentExtensionSpec{
+		MinReplicas: &minReplicas,
+		MaxReplicas: modelService.ResourceRequest.MaxReplica,
+	}
+	return predictor
+}
+
+func convertTransformerSpec(transformer *kfsv1alpha2.TransformerSpec) *servingv1beta1.TransformerSpec {
+	if transformer == nil {
+		return nil
+	}
+
+	return &servingv1beta1.TransformerSpec{
+		PodSpec:                servingv1beta1.PodSpec{Containers: customContainers(transformer.Custom)},
+		ComponentExtensionSpec: convertDeploymentSpec(transformer.DeploymentSpec),
+	}
+}
+
+func convertExplainerSpec(explainer *kfsv1alpha2.ExplainerSpec) *servingv1beta1.ExplainerSpec {
+	if explainer == nil {
+		return nil
+	}
+
+	spec := &servingv1beta1.ExplainerSpec{
+		ComponentExtensionSpec: convertDeploymentSpec(explainer.DeploymentSpec),
+	}
+	if alibi := explainer.Alibi; alibi != nil {
+		spec.Alibi = &servingv1beta1.AlibiExplainerSpec{
+			Type:           servingv1beta1.AlibiExplainerType(alibi.Type),
+			StorageURI:     alibi.StorageURI,
+			RuntimeVersion: optionalString(alibi.RuntimeVersion),
+			Config:         alibi.Config,
+			Container:      v1.Container{Resources: alibi.Resources},
+		}
+		return spec
+	}
+	spec.Containers = customContainers(explainer.Custom)
+	return spec
+}
+
+// convertDeploymentSpec always sets the min replicas since v1beta1 defaults them to 1 while v1alpha2 scales to zero.
+func convertDeploymentSpec(deploymentSpec kfsv1alpha2.DeploymentSpec) servingv1beta1.ComponentExtensionSpec {
+	minReplicas := deploymentSpec.MinReplicas
+	return servingv1beta1.ComponentExtensionSpec{
+		MinReplicas: &minReplicas,
+		MaxReplicas: deploymentSpec.MaxReplicas,
+	}
+}
+
+func customContainers(custom *kfsv1alpha2.CustomSpec) []v1.Container {
+	if custom == nil {
+		return nil
+	}
+
+	container := custom.Container
+	container.Name = kfservingContainerName
+	return []v1.Container{container}
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,626 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+// +build unit
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gojek/merlin/config"
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/mlp"
+	"github.com/gojek/merlin/models"
+)
+
+func TestCreateV1beta1InferenceServiceSpec(t *testing.T) {
+	project := mlp.Project{
+		Name: "project",
+	}
+
+	model := &models.Service{
+		Name:        "model",
+		ArtifactUri: "gs://my-artifacet",
+		Metadata: models.Metadata{
+			Team:        "dsp",
+			Stream:      "dsp",
+			App:         "model",
+			Environment: "dev",
+		},
+	}
+	versionId := 1
+
+	minReplica := 1
+	maxReplica := 10
+	zeroReplica := 0
+	cpuRequest := resource.MustParse("1")
+	memoryRequest := resource.MustParse("1Gi")
+	cpuLimit := cpuRequest.DeepCopy()
+	cpuLimit.Add(cpuRequest)
+	memoryLimit := memoryRequest.DeepCopy()
+	memoryLimit.Add(memoryRequest)
+	queueResourcePercentage := "2"
+	storageURI := fmt.Sprintf("%s/model", model.ArtifactUri)
+
+	resourceRequests := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    cpuRequest,
+			v1.ResourceMemory: memoryRequest,
+		},
+		Limits: v1.ResourceList{
+			v1.ResourceCPU:    cpuLimit,
+			v1.ResourceMemory: memoryLimit,
+		},
+	}
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      fmt.Sprintf("%s-%d", model.Name, versionId),
+		Namespace: project.Name,
+		Annotations: map[string]string{
+			"queue.sidecar.serving.knative.dev/resourcePercentage": queueResourcePercentage,
+		},
+		Labels: map[string]string{
+			"gojek.com/app":          model.Metadata.App,
+			"gojek.com/orchestrator": "merlin",
+			"gojek.com/stream":       model.Metadata.Stream,
+			"gojek.com/team":         model.Metadata.Team,
+			"gojek.com/environment":  model.Metadata.Environment,
+		},
+	}
+
+	tolerations := []v1.Toleration{
+		{
+			Key:      "nvidia.com/gpu",
+			Operator: v1.TolerationOpEqual,
+			Value:    "present",
+			Effect:   v1.TaintEffectNoSchedule,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		modelSvc *models.Service
+		exp      *servingv1beta1.InferenceService
+	}{
+		{
+			name: "tensorflow spec",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypeTensorflow,
+				Options:     &models.ModelOption{},
+				Metadata:    model.Metadata,
+			},
+			exp: &servingv1beta1.InferenceService{
+				ObjectMeta: objectMeta,
+				Spec: servingv1beta1.InferenceServiceSpec{
+					Predictor: servingv1beta1.PredictorSpec{
+						Tensorflow: &servingv1beta1.TFServingSpec{
+							PredictorExtensionSpec: servingv1beta1.PredictorExtensionSpec{
+								StorageURI: &storageURI,
+								Container:  v1.Container{Resources: resourceRequests},
+							},
+						},
+						ComponentExtensionSpec: servingv1beta1.ComponentExtensionSpec{
+							MinReplicas: &minReplica,
+							MaxReplicas: maxReplica,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "pytorch spec with gpu request",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypePyTorch,
+				Options: &models.ModelOption{
+					PyTorchModelClassName: "MyModel",
+				},
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    minReplica,
+					MaxReplica:    maxReplica,
+					CpuRequest:    cpuRequest,
+					MemoryRequest: memoryRequest,
+					GPURequest: &models.GPURequest{
+						Name:  "nvidia-tesla-t4",
+						Count: 2,
+					},
+				},
+				Metadata: model.Metadata,
+			},
+			exp: &servingv1beta1.InferenceService{
+				ObjectMeta: objectMeta,
+				Spec: servingv1beta1.InferenceServiceSpec{
+					Predictor: servingv1beta1.PredictorSpec{
+						PyTorch: &servingv1beta1.TorchServeSpec{
+							ModelClassName: "MyModel",
+							PredictorExtensionSpec: servingv1beta1.PredictorExtensionSpec{
+								StorageURI: &storageURI,
+								Container: v1.Container{
+									Resources: v1.ResourceRequirements{
+										Requests: v1.ResourceList{
+											v1.ResourceCPU:    cpuRequest,
+											v1.ResourceMemory: memoryRequest,
+										},
+										Limits: v1.ResourceList{
+											v1.ResourceCPU:    cpuLimit,
+											v1.ResourceMemory: memoryLimit,
+											"nvidia.com/gpu":  *resource.NewQuantity(2, resource.DecimalSI),
+										},
+									},
+								},
+							},
+						},
+						PodSpec: servingv1beta1.PodSpec{
+							NodeSelector: map[string]string{
+								"cloud.google.com/gke-accelerator": "nvidia-tesla-t4",
+							},
+							Tolerations: tolerations,
+						},
+						ComponentExtensionSpec: servingv1beta1.ComponentExtensionSpec{
+							MinReplicas: &minReplica,
+							MaxReplicas: maxReplica,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "custom spec scaling to zero",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypeCustom,
+				Options: &models.ModelOption{
+					CustomPredictor: &models.CustomPredictor{
+						Image: "gojek/my-model:1",
+						Ports: []models.ContainerPort{{Name: "http1", Port: 8000}},
+					},
+				},
+				AutoscalingPolicy: &models.AutoscalingPolicy{
+					MetricType:  models.AutoscalingMetricConcurrency,
+					TargetValue: 1,
+					ScaleToZero: true,
+				},
+				Metadata: model.Metadata,
+			},
+			exp: &servingv1beta1.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", model.Name, versionId),
+					Namespace: project.Name,
+					Annotations: map[string]string{
+						"queue.sidecar.serving.knative.dev/resourcePercentage": queueResourcePercentage,
+						"prometheus.io/scrape":                                 "true",
+						"prometheus.io/port":                                   "8000",
+						"autoscaling.knative.dev/class":                        "kpa.autoscaling.knative.dev",
+						"autoscaling.knative.dev/metric":                       "concurrency",
+						"autoscaling.knative.dev/target":                       "1",
+						"autoscaling.knative.dev/minScale":                     "0",
+					},
+					Labels: objectMeta.Labels,
+				},
+				Spec: servingv1beta1.InferenceServiceSpec{
+					Predictor: servingv1beta1.PredictorSpec{
+						PodSpec: servingv1beta1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name:      "kfserving-container",
+									Image:     "gojek/my-model:1",
+									Ports:     []v1.ContainerPort{{Name: "http1", ContainerPort: 8000}},
+									Env:       []v1.EnvVar{},
+									Resources: resourceRequests,
+								},
+							},
+						},
+						ComponentExtensionSpec: servingv1beta1.ComponentExtensionSpec{
+							MinReplicas: &zeroReplica,
+							MaxReplicas: maxReplica,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "tensorflow spec with transformer and explainer",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypeTensorflow,
+				Options:     &models.ModelOption{},
+				Metadata:    model.Metadata,
+				Transformer: &models.Transformer{
+					Enabled:         true,
+					TransformerType: models.CustomTransformerType,
+					Image:           "gojek/transformer:1",
+				},
+				Explainer: &models.Explainer{
+					Enabled:       true,
+					ExplainerType: models.AnchorTabularExplainerType,
+					Config:        map[string]string{"threshold": "0.95"},
+				},
+			},
+			exp: &servingv1beta1.InferenceService{
+				ObjectMeta: objectMeta,
+				Spec: servingv1beta1.InferenceServiceSpec{
+					Predictor: servingv1beta1.PredictorSpec{
+						Tensorflow: &servingv1beta1.TFServingSpec{
+							PredictorExtensionSpec: servingv1beta1.PredictorExtensionSpec{
+								StorageURI: &storageURI,
+								Container:  v1.Container{Resources: resourceRequests},
+							},
+						},
+						ComponentExtensionSpec: servingv1beta1.ComponentExtensionSpec{
+							MinReplicas: &minReplica,
+							MaxReplicas: maxReplica,
+						},
+					},
+					Transformer: &servingv1beta1.TransformerSpec{
+						PodSpec: servingv1beta1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name:      "kfserving-container",
+									Image:     "gojek/transformer:1",
+									Env:       []v1.EnvVar{},
+									Resources: resourceRequests,
+								},
+							},
+						},
+						ComponentExtensionSpec: servingv1beta1.ComponentExtensionSpec{
+							MinReplicas: &minReplica,
+							MaxReplicas: maxReplica,
+						},
+					},
+					Explainer: &servingv1beta1.ExplainerSpec{
+						Alibi: &servingv1beta1.AlibiExplainerSpec{
+							Type:       servingv1beta1.AlibiAnchorsTabularExplainer,
+							StorageURI: fmt.Sprintf("%s/explainer", model.ArtifactUri),
+							Config:     map[string]string{"threshold": "0.95"},
+							Container:  v1.Container{Resources: resourceRequests},
+						},
+						ComponentExtensionSpec: servingv1beta1.ComponentExtensionSpec{
+							MinReplicas: &minReplica,
+							MaxReplicas: maxReplica,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployConfig := &config.DeploymentConfig{
+				MinReplica:              minReplica,
+				MaxReplica:              maxReplica,
+				CpuRequest:              cpuRequest,
+				CpuLimit:                cpuLimit,
+				MemoryRequest:           memoryRequest,
+				MemoryLimit:             memoryLimit,
+				QueueResourcePercentage: queueResourcePercentage,
+				GPUs: []config.GPUConfig{
+					{
+						Name:     "nvidia-tesla-t4",
+						MaxCount: 4,
+						NodeSelector: map[string]string{
+							"cloud.google.com/gke-accelerator": "nvidia-tesla-t4",
+						},
+						Tolerations: tolerations,
+					},
+				},
+			}
+
+			infSvcSpec := createV1beta1InferenceServiceSpec(tt.modelSvc, deployConfig)
+			assert.Equal(t, tt.exp, infSvcSpec)
+		})
+	}
+}
+
+func TestPatchV1beta1InferenceServiceSpec(t *testing.T) {
+	cpuRequest := resource.MustParse("1")
+	memoryRequest := resource.MustParse("1Gi")
+	deployConfig := &config.DeploymentConfig{
+		MinReplica:    1,
+		MaxReplica:    2,
+		CpuRequest:    cpuRequest,
+		CpuLimit:      cpuRequest,
+		MemoryRequest: memoryRequest,
+		MemoryLimit:   memoryRequest,
+	}
+
+	modelSvc := &models.Service{
+		Name:        "model-1",
+		Namespace:   "project",
+		ArtifactUri: "gs://my-artifact",
+		Type:        models.ModelTypeSkLearn,
+		Options:     &models.ModelOption{},
+		Metadata:    models.Metadata{Team: "dsp", Stream: "dsp", App: "model", Environment: "dev"},
+	}
+
+	original := &servingv1beta1.InferenceService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "model-1",
+			Namespace: "project",
+			Annotations: map[string]string{
+				"queue.sidecar.serving.knative.dev/resourcePercentage": "2",
+				"serving.kubeflow.org/gke-accelerator":                 "nvidia-tesla-t4",
+			},
+			ResourceVersion: "1",
+		},
+		Spec: servingv1beta1.InferenceServiceSpec{
+			Predictor: servingv1beta1.PredictorSpec{
+				Tensorflow: &servingv1beta1.TFServingSpec{},
+			},
+			Transformer: &servingv1beta1.TransformerSpec{},
+		},
+	}
+
+	patched := patchV1beta1InferenceServiceSpec(original, modelSvc, deployConfig)
+	assert.Equal(t, "1", patched.ResourceVersion)
+	assert.Equal(t, map[string]string{
+		"queue.sidecar.serving.knative.dev/resourcePercentage": "2",
+	}, patched.Annotations)
+	assert.Equal(t, "merlin", patched.Labels["gojek.com/orchestrator"])
+	assert.Nil(t, patched.Spec.Predictor.Tensorflow)
+	assert.Nil(t, patched.Spec.Transformer)
+	assert.Equal(t, "gs://my-artifact/model", *patched.Spec.Predictor.SKLearn.StorageURI)
+}
+
+func TestV1beta1InferenceServicePatch(t *testing.T) {
+	deployConfig := &config.DeploymentConfig{
+		MinReplica:    1,
+		MaxReplica:    2,
+		CpuRequest:    resource.MustParse("1"),
+		MemoryRequest: resource.MustParse("1Gi"),
+	}
+
+	modelSvc := &models.Service{
+		Name:        "model-1",
+		Namespace:   "project",
+		ArtifactUri: "gs://my-artifact",
+		Type:        models.ModelTypeSkLearn,
+		Options:     &models.ModelOption{},
+		Metadata:    models.Metadata{Team: "dsp", Stream: "dsp", App: "model", Environment: "dev"},
+	}
+
+	// the fields unknown to merlin are set by the cluster or an operator
+	origJSON := []byte(`{
+		"apiVersion": "serving.kubeflow.org/v1beta1",
+		"kind": "InferenceService",
+		"metadata": {
+			"name": "model-1",
+			"namespace": "project",
+			"annotations": {"serving.kubeflow.org/gke-accelerator": "nvidia-tesla-t4"}
+		},
+		"spec": {
+			"predictor": {
+				"tensorflow": {"storageUri": "gs://my-old-artifact"},
+				"containerConcurrency": 4,
+				"timeout": 60,
+				"serviceAccountName": "model-sa",
+				"logger": {"mode": "all"}
+			},
+			"transformer": {"containers": [{"name": "kfserving-container", "image": "transformer:1"}]}
+		}
+	}`)
+
+	var orig servingv1beta1.InferenceService
+	assert.NoError(t, json.Unmarshal(origJSON, &orig))
+
+	patch, err := v1beta1InferenceServicePatch(&orig, modelSvc, deployConfig)
+	assert.NoError(t, err)
+
+	patchedJSON, err := jsonpatch.MergePatch(origJSON, patch)
+	assert.NoError(t, err)
+
+	var patched map[string]interface{}
+	assert.NoError(t, json.Unmarshal(patchedJSON, &patched))
+	metadata := patched["metadata"].(map[string]interface{})
+	assert.NotContains(t, metadata, "annotations")
+	assert.Equal(t, "merlin", metadata["labels"].(map[string]interface{})["gojek.com/orchestrator"])
+
+	spec := patched["spec"].(map[string]interface{})
+	assert.NotContains(t, spec, "transformer")
+	predictor := spec["predictor"].(map[string]interface{})
+	assert.NotContains(t, predictor, "tensorflow")
+	assert.Equal(t, "gs://my-artifact/model", predictor["sklearn"].(map[string]interface{})["storageUri"])
+	assert.Equal(t, float64(2), predictor["maxReplicas"])
+	assert.Equal(t, float64(4), predictor["containerConcurrency"])
+	assert.Equal(t, float64(60), predictor["timeout"])
+	assert.Equal(t, "model-sa", predictor["serviceAccountName"])
+	assert.Equal(t, map[string]interface{}{"mode": "all"}, predictor["logger"])
+}
+
+func TestCreateV1beta1Spec_NodePool(t *testing.T) {
+	poolToleration := v1.Toleration{Key: "highmem", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}
+	gpuToleration := v1.Toleration{Key: "nvidia.com/gpu", Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule}
+	deployConfig := &config.DeploymentConfig{
+		KFServingAPIVersion: config.KFServingV1beta1,
+		MinReplica:          1,
+		MaxReplica:          2,
+		CpuRequest:          resource.MustParse("1"),
+		MemoryRequest:       resource.MustParse("1Gi"),
+		GPUs: []config.GPUConfig{
+			{
+				Name:         "nvidia-tesla-t4",
+				NodeSelector: map[string]string{"cloud.google.com/gke-accelerator": "nvidia-tesla-t4"},
+				Tolerations:  []v1.Toleration{gpuToleration},
+			},
+		},
+		Scheduling: config.SchedulingConfig{
+			NodePools: []config.NodePoolConfig{
+				{
+					Name:               "highmem",
+					NodeSelector:       map[string]string{"pool": "highmem"},
+					Tolerations:        []v1.Toleration{poolToleration},
+					PriorityClassName:  "online-high",
+					SpreadTopologyKeys: []string{"topology.kubernetes.io/zone"},
+				},
+			},
+			DefaultOnlineNodePool: "highmem",
+		},
+	}
+
+	modelSvc := &models.Service{
+		Name:        "model-1",
+		Namespace:   "project",
+		ArtifactUri: "gs://my-artifact",
+		Type:        models.ModelTypeSkLearn,
+		Options:     &models.ModelOption{},
+		ResourceRequest: &models.ResourceRequest{
+			MinReplica:    1,
+			MaxReplica:    2,
+			CpuRequest:    resource.MustParse("1"),
+			MemoryRequest: resource.MustParse("1Gi"),
+			GPURequest:    &models.GPURequest{Name: "nvidia-tesla-t4", Count: 1},
+		},
+		Transformer: &models.Transformer{
+			Enabled:         true,
+			TransformerType: models.CustomTransformerType,
+			Image:           "gojek/transformer:1",
+		},
+	}
+
+	wantConstraints := []servingv1beta1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: servingv1beta1.ScheduleAnyway,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{labelInferenceServiceName: "model-1"}},
+		},
+	}
+
+	spec := createV1beta1Spec(modelSvc, deployConfig)
+
+	predictor := spec.Predictor.PodSpec
+	assert.Equal(t, map[string]string{"pool": "highmem", "cloud.google.com/gke-accelerator": "nvidia-tesla-t4"}, predictor.NodeSelector)
+	assert.Equal(t, []v1.Toleration{poolToleration, gpuToleration}, predictor.Tolerations)
+	assert.Nil(t, predictor.Affinity)
+	assert.Equal(t, wantConstraints, predictor.TopologySpreadConstraints)
+	assert.Equal(t, "online-high", predictor.PriorityClassName)
+
+	// the transformer doesn't request the GPU
+	transformer := spec.Transformer.PodSpec
+	assert.Equal(t, map[string]string{"pool": "highmem"}, transformer.NodeSelector)
+	assert.Equal(t, []v1.Toleration{poolToleration}, transformer.Tolerations)
+	assert.Nil(t, transformer.Affinity)
+	assert.Equal(t, wantConstraints, transformer.TopologySpreadConstraints)
+	assert.Equal(t, "online-high", transformer.PriorityClassName)
+}
+
+func TestCreateV1beta1Spec_ExplainerSecrets(t *testing.T) {
+	deployConfig := &config.DeploymentConfig{
+		KFServingAPIVersion: config.KFServingV1beta1,
+		MinReplica:          1,
+		MaxReplica:          2,
+		CpuRequest:          resource.MustParse("1"),
+		MemoryRequest:       resource.MustParse("1Gi"),
+	}
+
+	modelSvc := &models.Service{
+		Name:        "model-1",
+		Namespace:   "project",
+		ArtifactUri: "gs://my-artifact",
+		Type:        models.ModelTypeSkLearn,
+		Options:     &models.ModelOption{},
+		Explainer: &models.Explainer{
+			Enabled:       true,
+			ExplainerType: models.CustomExplainerType,
+			Image:         "gojek/my-explainer:1",
+			EnvVars: models.EnvVars{
+				{Name: "API_KEY", SecretName: "api-key"},
+				{Name: "CREDENTIALS", SecretName: "credentials", MountPath: "/mnt/credentials"},
+			},
+		},
+	}
+
+	explainer := createV1beta1Spec(modelSvc, deployConfig).Explainer
+	container := explainer.Containers[0]
+	assert.Equal(t, []v1.EnvVar{
+		{
+			Name: "API_KEY",
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: "model-1-secrets"},
+					Key:                  "api-key",
+				},
+			},
+		},
+		{Name: "CREDENTIALS", Value: "/mnt/credentials"},
+	}, container.Env)
+	assert.Equal(t, []v1.VolumeMount{{Name: secretVolumeName, MountPath: "/mnt/credentials", SubPath: "credentials", ReadOnly: true}}, container.VolumeMounts)
+	assert.Equal(t, secretVolumes(modelSvc), explainer.Volumes)
+	assert.NotEmpty(t, explainer.Volumes)
+}
+
+func TestValidateNodePool(t *testing.T) {
+	scheduling := config.SchedulingConfig{
+		NodePools: []config.NodePoolConfig{{Name: "highmem"}},
+	}
+
+	tests := []struct {
+		name     string
+		nodePool string
+		config   *config.DeploymentConfig
+		want     error
+	}{
+		{
+			name:   "no node pool",
+			config: &config.DeploymentConfig{Scheduling: scheduling},
+		},
+		{
+			name:     "allowed node pool",
+			nodePool: "highmem",
+			config:   &config.DeploymentConfig{KFServingAPIVersion: config.KFServingV1beta1, Scheduling: scheduling},
+		},
+		{
+			name:     "kubernetes backend",
+			nodePool: "highmem",
+			config:   &config.DeploymentConfig{DeploymentBackend: config.DeploymentBackendKubernetes, Scheduling: scheduling},
+		},
+		{
+			name:     "node pool not allowed",
+			nodePool: "gpu",
+			config:   &config.DeploymentConfig{KFServingAPIVersion: config.KFServingV1beta1, Scheduling: scheduling},
+			want:     ErrNodePoolNotAllowed,
+		},
+		{
+			name:     "v1alpha2 inference service",
+			nodePool: "highmem",
+			config:   &config.DeploymentConfig{KFServingAPIVersion: config.KFServingV1alpha2, Scheduling: scheduling},
+			want:     ErrNodePoolNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, validateNodePool(tt.nodePool, tt.config))
+		})
+	}
+}
@@ -344,7 +344,453 @@ func TestCreateInferenceServiceSpec(t *testing.T) {
 				},
 			},
 		},
-	}
+		{
+			name: "custom spec",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypeCustom,
+				Options: &models.ModelOption{
+					CustomPredictor: &models.CustomPredictor{
+						Image:          "gojek/triton-model:1",
+						Command:        []string{"tritonserver"},
+						Args:           []string{"--model-repository=/models"},
+						Ports:          []models.ContainerPort{{Name: "http1", Port: 8000}},
+						PredictPath:    "/v2/models/model/infer",
+						LivenessProbe:  &models.Probe{Path: "/v2/health/live", PeriodSeconds: 10},
+						ReadinessProbe: &models.Probe{InitialDelaySeconds: 5},
+					},
+				},
+				EnvVars:  models.EnvVars{{Name: "LOG_VERBOSE", Value: "1"}},
+				Metadata: model.Metadata,
+			},
+
+			exp: &v1alpha2.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", model.Name, versionId),
+					Namespace: project.Name,
+					Annotations: map[string]string{
+						"queue.sidecar.serving.knative.dev/resourcePercentage": queueResourcePercentage,
+						"prometheus.io/scrape":                                 "true",
+						"prometheus.io/port":                                   "8000",
+					},
+					Labels: map[string]string{
+						"gojek.com/app":                model.Metadata.App,
+						"gojek.com/orchestrator":       "merlin",
+						"gojek.com/stream":             model.Metadata.Stream,
+						"gojek.com/team":               model.Metadata.Team,
+						"gojek.com/user-labels/sample": "true",
+						"gojek.com/environment":        model.Metadata.Environment,
+					},
+				},
+				Spec: v1alpha2.InferenceServiceSpec{
+					Default: v1alpha2.EndpointSpec{
+						Predictor: v1alpha2.PredictorSpec{
+							Custom: &v1alpha2.CustomSpec{
+								Container: v1.Container{
+									Image:     "gojek/triton-model:1",
+									Command:   []string{"tritonserver"},
+									Args:      []string{"--model-repository=/models"},
+									Ports:     []v1.ContainerPort{{Name: "http1", ContainerPort: 8000}},
+									Env:       []v1.EnvVar{{Name: "LOG_VERBOSE", Value: "1"}},
+									Resources: resourceRequests,
+									LivenessProbe: &v1.Probe{
+										Handler:       v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/v2/health/live"}},
+										PeriodSeconds: 10,
+									},
+									ReadinessProbe: &v1.Probe{
+										Handler:             v1.Handler{TCPSocket: &v1.TCPSocketAction{}},
+										InitialDelaySeconds: 5,
+									},
+								},
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: minReplica,
+								MaxReplicas: maxReplica,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "tensorflow spec with autoscaling policy",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypeTensorflow,
+				Options:     &models.ModelOption{},
+				AutoscalingPolicy: &models.AutoscalingPolicy{
+					MetricType:  models.AutoscalingMetricRPS,
+					TargetValue: 12.5,
+					ScaleToZero: true,
+				},
+				Metadata: model.Metadata,
+			},
+			exp: &v1alpha2.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", model.Name, versionId),
+					Namespace: project.Name,
+					Annotations: map[string]string{
+						"queue.sidecar.serving.knative.dev/resourcePercentage": queueResourcePercentage,
+						"autoscaling.knative.dev/class":                        "kpa.autoscaling.knative.dev",
+						"autoscaling.knative.dev/metric":                       "rps",
+						"autoscaling.knative.dev/target":                       "12.5",
+						"autoscaling.knative.dev/minScale":                     "0",
+					},
+					Labels: map[string]string{
+						"gojek.com/app":                model.Metadata.App,
+						"gojek.com/orchestrator":       "merlin",
+						"gojek.com/stream":             model.Metadata.Stream,
+						"gojek.com/team":               model.Metadata.Team,
+						"gojek.com/user-labels/sample": "true",
+						"gojek.com/environment":        model.Metadata.Environment,
+					},
+				},
+				Spec: v1alpha2.InferenceServiceSpec{
+					Default: v1alpha2.EndpointSpec{
+						Predictor: v1alpha2.PredictorSpec{
+							Tensorflow: &v1alpha2.TensorflowSpec{
+								StorageURI: fmt.Sprintf("%s/model", model.ArtifactUri),
+								Resources:  resourceRequests,
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: 0,
+								MaxReplicas: maxReplica,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "tensorflow spec with resource limits",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypeTensorflow,
+				Options:     &models.ModelOption{},
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    minReplica,
+					MaxReplica:    maxReplica,
+					CpuRequest:    cpuRequest,
+					MemoryRequest: memoryRequest,
+					CpuLimit:      &cpuRequest,
+					MemoryLimit:   &memoryLimit,
+				},
+				Metadata: model.Metadata,
+			},
+			exp: &v1alpha2.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", model.Name, versionId),
+					Namespace: project.Name,
+					Annotations: map[string]string{
+						"queue.sidecar.serving.knative.dev/resourcePercentage": queueResourcePercentage,
+					},
+					Labels: map[string]string{
+						"gojek.com/app":                model.Metadata.App,
+						"gojek.com/orchestrator":       "merlin",
+						"gojek.com/stream":             model.Metadata.Stream,
+						"gojek.com/team":               model.Metadata.Team,
+						"gojek.com/user-labels/sample": "true",
+						"gojek.com/environment":        model.Metadata.Environment,
+					},
+				},
+				Spec: v1alpha2.InferenceServiceSpec{
+					Default: v1alpha2.EndpointSpec{
+						Predictor: v1alpha2.PredictorSpec{
+							Tensorflow: &v1alpha2.TensorflowSpec{
+								StorageURI: fmt.Sprintf("%s/model", model.ArtifactUri),
+								Resources: v1.ResourceRequirements{
+									Requests: v1.ResourceList{
+										v1.ResourceCPU:    cpuRequest,
+										v1.ResourceMemory: memoryRequest,
+									},
+									Limits: v1.ResourceList{
+										v1.ResourceCPU:    cpuRequest,
+										v1.ResourceMemory: memoryLimit,
+									},
+								},
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: minReplica,
+								MaxReplicas: maxReplica,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "pytorch spec with gpu request",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypePyTorch,
+				Options: &models.ModelOption{
+					PyTorchModelClassName: "MyModel",
+				},
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    minReplica,
+					MaxReplica:    maxReplica,
+					CpuRequest:    cpuRequest,
+					MemoryRequest: memoryRequest,
+					GPURequest: &models.GPURequest{
+						Name:  "nvidia-tesla-t4",
+						Count: 2,
+					},
+				},
+				Metadata: model.Metadata,
+			},
+			exp: &v1alpha2.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", model.Name, versionId),
+					Namespace: project.Name,
+					Annotations: map[string]string{
+						"queue.sidecar.serving.knative.dev/resourcePercentage": queueResourcePercentage,
+						"serving.kubeflow.org/gke-accelerator":                 "nvidia-tesla-t4",
+					},
+					Labels: map[string]string{
+						"gojek.com/app":                model.Metadata.App,
+						"gojek.com/orchestrator":       "merlin",
+						"gojek.com/stream":             model.Metadata.Stream,
+						"gojek.com/team":               model.Metadata.Team,
+						"gojek.com/user-labels/sample": "true",
+						"gojek.com/environment":        model.Metadata.Environment,
+					},
+				},
+				Spec: v1alpha2.InferenceServiceSpec{
+					Default: v1alpha2.EndpointSpec{
+						Predictor: v1alpha2.PredictorSpec{
+							PyTorch: &v1alpha2.PyTorchSpec{
+								StorageURI:     fmt.Sprintf("%s/model", model.ArtifactUri),
+								ModelClassName: "MyModel",
+								Resources: v1.ResourceRequirements{
+									Requests: v1.ResourceList{
+										v1.ResourceCPU:    cpuRequest,
+										v1.ResourceMemory: memoryRequest,
+									},
+									Limits: v1.ResourceList{
+										v1.ResourceCPU:    cpuLimit,
+										v1.ResourceMemory: memoryLimit,
+										"nvidia.com/gpu":  *resource.NewQuantity(2, resource.DecimalSI),
+									},
+								},
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: minReplica,
+								MaxReplicas: maxReplica,
+							},
+						},
+					},
+				},
+			},
+		},
+
+		{
+			name: "tensorflow spec with custom transformer",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypeTensorflow,
+				Options:     &models.ModelOption{},
+				Metadata:    model.Metadata,
+				Transformer: &models.Transformer{
+					Enabled:         true,
+					TransformerType: models.CustomTransformerType,
+					Image:           "gojek/transformer:1.0.0",
+					Command:         []string{"python"},
+					Args:            []string{"-m", "transformer"},
+					ResourceRequest: &models.ResourceRequest{
+						MinReplica:    2,
+						MaxReplica:    4,
+						CpuRequest:    resource.MustParse("500m"),
+						MemoryRequest: resource.MustParse("512Mi"),
+					},
+					EnvVars: models.EnvVars{{Name: "LOG_LEVEL", Value: "DEBUG"}},
+				},
+			},
+			exp: &v1alpha2.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", model.Name, versionId),
+					Namespace: project.Name,
+					Annotations: map[string]string{
+						"queue.sidecar.serving.knative.dev/resourcePercentage": queueResourcePercentage,
+					},
+					Labels: map[string]string{
+						"gojek.com/app":                model.Metadata.App,
+						"gojek.com/orchestrator":       "merlin",
+						"gojek.com/stream":             model.Metadata.Stream,
+						"gojek.com/team":               model.Metadata.Team,
+						"gojek.com/user-labels/sample": "true",
+						"gojek.com/environment":        model.Metadata.Environment,
+					},
+				},
+				Spec: v1alpha2.InferenceServiceSpec{
+					Default: v1alpha2.EndpointSpec{
+						Predictor: v1alpha2.PredictorSpec{
+							Tensorflow: &v1alpha2.TensorflowSpec{
+								StorageURI: fmt.Sprintf("%s/model", model.ArtifactUri),
+								Resources:  resourceRequests,
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: minReplica,
+								MaxReplicas: maxReplica,
+							},
+						},
+						Transformer: &v1alpha2.TransformerSpec{
+							Custom: &v1alpha2.CustomSpec{
+								Container: v1.Container{
+									Image:   "gojek/transformer:1.0.0",
+									Command: []string{"python"},
+									Args:    []string{"-m", "transformer"},
+									Env:     []v1.EnvVar{{Name: "LOG_LEVEL", Value: "DEBUG"}},
+									Resources: v1.ResourceRequirements{
+										Requests: v1.ResourceList{
+											v1.ResourceCPU:    resource.MustParse("500m"),
+											v1.ResourceMemory: resource.MustParse("512Mi"),
+										},
+										Limits: v1.ResourceList{
+											v1.ResourceCPU:    cpuLimit,
+											v1.ResourceMemory: memoryLimit,
+										},
+									},
+								},
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: 2,
+								MaxReplicas: 4,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "tensorflow spec with pyfunc transformer",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypeTensorflow,
+				Options: &models.ModelOption{
+					TransformerImageName: "gojek/project-model-transformer:1",
+				},
+				Metadata: model.Metadata,
+				Transformer: &models.Transformer{
+					Enabled:         true,
+					TransformerType: models.PyFuncTransformerType,
+				},
+			},
+			exp: &v1alpha2.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", model.Name, versionId),
+					Namespace: project.Name,
+					Annotations: map[string]string{
+						"queue.sidecar.serving.knative.dev/resourcePercentage": queueResourcePercentage,
+					},
+					Labels: map[string]string{
+						"gojek.com/app":                model.Metadata.App,
+						"gojek.com/orchestrator":       "merlin",
+						"gojek.com/stream":             model.Metadata.Stream,
+						"gojek.com/team":               model.Metadata.Team,
+						"gojek.com/user-labels/sample": "true",
+						"gojek.com/environment":        model.Metadata.Environment,
+					},
+				},
+				Spec: v1alpha2.InferenceServiceSpec{
+					Default: v1alpha2.EndpointSpec{
+						Predictor: v1alpha2.PredictorSpec{
+							Tensorflow: &v1alpha2.TensorflowSpec{
+								StorageURI: fmt.Sprintf("%s/model", model.ArtifactUri),
+								Resources:  resourceRequests,
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: minReplica,
+								MaxReplicas: maxReplica,
+							},
+						},
+						Transformer: &v1alpha2.TransformerSpec{
+							Custom: &v1alpha2.CustomSpec{
+								Container: v1.Container{
+									Image:     "gojek/project-model-transformer:1",
+									Env:       []v1.EnvVar{},
+									Resources: resourceRequests,
+								},
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: minReplica,
+								MaxReplicas: maxReplica,
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "tensorflow spec with alibi explainer",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypeTensorflow,
+				Options:     &models.ModelOption{},
+				Metadata:    model.Metadata,
+				Explainer: &models.Explainer{
+					Enabled:       true,
+					ExplainerType: models.AnchorTabularExplainerType,
+					Config:        map[string]string{"threshold": "0.95"},
+				},
+			},
+			exp: &v1alpha2.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", model.Name, versionId),
+					Namespace: project.Name,
+					Annotations: map[string]string{
+						"queue.sidecar.serving.knative.dev/resourcePercentage": queueResourcePercentage,
+					},
+					Labels: map[string]string{
+						"gojek.com/app":                model.Metadata.App,
+						"gojek.com/orchestrator":       "merlin",
+						"gojek.com/stream":             model.Metadata.Stream,
+						"gojek.com/team":               model.Metadata.Team,
+						"gojek.com/user-labels/sample": "true",
+						"gojek.com/environment":        model.Metadata.Environment,
+					},
+				},
+				Spec: v1alpha2.InferenceServiceSpec{
+					Default: v1alpha2.EndpointSpec{
+						Predictor: v1alpha2.PredictorSpec{
+							Tensorflow: &v1alpha2.TensorflowSpec{
+								StorageURI: fmt.Sprintf("%s/model", model.ArtifactUri),
+								Resources:  resourceRequests,
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: minReplica,
+								MaxReplicas: maxReplica,
+							},
+						},
+						Explainer: &v1alpha2.ExplainerSpec{
+							Alibi: &v1alpha2.AlibiExplainerSpec{
+								Type:       v1alpha2.AlibiAnchorsTabularExplainer,
+								StorageURI: fmt.Sprintf("%s/explainer", model.ArtifactUri),
+								Resources:  resourceRequests,
+								Config:     map[string]string{"threshold": "0.95"},
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: minReplica,
+								MaxReplicas: maxReplica,
+							},
+						},
+					},
+				},
+			},
+		}}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -356,6 +802,15 @@ func TestCreateInferenceServiceSpec(t *testing.T) {
 				MemoryRequest:           memoryRequest,
 				MemoryLimit:             memoryLimit,
 				QueueResourcePercentage: queueResourcePercentage,
+				GPUs: []config.GPUConfig{
+					{
+						Name:     "nvidia-tesla-t4",
+						MaxCount: 4,
+						NodeSelector: map[string]string{
+							"cloud.google.com/gke-accelerator": "nvidia-tesla-t4",
+						},
+					},
+				},
 			}
 
 			infSvcSpec := createInferenceServiceSpec(tt.modelSvc, deployConfig)
@@ -479,6 +934,82 @@ func TestPatchInferenceServiceSpec(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "tensorflow spec replacing autoscaling policy",
+			modelSvc: &models.Service{
+				Name:        models.CreateInferenceServiceName(model.Name, "1"),
+				Namespace:   project.Name,
+				ArtifactUri: model.ArtifactUri,
+				Type:        models.ModelTypeTensorflow,
+				Options:     &models.ModelOption{},
+				AutoscalingPolicy: &models.AutoscalingPolicy{
+					MetricType:  models.AutoscalingMetricCPU,
+					TargetValue: 80,
+				},
+				Metadata: model.Metadata,
+			},
+			original: &v1alpha2.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", model.Name, versionId),
+					Namespace: project.Name,
+					Annotations: map[string]string{
+						"queue.sidecar.serving.knative.dev/resourcePercentage": queueResourcePercentage,
+						"autoscaling.knative.dev/class":                        "kpa.autoscaling.knative.dev",
+						"autoscaling.knative.dev/metric":                       "concurrency",
+						"autoscaling.knative.dev/target":                       "10",
+						"autoscaling.knative.dev/minScale":                     "0",
+					},
+				},
+				Spec: v1alpha2.InferenceServiceSpec{
+					Default: v1alpha2.EndpointSpec{
+						Predictor: v1alpha2.PredictorSpec{
+							Tensorflow: &v1alpha2.TensorflowSpec{
+								StorageURI: fmt.Sprintf("%s/model", model.ArtifactUri),
+								Resources:  resourceRequests,
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: 0,
+								MaxReplicas: maxReplica,
+							},
+						},
+					},
+				},
+			},
+			exp: &v1alpha2.InferenceService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("%s-%d", model.Name, versionId),
+					Namespace: project.Name,
+					Annotations: map[string]string{
+						"queue.sidecar.serving.knative.dev/resourcePercentage": queueResourcePercentage,
+						"autoscaling.knative.dev/class":                        "hpa.autoscaling.knative.dev",
+						"autoscaling.knative.dev/metric":                       "cpu",
+						"autoscaling.knative.dev/target":                       "80",
+					},
+					Labels: map[string]string{
+						"gojek.com/app":                model.Metadata.App,
+						"gojek.com/orchestrator":       "merlin",
+						"gojek.com/stream":             model.Metadata.Stream,
+						"gojek.com/team":               model.Metadata.Team,
+						"gojek.com/user-labels/sample": "true",
+						"gojek.com/environment":        model.Metadata.Environment,
+					},
+				},
+				Spec: v1alpha2.InferenceServiceSpec{
+					Default: v1alpha2.EndpointSpec{
+						Predictor: v1alpha2.PredictorSpec{
+							Tensorflow: &v1alpha2.TensorflowSpec{
+								StorageURI: fmt.Sprintf("%s/model", model.ArtifactUri),
+								Resources:  resourceRequests,
+							},
+							DeploymentSpec: v1alpha2.DeploymentSpec{
+								MinReplicas: minReplica,
+								MaxReplicas: maxReplica,
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -498,3 +1029,73 @@ func TestPatchInferenceServiceSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceLimit(t *testing.T) {
+	cpuLimit := resource.MustParse("1500m")
+
+	tests := []struct {
+		name         string
+		limit        *resource.Quantity
+		defaultLimit resource.Quantity
+		request      resource.Quantity
+		maxRatio     float64
+		exp          resource.Quantity
+	}{
+		{
+			name:    "explicit limit",
+			limit:   &cpuLimit,
+			request: resource.MustParse("1"),
+			exp:     resource.MustParse("1500m"),
+		},
+		{
+			name:         "explicit limit over default limit",
+			limit:        &cpuLimit,
+			defaultLimit: resource.MustParse("4"),
+			request:      resource.MustParse("1"),
+			exp:          resource.MustParse("1500m"),
+		},
+		{
+			name:         "default limit of the environment",
+			defaultLimit: resource.MustParse("2"),
+			request:      resource.MustParse("1500m"),
+			exp:          resource.MustParse("2"),
+		},
+		{
+			name:         "default limit below the request",
+			defaultLimit: resource.MustParse("2"),
+			request:      resource.MustParse("4"),
+			exp:          resource.MustParse("8"),
+		},
+		{
+			name:         "default limit above the max ratio",
+			defaultLimit: resource.MustParse("4Gi"),
+			request:      resource.MustParse("1Gi"),
+			maxRatio:     1.5,
+			exp:          resource.MustParse("1536Mi"),
+		},
+		{
+			name:    "default to twice the request",
+			request: resource.MustParse("500m"),
+			exp:     resource.MustParse("1"),
+		},
+		{
+			name:     "default capped at max ratio",
+			request:  resource.MustParse("1Gi"),
+			maxRatio: 1.5,
+			exp:      resource.MustParse("1536Mi"),
+		},
+		{
+			name:     "max ratio above default",
+			request:  resource.MustParse("1Gi"),
+			maxRatio: 4,
+			exp:      resource.MustParse("2Gi"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := resourceLimit(tt.limit, tt.defaultLimit, tt.request, tt.maxRatio)
+			assert.Equal(t, 0, tt.exp.Cmp(limit), "expected %s, got %s", tt.exp.String(), limit.String())
+		})
+	}
+}
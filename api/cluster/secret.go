@@ -0,0 +1,157 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"github.com/gojek/merlin/models"
+)
+
+// Volume of the Secret whose keys are mounted as files
+const secretVolumeName = "merlin-secrets"
+
+// secretResourceName returns the name of the Secret holding the project secrets of the model service.
+func secretResourceName(modelService *models.Service) string {
+	return fmt.Sprintf("%s-secrets", modelService.Name)
+}
+
+// createSecretSpec returns the Secret holding the project secrets referenced by the environment variables,
+// keyed by secret name.
+func createSecretSpec(modelService *models.Service) *v1.Secret {
+	data := make(map[string][]byte, len(modelService.Secrets))
+	for name, value := range modelService.Secrets {
+		data[name] = []byte(value)
+	}
+
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretResourceName(modelService),
+			Namespace: modelService.Namespace,
+			Labels:    createLabels(modelService),
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// renderSecretSpec returns the Secret of the model service along with its kind.
+func renderSecretSpec(modelService *models.Service) *v1.Secret {
+	secret := createSecretSpec(modelService)
+	secret.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Secret"))
+	return secret
+}
+
+// applySecret creates or updates the Secret of the model service, or deletes it when no project secret is referenced.
+func applySecret(client corev1.SecretsGetter, modelService *models.Service) error {
+	if len(modelService.Secrets) == 0 {
+		return deleteSecret(client, modelService)
+	}
+
+	secrets := client.Secrets(modelService.Namespace)
+	secret := createSecretSpec(modelService)
+	orig, err := secrets.Get(secret.Name, metav1.GetOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+		_, err = secrets.Create(secret)
+		return err
+	}
+
+	orig.Labels = secret.Labels
+	orig.Data = secret.Data
+	_, err = secrets.Update(orig)
+	return err
+}
+
+// deleteSecret deletes the Secret of the model service if it exists.
+func deleteSecret(client corev1.SecretsGetter, modelService *models.Service) error {
+	err := client.Secrets(modelService.Namespace).Delete(secretResourceName(modelService), &metav1.DeleteOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// injectSecretEnvVars adds the environment variables referencing project secrets to the container, either read
+// from the Secret of the model service or mounted as files from its volume.
+func injectSecretEnvVars(container *v1.Container, envVars models.EnvVars, modelService *models.Service) {
+	secretName := secretResourceName(modelService)
+	for _, ev := range envVars {
+		if !ev.IsSecret() {
+			continue
+		}
+
+		if ev.MountPath == "" {
+			container.Env = append(container.Env, v1.EnvVar{
+				Name: ev.Name,
+				ValueFrom: &v1.EnvVarSource{
+					SecretKeyRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: secretName},
+						Key:                  ev.SecretName,
+					},
+				},
+			})
+			continue
+		}
+
+		container.Env = append(container.Env, v1.EnvVar{Name: ev.Name, Value: ev.MountPath})
+		container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{
+			Name:      secretVolumeName,
+			MountPath: ev.MountPath,
+			SubPath:   ev.SecretName,
+			ReadOnly:  true,
+		})
+	}
+}
+
+// secretVolumes returns the volume of the Secret of the model service if any of its secrets is mounted as a file.
+func secretVolumes(modelService *models.Service) []v1.Volume {
+	if !hasMountedSecret(modelService) {
+		return nil
+	}
+
+	return []v1.Volume{
+		{
+			Name: secretVolumeName,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{SecretName: secretResourceName(modelService)},
+			},
+		},
+	}
+}
+
+func hasMountedSecret(modelService *models.Service) bool {
+	envVars := append(models.EnvVars{}, modelService.EnvVars...)
+	if transformer := modelService.Transformer; transformer != nil && transformer.Enabled {
+		envVars = append(envVars, transformer.EnvVars...)
+	}
+	if explainer := modelService.Explainer; explainer != nil && explainer.Enabled {
+		envVars = append(envVars, explainer.EnvVars...)
+	}
+
+	for _, ev := range envVars {
+		if ev.IsSecret() && ev.MountPath != "" {
+			return true
+		}
+	}
+	return false
+}
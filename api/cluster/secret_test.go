@@ -0,0 +1,188 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+// +build unit
+
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/gojek/merlin/models"
+)
+
+func TestApplySecret(t *testing.T) {
+	existing := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "model-1-secrets", Namespace: "project"},
+		Data:       map[string][]byte{"old-key": []byte("old")},
+	}
+
+	tests := []struct {
+		name     string
+		existing []runtime.Object
+		secrets  map[string]string
+		want     map[string][]byte
+	}{
+		{
+			name:    "create",
+			secrets: map[string]string{"api-key": "my-api-key"},
+			want:    map[string][]byte{"api-key": []byte("my-api-key")},
+		},
+		{
+			name:     "update",
+			existing: []runtime.Object{existing},
+			secrets:  map[string]string{"api-key": "my-api-key"},
+			want:     map[string][]byte{"api-key": []byte("my-api-key")},
+		},
+		{
+			name:     "delete when no secret is referenced",
+			existing: []runtime.Object{existing},
+		},
+		{
+			name: "no secret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(tt.existing...).CoreV1()
+			modelSvc := &models.Service{Name: "model-1", Namespace: "project", Secrets: tt.secrets}
+
+			err := applySecret(client, modelSvc)
+			assert.NoError(t, err)
+
+			secret, err := client.Secrets("project").Get("model-1-secrets", metav1.GetOptions{})
+			if tt.want == nil {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, secret.Data)
+			assert.Equal(t, "merlin", secret.Labels[labelOrchestratorName])
+		})
+	}
+}
+
+func TestInjectSecretEnvVars(t *testing.T) {
+	modelSvc := &models.Service{Name: "model-1", Namespace: "project"}
+	envVars := models.EnvVars{
+		{Name: "LOG_LEVEL", Value: "info"},
+		{Name: "API_KEY", SecretName: "api-key"},
+		{Name: "GOOGLE_APPLICATION_CREDENTIALS", SecretName: "service-account.json", MountPath: "/mnt/secrets/service-account.json"},
+	}
+
+	container := v1.Container{Env: envVars.ToKubernetesEnvVars()}
+	injectSecretEnvVars(&container, envVars, modelSvc)
+
+	assert.Equal(t, []v1.EnvVar{
+		{Name: "LOG_LEVEL", Value: "info"},
+		{
+			Name: "API_KEY",
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: "model-1-secrets"},
+					Key:                  "api-key",
+				},
+			},
+		},
+		{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: "/mnt/secrets/service-account.json"},
+	}, container.Env)
+	assert.Equal(t, []v1.VolumeMount{
+		{
+			Name:      secretVolumeName,
+			MountPath: "/mnt/secrets/service-account.json",
+			SubPath:   "service-account.json",
+			ReadOnly:  true,
+		},
+	}, container.VolumeMounts)
+}
+
+func TestSecretVolumes(t *testing.T) {
+	secretVolume := []v1.Volume{
+		{
+			Name: secretVolumeName,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{SecretName: "model-1-secrets"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		modelSvc *models.Service
+		want     []v1.Volume
+	}{
+		{
+			name: "secret read from environment variable",
+			modelSvc: &models.Service{
+				Name:    "model-1",
+				EnvVars: models.EnvVars{{Name: "API_KEY", SecretName: "api-key"}},
+			},
+		},
+		{
+			name: "secret mounted by model",
+			modelSvc: &models.Service{
+				Name:    "model-1",
+				EnvVars: models.EnvVars{{Name: "CREDENTIALS", SecretName: "credentials", MountPath: "/mnt/credentials"}},
+			},
+			want: secretVolume,
+		},
+		{
+			name: "secret mounted by transformer",
+			modelSvc: &models.Service{
+				Name: "model-1",
+				Transformer: &models.Transformer{
+					Enabled: true,
+					EnvVars: models.EnvVars{{Name: "CREDENTIALS", SecretName: "credentials", MountPath: "/mnt/credentials"}},
+				},
+			},
+			want: secretVolume,
+		},
+		{
+			name: "secret mounted by explainer",
+			modelSvc: &models.Service{
+				Name: "model-1",
+				Explainer: &models.Explainer{
+					Enabled:       true,
+					ExplainerType: models.CustomExplainerType,
+					EnvVars:       models.EnvVars{{Name: "CREDENTIALS", SecretName: "credentials", MountPath: "/mnt/credentials"}},
+				},
+			},
+			want: secretVolume,
+		},
+		{
+			name: "secret mounted by disabled explainer",
+			modelSvc: &models.Service{
+				Name: "model-1",
+				Explainer: &models.Explainer{
+					ExplainerType: models.CustomExplainerType,
+					EnvVars:       models.EnvVars{{Name: "CREDENTIALS", SecretName: "credentials", MountPath: "/mnt/credentials"}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, secretVolumes(tt.modelSvc))
+		})
+	}
+}
@@ -54,6 +54,7 @@ import (
 	"github.com/gojek/merlin/imagebuilder"
 	"github.com/gojek/merlin/istio"
 	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/metrics"
 	"github.com/gojek/merlin/mlp"
 	"github.com/gojek/merlin/models"
 	"github.com/gojek/merlin/service"
@@ -123,11 +124,32 @@ func main() {
 	mlpApiClient := mlp.NewAPIClient(mlpHttpClient, cfg.MlpApiConfig.ApiHost, cfg.MlpApiConfig.EncryptionKey)
 
 	vaultClient := initVault(cfg)
-	webServiceBuilder, predJobBuilder := initImageBuilder(cfg, vaultClient)
+	webServiceBuilder, transformerBuilder, predJobBuilder := initImageBuilder(cfg, vaultClient)
+
+	// owner identifies this replica when claiming deployment tasks and the leader lease
+	owner := replicaOwner()
+	deploymentTaskQueue := service.NewDeploymentTaskQueue(storage.NewDeploymentTaskStorage(db), owner, cfg.DeploymentQueueConfig, clock.RealClock{})
+
+	// background loops acting on shared resources only run on the replica holding the leader lease
+	leaderLease := service.NewLeaderLease(storage.NewLeaseStorage(db), owner, cfg.LeaderElectionConfig.LeaseDuration, clock.RealClock{})
+	go leaderLease.Run(make(chan struct{}))
+
+	quotaService := initQuotaService(cfg, db)
 
 	modelEndpointService := initModelEndpointService(cfg, vaultClient, db)
-	versionEndpointService := initVersionEndpointService(cfg, webServiceBuilder, vaultClient, db)
-	predictionJobService := initPredictionJobService(cfg, mlpApiClient, predJobBuilder, vaultClient, db)
+	versionEndpointService := initVersionEndpointService(cfg, mlpApiClient, webServiceBuilder, transformerBuilder, vaultClient, db, deploymentTaskQueue, quotaService)
+	predictionJobService := initPredictionJobService(cfg, mlpApiClient, predJobBuilder, vaultClient, db, deploymentTaskQueue, quotaService)
+
+	deploymentTaskWorker := service.NewDeploymentTaskWorker(deploymentTaskQueue, map[models.DeploymentTaskType]service.DeploymentTaskHandler{
+		models.DeployVersionEndpointTask:   versionEndpointService,
+		models.UndeployVersionEndpointTask: versionEndpointService,
+		models.SubmitPredictionJobTask:     predictionJobService,
+	})
+	go deploymentTaskWorker.Run(make(chan struct{}))
+
+	if cfg.ReconcilerConfig.Enabled {
+		runInferenceServiceReconcilers(cfg, vaultClient, db, leaderLease, versionEndpointService)
+	}
 	logService := initLogService(cfg, vaultClient)
 
 	// use "mlp" as product name for enforcer so that same policy can be reused by excalibur
@@ -159,32 +181,70 @@ func main() {
 		gitlabConfig.DashboardRepository, gitlabConfig.DashboardBranch,
 		gitlabConfig.AlertRepository, gitlabConfig.AlertBranch)
 
+	costService := initCostService(cfg, projectsService, modelsService, db)
 	tracker, err := cronjob.NewTracker(projectsService,
 		modelsService,
 		storage.NewPredictionJobStorage(db),
-		storage.NewDeploymentStorage(db))
+		storage.NewDeploymentStorage(db),
+		costService)
 	if err != nil {
 		log.Panicf("unable to create tracker %v", err)
 	}
 	tracker.Start()
 
+	var metricsProvider metrics.Provider
+	var rolloutGateEvaluator service.RolloutGateEvaluator
+	var trafficEvaluator service.EndpointTrafficEvaluator
+	if cfg.MetricsConfig.PrometheusURL != "" {
+		metricsProvider = metrics.NewPrometheusProvider(&http.Client{Timeout: cfg.MetricsConfig.QueryTimeout}, cfg.MetricsConfig.PrometheusURL)
+		rolloutGateEvaluator = metrics.NewGateEvaluator(metricsProvider, cfg.RolloutConfig.GateWindow)
+		trafficEvaluator = metrics.NewTrafficEvaluator(metricsProvider)
+	}
+
+	modelEndpointRolloutService := service.NewModelEndpointRolloutService(
+		storage.NewModelEndpointRolloutStorage(db), modelEndpointService, modelsService,
+		storage.NewVersionEndpointStorage(db), rolloutGateEvaluator, leaderLease, clock.RealClock{}, cfg.RolloutConfig.SyncPeriod)
+	go modelEndpointRolloutService.Run(make(chan struct{}))
+
+	scalingScheduleService := service.NewScalingScheduleService(storage.NewScalingScheduleStorage(db),
+		versionEndpointService, modelsService, quotaService, storage.NewVersionEndpointEventStorage(db))
+	scalingScheduler, err := cronjob.NewScalingScheduler(scalingScheduleService, clock.RealClock{})
+	if err != nil {
+		log.Panicf("unable to create scaling scheduler %v", err)
+	}
+	scalingScheduler.Start()
+
+	if cfg.EndpointJanitorConfig.Enabled {
+		endpointJanitor := service.NewEndpointJanitor(storage.NewVersionEndpointStorage(db),
+			storage.NewVersionEndpointEventStorage(db), versionEndpointService, modelsService, versionsService,
+			modelEndpointService, trafficEvaluator, leaderLease, clock.RealClock{}, cfg.EndpointJanitorConfig)
+		go endpointJanitor.Run(make(chan struct{}))
+	}
+
 	appCtx := api.AppContext{
 		EnvironmentService: environmentService,
 
-		ProjectsService:           projectsService,
-		ModelsService:             modelsService,
-		ModelEndpointsService:     modelEndpointService,
-		VersionsService:           versionsService,
-		EndpointsService:          versionEndpointService,
-		PredictionJobService:      predictionJobService,
-		LogService:                logService,
-		SecretService:             secretService,
-		ModelEndpointAlertService: modelEndpointAlertService,
-		AuthorizationEnabled:      cfg.AuthorizationConfig.AuthorizationEnabled,
-		MonitoringConfig:          cfg.FeatureToggleConfig.MonitoringConfig,
-		AlertEnabled:              cfg.FeatureToggleConfig.AlertConfig.AlertEnabled,
-		DB:                        db,
-		Enforcer:                  authEnforcer,
+		ProjectsService:             projectsService,
+		ModelsService:               modelsService,
+		ModelEndpointsService:       modelEndpointService,
+		VersionsService:             versionsService,
+		EndpointsService:            versionEndpointService,
+		DeploymentService:           service.NewDeploymentService(storage.NewDeploymentStorage(db)),
+		QuotaService:                quotaService,
+		CostService:                 costService,
+		PredictionJobService:        predictionJobService,
+		LogService:                  logService,
+		SecretService:               secretService,
+		ModelEndpointAlertService:   modelEndpointAlertService,
+		ModelEndpointRolloutService: modelEndpointRolloutService,
+		ScalingScheduleService:      scalingScheduleService,
+		MetricsProvider:             metricsProvider,
+		AuthorizationEnabled:        cfg.AuthorizationConfig.AuthorizationEnabled,
+		AdminUsers:                  cfg.AuthorizationConfig.AdminUsers,
+		MonitoringConfig:            cfg.FeatureToggleConfig.MonitoringConfig,
+		AlertEnabled:                cfg.FeatureToggleConfig.AlertConfig.AlertEnabled,
+		DB:                          db,
+		Enforcer:                    authEnforcer,
 	}
 
 	router := mux.NewRouter()
@@ -288,7 +348,7 @@ func (h uiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	http.FileServer(http.Dir(h.staticPath)).ServeHTTP(w, r)
 }
 
-func initPredictionJobService(cfg *config.Config, mlpApiClient mlp.APIClient, builder imagebuilder.ImageBuilder, vaultClient vault.VaultClient, db *gorm.DB) service.PredictionJobService {
+func initPredictionJobService(cfg *config.Config, mlpApiClient mlp.APIClient, builder imagebuilder.ImageBuilder, vaultClient vault.VaultClient, db *gorm.DB, taskQueue *service.DeploymentTaskQueue, quotaService service.QuotaService) service.PredictionJobService {
 	controllers := make(map[string]batch.Controller)
 	predictionJobStorage := storage.NewPredictionJobStorage(db)
 	for _, env := range cfg.EnvironmentConfigs {
@@ -324,14 +384,14 @@ func initPredictionJobService(cfg *config.Config, mlpApiClient mlp.APIClient, bu
 			GcpProject:  env.GcpProject,
 		}
 
-		ctl := batch.NewController(predictionJobStorage, mlpApiClient, sparkClient, kubeClient, manifestManager, envMetadata)
+		ctl := batch.NewController(predictionJobStorage, mlpApiClient, sparkClient, kubeClient, manifestManager, envMetadata, env.Scheduling)
 		stopCh := make(chan struct{})
 		go ctl.Run(stopCh)
 
 		controllers[env.Name] = ctl
 	}
 
-	return service.NewPredictionJobService(controllers, builder, predictionJobStorage, clock.RealClock{}, cfg.Environment)
+	return service.NewPredictionJobService(controllers, builder, predictionJobStorage, taskQueue, quotaService, clock.RealClock{}, cfg.Environment)
 }
 
 func initEnvironmentService(cfg *config.Config, db *gorm.DB) service.EnvironmentService {
@@ -364,6 +424,11 @@ func initEnvironmentService(cfg *config.Config, db *gorm.DB) service.Environment
 
 		cfg := config.ParseDeploymentConfig(envCfg)
 
+		endpointLifetime, err := models.NewEndpointLifetime(envCfg.EndpointLifetime)
+		if err != nil {
+			log.Panicf("invalid endpoint lifetime of environment %s: %v", envCfg.Name, err)
+		}
+
 		env, err := envSvc.GetEnvironment(envCfg.Name)
 		if err != nil {
 			if !gorm.IsRecordNotFoundError(err) {
@@ -385,6 +450,7 @@ func initEnvironmentService(cfg *config.Config, db *gorm.DB) service.Environment
 					CpuRequest:    cfg.CpuRequest,
 					MemoryRequest: cfg.MemoryRequest,
 				},
+				EndpointLifetime:       endpointLifetime,
 				IsDefaultPredictionJob: isDefaultPredictionJob,
 				IsPredictionJobEnabled: envCfg.IsPredictionJobEnabled,
 			}
@@ -415,6 +481,7 @@ func initEnvironmentService(cfg *config.Config, db *gorm.DB) service.Environment
 				CpuRequest:    cfg.CpuRequest,
 				MemoryRequest: cfg.MemoryRequest,
 			}
+			env.EndpointLifetime = endpointLifetime
 			env.IsDefaultPredictionJob = isDefaultPredictionJob
 			env.IsPredictionJobEnabled = envCfg.IsPredictionJobEnabled
 
@@ -504,7 +571,7 @@ func initModelEndpointService(cfg *config.Config, vaultClient vault.VaultClient,
 	return service.NewModelEndpointsService(istioClients, db, cfg.Environment)
 }
 
-func initVersionEndpointService(cfg *config.Config, builder imagebuilder.ImageBuilder, vaultClient vault.VaultClient, db *gorm.DB) service.EndpointsService {
+func initVersionEndpointService(cfg *config.Config, mlpApiClient mlp.APIClient, builder imagebuilder.ImageBuilder, transformerBuilder imagebuilder.ImageBuilder, vaultClient vault.VaultClient, db *gorm.DB, taskQueue *service.DeploymentTaskQueue, quotaService service.QuotaService) service.EndpointsService {
 	controllers := make(map[string]cluster.Controller)
 	for _, env := range cfg.EnvironmentConfigs {
 		clusterName := env.Cluster
@@ -529,11 +596,74 @@ func initVersionEndpointService(cfg *config.Config, builder imagebuilder.ImageBu
 		controllers[env.Name] = ctl
 	}
 
-	return service.NewEndpointService(controllers, builder, storage.NewVersionEndpointStorage(db),
-		storage.NewDeploymentStorage(db), cfg.Environment,
+	return service.NewEndpointService(controllers, builder, transformerBuilder, storage.NewVersionEndpointStorage(db),
+		storage.NewDeploymentStorage(db), storage.NewVersionEndpointEventStorage(db), taskQueue, quotaService, mlpApiClient, cfg.Environment,
 		cfg.FeatureToggleConfig.MonitoringConfig)
 }
 
+func runInferenceServiceReconcilers(cfg *config.Config, vaultClient vault.VaultClient, db *gorm.DB, leader cluster.LeaderElector, redeployer cluster.EndpointRedeployer) {
+	versionEndpointStorage := storage.NewVersionEndpointStorage(db)
+	eventStorage := storage.NewVersionEndpointEventStorage(db)
+	for _, env := range cfg.EnvironmentConfigs {
+		// there is no inference service to reconcile in the environments deploying to plain deployments
+		if env.DeploymentBackend == config.DeploymentBackendKubernetes {
+			continue
+		}
+
+		clusterName := env.Cluster
+		clusterSecret, err := vaultClient.GetClusterSecret(clusterName)
+		if err != nil {
+			log.Panicf("unable to get cluster secret of cluster: %s %v", clusterName, err)
+		}
+
+		reconciler, err := cluster.NewInferenceServiceReconciler(cluster.ClusterConfig{
+			Host:       clusterSecret.Endpoint,
+			CACert:     clusterSecret.CaCert,
+			ClientCert: clusterSecret.ClientCert,
+			ClientKey:  clusterSecret.ClientKey,
+
+			ClusterName: clusterName,
+			GcpProject:  env.GcpProject,
+		}, env.KFServingAPIVersion, env.Name, env.ReapplyDriftedEndpoints, cfg.ReconcilerConfig, leader, versionEndpointStorage, eventStorage, redeployer)
+		if err != nil {
+			log.Panicf("unable to initialize inference service reconciler %v", err)
+		}
+
+		go reconciler.Run(make(chan struct{}))
+	}
+}
+
+func replicaOwner() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Panicf("unable to get hostname %v", err)
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+func initQuotaService(cfg *config.Config, db *gorm.DB) service.QuotaService {
+	defaultQuotas := make(map[string]models.QuotaLimits)
+	for _, env := range cfg.EnvironmentConfigs {
+		limits, err := models.NewQuotaLimits(env.DefaultQuota)
+		if err != nil {
+			log.Panicf("invalid default quota of environment %s: %v", env.Name, err)
+		}
+		defaultQuotas[env.Name] = limits
+	}
+
+	return service.NewQuotaService(storage.NewProjectQuotaStorage(db), defaultQuotas)
+}
+
+func initCostService(cfg *config.Config, projectsService service.ProjectsService, modelsService service.ModelsService, db *gorm.DB) service.CostService {
+	unitPrices := make(map[string]config.UnitPriceConfig)
+	for _, env := range cfg.EnvironmentConfigs {
+		unitPrices[env.Name] = env.UnitPrice
+	}
+
+	return service.NewCostService(projectsService, modelsService, storage.NewDeploymentStorage(db), storage.NewVersionEndpointStorage(db),
+		storage.NewPredictionJobStorage(db), unitPrices, clock.RealClock{})
+}
+
 func initVault(cfg *config.Config) vault.VaultClient {
 	vaultConfig := &vault.Config{
 		Address: cfg.VaultConfig.Address,
@@ -546,7 +676,7 @@ func initVault(cfg *config.Config) vault.VaultClient {
 	return vaultClient
 }
 
-func initImageBuilder(cfg *config.Config, vaultClient vault.VaultClient) (webserviceBuilder imagebuilder.ImageBuilder, predJobBuilder imagebuilder.ImageBuilder) {
+func initImageBuilder(cfg *config.Config, vaultClient vault.VaultClient) (webserviceBuilder imagebuilder.ImageBuilder, transformerBuilder imagebuilder.ImageBuilder, predJobBuilder imagebuilder.ImageBuilder) {
 	imgBuilderClusterSecret, err := vaultClient.GetClusterSecret(cfg.ImageBuilderConfig.ClusterName)
 	if err != nil {
 		log.Panicf("unable to retrieve secret for cluster %s from vault", cfg.ImageBuilderConfig.ClusterName)
@@ -587,6 +717,7 @@ func initImageBuilder(cfg *config.Config, vaultClient vault.VaultClient) (webser
 		Environment: cfg.Environment,
 	}
 	webserviceBuilder = imagebuilder.NewModelServiceImageBuilder(kubeClient, webServiceConfig)
+	transformerBuilder = imagebuilder.NewTransformerImageBuilder(kubeClient, webServiceConfig)
 	predJobConfig := imagebuilder.Config{
 		BuildContextUrl:      cfg.ImageBuilderConfig.PredictionJobBuildContextUri,
 		DockerfilePath:       cfg.ImageBuilderConfig.PredictionJobDockerfilePath,
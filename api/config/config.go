@@ -15,16 +15,14 @@
 package config
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 
 	"github.com/gojek/mlp/api/pkg/instrumentation/newrelic"
 	"github.com/gojek/mlp/api/pkg/instrumentation/sentry"
 )
 
-const (
-	MaxDeployedVersion = 2
-)
-
 type Config struct {
 	Environment string `envconfig:"ENVIRONMENT" default:"dev"`
 	Port        int    `envconfig:"PORT" default:"3000"`
@@ -42,7 +40,13 @@ type Config struct {
 
 	MlpApiConfig MlpApiConfig
 
-	FeatureToggleConfig FeatureToggleConfig
+	FeatureToggleConfig   FeatureToggleConfig
+	RolloutConfig         RolloutConfig
+	MetricsConfig         MetricsConfig
+	DeploymentQueueConfig DeploymentQueueConfig
+	LeaderElectionConfig  LeaderElectionConfig
+	ReconcilerConfig      ReconcilerConfig
+	EndpointJanitorConfig EndpointJanitorConfig
 
 	ReactAppConfig ReactAppConfig
 
@@ -100,6 +104,8 @@ type VaultConfig struct {
 type AuthorizationConfig struct {
 	AuthorizationEnabled   bool   `envconfig:"AUTHORIZATION_ENABLED" default:"true"`
 	AuthorizationServerUrl string `envconfig:"AUTHORIZATION_SERVER_URL" default:"http://localhost:4466"`
+	// AdminUsers are the emails of the users allowed to use the admin APIs, such as changing the project quotas
+	AdminUsers []string `envconfig:"AUTHORIZATION_ADMIN_USERS"`
 }
 
 type FeatureToggleConfig struct {
@@ -132,6 +138,61 @@ type WardenConfig struct {
 	ApiHost string `envconfig:"WARDEN_API_HOST"`
 }
 
+// RolloutConfig stores the configuration of model endpoint canary rollouts.
+type RolloutConfig struct {
+	// SyncPeriod is the interval to check and advance progressing rollouts
+	SyncPeriod time.Duration `envconfig:"ROLLOUT_SYNC_PERIOD" default:"30s"`
+	// GateWindow is the time range the SLIs of rollout gates are aggregated over
+	GateWindow time.Duration `envconfig:"ROLLOUT_GATE_WINDOW" default:"5m"`
+}
+
+// MetricsConfig stores the configuration of the metrics provider used to evaluate SLIs.
+// Metrics API and rollout gates are disabled if PrometheusURL is empty.
+type MetricsConfig struct {
+	PrometheusURL string        `envconfig:"METRICS_PROMETHEUS_URL"`
+	QueryTimeout  time.Duration `envconfig:"METRICS_QUERY_TIMEOUT" default:"10s"`
+}
+
+// DeploymentQueueConfig stores the configuration of the durable deployment task queue.
+type DeploymentQueueConfig struct {
+	// Workers is the number of tasks executed concurrently by each API replica
+	Workers      int           `envconfig:"DEPLOYMENT_QUEUE_WORKERS" default:"10"`
+	PollInterval time.Duration `envconfig:"DEPLOYMENT_QUEUE_POLL_INTERVAL" default:"5s"`
+	// LeaseDuration is the time after which a task whose owner stops sending heartbeats can be claimed by another replica
+	LeaseDuration time.Duration `envconfig:"DEPLOYMENT_QUEUE_LEASE_DURATION" default:"1m"`
+	// MaxAttempts is the number of times a task can be claimed before it's abandoned
+	MaxAttempts       int           `envconfig:"DEPLOYMENT_QUEUE_MAX_ATTEMPTS" default:"3"`
+	ReconcileInterval time.Duration `envconfig:"DEPLOYMENT_QUEUE_RECONCILE_INTERVAL" default:"5m"`
+	// OrphanGracePeriod is the minimum age of a pending resource without unfinished task before it's marked as failed
+	OrphanGracePeriod time.Duration `envconfig:"DEPLOYMENT_QUEUE_ORPHAN_GRACE_PERIOD" default:"10m"`
+}
+
+// LeaderElectionConfig stores the configuration of the lease electing the API replica which runs the background loops,
+// e.g. the rollouts, the reconcilers and the endpoint janitor.
+type LeaderElectionConfig struct {
+	// LeaseDuration is the time after which another replica takes over once the leader stops renewing its lease
+	LeaseDuration time.Duration `envconfig:"LEADER_LEASE_DURATION" default:"1m"`
+}
+
+// ReconcilerConfig stores the configuration of the reconcilers which sync version endpoints with their inference services.
+type ReconcilerConfig struct {
+	Enabled bool `envconfig:"RECONCILER_ENABLED" default:"true"`
+	// ResyncPeriod is the interval of re-checking all inference services, in addition to watching their changes
+	ResyncPeriod time.Duration `envconfig:"RECONCILER_RESYNC_PERIOD" default:"5m"`
+	// MaxReapplies is the number of times a drifted endpoint is re-applied in a row before giving up, if re-applying is enabled
+	MaxReapplies int `envconfig:"RECONCILER_MAX_REAPPLIES" default:"3"`
+}
+
+// EndpointJanitorConfig stores the configuration of the janitor undeploying the version endpoints whose ttl elapsed
+// or which have been idle for longer than their idle timeout.
+type EndpointJanitorConfig struct {
+	Enabled bool `envconfig:"ENDPOINT_JANITOR_ENABLED" default:"true"`
+	// SyncPeriod is the interval to check the lifetime of the version endpoints
+	SyncPeriod time.Duration `envconfig:"ENDPOINT_JANITOR_SYNC_PERIOD" default:"10m"`
+	// GracePeriod is the time between the warning event and the undeployment of an expired version endpoint
+	GracePeriod time.Duration `envconfig:"ENDPOINT_JANITOR_GRACE_PERIOD" default:"1h"`
+}
+
 type MlpApiConfig struct {
 	ApiHost       string `envconfig:"MLP_API_HOST" required:"true"`
 	EncryptionKey string `envconfig:"MLP_API_ENCRYPTION_KEY" required:"true"`
@@ -0,0 +1,24 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// UnitPriceConfig is the price of the resources requested in an environment, used to estimate the cost of the
+// version endpoints and prediction jobs. The prices are expressed in a single currency across environments.
+type UnitPriceConfig struct {
+	// Price of one CPU core requested for an hour
+	CpuHour float64 `yaml:"cpu_hour"`
+	// Price of one GB (2^30 bytes) of memory requested for an hour
+	MemoryGbHour float64 `yaml:"memory_gb_hour"`
+}
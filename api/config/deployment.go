@@ -31,19 +31,48 @@ type DeploymentConfig struct {
 	// Maximum number of replica of inference service
 	MaxReplica int
 
-	// CPU limit of inference service
-	CpuLimit resource.Quantity //deprecated
+	// Default CPU limit of inference service
+	CpuLimit resource.Quantity
 	// Max CPU of machine
 	MaxCpu resource.Quantity
 	// Max Memory of machine
 	MaxMemory resource.Quantity
-	// Memory limit of inference service
+	// Default memory limit of inference service
 	MemoryLimit resource.Quantity
 	// CPU request of inference service
 	CpuRequest resource.Quantity
 	// Memory request of inference service
 	MemoryRequest resource.Quantity
 
+	// Maximum ratio of CPU limit to CPU request, 0 means unbounded
+	MaxCpuLimitRatio float64
+	// Maximum ratio of memory limit to memory request, 0 means unbounded
+	MaxMemoryLimitRatio float64
+
 	// Percentage of knative's queue proxy resource request from the inference service resource request
 	QueueResourcePercentage string
+
+	// Backend deploying the version endpoints
+	DeploymentBackend string
+	// Version of the KFServing InferenceService API used to deploy inference service
+	KFServingAPIVersion string
+
+	// GPU types that can be requested by inference service
+	GPUs []GPUConfig
+
+	// Bounds of the autoscaling policy of inference service
+	Autoscaling AutoscalingConfig
+
+	// Node pools that can be requested by inference service
+	Scheduling SchedulingConfig
+}
+
+// GPU returns the configuration of the GPU type with the given name.
+func (c *DeploymentConfig) GPU(name string) (GPUConfig, bool) {
+	for _, gpu := range c.GPUs {
+		if gpu.Name == name {
+			return gpu, true
+		}
+	}
+	return GPUConfig{}, false
 }
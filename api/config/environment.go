@@ -20,9 +20,27 @@ import (
 	"time"
 
 	"gopkg.in/yaml.v2"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
+const defaultGPUResourceType = "nvidia.com/gpu"
+
+// Backends deploying the version endpoints of an environment
+const (
+	// DeploymentBackendKFServing deploys KFServing inference services
+	DeploymentBackendKFServing = "kfserving"
+	// DeploymentBackendKubernetes deploys a Deployment, a Service and a HorizontalPodAutoscaler,
+	// for clusters without Knative and KFServing
+	DeploymentBackendKubernetes = "kubernetes"
+)
+
+// KFServing API versions of the inference services deployed to an environment
+const (
+	KFServingV1alpha2 = "v1alpha2"
+	KFServingV1beta1  = "v1beta1"
+)
+
 type EnvironmentConfig struct {
 	Name                    string        `yaml:"name"`
 	Cluster                 string        `yaml:"cluster"`
@@ -41,11 +59,82 @@ type EnvironmentConfig struct {
 	MemoryLimit             string        `yaml:"memory_limit"`
 	QueueResourcePercentage string        `yaml:"queue_resource_percentage"`
 
+	// DeploymentBackend deploys the version endpoints of the environment (kfserving or kubernetes), defaults to kfserving
+	DeploymentBackend string `yaml:"deployment_backend"`
+	// KFServingAPIVersion is the version of the KFServing InferenceService API served by the cluster
+	// (v1alpha2 or v1beta1), defaults to v1alpha2
+	KFServingAPIVersion string `yaml:"kfserving_api_version"`
+
+	// Maximum ratio of the limit to the request of the inference services, 0 means unbounded
+	MaxCpuLimitRatio    float64 `yaml:"max_cpu_limit_ratio"`
+	MaxMemoryLimitRatio float64 `yaml:"max_memory_limit_ratio"`
+
+	// GPUs lists the GPU types that can be requested by the inference services
+	GPUs []GPUConfig `yaml:"gpus"`
+
+	// Scheduling lists the node pools the version endpoints and prediction jobs of the environment are scheduled to.
+	// The version endpoints of KFServing v1alpha2 environments can't be scheduled to node pools.
+	Scheduling SchedulingConfig `yaml:"scheduling"`
+
+	// Autoscaling bounds the autoscaling policies of the version endpoints deployed to the environment
+	Autoscaling AutoscalingConfig `yaml:"autoscaling"`
+
+	// DefaultQuota limits the resources used by each project in the environment, unless an administrator sets
+	// the quota of the project
+	DefaultQuota QuotaConfig `yaml:"default_quota"`
+
+	// UnitPrice is used to estimate the cost of the resources requested by the projects in the environment
+	UnitPrice UnitPriceConfig `yaml:"unit_price"`
+
+	// EndpointLifetime bounds the ttl and the idle timeout of the version endpoints deployed to the environment
+	EndpointLifetime EndpointLifetimeConfig `yaml:"endpoint_lifetime"`
+
+	// ReapplyDriftedEndpoints re-applies the deployed spec of version endpoints whose inference service
+	// was deleted or became not ready outside of Merlin
+	ReapplyDriftedEndpoints bool `yaml:"reapply_drifted_endpoints"`
+
 	IsPredictionJobEnabled bool                 `yaml:"is_prediction_job_enabled"`
 	IsDefaultPredictionJob bool                 `yaml:"is_default_prediction_job"`
 	PredictionJobConfig    *PredictionJobConfig `yaml:"prediction_job_config"`
 }
 
+type AutoscalingConfig struct {
+	// Whether version endpoints are allowed to scale down to zero replica
+	AllowScaleToZero bool `yaml:"allow_scale_to_zero"`
+	// Bounds of the target value keyed by autoscaling metric (concurrency, rps, cpu).
+	// Only the listed metrics are allowed, all metrics are allowed if none is listed.
+	Targets map[string]AutoscalingTargetBounds `yaml:"targets"`
+}
+
+// AutoscalingTargetBounds is the allowed range of an autoscaling target value, a zero Max means unbounded.
+type AutoscalingTargetBounds struct {
+	Min float64 `yaml:"min"`
+	Max float64 `yaml:"max"`
+}
+
+type GPUConfig struct {
+	// Name of the GPU type, e.g. nvidia-tesla-t4
+	Name string `yaml:"name"`
+	// Kubernetes resource name of the GPU, defaults to nvidia.com/gpu
+	ResourceType string `yaml:"resource_type"`
+	// Maximum number of GPU per replica
+	MaxCount int64 `yaml:"max_count"`
+	// Node selector and tolerations of the node pool providing the GPU. KFServing v1alpha2 inference services
+	// only support the cloud.google.com/gke-accelerator node selector, GKE tolerates the GPU taint itself, so the
+	// GPU types with other constraints are rejected on them. Both are set on the predictor of KFServing v1beta1
+	// inference services.
+	NodeSelector map[string]string `yaml:"node_selector"`
+	Tolerations  []v1.Toleration   `yaml:"tolerations"`
+}
+
+// ResourceName returns the Kubernetes resource name of the GPU.
+func (c GPUConfig) ResourceName() v1.ResourceName {
+	if c.ResourceType == "" {
+		return defaultGPUResourceType
+	}
+	return v1.ResourceName(c.ResourceType)
+}
+
 type PredictionJobConfig struct {
 	ExecutorReplica       int32  `yaml:"executor_replica"`
 	DriverCpuRequest      string `yaml:"driver_cpu_request"`
@@ -75,10 +164,17 @@ func ParseDeploymentConfig(cfg EnvironmentConfig) DeploymentConfig {
 		MaxReplica:              cfg.MaxReplica,
 		CpuRequest:              resource.MustParse(cfg.CpuRequest),
 		MemoryRequest:           resource.MustParse(cfg.MemoryRequest),
-		CpuLimit:                resource.MustParse(cfg.CpuLimit), //Deprecated
+		CpuLimit:                resource.MustParse(cfg.CpuLimit),
 		MaxCpu:                  resource.MustParse(cfg.MaxCpu),
 		MaxMemory:               resource.MustParse(cfg.MaxMemory),
 		MemoryLimit:             resource.MustParse(cfg.MemoryLimit),
 		QueueResourcePercentage: cfg.QueueResourcePercentage,
+		DeploymentBackend:       cfg.DeploymentBackend,
+		KFServingAPIVersion:     cfg.KFServingAPIVersion,
+		MaxCpuLimitRatio:        cfg.MaxCpuLimitRatio,
+		MaxMemoryLimitRatio:     cfg.MaxMemoryLimitRatio,
+		GPUs:                    cfg.GPUs,
+		Autoscaling:             cfg.Autoscaling,
+		Scheduling:              cfg.Scheduling,
 	}
 }
@@ -0,0 +1,30 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// EndpointLifetimeConfig bounds how long the version endpoints of an environment stay deployed, e.g. to clean up
+// the forgotten endpoints of development environments. Durations are formatted like "72h", an unset duration
+// is unlimited.
+type EndpointLifetimeConfig struct {
+	// Time after its deployment at which a version endpoint is undeployed, unless it sets its own ttl
+	DefaultTTL string `yaml:"default_ttl"`
+	// Maximum ttl of the version endpoints, it applies to the version endpoints without a ttl if there is no default
+	MaxTTL string `yaml:"max_ttl"`
+	// Time without any request after which a version endpoint is undeployed, unless it sets its own idle timeout
+	DefaultIdleTimeout string `yaml:"default_idle_timeout"`
+	// Maximum idle timeout of the version endpoints, it applies to the version endpoints without an idle timeout
+	// if there is no default
+	MaxIdleTimeout string `yaml:"max_idle_timeout"`
+}
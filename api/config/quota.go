@@ -0,0 +1,35 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// MaxDeployedVersion is the default maximum number of pending, running and serving version endpoints of a model in
+// an environment
+const MaxDeployedVersion = 2
+
+// QuotaConfig limits the resources used by the version endpoints and prediction jobs of a project in an environment.
+// An unset limit is unlimited.
+type QuotaConfig struct {
+	// Maximum number of pending, running and serving version endpoints
+	MaxEndpoints *int `yaml:"max_endpoints"`
+	// Maximum number of pending, running and serving version endpoints of each model, MaxDeployedVersion if unset
+	MaxEndpointsPerModel *int `yaml:"max_endpoints_per_model"`
+	// Maximum CPU and memory requested by the version endpoints when they are scaled to their max replica
+	MaxCpu    string `yaml:"max_cpu"`
+	MaxMemory string `yaml:"max_memory"`
+	// Maximum number of replicas of the version endpoints when they are scaled to their max replica
+	MaxReplicas *int `yaml:"max_replicas"`
+	// Maximum number of pending and running prediction jobs
+	MaxPredictionJobs *int `yaml:"max_prediction_jobs"`
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	k8syaml "github.com/ghodss/yaml"
+	"gopkg.in/yaml.v2"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Weight of the pod anti-affinity terms spreading the replicas across the topology domains
+const spreadTopologyWeight = 100
+
+type SchedulingConfig struct {
+	// NodePools lists the node pools the version endpoints and prediction jobs can request
+	NodePools []NodePoolConfig `yaml:"node_pools"`
+	// Node pool of the version endpoints not requesting one, none means no scheduling constraint
+	DefaultOnlineNodePool string `yaml:"default_online_node_pool"`
+	// Node pool of the prediction jobs not requesting one, none means no scheduling constraint
+	DefaultBatchNodePool string `yaml:"default_batch_node_pool"`
+}
+
+// NodePool returns the configuration of the requested node pool, or of the default one when none is requested.
+// It returns false if the node pool is not allowed in the environment.
+func (c SchedulingConfig) NodePool(name string, defaultName string) (NodePoolConfig, bool) {
+	if name == "" {
+		if defaultName == "" {
+			return NodePoolConfig{}, true
+		}
+		name = defaultName
+	}
+
+	for _, pool := range c.NodePools {
+		if pool.Name == name {
+			return pool, true
+		}
+	}
+	return NodePoolConfig{}, false
+}
+
+type NodePoolConfig struct {
+	// Name of the node pool requested by the version endpoints and prediction jobs
+	Name string `yaml:"name"`
+	// Node selector and tolerations of the nodes of the pool
+	NodeSelector map[string]string `yaml:"node_selector"`
+	Tolerations  []v1.Toleration   `yaml:"tolerations"`
+	// Affinity of the pods, in its Kubernetes representation
+	Affinity *v1.Affinity `yaml:"-"`
+	// Priority class of the pods
+	PriorityClassName string `yaml:"priority_class_name"`
+	// Node labels, e.g. topology.kubernetes.io/zone, whose domains the replicas are preferably spread across.
+	// They're set as topology spread constraints on the KFServing v1beta1 inference services, and as pod
+	// anti-affinity on the Kubernetes deployments and prediction jobs, whose APIs used by Merlin predate them.
+	SpreadTopologyKeys []string `yaml:"spread_topology_keys"`
+}
+
+// UnmarshalYAML parses the affinity with the JSON names of its fields, as in the Kubernetes manifests.
+func (c *NodePoolConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type nodePoolConfig NodePoolConfig
+	var raw struct {
+		nodePoolConfig `yaml:",inline"`
+		Affinity       interface{} `yaml:"affinity"`
+	}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+
+	*c = NodePoolConfig(raw.nodePoolConfig)
+	if raw.Affinity == nil {
+		return nil
+	}
+
+	affinity, err := yaml.Marshal(raw.Affinity)
+	if err != nil {
+		return err
+	}
+	return k8syaml.Unmarshal(affinity, &c.Affinity)
+}
+
+// PodAffinity returns the affinity of the node pool, with the anti-affinity spreading the pods having
+// the given labels across the topology domains, for the pods not supporting topology spread constraints.
+func (c NodePoolConfig) PodAffinity(podLabels map[string]string) *v1.Affinity {
+	if len(c.SpreadTopologyKeys) == 0 {
+		return c.Affinity.DeepCopy()
+	}
+
+	affinity := &v1.Affinity{}
+	if c.Affinity != nil {
+		affinity = c.Affinity.DeepCopy()
+	}
+	if affinity.PodAntiAffinity == nil {
+		affinity.PodAntiAffinity = &v1.PodAntiAffinity{}
+	}
+
+	for _, topologyKey := range c.SpreadTopologyKeys {
+		affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, v1.WeightedPodAffinityTerm{
+			Weight: spreadTopologyWeight,
+			PodAffinityTerm: v1.PodAffinityTerm{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: podLabels},
+				TopologyKey:   topologyKey,
+			},
+		})
+	}
+	return affinity
+}
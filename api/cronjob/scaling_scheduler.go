@@ -0,0 +1,65 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronjob
+
+import (
+	"context"
+	"sync"
+
+	"github.com/robfig/cron"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/service"
+)
+
+// ScalingScheduler applies the scaling schedules of version endpoints every minute. The schedules keep the time they
+// were last applied, so the ones which fired while merlin was down are applied on the first run.
+type ScalingScheduler struct {
+	c               *cron.Cron
+	scheduleService service.ScalingScheduleService
+	clock           clock.Clock
+
+	mu sync.Mutex
+}
+
+func NewScalingScheduler(scheduleService service.ScalingScheduleService, clock clock.Clock) (*ScalingScheduler, error) {
+	c := cron.New()
+	s := &ScalingScheduler{
+		c:               c,
+		scheduleService: scheduleService,
+		clock:           clock,
+	}
+
+	// cron expressions of the schedules have a precision of one minute
+	err := c.AddFunc("0 * * * * *", s.applySchedules)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *ScalingScheduler) Start() {
+	s.c.Start()
+}
+
+func (s *ScalingScheduler) applySchedules() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.scheduleService.ApplySchedules(context.Background(), s.clock.Now()); err != nil {
+		log.Errorf("unable to apply scaling schedules: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronjob
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/gojek/merlin/service/mocks"
+)
+
+func TestScalingScheduler_applySchedules(t *testing.T) {
+	start := time.Date(2020, 10, 5, 0, 0, 30, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(start)
+
+	scheduleService := &mocks.ScalingScheduleService{}
+	scheduler, err := NewScalingScheduler(scheduleService, fakeClock)
+	assert.NoError(t, err)
+
+	// the schedules are applied up to the current time
+	first := start.Add(30 * time.Second)
+	fakeClock.SetTime(first)
+	scheduleService.On("ApplySchedules", mock.Anything, first).Return(nil).Once()
+	scheduler.applySchedules()
+
+	// the schedules are applied again after a failure
+	second := first.Add(time.Minute)
+	fakeClock.SetTime(second)
+	scheduleService.On("ApplySchedules", mock.Anything, second).Return(errors.New("connection refused")).Once()
+	scheduler.applySchedules()
+
+	third := second.Add(time.Minute)
+	fakeClock.SetTime(third)
+	scheduleService.On("ApplySchedules", mock.Anything, third).Return(nil).Once()
+	scheduler.applySchedules()
+
+	scheduleService.AssertExpectations(t)
+}
@@ -17,6 +17,7 @@ package cronjob
 import (
 	"context"
 	"math"
+	"time"
 
 	"github.com/gojek/merlin/log"
 	"github.com/gojek/merlin/models"
@@ -58,22 +59,37 @@ var firstSuccessfulPredictionJobGauge = prometheus.NewGaugeVec(
 	[]string{"stats_type"},
 )
 
+var estimatedDailyCostGauge = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name:      "estimated_daily_cost",
+		Namespace: "merlin_api",
+		Help:      "Estimated cost of the resources requested by version endpoints and prediction jobs over the last 24 hours",
+	},
+	[]string{"project", "team", "model", "environment"},
+)
+
+// costReportPeriod is the time range of the estimated costs exported by the tracker
+const costReportPeriod = 24 * time.Hour
+
 type Tracker struct {
 	c                    *cron.Cron
 	projectService       service.ProjectsService
 	modelService         service.ModelsService
 	predictionJobStorage storage.PredictionJobStorage
 	deploymentStorage    storage.DeploymentStorage
+	costService          service.CostService
 }
 
 func NewTracker(projectService service.ProjectsService,
 	modelService service.ModelsService,
 	predictionJobStorage storage.PredictionJobStorage,
-	deploymentStorage storage.DeploymentStorage) (*Tracker, error) {
+	deploymentStorage storage.DeploymentStorage,
+	costService service.CostService) (*Tracker, error) {
 	prometheus.MustRegister(projectCount)
 	prometheus.MustRegister(modelCount)
 	prometheus.MustRegister(firstSuccessfulDeploymentGauge)
 	prometheus.MustRegister(firstSuccessfulPredictionJobGauge)
+	prometheus.MustRegister(estimatedDailyCostGauge)
 
 	c := cron.New()
 	t := &Tracker{
@@ -82,6 +98,7 @@ func NewTracker(projectService service.ProjectsService,
 		modelService:         modelService,
 		predictionJobStorage: predictionJobStorage,
 		deploymentStorage:    deploymentStorage,
+		costService:          costService,
 	}
 
 	err := c.AddFunc("@hourly", t.trackMetrics)
@@ -102,6 +119,7 @@ func (t *Tracker) trackMetrics() {
 	t.recordFirstSuccessfulDeploymentStats()
 	t.recordProjectAndModelCount()
 	t.recordFirstSuccessfulBatchJobStats()
+	t.recordEstimatedCost()
 }
 
 func (t *Tracker) recordProjectAndModelCount() {
@@ -154,6 +172,25 @@ func (t *Tracker) recordFirstSuccessfulBatchJobStats() {
 	firstSuccessfulPredictionJobGauge.WithLabelValues("mean").Set(float64(mean))
 }
 
+func (t *Tracker) recordEstimatedCost() {
+	end := time.Now()
+	report, err := t.costService.GetCostReport(context.Background(), &service.CostReportQuery{
+		GroupBy: []string{models.CostGroupByProject, models.CostGroupByTeam, models.CostGroupByModel, models.CostGroupByEnvironment},
+		Start:   end.Add(-costReportPeriod),
+		End:     end,
+	})
+	if err != nil {
+		log.Errorf("error estimating costs: %v", err)
+		return
+	}
+
+	// remove the models which haven't requested any resources since the previous estimation
+	estimatedDailyCostGauge.Reset()
+	for _, item := range report.Items {
+		estimatedDailyCostGauge.WithLabelValues(item.ProjectName, item.Team, item.ModelName, item.EnvironmentName).Set(item.Cost)
+	}
+}
+
 func getStats(successVersionMap map[models.Id]models.Id) (min int, max int, mean int) {
 	if len(successVersionMap) == 0 {
 		return 0, 0, 0
@@ -44,6 +44,8 @@ type ImageBuilder interface {
 	BuildImage(project mlp.Project, model *models.Model, version *models.Version) (string, error)
 	// GetContainers return reference to container used to build the docker image of a model version
 	GetContainers(project mlp.Project, model *models.Model, version *models.Version) ([]*models.Container, error)
+	// ImageRef returns the docker image ref built by BuildImage for the given model version, without building it
+	ImageRef(project mlp.Project, model *models.Model, version *models.Version) string
 }
 
 type nameGenerator interface {
@@ -57,6 +59,8 @@ type imageBuilder struct {
 	kubeClient    kubernetes.Interface
 	config        Config
 	nameGenerator nameGenerator
+	// path of the pyfunc artifact to be built within the model version's artifact
+	artifactPath string
 }
 
 const (
@@ -65,6 +69,9 @@ const (
 	kanikoSecretName   = "kaniko-secret"
 	tickDurationSecond = 5
 
+	modelArtifactPath       = "model"
+	transformerArtifactPath = "transformer"
+
 	labelTeamName         = "gojek.com/team"
 	labelStreamName       = "gojek.com/stream"
 	labelAppName          = "gojek.com/app"
@@ -85,11 +92,12 @@ var (
 	}
 )
 
-func newImageBuilder(kubeClient kubernetes.Interface, config Config, nameGenerator nameGenerator) ImageBuilder {
+func newImageBuilder(kubeClient kubernetes.Interface, config Config, nameGenerator nameGenerator, artifactPath string) ImageBuilder {
 	return &imageBuilder{
 		kubeClient:    kubeClient,
 		config:        config,
 		nameGenerator: nameGenerator,
+		artifactPath:  artifactPath,
 	}
 }
 
@@ -104,7 +112,7 @@ func (c *imageBuilder) BuildImage(project mlp.Project, model *models.Model, vers
 		return "", ErrUnableToGetImageRef
 	}
 
-	imageRef := c.imageRef(project, model, version)
+	imageRef := c.ImageRef(project, model, version)
 	if imageExists {
 		log.Infof("Image %s already exists. Skipping build.", imageRef)
 		return imageRef, nil
@@ -178,7 +186,7 @@ func (c *imageBuilder) GetContainers(project mlp.Project, model *models.Model, v
 	return containers, nil
 }
 
-// imageRef represents a versioned (i.e., tagged) image. The tag is
+// ImageRef represents a versioned (i.e., tagged) image. The tag is
 // allowed to be empty, though it is in general undefined what that
 // means. As such, `Ref` also includes all `Name` values.
 //
@@ -188,7 +196,7 @@ func (c *imageBuilder) GetContainers(project mlp.Project, model *models.Model, v
 //  * docker.io/fluxcd/flux:1.1.0
 //  * gojek/merlin-api:1.0.0
 //  * localhost:5000/arbitrary/path/to/repo:revision-sha1
-func (c *imageBuilder) imageRef(project mlp.Project, model *models.Model, version *models.Version) string {
+func (c *imageBuilder) ImageRef(project mlp.Project, model *models.Model, version *models.Version) string {
 	return fmt.Sprintf("%s:%s", c.nameGenerator.generateDockerImageName(project, model), version.Id)
 }
 
@@ -261,7 +269,7 @@ func (c *imageBuilder) waitJobCompleted(job *batchv1.Job) error {
 
 func (c *imageBuilder) createKanikoJobSpec(project mlp.Project, model *models.Model, version *models.Version) *batchv1.Job {
 	kanikoPodName := c.nameGenerator.generateBuilderJobName(project, model, version)
-	imageRef := c.imageRef(project, model, version)
+	imageRef := c.ImageRef(project, model, version)
 
 	var labels = map[string]string{
 		labelTeamName:         project.Team,
@@ -278,7 +286,7 @@ func (c *imageBuilder) createKanikoJobSpec(project mlp.Project, model *models.Mo
 	kanikoArgs := []string{
 		fmt.Sprintf("--dockerfile=%s", c.config.DockerfilePath),
 		fmt.Sprintf("--context=%s", c.config.BuildContextUrl),
-		fmt.Sprintf("--build-arg=MODEL_URL=%s/model", version.ArtifactUri),
+		fmt.Sprintf("--build-arg=MODEL_URL=%s/%s", version.ArtifactUri, c.artifactPath),
 		fmt.Sprintf("--build-arg=BASE_IMAGE=%s", c.config.BaseImage),
 		fmt.Sprintf("--destination=%s", imageRef),
 		"--cache=true",
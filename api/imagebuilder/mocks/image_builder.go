@@ -57,3 +57,17 @@ func (_m *ImageBuilder) GetContainers(project mlp.Project, model *models.Model,
 
 	return r0, r1
 }
+
+// ImageRef provides a mock function with given fields: project, model, version
+func (_m *ImageBuilder) ImageRef(project mlp.Project, model *models.Model, version *models.Version) string {
+	ret := _m.Called(project, model, version)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(mlp.Project, *models.Model, *models.Version) string); ok {
+		r0 = rf(project, model, version)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
@@ -25,7 +25,7 @@ import (
 
 // NewModelServiceImageBuilder create an ImageBuilder that will be used to build docker image of model service
 func NewModelServiceImageBuilder(kubeClient kubernetes.Interface, config Config) ImageBuilder {
-	return newImageBuilder(kubeClient, config, &modelServiceNameGenerator{dockerRegistry: config.DockerRegistry})
+	return newImageBuilder(kubeClient, config, &modelServiceNameGenerator{dockerRegistry: config.DockerRegistry}, modelArtifactPath)
 }
 
 // modelServiceNameGenerator is name generator to generate docker image of model service
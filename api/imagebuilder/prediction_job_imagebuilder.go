@@ -25,7 +25,7 @@ import (
 
 // NewPredictionJobImageBuilder create ImageBuilder for building docker image of prediction job (batch)
 func NewPredictionJobImageBuilder(kubeClient kubernetes.Interface, config Config) ImageBuilder {
-	return newImageBuilder(kubeClient, config, &predictionJobNameGenerator{dockerRegistry: config.DockerRegistry})
+	return newImageBuilder(kubeClient, config, &predictionJobNameGenerator{dockerRegistry: config.DockerRegistry}, modelArtifactPath)
 }
 
 // predictionJobNameGenerator is name generator that will be used for building docker image of prediction job
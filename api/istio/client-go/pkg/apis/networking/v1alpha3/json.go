@@ -0,0 +1,76 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The specs are protobuf messages, which have to be marshalled with jsonpb for Istio to accept
+// well-known types such as durations ("5s") and wrappers (plain numbers).
+
+type resourceJSON struct {
+	v1.TypeMeta   `json:",inline"`
+	v1.ObjectMeta `json:"metadata,omitempty"`
+	Spec          json.RawMessage `json:"spec,omitempty"`
+}
+
+func marshalResource(typeMeta v1.TypeMeta, objectMeta v1.ObjectMeta, spec proto.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := (&jsonpb.Marshaler{}).Marshal(&buf, spec); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(resourceJSON{TypeMeta: typeMeta, ObjectMeta: objectMeta, Spec: buf.Bytes()})
+}
+
+func unmarshalResource(data []byte, typeMeta *v1.TypeMeta, objectMeta *v1.ObjectMeta, spec proto.Message) error {
+	var resource resourceJSON
+	if err := json.Unmarshal(data, &resource); err != nil {
+		return err
+	}
+
+	*typeMeta = resource.TypeMeta
+	*objectMeta = resource.ObjectMeta
+	if len(resource.Spec) == 0 {
+		return nil
+	}
+	return (&jsonpb.Unmarshaler{AllowUnknownFields: true}).Unmarshal(bytes.NewReader(resource.Spec), spec)
+}
+
+// MarshalJSON marshals the VirtualService with its spec in the jsonpb format.
+func (in VirtualService) MarshalJSON() ([]byte, error) {
+	return marshalResource(in.TypeMeta, in.ObjectMeta, &in.Spec)
+}
+
+// UnmarshalJSON unmarshals the VirtualService with its spec in the jsonpb format.
+func (in *VirtualService) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, &in.TypeMeta, &in.ObjectMeta, &in.Spec)
+}
+
+// MarshalJSON marshals the DestinationRule with its spec in the jsonpb format.
+func (in DestinationRule) MarshalJSON() ([]byte, error) {
+	return marshalResource(in.TypeMeta, in.ObjectMeta, &in.Spec)
+}
+
+// UnmarshalJSON unmarshals the DestinationRule with its spec in the jsonpb format.
+func (in *DestinationRule) UnmarshalJSON(data []byte) error {
+	return unmarshalResource(data, &in.TypeMeta, &in.ObjectMeta, &in.Spec)
+}
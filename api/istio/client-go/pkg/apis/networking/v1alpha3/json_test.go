@@ -0,0 +1,83 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networking "istio.io/api/networking/v1alpha3"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVirtualService_JSON(t *testing.T) {
+	vs := &VirtualService{
+		ObjectMeta: v1.ObjectMeta{Name: "model-1", Namespace: "project-1"},
+		Spec: networking.VirtualService{
+			Hosts: []string{"model-1.project-1.mlp.io"},
+			Http: []*networking.HTTPRoute{
+				{
+					Match: []*networking.HTTPMatchRequest{
+						{Uri: &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: "/v1/predict"}}},
+					},
+					Timeout:       &types.Duration{Seconds: 2},
+					Retries:       &networking.HTTPRetry{Attempts: 3, PerTryTimeout: &types.Duration{Nanos: 500000000}, RetryOn: "5xx"},
+					MirrorPercent: &types.UInt32Value{Value: 30},
+				},
+			},
+		},
+	}
+
+	data, err := json.Marshal(vs)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"metadata": {"name": "model-1", "namespace": "project-1", "creationTimestamp": null},
+		"spec": {
+			"hosts": ["model-1.project-1.mlp.io"],
+			"http": [{
+				"match": [{"uri": {"prefix": "/v1/predict"}}],
+				"timeout": "2s",
+				"retries": {"attempts": 3, "perTryTimeout": "0.500s", "retryOn": "5xx"},
+				"mirrorPercent": 30
+			}]
+		}
+	}`, string(data))
+
+	got := &VirtualService{}
+	require.NoError(t, json.Unmarshal(data, got))
+	assert.Equal(t, vs, got)
+}
+
+func TestDestinationRule_JSON(t *testing.T) {
+	dr := &DestinationRule{
+		ObjectMeta: v1.ObjectMeta{Name: "model-1", Namespace: "project-1"},
+		Spec: networking.DestinationRule{
+			Host: "istio-ingressgateway.istio-system.svc.cluster.local",
+			TrafficPolicy: &networking.TrafficPolicy{
+				OutlierDetection: &networking.OutlierDetection{ConsecutiveErrors: 5, Interval: &types.Duration{Seconds: 10}},
+			},
+		},
+	}
+
+	data, err := json.Marshal(dr)
+	require.NoError(t, err)
+
+	got := &DestinationRule{}
+	require.NoError(t, json.Unmarshal(data, got))
+	assert.Equal(t, dr, got)
+}
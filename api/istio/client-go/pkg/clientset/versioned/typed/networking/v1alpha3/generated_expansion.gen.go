@@ -2,4 +2,6 @@
 
 package v1alpha3
 
+type DestinationRuleExpansion interface{}
+
 type VirtualServiceExpansion interface{}
@@ -11,6 +11,7 @@ import (
 
 type NetworkingV1alpha3Interface interface {
 	RESTClient() rest.Interface
+	DestinationRulesGetter
 	VirtualServicesGetter
 }
 
@@ -19,6 +20,10 @@ type NetworkingV1alpha3Client struct {
 	restClient rest.Interface
 }
 
+func (c *NetworkingV1alpha3Client) DestinationRules(namespace string) DestinationRuleInterface {
+	return newDestinationRules(c, namespace)
+}
+
 func (c *NetworkingV1alpha3Client) VirtualServices(namespace string) VirtualServiceInterface {
 	return newVirtualServices(c, namespace)
 }
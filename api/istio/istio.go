@@ -42,6 +42,12 @@ type Client interface {
 	CreateVirtualService(ctx context.Context, namespace string, vs *v1alpha3.VirtualService) (*v1alpha3.VirtualService, error)
 	PatchVirtualService(ctx context.Context, namespace string, vs *v1alpha3.VirtualService) (*v1alpha3.VirtualService, error)
 	DeleteVirtualService(ctx context.Context, namespace, name string) error
+
+	GetDestinationRule(ctx context.Context, namespace, name string) (*v1alpha3.DestinationRule, error)
+	ListDestinationRules(ctx context.Context, namespace, labelSelector string) (*v1alpha3.DestinationRuleList, error)
+	CreateDestinationRule(ctx context.Context, namespace string, dr *v1alpha3.DestinationRule) (*v1alpha3.DestinationRule, error)
+	PatchDestinationRule(ctx context.Context, namespace string, dr *v1alpha3.DestinationRule) (*v1alpha3.DestinationRule, error)
+	DeleteDestinationRule(ctx context.Context, namespace, name string) error
 }
 
 // NewClient returns an initialized Istio's client.
@@ -91,3 +97,28 @@ func (c *client) PatchVirtualService(ctx context.Context, namespace string, vs *
 func (c *client) DeleteVirtualService(ctx context.Context, namespace, name string) error {
 	return c.networking.VirtualServices(namespace).Delete(name, &v1.DeleteOptions{})
 }
+
+func (c *client) GetDestinationRule(ctx context.Context, namespace, name string) (*v1alpha3.DestinationRule, error) {
+	return c.networking.DestinationRules(namespace).Get(name, v1.GetOptions{})
+}
+
+func (c *client) ListDestinationRules(ctx context.Context, namespace, labelSelector string) (*v1alpha3.DestinationRuleList, error) {
+	return c.networking.DestinationRules(namespace).List(v1.ListOptions{LabelSelector: labelSelector})
+}
+
+func (c *client) CreateDestinationRule(ctx context.Context, namespace string, dr *v1alpha3.DestinationRule) (*v1alpha3.DestinationRule, error) {
+	return c.networking.DestinationRules(namespace).Create(dr)
+}
+
+func (c *client) PatchDestinationRule(ctx context.Context, namespace string, dr *v1alpha3.DestinationRule) (*v1alpha3.DestinationRule, error) {
+	drJSON, err := json.Marshal(dr)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.networking.DestinationRules(namespace).Patch(dr.ObjectMeta.Name, types.MergePatchType, drJSON)
+}
+
+func (c *client) DeleteDestinationRule(ctx context.Context, namespace, name string) error {
+	return c.networking.DestinationRules(namespace).Delete(name, &v1.DeleteOptions{})
+}
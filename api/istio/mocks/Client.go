@@ -15,6 +15,29 @@ type Client struct {
 	mock.Mock
 }
 
+// CreateDestinationRule provides a mock function with given fields: ctx, namespace, dr
+func (_m *Client) CreateDestinationRule(ctx context.Context, namespace string, dr *v1alpha3.DestinationRule) (*v1alpha3.DestinationRule, error) {
+	ret := _m.Called(ctx, namespace, dr)
+
+	var r0 *v1alpha3.DestinationRule
+	if rf, ok := ret.Get(0).(func(context.Context, string, *v1alpha3.DestinationRule) *v1alpha3.DestinationRule); ok {
+		r0 = rf(ctx, namespace, dr)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1alpha3.DestinationRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *v1alpha3.DestinationRule) error); ok {
+		r1 = rf(ctx, namespace, dr)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateVirtualService provides a mock function with given fields: ctx, namespace, vs
 func (_m *Client) CreateVirtualService(ctx context.Context, namespace string, vs *v1alpha3.VirtualService) (*v1alpha3.VirtualService, error) {
 	ret := _m.Called(ctx, namespace, vs)
@@ -38,6 +61,20 @@ func (_m *Client) CreateVirtualService(ctx context.Context, namespace string, vs
 	return r0, r1
 }
 
+// DeleteDestinationRule provides a mock function with given fields: ctx, namespace, name
+func (_m *Client) DeleteDestinationRule(ctx context.Context, namespace string, name string) error {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteVirtualService provides a mock function with given fields: ctx, namespace, name
 func (_m *Client) DeleteVirtualService(ctx context.Context, namespace string, name string) error {
 	ret := _m.Called(ctx, namespace, name)
@@ -52,6 +89,75 @@ func (_m *Client) DeleteVirtualService(ctx context.Context, namespace string, na
 	return r0
 }
 
+// GetDestinationRule provides a mock function with given fields: ctx, namespace, name
+func (_m *Client) GetDestinationRule(ctx context.Context, namespace string, name string) (*v1alpha3.DestinationRule, error) {
+	ret := _m.Called(ctx, namespace, name)
+
+	var r0 *v1alpha3.DestinationRule
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1alpha3.DestinationRule); ok {
+		r0 = rf(ctx, namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1alpha3.DestinationRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDestinationRules provides a mock function with given fields: ctx, namespace, labelSelector
+func (_m *Client) ListDestinationRules(ctx context.Context, namespace string, labelSelector string) (*v1alpha3.DestinationRuleList, error) {
+	ret := _m.Called(ctx, namespace, labelSelector)
+
+	var r0 *v1alpha3.DestinationRuleList
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *v1alpha3.DestinationRuleList); ok {
+		r0 = rf(ctx, namespace, labelSelector)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1alpha3.DestinationRuleList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, labelSelector)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PatchDestinationRule provides a mock function with given fields: ctx, namespace, dr
+func (_m *Client) PatchDestinationRule(ctx context.Context, namespace string, dr *v1alpha3.DestinationRule) (*v1alpha3.DestinationRule, error) {
+	ret := _m.Called(ctx, namespace, dr)
+
+	var r0 *v1alpha3.DestinationRule
+	if rf, ok := ret.Get(0).(func(context.Context, string, *v1alpha3.DestinationRule) *v1alpha3.DestinationRule); ok {
+		r0 = rf(ctx, namespace, dr)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1alpha3.DestinationRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *v1alpha3.DestinationRule) error); ok {
+		r1 = rf(ctx, namespace, dr)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PatchVirtualService provides a mock function with given fields: ctx, namespace, vs
 func (_m *Client) PatchVirtualService(ctx context.Context, namespace string, vs *v1alpha3.VirtualService) (*v1alpha3.VirtualService, error) {
 	ret := _m.Called(ctx, namespace, vs)
@@ -11,6 +11,22 @@ type NetworkingV1alpha3Interface struct {
 	mock.Mock
 }
 
+// DestinationRules provides a mock function with given fields: namespace
+func (_m *NetworkingV1alpha3Interface) DestinationRules(namespace string) v1alpha3.DestinationRuleInterface {
+	ret := _m.Called(namespace)
+
+	var r0 v1alpha3.DestinationRuleInterface
+	if rf, ok := ret.Get(0).(func(string) v1alpha3.DestinationRuleInterface); ok {
+		r0 = rf(namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(v1alpha3.DestinationRuleInterface)
+		}
+	}
+
+	return r0
+}
+
 // RESTClient provides a mock function with given fields:
 func (_m *NetworkingV1alpha3Interface) RESTClient() rest.Interface {
 	ret := _m.Called()
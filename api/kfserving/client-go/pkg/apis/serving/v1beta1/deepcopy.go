@@ -0,0 +1,302 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InferenceService) DeepCopyInto(out *InferenceService) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is a deepcopy function, copying the receiver, creating a new InferenceService.
+func (in *InferenceService) DeepCopy() *InferenceService {
+	if in == nil {
+		return nil
+	}
+	out := new(InferenceService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is a deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InferenceService) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InferenceServiceList) DeepCopyInto(out *InferenceServiceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]InferenceService, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is a deepcopy function, copying the receiver, creating a new InferenceServiceList.
+func (in *InferenceServiceList) DeepCopy() *InferenceServiceList {
+	if in == nil {
+		return nil
+	}
+	out := new(InferenceServiceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is a deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InferenceServiceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InferenceServiceSpec) DeepCopyInto(out *InferenceServiceSpec) {
+	*out = *in
+	in.Predictor.DeepCopyInto(&out.Predictor)
+	if in.Explainer != nil {
+		in, out := &in.Explainer, &out.Explainer
+		*out = new(ExplainerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Transformer != nil {
+		in, out := &in.Transformer, &out.Transformer
+		*out = new(TransformerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodSpec) DeepCopyInto(out *PodSpec) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologySpreadConstraint) DeepCopyInto(out *TopologySpreadConstraint) {
+	*out = *in
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentExtensionSpec) DeepCopyInto(out *ComponentExtensionSpec) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictorSpec) DeepCopyInto(out *PredictorSpec) {
+	*out = *in
+	if in.SKLearn != nil {
+		in, out := &in.SKLearn, &out.SKLearn
+		*out = new(SKLearnSpec)
+		(*in).PredictorExtensionSpec.DeepCopyInto(&(*out).PredictorExtensionSpec)
+	}
+	if in.XGBoost != nil {
+		in, out := &in.XGBoost, &out.XGBoost
+		*out = new(XGBoostSpec)
+		(*in).PredictorExtensionSpec.DeepCopyInto(&(*out).PredictorExtensionSpec)
+	}
+	if in.Tensorflow != nil {
+		in, out := &in.Tensorflow, &out.Tensorflow
+		*out = new(TFServingSpec)
+		(*in).PredictorExtensionSpec.DeepCopyInto(&(*out).PredictorExtensionSpec)
+	}
+	if in.PyTorch != nil {
+		in, out := &in.PyTorch, &out.PyTorch
+		*out = new(TorchServeSpec)
+		(*out).ModelClassName = (*in).ModelClassName
+		(*in).PredictorExtensionSpec.DeepCopyInto(&(*out).PredictorExtensionSpec)
+	}
+	if in.ONNX != nil {
+		in, out := &in.ONNX, &out.ONNX
+		*out = new(ONNXRuntimeSpec)
+		(*in).PredictorExtensionSpec.DeepCopyInto(&(*out).PredictorExtensionSpec)
+	}
+	in.PodSpec.DeepCopyInto(&out.PodSpec)
+	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PredictorExtensionSpec) DeepCopyInto(out *PredictorExtensionSpec) {
+	*out = *in
+	if in.StorageURI != nil {
+		in, out := &in.StorageURI, &out.StorageURI
+		*out = new(string)
+		**out = **in
+	}
+	if in.RuntimeVersion != nil {
+		in, out := &in.RuntimeVersion, &out.RuntimeVersion
+		*out = new(string)
+		**out = **in
+	}
+	in.Container.DeepCopyInto(&out.Container)
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformerSpec) DeepCopyInto(out *TransformerSpec) {
+	*out = *in
+	in.PodSpec.DeepCopyInto(&out.PodSpec)
+	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExplainerSpec) DeepCopyInto(out *ExplainerSpec) {
+	*out = *in
+	if in.Alibi != nil {
+		in, out := &in.Alibi, &out.Alibi
+		*out = new(AlibiExplainerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.PodSpec.DeepCopyInto(&out.PodSpec)
+	in.ComponentExtensionSpec.DeepCopyInto(&out.ComponentExtensionSpec)
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlibiExplainerSpec) DeepCopyInto(out *AlibiExplainerSpec) {
+	*out = *in
+	if in.RuntimeVersion != nil {
+		in, out := &in.RuntimeVersion, &out.RuntimeVersion
+		*out = new(string)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Container.DeepCopyInto(&out.Container)
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InferenceServiceStatus) DeepCopyInto(out *InferenceServiceStatus) {
+	*out = *in
+	in.Status.DeepCopyInto(&out.Status)
+	if in.Address != nil {
+		in, out := &in.Address, &out.Address
+		*out = new(duckv1.Addressable)
+		(*in).DeepCopyInto(*out)
+	}
+	out.URL = deepCopyURL(in.URL)
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make(map[ComponentType]ComponentStatusSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentStatusSpec) DeepCopyInto(out *ComponentStatusSpec) {
+	*out = *in
+	out.URL = deepCopyURL(in.URL)
+	if in.Address != nil {
+		in, out := &in.Address, &out.Address
+		*out = new(duckv1.Addressable)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is a deepcopy function, copying the receiver, creating a new ComponentStatusSpec.
+func (in *ComponentStatusSpec) DeepCopy() *ComponentStatusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentStatusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// deepCopyURL copies the URL, apis.URL doesn't have deepcopy functions in the knative version used by Merlin.
+func deepCopyURL(in *apis.URL) *apis.URL {
+	if in == nil {
+		return nil
+	}
+	out := *in
+	if in.User != nil {
+		user := *in.User
+		out.User = &user
+	}
+	return &out
+}
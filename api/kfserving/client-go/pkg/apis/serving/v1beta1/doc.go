@@ -0,0 +1,21 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v1beta1 contains the subset of the KFServing serving.kubeflow.org/v1beta1 API used by Merlin.
+//
+// The KFServing module used by Merlin only ships the v1alpha2 API, the types in this package follow
+// the v1beta1 schema of KFServing v0.5 so that they can be sent to and read from clusters serving it.
+// They're meant to be replaced by the ones of the KFServing module once it's bumped to a version shipping
+// the v1beta1 API, which also requires bumping the Kubernetes and Knative modules it depends on.
+package v1beta1
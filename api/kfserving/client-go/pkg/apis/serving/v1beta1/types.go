@@ -0,0 +1,230 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+// InferenceService is the Schema for the InferenceServices API
+type InferenceService struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   InferenceServiceSpec   `json:"spec,omitempty"`
+	Status InferenceServiceStatus `json:"status,omitempty"`
+}
+
+// InferenceServiceList contains a list of InferenceService
+type InferenceServiceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []InferenceService `json:"items"`
+}
+
+// InferenceServiceSpec is the top level type for this resource
+type InferenceServiceSpec struct {
+	// Predictor defines the model serving spec
+	Predictor PredictorSpec `json:"predictor"`
+	// Explainer defines the model explanation service spec,
+	// explainer service calls to predictor or transformer if it is specified.
+	Explainer *ExplainerSpec `json:"explainer,omitempty"`
+	// Transformer defines the pre/post processing before and after the predictor call,
+	// transformer service calls to predictor service.
+	Transformer *TransformerSpec `json:"transformer,omitempty"`
+}
+
+// PodSpec is the subset of the Kubernetes pod spec supported by the components of an inference service.
+type PodSpec struct {
+	Containers        []v1.Container    `json:"containers,omitempty"`
+	NodeSelector      map[string]string `json:"nodeSelector,omitempty"`
+	Affinity          *v1.Affinity      `json:"affinity,omitempty"`
+	Tolerations       []v1.Toleration   `json:"tolerations,omitempty"`
+	PriorityClassName string            `json:"priorityClassName,omitempty"`
+	Volumes           []v1.Volume       `json:"volumes,omitempty"`
+	// TopologySpreadConstraints describes how the pods spread across the topology domains
+	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
+// UnsatisfiableConstraintAction is the action of the scheduler when a pod doesn't satisfy a topology spread constraint.
+type UnsatisfiableConstraintAction string
+
+const (
+	// DoNotSchedule keeps the pod pending until the constraint can be satisfied
+	DoNotSchedule UnsatisfiableConstraintAction = "DoNotSchedule"
+	// ScheduleAnyway schedules the pod while prioritizing the nodes minimizing the skew
+	ScheduleAnyway UnsatisfiableConstraintAction = "ScheduleAnyway"
+)
+
+// TopologySpreadConstraint follows the Kubernetes core/v1 type of the same name, which the Kubernetes module
+// used by Merlin predates.
+type TopologySpreadConstraint struct {
+	// MaxSkew is the maximum difference between the number of matching pods of two topology domains
+	MaxSkew int32 `json:"maxSkew"`
+	// TopologyKey is the node label whose values are the topology domains
+	TopologyKey string `json:"topologyKey"`
+	// WhenUnsatisfiable is the action of the scheduler when the pod doesn't satisfy the constraint
+	WhenUnsatisfiable UnsatisfiableConstraintAction `json:"whenUnsatisfiable"`
+	// LabelSelector selects the pods counted in each topology domain
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+// ComponentExtensionSpec defines the deployment configuration for a given InferenceService component
+type ComponentExtensionSpec struct {
+	// Minimum number of replicas, defaults to 1 but can be set to 0 to enable scale-to-zero.
+	MinReplicas *int `json:"minReplicas,omitempty"`
+	// Maximum number of replicas for autoscaling.
+	MaxReplicas int `json:"maxReplicas,omitempty"`
+}
+
+// PredictorSpec defines the configuration for a predictor,
+// exactly one of the model frameworks or the containers of the pod spec must be specified.
+type PredictorSpec struct {
+	SKLearn    *SKLearnSpec     `json:"sklearn,omitempty"`
+	XGBoost    *XGBoostSpec     `json:"xgboost,omitempty"`
+	Tensorflow *TFServingSpec   `json:"tensorflow,omitempty"`
+	PyTorch    *TorchServeSpec  `json:"pytorch,omitempty"`
+	ONNX       *ONNXRuntimeSpec `json:"onnx,omitempty"`
+
+	PodSpec                `json:",inline"`
+	ComponentExtensionSpec `json:",inline"`
+}
+
+// PredictorExtensionSpec defines configuration shared across all predictor frameworks
+type PredictorExtensionSpec struct {
+	// This field points to the location of the trained model which is mounted onto the pod.
+	StorageURI *string `json:"storageUri,omitempty"`
+	// Runtime version of the predictor docker image
+	RuntimeVersion *string `json:"runtimeVersion,omitempty"`
+	// Container enables overrides for the predictor.
+	v1.Container `json:",inline"`
+}
+
+// SKLearnSpec defines arguments for configuring SKLearn model serving.
+type SKLearnSpec struct {
+	PredictorExtensionSpec `json:",inline"`
+}
+
+// XGBoostSpec defines arguments for configuring XGBoost model serving.
+type XGBoostSpec struct {
+	PredictorExtensionSpec `json:",inline"`
+}
+
+// TFServingSpec defines arguments for configuring Tensorflow model serving.
+type TFServingSpec struct {
+	PredictorExtensionSpec `json:",inline"`
+}
+
+// TorchServeSpec defines arguments for configuring PyTorch model serving.
+type TorchServeSpec struct {
+	// When this field is specified KFS chooses the KFServer implementation, otherwise KFS uses the TorchServe implementation
+	ModelClassName string `json:"modelClassName,omitempty"`
+
+	PredictorExtensionSpec `json:",inline"`
+}
+
+// ONNXRuntimeSpec defines arguments for configuring ONNX model serving.
+type ONNXRuntimeSpec struct {
+	PredictorExtensionSpec `json:",inline"`
+}
+
+// TransformerSpec defines transformer service for pre/post processing
+type TransformerSpec struct {
+	PodSpec                `json:",inline"`
+	ComponentExtensionSpec `json:",inline"`
+}
+
+// ExplainerSpec defines the container spec for a model explanation server,
+// either the Alibi explainer or the containers of the pod spec must be specified.
+type ExplainerSpec struct {
+	Alibi *AlibiExplainerSpec `json:"alibi,omitempty"`
+
+	PodSpec                `json:",inline"`
+	ComponentExtensionSpec `json:",inline"`
+}
+
+// AlibiExplainerType is the explanation method
+type AlibiExplainerType string
+
+// AlibiExplainerType Enum
+const (
+	AlibiAnchorsTabularExplainer AlibiExplainerType = "AnchorTabular"
+	AlibiAnchorsImageExplainer   AlibiExplainerType = "AnchorImages"
+	AlibiAnchorsTextExplainer    AlibiExplainerType = "AnchorText"
+)
+
+// AlibiExplainerSpec defines the arguments for configuring an Alibi Explanation Server
+type AlibiExplainerSpec struct {
+	// The type of Alibi explainer
+	Type AlibiExplainerType `json:"type"`
+	// The location of a trained explanation model
+	StorageURI string `json:"storageUri,omitempty"`
+	// Alibi docker image version, defaults to latest Alibi Version
+	RuntimeVersion *string `json:"runtimeVersion,omitempty"`
+	// Inline custom parameter settings for explainer
+	Config map[string]string `json:"config,omitempty"`
+	// Container enables overrides for the explainer.
+	v1.Container `json:",inline"`
+}
+
+// ComponentType contains the different types of components of the service
+type ComponentType string
+
+// ComponentType Enum
+const (
+	PredictorComponent   ComponentType = "predictor"
+	ExplainerComponent   ComponentType = "explainer"
+	TransformerComponent ComponentType = "transformer"
+)
+
+// ConditionType represents a Service condition value
+const (
+	// PredictorReady is set when the predictor has reported readiness.
+	PredictorReady apis.ConditionType = "PredictorReady"
+	// TransformerReady is set when the transformer has reported readiness.
+	TransformerReady apis.ConditionType = "TransformerReady"
+	// ExplainerReady is set when the explainer has reported readiness.
+	ExplainerReady apis.ConditionType = "ExplainerReady"
+	// IngressReady is set when the ingress of the inference service is configured.
+	IngressReady apis.ConditionType = "IngressReady"
+)
+
+// InferenceServiceStatus defines the observed state of InferenceService
+type InferenceServiceStatus struct {
+	// Conditions for the InferenceService, the Ready condition doesn't include the transformer and the explainer.
+	duckv1.Status `json:",inline"`
+	// Addressable endpoint for the InferenceService
+	Address *duckv1.Addressable `json:"address,omitempty"`
+	// URL holds the url that will distribute traffic over the provided traffic targets.
+	URL *apis.URL `json:"url,omitempty"`
+	// Statuses for the components of the InferenceService
+	Components map[ComponentType]ComponentStatusSpec `json:"components,omitempty"`
+}
+
+// ComponentStatusSpec describes the state of the component
+type ComponentStatusSpec struct {
+	// Latest revision name that is in ready state
+	LatestReadyRevision string `json:"latestReadyRevision,omitempty"`
+	// Latest revision name that is created
+	LatestCreatedRevision string `json:"latestCreatedRevision,omitempty"`
+	// URL holds the url that will distribute traffic over the provided traffic targets.
+	URL *apis.URL `json:"url,omitempty"`
+	// Addressable endpoint for the component
+	Address *duckv1.Addressable `json:"address,omitempty"`
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInferenceService_JSON(t *testing.T) {
+	storageURI := "gs://my-artifact/model"
+	minReplicas := 0
+	isvc := &InferenceService{
+		ObjectMeta: metav1.ObjectMeta{Name: "model-1", Namespace: "project"},
+		Spec: InferenceServiceSpec{
+			Predictor: PredictorSpec{
+				SKLearn: &SKLearnSpec{
+					PredictorExtensionSpec: PredictorExtensionSpec{StorageURI: &storageURI},
+				},
+				PodSpec: PodSpec{
+					NodeSelector: map[string]string{"cloud.google.com/gke-accelerator": "nvidia-tesla-t4"},
+				},
+				ComponentExtensionSpec: ComponentExtensionSpec{MinReplicas: &minReplicas, MaxReplicas: 2},
+			},
+			Transformer: &TransformerSpec{
+				PodSpec: PodSpec{
+					Containers: []v1.Container{{Name: "kfserving-container", Image: "gojek/transformer:1"}},
+				},
+			},
+		},
+	}
+
+	data, err := json.Marshal(isvc)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"metadata": {"name": "model-1", "namespace": "project", "creationTimestamp": null},
+		"spec": {
+			"predictor": {
+				"sklearn": {"name": "", "storageUri": "gs://my-artifact/model", "resources": {}},
+				"nodeSelector": {"cloud.google.com/gke-accelerator": "nvidia-tesla-t4"},
+				"minReplicas": 0,
+				"maxReplicas": 2
+			},
+			"transformer": {
+				"containers": [{"name": "kfserving-container", "image": "gojek/transformer:1", "resources": {}}]
+			}
+		},
+		"status": {}
+	}`, string(data))
+
+	var got InferenceService
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, isvc, &got)
+}
+
+func TestInferenceService_DeepCopy(t *testing.T) {
+	minReplicas := 1
+	isvc := &InferenceService{
+		Spec: InferenceServiceSpec{
+			Predictor: PredictorSpec{
+				ComponentExtensionSpec: ComponentExtensionSpec{MinReplicas: &minReplicas},
+			},
+			Explainer: &ExplainerSpec{
+				Alibi: &AlibiExplainerSpec{Config: map[string]string{"threshold": "0.95"}},
+			},
+		},
+	}
+
+	copied := isvc.DeepCopy()
+	assert.Equal(t, isvc, copied)
+
+	*copied.Spec.Predictor.MinReplicas = 2
+	copied.Spec.Explainer.Alibi.Config["threshold"] = "0.9"
+	assert.Equal(t, 1, *isvc.Spec.Predictor.MinReplicas)
+	assert.Equal(t, "0.95", isvc.Spec.Explainer.Alibi.Config["threshold"])
+}
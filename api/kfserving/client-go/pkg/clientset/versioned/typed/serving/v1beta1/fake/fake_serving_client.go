@@ -0,0 +1,69 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	testing "k8s.io/client-go/testing"
+
+	"github.com/gojek/merlin/kfserving/client-go/pkg/clientset/versioned/scheme"
+	v1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/clientset/versioned/typed/serving/v1beta1"
+)
+
+type FakeServingV1beta1 struct {
+	*testing.Fake
+	tracker testing.ObjectTracker
+}
+
+// NewSimpleServingV1beta1 returns a client that will respond with the provided objects.
+// It's backed by a very simple object tracker that processes creates, updates and deletions as-is,
+// without applying any validations and/or defaults.
+func NewSimpleServingV1beta1(objects ...runtime.Object) *FakeServingV1beta1 {
+	o := testing.NewObjectTracker(scheme.Scheme, scheme.Codecs.UniversalDecoder())
+	for _, obj := range objects {
+		if err := o.Add(obj); err != nil {
+			panic(err)
+		}
+	}
+
+	c := &FakeServingV1beta1{Fake: &testing.Fake{}, tracker: o}
+	c.AddReactor("*", "*", testing.ObjectReaction(o))
+	c.AddWatchReactor("*", func(action testing.Action) (handled bool, ret watch.Interface, err error) {
+		w, err := o.Watch(action.GetResource(), action.GetNamespace())
+		if err != nil {
+			return false, nil, err
+		}
+		return true, w, nil
+	})
+	return c
+}
+
+// Tracker returns the object tracker backing the client.
+func (c *FakeServingV1beta1) Tracker() testing.ObjectTracker {
+	return c.tracker
+}
+
+func (c *FakeServingV1beta1) InferenceServices(namespace string) v1beta1.InferenceServiceInterface {
+	return &FakeInferenceServices{c, namespace}
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *FakeServingV1beta1) RESTClient() rest.Interface {
+	var ret *rest.RESTClient
+	return ret
+}
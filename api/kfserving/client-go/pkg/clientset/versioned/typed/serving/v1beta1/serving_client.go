@@ -0,0 +1,73 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	serializer "k8s.io/apimachinery/pkg/runtime/serializer"
+	rest "k8s.io/client-go/rest"
+
+	v1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/kfserving/client-go/pkg/clientset/versioned/scheme"
+)
+
+type ServingV1beta1Interface interface {
+	RESTClient() rest.Interface
+	InferenceServicesGetter
+}
+
+// ServingV1beta1Client is used to interact with features provided by the serving.kubeflow.org group.
+type ServingV1beta1Client struct {
+	restClient rest.Interface
+}
+
+func (c *ServingV1beta1Client) InferenceServices(namespace string) InferenceServiceInterface {
+	return newInferenceServices(c, namespace)
+}
+
+// NewForConfig creates a new ServingV1beta1Client for the given config.
+func NewForConfig(c *rest.Config) (*ServingV1beta1Client, error) {
+	config := *c
+	setConfigDefaults(&config)
+	client, err := rest.RESTClientFor(&config)
+	if err != nil {
+		return nil, err
+	}
+	return &ServingV1beta1Client{client}, nil
+}
+
+// New creates a new ServingV1beta1Client for the given RESTClient.
+func New(c rest.Interface) *ServingV1beta1Client {
+	return &ServingV1beta1Client{c}
+}
+
+func setConfigDefaults(config *rest.Config) {
+	gv := v1beta1.SchemeGroupVersion
+	config.GroupVersion = &gv
+	config.APIPath = "/apis"
+	config.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
+
+	if config.UserAgent == "" {
+		config.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+}
+
+// RESTClient returns a RESTClient that is used to communicate
+// with API server by this client implementation.
+func (c *ServingV1beta1Client) RESTClient() rest.Interface {
+	if c == nil {
+		return nil
+	}
+	return c.restClient
+}
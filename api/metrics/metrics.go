@@ -0,0 +1,135 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gojek/merlin/models"
+)
+
+const (
+	DefaultWindow = 5 * time.Minute
+)
+
+// ErrNoData is returned when the metrics backend has no sample for the requested SLI,
+// e.g. the endpoint doesn't receive any traffic in the window.
+var ErrNoData = errors.New("no data")
+
+// Query describes an SLI to be evaluated.
+type Query struct {
+	MetricType models.AlertConditionMetricType `json:"metric_type"`
+	// Percentile of latency SLI, in the range of 0 to 100
+	Percentile float64 `json:"percentile,omitempty"`
+	// Window is the time range the SLI is aggregated over
+	Window time.Duration `json:"-"`
+}
+
+// Provider evaluates SLIs of deployed models.
+type Provider interface {
+	// VersionEndpointSLI returns the current value of an SLI for a single version endpoint
+	VersionEndpointSLI(ctx context.Context, model *models.Model, versionEndpoint *models.VersionEndpoint, query Query) (float64, error)
+	// ModelEndpointSLI returns the current value of an SLI aggregated over all version endpoints of a model in the model endpoint's environment
+	ModelEndpointSLI(ctx context.Context, model *models.Model, modelEndpoint *models.ModelEndpoint, query Query) (float64, error)
+}
+
+// GateEvaluator evaluates rollout gates using a metrics provider.
+type GateEvaluator struct {
+	provider Provider
+	window   time.Duration
+}
+
+func NewGateEvaluator(provider Provider, window time.Duration) *GateEvaluator {
+	return &GateEvaluator{provider: provider, window: window}
+}
+
+func (e *GateEvaluator) EvaluateGate(ctx context.Context, model *models.Model, versionEndpoint *models.VersionEndpoint, gate *models.RolloutGate) (float64, error) {
+	return e.provider.VersionEndpointSLI(ctx, model, versionEndpoint, Query{
+		MetricType: gate.MetricType,
+		Percentile: gate.Percentile,
+		Window:     e.window,
+	})
+}
+
+// TrafficEvaluator measures the traffic received by version endpoints using a metrics provider.
+type TrafficEvaluator struct {
+	provider Provider
+}
+
+func NewTrafficEvaluator(provider Provider) *TrafficEvaluator {
+	return &TrafficEvaluator{provider: provider}
+}
+
+// EvaluateThroughput returns the request rate of the version endpoint over the window, 0 if it hasn't received any request.
+func (e *TrafficEvaluator) EvaluateThroughput(ctx context.Context, model *models.Model, versionEndpoint *models.VersionEndpoint, window time.Duration) (float64, error) {
+	value, err := e.provider.VersionEndpointSLI(ctx, model, versionEndpoint, Query{
+		MetricType: models.AlertConditionTypeThroughput,
+		Window:     window,
+	})
+	if err == ErrNoData {
+		return 0, nil
+	}
+	return value, err
+}
+
+// promDuration formats a duration the way PromQL range selectors expect, e.g. "90s" or "5m".
+func promDuration(d time.Duration) string {
+	if d <= 0 {
+		d = DefaultWindow
+	}
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%ds", d/time.Second)
+}
+
+// SLI is the evaluated value of a query. Value is nil if there is no data.
+type SLI struct {
+	Query
+	Value *float64 `json:"value"`
+}
+
+// DefaultQueries returns the queries of all supported SLIs, with latency evaluated at the given percentile.
+func DefaultQueries(latencyPercentile float64, window time.Duration) []Query {
+	return []Query{
+		{MetricType: models.AlertConditionTypeThroughput, Window: window},
+		{MetricType: models.AlertConditionTypeLatency, Percentile: latencyPercentile, Window: window},
+		{MetricType: models.AlertConditionTypeErrorRate, Window: window},
+		{MetricType: models.AlertConditionTypeCPU, Window: window},
+		{MetricType: models.AlertConditionTypeMemory, Window: window},
+	}
+}
+
+// Evaluate runs all queries using evaluate function. Queries without data are returned with nil value.
+func Evaluate(queries []Query, evaluate func(Query) (float64, error)) ([]SLI, error) {
+	slis := make([]SLI, 0, len(queries))
+	for _, query := range queries {
+		sli := SLI{Query: query}
+
+		value, err := evaluate(query)
+		if err != nil && err != ErrNoData {
+			return nil, fmt.Errorf("unable to evaluate %s: %v", query.MetricType, err)
+		}
+		if err == nil {
+			sli.Value = &value
+		}
+
+		slis = append(slis, sli)
+	}
+	return slis, nil
+}
@@ -0,0 +1,169 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gojek/merlin/models"
+)
+
+const (
+	prometheusQueryPath = "/api/v1/query"
+)
+
+// NewPrometheusProvider returns a Provider which evaluates SLIs using Prometheus instant query API.
+func NewPrometheusProvider(httpClient *http.Client, prometheusURL string) Provider {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &prometheusProvider{
+		c:             httpClient,
+		prometheusURL: prometheusURL,
+	}
+}
+
+type prometheusProvider struct {
+	c             *http.Client
+	prometheusURL string
+}
+
+type prometheusQueryResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type prometheusSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+func (p *prometheusProvider) VersionEndpointSLI(ctx context.Context, model *models.Model, versionEndpoint *models.VersionEndpoint, query Query) (float64, error) {
+	if versionEndpoint.Environment == nil {
+		return 0, fmt.Errorf("environment of version endpoint %s is not loaded", versionEndpoint.Id)
+	}
+
+	// Revision and pod names of a version endpoint are prefixed by "<inference service name>-predictor"
+	name := fmt.Sprintf("%s-predictor", versionEndpoint.InferenceServiceName)
+	return p.query(ctx, query, versionEndpoint.Environment.Cluster, model.Project.Name, name)
+}
+
+func (p *prometheusProvider) ModelEndpointSLI(ctx context.Context, model *models.Model, modelEndpoint *models.ModelEndpoint, query Query) (float64, error) {
+	if modelEndpoint.Environment == nil {
+		return 0, fmt.Errorf("environment of model endpoint %s is not loaded", modelEndpoint.Id)
+	}
+
+	return p.query(ctx, query, modelEndpoint.Environment.Cluster, model.Project.Name, model.Name)
+}
+
+func (p *prometheusProvider) query(ctx context.Context, query Query, cluster, namespace, name string) (float64, error) {
+	if query.MetricType == models.AlertConditionTypeLatency && (query.Percentile <= 0 || query.Percentile > 100) {
+		return 0, fmt.Errorf("latency query requires a percentile between 0 and 100, got %.2f", query.Percentile)
+	}
+
+	expr := models.SliExpr(query.MetricType, query.Percentile, cluster, namespace, name, promDuration(query.Window))
+	if expr == "" {
+		return 0, fmt.Errorf("unsupported metric type: %s", query.MetricType)
+	}
+
+	endpoint := fmt.Sprintf("%s/%s", strings.TrimRight(p.prometheusURL, "/"), strings.TrimLeft(prometheusQueryPath, "/"))
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	req = req.WithContext(ctx)
+	req.URL.RawQuery = url.Values{"query": []string{strings.TrimSpace(expr)}}.Encode()
+
+	resp, err := p.c.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	response := prometheusQueryResponse{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return 0, fmt.Errorf("unable to parse prometheus response (status %d): %v", resp.StatusCode, err)
+	}
+
+	if response.Status != "success" {
+		return 0, fmt.Errorf("prometheus query failed: %s: %s", response.ErrorType, response.Error)
+	}
+
+	return parseQueryResult(response.Data.ResultType, response.Data.Result)
+}
+
+// parseQueryResult returns the value of a scalar or a single element vector result.
+func parseQueryResult(resultType string, result json.RawMessage) (float64, error) {
+	var value []interface{}
+	switch resultType {
+	case "scalar":
+		if err := json.Unmarshal(result, &value); err != nil {
+			return 0, err
+		}
+	case "vector":
+		var samples []prometheusSample
+		if err := json.Unmarshal(result, &samples); err != nil {
+			return 0, err
+		}
+		if len(samples) == 0 {
+			return 0, ErrNoData
+		}
+		if len(samples) > 1 {
+			return 0, fmt.Errorf("expected a single sample, got %d", len(samples))
+		}
+		value = samples[0].Value
+	default:
+		return 0, fmt.Errorf("unsupported prometheus result type: %s", resultType)
+	}
+
+	if len(value) != 2 {
+		return 0, fmt.Errorf("malformed prometheus sample: %v", value)
+	}
+
+	s, ok := value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("malformed prometheus sample value: %v", value[1])
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	// Ratio SLIs are NaN when there is no request at all
+	if math.IsNaN(f) {
+		return 0, ErrNoData
+	}
+	return f, nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojek/merlin/mlp"
+	"github.com/gojek/merlin/models"
+)
+
+func newFakePrometheus(t *testing.T, response string, assertQuery func(query string)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, prometheusQueryPath, r.URL.Path)
+		if assertQuery != nil {
+			assertQuery(r.URL.Query().Get("query"))
+		}
+		fmt.Fprint(w, response)
+	}))
+}
+
+func Test_prometheusProvider_VersionEndpointSLI(t *testing.T) {
+	model := &models.Model{Name: "my-model", Project: mlp.Project{Name: "my-project"}}
+	versionEndpoint := &models.VersionEndpoint{
+		InferenceServiceName: "my-model-1",
+		Environment:          &models.Environment{Cluster: "my-cluster"},
+	}
+
+	tests := []struct {
+		name      string
+		query     Query
+		response  string
+		wantQuery string
+		want      float64
+		wantErr   error
+	}{
+		{
+			name:      "throughput",
+			query:     Query{MetricType: models.AlertConditionTypeThroughput, Window: 5 * time.Minute},
+			response:  `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"12.5"]}]}}`,
+			wantQuery: `round(sum(rate(revision_request_count{cluster_name="my-cluster",namespace_name="my-project",revision_name=~".*my-model-1-predictor.*"}[5m])), 0.001)`,
+			want:      12.5,
+		},
+		{
+			name:      "latency",
+			query:     Query{MetricType: models.AlertConditionTypeLatency, Percentile: 99, Window: 90 * time.Second},
+			response:  `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"42"]}]}}`,
+			wantQuery: `avg(histogram_quantile(0.990000, sum(rate(revision_request_latencies_bucket{cluster_name="my-cluster",namespace_name="my-project",revision_name=~".*my-model-1-predictor.*"}[90s])) by (le)))`,
+			want:      42,
+		},
+		{
+			name:     "scalar result",
+			query:    Query{MetricType: models.AlertConditionTypeMemory},
+			response: `{"status":"success","data":{"resultType":"scalar","result":[1600000000,"0.75"]}}`,
+			want:     0.75,
+		},
+		{
+			name:     "no data",
+			query:    Query{MetricType: models.AlertConditionTypeThroughput},
+			response: `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			wantErr:  ErrNoData,
+		},
+		{
+			name:     "NaN error rate",
+			query:    Query{MetricType: models.AlertConditionTypeErrorRate},
+			response: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"NaN"]}]}}`,
+			wantErr:  ErrNoData,
+		},
+		{
+			name:     "query error",
+			query:    Query{MetricType: models.AlertConditionTypeCPU},
+			response: `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			wantErr:  fmt.Errorf("prometheus query failed: bad_data: parse error"),
+		},
+		{
+			name:    "latency without percentile",
+			query:   Query{MetricType: models.AlertConditionTypeLatency},
+			wantErr: fmt.Errorf("latency query requires a percentile between 0 and 100, got 0.00"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakePrometheus(t, tt.response, func(query string) {
+				if tt.wantQuery != "" {
+					assert.Equal(t, tt.wantQuery, query)
+				}
+			})
+			defer server.Close()
+
+			p := NewPrometheusProvider(nil, server.URL)
+			got, err := p.VersionEndpointSLI(context.Background(), model, versionEndpoint, tt.query)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_prometheusProvider_ModelEndpointSLI(t *testing.T) {
+	model := &models.Model{Name: "my-model", Project: mlp.Project{Name: "my-project"}}
+	modelEndpoint := &models.ModelEndpoint{Environment: &models.Environment{Cluster: "my-cluster"}}
+
+	server := newFakePrometheus(t,
+		`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1600000000,"0.01"]}]}}`,
+		func(query string) {
+			assert.True(t, strings.HasPrefix(query, "sum(rate(revision_request_count{"))
+			assert.Contains(t, query, `revision_name=~".*my-model.*", response_code_class != "2xx"}[5m]`)
+		})
+	defer server.Close()
+
+	p := NewPrometheusProvider(nil, server.URL)
+	got, err := p.ModelEndpointSLI(context.Background(), model, modelEndpoint, Query{MetricType: models.AlertConditionTypeErrorRate})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.01, got)
+}
+
+func TestEvaluate(t *testing.T) {
+	queries := DefaultQueries(95, time.Minute)
+	slis, err := Evaluate(queries, func(query Query) (float64, error) {
+		if query.MetricType == models.AlertConditionTypeThroughput {
+			return 0, ErrNoData
+		}
+		return 1, nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, slis, 5)
+	assert.Nil(t, slis[0].Value)
+	assert.Equal(t, 1.0, *slis[1].Value)
+	assert.Equal(t, 95.0, slis[1].Percentile)
+
+	_, err = Evaluate(queries, func(query Query) (float64, error) {
+		return 0, fmt.Errorf("connection refused")
+	})
+	assert.EqualError(t, err, "unable to evaluate throughput: connection refused")
+}
+
+func TestTrafficEvaluator_EvaluateThroughput(t *testing.T) {
+	model := &models.Model{Name: "my-model", Project: mlp.Project{Name: "my-project"}}
+	versionEndpoint := &models.VersionEndpoint{
+		InferenceServiceName: "my-model-1",
+		Environment:          &models.Environment{Cluster: "my-cluster"},
+	}
+
+	server := newFakePrometheus(t, `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		func(query string) {
+			assert.Contains(t, query, "[1440m]")
+		})
+	defer server.Close()
+
+	e := NewTrafficEvaluator(NewPrometheusProvider(nil, server.URL))
+	got, err := e.EvaluateThroughput(context.Background(), model, versionEndpoint, 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, got)
+}
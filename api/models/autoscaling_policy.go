@@ -0,0 +1,77 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type AutoscalingMetric string
+
+const (
+	// AutoscalingMetricConcurrency scales on the number of in-flight requests per replica
+	AutoscalingMetricConcurrency AutoscalingMetric = "concurrency"
+	// AutoscalingMetricRPS scales on the number of requests per second per replica
+	AutoscalingMetricRPS AutoscalingMetric = "rps"
+	// AutoscalingMetricCPU scales on the CPU utilisation of the replicas, in percent of the CPU request
+	AutoscalingMetricCPU AutoscalingMetric = "cpu"
+)
+
+// AutoscalingPolicy configures how the replicas of a version endpoint are scaled between its min and max replica.
+type AutoscalingPolicy struct {
+	// Metric used by the autoscaler
+	MetricType AutoscalingMetric `json:"metric_type"`
+	// Target value of the metric per replica
+	TargetValue float64 `json:"target_value"`
+	// Whether the version endpoint scales down to zero replica when it receives no traffic
+	ScaleToZero bool `json:"scale_to_zero"`
+}
+
+// Validate checks that the policy is well-formed regardless of the environment it is deployed to.
+func (p *AutoscalingPolicy) Validate() error {
+	switch p.MetricType {
+	case AutoscalingMetricConcurrency, AutoscalingMetricRPS:
+	case AutoscalingMetricCPU:
+		if p.TargetValue > 100 {
+			return fmt.Errorf("cpu target value must be a percentage between 0 and 100, got %v", p.TargetValue)
+		}
+		if p.ScaleToZero {
+			return errors.New("scale to zero is not supported with cpu metric")
+		}
+	default:
+		return fmt.Errorf("unsupported autoscaling metric: %s", p.MetricType)
+	}
+
+	if p.TargetValue <= 0 {
+		return fmt.Errorf("target value must be positive, got %v", p.TargetValue)
+	}
+	return nil
+}
+
+func (p AutoscalingPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *AutoscalingPolicy) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &p)
+}
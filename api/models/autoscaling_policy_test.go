@@ -0,0 +1,83 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoscalingPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *AutoscalingPolicy
+		wantErr string
+	}{
+		{
+			name:   "concurrency with scale to zero",
+			policy: &AutoscalingPolicy{MetricType: AutoscalingMetricConcurrency, TargetValue: 10, ScaleToZero: true},
+		},
+		{
+			name:   "rps",
+			policy: &AutoscalingPolicy{MetricType: AutoscalingMetricRPS, TargetValue: 150.5},
+		},
+		{
+			name:   "cpu",
+			policy: &AutoscalingPolicy{MetricType: AutoscalingMetricCPU, TargetValue: 70},
+		},
+		{
+			name:    "unsupported metric",
+			policy:  &AutoscalingPolicy{MetricType: "memory", TargetValue: 70},
+			wantErr: "unsupported autoscaling metric: memory",
+		},
+		{
+			name:    "zero target",
+			policy:  &AutoscalingPolicy{MetricType: AutoscalingMetricConcurrency},
+			wantErr: "target value must be positive, got 0",
+		},
+		{
+			name:    "cpu target above 100",
+			policy:  &AutoscalingPolicy{MetricType: AutoscalingMetricCPU, TargetValue: 120},
+			wantErr: "cpu target value must be a percentage between 0 and 100, got 120",
+		},
+		{
+			name:    "cpu with scale to zero",
+			policy:  &AutoscalingPolicy{MetricType: AutoscalingMetricCPU, TargetValue: 50, ScaleToZero: true},
+			wantErr: "scale to zero is not supported with cpu metric",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAutoscalingPolicy_ValueScan(t *testing.T) {
+	policy := AutoscalingPolicy{MetricType: AutoscalingMetricRPS, TargetValue: 20, ScaleToZero: true}
+
+	value, err := policy.Value()
+	assert.NoError(t, err)
+
+	var scanned AutoscalingPolicy
+	assert.NoError(t, scanned.Scan(value))
+	assert.Equal(t, policy, scanned)
+}
@@ -0,0 +1,190 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/gojek/merlin/config"
+)
+
+// Dimensions by which the cost reports can be grouped
+const (
+	CostGroupByProject     = "project"
+	CostGroupByTeam        = "team"
+	CostGroupByModel       = "model"
+	CostGroupByEnvironment = "environment"
+)
+
+var costGroupByDimensions = []string{CostGroupByProject, CostGroupByTeam, CostGroupByModel, CostGroupByEnvironment}
+
+// ParseCostGroupBy parses a comma separated list of dimensions, an empty list groups the costs by all dimensions.
+func ParseCostGroupBy(value string) ([]string, error) {
+	if value == "" {
+		return costGroupByDimensions, nil
+	}
+
+	var groupBy []string
+	for _, dimension := range strings.Split(value, ",") {
+		dimension = strings.TrimSpace(dimension)
+		if !isCostGroupByDimension(dimension) {
+			return nil, fmt.Errorf("unknown dimension %s, it must be one of %s", dimension, strings.Join(costGroupByDimensions, ", "))
+		}
+		groupBy = append(groupBy, dimension)
+	}
+	return groupBy, nil
+}
+
+func isCostGroupByDimension(dimension string) bool {
+	for _, d := range costGroupByDimensions {
+		if d == dimension {
+			return true
+		}
+	}
+	return false
+}
+
+const bytesPerGb = 1 << 30
+
+// ResourceUsage is an amount of resources requested over a period of time.
+type ResourceUsage struct {
+	CpuHours float64 `json:"cpu_hours"`
+	// MemoryGbHours is measured in GB of 2^30 bytes
+	MemoryGbHours float64 `json:"memory_gb_hours"`
+}
+
+// NewResourceUsage returns the usage of the replicas requesting the cpu and memory for the duration.
+func NewResourceUsage(cpu, memory resource.Quantity, replicas int, duration time.Duration) ResourceUsage {
+	hours := duration.Hours() * float64(replicas)
+	return ResourceUsage{
+		CpuHours:      float64(cpu.MilliValue()) / 1000 * hours,
+		MemoryGbHours: float64(memory.Value()) / bytesPerGb * hours,
+	}
+}
+
+// Add adds the other usage to this one.
+func (u *ResourceUsage) Add(other ResourceUsage) {
+	u.CpuHours += other.CpuHours
+	u.MemoryGbHours += other.MemoryGbHours
+}
+
+// Cost returns the estimated cost of the usage given the unit prices.
+func (u ResourceUsage) Cost(price config.UnitPriceConfig) float64 {
+	return u.CpuHours*price.CpuHour + u.MemoryGbHours*price.MemoryGbHour
+}
+
+// ResourceUsage returns the resources requested by the model, transformer and explainer deployed with the spec
+// for the duration. Only the min replicas are accounted for since the replicas added by the autoscaler are unknown.
+func (s *DeploymentSpec) ResourceUsage(duration time.Duration) ResourceUsage {
+	var usage ResourceUsage
+	addResourceRequest := func(resourceRequest *ResourceRequest) {
+		if resourceRequest != nil {
+			usage.Add(NewResourceUsage(resourceRequest.CpuRequest, resourceRequest.MemoryRequest, resourceRequest.MinReplica, duration))
+		}
+	}
+
+	addResourceRequest(s.ResourceRequest)
+	if s.Transformer != nil && s.Transformer.Enabled {
+		addResourceRequest(s.Transformer.ResourceRequest)
+	}
+	if s.Explainer != nil && s.Explainer.Enabled {
+		addResourceRequest(s.Explainer.ResourceRequest)
+	}
+	return usage
+}
+
+// ResourceUsage returns the resources requested by the driver and executors of a prediction job for the duration.
+// Invalid quantities are not accounted for.
+func (r *PredictionJobResourceRequest) ResourceUsage(duration time.Duration) ResourceUsage {
+	parse := func(value string) resource.Quantity {
+		quantity, _ := resource.ParseQuantity(value)
+		return quantity
+	}
+
+	usage := NewResourceUsage(parse(r.DriverCpuRequest), parse(r.DriverMemoryRequest), 1, duration)
+	usage.Add(NewResourceUsage(parse(r.ExecutorCpuRequest), parse(r.ExecutorMemoryRequest), int(r.ExecutorReplica), duration))
+	return usage
+}
+
+// CostItem is the estimated cost of the resources requested within a group of a cost report. The dimensions the
+// report is not grouped by are left empty.
+type CostItem struct {
+	ProjectId       Id     `json:"project_id,omitempty"`
+	ProjectName     string `json:"project_name,omitempty"`
+	Team            string `json:"team,omitempty"`
+	ModelId         Id     `json:"model_id,omitempty"`
+	ModelName       string `json:"model_name,omitempty"`
+	EnvironmentName string `json:"environment_name,omitempty"`
+	ResourceUsage
+	Cost float64 `json:"cost"`
+}
+
+// CostReport is the estimated cost of the resources requested by the version endpoints and prediction jobs within a
+// time range.
+type CostReport struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	GroupBy []string  `json:"group_by"`
+	// Items are sorted by decreasing cost
+	Items     []*CostItem `json:"items"`
+	TotalCost float64     `json:"total_cost"`
+}
+
+// NewCostReport aggregates the cost items by the given dimensions.
+func NewCostReport(start, end time.Time, groupBy []string, items []*CostItem) *CostReport {
+	groups := make(map[CostItem]*CostItem)
+	report := &CostReport{Start: start, End: end, GroupBy: groupBy, Items: []*CostItem{}}
+	for _, item := range items {
+		key := item.groupKey(groupBy)
+		group, ok := groups[key]
+		if !ok {
+			group = &key
+			groups[key] = group
+			report.Items = append(report.Items, group)
+		}
+
+		group.ResourceUsage.Add(item.ResourceUsage)
+		group.Cost += item.Cost
+		report.TotalCost += item.Cost
+	}
+
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		return report.Items[i].Cost > report.Items[j].Cost
+	})
+	return report
+}
+
+// groupKey returns the dimensions of the item the report is grouped by
+func (c *CostItem) groupKey(groupBy []string) CostItem {
+	var key CostItem
+	for _, dimension := range groupBy {
+		switch dimension {
+		case CostGroupByProject:
+			key.ProjectId, key.ProjectName = c.ProjectId, c.ProjectName
+		case CostGroupByTeam:
+			key.Team = c.Team
+		case CostGroupByModel:
+			key.ModelId, key.ModelName = c.ModelId, c.ModelName
+		case CostGroupByEnvironment:
+			key.EnvironmentName = c.EnvironmentName
+		}
+	}
+	return key
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/gojek/merlin/config"
+)
+
+func TestParseCostGroupBy(t *testing.T) {
+	groupBy, err := ParseCostGroupBy("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{CostGroupByProject, CostGroupByTeam, CostGroupByModel, CostGroupByEnvironment}, groupBy)
+
+	groupBy, err = ParseCostGroupBy("team, environment")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{CostGroupByTeam, CostGroupByEnvironment}, groupBy)
+
+	_, err = ParseCostGroupBy("team,stream")
+	assert.EqualError(t, err, "unknown dimension stream, it must be one of project, team, model, environment")
+}
+
+func TestDeploymentSpec_ResourceUsage(t *testing.T) {
+	resourceRequest := &ResourceRequest{
+		MinReplica:    2,
+		MaxReplica:    4,
+		CpuRequest:    resource.MustParse("500m"),
+		MemoryRequest: resource.MustParse("1Gi"),
+	}
+	spec := &DeploymentSpec{
+		ResourceRequest: resourceRequest,
+		Transformer:     &Transformer{Enabled: true, ResourceRequest: resourceRequest},
+		Explainer:       &Explainer{Enabled: false, ResourceRequest: resourceRequest},
+	}
+
+	usage := spec.ResourceUsage(3 * time.Hour)
+	assert.Equal(t, ResourceUsage{CpuHours: 6, MemoryGbHours: 12}, usage)
+	assert.InDelta(t, 0.42, usage.Cost(config.UnitPriceConfig{CpuHour: 0.05, MemoryGbHour: 0.01}), 1e-9)
+}
+
+func TestPredictionJobResourceRequest_ResourceUsage(t *testing.T) {
+	resourceRequest := &PredictionJobResourceRequest{
+		DriverCpuRequest:      "2",
+		DriverMemoryRequest:   "2Gi",
+		ExecutorReplica:       3,
+		ExecutorCpuRequest:    "1",
+		ExecutorMemoryRequest: "512Mi",
+	}
+	assert.Equal(t, ResourceUsage{CpuHours: 10, MemoryGbHours: 7}, resourceRequest.ResourceUsage(2*time.Hour))
+
+	// invalid quantities are ignored
+	resourceRequest.DriverCpuRequest = "two"
+	assert.Equal(t, ResourceUsage{CpuHours: 6, MemoryGbHours: 7}, resourceRequest.ResourceUsage(2*time.Hour))
+}
+
+func TestNewCostReport(t *testing.T) {
+	start := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	items := []*CostItem{
+		{ProjectId: 1, ProjectName: "p1", Team: "t1", ModelId: 1, ModelName: "m1", EnvironmentName: "dev", ResourceUsage: ResourceUsage{CpuHours: 1, MemoryGbHours: 2}, Cost: 1},
+		{ProjectId: 1, ProjectName: "p1", Team: "t1", ModelId: 2, ModelName: "m2", EnvironmentName: "dev", ResourceUsage: ResourceUsage{CpuHours: 2, MemoryGbHours: 4}, Cost: 2},
+		{ProjectId: 2, ProjectName: "p2", Team: "t1", ModelId: 3, ModelName: "m3", EnvironmentName: "prod", ResourceUsage: ResourceUsage{CpuHours: 4, MemoryGbHours: 8}, Cost: 8},
+		{ProjectId: 3, ProjectName: "p3", Team: "t2", ModelId: 4, ModelName: "m4", EnvironmentName: "prod", ResourceUsage: ResourceUsage{CpuHours: 5, MemoryGbHours: 5}, Cost: 10},
+	}
+
+	report := NewCostReport(start, end, []string{CostGroupByTeam}, items)
+	assert.Equal(t, &CostReport{
+		Start:   start,
+		End:     end,
+		GroupBy: []string{CostGroupByTeam},
+		Items: []*CostItem{
+			{Team: "t1", ResourceUsage: ResourceUsage{CpuHours: 7, MemoryGbHours: 14}, Cost: 11},
+			{Team: "t2", ResourceUsage: ResourceUsage{CpuHours: 5, MemoryGbHours: 5}, Cost: 10},
+		},
+		TotalCost: 21,
+	}, report)
+
+	report = NewCostReport(start, end, []string{CostGroupByProject, CostGroupByEnvironment}, items)
+	assert.Equal(t, []*CostItem{
+		{ProjectId: 3, ProjectName: "p3", EnvironmentName: "prod", ResourceUsage: ResourceUsage{CpuHours: 5, MemoryGbHours: 5}, Cost: 10},
+		{ProjectId: 2, ProjectName: "p2", EnvironmentName: "prod", ResourceUsage: ResourceUsage{CpuHours: 4, MemoryGbHours: 8}, Cost: 8},
+		{ProjectId: 1, ProjectName: "p1", EnvironmentName: "dev", ResourceUsage: ResourceUsage{CpuHours: 3, MemoryGbHours: 6}, Cost: 3},
+	}, report.Items)
+
+	report = NewCostReport(start, end, []string{CostGroupByModel}, nil)
+	assert.Empty(t, report.Items)
+	assert.Zero(t, report.TotalCost)
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CustomPredictor is the container of a model version of the custom model type, built and pushed by the user.
+type CustomPredictor struct {
+	Image   string   `json:"image"`
+	Command []string `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	// Knative only supports a single port, 8080 is used if none is specified
+	Ports []ContainerPort `json:"ports,omitempty"`
+	// Path of the prediction requests, defaults to the KFServing "/v1/models/<name>:predict" path
+	PredictPath    string `json:"predict_path,omitempty"`
+	LivenessProbe  *Probe `json:"liveness_probe,omitempty"`
+	ReadinessProbe *Probe `json:"readiness_probe,omitempty"`
+}
+
+type ContainerPort struct {
+	Name     string `json:"name,omitempty"`
+	Port     int32  `json:"port"`
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// Probe checks the health of the container with an HTTP GET request on the path, or by opening a TCP connection
+// if there's no path. The port of the probe is always the container port.
+type Probe struct {
+	Path                string `json:"path,omitempty"`
+	InitialDelaySeconds int32  `json:"initial_delay_seconds,omitempty"`
+	PeriodSeconds       int32  `json:"period_seconds,omitempty"`
+	TimeoutSeconds      int32  `json:"timeout_seconds,omitempty"`
+	FailureThreshold    int32  `json:"failure_threshold,omitempty"`
+}
+
+func (p *CustomPredictor) Validate() error {
+	if p.Image == "" {
+		return errors.New("image is required")
+	}
+	if len(p.Ports) > 1 {
+		return fmt.Errorf("only a single port is supported, got %d", len(p.Ports))
+	}
+	for _, port := range p.Ports {
+		if port.Port <= 0 || port.Port > 65535 {
+			return fmt.Errorf("invalid port: %d", port.Port)
+		}
+	}
+	if p.PredictPath != "" && !strings.HasPrefix(p.PredictPath, "/") {
+		return fmt.Errorf("predict path must start with /, got %s", p.PredictPath)
+	}
+	return nil
+}
+
+func (p CustomPredictor) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *CustomPredictor) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &p)
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomPredictor_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		predictor *CustomPredictor
+		wantErr   string
+	}{
+		{
+			name: "valid",
+			predictor: &CustomPredictor{
+				Image:       "gojek/triton-model:1",
+				Ports:       []ContainerPort{{Name: "http1", Port: 8000}},
+				PredictPath: "/v2/models/model/infer",
+			},
+		},
+		{
+			name:      "missing image",
+			predictor: &CustomPredictor{},
+			wantErr:   "image is required",
+		},
+		{
+			name: "multiple ports",
+			predictor: &CustomPredictor{
+				Image: "gojek/triton-model:1",
+				Ports: []ContainerPort{{Port: 8000}, {Port: 8001}},
+			},
+			wantErr: "only a single port is supported, got 2",
+		},
+		{
+			name: "invalid port",
+			predictor: &CustomPredictor{
+				Image: "gojek/triton-model:1",
+				Ports: []ContainerPort{{Port: 70000}},
+			},
+			wantErr: "invalid port: 70000",
+		},
+		{
+			name: "relative predict path",
+			predictor: &CustomPredictor{
+				Image:       "gojek/triton-model:1",
+				PredictPath: "predict",
+			},
+			wantErr: "predict path must start with /, got predict",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.predictor.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
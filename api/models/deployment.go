@@ -14,7 +14,16 @@
 
 package models
 
-import "github.com/google/uuid"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Deployment is a struct representing an attempt to deploy a version_endpoint
 type Deployment struct {
@@ -23,7 +32,230 @@ type Deployment struct {
 	VersionId         Id             `json:"version_id"`
 	VersionModelId    Id             `json:"model_id"`
 	VersionEndpointId uuid.UUID      `json:"version_endpoint_id"`
+	EnvironmentName   string         `json:"environment_name"`
 	Status            EndpointStatus `json:"status"`
 	Error             string         `json:"error"`
+	// DeployedBy is the user requesting the deployment, empty if it was triggered by merlin
+	DeployedBy string `json:"deployed_by,omitempty"`
+	// Reason describes why merlin changed the version endpoint, e.g. a scaling schedule, empty for the deployments requested by users
+	Reason string `json:"reason,omitempty"`
+	// DurationSeconds is the time taken by a finished deployment, from the start of the deployment task
+	DurationSeconds float64 `json:"duration_seconds,omitempty" gorm:"-"`
+	// Revision is a sequence number of the deployments of the version endpoint, starting from 1
+	Revision int `json:"revision"`
+	// Spec is the configuration applied by the deployment, empty for deployments made before revisions were recorded
+	Spec *DeploymentSpec `json:"spec,omitempty"`
 	CreatedUpdated
 }
+
+// IsSucceeded returns true if the deployment made the version endpoint ready.
+func (d *Deployment) IsSucceeded() bool {
+	return d.Status == EndpointRunning || d.Status == EndpointServing
+}
+
+// IsFinished returns true if the deployment is not in progress anymore.
+func (d *Deployment) IsFinished() bool {
+	return d.Status != EndpointPending
+}
+
+// Duration returns the time taken by a finished deployment. The deployment record is created when the deployment
+// starts and updated when it finishes.
+func (d *Deployment) Duration() time.Duration {
+	if !d.IsFinished() {
+		return 0
+	}
+	return d.UpdatedAt.Sub(d.CreatedAt)
+}
+
+// AfterFind populates the duration of the deployments read from database
+func (d *Deployment) AfterFind() error {
+	d.DurationSeconds = d.Duration().Seconds()
+	return nil
+}
+
+// DeploymentStats summarizes a list of deployments.
+type DeploymentStats struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	// SuccessRate is the ratio of succeeded deployments among the finished ones, between 0 and 1
+	SuccessRate float64 `json:"success_rate"`
+	// MeanTimeToReadySeconds is the mean duration of the succeeded deployments
+	MeanTimeToReadySeconds float64 `json:"mean_time_to_ready_seconds"`
+}
+
+// SetSuccessRate computes the success rate from the numbers of succeeded and failed deployments.
+func (s *DeploymentStats) SetSuccessRate() {
+	s.SuccessRate = 0
+	if finished := s.Succeeded + s.Failed; finished > 0 {
+		s.SuccessRate = float64(s.Succeeded) / float64(finished)
+	}
+}
+
+// DeploymentHistory is a page of deployments along with the statistics of all the deployments matching the query.
+type DeploymentHistory struct {
+	Deployments []*Deployment    `json:"deployments"`
+	Stats       *DeploymentStats `json:"stats"`
+}
+
+// DeploymentSpec is a snapshot of the effective configuration of a version endpoint applied by a deployment.
+// It's sufficient to redeploy the endpoint to the same state, except for the secret data which is resolved at deployment.
+type DeploymentSpec struct {
+	ResourceRequest   *ResourceRequest   `json:"resource_request,omitempty"`
+	AutoscalingPolicy *AutoscalingPolicy `json:"autoscaling_policy,omitempty"`
+	EnvVars           EnvVars            `json:"env_vars,omitempty"`
+	Transformer       *Transformer       `json:"transformer,omitempty"`
+	Explainer         *Explainer         `json:"explainer,omitempty"`
+	NodePool          string             `json:"node_pool,omitempty"`
+	// Options contains the images built by merlin and the model type options used by the deployment
+	Options *ModelOption `json:"options,omitempty"`
+}
+
+// NewDeploymentSpec snapshots the configuration of the version endpoint deployed with the model options.
+func NewDeploymentSpec(endpoint *VersionEndpoint, options *ModelOption) *DeploymentSpec {
+	return &DeploymentSpec{
+		ResourceRequest:   endpoint.ResourceRequest,
+		AutoscalingPolicy: endpoint.AutoscalingPolicy,
+		EnvVars:           endpoint.EnvVars,
+		Transformer:       endpoint.Transformer,
+		Explainer:         endpoint.Explainer,
+		NodePool:          endpoint.NodePool,
+		Options:           options,
+	}
+}
+
+// ApplyTo restores the configuration of the version endpoint from the spec.
+func (s *DeploymentSpec) ApplyTo(endpoint *VersionEndpoint) {
+	endpoint.ResourceRequest = s.ResourceRequest
+	endpoint.AutoscalingPolicy = s.AutoscalingPolicy
+	endpoint.EnvVars = s.EnvVars
+	endpoint.Transformer = s.Transformer
+	endpoint.Explainer = s.Explainer
+	endpoint.NodePool = s.NodePool
+}
+
+// SpecChange is a field of the deployment spec having a different value between two revisions.
+// The field is a dotted path of the JSON representation of the spec, environment variables are keyed by their name.
+type SpecChange struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// Diff returns the changes from the previous spec, sorted by field. A nil previous spec is treated as an empty spec.
+func (s *DeploymentSpec) Diff(previous *DeploymentSpec) ([]SpecChange, error) {
+	from, err := flattenSpec(previous)
+	if err != nil {
+		return nil, err
+	}
+	to, err := flattenSpec(s)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]SpecChange, 0)
+	for field, value := range to {
+		if from[field] != value {
+			changes = append(changes, SpecChange{Field: field, From: from[field], To: value})
+		}
+	}
+	for field, value := range from {
+		if _, ok := to[field]; !ok {
+			changes = append(changes, SpecChange{Field: field, From: value})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes, nil
+}
+
+func flattenSpec(spec *DeploymentSpec) (map[string]string, error) {
+	fields := make(map[string]string)
+	if spec == nil {
+		return fields, nil
+	}
+
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		return nil, err
+	}
+
+	flattenValue("", value, fields)
+	return fields, nil
+}
+
+func flattenValue(path string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			flattenValue(joinPath(path, key), item, fields)
+		}
+	case []interface{}:
+		if !hasNamedItems(v) {
+			b, _ := json.Marshal(v)
+			fields[path] = string(b)
+			return
+		}
+		for _, item := range v {
+			named := item.(map[string]interface{})
+			name := named["name"].(string)
+			delete(named, "name")
+			flattenValue(joinPath(path, name), named, fields)
+		}
+	case string:
+		fields[path] = v
+	case nil:
+	default:
+		fields[path] = fmt.Sprint(v)
+	}
+}
+
+// hasNamedItems returns true if all items of the list are objects with a name, e.g. environment variables.
+func hasNamedItems(items []interface{}) bool {
+	if len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		named, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := named["name"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func (s DeploymentSpec) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *DeploymentSpec) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &s)
+}
+
+// DeploymentRevision is a deployment of a version endpoint along with the changes of its spec
+// from the previous revision.
+type DeploymentRevision struct {
+	*Deployment
+	Changes []SpecChange `json:"changes"`
+}
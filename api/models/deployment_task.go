@@ -0,0 +1,120 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/gojek/merlin/mlp"
+)
+
+type DeploymentTaskType string
+
+const (
+	DeployVersionEndpointTask   DeploymentTaskType = "deploy_version_endpoint"
+	UndeployVersionEndpointTask DeploymentTaskType = "undeploy_version_endpoint"
+	SubmitPredictionJobTask     DeploymentTaskType = "submit_prediction_job"
+)
+
+type DeploymentTaskStatus string
+
+const (
+	TaskPending   DeploymentTaskStatus = "pending"
+	TaskRunning   DeploymentTaskStatus = "running"
+	TaskSucceeded DeploymentTaskStatus = "succeeded"
+	TaskFailed    DeploymentTaskStatus = "failed"
+)
+
+// DeploymentTask is a unit of asynchronous deployment work persisted in database, so that it can be
+// claimed and resumed by any API replica. A running task is owned by a replica until its lease expires.
+type DeploymentTask struct {
+	Id                Id                     `json:"id"`
+	Type              DeploymentTaskType     `json:"type"`
+	VersionEndpointId *uuid.UUID             `json:"version_endpoint_id,omitempty"`
+	PredictionJobId   *Id                    `json:"prediction_job_id,omitempty"`
+	Payload           *DeploymentTaskPayload `json:"payload"`
+	Status            DeploymentTaskStatus   `json:"status"`
+	Attempts          int                    `json:"attempts"`
+	MaxAttempts       int                    `json:"max_attempts"`
+	Owner             string                 `json:"owner"`
+	LeaseExpiresAt    *time.Time             `json:"lease_expires_at"`
+	Error             string                 `json:"error"`
+	CreatedUpdated
+}
+
+// IsExhausted returns true if the task has been claimed more times than allowed,
+// i.e. previous owners died before finishing it.
+func (t *DeploymentTask) IsExhausted() bool {
+	return t.MaxAttempts > 0 && t.Attempts > t.MaxAttempts
+}
+
+// IsFinished returns true if the task has either succeeded or failed.
+func (t *DeploymentTask) IsFinished() bool {
+	return t.Status == TaskSucceeded || t.Status == TaskFailed
+}
+
+// DeploymentTaskPayload contains the data fetched at request time which is needed to execute the task later.
+type DeploymentTaskPayload struct {
+	Project        mlp.Project    `json:"project"`
+	Model          *Model         `json:"model"`
+	Version        *Version       `json:"version"`
+	PreviousStatus EndpointStatus `json:"previous_status,omitempty"`
+	// Options of a recorded revision, deployed instead of building the images again
+	Options *ModelOption `json:"options,omitempty"`
+	// User requesting the deployment, empty if it's triggered by merlin
+	User string `json:"user,omitempty"`
+}
+
+// NewDeploymentTaskPayload creates a payload without the associations of model and version.
+func NewDeploymentTaskPayload(model *Model, version *Version, previousStatus EndpointStatus) *DeploymentTaskPayload {
+	m := *model
+	m.Endpoints = nil
+
+	v := *version
+	v.Model = nil
+	v.Endpoints = nil
+
+	return &DeploymentTaskPayload{
+		Project:        model.Project,
+		Model:          &m,
+		Version:        &v,
+		PreviousStatus: previousStatus,
+	}
+}
+
+// GetModel returns the model of the payload with its project populated.
+func (p *DeploymentTaskPayload) GetModel() *Model {
+	model := *p.Model
+	model.Project = p.Project
+	return &model
+}
+
+func (p DeploymentTaskPayload) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *DeploymentTaskPayload) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &p)
+}
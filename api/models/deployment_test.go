@@ -0,0 +1,173 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestDeploymentSpec_Diff(t *testing.T) {
+	previous := &DeploymentSpec{
+		ResourceRequest: &ResourceRequest{
+			MinReplica:    1,
+			MaxReplica:    2,
+			CpuRequest:    resource.MustParse("500m"),
+			MemoryRequest: resource.MustParse("512Mi"),
+		},
+		EnvVars: EnvVars{
+			{Name: "WORKERS", Value: "1"},
+			{Name: "LOG_LEVEL", Value: "info"},
+		},
+		Transformer: &Transformer{
+			Enabled:         true,
+			TransformerType: CustomTransformerType,
+			Image:           "transformer:1",
+			Command:         []string{"python", "main.py"},
+		},
+		Options: &ModelOption{PyFuncImageName: "model:1"},
+	}
+
+	tests := []struct {
+		name     string
+		spec     *DeploymentSpec
+		previous *DeploymentSpec
+		want     []SpecChange
+	}{
+		{
+			name:     "no change",
+			spec:     previous,
+			previous: previous,
+			want:     []SpecChange{},
+		},
+		{
+			name: "changes",
+			spec: &DeploymentSpec{
+				ResourceRequest: &ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    4,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("512Mi"),
+				},
+				EnvVars: EnvVars{
+					{Name: "WORKERS", Value: "2"},
+					{Name: "API_KEY", SecretName: "api-key"},
+				},
+				Transformer: &Transformer{
+					Enabled:         true,
+					TransformerType: CustomTransformerType,
+					Image:           "transformer:1",
+					Command:         []string{"python", "app.py"},
+				},
+				NodePool: "highmem",
+				Options:  &ModelOption{PyFuncImageName: "model:2"},
+			},
+			previous: previous,
+			want: []SpecChange{
+				{Field: "env_vars.API_KEY.secret_name", To: "api-key"},
+				{Field: "env_vars.LOG_LEVEL.value", From: "info"},
+				{Field: "env_vars.WORKERS.value", From: "1", To: "2"},
+				{Field: "node_pool", To: "highmem"},
+				{Field: "options.pyfunc_image_name", From: "model:1", To: "model:2"},
+				{Field: "resource_request.cpu_request", From: "500m", To: "1"},
+				{Field: "resource_request.max_replica", From: "2", To: "4"},
+				{Field: "transformer.command", From: `["python","main.py"]`, To: `["python","app.py"]`},
+			},
+		},
+		{
+			name: "first revision",
+			spec: &DeploymentSpec{
+				EnvVars: EnvVars{{Name: "WORKERS", Value: "1"}},
+			},
+			want: []SpecChange{
+				{Field: "env_vars.WORKERS.value", To: "1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.spec.Diff(tt.previous)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDeploymentSpec_ApplyTo(t *testing.T) {
+	spec := NewDeploymentSpec(&VersionEndpoint{
+		EnvVars:  EnvVars{{Name: "WORKERS", Value: "1"}},
+		NodePool: "highmem",
+	}, &ModelOption{PyFuncImageName: "model:1"})
+
+	endpoint := &VersionEndpoint{
+		EnvVars:     EnvVars{{Name: "WORKERS", Value: "2"}},
+		Transformer: &Transformer{Enabled: true},
+	}
+	spec.ApplyTo(endpoint)
+
+	assert.Equal(t, EnvVars{{Name: "WORKERS", Value: "1"}}, endpoint.EnvVars)
+	assert.Equal(t, "highmem", endpoint.NodePool)
+	assert.Nil(t, endpoint.Transformer)
+}
+
+func TestDeploymentStats_SetSuccessRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats *DeploymentStats
+		want  float64
+	}{
+		{
+			name:  "no deployment",
+			stats: &DeploymentStats{},
+			want:  0,
+		},
+		{
+			name:  "only deployments in progress",
+			stats: &DeploymentStats{Total: 2},
+			want:  0,
+		},
+		{
+			name:  "finished and in progress deployments",
+			stats: &DeploymentStats{Total: 4, Succeeded: 2, Failed: 1},
+			want:  2.0 / 3.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.stats.SetSuccessRate()
+			assert.Equal(t, tt.want, tt.stats.SuccessRate)
+		})
+	}
+}
+
+func TestDeployment_Duration(t *testing.T) {
+	start := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	deployment := &Deployment{
+		Status:         EndpointPending,
+		CreatedUpdated: CreatedUpdated{CreatedAt: start, UpdatedAt: start.Add(time.Second)},
+	}
+	assert.Equal(t, time.Duration(0), deployment.Duration())
+
+	deployment.Status = EndpointRunning
+	deployment.UpdatedAt = start.Add(90 * time.Second)
+	assert.NoError(t, deployment.AfterFind())
+	assert.Equal(t, 90*time.Second, deployment.Duration())
+	assert.Equal(t, 90.0, deployment.DurationSeconds)
+}
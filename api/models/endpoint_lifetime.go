@@ -0,0 +1,135 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gojek/merlin/config"
+)
+
+// EndpointLifetime bounds how long the version endpoints of an environment stay deployed.
+// Durations are formatted like "72h", an empty duration is unlimited.
+type EndpointLifetime struct {
+	DefaultTTL         string `json:"default_ttl,omitempty"`
+	MaxTTL             string `json:"max_ttl,omitempty"`
+	DefaultIdleTimeout string `json:"default_idle_timeout,omitempty"`
+	MaxIdleTimeout     string `json:"max_idle_timeout,omitempty"`
+}
+
+// NewEndpointLifetime parses the endpoint lifetime of an environment, nil is returned if it's unlimited.
+func NewEndpointLifetime(cfg config.EndpointLifetimeConfig) (*EndpointLifetime, error) {
+	lifetime := &EndpointLifetime{
+		DefaultTTL:         cfg.DefaultTTL,
+		MaxTTL:             cfg.MaxTTL,
+		DefaultIdleTimeout: cfg.DefaultIdleTimeout,
+		MaxIdleTimeout:     cfg.MaxIdleTimeout,
+	}
+	if *lifetime == (EndpointLifetime{}) {
+		return nil, nil
+	}
+
+	if err := validateLifetime("max ttl", lifetime.MaxTTL, ""); err != nil {
+		return nil, err
+	}
+	if err := validateLifetime("default ttl", lifetime.DefaultTTL, lifetime.MaxTTL); err != nil {
+		return nil, err
+	}
+	if err := validateLifetime("max idle timeout", lifetime.MaxIdleTimeout, ""); err != nil {
+		return nil, err
+	}
+	if err := validateLifetime("default idle timeout", lifetime.DefaultIdleTimeout, lifetime.MaxIdleTimeout); err != nil {
+		return nil, err
+	}
+	return lifetime, nil
+}
+
+// ApplyDefaults sets the ttl and the idle timeout that the endpoint doesn't set to the defaults of the environment,
+// or to the maximums if there is no default.
+func (l *EndpointLifetime) ApplyDefaults(endpoint *VersionEndpoint) {
+	if l == nil {
+		return
+	}
+	endpoint.TTL = defaultLifetime(endpoint.TTL, l.DefaultTTL, l.MaxTTL)
+	endpoint.IdleTimeout = defaultLifetime(endpoint.IdleTimeout, l.DefaultIdleTimeout, l.MaxIdleTimeout)
+}
+
+// Validate checks the ttl and the idle timeout of the endpoint against the maximums of the environment.
+func (l *EndpointLifetime) Validate(endpoint *VersionEndpoint) error {
+	var maxTTL, maxIdleTimeout string
+	if l != nil {
+		maxTTL, maxIdleTimeout = l.MaxTTL, l.MaxIdleTimeout
+	}
+
+	if err := validateLifetime("ttl", endpoint.TTL, maxTTL); err != nil {
+		return err
+	}
+	return validateLifetime("idle timeout", endpoint.IdleTimeout, maxIdleTimeout)
+}
+
+func (l EndpointLifetime) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+func (l *EndpointLifetime) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &l)
+}
+
+func defaultLifetime(value, defaultValue, maxValue string) string {
+	switch {
+	case value != "":
+		return value
+	case defaultValue != "":
+		return defaultValue
+	default:
+		return maxValue
+	}
+}
+
+func validateLifetime(name, value, maxValue string) error {
+	duration, err := parseLifetime(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %s: %v", name, value, err)
+	}
+	if duration < 0 {
+		return fmt.Errorf("%s must not be negative, got %s", name, value)
+	}
+
+	maxDuration, err := parseLifetime(maxValue)
+	if err != nil {
+		return fmt.Errorf("invalid maximum %s %s: %v", name, maxValue, err)
+	}
+	if maxDuration > 0 && duration > maxDuration {
+		return fmt.Errorf("%s %s exceeds the maximum of the environment: %s", name, value, maxValue)
+	}
+	return nil
+}
+
+// parseLifetime parses a ttl or an idle timeout, an empty one is returned as 0.
+func parseLifetime(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
+}
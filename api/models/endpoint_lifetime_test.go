@@ -0,0 +1,128 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojek/merlin/config"
+)
+
+func TestNewEndpointLifetime(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.EndpointLifetimeConfig
+		want    *EndpointLifetime
+		wantErr bool
+	}{
+		{
+			name: "unlimited",
+			cfg:  config.EndpointLifetimeConfig{},
+			want: nil,
+		},
+		{
+			name: "valid",
+			cfg:  config.EndpointLifetimeConfig{DefaultTTL: "24h", MaxTTL: "72h", MaxIdleTimeout: "6h"},
+			want: &EndpointLifetime{DefaultTTL: "24h", MaxTTL: "72h", MaxIdleTimeout: "6h"},
+		},
+		{
+			name:    "invalid duration",
+			cfg:     config.EndpointLifetimeConfig{MaxTTL: "3 days"},
+			wantErr: true,
+		},
+		{
+			name:    "default exceeds maximum",
+			cfg:     config.EndpointLifetimeConfig{DefaultIdleTimeout: "12h", MaxIdleTimeout: "6h"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewEndpointLifetime(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEndpointLifetime_ApplyDefaults(t *testing.T) {
+	lifetime := &EndpointLifetime{DefaultTTL: "24h", MaxTTL: "72h", MaxIdleTimeout: "6h"}
+
+	endpoint := &VersionEndpoint{}
+	lifetime.ApplyDefaults(endpoint)
+	assert.Equal(t, "24h", endpoint.TTL)
+	assert.Equal(t, "6h", endpoint.IdleTimeout)
+
+	endpoint = &VersionEndpoint{TTL: "48h", IdleTimeout: "1h"}
+	lifetime.ApplyDefaults(endpoint)
+	assert.Equal(t, "48h", endpoint.TTL)
+	assert.Equal(t, "1h", endpoint.IdleTimeout)
+
+	endpoint = &VersionEndpoint{}
+	(*EndpointLifetime)(nil).ApplyDefaults(endpoint)
+	assert.Empty(t, endpoint.TTL)
+	assert.Empty(t, endpoint.IdleTimeout)
+}
+
+func TestEndpointLifetime_Validate(t *testing.T) {
+	lifetime := &EndpointLifetime{MaxTTL: "72h", MaxIdleTimeout: "6h"}
+
+	tests := []struct {
+		name     string
+		lifetime *EndpointLifetime
+		endpoint *VersionEndpoint
+		wantErr  string
+	}{
+		{
+			name:     "within maximums",
+			lifetime: lifetime,
+			endpoint: &VersionEndpoint{TTL: "72h", IdleTimeout: "30m"},
+		},
+		{
+			name:     "ttl exceeds maximum",
+			lifetime: lifetime,
+			endpoint: &VersionEndpoint{TTL: "100h"},
+			wantErr:  "ttl 100h exceeds the maximum of the environment: 72h",
+		},
+		{
+			name:     "negative idle timeout",
+			lifetime: lifetime,
+			endpoint: &VersionEndpoint{IdleTimeout: "-1h"},
+			wantErr:  "idle timeout must not be negative, got -1h",
+		},
+		{
+			name:     "invalid ttl without maximum",
+			lifetime: nil,
+			endpoint: &VersionEndpoint{TTL: "forever"},
+			wantErr:  "invalid ttl forever: time: invalid duration \"forever\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.lifetime.Validate(tt.endpoint)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"path"
+	"regexp"
 	"strconv"
 
 	"github.com/gojek/merlin/utils"
@@ -34,6 +36,9 @@ var (
 		envModelName: true,
 		envModelDir:  true,
 	}
+
+	// Project secrets are stored as keys of a Kubernetes Secret
+	secretNameRegex = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
 )
 
 // EnvVar represents an environment variable present in a container.
@@ -44,6 +49,17 @@ type EnvVar struct {
 	// Value of the environment variable.
 	// Defaults to "".
 	Value string `json:"value"`
+
+	// Name of the MLP project secret holding the value of the environment variable, instead of Value.
+	SecretName string `json:"secret_name,omitempty"`
+	// Path of the file the secret is mounted to, the environment variable is then set to the path.
+	// The secret is read from the environment variable if empty.
+	MountPath string `json:"mount_path,omitempty"`
+}
+
+// IsSecret returns true if the value of the environment variable is read from a project secret.
+func (ev EnvVar) IsSecret() bool {
+	return ev.SecretName != ""
 }
 
 // EnvVars is a list of environment variables to set in the container.
@@ -73,13 +89,52 @@ func (evs EnvVars) CheckForProtectedEnvVars() error {
 	return nil
 }
 
+// Validate checks the environment variables referencing project secrets.
+func (evs EnvVars) Validate() error {
+	for _, ev := range evs {
+		if !ev.IsSecret() {
+			if ev.MountPath != "" {
+				return fmt.Errorf("environment variable '%s' can only be mounted from a secret", ev.Name)
+			}
+			continue
+		}
+
+		if ev.Value != "" {
+			return fmt.Errorf("environment variable '%s' can't have both a value and a secret", ev.Name)
+		}
+		if !secretNameRegex.MatchString(ev.SecretName) {
+			return fmt.Errorf("environment variable '%s' references an invalid secret name: %s", ev.Name, ev.SecretName)
+		}
+		if ev.MountPath != "" && !path.IsAbs(ev.MountPath) {
+			return fmt.Errorf("environment variable '%s' must be mounted to an absolute path: %s", ev.Name, ev.MountPath)
+		}
+	}
+	return nil
+}
+
+// SecretNames returns the names of the project secrets referenced by the environment variables.
+func (evs EnvVars) SecretNames() []string {
+	var names []string
+	for _, ev := range evs {
+		if ev.IsSecret() {
+			names = append(names, ev.SecretName)
+		}
+	}
+	return names
+}
+
 // ToKubernetesEnvVars returns the representation of Kubernetes'
-// v1.EnvVars.
+// v1.EnvVars. The environment variables referencing project secrets are
+// left out, they are injected by the cluster controller which creates the
+// Kubernetes Secret.
 func (evs EnvVars) ToKubernetesEnvVars() []v1.EnvVar {
-	kubeEnvVars := make([]v1.EnvVar, len(evs))
+	kubeEnvVars := make([]v1.EnvVar, 0, len(evs))
 
-	for k, ev := range evs {
-		kubeEnvVars[k] = v1.EnvVar{Name: ev.Name, Value: ev.Value}
+	for _, ev := range evs {
+		if ev.IsSecret() {
+			continue
+		}
+		kubeEnvVars = append(kubeEnvVars, v1.EnvVar{Name: ev.Name, Value: ev.Value})
 	}
 
 	return kubeEnvVars
@@ -95,7 +150,7 @@ func MergeEnvVars(left, right EnvVars) EnvVars {
 	}
 	for _, add := range right {
 		if index, exist := envIndexMap[add.Name]; exist {
-			left[index].Value = add.Value
+			left[index] = add
 		} else {
 			left = append(left, add)
 		}
@@ -30,14 +30,14 @@ func TestEnvVars_CheckForProtectedEnvVars(t *testing.T) {
 		{
 			"no protected",
 			EnvVars{
-				EnvVar{"foo", "bar"},
+				EnvVar{Name: "foo", Value: "bar"},
 			},
 			false,
 		},
 		{
 			"protected exist",
 			EnvVars{
-				EnvVar{envModelName, "test"},
+				EnvVar{Name: envModelName, Value: "test"},
 			},
 			true,
 		},
@@ -82,6 +82,16 @@ func TestEnvVars_ToKubernetesEnvVars(t *testing.T) {
 				v1.EnvVar{Name: "2", Value: "2"},
 			},
 		},
+		{
+			"secret",
+			EnvVars{
+				EnvVar{Name: "foo", Value: "bar"},
+				EnvVar{Name: "API_KEY", SecretName: "api-key"},
+			},
+			[]v1.EnvVar{
+				v1.EnvVar{Name: "foo", Value: "bar"},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -114,12 +124,12 @@ func TestMergeEnvVars(t *testing.T) {
 			"right empty",
 			args{
 				EnvVars{
-					EnvVar{"left_foo_1", "left_bar_1"},
+					EnvVar{Name: "left_foo_1", Value: "left_bar_1"},
 				},
 				EnvVars{},
 			},
 			EnvVars{
-				EnvVar{"left_foo_1", "left_bar_1"},
+				EnvVar{Name: "left_foo_1", Value: "left_bar_1"},
 			},
 		},
 		{
@@ -127,78 +137,78 @@ func TestMergeEnvVars(t *testing.T) {
 			args{
 				EnvVars{},
 				EnvVars{
-					EnvVar{"right_foo_1", "right_bar_1"},
+					EnvVar{Name: "right_foo_1", Value: "right_bar_1"},
 				},
 			},
 			EnvVars{
-				EnvVar{"right_foo_1", "right_bar_1"},
+				EnvVar{Name: "right_foo_1", Value: "right_bar_1"},
 			},
 		},
 		{
 			"no overlap",
 			args{
 				EnvVars{
-					EnvVar{"left_foo_1", "left_bar_1"},
+					EnvVar{Name: "left_foo_1", Value: "left_bar_1"},
 				},
 				EnvVars{
-					EnvVar{"right_foo_1", "right_bar_1"},
+					EnvVar{Name: "right_foo_1", Value: "right_bar_1"},
 				},
 			},
 			EnvVars{
-				EnvVar{"left_foo_1", "left_bar_1"},
-				EnvVar{"right_foo_1", "right_bar_1"},
+				EnvVar{Name: "left_foo_1", Value: "left_bar_1"},
+				EnvVar{Name: "right_foo_1", Value: "right_bar_1"},
 			},
 		},
 		{
 			"one overlap - 2",
 			args{
 				EnvVars{
-					EnvVar{"left_foo_1", "left_bar_1"},
+					EnvVar{Name: "left_foo_1", Value: "left_bar_1"},
 				},
 				EnvVars{
-					EnvVar{"left_foo_1", "right_bar_1"},
+					EnvVar{Name: "left_foo_1", Value: "right_bar_1"},
 				},
 			},
 			EnvVars{
-				EnvVar{"left_foo_1", "right_bar_1"},
+				EnvVar{Name: "left_foo_1", Value: "right_bar_1"},
 			},
 		},
 		{
 			"one overlap - 2",
 			args{
 				EnvVars{
-					EnvVar{"left_foo_1", "left_bar_1"},
+					EnvVar{Name: "left_foo_1", Value: "left_bar_1"},
 				},
 				EnvVars{
-					EnvVar{"left_foo_1", "right_bar_1"},
-					EnvVar{"right_foo_1", "right_bar_1"},
+					EnvVar{Name: "left_foo_1", Value: "right_bar_1"},
+					EnvVar{Name: "right_foo_1", Value: "right_bar_1"},
 				},
 			},
 			EnvVars{
-				EnvVar{"left_foo_1", "right_bar_1"},
-				EnvVar{"right_foo_1", "right_bar_1"},
+				EnvVar{Name: "left_foo_1", Value: "right_bar_1"},
+				EnvVar{Name: "right_foo_1", Value: "right_bar_1"},
 			},
 		},
 		{
 			"overlap workers",
 			args{
 				EnvVars{
-					EnvVar{"MODEL_NAME", "TEST"},
-					EnvVar{"WORKERS", "2"},
-					EnvVar{"REPLICAS", "10"},
+					EnvVar{Name: "MODEL_NAME", Value: "TEST"},
+					EnvVar{Name: "WORKERS", Value: "2"},
+					EnvVar{Name: "REPLICAS", Value: "10"},
 				},
 				EnvVars{
-					EnvVar{"WORKERS", "4"},
-					EnvVar{"CPU_REQUEST", "2"},
-					EnvVar{"MEMORY_REQUEST", "2Gi"},
+					EnvVar{Name: "WORKERS", Value: "4"},
+					EnvVar{Name: "CPU_REQUEST", Value: "2"},
+					EnvVar{Name: "MEMORY_REQUEST", Value: "2Gi"},
 				},
 			},
 			EnvVars{
-				EnvVar{"MODEL_NAME", "TEST"},
-				EnvVar{"WORKERS", "4"},
-				EnvVar{"REPLICAS", "10"},
-				EnvVar{"CPU_REQUEST", "2"},
-				EnvVar{"MEMORY_REQUEST", "2Gi"},
+				EnvVar{Name: "MODEL_NAME", Value: "TEST"},
+				EnvVar{Name: "WORKERS", Value: "4"},
+				EnvVar{Name: "REPLICAS", Value: "10"},
+				EnvVar{Name: "CPU_REQUEST", Value: "2"},
+				EnvVar{Name: "MEMORY_REQUEST", Value: "2Gi"},
 			},
 		},
 	}
@@ -210,3 +220,80 @@ func TestMergeEnvVars(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvVars_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		evs     EnvVars
+		wantErr bool
+	}{
+		{
+			"literal",
+			EnvVars{
+				EnvVar{Name: "foo", Value: "bar"},
+			},
+			false,
+		},
+		{
+			"secret",
+			EnvVars{
+				EnvVar{Name: "API_KEY", SecretName: "api-key"},
+			},
+			false,
+		},
+		{
+			"mounted secret",
+			EnvVars{
+				EnvVar{Name: "GOOGLE_APPLICATION_CREDENTIALS", SecretName: "service-account.json", MountPath: "/mnt/secrets/service-account.json"},
+			},
+			false,
+		},
+		{
+			"value and secret",
+			EnvVars{
+				EnvVar{Name: "API_KEY", Value: "plain", SecretName: "api-key"},
+			},
+			true,
+		},
+		{
+			"invalid secret name",
+			EnvVars{
+				EnvVar{Name: "API_KEY", SecretName: "api key"},
+			},
+			true,
+		},
+		{
+			"relative mount path",
+			EnvVars{
+				EnvVar{Name: "API_KEY", SecretName: "api-key", MountPath: "secrets/api-key"},
+			},
+			true,
+		},
+		{
+			"mounted literal",
+			EnvVars{
+				EnvVar{Name: "foo", Value: "bar", MountPath: "/mnt/foo"},
+			},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.evs.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("EnvVars.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnvVars_SecretNames(t *testing.T) {
+	evs := EnvVars{
+		EnvVar{Name: "foo", Value: "bar"},
+		EnvVar{Name: "API_KEY", SecretName: "api-key"},
+		EnvVar{Name: "CREDENTIALS", SecretName: "credentials.json", MountPath: "/mnt/credentials.json"},
+	}
+	want := []string{"api-key", "credentials.json"}
+	if got := evs.SecretNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EnvVars.SecretNames() = %v, want %v", got, want)
+	}
+}
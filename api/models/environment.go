@@ -24,6 +24,8 @@ type Environment struct {
 	MaxCpu                 string           `json:"max_cpu"`
 	MaxMemory              string           `json:"max_memory"`
 	DefaultResourceRequest *ResourceRequest `json:"default_resource_request"`
+	// EndpointLifetime bounds the ttl and the idle timeout of the version endpoints, nil if they're unlimited
+	EndpointLifetime *EndpointLifetime `json:"endpoint_lifetime,omitempty"`
 
 	IsPredictionJobEnabled              bool                          `json:"is_prediction_job_enabled"`
 	IsDefaultPredictionJob              *bool                         `json:"is_default_prediction_job"`
@@ -0,0 +1,95 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type ExplainerType string
+
+const (
+	// AnchorTabularExplainerType runs an Alibi anchor explainer for tabular data
+	AnchorTabularExplainerType ExplainerType = "anchor_tabular"
+	// AnchorImageExplainerType runs an Alibi anchor explainer for images
+	AnchorImageExplainerType ExplainerType = "anchor_image"
+	// AnchorTextExplainerType runs an Alibi anchor explainer for text
+	AnchorTextExplainerType ExplainerType = "anchor_text"
+	// CustomExplainerType runs a user-provided docker image
+	CustomExplainerType ExplainerType = "custom"
+)
+
+// Explainer explains the predictions of a version endpoint's model.
+// Alibi explainers load the explanation model logged in the "explainer" directory of the model version's artifact.
+type Explainer struct {
+	Enabled         bool              `json:"enabled"`
+	ExplainerType   ExplainerType     `json:"explainer_type"`
+	Image           string            `json:"image,omitempty"`
+	Config          map[string]string `json:"config,omitempty"`
+	ResourceRequest *ResourceRequest  `json:"resource_request,omitempty"`
+	EnvVars         EnvVars           `json:"env_vars,omitempty"`
+}
+
+// IsAlibi returns true if the explainer is run by the Alibi explainer server of KFServing.
+func (e *Explainer) IsAlibi() bool {
+	switch e.ExplainerType {
+	case AnchorTabularExplainerType, AnchorImageExplainerType, AnchorTextExplainerType:
+		return true
+	}
+	return false
+}
+
+// Validate checks the explainer's configuration. A disabled explainer is always valid.
+func (e *Explainer) Validate() error {
+	if !e.Enabled {
+		return nil
+	}
+
+	switch {
+	case e.ExplainerType == CustomExplainerType:
+		if e.Image == "" {
+			return errors.New("custom explainer requires an image")
+		}
+		if len(e.Config) > 0 {
+			return errors.New("config is only supported by alibi explainers")
+		}
+	case e.IsAlibi():
+		if e.Image != "" {
+			return errors.New("alibi explainer image can't be specified")
+		}
+		if len(e.EnvVars) > 0 {
+			return errors.New("env vars are only supported by custom explainers")
+		}
+	default:
+		return fmt.Errorf("unsupported explainer type: %s", e.ExplainerType)
+	}
+	return nil
+}
+
+func (e Explainer) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+func (e *Explainer) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &e)
+}
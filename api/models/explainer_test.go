@@ -0,0 +1,92 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainer_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		explainer *Explainer
+		wantErr   string
+	}{
+		{
+			name:      "disabled",
+			explainer: &Explainer{Enabled: false},
+		},
+		{
+			name:      "anchor tabular",
+			explainer: &Explainer{Enabled: true, ExplainerType: AnchorTabularExplainerType, Config: map[string]string{"threshold": "0.95"}},
+		},
+		{
+			name:      "custom",
+			explainer: &Explainer{Enabled: true, ExplainerType: CustomExplainerType, Image: "ghcr.io/gojek/explainer:1.0.0", EnvVars: EnvVars{{Name: "LOG_LEVEL", Value: "INFO"}}},
+		},
+		{
+			name:      "custom without image",
+			explainer: &Explainer{Enabled: true, ExplainerType: CustomExplainerType},
+			wantErr:   "custom explainer requires an image",
+		},
+		{
+			name:      "custom with config",
+			explainer: &Explainer{Enabled: true, ExplainerType: CustomExplainerType, Image: "ghcr.io/gojek/explainer:1.0.0", Config: map[string]string{"threshold": "0.95"}},
+			wantErr:   "config is only supported by alibi explainers",
+		},
+		{
+			name:      "alibi with image",
+			explainer: &Explainer{Enabled: true, ExplainerType: AnchorTextExplainerType, Image: "ghcr.io/gojek/explainer:1.0.0"},
+			wantErr:   "alibi explainer image can't be specified",
+		},
+		{
+			name:      "alibi with env vars",
+			explainer: &Explainer{Enabled: true, ExplainerType: AnchorImageExplainerType, EnvVars: EnvVars{{Name: "LOG_LEVEL", Value: "INFO"}}},
+			wantErr:   "env vars are only supported by custom explainers",
+		},
+		{
+			name:      "unsupported type",
+			explainer: &Explainer{Enabled: true, ExplainerType: "shap"},
+			wantErr:   "unsupported explainer type: shap",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.explainer.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExplainer_ValueScan(t *testing.T) {
+	explainer := Explainer{
+		Enabled:       true,
+		ExplainerType: AnchorTabularExplainerType,
+		Config:        map[string]string{"threshold": "0.95"},
+	}
+
+	value, err := explainer.Value()
+	assert.NoError(t, err)
+
+	var scanned Explainer
+	assert.NoError(t, scanned.Scan(value))
+	assert.Equal(t, explainer, scanned)
+}
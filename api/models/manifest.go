@@ -0,0 +1,96 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Value replacing the data of the rendered Secrets
+const redactedSecretValue = "<redacted>"
+
+// Manifest is a Kubernetes resource rendered as YAML, as it would be applied to the cluster.
+type Manifest struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Yaml      string `json:"yaml"`
+}
+
+// DryRunResult lists the manifests that a deployment would apply, without applying them.
+type DryRunResult struct {
+	Manifests []*Manifest `json:"manifests"`
+}
+
+// NewManifest renders the resource, whose apiVersion and kind must be set.
+// The data of a Secret is redacted, only its keys are rendered.
+func NewManifest(obj runtime.Object) (*Manifest, error) {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		return nil, fmt.Errorf("unable to render manifest of %T without kind", obj)
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret, ok := obj.(*corev1.Secret); ok {
+		obj = redactSecret(secret)
+	}
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("unable to render manifest of %s %s: %v", kind, accessor.GetName(), err)
+	}
+
+	return &Manifest{
+		Kind:      kind,
+		Name:      accessor.GetName(),
+		Namespace: accessor.GetNamespace(),
+		Yaml:      string(data),
+	}, nil
+}
+
+// NewDryRunResult renders the resources in the order they would be applied.
+func NewDryRunResult(objs ...runtime.Object) (*DryRunResult, error) {
+	manifests := make([]*Manifest, 0, len(objs))
+	for _, obj := range objs {
+		manifest, err := NewManifest(obj)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+	return &DryRunResult{Manifests: manifests}, nil
+}
+
+func redactSecret(secret *corev1.Secret) *corev1.Secret {
+	redacted := secret.DeepCopy()
+	redacted.Data = nil
+	redacted.StringData = make(map[string]string, len(secret.Data)+len(secret.StringData))
+	for key := range secret.Data {
+		redacted.StringData[key] = redactedSecretValue
+	}
+	for key := range secret.StringData {
+		redacted.StringData[key] = redactedSecretValue
+	}
+	return redacted
+}
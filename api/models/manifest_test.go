@@ -0,0 +1,76 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewDryRunResult(t *testing.T) {
+	configMap := &v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "my-job", Namespace: "my-project"},
+		Data:       map[string]string{"jobspec.yaml": "version: v1"},
+	}
+	secret := &v1.Secret{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{Name: "my-model-1-secrets", Namespace: "my-project"},
+		Data:       map[string][]byte{"db-password": []byte("hunter2")},
+	}
+
+	result, err := NewDryRunResult(configMap, secret)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Manifest{
+		{
+			Kind:      "ConfigMap",
+			Name:      "my-job",
+			Namespace: "my-project",
+			Yaml: `apiVersion: v1
+data:
+  jobspec.yaml: 'version: v1'
+kind: ConfigMap
+metadata:
+  creationTimestamp: null
+  name: my-job
+  namespace: my-project
+`,
+		},
+		{
+			Kind:      "Secret",
+			Name:      "my-model-1-secrets",
+			Namespace: "my-project",
+			Yaml: `apiVersion: v1
+kind: Secret
+metadata:
+  creationTimestamp: null
+  name: my-model-1-secrets
+  namespace: my-project
+stringData:
+  db-password: <redacted>
+`,
+		},
+	}, result.Manifests)
+	// the rendered secret is a copy
+	assert.Equal(t, []byte("hunter2"), secret.Data["db-password"])
+}
+
+func TestNewManifest_MissingKind(t *testing.T) {
+	_, err := NewManifest(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "my-job"}})
+	assert.EqualError(t, err, "unable to render manifest of *v1.ConfigMap without kind")
+}
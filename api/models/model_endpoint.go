@@ -30,15 +30,60 @@ type ModelEndpoint struct {
 	Status          EndpointStatus     `json:"status"`
 	URL             string             `json:"url" gorm:"url"`
 	Rule            *ModelEndpointRule `json:"rule" gorm:"rule"`
+	TrafficPolicy   *TrafficPolicy     `json:"traffic_policy,omitempty" gorm:"traffic_policy"`
 	Environment     *Environment       `json:"environment" gorm:"association_foreignkey:Name"`
 	EnvironmentName string             `json:"environment_name"`
 	CreatedUpdated
 }
 
 // ModelEndpointRule describes model's endpoint traffic rule.
+// Requests satisfying the conditions of one of the matches, evaluated in order, are routed to the match's destinations.
+// The rest of the requests are routed to the default destinations.
 type ModelEndpointRule struct {
 	Destination []*ModelEndpointRuleDestination `json:"destinations"`
-	Mirror      *VersionEndpoint                `json:"mirror,omitempty"`
+	Matches     []*ModelEndpointRuleMatch       `json:"matches,omitempty"`
+	Mirrors     []*ModelEndpointRuleMirror      `json:"mirrors,omitempty"`
+	// Deprecated: use Mirrors, which supports a mirror percentage and multiple shadow version endpoints.
+	Mirror *VersionEndpoint `json:"mirror,omitempty"`
+}
+
+// AllDestinations returns the destinations of all matches followed by the default destinations.
+func (rule *ModelEndpointRule) AllDestinations() []*ModelEndpointRuleDestination {
+	var destinations []*ModelEndpointRuleDestination
+	for _, match := range rule.Matches {
+		destinations = append(destinations, match.Destination...)
+	}
+	return append(destinations, rule.Destination...)
+}
+
+// MirrorTargets returns the shadow version endpoints of the rule.
+// The deprecated Mirror field is treated as a shadow receiving all of the traffic.
+func (rule *ModelEndpointRule) MirrorTargets() []*ModelEndpointRuleMirror {
+	if len(rule.Mirrors) == 0 && rule.Mirror != nil {
+		return []*ModelEndpointRuleMirror{
+			{
+				VersionEndpointID: rule.Mirror.Id,
+				VersionEndpoint:   rule.Mirror,
+				Percentage:        100,
+			},
+		}
+	}
+	return rule.Mirrors
+}
+
+// HasVersionEndpoint returns true if the rule routes or mirrors traffic to the version endpoint.
+func (rule *ModelEndpointRule) HasVersionEndpoint(versionEndpointId uuid.UUID) bool {
+	for _, dest := range rule.AllDestinations() {
+		if dest.VersionEndpointID == versionEndpointId {
+			return true
+		}
+	}
+	for _, mirror := range rule.MirrorTargets() {
+		if mirror.VersionEndpointID == versionEndpointId {
+			return true
+		}
+	}
+	return false
 }
 
 func (rule ModelEndpointRule) Value() (driver.Value, error) {
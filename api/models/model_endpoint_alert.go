@@ -29,10 +29,12 @@ const (
 )
 
 const (
-	throughputSliExprFormat = "round(sum(rate(revision_request_count{cluster_name=\"%s\",namespace_name=\"%s\",revision_name=~\".*%s.*\"}[1m])), 0.001)\n"
-	latencySliExprFormat    = "avg(histogram_quantile(%f, sum(rate(revision_request_latencies_bucket{cluster_name=\"%s\",namespace_name=\"%s\",revision_name=~\".*%s.*\"}[1m])) by (le)))\n"
-	errorRateSliExprFormat  = "sum(rate(revision_request_count{cluster_name=\"%s\",namespace_name=\"%s\",revision_name=~\".*%s.*\", response_code_class != \"2xx\"}[1m])) / sum(rate(revision_request_count{cluster_name=\"%s\",namespace_name=\"%s\",revision_name=~\".*%s.*\"}[1m]))\n"
-	cpuSliExprFormat        = "sum(rate(container_cpu_usage_seconds_total{cluster_name=\"%s\", namespace=\"%s\", pod_name=~\".*%s.*\"}[1m])) / sum(kube_pod_container_resource_requests_cpu_cores{cluster_name=\"%s\", namespace=\"%s\", pod=~\".*%s.*\"})\n"
+	defaultSliWindow = "1m"
+
+	throughputSliExprFormat = "round(sum(rate(revision_request_count{cluster_name=\"%s\",namespace_name=\"%s\",revision_name=~\".*%s.*\"}[%s])), 0.001)\n"
+	latencySliExprFormat    = "avg(histogram_quantile(%f, sum(rate(revision_request_latencies_bucket{cluster_name=\"%s\",namespace_name=\"%s\",revision_name=~\".*%s.*\"}[%s])) by (le)))\n"
+	errorRateSliExprFormat  = "sum(rate(revision_request_count{cluster_name=\"%s\",namespace_name=\"%s\",revision_name=~\".*%s.*\", response_code_class != \"2xx\"}[%s])) / sum(rate(revision_request_count{cluster_name=\"%s\",namespace_name=\"%s\",revision_name=~\".*%s.*\"}[%s]))\n"
+	cpuSliExprFormat        = "sum(rate(container_cpu_usage_seconds_total{cluster_name=\"%s\", namespace=\"%s\", pod_name=~\".*%s.*\"}[%s])) / sum(kube_pod_container_resource_requests_cpu_cores{cluster_name=\"%s\", namespace=\"%s\", pod=~\".*%s.*\"})\n"
 	memorySliExprFormat     = "sum(container_memory_usage_bytes{cluster_name=\"%s\",namespace=\"%s\",pod_name=~\".*%s.*\"}) / sum(kube_pod_container_resource_requests_memory_bytes{cluster_name=\"%s\",namespace=\"%s\",pod=~\".*%s.*\"})\n"
 )
 
@@ -136,35 +138,41 @@ func (alert ModelEndpointAlert) ToPromAlertSpec() PromAlert {
 }
 
 func (alert ModelEndpointAlert) sliExpr(alertCondition AlertCondition) string {
-	switch alertCondition.MetricType {
+	return SliExpr(alertCondition.MetricType, alertCondition.Percentile,
+		alert.ModelEndpoint.Environment.Cluster, alert.Model.Project.Name, alert.Model.Name, defaultSliWindow)
+}
+
+// SliExpr returns the PromQL expression of an SLI for workloads whose revision and pod names contain name.
+// percentile is only used by latency SLI and is in the range of 0 to 100. window is the range of rate() functions, e.g. "5m".
+func SliExpr(metricType AlertConditionMetricType, percentile float64, cluster, namespace, name, window string) string {
+	switch metricType {
 	case AlertConditionTypeThroughput:
 		return fmt.Sprintf(
 			throughputSliExprFormat,
-			alert.ModelEndpoint.Environment.Cluster, alert.Model.Project.Name, alert.Model.Name,
+			cluster, namespace, name, window,
 		)
 	case AlertConditionTypeLatency:
-		percentile := alertCondition.Percentile / 100
 		return fmt.Sprintf(
 			latencySliExprFormat,
-			percentile, alert.ModelEndpoint.Environment.Cluster, alert.Model.Project.Name, alert.Model.Name,
+			percentile/100, cluster, namespace, name, window,
 		)
 	case AlertConditionTypeErrorRate:
 		return fmt.Sprintf(
 			errorRateSliExprFormat,
-			alert.ModelEndpoint.Environment.Cluster, alert.Model.Project.Name, alert.Model.Name,
-			alert.ModelEndpoint.Environment.Cluster, alert.Model.Project.Name, alert.Model.Name,
+			cluster, namespace, name, window,
+			cluster, namespace, name, window,
 		)
 	case AlertConditionTypeCPU:
 		return fmt.Sprintf(
 			cpuSliExprFormat,
-			alert.ModelEndpoint.Environment.Cluster, alert.Model.Project.Name, alert.Model.Name,
-			alert.ModelEndpoint.Environment.Cluster, alert.Model.Project.Name, alert.Model.Name,
+			cluster, namespace, name, window,
+			cluster, namespace, name,
 		)
 	case AlertConditionTypeMemory:
 		return fmt.Sprintf(
 			memorySliExprFormat,
-			alert.ModelEndpoint.Environment.Cluster, alert.Model.Project.Name, alert.Model.Name,
-			alert.ModelEndpoint.Environment.Cluster, alert.Model.Project.Name, alert.Model.Name,
+			cluster, namespace, name,
+			cluster, namespace, name,
 		)
 	default:
 		return ""
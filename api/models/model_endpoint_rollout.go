@@ -0,0 +1,248 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RolloutStatus string
+
+const (
+	RolloutProgressing RolloutStatus = "progressing"
+	RolloutPromoted    RolloutStatus = "promoted"
+	RolloutRolledBack  RolloutStatus = "rolled_back"
+	RolloutFailed      RolloutStatus = "failed"
+)
+
+// IsTerminal returns true if the rollout will not shift traffic anymore.
+func (s RolloutStatus) IsTerminal() bool {
+	return s == RolloutPromoted || s == RolloutRolledBack || s == RolloutFailed
+}
+
+// ModelEndpointRollout describes a gradual traffic shift of a model endpoint to a new version endpoint.
+type ModelEndpointRollout struct {
+	Id                Id                 `json:"id"`
+	ModelId           Id                 `json:"model_id"`
+	ModelEndpointId   Id                 `json:"model_endpoint_id"`
+	VersionEndpointId uuid.UUID          `json:"version_endpoint_id"`
+	PreviousRule      *ModelEndpointRule `json:"previous_rule,omitempty"`
+	// Percentage of traffic routed to the version endpoint at each step, e.g. [5, 25, 50, 100]
+	Steps RolloutSteps `json:"steps"`
+	// Duration to wait between two steps, e.g. "10m"
+	StepInterval string `json:"step_interval"`
+	// Criteria that must be satisfied before moving to the next step
+	Gates       RolloutGates  `json:"gates"`
+	CurrentStep int           `json:"current_step"`
+	Status      RolloutStatus `json:"status"`
+	Message     string        `json:"message"`
+	NextStepAt  *time.Time    `json:"next_step_at,omitempty"`
+	CreatedUpdated
+}
+
+// Interval returns the parsed step interval.
+func (r *ModelEndpointRollout) Interval() (time.Duration, error) {
+	return time.ParseDuration(r.StepInterval)
+}
+
+// CurrentWeight returns the percentage of traffic currently routed to the version endpoint.
+func (r *ModelEndpointRollout) CurrentWeight() int32 {
+	if r.CurrentStep <= 0 || r.CurrentStep > len(r.Steps) {
+		return 0
+	}
+	return r.Steps[r.CurrentStep-1]
+}
+
+// IsLastStep returns true if all steps of the rollout have been applied.
+func (r *ModelEndpointRollout) IsLastStep() bool {
+	return r.CurrentStep >= len(r.Steps)
+}
+
+// Validate checks that the rollout schedule is well-formed.
+func (r *ModelEndpointRollout) Validate() error {
+	if len(r.Steps) == 0 {
+		return errors.New("rollout must have at least one step")
+	}
+
+	var prev int32
+	for _, weight := range r.Steps {
+		if weight <= prev || weight > 100 {
+			return fmt.Errorf("rollout steps must be strictly increasing weights between 1 and 100, got %v", []int32(r.Steps))
+		}
+		prev = weight
+	}
+
+	if prev != 100 {
+		return fmt.Errorf("last rollout step must route 100%% of traffic, got %d", prev)
+	}
+
+	interval, err := r.Interval()
+	if err != nil {
+		return fmt.Errorf("invalid step interval %s: %v", r.StepInterval, err)
+	}
+
+	if interval <= 0 {
+		return fmt.Errorf("step interval must be positive, got %s", r.StepInterval)
+	}
+
+	for _, gate := range r.Gates {
+		if err := gate.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InvalidRolloutError is returned when a rollout can't be started on a model endpoint.
+type InvalidRolloutError struct {
+	Reason string
+}
+
+func NewInvalidRolloutError(format string, args ...interface{}) *InvalidRolloutError {
+	return &InvalidRolloutError{Reason: fmt.Sprintf(format, args...)}
+}
+
+func (e *InvalidRolloutError) Error() string {
+	return e.Reason
+}
+
+// RuleForWeight returns the traffic rule routing the given percentage of traffic to the target version endpoint.
+// The rest of the traffic is split among the destinations of the previous rule, proportionally to their original weights.
+// The matches of the previous rule are kept as is, only the default route is shifted.
+func (r *ModelEndpointRollout) RuleForWeight(target *VersionEndpoint, weight int32) *ModelEndpointRule {
+	rule := &ModelEndpointRule{
+		Destination: []*ModelEndpointRuleDestination{
+			{
+				VersionEndpointID: target.Id,
+				VersionEndpoint:   target,
+				Weight:            weight,
+			},
+		},
+	}
+
+	if r.PreviousRule == nil {
+		return rule
+	}
+	rule.Matches = r.PreviousRule.Matches
+	// The target stops shadowing the model endpoint once it receives traffic
+	for _, mirror := range r.PreviousRule.MirrorTargets() {
+		if mirror.VersionEndpointID != target.Id {
+			rule.Mirrors = append(rule.Mirrors, mirror)
+		}
+	}
+
+	var previous []*ModelEndpointRuleDestination
+	var total int32
+	for _, dest := range r.PreviousRule.Destination {
+		if dest.VersionEndpointID == target.Id || dest.Weight <= 0 {
+			continue
+		}
+		previous = append(previous, dest)
+		total += dest.Weight
+	}
+
+	remaining := 100 - weight
+	if remaining <= 0 || total == 0 {
+		rule.Destination[0].Weight = 100
+		return rule
+	}
+
+	var assigned int32
+	shifted := make([]*ModelEndpointRuleDestination, len(previous))
+	for i, dest := range previous {
+		shifted[i] = &ModelEndpointRuleDestination{
+			VersionEndpointID: dest.VersionEndpointID,
+			VersionEndpoint:   dest.VersionEndpoint,
+			Weight:            dest.Weight * remaining / total,
+		}
+		assigned += shifted[i].Weight
+	}
+	// Give the rounding leftover to the first previous destination so that weights always sum up to 100
+	shifted[0].Weight += remaining - assigned
+
+	for _, dest := range shifted {
+		if dest.Weight > 0 {
+			rule.Destination = append(rule.Destination, dest)
+		}
+	}
+	return rule
+}
+
+type RolloutSteps []int32
+
+func (s RolloutSteps) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *RolloutSteps) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &s)
+}
+
+// RolloutGate is a metric criterion evaluated against the version endpoint before each step.
+// Throughput must stay above the threshold, all other metrics must stay below it.
+type RolloutGate struct {
+	MetricType AlertConditionMetricType `json:"metric_type"`
+	Threshold  float64                  `json:"threshold"`
+	Percentile float64                  `json:"percentile,omitempty"`
+}
+
+// IsSatisfied returns true if the observed value meets the gate's threshold.
+func (g RolloutGate) IsSatisfied(value float64) bool {
+	if g.MetricType == AlertConditionTypeThroughput {
+		return value >= g.Threshold
+	}
+	return value <= g.Threshold
+}
+
+func (g RolloutGate) Validate() error {
+	switch g.MetricType {
+	case AlertConditionTypeThroughput, AlertConditionTypeErrorRate, AlertConditionTypeCPU, AlertConditionTypeMemory:
+		return nil
+	case AlertConditionTypeLatency:
+		if g.Percentile <= 0 || g.Percentile > 100 {
+			return fmt.Errorf("latency gate requires a percentile between 0 and 100, got %.2f", g.Percentile)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported rollout gate metric type: %s", g.MetricType)
+	}
+}
+
+type RolloutGates []*RolloutGate
+
+func (g RolloutGates) Value() (driver.Value, error) {
+	return json.Marshal(g)
+}
+
+func (g *RolloutGates) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &g)
+}
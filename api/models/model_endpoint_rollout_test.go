@@ -0,0 +1,192 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelEndpointRollout_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rollout *ModelEndpointRollout
+		wantErr bool
+	}{
+		{
+			name:    "valid rollout",
+			rollout: &ModelEndpointRollout{Steps: RolloutSteps{10, 50, 100}, StepInterval: "10m"},
+		},
+		{
+			name: "valid rollout with gates",
+			rollout: &ModelEndpointRollout{
+				Steps:        RolloutSteps{100},
+				StepInterval: "1m",
+				Gates: RolloutGates{
+					{MetricType: AlertConditionTypeErrorRate, Threshold: 1},
+					{MetricType: AlertConditionTypeLatency, Threshold: 100, Percentile: 99},
+				},
+			},
+		},
+		{
+			name:    "no steps",
+			rollout: &ModelEndpointRollout{StepInterval: "10m"},
+			wantErr: true,
+		},
+		{
+			name:    "steps not increasing",
+			rollout: &ModelEndpointRollout{Steps: RolloutSteps{50, 10, 100}, StepInterval: "10m"},
+			wantErr: true,
+		},
+		{
+			name:    "last step not 100",
+			rollout: &ModelEndpointRollout{Steps: RolloutSteps{10, 50}, StepInterval: "10m"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid interval",
+			rollout: &ModelEndpointRollout{Steps: RolloutSteps{100}, StepInterval: "ten minutes"},
+			wantErr: true,
+		},
+		{
+			name: "latency gate without percentile",
+			rollout: &ModelEndpointRollout{
+				Steps:        RolloutSteps{100},
+				StepInterval: "1m",
+				Gates:        RolloutGates{{MetricType: AlertConditionTypeLatency, Threshold: 100}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rollout.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestModelEndpointRollout_RuleForWeight(t *testing.T) {
+	target := &VersionEndpoint{Id: uuid.New()}
+	ve1 := &VersionEndpoint{Id: uuid.New()}
+	ve2 := &VersionEndpoint{Id: uuid.New()}
+
+	tests := []struct {
+		name         string
+		previousRule *ModelEndpointRule
+		weight       int32
+		want         map[uuid.UUID]int32
+	}{
+		{
+			name: "single previous destination",
+			previousRule: &ModelEndpointRule{
+				Destination: []*ModelEndpointRuleDestination{{VersionEndpointID: ve1.Id, VersionEndpoint: ve1, Weight: 100}},
+			},
+			weight: 10,
+			want:   map[uuid.UUID]int32{target.Id: 10, ve1.Id: 90},
+		},
+		{
+			name: "previous destinations are scaled proportionally",
+			previousRule: &ModelEndpointRule{
+				Destination: []*ModelEndpointRuleDestination{
+					{VersionEndpointID: ve1.Id, VersionEndpoint: ve1, Weight: 70},
+					{VersionEndpointID: ve2.Id, VersionEndpoint: ve2, Weight: 30},
+				},
+			},
+			weight: 25,
+			want:   map[uuid.UUID]int32{target.Id: 25, ve1.Id: 53, ve2.Id: 22},
+		},
+		{
+			name: "target already in previous rule",
+			previousRule: &ModelEndpointRule{
+				Destination: []*ModelEndpointRuleDestination{
+					{VersionEndpointID: ve1.Id, VersionEndpoint: ve1, Weight: 80},
+					{VersionEndpointID: target.Id, VersionEndpoint: target, Weight: 20},
+				},
+			},
+			weight: 50,
+			want:   map[uuid.UUID]int32{target.Id: 50, ve1.Id: 50},
+		},
+		{
+			name: "full weight drops previous destinations",
+			previousRule: &ModelEndpointRule{
+				Destination: []*ModelEndpointRuleDestination{{VersionEndpointID: ve1.Id, VersionEndpoint: ve1, Weight: 100}},
+			},
+			weight: 100,
+			want:   map[uuid.UUID]int32{target.Id: 100},
+		},
+		{
+			name: "matches are kept",
+			previousRule: &ModelEndpointRule{
+				Destination: []*ModelEndpointRuleDestination{{VersionEndpointID: ve1.Id, VersionEndpoint: ve1, Weight: 100}},
+				Matches: []*ModelEndpointRuleMatch{
+					{
+						Conditions:  []*ModelEndpointRuleMatchCondition{{Type: RuleMatchHeader, Name: "x-variant", Value: "beta"}},
+						Destination: []*ModelEndpointRuleDestination{{VersionEndpointID: ve2.Id, VersionEndpoint: ve2, Weight: 100}},
+					},
+				},
+			},
+			weight: 10,
+			want:   map[uuid.UUID]int32{target.Id: 10, ve1.Id: 90},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ModelEndpointRollout{PreviousRule: tt.previousRule}
+			rule := r.RuleForWeight(target, tt.weight)
+
+			got := map[uuid.UUID]int32{}
+			var total int32
+			for _, dest := range rule.Destination {
+				got[dest.VersionEndpointID] = dest.Weight
+				total += dest.Weight
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, int32(100), total)
+			assert.Equal(t, tt.previousRule.Matches, rule.Matches)
+		})
+	}
+}
+
+func TestModelEndpointRollout_RuleForWeightMirrors(t *testing.T) {
+	target := &VersionEndpoint{Id: uuid.New()}
+	ve1 := &VersionEndpoint{Id: uuid.New()}
+	shadow := &ModelEndpointRuleMirror{VersionEndpointID: uuid.New(), Percentage: 10}
+
+	r := &ModelEndpointRollout{
+		PreviousRule: &ModelEndpointRule{
+			Destination: []*ModelEndpointRuleDestination{{VersionEndpointID: ve1.Id, VersionEndpoint: ve1, Weight: 100}},
+			Mirrors: []*ModelEndpointRuleMirror{
+				{VersionEndpointID: target.Id, VersionEndpoint: target, Percentage: 50},
+				shadow,
+			},
+		},
+	}
+	rule := r.RuleForWeight(target, 10)
+	assert.Equal(t, []*ModelEndpointRuleMirror{shadow}, rule.Mirrors)
+}
+
+func TestRolloutGate_IsSatisfied(t *testing.T) {
+	assert.True(t, RolloutGate{MetricType: AlertConditionTypeThroughput, Threshold: 10}.IsSatisfied(12))
+	assert.False(t, RolloutGate{MetricType: AlertConditionTypeThroughput, Threshold: 10}.IsSatisfied(8))
+	assert.True(t, RolloutGate{MetricType: AlertConditionTypeErrorRate, Threshold: 1}.IsSatisfied(0.5))
+	assert.False(t, RolloutGate{MetricType: AlertConditionTypeErrorRate, Threshold: 1}.IsSatisfied(2))
+}
@@ -0,0 +1,232 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type RuleMatchConditionType string
+
+const (
+	RuleMatchHeader RuleMatchConditionType = "header"
+	RuleMatchCookie RuleMatchConditionType = "cookie"
+	RuleMatchQuery  RuleMatchConditionType = "query"
+)
+
+type StringMatchType string
+
+const (
+	StringMatchExact  StringMatchType = "exact"
+	StringMatchPrefix StringMatchType = "prefix"
+	StringMatchRegex  StringMatchType = "regex"
+)
+
+// ModelEndpointRuleMatch routes requests satisfying all of its conditions to its destinations.
+type ModelEndpointRuleMatch struct {
+	Conditions  []*ModelEndpointRuleMatchCondition `json:"conditions"`
+	Destination []*ModelEndpointRuleDestination    `json:"destinations"`
+}
+
+// ModelEndpointRuleMatchCondition matches the value of a request header, cookie or query parameter.
+// Cookies only support exact match and query parameters don't support prefix match.
+type ModelEndpointRuleMatchCondition struct {
+	Type      RuleMatchConditionType `json:"type"`
+	Name      string                 `json:"name"`
+	MatchType StringMatchType        `json:"match_type,omitempty"`
+	Value     string                 `json:"value"`
+}
+
+// GetMatchType returns the match type of the condition, which defaults to exact.
+func (c *ModelEndpointRuleMatchCondition) GetMatchType() StringMatchType {
+	if c.MatchType == "" {
+		return StringMatchExact
+	}
+	return c.MatchType
+}
+
+// attribute identifies the request attribute the condition is evaluated against.
+// Header names are case insensitive and all cookies are matched against the cookie header.
+func (c *ModelEndpointRuleMatchCondition) attribute() string {
+	switch c.Type {
+	case RuleMatchHeader:
+		return "header:" + strings.ToLower(c.Name)
+	case RuleMatchCookie:
+		return "header:cookie"
+	default:
+		return string(c.Type) + ":" + c.Name
+	}
+}
+
+// canonical returns the normalized form of the condition used to compare conditions.
+func (c *ModelEndpointRuleMatchCondition) canonical() string {
+	name := c.Name
+	if c.Type == RuleMatchHeader {
+		name = strings.ToLower(name)
+	}
+	return fmt.Sprintf("%s %s %s %s", c.Type, name, c.GetMatchType(), c.Value)
+}
+
+func (c *ModelEndpointRuleMatchCondition) String() string {
+	return fmt.Sprintf("%s %s %s %q", c.Type, c.Name, c.GetMatchType(), c.Value)
+}
+
+func (c *ModelEndpointRuleMatchCondition) Validate() error {
+	if c.Name == "" || c.Value == "" {
+		return fmt.Errorf("condition %s must have both name and value", c)
+	}
+
+	matchType := c.GetMatchType()
+	switch matchType {
+	case StringMatchExact, StringMatchPrefix:
+	case StringMatchRegex:
+		if _, err := regexp.Compile(c.Value); err != nil {
+			return fmt.Errorf("condition %s has invalid regex: %v", c, err)
+		}
+	default:
+		return fmt.Errorf("condition %s has unsupported match type %s", c, matchType)
+	}
+
+	switch c.Type {
+	case RuleMatchHeader:
+		if strings.EqualFold(c.Name, "cookie") {
+			return fmt.Errorf("condition %s must use cookie type to match cookies", c)
+		}
+	case RuleMatchCookie:
+		if matchType != StringMatchExact {
+			return fmt.Errorf("condition %s: cookie only supports exact match", c)
+		}
+	case RuleMatchQuery:
+		if matchType == StringMatchPrefix {
+			return fmt.Errorf("condition %s: query parameter doesn't support prefix match", c)
+		}
+	default:
+		return fmt.Errorf("condition %s has unsupported type %s", c, c.Type)
+	}
+
+	return nil
+}
+
+// Validate checks that the match has valid conditions on distinct request attributes, and destinations whose weights sum up to 100.
+func (m *ModelEndpointRuleMatch) Validate() error {
+	if len(m.Conditions) == 0 {
+		return fmt.Errorf("match must have at least one condition")
+	}
+
+	attributes := map[string]bool{}
+	for _, condition := range m.Conditions {
+		if err := condition.Validate(); err != nil {
+			return err
+		}
+
+		attribute := condition.attribute()
+		if attributes[attribute] {
+			return fmt.Errorf("condition %s conflicts with another condition on the same %s", condition, strings.Replace(attribute, ":", " ", 1))
+		}
+		attributes[attribute] = true
+	}
+
+	if len(m.Destination) == 0 {
+		return fmt.Errorf("match must have at least one destination")
+	}
+
+	var totalWeight int32
+	for _, destination := range m.Destination {
+		totalWeight += destination.Weight
+	}
+	if totalWeight != 100 {
+		return fmt.Errorf("weights of match destinations must sum up to 100, got %d", totalWeight)
+	}
+
+	return nil
+}
+
+// covers returns true if every request satisfying the other match also satisfies this match.
+func (m *ModelEndpointRuleMatch) covers(other *ModelEndpointRuleMatch) bool {
+	conditions := map[string]bool{}
+	for _, condition := range other.Conditions {
+		conditions[condition.canonical()] = true
+	}
+
+	for _, condition := range m.Conditions {
+		if !conditions[condition.canonical()] {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateMatches validates each match of the rule and rejects ambiguous matches,
+// i.e. a match that would never be evaluated because an earlier match accepts all of its requests.
+func (rule *ModelEndpointRule) ValidateMatches() error {
+	for i, match := range rule.Matches {
+		if err := match.Validate(); err != nil {
+			return fmt.Errorf("invalid match %d: %v", i+1, err)
+		}
+
+		for j := 0; j < i; j++ {
+			if rule.Matches[j].covers(match) {
+				return fmt.Errorf("ambiguous matches: match %d is never reached because match %d accepts all of its requests", i+1, j+1)
+			}
+		}
+	}
+	return nil
+}
+
+// ModelEndpointRuleMirror shadows a percentage of the model endpoint traffic to a version endpoint.
+// Responses of the shadow version endpoint are discarded, so it doesn't have to be running for the model endpoint to serve traffic.
+type ModelEndpointRuleMirror struct {
+	VersionEndpointID uuid.UUID        `json:"version_endpoint_id"`
+	VersionEndpoint   *VersionEndpoint `json:"version_endpoint,omitempty"`
+	Percentage        int32            `json:"percentage"`
+}
+
+// ValidateMirrors checks that each shadow version endpoint is mirrored once with a percentage between 1 and 100,
+// and that it isn't a destination of the rule.
+func (rule *ModelEndpointRule) ValidateMirrors() error {
+	destinations := map[uuid.UUID]bool{}
+	for _, destination := range rule.AllDestinations() {
+		destinations[destination.VersionEndpointID] = true
+	}
+
+	mirrors := map[uuid.UUID]bool{}
+	for _, mirror := range rule.MirrorTargets() {
+		if mirror.Percentage < 1 || mirror.Percentage > 100 {
+			return fmt.Errorf("mirror percentage of version endpoint %s must be between 1 and 100, got %d", mirror.VersionEndpointID, mirror.Percentage)
+		}
+		if destinations[mirror.VersionEndpointID] {
+			return fmt.Errorf("version endpoint %s can't be both a destination and a mirror", mirror.VersionEndpointID)
+		}
+		if mirrors[mirror.VersionEndpointID] {
+			return fmt.Errorf("version endpoint %s is mirrored more than once", mirror.VersionEndpointID)
+		}
+		mirrors[mirror.VersionEndpointID] = true
+	}
+	return nil
+}
+
+// ModelEndpointShadow describes a version endpoint shadowing a model endpoint.
+// Active is false when the version endpoint isn't deployed, in which case no traffic is mirrored to it.
+type ModelEndpointShadow struct {
+	VersionId         Id             `json:"version_id"`
+	VersionEndpointId uuid.UUID      `json:"version_endpoint_id"`
+	Percentage        int32          `json:"percentage"`
+	Status            EndpointStatus `json:"status"`
+	Active            bool           `json:"active"`
+}
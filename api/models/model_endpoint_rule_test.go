@@ -0,0 +1,228 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelEndpointRule_ValidateMatches(t *testing.T) {
+	destination := []*ModelEndpointRuleDestination{{Weight: 100}}
+
+	tests := []struct {
+		name    string
+		matches []*ModelEndpointRuleMatch
+		wantErr string
+	}{
+		{
+			name: "no matches",
+		},
+		{
+			name: "valid matches",
+			matches: []*ModelEndpointRuleMatch{
+				{
+					Conditions: []*ModelEndpointRuleMatchCondition{
+						{Type: RuleMatchHeader, Name: "X-Variant", Value: "beta"},
+						{Type: RuleMatchQuery, Name: "debug", MatchType: StringMatchRegex, Value: "^(1|true)$"},
+					},
+					Destination: destination,
+				},
+				{
+					Conditions:  []*ModelEndpointRuleMatchCondition{{Type: RuleMatchCookie, Name: "variant", Value: "beta"}},
+					Destination: destination,
+				},
+				{
+					Conditions:  []*ModelEndpointRuleMatchCondition{{Type: RuleMatchHeader, Name: "x-variant", MatchType: StringMatchPrefix, Value: "be"}},
+					Destination: destination,
+				},
+			},
+		},
+		{
+			name:    "match without condition",
+			matches: []*ModelEndpointRuleMatch{{Destination: destination}},
+			wantErr: "invalid match 1: match must have at least one condition",
+		},
+		{
+			name: "condition without value",
+			matches: []*ModelEndpointRuleMatch{
+				{
+					Conditions:  []*ModelEndpointRuleMatchCondition{{Type: RuleMatchHeader, Name: "x-variant"}},
+					Destination: destination,
+				},
+			},
+			wantErr: `invalid match 1: condition header x-variant exact "" must have both name and value`,
+		},
+		{
+			name: "invalid regex",
+			matches: []*ModelEndpointRuleMatch{
+				{
+					Conditions:  []*ModelEndpointRuleMatchCondition{{Type: RuleMatchHeader, Name: "x-variant", MatchType: StringMatchRegex, Value: "("}},
+					Destination: destination,
+				},
+			},
+			wantErr: "invalid match 1: condition header x-variant regex \"(\" has invalid regex: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name: "cookie with prefix match",
+			matches: []*ModelEndpointRuleMatch{
+				{
+					Conditions:  []*ModelEndpointRuleMatchCondition{{Type: RuleMatchCookie, Name: "variant", MatchType: StringMatchPrefix, Value: "b"}},
+					Destination: destination,
+				},
+			},
+			wantErr: `invalid match 1: condition cookie variant prefix "b": cookie only supports exact match`,
+		},
+		{
+			name: "query with prefix match",
+			matches: []*ModelEndpointRuleMatch{
+				{
+					Conditions:  []*ModelEndpointRuleMatchCondition{{Type: RuleMatchQuery, Name: "debug", MatchType: StringMatchPrefix, Value: "t"}},
+					Destination: destination,
+				},
+			},
+			wantErr: `invalid match 1: condition query debug prefix "t": query parameter doesn't support prefix match`,
+		},
+		{
+			name: "conflicting conditions on the same header",
+			matches: []*ModelEndpointRuleMatch{
+				{
+					Conditions: []*ModelEndpointRuleMatchCondition{
+						{Type: RuleMatchHeader, Name: "X-Variant", Value: "beta"},
+						{Type: RuleMatchHeader, Name: "x-variant", Value: "alpha"},
+					},
+					Destination: destination,
+				},
+			},
+			wantErr: `invalid match 1: condition header x-variant exact "alpha" conflicts with another condition on the same header x-variant`,
+		},
+		{
+			name: "weights not summing up to 100",
+			matches: []*ModelEndpointRuleMatch{
+				{
+					Conditions:  []*ModelEndpointRuleMatchCondition{{Type: RuleMatchHeader, Name: "x-variant", Value: "beta"}},
+					Destination: []*ModelEndpointRuleDestination{{Weight: 50}},
+				},
+			},
+			wantErr: "invalid match 1: weights of match destinations must sum up to 100, got 50",
+		},
+		{
+			name: "ambiguous matches",
+			matches: []*ModelEndpointRuleMatch{
+				{
+					Conditions:  []*ModelEndpointRuleMatchCondition{{Type: RuleMatchHeader, Name: "X-Variant", Value: "beta"}},
+					Destination: destination,
+				},
+				{
+					Conditions: []*ModelEndpointRuleMatchCondition{
+						{Type: RuleMatchQuery, Name: "debug", Value: "true"},
+						{Type: RuleMatchHeader, Name: "x-variant", MatchType: StringMatchExact, Value: "beta"},
+					},
+					Destination: destination,
+				},
+			},
+			wantErr: "ambiguous matches: match 2 is never reached because match 1 accepts all of its requests",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &ModelEndpointRule{Destination: destination, Matches: tt.matches}
+			err := rule.ValidateMatches()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestModelEndpointRule_AllDestinations(t *testing.T) {
+	first := &ModelEndpointRuleDestination{Weight: 100}
+	second := &ModelEndpointRuleDestination{Weight: 80}
+	third := &ModelEndpointRuleDestination{Weight: 20}
+
+	rule := &ModelEndpointRule{
+		Destination: []*ModelEndpointRuleDestination{second, third},
+		Matches: []*ModelEndpointRuleMatch{
+			{Destination: []*ModelEndpointRuleDestination{first}},
+		},
+	}
+	assert.Equal(t, []*ModelEndpointRuleDestination{first, second, third}, rule.AllDestinations())
+}
+
+func TestModelEndpointRule_ValidateMirrors(t *testing.T) {
+	destination := &ModelEndpointRuleDestination{VersionEndpointID: uuid.New(), Weight: 100}
+	shadow := uuid.New()
+
+	tests := []struct {
+		name    string
+		mirrors []*ModelEndpointRuleMirror
+		wantErr string
+	}{
+		{
+			name: "no mirrors",
+		},
+		{
+			name: "valid mirrors",
+			mirrors: []*ModelEndpointRuleMirror{
+				{VersionEndpointID: shadow, Percentage: 100},
+				{VersionEndpointID: uuid.New(), Percentage: 5},
+			},
+		},
+		{
+			name:    "percentage out of range",
+			mirrors: []*ModelEndpointRuleMirror{{VersionEndpointID: shadow, Percentage: 0}},
+			wantErr: fmt.Sprintf("mirror percentage of version endpoint %s must be between 1 and 100, got 0", shadow),
+		},
+		{
+			name:    "destination mirrored",
+			mirrors: []*ModelEndpointRuleMirror{{VersionEndpointID: destination.VersionEndpointID, Percentage: 10}},
+			wantErr: fmt.Sprintf("version endpoint %s can't be both a destination and a mirror", destination.VersionEndpointID),
+		},
+		{
+			name: "version endpoint mirrored twice",
+			mirrors: []*ModelEndpointRuleMirror{
+				{VersionEndpointID: shadow, Percentage: 10},
+				{VersionEndpointID: shadow, Percentage: 20},
+			},
+			wantErr: fmt.Sprintf("version endpoint %s is mirrored more than once", shadow),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &ModelEndpointRule{Destination: []*ModelEndpointRuleDestination{destination}, Mirrors: tt.mirrors}
+			err := rule.ValidateMirrors()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestModelEndpointRule_MirrorTargets(t *testing.T) {
+	legacy := &VersionEndpoint{Id: uuid.New()}
+	rule := &ModelEndpointRule{Mirror: legacy}
+	assert.Equal(t, []*ModelEndpointRuleMirror{{VersionEndpointID: legacy.Id, VersionEndpoint: legacy, Percentage: 100}}, rule.MirrorTargets())
+
+	mirrors := []*ModelEndpointRuleMirror{{VersionEndpointID: uuid.New(), Percentage: 20}}
+	rule.Mirrors = mirrors
+	assert.Equal(t, mirrors, rule.MirrorTargets())
+}
@@ -47,6 +47,7 @@ type Config struct {
 	ImageRef           string                        `json:"image_ref"`
 	ResourceRequest    *PredictionJobResourceRequest `json:"resource_request"`
 	EnvVars            EnvVars                       `json:"env_vars"`
+	NodePool           string                        `json:"node_pool,omitempty"`
 }
 
 type PredictionJobResourceRequest struct {
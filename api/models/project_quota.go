@@ -0,0 +1,236 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/gojek/merlin/config"
+)
+
+// Resources limited by the project quotas
+const (
+	QuotaEndpoints         = "endpoints"
+	QuotaEndpointsPerModel = "endpoints_per_model"
+	QuotaCpu               = "cpu"
+	QuotaMemory            = "memory"
+	QuotaReplicas          = "replicas"
+	QuotaPredictionJobs    = "prediction_jobs"
+)
+
+// ProjectQuota limits the resources used by the version endpoints and prediction jobs of a project in an environment.
+type ProjectQuota struct {
+	Id              Id          `json:"id"`
+	ProjectId       Id          `json:"project_id"`
+	EnvironmentName string      `json:"environment_name"`
+	Limits          QuotaLimits `json:"limits"`
+	CreatedUpdated
+}
+
+// QuotaLimits are the maximum resources of a project quota, a nil limit is unlimited.
+type QuotaLimits struct {
+	Endpoints *int `json:"endpoints,omitempty"`
+	// Endpoints of each model of the project
+	EndpointsPerModel *int `json:"endpoints_per_model,omitempty"`
+	// CPU and memory requested by the version endpoints when they are scaled to their max replica
+	Cpu    *resource.Quantity `json:"cpu,omitempty"`
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// Replicas of the version endpoints when they are scaled to their max replica
+	Replicas       *int `json:"replicas,omitempty"`
+	PredictionJobs *int `json:"prediction_jobs,omitempty"`
+}
+
+// NewQuotaLimits parses the default quota of an environment. The endpoints of each model are limited to
+// config.MaxDeployedVersion unless the default quota sets another limit.
+func NewQuotaLimits(cfg config.QuotaConfig) (QuotaLimits, error) {
+	limits := QuotaLimits{
+		Endpoints:         cfg.MaxEndpoints,
+		EndpointsPerModel: cfg.MaxEndpointsPerModel,
+		Replicas:          cfg.MaxReplicas,
+		PredictionJobs:    cfg.MaxPredictionJobs,
+	}
+	if limits.EndpointsPerModel == nil {
+		maxDeployedVersion := config.MaxDeployedVersion
+		limits.EndpointsPerModel = &maxDeployedVersion
+	}
+	if cfg.MaxCpu != "" {
+		cpu, err := resource.ParseQuantity(cfg.MaxCpu)
+		if err != nil {
+			return QuotaLimits{}, fmt.Errorf("invalid max cpu %s: %v", cfg.MaxCpu, err)
+		}
+		limits.Cpu = &cpu
+	}
+	if cfg.MaxMemory != "" {
+		memory, err := resource.ParseQuantity(cfg.MaxMemory)
+		if err != nil {
+			return QuotaLimits{}, fmt.Errorf("invalid max memory %s: %v", cfg.MaxMemory, err)
+		}
+		limits.Memory = &memory
+	}
+	return limits, limits.Validate()
+}
+
+// IsUnlimited returns true if none of the resources is limited.
+func (l QuotaLimits) IsUnlimited() bool {
+	return l.Endpoints == nil && l.EndpointsPerModel == nil && l.Cpu == nil && l.Memory == nil && l.Replicas == nil && l.PredictionJobs == nil
+}
+
+// Validate checks that none of the limits is negative.
+func (l QuotaLimits) Validate() error {
+	for _, limit := range []*int{l.Endpoints, l.EndpointsPerModel, l.Replicas, l.PredictionJobs} {
+		if limit != nil && *limit < 0 {
+			return errors.New("quota limits can't be negative")
+		}
+	}
+	for _, limit := range []*resource.Quantity{l.Cpu, l.Memory} {
+		if limit != nil && limit.Sign() < 0 {
+			return errors.New("quota limits can't be negative")
+		}
+	}
+	return nil
+}
+
+// CheckIncrease returns a QuotaExceededError if the usage after a deployment exceeds a limit. A resource whose usage
+// doesn't increase is not checked, so that projects exceeding a lowered quota can still scale down.
+func (l QuotaLimits) CheckIncrease(before, after *QuotaUsage) error {
+	countLimits := []struct {
+		resource      string
+		limit         *int
+		before, after int
+	}{
+		{QuotaEndpoints, l.Endpoints, before.Endpoints, after.Endpoints},
+		{QuotaReplicas, l.Replicas, before.Replicas, after.Replicas},
+		{QuotaPredictionJobs, l.PredictionJobs, before.PredictionJobs, after.PredictionJobs},
+	}
+	for _, c := range countLimits {
+		if c.limit != nil && c.after > *c.limit && c.after > c.before {
+			return &QuotaExceededError{
+				Resource:       c.resource,
+				Usage:          strconv.Itoa(c.before),
+				RequestedUsage: strconv.Itoa(c.after),
+				Limit:          strconv.Itoa(*c.limit),
+			}
+		}
+	}
+
+	quantityLimits := []struct {
+		resource      string
+		limit         *resource.Quantity
+		before, after resource.Quantity
+	}{
+		{QuotaCpu, l.Cpu, before.Cpu, after.Cpu},
+		{QuotaMemory, l.Memory, before.Memory, after.Memory},
+	}
+	for _, q := range quantityLimits {
+		if q.limit != nil && q.after.Cmp(*q.limit) > 0 && q.after.Cmp(q.before) > 0 {
+			return &QuotaExceededError{
+				Resource:       q.resource,
+				Usage:          q.before.String(),
+				RequestedUsage: q.after.String(),
+				Limit:          q.limit.String(),
+			}
+		}
+	}
+	return nil
+}
+
+// CheckModelEndpointsIncrease returns a QuotaExceededError if the number of endpoints of a model after a deployment
+// exceeds the limit per model. As for CheckIncrease, the limit is not checked if the number doesn't increase.
+func (l QuotaLimits) CheckModelEndpointsIncrease(before, after int) error {
+	if l.EndpointsPerModel != nil && after > *l.EndpointsPerModel && after > before {
+		return &QuotaExceededError{
+			Resource:       QuotaEndpointsPerModel,
+			Usage:          strconv.Itoa(before),
+			RequestedUsage: strconv.Itoa(after),
+			Limit:          strconv.Itoa(*l.EndpointsPerModel),
+		}
+	}
+	return nil
+}
+
+func (l QuotaLimits) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+func (l *QuotaLimits) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &l)
+}
+
+// QuotaUsage is the resources used by the version endpoints and prediction jobs of a project in an environment.
+type QuotaUsage struct {
+	Endpoints      int               `json:"endpoints"`
+	Cpu            resource.Quantity `json:"cpu"`
+	Memory         resource.Quantity `json:"memory"`
+	Replicas       int               `json:"replicas"`
+	PredictionJobs int               `json:"prediction_jobs"`
+}
+
+// AddEndpoint adds the resources of the model, transformer and explainer of the version endpoint
+// scaled to their max replica.
+func (u *QuotaUsage) AddEndpoint(endpoint *VersionEndpoint) {
+	u.Endpoints++
+	u.addResourceRequest(endpoint.ResourceRequest)
+	if endpoint.Transformer != nil && endpoint.Transformer.Enabled {
+		u.addResourceRequest(endpoint.Transformer.ResourceRequest)
+	}
+	if endpoint.Explainer != nil && endpoint.Explainer.Enabled {
+		u.addResourceRequest(endpoint.Explainer.ResourceRequest)
+	}
+}
+
+func (u *QuotaUsage) addResourceRequest(resourceRequest *ResourceRequest) {
+	if resourceRequest == nil {
+		return
+	}
+
+	u.Replicas += resourceRequest.MaxReplica
+	for i := 0; i < resourceRequest.MaxReplica; i++ {
+		u.Cpu.Add(resourceRequest.CpuRequest)
+		u.Memory.Add(resourceRequest.MemoryRequest)
+	}
+}
+
+// QuotaStatus is the quota of a project in an environment along with its current usage.
+type QuotaStatus struct {
+	EnvironmentName string      `json:"environment_name"`
+	Limits          QuotaLimits `json:"limits"`
+	Usage           *QuotaUsage `json:"usage"`
+	// IsDefault is true if the project doesn't have its own quota and the default quota of the environment applies
+	IsDefault bool `json:"is_default"`
+}
+
+// QuotaExceededError is returned when a deployment would use more resources than allowed by the project quota.
+type QuotaExceededError struct {
+	Resource string
+	// Usage is the current usage of the project and RequestedUsage the usage it would reach
+	Usage          string
+	RequestedUsage string
+	Limit          string
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("project quota of %s exceeded: current usage is %s, requested usage is %s, limit is %s", e.Resource, e.Usage, e.RequestedUsage, e.Limit)
+}
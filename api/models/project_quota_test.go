@@ -0,0 +1,107 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/gojek/merlin/config"
+)
+
+func TestNewQuotaLimits(t *testing.T) {
+	maxEndpoints := 5
+	maxEndpointsPerModel := 3
+
+	limits, err := NewQuotaLimits(config.QuotaConfig{MaxEndpoints: &maxEndpoints, MaxEndpointsPerModel: &maxEndpointsPerModel, MaxCpu: "10", MaxMemory: "20Gi"})
+	assert.NoError(t, err)
+	assert.Equal(t, &maxEndpoints, limits.Endpoints)
+	assert.Equal(t, &maxEndpointsPerModel, limits.EndpointsPerModel)
+	assert.Equal(t, "10", limits.Cpu.String())
+	assert.Equal(t, "20Gi", limits.Memory.String())
+	assert.Nil(t, limits.Replicas)
+	assert.Nil(t, limits.PredictionJobs)
+
+	maxDeployedVersion := config.MaxDeployedVersion
+	limits, err = NewQuotaLimits(config.QuotaConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, QuotaLimits{EndpointsPerModel: &maxDeployedVersion}, limits)
+
+	_, err = NewQuotaLimits(config.QuotaConfig{MaxCpu: "ten"})
+	assert.Error(t, err)
+
+	_, err = NewQuotaLimits(config.QuotaConfig{MaxMemory: "-1Gi"})
+	assert.Error(t, err)
+}
+
+func TestQuotaLimits_ValueAndScan(t *testing.T) {
+	maxReplicas := 10
+	cpu := resource.MustParse("4")
+	limits := QuotaLimits{Cpu: &cpu, Replicas: &maxReplicas}
+
+	value, err := limits.Value()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"cpu": "4", "replicas": 10}`, string(value.([]byte)))
+
+	var scanned QuotaLimits
+	assert.NoError(t, scanned.Scan(value))
+	assert.Equal(t, "4", scanned.Cpu.String())
+	assert.Equal(t, &maxReplicas, scanned.Replicas)
+	assert.Nil(t, scanned.Memory)
+}
+
+func TestQuotaUsage_AddEndpoint(t *testing.T) {
+	resourceRequest := &ResourceRequest{
+		MinReplica:    1,
+		MaxReplica:    2,
+		CpuRequest:    resource.MustParse("500m"),
+		MemoryRequest: resource.MustParse("512Mi"),
+	}
+
+	usage := &QuotaUsage{}
+	usage.AddEndpoint(&VersionEndpoint{
+		ResourceRequest: resourceRequest,
+		Transformer:     &Transformer{Enabled: true, ResourceRequest: resourceRequest},
+		Explainer:       &Explainer{Enabled: false, ResourceRequest: resourceRequest},
+	})
+	usage.AddEndpoint(&VersionEndpoint{ResourceRequest: resourceRequest})
+
+	assert.Equal(t, 2, usage.Endpoints)
+	assert.Equal(t, 6, usage.Replicas)
+	assert.Equal(t, "3", usage.Cpu.String())
+	assert.Equal(t, "3Gi", usage.Memory.String())
+
+	b, err := json.Marshal(usage)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"endpoints": 2, "cpu": "3", "memory": "3Gi", "replicas": 6, "prediction_jobs": 0}`, string(b))
+}
+
+func TestQuotaLimits_CheckModelEndpointsIncrease(t *testing.T) {
+	maxEndpointsPerModel := 2
+	limits := QuotaLimits{EndpointsPerModel: &maxEndpointsPerModel}
+
+	assert.NoError(t, limits.CheckModelEndpointsIncrease(1, 2))
+	assert.NoError(t, limits.CheckModelEndpointsIncrease(3, 3))
+	assert.Equal(t, &QuotaExceededError{Resource: QuotaEndpointsPerModel, Usage: "2", RequestedUsage: "3", Limit: "2"}, limits.CheckModelEndpointsIncrease(2, 3))
+	assert.NoError(t, QuotaLimits{}.CheckModelEndpointsIncrease(2, 3))
+}
+
+func TestQuotaExceededError(t *testing.T) {
+	err := &QuotaExceededError{Resource: QuotaMemory, Usage: "4Gi", RequestedUsage: "6Gi", Limit: "5Gi"}
+	assert.Equal(t, "project quota of memory exceeded: current usage is 4Gi, requested usage is 6Gi, limit is 5Gi", err.Error())
+}
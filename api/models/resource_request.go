@@ -32,6 +32,21 @@ type ResourceRequest struct {
 	CpuRequest resource.Quantity `json:"cpu_request"`
 	// Memory request of inference service
 	MemoryRequest resource.Quantity `json:"memory_request"`
+
+	// CPU limit of inference service, defaults to twice the CPU request
+	CpuLimit *resource.Quantity `json:"cpu_limit,omitempty"`
+	// Memory limit of inference service, defaults to twice the memory request
+	MemoryLimit *resource.Quantity `json:"memory_limit,omitempty"`
+
+	// GPU request of inference service
+	GPURequest *GPURequest `json:"gpu_request,omitempty"`
+}
+
+type GPURequest struct {
+	// Name of the GPU type, one of the GPUs allowed in the environment
+	Name string `json:"name"`
+	// Number of GPU per replica
+	Count int64 `json:"count"`
 }
 
 func (r ResourceRequest) Value() (driver.Value, error) {
@@ -0,0 +1,118 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron"
+)
+
+// ScalingSchedule sets the min and max replicas of a version endpoint whenever its cron expression matches,
+// e.g. to scale up before the daily peak of traffic and down at night.
+type ScalingSchedule struct {
+	Id                Id        `json:"id"`
+	VersionEndpointId uuid.UUID `json:"version_endpoint_id"`
+	// Schedule is a cron expression with 5 fields, e.g. "0 7 * * 1-5", or a predefined schedule such as "@daily"
+	Schedule string `json:"schedule"`
+	// Timezone is the IANA time zone in which the schedule is evaluated, UTC by default
+	Timezone   string `json:"timezone,omitempty"`
+	MinReplica int    `json:"min_replica"`
+	MaxReplica int    `json:"max_replica"`
+	// LastAppliedAt is the scheduled time at which the replicas were last applied to the version endpoint
+	LastAppliedAt *time.Time `json:"last_applied_at,omitempty"`
+	CreatedUpdated
+}
+
+// Validate checks the cron expression, the time zone and the replicas of the schedule.
+func (s *ScalingSchedule) Validate() error {
+	if strings.HasPrefix(strings.TrimSpace(s.Schedule), "@every") {
+		return errors.New("interval schedules aren't supported, use a cron expression instead")
+	}
+	if _, err := s.Next(time.Now()); err != nil {
+		return err
+	}
+
+	if s.MinReplica < 0 {
+		return fmt.Errorf("min replica must not be negative, got %d", s.MinReplica)
+	}
+	if s.MaxReplica < 1 || s.MaxReplica < s.MinReplica {
+		return fmt.Errorf("max replica must be at least 1 and not lower than min replica, got %d", s.MaxReplica)
+	}
+	return nil
+}
+
+// Next returns the first time after t matching the schedule, in the time zone of the schedule.
+func (s *ScalingSchedule) Next(t time.Time) (time.Time, error) {
+	schedule, location, err := s.parse()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(t.In(location)), nil
+}
+
+// LastFired returns the latest time after since and not after now matching the schedule, and false if there is none.
+func (s *ScalingSchedule) LastFired(since, now time.Time) (time.Time, bool, error) {
+	schedule, location, err := s.parse()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	var firedAt time.Time
+	for next := schedule.Next(since.In(location)); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		firedAt = next
+	}
+	return firedAt, !firedAt.IsZero(), nil
+}
+
+func (s *ScalingSchedule) parse() (cron.Schedule, *time.Location, error) {
+	schedule, err := cron.ParseStandard(s.Schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule %s: %v", s.Schedule, err)
+	}
+
+	location := time.UTC
+	if s.Timezone != "" {
+		location, err = time.LoadLocation(s.Timezone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid timezone %s: %v", s.Timezone, err)
+		}
+	}
+	return schedule, location, nil
+}
+
+// ApplyTo returns a copy of the resource request with the replicas of the schedule.
+func (s *ScalingSchedule) ApplyTo(resourceRequest *ResourceRequest) *ResourceRequest {
+	scaled := &ResourceRequest{}
+	if resourceRequest != nil {
+		*scaled = *resourceRequest
+	}
+	scaled.MinReplica = s.MinReplica
+	scaled.MaxReplica = s.MaxReplica
+	return scaled
+}
+
+// Description returns the reason recorded for the changes made by the schedule.
+func (s *ScalingSchedule) Description() string {
+	description := fmt.Sprintf("scaling schedule %s \"%s\"", s.Id, s.Schedule)
+	if s.Timezone != "" {
+		description += " " + s.Timezone
+	}
+	return fmt.Sprintf("%s: %d to %d replicas", description, s.MinReplica, s.MaxReplica)
+}
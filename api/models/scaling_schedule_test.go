@@ -0,0 +1,127 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestScalingSchedule_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule *ScalingSchedule
+		wantErr  bool
+	}{
+		{
+			name:     "valid schedule",
+			schedule: &ScalingSchedule{Schedule: "0 7 * * 1-5", Timezone: "Asia/Jakarta", MinReplica: 2, MaxReplica: 10},
+		},
+		{
+			name:     "valid predefined schedule",
+			schedule: &ScalingSchedule{Schedule: "@midnight", MinReplica: 0, MaxReplica: 1},
+		},
+		{
+			name:     "invalid cron expression",
+			schedule: &ScalingSchedule{Schedule: "0 7 * *", MinReplica: 1, MaxReplica: 1},
+			wantErr:  true,
+		},
+		{
+			name:     "interval schedule",
+			schedule: &ScalingSchedule{Schedule: "@every 1h", MinReplica: 1, MaxReplica: 1},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid timezone",
+			schedule: &ScalingSchedule{Schedule: "0 7 * * *", Timezone: "Mars/Olympus", MinReplica: 1, MaxReplica: 1},
+			wantErr:  true,
+		},
+		{
+			name:     "negative min replica",
+			schedule: &ScalingSchedule{Schedule: "0 7 * * *", MinReplica: -1, MaxReplica: 1},
+			wantErr:  true,
+		},
+		{
+			name:     "max replica lower than min replica",
+			schedule: &ScalingSchedule{Schedule: "0 7 * * *", MinReplica: 3, MaxReplica: 2},
+			wantErr:  true,
+		},
+		{
+			name:     "zero max replica",
+			schedule: &ScalingSchedule{Schedule: "0 7 * * *", MinReplica: 0, MaxReplica: 0},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			assert.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+		})
+	}
+}
+
+func TestScalingSchedule_Next(t *testing.T) {
+	now := time.Date(2021, 3, 1, 0, 30, 0, 0, time.UTC)
+
+	schedule := &ScalingSchedule{Schedule: "0 7 * * *"}
+	next, err := schedule.Next(now)
+	assert.NoError(t, err)
+	assert.True(t, next.Equal(time.Date(2021, 3, 1, 7, 0, 0, 0, time.UTC)))
+
+	// 07:00 in Jakarta is 00:00 UTC, which has just passed
+	schedule.Timezone = "Asia/Jakarta"
+	next, err = schedule.Next(now)
+	assert.NoError(t, err)
+	assert.True(t, next.Equal(time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestScalingSchedule_LastFired(t *testing.T) {
+	now := time.Date(2021, 3, 3, 0, 30, 0, 0, time.UTC)
+	schedule := &ScalingSchedule{Schedule: "0 7 * * *", Timezone: "Asia/Jakarta"}
+
+	// the latest of the times missed since two days ago
+	firedAt, fired, err := schedule.LastFired(now.Add(-48*time.Hour), now)
+	assert.NoError(t, err)
+	assert.True(t, fired)
+	assert.True(t, firedAt.Equal(time.Date(2021, 3, 3, 0, 0, 0, 0, time.UTC)))
+
+	// the schedule fired exactly at since
+	_, fired, err = schedule.LastFired(time.Date(2021, 3, 3, 0, 0, 0, 0, time.UTC), now)
+	assert.NoError(t, err)
+	assert.False(t, fired)
+
+	_, _, err = (&ScalingSchedule{Schedule: "0 7 * *"}).LastFired(now.Add(-time.Hour), now)
+	assert.Error(t, err)
+}
+
+func TestScalingSchedule_ApplyTo(t *testing.T) {
+	resourceRequest := &ResourceRequest{MinReplica: 1, MaxReplica: 2, CpuRequest: resource.MustParse("1")}
+	schedule := &ScalingSchedule{MinReplica: 3, MaxReplica: 6}
+
+	scaled := schedule.ApplyTo(resourceRequest)
+	assert.Equal(t, &ResourceRequest{MinReplica: 3, MaxReplica: 6, CpuRequest: resource.MustParse("1")}, scaled)
+	// the original resource request is left as is
+	assert.Equal(t, 1, resourceRequest.MinReplica)
+	assert.Equal(t, 2, resourceRequest.MaxReplica)
+}
+
+func TestScalingSchedule_Description(t *testing.T) {
+	schedule := &ScalingSchedule{Id: 3, Schedule: "0 7 * * 1-5", Timezone: "Asia/Jakarta", MinReplica: 2, MaxReplica: 10}
+	assert.Equal(t, `scaling schedule 3 "0 7 * * 1-5" Asia/Jakarta: 2 to 10 replicas`, schedule.Description())
+}
@@ -17,27 +17,39 @@ package models
 import "fmt"
 
 type Service struct {
-	Name            string
-	Namespace       string
-	ServiceName     string
-	Url             string
-	ArtifactUri     string
-	Type            string
-	Options         *ModelOption
-	ResourceRequest *ResourceRequest
-	EnvVars         EnvVars
-	Metadata        Metadata
+	Name              string
+	Namespace         string
+	ServiceName       string
+	Url               string
+	ExplainerUrl      string
+	ArtifactUri       string
+	Type              string
+	Options           *ModelOption
+	ResourceRequest   *ResourceRequest
+	AutoscalingPolicy *AutoscalingPolicy
+	EnvVars           EnvVars
+	Transformer       *Transformer
+	Explainer         *Explainer
+	NodePool          string
+	// Secrets holds the data of the project secrets referenced by the environment variables, keyed by secret name.
+	// It is resolved at deployment and never stored.
+	Secrets  map[string]string
+	Metadata Metadata
 }
 
-func NewService(model *Model, version *Version, modelOpt *ModelOption, resource *ResourceRequest, envVars EnvVars, environment string) *Service {
+func NewService(model *Model, version *Version, modelOpt *ModelOption, endpoint *VersionEndpoint, environment string) *Service {
 	return &Service{
-		Name:            CreateInferenceServiceName(model.Name, version.Id.String()),
-		Namespace:       model.Project.Name,
-		ArtifactUri:     version.ArtifactUri,
-		Type:            model.Type,
-		Options:         modelOpt,
-		ResourceRequest: resource,
-		EnvVars:         envVars,
+		Name:              CreateInferenceServiceName(model.Name, version.Id.String()),
+		Namespace:         model.Project.Name,
+		ArtifactUri:       version.ArtifactUri,
+		Type:              model.Type,
+		Options:           modelOpt,
+		ResourceRequest:   endpoint.ResourceRequest,
+		AutoscalingPolicy: endpoint.AutoscalingPolicy,
+		EnvVars:           endpoint.EnvVars,
+		Transformer:       endpoint.Transformer,
+		Explainer:         endpoint.Explainer,
+		NodePool:          endpoint.NodePool,
 		Metadata: Metadata{
 			Team:        model.Project.Team,
 			Stream:      model.Project.Stream,
@@ -0,0 +1,170 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// retryConditions lists the conditions supported by Envoy's x-envoy-retry-on and x-envoy-retry-grpc-on headers.
+var retryConditions = map[string]bool{
+	"5xx":                    true,
+	"gateway-error":          true,
+	"reset":                  true,
+	"connect-failure":        true,
+	"retriable-4xx":          true,
+	"refused-stream":         true,
+	"retriable-status-codes": true,
+	"cancelled":              true,
+	"deadline-exceeded":      true,
+	"internal":               true,
+	"resource-exhausted":     true,
+	"unavailable":            true,
+}
+
+// TrafficPolicy describes the timeouts, retries and circuit breaking applied to the requests of a model endpoint.
+// Durations are expressed as Go durations, e.g. "500ms" or "2s".
+type TrafficPolicy struct {
+	// Timeout of a request, including its retries
+	Timeout          string            `json:"timeout,omitempty"`
+	Retries          *RetryPolicy      `json:"retries,omitempty"`
+	OutlierDetection *OutlierDetection `json:"outlier_detection,omitempty"`
+	ConnectionPool   *ConnectionPool   `json:"connection_pool,omitempty"`
+}
+
+type RetryPolicy struct {
+	// Number of retries of a request, 0 disables retries
+	Attempts int32 `json:"attempts"`
+	// Timeout of each attempt
+	PerTryTimeout string `json:"per_try_timeout,omitempty"`
+	// Conditions under which a request is retried, e.g. "5xx" or "connect-failure"
+	RetryOn []string `json:"retry_on,omitempty"`
+}
+
+// OutlierDetection ejects the destinations returning consecutive errors from the load balancing pool.
+type OutlierDetection struct {
+	ConsecutiveErrors  int32  `json:"consecutive_errors"`
+	Interval           string `json:"interval,omitempty"`
+	BaseEjectionTime   string `json:"base_ejection_time,omitempty"`
+	MaxEjectionPercent int32  `json:"max_ejection_percent,omitempty"`
+}
+
+// ConnectionPool limits the connections and requests made to the destinations.
+type ConnectionPool struct {
+	MaxConnections           int32  `json:"max_connections,omitempty"`
+	ConnectTimeout           string `json:"connect_timeout,omitempty"`
+	HTTP1MaxPendingRequests  int32  `json:"http1_max_pending_requests,omitempty"`
+	HTTP2MaxRequests         int32  `json:"http2_max_requests,omitempty"`
+	MaxRequestsPerConnection int32  `json:"max_requests_per_connection,omitempty"`
+	MaxRetries               int32  `json:"max_retries,omitempty"`
+}
+
+// HasDestinationPolicy returns true if the policy requires a DestinationRule, i.e. it has outlier detection or connection pool settings.
+func (p *TrafficPolicy) HasDestinationPolicy() bool {
+	return p != nil && (p.OutlierDetection != nil || p.ConnectionPool != nil)
+}
+
+func (p *TrafficPolicy) Validate() error {
+	timeout, err := parsePolicyDuration("timeout", p.Timeout)
+	if err != nil {
+		return err
+	}
+
+	if p.Retries != nil {
+		if p.Retries.Attempts < 0 {
+			return fmt.Errorf("retry attempts must not be negative, got %d", p.Retries.Attempts)
+		}
+
+		perTryTimeout, err := parsePolicyDuration("per_try_timeout", p.Retries.PerTryTimeout)
+		if err != nil {
+			return err
+		}
+		if timeout > 0 && perTryTimeout > timeout {
+			return fmt.Errorf("per_try_timeout %s must not be greater than timeout %s", p.Retries.PerTryTimeout, p.Timeout)
+		}
+
+		for _, condition := range p.Retries.RetryOn {
+			if !retryConditions[condition] {
+				return fmt.Errorf("unsupported retry condition %q", condition)
+			}
+		}
+	}
+
+	if p.OutlierDetection != nil {
+		if p.OutlierDetection.ConsecutiveErrors < 1 {
+			return fmt.Errorf("outlier detection consecutive_errors must be at least 1, got %d", p.OutlierDetection.ConsecutiveErrors)
+		}
+		if p.OutlierDetection.MaxEjectionPercent < 0 || p.OutlierDetection.MaxEjectionPercent > 100 {
+			return fmt.Errorf("outlier detection max_ejection_percent must be between 0 and 100, got %d", p.OutlierDetection.MaxEjectionPercent)
+		}
+		if _, err := parsePolicyDuration("interval", p.OutlierDetection.Interval); err != nil {
+			return err
+		}
+		if _, err := parsePolicyDuration("base_ejection_time", p.OutlierDetection.BaseEjectionTime); err != nil {
+			return err
+		}
+	}
+
+	if p.ConnectionPool != nil {
+		pool := p.ConnectionPool
+		if pool.MaxConnections < 0 || pool.HTTP1MaxPendingRequests < 0 || pool.HTTP2MaxRequests < 0 || pool.MaxRequestsPerConnection < 0 || pool.MaxRetries < 0 {
+			return errors.New("connection pool limits must not be negative")
+		}
+		if _, err := parsePolicyDuration("connect_timeout", pool.ConnectTimeout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RetryOnString returns the retry conditions in the comma separated format expected by Istio.
+func (p *RetryPolicy) RetryOnString() string {
+	return strings.Join(p.RetryOn, ",")
+}
+
+// parsePolicyDuration parses an optional positive duration, returning 0 if it's empty.
+func parsePolicyDuration(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", name, value, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s must be positive, got %s", name, value)
+	}
+	return d, nil
+}
+
+func (p TrafficPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *TrafficPolicy) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &p)
+}
@@ -0,0 +1,83 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrafficPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *TrafficPolicy
+		wantErr string
+	}{
+		{
+			name:   "empty policy",
+			policy: &TrafficPolicy{},
+		},
+		{
+			name: "valid policy",
+			policy: &TrafficPolicy{
+				Timeout:          "2s",
+				Retries:          &RetryPolicy{Attempts: 3, PerTryTimeout: "500ms", RetryOn: []string{"5xx", "connect-failure"}},
+				OutlierDetection: &OutlierDetection{ConsecutiveErrors: 5, Interval: "10s", BaseEjectionTime: "30s", MaxEjectionPercent: 50},
+				ConnectionPool:   &ConnectionPool{MaxConnections: 100, ConnectTimeout: "1s", HTTP2MaxRequests: 1000},
+			},
+		},
+		{
+			name:    "invalid timeout",
+			policy:  &TrafficPolicy{Timeout: "2 seconds"},
+			wantErr: `invalid timeout "2 seconds": time: unknown unit " seconds" in duration "2 seconds"`,
+		},
+		{
+			name:    "negative timeout",
+			policy:  &TrafficPolicy{Timeout: "-1s"},
+			wantErr: "timeout must be positive, got -1s",
+		},
+		{
+			name:    "per try timeout greater than timeout",
+			policy:  &TrafficPolicy{Timeout: "1s", Retries: &RetryPolicy{Attempts: 2, PerTryTimeout: "2s"}},
+			wantErr: "per_try_timeout 2s must not be greater than timeout 1s",
+		},
+		{
+			name:    "unsupported retry condition",
+			policy:  &TrafficPolicy{Retries: &RetryPolicy{Attempts: 2, RetryOn: []string{"always"}}},
+			wantErr: `unsupported retry condition "always"`,
+		},
+		{
+			name:    "outlier detection without consecutive errors",
+			policy:  &TrafficPolicy{OutlierDetection: &OutlierDetection{Interval: "10s"}},
+			wantErr: "outlier detection consecutive_errors must be at least 1, got 0",
+		},
+		{
+			name:    "negative connection pool limit",
+			policy:  &TrafficPolicy{ConnectionPool: &ConnectionPool{MaxConnections: -1}},
+			wantErr: "connection pool limits must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type TransformerType string
+
+const (
+	// CustomTransformerType runs a user-provided docker image
+	CustomTransformerType TransformerType = "custom"
+	// PyFuncTransformerType runs an image built by Merlin from the pyfunc transformer logged in the "transformer"
+	// directory of the model version's artifact
+	PyFuncTransformerType TransformerType = "pyfunc"
+)
+
+// Transformer pre-processes the requests and post-processes the responses of a version endpoint's model.
+type Transformer struct {
+	Enabled         bool             `json:"enabled"`
+	TransformerType TransformerType  `json:"transformer_type"`
+	Image           string           `json:"image,omitempty"`
+	Command         []string         `json:"command,omitempty"`
+	Args            []string         `json:"args,omitempty"`
+	ResourceRequest *ResourceRequest `json:"resource_request,omitempty"`
+	EnvVars         EnvVars          `json:"env_vars,omitempty"`
+}
+
+// Validate checks the transformer's configuration. A disabled transformer is always valid.
+func (t *Transformer) Validate() error {
+	if !t.Enabled {
+		return nil
+	}
+
+	switch t.TransformerType {
+	case CustomTransformerType:
+		if t.Image == "" {
+			return errors.New("custom transformer requires an image")
+		}
+	case PyFuncTransformerType:
+		if t.Image != "" {
+			return errors.New("pyfunc transformer image is built by merlin and can't be specified")
+		}
+	default:
+		return fmt.Errorf("unsupported transformer type: %s", t.TransformerType)
+	}
+	return t.EnvVars.Validate()
+}
+
+// PyfuncTransformerDefaultEnvVars returns the environment variables used by the pyfunc server to load the transformer.
+func PyfuncTransformerDefaultEnvVars(model Model, version Version, workers int64) EnvVars {
+	envVars := PyfuncDefaultEnvVars(model, version, workers)
+	for i := range envVars {
+		if envVars[i].Name == envModelDir {
+			envVars[i].Value = fmt.Sprintf("%s/transformer", version.ArtifactUri)
+		}
+	}
+	return envVars
+}
+
+func (t Transformer) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+func (t *Transformer) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &t)
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformer_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		transformer *Transformer
+		wantErr     string
+	}{
+		{
+			name:        "disabled",
+			transformer: &Transformer{Enabled: false},
+		},
+		{
+			name:        "custom",
+			transformer: &Transformer{Enabled: true, TransformerType: CustomTransformerType, Image: "ghcr.io/gojek/transformer:1.0.0"},
+		},
+		{
+			name:        "pyfunc",
+			transformer: &Transformer{Enabled: true, TransformerType: PyFuncTransformerType},
+		},
+		{
+			name:        "custom without image",
+			transformer: &Transformer{Enabled: true, TransformerType: CustomTransformerType},
+			wantErr:     "custom transformer requires an image",
+		},
+		{
+			name:        "pyfunc with image",
+			transformer: &Transformer{Enabled: true, TransformerType: PyFuncTransformerType, Image: "ghcr.io/gojek/transformer:1.0.0"},
+			wantErr:     "pyfunc transformer image is built by merlin and can't be specified",
+		},
+		{
+			name:        "unsupported type",
+			transformer: &Transformer{Enabled: true, TransformerType: "feast"},
+			wantErr:     "unsupported transformer type: feast",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.transformer.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPyfuncTransformerDefaultEnvVars(t *testing.T) {
+	model := Model{Name: "model"}
+	version := Version{Id: Id(1), ArtifactUri: "gs://bucket/mlflow/1/abc/artifacts"}
+
+	envVars := PyfuncTransformerDefaultEnvVars(model, version, 2)
+	assert.Contains(t, envVars, EnvVar{Name: envModelDir, Value: "gs://bucket/mlflow/1/abc/artifacts/transformer"})
+	assert.Contains(t, envVars, EnvVar{Name: envModelName, Value: "model-1"})
+}
+
+func TestTransformer_ValueScan(t *testing.T) {
+	transformer := Transformer{
+		Enabled:         true,
+		TransformerType: CustomTransformerType,
+		Image:           "ghcr.io/gojek/transformer:1.0.0",
+		Args:            []string{"--port", "8080"},
+		EnvVars:         EnvVars{{Name: "LOG_LEVEL", Value: "INFO"}},
+	}
+
+	value, err := transformer.Value()
+	assert.NoError(t, err)
+
+	var scanned Transformer
+	assert.NoError(t, scanned.Scan(value))
+	assert.Equal(t, transformer, scanned)
+}
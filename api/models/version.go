@@ -33,11 +33,14 @@ type Version struct {
 	ArtifactUri string             `json:"artifact_uri" gorm:"artifact_uri"`
 	Endpoints   []*VersionEndpoint `json:"endpoints" gorm:"foreignkey:VersionId,VersionModelId;association_foreignkey:Id,ModelId;"`
 	Properties  KV                 `json:"properties" gorm:"properties"`
+	// Container of the model version if the model is of the custom type
+	CustomPredictor *CustomPredictor `json:"custom_predictor,omitempty" gorm:"custom_predictor"`
 	CreatedUpdated
 }
 
 type VersionPatch struct {
-	Properties *KV `json:"properties,omitempty"`
+	Properties      *KV              `json:"properties,omitempty"`
+	CustomPredictor *CustomPredictor `json:"custom_predictor,omitempty"`
 }
 
 type KV map[string]interface{}
@@ -59,6 +62,9 @@ func (v *Version) Patch(patch *VersionPatch) {
 	if patch.Properties != nil {
 		v.Properties = *patch.Properties
 	}
+	if patch.CustomPredictor != nil {
+		v.CustomPredictor = patch.CustomPredictor
+	}
 }
 
 func (v *Version) BeforeCreate(scope *gorm.Scope) {
@@ -89,24 +95,12 @@ func (v *Version) GetEndpointByEnvironmentName(envName string) (endpoint *Versio
 	return nil, false
 }
 
+// ModelOption holds the type-specific options of a model version, derived by the model type handlers.
 type ModelOption struct {
-	PyFuncImageName       string
-	PyTorchModelClassName string
-}
-
-const DefaultPyTorchClassName = "PyTorchModel"
-
-func NewPyTorchModelOption(version *Version) *ModelOption {
-	// Fallback to default if it's empty
-	clsName, ok := version.Properties[PropertyPyTorchClassName]
-	if !ok {
-		return &ModelOption{PyTorchModelClassName: DefaultPyTorchClassName}
-	}
-
-	// Fallback to default if it's not castable to string
-	clsNameStr, ok := clsName.(string)
-	if !ok {
-		return &ModelOption{PyTorchModelClassName: DefaultPyTorchClassName}
-	}
-	return &ModelOption{PyTorchModelClassName: clsNameStr}
+	// Image built by merlin for the model types requiring an image build
+	PyFuncImageName       string `json:"pyfunc_image_name,omitempty"`
+	PyTorchModelClassName string `json:"pytorch_model_class_name,omitempty"`
+	// Image of the pyfunc transformer built by merlin
+	TransformerImageName string           `json:"transformer_image_name,omitempty"`
+	CustomPredictor      *CustomPredictor `json:"custom_predictor,omitempty"`
 }
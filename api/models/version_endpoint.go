@@ -17,6 +17,8 @@ package models
 import (
 	"fmt"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -24,23 +26,39 @@ import (
 	"github.com/gojek/merlin/mlp"
 )
 
+// ClusterLocalDomain is the domain of the Kubernetes services, which can't be reached from outside of their cluster
+const ClusterLocalDomain = "svc.cluster.local"
+
 type VersionEndpoint struct {
 	Id uuid.UUID `json:"id" gorm:"type:uuid;primary_key;"`
 	// The field name has to be prefixed with the related struct name
 	// in order for gorm Preload to work with association_foreignkey
-	VersionId            Id               `json:"version_id"`
-	VersionModelId       Id               `json:"model_id"`
-	Status               EndpointStatus   `json:"status"`
-	Url                  string           `json:"url" gorm:"url"`
-	ServiceName          string           `json:"service_name" gorm:"service_name"`
-	InferenceServiceName string           `json:"-" gorm:"inference_service_name"`
-	Namespace            string           `json:"-" gorm:"namespace"`
-	MonitoringUrl        string           `json:"monitoring_url,omitempty" gorm:"-"`
-	Environment          *Environment     `json:"environment" gorm:"association_foreignkey:Name;"`
-	EnvironmentName      string           `json:"environment_name"`
-	Message              string           `json:"message"`
-	ResourceRequest      *ResourceRequest `json:"resource_request" gorm:"resource_request"`
-	EnvVars              EnvVars          `json:"env_vars" gorm:"column:env_vars"`
+	VersionId            Id                 `json:"version_id"`
+	VersionModelId       Id                 `json:"model_id"`
+	Status               EndpointStatus     `json:"status"`
+	Url                  string             `json:"url" gorm:"url"`
+	ServiceName          string             `json:"service_name" gorm:"service_name"`
+	InferenceServiceName string             `json:"-" gorm:"inference_service_name"`
+	Namespace            string             `json:"-" gorm:"namespace"`
+	MonitoringUrl        string             `json:"monitoring_url,omitempty" gorm:"-"`
+	Environment          *Environment       `json:"environment" gorm:"association_foreignkey:Name;"`
+	EnvironmentName      string             `json:"environment_name"`
+	Message              string             `json:"message"`
+	ResourceRequest      *ResourceRequest   `json:"resource_request" gorm:"resource_request"`
+	AutoscalingPolicy    *AutoscalingPolicy `json:"autoscaling_policy,omitempty" gorm:"autoscaling_policy"`
+	EnvVars              EnvVars            `json:"env_vars" gorm:"column:env_vars"`
+	Transformer          *Transformer       `json:"transformer,omitempty" gorm:"transformer"`
+	Explainer            *Explainer         `json:"explainer,omitempty" gorm:"explainer"`
+	ExplainerUrl         string             `json:"explainer_url,omitempty" gorm:"explainer_url"`
+	NodePool             string             `json:"node_pool,omitempty" gorm:"node_pool"`
+	// TTL is the time after its deployment at which the endpoint is undeployed, e.g. "72h", empty means unlimited
+	TTL string `json:"ttl,omitempty" gorm:"column:ttl"`
+	// IdleTimeout is the time without any request after which the endpoint is undeployed, empty means unlimited
+	IdleTimeout string `json:"idle_timeout,omitempty" gorm:"column:idle_timeout"`
+	// DeployedAt is the time of the last deployment of the endpoint requested by the user
+	DeployedAt *time.Time `json:"deployed_at,omitempty"`
+	// ExpiryWarnedAt is the time at which the endpoint was warned to be undeployed because of its ttl or idle timeout
+	ExpiryWarnedAt *time.Time `json:"-"`
 
 	CreatedUpdated
 }
@@ -73,6 +91,54 @@ func (e *VersionEndpoint) IsServing() bool {
 	return e.Status == EndpointServing
 }
 
+// IsClusterLocal returns true if the endpoint is only reachable from inside its cluster, i.e. it's served by a
+// Kubernetes service instead of the ingress gateway, so the model endpoints can't route traffic to it.
+func (e *VersionEndpoint) IsClusterLocal() bool {
+	u, err := url.Parse(e.Url)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(u.Hostname(), "."+ClusterLocalDomain)
+}
+
+// MarkDeployed restarts the lifetime of the endpoint, its ttl counts from the given time.
+func (e *VersionEndpoint) MarkDeployed(now time.Time) {
+	e.DeployedAt = &now
+	e.ExpiryWarnedAt = nil
+}
+
+// ExpiresAt returns the time at which the ttl of the endpoint elapses, nil if its ttl is unlimited.
+func (e *VersionEndpoint) ExpiresAt() *time.Time {
+	ttl, err := parseLifetime(e.TTL)
+	if err != nil || ttl <= 0 || e.DeployedAt == nil {
+		return nil
+	}
+	expiresAt := e.DeployedAt.Add(ttl)
+	return &expiresAt
+}
+
+// IdleTimeoutDuration returns the idle timeout of the endpoint, 0 if it's unlimited.
+func (e *VersionEndpoint) IdleTimeoutDuration() time.Duration {
+	idleTimeout, err := parseLifetime(e.IdleTimeout)
+	if err != nil || idleTimeout < 0 {
+		return 0
+	}
+	return idleTimeout
+}
+
+// InvalidEndpointError is returned when the configuration of a version endpoint can't be deployed to its environment.
+type InvalidEndpointError struct {
+	Reason string
+}
+
+func NewInvalidEndpointError(format string, args ...interface{}) *InvalidEndpointError {
+	return &InvalidEndpointError{Reason: fmt.Sprintf(format, args...)}
+}
+
+func (e *InvalidEndpointError) Error() string {
+	return e.Reason
+}
+
 type EndpointMonitoringURLParams struct {
 	Cluster      string
 	Project      string
@@ -0,0 +1,59 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventTypeNormal  EventType = "normal"
+	EventTypeWarning EventType = "warning"
+)
+
+// Reasons of version endpoint events recorded by Merlin
+const (
+	EventReasonInferenceServiceDeleted  = "InferenceServiceDeleted"
+	EventReasonInferenceServiceNotReady = "InferenceServiceNotReady"
+	EventReasonInferenceServiceReady    = "InferenceServiceReady"
+	EventReasonReapplied                = "Reapplied"
+	EventReasonReapplyFailed            = "ReapplyFailed"
+	EventReasonScaled                   = "Scaled"
+	EventReasonScaleFailed              = "ScaleFailed"
+	EventReasonExpiring                 = "Expiring"
+	EventReasonExpired                  = "Expired"
+)
+
+// VersionEndpointEvent records a notable change of a version endpoint which doesn't come from user's request,
+// e.g. the inference service being modified outside of Merlin.
+type VersionEndpointEvent struct {
+	Id                Id        `json:"id"`
+	VersionEndpointId uuid.UUID `json:"version_endpoint_id"`
+	Type              EventType `json:"type"`
+	Reason            string    `json:"reason"`
+	Message           string    `json:"message"`
+	CreatedUpdated
+}
+
+func NewVersionEndpointEvent(endpoint *VersionEndpoint, eventType EventType, reason, message string) *VersionEndpointEvent {
+	return &VersionEndpointEvent{
+		VersionEndpointId: endpoint.Id,
+		Type:              eventType,
+		Reason:            reason,
+		Message:           message,
+	}
+}
@@ -128,3 +128,33 @@ func TestVersionEndpoint_UpdateMonitoringUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestVersionEndpoint_IsClusterLocal(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want bool
+	}{
+		{
+			"not deployed",
+			"",
+			false,
+		},
+		{
+			"inference service",
+			"my-model-1.my-project.models.example.com/v1/models/my-model-1",
+			false,
+		},
+		{
+			"kubernetes service",
+			"http://my-model-1.my-project.svc.cluster.local/v1/models/my-model-1",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &VersionEndpoint{Url: tt.url}
+			assert.Equal(t, tt.want, e.IsClusterLocal())
+		})
+	}
+}
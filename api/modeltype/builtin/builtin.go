@@ -0,0 +1,29 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package builtin registers the model types supported by merlin out of the box. A new model type is added by
+// implementing its modeltype.Handler in its own package and importing the package here.
+package builtin
+
+import (
+	// Register the built-in model types
+	_ "github.com/gojek/merlin/modeltype/custom"
+	_ "github.com/gojek/merlin/modeltype/onnx"
+	_ "github.com/gojek/merlin/modeltype/pyfunc"
+	_ "github.com/gojek/merlin/modeltype/pyfuncv2"
+	_ "github.com/gojek/merlin/modeltype/pytorch"
+	_ "github.com/gojek/merlin/modeltype/sklearn"
+	_ "github.com/gojek/merlin/modeltype/tensorflow"
+	_ "github.com/gojek/merlin/modeltype/xgboost"
+)
@@ -0,0 +1,54 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builtin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
+)
+
+func TestBuiltinModelTypes(t *testing.T) {
+	tests := []struct {
+		modelType          string
+		supportsOnline     bool
+		supportsBatch      bool
+		requiresImageBuild bool
+	}{
+		{modelType: models.ModelTypeCustom, supportsOnline: true},
+		{modelType: models.ModelTypeOnnx, supportsOnline: true},
+		{modelType: models.ModelTypePyFunc, supportsOnline: true, requiresImageBuild: true},
+		{modelType: models.ModelTypePyFuncV2, supportsBatch: true},
+		{modelType: models.ModelTypePyTorch, supportsOnline: true},
+		{modelType: models.ModelTypeSkLearn, supportsOnline: true},
+		{modelType: models.ModelTypeTensorflow, supportsOnline: true},
+		{modelType: models.ModelTypeXgboost, supportsOnline: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.modelType, func(t *testing.T) {
+			handler, err := modeltype.Get(tt.modelType)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.modelType, handler.Type())
+			assert.Equal(t, tt.supportsOnline, handler.SupportsOnline())
+			assert.Equal(t, tt.supportsBatch, handler.SupportsBatch())
+			assert.Equal(t, tt.requiresImageBuild, handler.RequiresImageBuild())
+		})
+	}
+
+	assert.Len(t, modeltype.Types(), len(tests))
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package custom registers the custom model type, served by a container built and pushed by the user.
+package custom
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	v1 "k8s.io/api/core/v1"
+
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
+)
+
+// Port of the container if none is specified
+const defaultPort = "8080"
+
+var ErrMissingCustomPredictor = errors.New("custom model version requires a custom predictor")
+
+func init() {
+	modeltype.Register(handler{})
+}
+
+type handler struct {
+	modeltype.BaseHandler
+}
+
+func (handler) Type() string {
+	return models.ModelTypeCustom
+}
+
+// ModelOption returns the container of the model version.
+func (handler) ModelOption(version *models.Version) *models.ModelOption {
+	return &models.ModelOption{CustomPredictor: version.CustomPredictor}
+}
+
+// ValidateOptions checks that the model version has a valid custom predictor.
+func (handler) ValidateOptions(options *models.ModelOption) error {
+	if options == nil || options.CustomPredictor == nil {
+		return ErrMissingCustomPredictor
+	}
+	return options.CustomPredictor.Validate()
+}
+
+// Annotations enables the scraping of the metrics exposed on the container port.
+func (handler) Annotations(modelService *models.Service) map[string]string {
+	predictor := modelService.Options.CustomPredictor
+	if len(predictor.Ports) == 0 {
+		return modeltype.PrometheusAnnotations(defaultPort)
+	}
+	return modeltype.PrometheusAnnotations(strconv.Itoa(int(predictor.Ports[0].Port)))
+}
+
+func (handler) PredictorSpec(modelService *models.Service, resources v1.ResourceRequirements) kfsv1alpha2.PredictorSpec {
+	return kfsv1alpha2.PredictorSpec{
+		Custom: &kfsv1alpha2.CustomSpec{
+			Container: createContainer(modelService, resources),
+		},
+	}
+}
+
+func (handler) V1beta1PredictorSpec(modelService *models.Service, resources v1.ResourceRequirements) servingv1beta1.PredictorSpec {
+	return servingv1beta1.PredictorSpec{
+		PodSpec: servingv1beta1.PodSpec{
+			Containers: []v1.Container{createContainer(modelService, resources)},
+		},
+	}
+}
+
+// URL returns the URL of the predict path of the container, which defaults to the KFServing predict path.
+func (handler) URL(modelURL string, options *models.ModelOption) (string, error) {
+	predictor := options.CustomPredictor
+	if predictor.PredictPath == "" {
+		return modelURL + modeltype.PredictPathSuffix, nil
+	}
+
+	u, err := url.Parse(modelURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = predictor.PredictPath
+	return u.String(), nil
+}
+
+// PredictPath returns the path of the URL, which is already the predict path of the container.
+func (handler) PredictPath(urlPath string) string {
+	return urlPath
+}
+
+// createContainer returns the container of the custom predictor.
+func createContainer(modelService *models.Service, resources v1.ResourceRequirements) v1.Container {
+	predictor := modelService.Options.CustomPredictor
+
+	container := v1.Container{
+		Image:          predictor.Image,
+		Command:        predictor.Command,
+		Args:           predictor.Args,
+		Env:            modelService.EnvVars.ToKubernetesEnvVars(),
+		Resources:      resources,
+		LivenessProbe:  createProbe(predictor.LivenessProbe),
+		ReadinessProbe: createProbe(predictor.ReadinessProbe),
+	}
+	for _, port := range predictor.Ports {
+		container.Ports = append(container.Ports, v1.ContainerPort{
+			Name:          port.Name,
+			ContainerPort: port.Port,
+			Protocol:      v1.Protocol(port.Protocol),
+		})
+	}
+	return container
+}
+
+// createProbe returns the probe of the container. The port of the probe is left empty as Knative probes
+// the container port.
+func createProbe(probe *models.Probe) *v1.Probe {
+	if probe == nil {
+		return nil
+	}
+
+	handler := v1.Handler{TCPSocket: &v1.TCPSocketAction{}}
+	if probe.Path != "" {
+		handler = v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: probe.Path}}
+	}
+	return &v1.Probe{
+		Handler:             handler,
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		FailureThreshold:    probe.FailureThreshold,
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package custom
+
+import (
+	"testing"
+
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+)
+
+func TestHandler_ModelOption(t *testing.T) {
+	predictor := &models.CustomPredictor{Image: "gojek/triton-model:1"}
+
+	option := handler{}.ModelOption(&models.Version{CustomPredictor: predictor})
+	assert.Equal(t, predictor, option.CustomPredictor)
+}
+
+func TestHandler_ValidateOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *models.ModelOption
+		wantErr string
+	}{
+		{
+			name:    "valid custom predictor",
+			options: &models.ModelOption{CustomPredictor: &models.CustomPredictor{Image: "gojek/triton-model:1"}},
+		},
+		{
+			name:    "missing custom predictor",
+			options: &models.ModelOption{},
+			wantErr: "custom model version requires a custom predictor",
+		},
+		{
+			name:    "invalid custom predictor",
+			options: &models.ModelOption{CustomPredictor: &models.CustomPredictor{}},
+			wantErr: "image is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := handler{}.ValidateOptions(tt.options)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestHandler_URL(t *testing.T) {
+	tests := []struct {
+		name        string
+		predictPath string
+		want        string
+	}{
+		{
+			name: "default predict path",
+			want: "http://my-model-1.project.example.com/v1/models/my-model-1:predict",
+		},
+		{
+			name:        "custom predict path",
+			predictPath: "/v2/models/my-model/infer",
+			want:        "http://my-model-1.project.example.com/v2/models/my-model/infer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := handler{}.URL("http://my-model-1.project.example.com/v1/models/my-model-1", &models.ModelOption{
+				CustomPredictor: &models.CustomPredictor{Image: "gojek/my-model:1", PredictPath: tt.predictPath},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHandler_PredictPath(t *testing.T) {
+	// the URL of the version endpoint already includes the predict path
+	assert.Equal(t, "/v2/models/my-model/infer", handler{}.PredictPath("/v2/models/my-model/infer"))
+}
+
+func TestHandler_Annotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		ports    []models.ContainerPort
+		wantPort string
+	}{
+		{
+			name:     "default port",
+			wantPort: "8080",
+		},
+		{
+			name:     "container port",
+			ports:    []models.ContainerPort{{Port: 8000}},
+			wantPort: "8000",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modelService := &models.Service{
+				Options: &models.ModelOption{
+					CustomPredictor: &models.CustomPredictor{Image: "gojek/triton-model:1", Ports: tt.ports},
+				},
+			}
+			annotations := handler{}.Annotations(modelService)
+			assert.Equal(t, tt.wantPort, annotations["prometheus.io/port"])
+		})
+	}
+}
+
+func TestHandler_PredictorSpec(t *testing.T) {
+	modelService := &models.Service{
+		Options: &models.ModelOption{
+			CustomPredictor: &models.CustomPredictor{
+				Image:          "gojek/triton-model:1",
+				Command:        []string{"tritonserver"},
+				Args:           []string{"--model-repository=/models"},
+				Ports:          []models.ContainerPort{{Name: "http1", Port: 8000}},
+				LivenessProbe:  &models.Probe{Path: "/v2/health/live", PeriodSeconds: 10},
+				ReadinessProbe: &models.Probe{InitialDelaySeconds: 5},
+			},
+		},
+		EnvVars: models.EnvVars{{Name: "LOG_VERBOSE", Value: "1"}},
+	}
+
+	spec := handler{}.PredictorSpec(modelService, v1.ResourceRequirements{})
+	assert.Equal(t, kfsv1alpha2.PredictorSpec{
+		Custom: &kfsv1alpha2.CustomSpec{
+			Container: v1.Container{
+				Image:   "gojek/triton-model:1",
+				Command: []string{"tritonserver"},
+				Args:    []string{"--model-repository=/models"},
+				Ports:   []v1.ContainerPort{{Name: "http1", ContainerPort: 8000}},
+				Env:     []v1.EnvVar{{Name: "LOG_VERBOSE", Value: "1"}},
+				LivenessProbe: &v1.Probe{
+					Handler:       v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/v2/health/live"}},
+					PeriodSeconds: 10,
+				},
+				ReadinessProbe: &v1.Probe{
+					Handler:             v1.Handler{TCPSocket: &v1.TCPSocketAction{}},
+					InitialDelaySeconds: 5,
+				},
+			},
+		},
+	}, spec)
+}
+
+func TestHandler_V1beta1PredictorSpec(t *testing.T) {
+	modelService := &models.Service{
+		Options: &models.ModelOption{
+			CustomPredictor: &models.CustomPredictor{
+				Image: "gojek/triton-model:1",
+				Ports: []models.ContainerPort{{Name: "http1", Port: 8000}},
+			},
+		},
+		EnvVars: models.EnvVars{{Name: "LOG_VERBOSE", Value: "1"}},
+	}
+
+	spec := handler{}.V1beta1PredictorSpec(modelService, v1.ResourceRequirements{})
+	assert.Equal(t, servingv1beta1.PredictorSpec{
+		PodSpec: servingv1beta1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Image: "gojek/triton-model:1",
+					Ports: []v1.ContainerPort{{Name: "http1", ContainerPort: 8000}},
+					Env:   []v1.EnvVar{{Name: "LOG_VERBOSE", Value: "1"}},
+				},
+			},
+		},
+	}, spec)
+}
@@ -0,0 +1,196 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modeltype defines the handlers of the model types supported by merlin. Each model type is implemented
+// in its own package, which registers its handler when it's imported. The built-in model types are registered
+// by importing the builtin package.
+package modeltype
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	v1 "k8s.io/api/core/v1"
+
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+)
+
+const (
+	AnnotationPrometheusScrapeFlag = "prometheus.io/scrape"
+	AnnotationPrometheusScrapePort = "prometheus.io/port"
+
+	// PredictPathSuffix is the suffix of the KFServing model path handling the prediction requests
+	PredictPathSuffix = ":predict"
+)
+
+// Handler handles the deployment of the models of a model type.
+type Handler interface {
+	// Type returns the model type handled, as stored in models.Model.
+	Type() string
+	// SupportsOnline returns true if the model can be deployed as a version endpoint.
+	SupportsOnline() bool
+	// SupportsBatch returns true if the model can be used by prediction jobs.
+	SupportsBatch() bool
+	// RequiresImageBuild returns true if merlin builds the image of the model's version endpoints from the
+	// version's artifact. The built image is passed to PredictorSpec in models.ModelOption.PyFuncImageName.
+	RequiresImageBuild() bool
+	// ModelOption derives the type-specific options of the model version.
+	ModelOption(version *models.Version) *models.ModelOption
+	// DefaultEnvVars returns the environment variables set on the version endpoints of the model, which can
+	// only be overridden by the user if they aren't protected.
+	DefaultEnvVars(model models.Model, version models.Version) models.EnvVars
+	// ValidateOptions checks that the options derived from the model version are enough to deploy it, e.g. that
+	// a custom model version has a custom predictor.
+	ValidateOptions(options *models.ModelOption) error
+	// Annotations returns the type-specific annotations of the model service's inference service.
+	Annotations(modelService *models.Service) map[string]string
+	// PredictorSpec returns the KFServing v1alpha2 predictor of the model service.
+	PredictorSpec(modelService *models.Service, resources v1.ResourceRequirements) kfsv1alpha2.PredictorSpec
+	// V1beta1PredictorSpec returns the KFServing v1beta1 predictor of the model service. The replicas and the
+	// pod scheduling of the predictor are set by the cluster controller.
+	V1beta1PredictorSpec(modelService *models.Service, resources v1.ResourceRequirements) servingv1beta1.PredictorSpec
+	// URL returns the URL of the version endpoint from the URL of the KFServing model of the model service.
+	URL(modelURL string, options *models.ModelOption) (string, error)
+	// PredictPath returns the path of the prediction requests from the path of the version endpoint's URL.
+	PredictPath(urlPath string) string
+}
+
+// BaseHandler implements the defaults of the optional behaviours of a Handler: online only, no image build,
+// no options, env vars nor annotations, served by the KFServing model servers. Handlers embed it and override
+// what they need.
+type BaseHandler struct{}
+
+func (BaseHandler) SupportsOnline() bool {
+	return true
+}
+
+func (BaseHandler) SupportsBatch() bool {
+	return false
+}
+
+func (BaseHandler) RequiresImageBuild() bool {
+	return false
+}
+
+func (BaseHandler) ModelOption(*models.Version) *models.ModelOption {
+	return &models.ModelOption{}
+}
+
+func (BaseHandler) DefaultEnvVars(models.Model, models.Version) models.EnvVars {
+	return nil
+}
+
+func (BaseHandler) ValidateOptions(*models.ModelOption) error {
+	return nil
+}
+
+func (BaseHandler) Annotations(*models.Service) map[string]string {
+	return nil
+}
+
+// URL returns the URL of the KFServing model, the version endpoints are served by the KFServing model servers.
+func (BaseHandler) URL(modelURL string, _ *models.ModelOption) (string, error) {
+	return modelURL, nil
+}
+
+// PredictPath returns the predict path of the KFServing model.
+func (BaseHandler) PredictPath(urlPath string) string {
+	if strings.HasSuffix(urlPath, PredictPathSuffix) {
+		return urlPath
+	}
+	return urlPath + PredictPathSuffix
+}
+
+// PrometheusAnnotations returns the annotations enabling the scraping of the metrics exposed on the port.
+func PrometheusAnnotations(port string) map[string]string {
+	return map[string]string{
+		AnnotationPrometheusScrapeFlag: "true",
+		AnnotationPrometheusScrapePort: port,
+	}
+}
+
+// PredictorExtensionSpec returns the KFServing v1beta1 spec of the model servers loading the model from the storage URI.
+func PredictorExtensionSpec(storageURI string, resources v1.ResourceRequirements) servingv1beta1.PredictorExtensionSpec {
+	return servingv1beta1.PredictorExtensionSpec{
+		StorageURI: &storageURI,
+		Container:  v1.Container{Resources: resources},
+	}
+}
+
+// Registry holds the handlers of the supported model types.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: map[string]Handler{}}
+}
+
+// Register adds the handler to the registry. It panics if a handler of the same model type is already registered.
+func (r *Registry) Register(handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.handlers[handler.Type()]; ok {
+		panic(fmt.Sprintf("model type %s is already registered", handler.Type()))
+	}
+	r.handlers[handler.Type()] = handler
+}
+
+// Get returns the handler of the model type.
+func (r *Registry) Get(modelType string) (Handler, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	handler, ok := r.handlers[modelType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported model type: %s", modelType)
+	}
+	return handler, nil
+}
+
+// Types returns the registered model types in alphabetical order.
+func (r *Registry) Types() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.handlers))
+	for modelType := range r.handlers {
+		types = append(types, modelType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+var defaultRegistry = NewRegistry()
+
+// Register adds the handler to the default registry, it's called by the model type packages when they're imported.
+func Register(handler Handler) {
+	defaultRegistry.Register(handler)
+}
+
+// Get returns the handler of the model type from the default registry.
+func Get(modelType string) (Handler, error) {
+	return defaultRegistry.Get(modelType)
+}
+
+// Types returns the model types of the default registry.
+func Types() []string {
+	return defaultRegistry.Types()
+}
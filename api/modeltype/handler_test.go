@@ -0,0 +1,87 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modeltype
+
+import (
+	"testing"
+
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+)
+
+type testHandler struct {
+	BaseHandler
+	modelType string
+}
+
+func (h testHandler) Type() string {
+	return h.modelType
+}
+
+func (testHandler) PredictorSpec(*models.Service, v1.ResourceRequirements) kfsv1alpha2.PredictorSpec {
+	return kfsv1alpha2.PredictorSpec{}
+}
+
+func (testHandler) V1beta1PredictorSpec(*models.Service, v1.ResourceRequirements) servingv1beta1.PredictorSpec {
+	return servingv1beta1.PredictorSpec{}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(testHandler{modelType: "triton"})
+	registry.Register(testHandler{modelType: "java"})
+
+	handler, err := registry.Get("triton")
+	assert.NoError(t, err)
+	assert.Equal(t, "triton", handler.Type())
+
+	_, err = registry.Get("h2o")
+	assert.EqualError(t, err, "unsupported model type: h2o")
+
+	assert.Equal(t, []string{"java", "triton"}, registry.Types())
+
+	assert.Panics(t, func() {
+		registry.Register(testHandler{modelType: "triton"})
+	})
+}
+
+func TestBaseHandler(t *testing.T) {
+	handler := testHandler{modelType: "triton"}
+
+	assert.True(t, handler.SupportsOnline())
+	assert.False(t, handler.SupportsBatch())
+	assert.False(t, handler.RequiresImageBuild())
+	assert.Equal(t, &models.ModelOption{}, handler.ModelOption(&models.Version{}))
+	assert.Nil(t, handler.DefaultEnvVars(models.Model{}, models.Version{}))
+	assert.Nil(t, handler.Annotations(&models.Service{}))
+	assert.NoError(t, handler.ValidateOptions(&models.ModelOption{}))
+
+	url, err := handler.URL("http://my-model-1.project.example.com/v1/models/my-model-1", &models.ModelOption{})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://my-model-1.project.example.com/v1/models/my-model-1", url)
+	assert.Equal(t, "/v1/models/my-model-1:predict", handler.PredictPath("/v1/models/my-model-1"))
+	assert.Equal(t, "/v1/models/my-model-1:predict", handler.PredictPath("/v1/models/my-model-1:predict"))
+}
+
+func TestPrometheusAnnotations(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   "8000",
+	}, PrometheusAnnotations("8000"))
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pyfunc registers the pyfunc model type, served by an image that merlin builds from the model
+// version's artifact.
+package pyfunc
+
+import (
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	v1 "k8s.io/api/core/v1"
+
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
+)
+
+const (
+	defaultWorkers = 1
+	prometheusPort = "8080"
+)
+
+func init() {
+	modeltype.Register(handler{})
+}
+
+type handler struct {
+	modeltype.BaseHandler
+}
+
+func (handler) Type() string {
+	return models.ModelTypePyFunc
+}
+
+func (handler) RequiresImageBuild() bool {
+	return true
+}
+
+// DefaultEnvVars returns the environment variables used by the pyfunc server to load the model.
+func (handler) DefaultEnvVars(model models.Model, version models.Version) models.EnvVars {
+	return models.PyfuncDefaultEnvVars(model, version, defaultWorkers)
+}
+
+func (handler) Annotations(*models.Service) map[string]string {
+	return modeltype.PrometheusAnnotations(prometheusPort)
+}
+
+func (handler) PredictorSpec(modelService *models.Service, resources v1.ResourceRequirements) kfsv1alpha2.PredictorSpec {
+	return kfsv1alpha2.PredictorSpec{
+		Custom: &kfsv1alpha2.CustomSpec{
+			Container: createContainer(modelService, resources),
+		},
+	}
+}
+
+func (handler) V1beta1PredictorSpec(modelService *models.Service, resources v1.ResourceRequirements) servingv1beta1.PredictorSpec {
+	return servingv1beta1.PredictorSpec{
+		PodSpec: servingv1beta1.PodSpec{
+			Containers: []v1.Container{createContainer(modelService, resources)},
+		},
+	}
+}
+
+// createContainer returns the container running the image built for the model version.
+func createContainer(modelService *models.Service, resources v1.ResourceRequirements) v1.Container {
+	return v1.Container{
+		Image:     modelService.Options.PyFuncImageName,
+		Env:       modelService.EnvVars.ToKubernetesEnvVars(),
+		Resources: resources,
+	}
+}
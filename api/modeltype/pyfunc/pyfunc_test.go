@@ -0,0 +1,78 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pyfunc
+
+import (
+	"testing"
+
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+)
+
+func TestHandler_DefaultEnvVars(t *testing.T) {
+	envVars := handler{}.DefaultEnvVars(models.Model{Name: "model"}, models.Version{Id: models.Id(1), ArtifactUri: "gs://bucket/artifacts"})
+	assert.Equal(t, models.EnvVars{
+		{Name: "MODEL_NAME", Value: "model-1"},
+		{Name: "MODEL_DIR", Value: "gs://bucket/artifacts/model"},
+		{Name: "WORKERS", Value: "1"},
+	}, envVars)
+}
+
+func TestHandler_Annotations(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   "8080",
+	}, handler{}.Annotations(&models.Service{}))
+}
+
+func TestHandler_PredictorSpec(t *testing.T) {
+	modelService := &models.Service{
+		Options: &models.ModelOption{PyFuncImageName: "gojek/project-model:1"},
+		EnvVars: models.EnvVars{{Name: "WORKERS", Value: "2"}},
+	}
+
+	spec := handler{}.PredictorSpec(modelService, v1.ResourceRequirements{})
+	assert.Equal(t, kfsv1alpha2.PredictorSpec{
+		Custom: &kfsv1alpha2.CustomSpec{
+			Container: v1.Container{
+				Image: "gojek/project-model:1",
+				Env:   []v1.EnvVar{{Name: "WORKERS", Value: "2"}},
+			},
+		},
+	}, spec)
+}
+
+func TestHandler_V1beta1PredictorSpec(t *testing.T) {
+	modelService := &models.Service{
+		Options: &models.ModelOption{PyFuncImageName: "gojek/project-model:1"},
+		EnvVars: models.EnvVars{{Name: "WORKERS", Value: "2"}},
+	}
+
+	spec := handler{}.V1beta1PredictorSpec(modelService, v1.ResourceRequirements{})
+	assert.Equal(t, servingv1beta1.PredictorSpec{
+		PodSpec: servingv1beta1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Image: "gojek/project-model:1",
+					Env:   []v1.EnvVar{{Name: "WORKERS", Value: "2"}},
+				},
+			},
+		},
+	}, spec)
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pyfuncv2 registers the pyfunc_v2 model type, which is only supported by prediction jobs.
+package pyfuncv2
+
+import (
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	v1 "k8s.io/api/core/v1"
+
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
+)
+
+func init() {
+	modeltype.Register(handler{})
+}
+
+type handler struct {
+	modeltype.BaseHandler
+}
+
+func (handler) Type() string {
+	return models.ModelTypePyFuncV2
+}
+
+func (handler) SupportsOnline() bool {
+	return false
+}
+
+func (handler) SupportsBatch() bool {
+	return true
+}
+
+// PredictorSpec returns an empty predictor since pyfunc_v2 models can't be deployed as version endpoints.
+func (handler) PredictorSpec(*models.Service, v1.ResourceRequirements) kfsv1alpha2.PredictorSpec {
+	return kfsv1alpha2.PredictorSpec{}
+}
+
+// V1beta1PredictorSpec returns an empty predictor since pyfunc_v2 models can't be deployed as version endpoints.
+func (handler) V1beta1PredictorSpec(*models.Service, v1.ResourceRequirements) servingv1beta1.PredictorSpec {
+	return servingv1beta1.PredictorSpec{}
+}
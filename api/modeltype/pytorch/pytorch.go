@@ -0,0 +1,71 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pytorch registers the pytorch model type, served by the KFServing PyTorch server from the model
+// version's artifact.
+package pytorch
+
+import (
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	v1 "k8s.io/api/core/v1"
+
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
+	"github.com/gojek/merlin/utils"
+)
+
+// Class name of the model if the version doesn't have the models.PropertyPyTorchClassName property
+const defaultClassName = "PyTorchModel"
+
+func init() {
+	modeltype.Register(handler{})
+}
+
+type handler struct {
+	modeltype.BaseHandler
+}
+
+func (handler) Type() string {
+	return models.ModelTypePyTorch
+}
+
+// ModelOption returns the class name of the model, stored in the version's properties.
+func (handler) ModelOption(version *models.Version) *models.ModelOption {
+	// Fallback to default if it's empty or not castable to string
+	className, ok := version.Properties[models.PropertyPyTorchClassName].(string)
+	if !ok {
+		return &models.ModelOption{PyTorchModelClassName: defaultClassName}
+	}
+	return &models.ModelOption{PyTorchModelClassName: className}
+}
+
+func (handler) PredictorSpec(modelService *models.Service, resources v1.ResourceRequirements) kfsv1alpha2.PredictorSpec {
+	return kfsv1alpha2.PredictorSpec{
+		PyTorch: &kfsv1alpha2.PyTorchSpec{
+			StorageURI:     utils.CreateModelLocation(modelService.ArtifactUri),
+			ModelClassName: modelService.Options.PyTorchModelClassName,
+			Resources:      resources,
+		},
+	}
+}
+
+func (handler) V1beta1PredictorSpec(modelService *models.Service, resources v1.ResourceRequirements) servingv1beta1.PredictorSpec {
+	return servingv1beta1.PredictorSpec{
+		PyTorch: &servingv1beta1.TorchServeSpec{
+			ModelClassName:         modelService.Options.PyTorchModelClassName,
+			PredictorExtensionSpec: modeltype.PredictorExtensionSpec(utils.CreateModelLocation(modelService.ArtifactUri), resources),
+		},
+	}
+}
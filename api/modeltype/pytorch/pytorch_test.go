@@ -0,0 +1,88 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pytorch
+
+import (
+	"testing"
+
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+)
+
+func TestHandler_ModelOption(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties models.KV
+		want       string
+	}{
+		{
+			name:       "class name property",
+			properties: models.KV{models.PropertyPyTorchClassName: "MyModel"},
+			want:       "MyModel",
+		},
+		{
+			name: "no class name property",
+			want: "PyTorchModel",
+		},
+		{
+			name:       "class name property isn't a string",
+			properties: models.KV{models.PropertyPyTorchClassName: 1},
+			want:       "PyTorchModel",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			option := handler{}.ModelOption(&models.Version{Properties: tt.properties})
+			assert.Equal(t, tt.want, option.PyTorchModelClassName)
+		})
+	}
+}
+
+func TestHandler_PredictorSpec(t *testing.T) {
+	modelService := &models.Service{
+		ArtifactUri: "gs://bucket/artifacts",
+		Options:     &models.ModelOption{PyTorchModelClassName: "MyModel"},
+	}
+
+	spec := handler{}.PredictorSpec(modelService, v1.ResourceRequirements{})
+	assert.Equal(t, kfsv1alpha2.PredictorSpec{
+		PyTorch: &kfsv1alpha2.PyTorchSpec{
+			StorageURI:     "gs://bucket/artifacts/model",
+			ModelClassName: "MyModel",
+		},
+	}, spec)
+}
+
+func TestHandler_V1beta1PredictorSpec(t *testing.T) {
+	modelService := &models.Service{
+		ArtifactUri: "gs://bucket/artifacts",
+		Options:     &models.ModelOption{PyTorchModelClassName: "MyModel"},
+	}
+
+	storageURI := "gs://bucket/artifacts/model"
+	spec := handler{}.V1beta1PredictorSpec(modelService, v1.ResourceRequirements{})
+	assert.Equal(t, servingv1beta1.PredictorSpec{
+		PyTorch: &servingv1beta1.TorchServeSpec{
+			ModelClassName: "MyModel",
+			PredictorExtensionSpec: servingv1beta1.PredictorExtensionSpec{
+				StorageURI: &storageURI,
+			},
+		},
+	}, spec)
+}
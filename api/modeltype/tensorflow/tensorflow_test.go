@@ -0,0 +1,58 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tensorflow
+
+import (
+	"testing"
+
+	kfsv1alpha2 "github.com/kubeflow/kfserving/pkg/apis/serving/v1alpha2"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	servingv1beta1 "github.com/gojek/merlin/kfserving/client-go/pkg/apis/serving/v1beta1"
+	"github.com/gojek/merlin/models"
+)
+
+func TestHandler_PredictorSpec(t *testing.T) {
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+	}
+
+	spec := handler{}.PredictorSpec(&models.Service{ArtifactUri: "gs://bucket/artifacts"}, resources)
+	assert.Equal(t, kfsv1alpha2.PredictorSpec{
+		Tensorflow: &kfsv1alpha2.TensorflowSpec{
+			StorageURI: "gs://bucket/artifacts/model",
+			Resources:  resources,
+		},
+	}, spec)
+}
+
+func TestHandler_V1beta1PredictorSpec(t *testing.T) {
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+	}
+
+	storageURI := "gs://bucket/artifacts/model"
+	spec := handler{}.V1beta1PredictorSpec(&models.Service{ArtifactUri: "gs://bucket/artifacts"}, resources)
+	assert.Equal(t, servingv1beta1.PredictorSpec{
+		Tensorflow: &servingv1beta1.TFServingSpec{
+			PredictorExtensionSpec: servingv1beta1.PredictorExtensionSpec{
+				StorageURI: &storageURI,
+				Container:  v1.Container{Resources: resources},
+			},
+		},
+	}, spec)
+}
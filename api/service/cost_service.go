@@ -0,0 +1,296 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/mlp"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/storage"
+)
+
+// defaultCostReportPeriod is the time range of the cost reports whose query doesn't specify its start
+const defaultCostReportPeriod = 30 * 24 * time.Hour
+
+// CostService estimates the cost of the resources requested by the version endpoints and prediction jobs. The
+// version endpoints are accounted for with their min replica, so the cost of the autoscaled endpoints is underestimated.
+type CostService interface {
+	// GetCostReport return the estimated cost of the resources requested within the time range of the query
+	GetCostReport(ctx context.Context, query *CostReportQuery) (*models.CostReport, error)
+}
+
+// CostReportQuery represent query string for cost report api
+type CostReportQuery struct {
+	// ProjectId restricts the report to a project, all projects are reported if it's zero
+	ProjectId models.Id `schema:"-"`
+	// GroupBy lists the dimensions the costs are grouped by
+	GroupBy []string `schema:"-"`
+	// Start and End are the time range of the report, respectively 30 days ago and now by default
+	Start time.Time `schema:"start"`
+	End   time.Time `schema:"end"`
+}
+
+type costService struct {
+	projectsService      ProjectsService
+	modelsService        ModelsService
+	deploymentStorage    storage.DeploymentStorage
+	endpointStorage      storage.VersionEndpointStorage
+	predictionJobStorage storage.PredictionJobStorage
+	unitPrices           map[string]config.UnitPriceConfig
+	clock                clock.Clock
+}
+
+// NewCostService creates a cost service estimating the costs with the unit prices keyed by environment name
+func NewCostService(projectsService ProjectsService,
+	modelsService ModelsService,
+	deploymentStorage storage.DeploymentStorage,
+	endpointStorage storage.VersionEndpointStorage,
+	predictionJobStorage storage.PredictionJobStorage,
+	unitPrices map[string]config.UnitPriceConfig,
+	clock clock.Clock) CostService {
+	return &costService{
+		projectsService:      projectsService,
+		modelsService:        modelsService,
+		deploymentStorage:    deploymentStorage,
+		endpointStorage:      endpointStorage,
+		predictionJobStorage: predictionJobStorage,
+		unitPrices:           unitPrices,
+		clock:                clock,
+	}
+}
+
+func (c *costService) GetCostReport(ctx context.Context, query *CostReportQuery) (*models.CostReport, error) {
+	now := c.clock.Now()
+	end := query.End
+	if end.IsZero() || end.After(now) {
+		end = now
+	}
+	start := query.Start
+	if start.IsZero() {
+		start = end.Add(-defaultCostReportPeriod)
+	}
+
+	endpointItems, err := c.estimateEndpoints(query.ProjectId, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	jobItems, err := c.estimatePredictionJobs(query.ProjectId, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	items := append(endpointItems, jobItems...)
+	if err := c.populateNames(ctx, query, items); err != nil {
+		return nil, err
+	}
+	return models.NewCostReport(start, end, query.GroupBy, items), nil
+}
+
+// estimateEndpoints estimates the cost of the version endpoints. A succeeded deployment keeps its resources requested
+// from the time the endpoint became ready until the next succeeded deployment of the endpoint, or until the
+// endpoint was terminated. Only the deployments started within the time range and the ones still deployed at its
+// start are listed.
+func (c *costService) estimateEndpoints(projectId models.Id, start, end time.Time) ([]*models.CostItem, error) {
+	deployments, err := c.deploymentStorage.List(storage.DeploymentFilter{ProjectId: projectId, Start: start, End: end})
+	if err != nil {
+		return nil, err
+	}
+
+	deployedAtStart, err := c.deploymentStorage.ListLastSucceededBefore(projectId, start)
+	if err != nil {
+		return nil, err
+	}
+	deployments = append(deployments, deployedAtStart...)
+
+	deploymentsByEndpoint := make(map[uuid.UUID][]*models.Deployment)
+	var endpointIds []uuid.UUID
+	for _, deployment := range deployments {
+		if !deployment.IsSucceeded() {
+			continue
+		}
+		if _, ok := deploymentsByEndpoint[deployment.VersionEndpointId]; !ok {
+			endpointIds = append(endpointIds, deployment.VersionEndpointId)
+		}
+		deploymentsByEndpoint[deployment.VersionEndpointId] = append(deploymentsByEndpoint[deployment.VersionEndpointId], deployment)
+	}
+
+	endpoints, err := c.endpointStorage.ListByIds(endpointIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// the endpoints which aren't running anymore stopped requesting resources at their last update
+	stoppedAt := make(map[uuid.UUID]time.Time)
+	endpointsById := make(map[uuid.UUID]*models.VersionEndpoint)
+	for _, endpoint := range endpoints {
+		endpointsById[endpoint.Id] = endpoint
+		if endpoint.Status == models.EndpointTerminated || endpoint.Status == models.EndpointFailed {
+			stoppedAt[endpoint.Id] = endpoint.UpdatedAt
+		}
+	}
+
+	var items []*models.CostItem
+	for _, endpointId := range endpointIds {
+		endpointDeployments := deploymentsByEndpoint[endpointId]
+		sort.Slice(endpointDeployments, func(i, j int) bool {
+			return endpointDeployments[i].UpdatedAt.Before(endpointDeployments[j].UpdatedAt)
+		})
+
+		for i, deployment := range endpointDeployments {
+			spec := deployment.Spec
+			if spec == nil {
+				// the deployments recorded before the deployment specs were introduced have none, the current
+				// resources of the endpoint are the best estimate of the ones they requested
+				endpoint, ok := endpointsById[endpointId]
+				if !ok {
+					continue
+				}
+				spec = models.NewDeploymentSpec(endpoint, nil)
+			}
+
+			until := end
+			if i+1 < len(endpointDeployments) {
+				until = endpointDeployments[i+1].UpdatedAt
+			}
+			if stopped, ok := stoppedAt[endpointId]; ok && stopped.Before(until) {
+				until = stopped
+			}
+
+			duration := overlap(deployment.UpdatedAt, until, start, end)
+			if duration <= 0 {
+				continue
+			}
+
+			items = append(items, c.newCostItem(deployment.ProjectId, deployment.VersionModelId, deployment.EnvironmentName,
+				spec.ResourceUsage(duration)))
+		}
+	}
+	return items, nil
+}
+
+// estimatePredictionJobs estimates the cost of the prediction jobs, which request their resources from their creation
+// until they finish
+func (c *costService) estimatePredictionJobs(projectId models.Id, start, end time.Time) ([]*models.CostItem, error) {
+	jobs, err := c.predictionJobStorage.ListRunWithin(projectId, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*models.CostItem
+	for _, job := range jobs {
+		if job.Config == nil || job.Config.ResourceRequest == nil {
+			continue
+		}
+
+		until := end
+		if job.Status != models.JobRunning && job.Status != models.JobTerminating {
+			until = job.UpdatedAt
+		}
+
+		duration := overlap(job.CreatedAt, until, start, end)
+		if duration <= 0 {
+			continue
+		}
+
+		items = append(items, c.newCostItem(job.ProjectId, job.VersionModelId, job.EnvironmentName,
+			job.Config.ResourceRequest.ResourceUsage(duration)))
+	}
+	return items, nil
+}
+
+func (c *costService) newCostItem(projectId, modelId models.Id, environmentName string, usage models.ResourceUsage) *models.CostItem {
+	return &models.CostItem{
+		ProjectId:       projectId,
+		ModelId:         modelId,
+		EnvironmentName: environmentName,
+		ResourceUsage:   usage,
+		Cost:            usage.Cost(c.unitPrices[environmentName]),
+	}
+}
+
+// populateNames fills the project, team and model names of the cost items
+func (c *costService) populateNames(ctx context.Context, query *CostReportQuery, items []*models.CostItem) error {
+	projectsById := make(map[models.Id]mlp.Project)
+	if query.ProjectId != 0 {
+		project, err := c.projectsService.GetByID(ctx, int32(query.ProjectId))
+		if err != nil {
+			return err
+		}
+		projectsById[query.ProjectId] = project
+	} else {
+		projects, err := c.projectsService.List(ctx, "")
+		if err != nil {
+			return err
+		}
+		for _, project := range projects {
+			projectsById[models.Id(project.Id)] = mlp.Project(project)
+		}
+	}
+
+	modelNames := make(map[models.Id]string)
+	if isGroupedBy(query.GroupBy, models.CostGroupByModel) {
+		listed := make(map[models.Id]bool)
+		for _, item := range items {
+			if listed[item.ProjectId] {
+				continue
+			}
+			listed[item.ProjectId] = true
+
+			projectModels, err := c.modelsService.ListModels(ctx, item.ProjectId, "")
+			if err != nil {
+				return err
+			}
+			for _, model := range projectModels {
+				modelNames[model.Id] = model.Name
+			}
+		}
+	}
+
+	for _, item := range items {
+		project := projectsById[item.ProjectId]
+		item.ProjectName = project.Name
+		item.Team = project.Team
+		item.ModelName = modelNames[item.ModelId]
+	}
+	return nil
+}
+
+func isGroupedBy(groupBy []string, dimension string) bool {
+	for _, d := range groupBy {
+		if d == dimension {
+			return true
+		}
+	}
+	return false
+}
+
+// overlap returns the duration of the period from begin to until within the time range
+func overlap(begin, until, start, end time.Time) time.Duration {
+	if begin.Before(start) {
+		begin = start
+	}
+	if until.After(end) {
+		until = end
+	}
+	return until.Sub(begin)
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+// +build unit
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gojek/mlp/api/client"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/mlp"
+	mlpMock "github.com/gojek/merlin/mlp/mocks"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/storage"
+	"github.com/gojek/merlin/storage/mocks"
+)
+
+func costTestDeployment(projectId models.Id, endpointId uuid.UUID, env string, status models.EndpointStatus, readyAt time.Time, replicas int) *models.Deployment {
+	deployment := &models.Deployment{
+		ProjectId:         projectId,
+		VersionModelId:    projectId,
+		VersionEndpointId: endpointId,
+		EnvironmentName:   env,
+		Status:            status,
+		Spec: &models.DeploymentSpec{
+			ResourceRequest: &models.ResourceRequest{
+				MinReplica:    replicas,
+				MaxReplica:    replicas,
+				CpuRequest:    resource.MustParse("1"),
+				MemoryRequest: resource.MustParse("1Gi"),
+			},
+		},
+	}
+	deployment.CreatedAt = readyAt.Add(-5 * time.Minute)
+	deployment.UpdatedAt = readyAt
+	return deployment
+}
+
+func TestCostService_GetCostReport(t *testing.T) {
+	now := time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC)
+	start := now.Add(-24 * time.Hour)
+
+	runningEndpoint := &models.VersionEndpoint{Id: uuid.New(), Status: models.EndpointRunning}
+	runningEndpoint.UpdatedAt = start.Add(12 * time.Hour)
+	terminatedEndpoint := &models.VersionEndpoint{
+		Id:     uuid.New(),
+		Status: models.EndpointTerminated,
+		ResourceRequest: &models.ResourceRequest{
+			MinReplica:    1,
+			MaxReplica:    1,
+			CpuRequest:    resource.MustParse("1"),
+			MemoryRequest: resource.MustParse("1Gi"),
+		},
+	}
+	terminatedEndpoint.UpdatedAt = start.Add(6 * time.Hour)
+
+	// listed from the latest deployment
+	deployments := []*models.Deployment{
+		costTestDeployment(1, runningEndpoint.Id, "dev", models.EndpointRunning, start.Add(12*time.Hour), 2),
+		costTestDeployment(1, runningEndpoint.Id, "dev", models.EndpointFailed, start.Add(6*time.Hour), 4),
+	}
+	// the deployments started before the report which were still deployed at its start
+	deployedAtStart := []*models.Deployment{
+		costTestDeployment(1, runningEndpoint.Id, "dev", models.EndpointRunning, start.Add(-12*time.Hour), 1),
+		costTestDeployment(2, terminatedEndpoint.Id, "prod", models.EndpointServing, start.Add(-30*24*time.Hour), 1),
+	}
+	// recorded without spec, the resources of the endpoint are used instead
+	deployedAtStart[1].Spec = nil
+
+	job := &models.PredictionJob{
+		ProjectId:       1,
+		VersionModelId:  1,
+		EnvironmentName: "dev",
+		Status:          models.JobCompleted,
+		Config: &models.Config{
+			ResourceRequest: &models.PredictionJobResourceRequest{
+				DriverCpuRequest:      "1",
+				DriverMemoryRequest:   "1Gi",
+				ExecutorReplica:       2,
+				ExecutorCpuRequest:    "1",
+				ExecutorMemoryRequest: "1Gi",
+			},
+		},
+	}
+	job.CreatedAt = start.Add(10 * time.Hour)
+	job.UpdatedAt = start.Add(12 * time.Hour)
+
+	mockDeploymentStorage := &mocks.DeploymentStorage{}
+	mockDeploymentStorage.On("List", storage.DeploymentFilter{Start: start, End: now}).Return(deployments, nil)
+	mockDeploymentStorage.On("ListLastSucceededBefore", models.Id(0), start).Return(deployedAtStart, nil)
+	mockEndpointStorage := &mocks.VersionEndpointStorage{}
+	mockEndpointStorage.On("ListByIds", []uuid.UUID{runningEndpoint.Id, terminatedEndpoint.Id}).
+		Return([]*models.VersionEndpoint{runningEndpoint, terminatedEndpoint}, nil)
+	mockPredictionJobStorage := &mocks.PredictionJobStorage{}
+	mockPredictionJobStorage.On("ListRunWithin", models.Id(0), start, now).Return([]*models.PredictionJob{job}, nil)
+	mockMlpAPIClient := &mlpMock.APIClient{}
+	mockMlpAPIClient.On("ListProjects", mock.Anything, "").Return(mlp.Projects{
+		client.Project{Id: 1, Name: "project-1", Team: "team-1"},
+		client.Project{Id: 2, Name: "project-2", Team: "team-2"},
+	}, nil)
+
+	costSvc := NewCostService(NewProjectsService(mockMlpAPIClient), nil, mockDeploymentStorage, mockEndpointStorage, mockPredictionJobStorage,
+		map[string]config.UnitPriceConfig{
+			"dev":  {CpuHour: 1, MemoryGbHour: 0.5},
+			"prod": {CpuHour: 2, MemoryGbHour: 1},
+		}, clock.NewFakeClock(now))
+
+	groupBy := []string{models.CostGroupByProject, models.CostGroupByTeam, models.CostGroupByEnvironment}
+	report, err := costSvc.GetCostReport(context.Background(), &CostReportQuery{GroupBy: groupBy, Start: start})
+	assert.NoError(t, err)
+	assert.Equal(t, &models.CostReport{
+		Start:   start,
+		End:     now,
+		GroupBy: groupBy,
+		Items: []*models.CostItem{
+			// 12 hours of 1 replica and 12 hours of 2 replicas of the endpoint, and 2 hours of 3 pods of the job
+			{ProjectId: 1, ProjectName: "project-1", Team: "team-1", EnvironmentName: "dev", ResourceUsage: models.ResourceUsage{CpuHours: 42, MemoryGbHours: 42}, Cost: 63},
+			// 6 hours until the endpoint was terminated
+			{ProjectId: 2, ProjectName: "project-2", Team: "team-2", EnvironmentName: "prod", ResourceUsage: models.ResourceUsage{CpuHours: 6, MemoryGbHours: 6}, Cost: 18},
+		},
+		TotalCost: 81,
+	}, report)
+}
+
+func TestCostService_GetCostReport_Project(t *testing.T) {
+	now := time.Date(2020, 10, 2, 0, 0, 0, 0, time.UTC)
+	start := now.Add(-30 * 24 * time.Hour)
+
+	mockDeploymentStorage := &mocks.DeploymentStorage{}
+	mockDeploymentStorage.On("List", storage.DeploymentFilter{ProjectId: 1, Start: start, End: now}).Return([]*models.Deployment{}, nil)
+	mockDeploymentStorage.On("ListLastSucceededBefore", models.Id(1), start).Return([]*models.Deployment{}, nil)
+	mockEndpointStorage := &mocks.VersionEndpointStorage{}
+	mockEndpointStorage.On("ListByIds", []uuid.UUID(nil)).Return(nil, nil)
+	mockPredictionJobStorage := &mocks.PredictionJobStorage{}
+	mockPredictionJobStorage.On("ListRunWithin", models.Id(1), start, now).Return([]*models.PredictionJob{}, nil)
+	mockMlpAPIClient := &mlpMock.APIClient{}
+	mockMlpAPIClient.On("GetProjectByID", mock.Anything, int32(1)).Return(mlp.Project{Id: 1, Name: "project-1"}, nil)
+
+	costSvc := NewCostService(NewProjectsService(mockMlpAPIClient), nil, mockDeploymentStorage, mockEndpointStorage, mockPredictionJobStorage,
+		nil, clock.NewFakeClock(now))
+
+	// the time range defaults to the last 30 days and ends now at the latest
+	report, err := costSvc.GetCostReport(context.Background(), &CostReportQuery{
+		ProjectId: 1,
+		GroupBy:   []string{models.CostGroupByProject},
+		End:       now.Add(time.Hour),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, start, report.Start)
+	assert.Equal(t, now, report.End)
+	assert.Empty(t, report.Items)
+	mockMlpAPIClient.AssertExpectations(t)
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"time"
+
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/storage"
+)
+
+const (
+	// DefaultDeploymentsLimit is the number of deployments listed when the query has no limit
+	DefaultDeploymentsLimit = 100
+	// MaxDeploymentsLimit is the maximum number of deployments listed at once
+	MaxDeploymentsLimit = 1000
+)
+
+// DeploymentService exposes the deployment attempts of version endpoints
+type DeploymentService interface {
+	// ListDeployments return a page of the deployments matching the query, the latest first, along with the statistics
+	// of all the deployments matching the query
+	ListDeployments(query *ListDeploymentQuery) (*models.DeploymentHistory, error)
+}
+
+// ListDeploymentQuery represent query string for list deployment api
+type ListDeploymentQuery struct {
+	ProjectId       models.Id             `schema:"-"`
+	ModelId         models.Id             `schema:"-"`
+	VersionId       models.Id             `schema:"version_id"`
+	EnvironmentName string                `schema:"environment_name"`
+	Status          models.EndpointStatus `schema:"status"`
+	// Start and End restrict the deployments to the ones started within the time range
+	Start time.Time `schema:"start"`
+	End   time.Time `schema:"end"`
+	// Limit and Offset page the listed deployments, DefaultDeploymentsLimit deployments are listed when there's no limit
+	Limit  int `schema:"limit"`
+	Offset int `schema:"offset"`
+}
+
+type deploymentService struct {
+	storage storage.DeploymentStorage
+}
+
+func NewDeploymentService(storage storage.DeploymentStorage) DeploymentService {
+	return &deploymentService{storage: storage}
+}
+
+func (d *deploymentService) ListDeployments(query *ListDeploymentQuery) (*models.DeploymentHistory, error) {
+	filter := storage.DeploymentFilter{
+		ProjectId:       query.ProjectId,
+		ModelId:         query.ModelId,
+		VersionId:       query.VersionId,
+		EnvironmentName: query.EnvironmentName,
+		Status:          query.Status,
+		Start:           query.Start,
+		End:             query.End,
+		Limit:           query.Limit,
+		Offset:          query.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultDeploymentsLimit
+	}
+
+	deployments, err := d.storage.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := d.storage.Stats(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DeploymentHistory{
+		Deployments: deployments,
+		Stats:       stats,
+	}, nil
+}
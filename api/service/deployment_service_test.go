@@ -0,0 +1,102 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+// +build unit
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/storage"
+	"github.com/gojek/merlin/storage/mocks"
+)
+
+func TestDeploymentService_ListDeployments(t *testing.T) {
+	start := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	deployments := []*models.Deployment{
+		{
+			Id:             2,
+			Status:         models.EndpointFailed,
+			Error:          "timeout",
+			CreatedUpdated: models.CreatedUpdated{CreatedAt: start, UpdatedAt: start.Add(10 * time.Minute)},
+		},
+		{
+			Id:             1,
+			Status:         models.EndpointRunning,
+			CreatedUpdated: models.CreatedUpdated{CreatedAt: start, UpdatedAt: start.Add(time.Minute)},
+		},
+	}
+
+	stats := &models.DeploymentStats{
+		Total:                  12,
+		Succeeded:              6,
+		Failed:                 6,
+		SuccessRate:            0.5,
+		MeanTimeToReadySeconds: 60,
+	}
+
+	tests := []struct {
+		name       string
+		query      *ListDeploymentQuery
+		wantFilter storage.DeploymentFilter
+	}{
+		{
+			name: "default limit",
+			query: &ListDeploymentQuery{
+				ModelId:         1,
+				EnvironmentName: "staging",
+				Start:           start,
+			},
+			wantFilter: storage.DeploymentFilter{
+				ModelId:         1,
+				EnvironmentName: "staging",
+				Start:           start,
+				Limit:           DefaultDeploymentsLimit,
+			},
+		},
+		{
+			name: "page",
+			query: &ListDeploymentQuery{
+				ModelId: 1,
+				Limit:   2,
+				Offset:  10,
+			},
+			wantFilter: storage.DeploymentFilter{
+				ModelId: 1,
+				Limit:   2,
+				Offset:  10,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage := &mocks.DeploymentStorage{}
+			mockStorage.On("List", tt.wantFilter).Return(deployments, nil)
+			mockStorage.On("Stats", tt.wantFilter).Return(stats, nil)
+
+			svc := NewDeploymentService(mockStorage)
+			history, err := svc.ListDeployments(tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, deployments, history.Deployments)
+			assert.Equal(t, stats, history.Stats)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/storage"
+)
+
+// errLeaseLost is returned by deployment steps which are skipped because the lease of their task has been lost.
+var errLeaseLost = errors.New("deployment task has been claimed by another replica")
+
+// checkLease returns errLeaseLost once ctx of the executed task is cancelled, i.e. once its lease has been lost.
+// Handlers call it between their steps so that a task claimed by another replica isn't executed twice.
+func checkLease(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return errLeaseLost
+	}
+	return nil
+}
+
+// DeploymentTaskHandler executes deployment tasks claimed from the deployment task queue.
+type DeploymentTaskHandler interface {
+	// ExecuteDeploymentTask executes a claimed task. Returning error marks the task as failed.
+	ExecuteDeploymentTask(ctx context.Context, task *models.DeploymentTask) error
+	// ReconcileOrphans resumes or marks as failed the resources which are still pending, but have no unfinished
+	// deployment task. It covers deployments interrupted before the task queue existed and tasks abandoned after too
+	// many attempts.
+	ReconcileOrphans(ctx context.Context, updatedBefore time.Time) error
+}
+
+// DeploymentTaskQueue persists deployment tasks so that they survive API restarts.
+type DeploymentTaskQueue struct {
+	storage storage.DeploymentTaskStorage
+	owner   string
+	config  config.DeploymentQueueConfig
+	clock   clock.Clock
+}
+
+// NewDeploymentTaskQueue creates a queue whose tasks are claimed by owner, which must be unique among API replicas.
+func NewDeploymentTaskQueue(storage storage.DeploymentTaskStorage, owner string, config config.DeploymentQueueConfig, clock clock.Clock) *DeploymentTaskQueue {
+	return &DeploymentTaskQueue{
+		storage: storage,
+		owner:   owner,
+		config:  config,
+		clock:   clock,
+	}
+}
+
+// Enqueue adds a pending task to be executed by any replica.
+func (q *DeploymentTaskQueue) Enqueue(task *models.DeploymentTask) error {
+	task.Status = models.TaskPending
+	task.MaxAttempts = q.config.MaxAttempts
+	return q.storage.Save(task)
+}
+
+// FindLatest returns the most recent task of a version endpoint, or nil if there is none.
+func (q *DeploymentTaskQueue) FindLatest(versionEndpointId uuid.UUID) (*models.DeploymentTask, error) {
+	return q.storage.FindLatest(versionEndpointId)
+}
+
+// execute runs fn while periodically extending the lease of the task, then records the result of the task.
+func (q *DeploymentTaskQueue) execute(task *models.DeploymentTask, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go wait.Until(func() {
+		owned, err := q.storage.Heartbeat(task.Id, q.owner, q.clock.Now().Add(q.config.LeaseDuration))
+		if err != nil {
+			log.Warnf("unable to extend lease of deployment task %s: %v", task.Id, err)
+			return
+		}
+		if !owned {
+			log.Warnf("deployment task %s has been claimed by another replica", task.Id)
+			cancel()
+		}
+	}, q.config.LeaseDuration/3, done)
+
+	err := fn(ctx)
+	close(done)
+
+	task.Status = models.TaskSucceeded
+	task.Error = ""
+	if err != nil {
+		task.Status = models.TaskFailed
+		task.Error = err.Error()
+	}
+
+	if finishErr := q.storage.Finish(task); finishErr != nil {
+		log.Errorf("unable to update status of deployment task %s: %v", task.Id, finishErr)
+	}
+	return err
+}
+
+// DeploymentTaskWorker claims and executes deployment tasks from the queue.
+type DeploymentTaskWorker interface {
+	// Run reconciles orphaned resources and executes deployment tasks until stopCh is closed
+	Run(stopCh <-chan struct{})
+}
+
+type deploymentTaskWorker struct {
+	queue    *DeploymentTaskQueue
+	handlers map[models.DeploymentTaskType]DeploymentTaskHandler
+}
+
+func NewDeploymentTaskWorker(queue *DeploymentTaskQueue, handlers map[models.DeploymentTaskType]DeploymentTaskHandler) DeploymentTaskWorker {
+	return &deploymentTaskWorker{
+		queue:    queue,
+		handlers: handlers,
+	}
+}
+
+func (w *deploymentTaskWorker) Run(stopCh <-chan struct{}) {
+	go wait.Until(w.reconcileOrphans, w.queue.config.ReconcileInterval, stopCh)
+
+	for i := 0; i < w.queue.config.Workers; i++ {
+		go wait.Until(w.processTasks, w.queue.config.PollInterval, stopCh)
+	}
+
+	<-stopCh
+}
+
+// processTasks executes tasks until there is no task left to be claimed.
+func (w *deploymentTaskWorker) processTasks() {
+	for {
+		task, err := w.queue.storage.Claim(w.queue.owner, w.queue.clock.Now(), w.queue.config.LeaseDuration)
+		if err != nil {
+			log.Errorf("unable to claim deployment task: %v", err)
+			return
+		}
+		if task == nil {
+			return
+		}
+
+		_ = w.processTask(task)
+	}
+}
+
+func (w *deploymentTaskWorker) processTask(task *models.DeploymentTask) error {
+	log.Infof("executing deployment task %s (%s), attempt %d", task.Id, task.Type, task.Attempts)
+
+	return w.queue.execute(task, func(ctx context.Context) error {
+		handler, ok := w.handlers[task.Type]
+		if !ok {
+			return fmt.Errorf("unsupported deployment task type: %s", task.Type)
+		}
+
+		// The resource of an abandoned task is marked as failed by the next reconciliation
+		if task.IsExhausted() {
+			return fmt.Errorf("deployment task is abandoned after %d attempts", task.MaxAttempts)
+		}
+
+		return handler.ExecuteDeploymentTask(ctx, task)
+	})
+}
+
+func (w *deploymentTaskWorker) reconcileOrphans() {
+	updatedBefore := w.queue.clock.Now().Add(-w.queue.config.OrphanGracePeriod)
+
+	reconciled := map[DeploymentTaskHandler]bool{}
+	for _, handler := range w.handlers {
+		if reconciled[handler] {
+			continue
+		}
+		reconciled[handler] = true
+
+		if err := handler.ReconcileOrphans(context.Background(), updatedBefore); err != nil {
+			log.Errorf("unable to reconcile orphaned deployments: %v", err)
+		}
+	}
+}
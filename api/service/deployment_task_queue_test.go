@@ -0,0 +1,127 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/models"
+	storageMock "github.com/gojek/merlin/storage/mocks"
+)
+
+type fakeDeploymentTaskHandler struct {
+	executed   []*models.DeploymentTask
+	err        error
+	reconciled int
+}
+
+func (h *fakeDeploymentTaskHandler) ExecuteDeploymentTask(ctx context.Context, task *models.DeploymentTask) error {
+	h.executed = append(h.executed, task)
+	return h.err
+}
+
+func (h *fakeDeploymentTaskHandler) ReconcileOrphans(ctx context.Context, updatedBefore time.Time) error {
+	h.reconciled++
+	return nil
+}
+
+var deploymentQueueConfig = config.DeploymentQueueConfig{
+	Workers:           1,
+	PollInterval:      time.Second,
+	LeaseDuration:     time.Minute,
+	MaxAttempts:       3,
+	ReconcileInterval: time.Minute,
+	OrphanGracePeriod: 10 * time.Minute,
+}
+
+func TestDeploymentTaskWorker_processTask(t *testing.T) {
+	tests := []struct {
+		name        string
+		task        *models.DeploymentTask
+		handlerErr  error
+		wantExecute bool
+		wantStatus  models.DeploymentTaskStatus
+	}{
+		{
+			name:        "succeeded",
+			task:        &models.DeploymentTask{Type: models.DeployVersionEndpointTask, Attempts: 1, MaxAttempts: 3},
+			wantExecute: true,
+			wantStatus:  models.TaskSucceeded,
+		},
+		{
+			name:        "failed",
+			task:        &models.DeploymentTask{Type: models.DeployVersionEndpointTask, Attempts: 1, MaxAttempts: 3},
+			handlerErr:  errors.New("failed deploying"),
+			wantExecute: true,
+			wantStatus:  models.TaskFailed,
+		},
+		{
+			name:        "exhausted",
+			task:        &models.DeploymentTask{Type: models.DeployVersionEndpointTask, Attempts: 4, MaxAttempts: 3},
+			wantExecute: false,
+			wantStatus:  models.TaskFailed,
+		},
+		{
+			name:        "unsupported task type",
+			task:        &models.DeploymentTask{Type: models.SubmitPredictionJobTask, Attempts: 1, MaxAttempts: 3},
+			wantExecute: false,
+			wantStatus:  models.TaskFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskStorage := &storageMock.DeploymentTaskStorage{}
+			taskStorage.On("Heartbeat", mock.Anything, "owner", mock.Anything).Return(true, nil)
+			taskStorage.On("Finish", tt.task).Return(nil)
+
+			handler := &fakeDeploymentTaskHandler{err: tt.handlerErr}
+			queue := NewDeploymentTaskQueue(taskStorage, "owner", deploymentQueueConfig, clock.NewFakeClock(time.Now()))
+			worker := &deploymentTaskWorker{
+				queue: queue,
+				handlers: map[models.DeploymentTaskType]DeploymentTaskHandler{
+					models.DeployVersionEndpointTask:   handler,
+					models.UndeployVersionEndpointTask: handler,
+				},
+			}
+
+			err := worker.processTask(tt.task)
+			assert.Equal(t, tt.wantStatus == models.TaskFailed, err != nil)
+			assert.Equal(t, tt.wantExecute, len(handler.executed) == 1)
+			assert.Equal(t, tt.wantStatus, tt.task.Status)
+			taskStorage.AssertCalled(t, "Finish", tt.task)
+		})
+	}
+}
+
+func TestDeploymentTaskWorker_reconcileOrphans(t *testing.T) {
+	handler := &fakeDeploymentTaskHandler{}
+	queue := NewDeploymentTaskQueue(&storageMock.DeploymentTaskStorage{}, "owner", deploymentQueueConfig, clock.NewFakeClock(time.Now()))
+	worker := NewDeploymentTaskWorker(queue, map[models.DeploymentTaskType]DeploymentTaskHandler{
+		models.DeployVersionEndpointTask:   handler,
+		models.UndeployVersionEndpointTask: handler,
+	}).(*deploymentTaskWorker)
+
+	worker.reconcileOrphans()
+	assert.Equal(t, 1, handler.reconciled)
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/storage"
+)
+
+// leaderLeaseName is the name of the lease shared by all background loops
+const leaderLeaseName = "merlin-api-leader"
+
+// LeaderElector tells whether this API replica is elected to run the background loops, so that
+// they don't act on the same resources from several replicas at once.
+type LeaderElector interface {
+	// IsLeader returns true if this replica currently holds the leader lease
+	IsLeader() bool
+}
+
+// LeaderLease elects the leader among the API replicas with a lease stored in the database.
+type LeaderLease struct {
+	storage  storage.LeaseStorage
+	owner    string
+	duration time.Duration
+	clock    clock.Clock
+
+	mu sync.RWMutex
+	// expiresAt is the time until which this replica holds the lease
+	expiresAt time.Time
+}
+
+// NewLeaderLease creates a lease acquired by owner, which must be unique among API replicas.
+func NewLeaderLease(storage storage.LeaseStorage, owner string, duration time.Duration, clock clock.Clock) *LeaderLease {
+	return &LeaderLease{
+		storage:  storage,
+		owner:    owner,
+		duration: duration,
+		clock:    clock,
+	}
+}
+
+// Run acquires the lease, then keeps renewing it, until stopCh is closed.
+func (l *LeaderLease) Run(stopCh <-chan struct{}) {
+	wait.Until(l.renew, l.duration/3, stopCh)
+}
+
+func (l *LeaderLease) IsLeader() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.clock.Now().Before(l.expiresAt)
+}
+
+func (l *LeaderLease) renew() {
+	now := l.clock.Now()
+	acquired, err := l.storage.Acquire(leaderLeaseName, l.owner, now, l.duration)
+	if err != nil {
+		log.Warnf("unable to renew leader lease: %v", err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	wasLeader := now.Before(l.expiresAt)
+	if !acquired {
+		if wasLeader {
+			log.Warnf("leader lease has been taken over by another replica")
+		}
+		l.expiresAt = time.Time{}
+		return
+	}
+
+	if !wasLeader {
+		log.Infof("%s is elected as leader", l.owner)
+	}
+	l.expiresAt = now.Add(l.duration)
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+// +build unit
+
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	storageMock "github.com/gojek/merlin/storage/mocks"
+)
+
+func TestLeaderLease_IsLeader(t *testing.T) {
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(now)
+
+	leaseStorage := &storageMock.LeaseStorage{}
+	lease := NewLeaderLease(leaseStorage, "owner-1", time.Minute, fakeClock)
+	assert.False(t, lease.IsLeader())
+
+	leaseStorage.On("Acquire", leaderLeaseName, "owner-1", now, time.Minute).Return(true, nil).Once()
+	lease.renew()
+	assert.True(t, lease.IsLeader())
+
+	// a failed renewal keeps the lease until it expires
+	fakeClock.Step(20 * time.Second)
+	leaseStorage.On("Acquire", leaderLeaseName, "owner-1", fakeClock.Now(), time.Minute).Return(false, errors.New("db error")).Once()
+	lease.renew()
+	assert.True(t, lease.IsLeader())
+
+	fakeClock.Step(40 * time.Second)
+	assert.False(t, lease.IsLeader())
+
+	// the lease has been taken over by another replica
+	leaseStorage.On("Acquire", leaderLeaseName, "owner-1", fakeClock.Now(), time.Minute).Return(false, nil).Once()
+	lease.renew()
+	assert.False(t, lease.IsLeader())
+
+	leaseStorage.AssertExpectations(t)
+}
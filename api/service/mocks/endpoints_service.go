@@ -2,32 +2,33 @@
 
 package mocks
 
-import (
-	models "github.com/gojek/merlin/models"
-	mock "github.com/stretchr/testify/mock"
-
-	uuid "github.com/google/uuid"
-)
+import context "context"
+import mock "github.com/stretchr/testify/mock"
+import models "github.com/gojek/merlin/models"
+import time "time"
+import uuid "github.com/google/uuid"
 
 // EndpointsService is an autogenerated mock type for the EndpointsService type
 type EndpointsService struct {
 	mock.Mock
 }
 
-// CountEndpoints provides a mock function with given fields: environment, model
-func (_m *EndpointsService) CountEndpoints(environment *models.Environment, model *models.Model) (int, error) {
-	ret := _m.Called(environment, model)
+// DeployEndpoint provides a mock function with given fields: environment, model, version, endpoint, user
+func (_m *EndpointsService) DeployEndpoint(environment *models.Environment, model *models.Model, version *models.Version, endpoint *models.VersionEndpoint, user string) (*models.VersionEndpoint, error) {
+	ret := _m.Called(environment, model, version, endpoint, user)
 
-	var r0 int
-	if rf, ok := ret.Get(0).(func(*models.Environment, *models.Model) int); ok {
-		r0 = rf(environment, model)
+	var r0 *models.VersionEndpoint
+	if rf, ok := ret.Get(0).(func(*models.Environment, *models.Model, *models.Version, *models.VersionEndpoint, string) *models.VersionEndpoint); ok {
+		r0 = rf(environment, model, version, endpoint, user)
 	} else {
-		r0 = ret.Get(0).(int)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.VersionEndpoint)
+		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*models.Environment, *models.Model) error); ok {
-		r1 = rf(environment, model)
+	if rf, ok := ret.Get(1).(func(*models.Environment, *models.Model, *models.Version, *models.VersionEndpoint, string) error); ok {
+		r1 = rf(environment, model, version, endpoint, user)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -35,27 +36,18 @@ func (_m *EndpointsService) CountEndpoints(environment *models.Environment, mode
 	return r0, r1
 }
 
-// DeployEndpoint provides a mock function with given fields: environment, model, version, endpoint
-func (_m *EndpointsService) DeployEndpoint(environment *models.Environment, model *models.Model, version *models.Version, endpoint *models.VersionEndpoint) (*models.VersionEndpoint, error) {
-	ret := _m.Called(environment, model, version, endpoint)
+// ExecuteDeploymentTask provides a mock function with given fields: ctx, task
+func (_m *EndpointsService) ExecuteDeploymentTask(ctx context.Context, task *models.DeploymentTask) error {
+	ret := _m.Called(ctx, task)
 
-	var r0 *models.VersionEndpoint
-	if rf, ok := ret.Get(0).(func(*models.Environment, *models.Model, *models.Version, *models.VersionEndpoint) *models.VersionEndpoint); ok {
-		r0 = rf(environment, model, version, endpoint)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeploymentTask) error); ok {
+		r0 = rf(ctx, task)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.VersionEndpoint)
-		}
+		r0 = ret.Error(0)
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(*models.Environment, *models.Model, *models.Version, *models.VersionEndpoint) error); ok {
-		r1 = rf(environment, model, version, endpoint)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
+	return r0
 }
 
 // FindById provides a mock function with given fields: uuid2
@@ -81,6 +73,27 @@ func (_m *EndpointsService) FindById(uuid2 uuid.UUID) (*models.VersionEndpoint,
 	return r0, r1
 }
 
+// IsDeploying provides a mock function with given fields: endpoint
+func (_m *EndpointsService) IsDeploying(endpoint *models.VersionEndpoint) (bool, error) {
+	ret := _m.Called(endpoint)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*models.VersionEndpoint) bool); ok {
+		r0 = rf(endpoint)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*models.VersionEndpoint) error); ok {
+		r1 = rf(endpoint)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListContainers provides a mock function with given fields: model, version, id
 func (_m *EndpointsService) ListContainers(model *models.Model, version *models.Version, id uuid.UUID) ([]*models.Container, error) {
 	ret := _m.Called(model, version, id)
@@ -104,6 +117,29 @@ func (_m *EndpointsService) ListContainers(model *models.Model, version *models.
 	return r0, r1
 }
 
+// ListEndpointEvents provides a mock function with given fields: id
+func (_m *EndpointsService) ListEndpointEvents(id uuid.UUID) ([]*models.VersionEndpointEvent, error) {
+	ret := _m.Called(id)
+
+	var r0 []*models.VersionEndpointEvent
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []*models.VersionEndpointEvent); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.VersionEndpointEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListEndpoints provides a mock function with given fields: model, version
 func (_m *EndpointsService) ListEndpoints(model *models.Model, version *models.Version) ([]*models.VersionEndpoint, error) {
 	ret := _m.Called(model, version)
@@ -127,6 +163,126 @@ func (_m *EndpointsService) ListEndpoints(model *models.Model, version *models.V
 	return r0, r1
 }
 
+// ListRevisions provides a mock function with given fields: id
+func (_m *EndpointsService) ListRevisions(id uuid.UUID) ([]*models.DeploymentRevision, error) {
+	ret := _m.Called(id)
+
+	var r0 []*models.DeploymentRevision
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []*models.DeploymentRevision); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.DeploymentRevision)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReconcileOrphans provides a mock function with given fields: ctx, updatedBefore
+func (_m *EndpointsService) ReconcileOrphans(ctx context.Context, updatedBefore time.Time) error {
+	ret := _m.Called(ctx, updatedBefore)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, updatedBefore)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RedeployEndpoint provides a mock function with given fields: endpoint
+func (_m *EndpointsService) RedeployEndpoint(endpoint *models.VersionEndpoint) error {
+	ret := _m.Called(endpoint)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.VersionEndpoint) error); ok {
+		r0 = rf(endpoint)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RenderEndpoint provides a mock function with given fields: environment, model, version, endpoint
+func (_m *EndpointsService) RenderEndpoint(environment *models.Environment, model *models.Model, version *models.Version, endpoint *models.VersionEndpoint) (*models.DryRunResult, error) {
+	ret := _m.Called(environment, model, version, endpoint)
+
+	var r0 *models.DryRunResult
+	if rf, ok := ret.Get(0).(func(*models.Environment, *models.Model, *models.Version, *models.VersionEndpoint) *models.DryRunResult); ok {
+		r0 = rf(environment, model, version, endpoint)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DryRunResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*models.Environment, *models.Model, *models.Version, *models.VersionEndpoint) error); ok {
+		r1 = rf(environment, model, version, endpoint)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RollbackEndpoint provides a mock function with given fields: model, version, endpoint, revision, user
+func (_m *EndpointsService) RollbackEndpoint(model *models.Model, version *models.Version, endpoint *models.VersionEndpoint, revision int, user string) (*models.VersionEndpoint, error) {
+	ret := _m.Called(model, version, endpoint, revision, user)
+
+	var r0 *models.VersionEndpoint
+	if rf, ok := ret.Get(0).(func(*models.Model, *models.Version, *models.VersionEndpoint, int, string) *models.VersionEndpoint); ok {
+		r0 = rf(model, version, endpoint, revision, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.VersionEndpoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*models.Model, *models.Version, *models.VersionEndpoint, int, string) error); ok {
+		r1 = rf(model, version, endpoint, revision, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ScaleEndpoint provides a mock function with given fields: model, endpoint, minReplica, maxReplica, reason
+func (_m *EndpointsService) ScaleEndpoint(model *models.Model, endpoint *models.VersionEndpoint, minReplica int, maxReplica int, reason string) (*models.VersionEndpoint, error) {
+	ret := _m.Called(model, endpoint, minReplica, maxReplica, reason)
+
+	var r0 *models.VersionEndpoint
+	if rf, ok := ret.Get(0).(func(*models.Model, *models.VersionEndpoint, int, int, string) *models.VersionEndpoint); ok {
+		r0 = rf(model, endpoint, minReplica, maxReplica, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.VersionEndpoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*models.Model, *models.VersionEndpoint, int, int, string) error); ok {
+		r1 = rf(model, endpoint, minReplica, maxReplica, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UndeployEndpoint provides a mock function with given fields: environment, model, version, endpoint
 func (_m *EndpointsService) UndeployEndpoint(environment *models.Environment, model *models.Model, version *models.Version, endpoint *models.VersionEndpoint) (*models.VersionEndpoint, error) {
 	ret := _m.Called(environment, model, version, endpoint)
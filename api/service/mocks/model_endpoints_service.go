@@ -106,6 +106,29 @@ func (_m *ModelEndpointsService) ListModelEndpointsInProject(ctx context.Context
 	return r0, r1
 }
 
+// RenderEndpoint provides a mock function with given fields: ctx, model, endpoint
+func (_m *ModelEndpointsService) RenderEndpoint(ctx context.Context, model *models.Model, endpoint *models.ModelEndpoint) (*models.DryRunResult, error) {
+	ret := _m.Called(ctx, model, endpoint)
+
+	var r0 *models.DryRunResult
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Model, *models.ModelEndpoint) *models.DryRunResult); ok {
+		r0 = rf(ctx, model, endpoint)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DryRunResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *models.Model, *models.ModelEndpoint) error); ok {
+		r1 = rf(ctx, model, endpoint)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: ctx, endpoint
 func (_m *ModelEndpointsService) Save(ctx context.Context, endpoint *models.ModelEndpoint) (*models.ModelEndpoint, error) {
 	ret := _m.Called(ctx, endpoint)
@@ -2,10 +2,12 @@
 
 package mocks
 
+import context "context"
 import mlp "github.com/gojek/merlin/mlp"
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/gojek/merlin/models"
 import service "github.com/gojek/merlin/service"
+import time "time"
 
 // PredictionJobService is an autogenerated mock type for the PredictionJobService type
 type PredictionJobService struct {
@@ -35,6 +37,20 @@ func (_m *PredictionJobService) CreatePredictionJob(env *models.Environment, mod
 	return r0, r1
 }
 
+// ExecuteDeploymentTask provides a mock function with given fields: ctx, task
+func (_m *PredictionJobService) ExecuteDeploymentTask(ctx context.Context, task *models.DeploymentTask) error {
+	ret := _m.Called(ctx, task)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.DeploymentTask) error); ok {
+		r0 = rf(ctx, task)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetPredictionJob provides a mock function with given fields: env, model, version, id
 func (_m *PredictionJobService) GetPredictionJob(env *models.Environment, model *models.Model, version *models.Version, id models.Id) (*models.PredictionJob, error) {
 	ret := _m.Called(env, model, version, id)
@@ -104,6 +120,43 @@ func (_m *PredictionJobService) ListPredictionJobs(project mlp.Project, query *s
 	return r0, r1
 }
 
+// ReconcileOrphans provides a mock function with given fields: ctx, updatedBefore
+func (_m *PredictionJobService) ReconcileOrphans(ctx context.Context, updatedBefore time.Time) error {
+	ret := _m.Called(ctx, updatedBefore)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) error); ok {
+		r0 = rf(ctx, updatedBefore)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RenderPredictionJob provides a mock function with given fields: env, model, version, predictionJob
+func (_m *PredictionJobService) RenderPredictionJob(env *models.Environment, model *models.Model, version *models.Version, predictionJob *models.PredictionJob) (*models.DryRunResult, error) {
+	ret := _m.Called(env, model, version, predictionJob)
+
+	var r0 *models.DryRunResult
+	if rf, ok := ret.Get(0).(func(*models.Environment, *models.Model, *models.Version, *models.PredictionJob) *models.DryRunResult); ok {
+		r0 = rf(env, model, version, predictionJob)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DryRunResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*models.Environment, *models.Model, *models.Version, *models.PredictionJob) error); ok {
+		r1 = rf(env, model, version, predictionJob)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StopPredictionJob provides a mock function with given fields: env, model, version, id
 func (_m *PredictionJobService) StopPredictionJob(env *models.Environment, model *models.Model, version *models.Version, id models.Id) (*models.PredictionJob, error) {
 	ret := _m.Called(env, model, version, id)
@@ -0,0 +1,485 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/storage"
+)
+
+var rolloutCounter = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:      "rollout_count",
+		Namespace: "merlin_api",
+		Help:      "Number of finished model endpoint rollout",
+	},
+	[]string{"project", "model", "status"},
+)
+
+func init() {
+	prometheus.MustRegister(rolloutCounter)
+}
+
+// RolloutGateEvaluator returns the observed value of a rollout gate's metric for the version endpoint being rolled out.
+type RolloutGateEvaluator interface {
+	EvaluateGate(ctx context.Context, model *models.Model, versionEndpoint *models.VersionEndpoint, gate *models.RolloutGate) (float64, error)
+}
+
+type ModelEndpointRolloutService interface {
+	// ListRollouts returns all rollouts of a model endpoint
+	ListRollouts(ctx context.Context, modelEndpointId models.Id) ([]*models.ModelEndpointRollout, error)
+	// FindById returns the rollout with given ID
+	FindById(ctx context.Context, id models.Id) (*models.ModelEndpointRollout, error)
+	// FindProgressingRollout returns the rollout of a model endpoint which isn't finished yet, nil if there is none
+	FindProgressingRollout(ctx context.Context, modelEndpointId models.Id) (*models.ModelEndpointRollout, error)
+	// StartRollout validates the rollout and shifts traffic of the model endpoint to the first step.
+	// It returns models.InvalidRolloutError if the rollout can't be started on the model endpoint.
+	StartRollout(ctx context.Context, model *models.Model, modelEndpoint *models.ModelEndpoint, rollout *models.ModelEndpointRollout) (*models.ModelEndpointRollout, error)
+	// RollbackRollout stops a progressing rollout and restores the model endpoint's previous traffic rule.
+	// It returns models.InvalidRolloutError if the rollout changed concurrently.
+	RollbackRollout(ctx context.Context, model *models.Model, rollout *models.ModelEndpointRollout, reason string) (*models.ModelEndpointRollout, error)
+	// Run periodically advances all progressing rollouts, while this replica is the leader, until stopCh is closed
+	Run(stopCh <-chan struct{})
+}
+
+type modelEndpointRolloutService struct {
+	storage                storage.ModelEndpointRolloutStorage
+	modelEndpointsService  ModelEndpointsService
+	modelsService          ModelsService
+	versionEndpointStorage storage.VersionEndpointStorage
+	gateEvaluator          RolloutGateEvaluator
+	leader                 LeaderElector
+	clock                  clock.Clock
+	syncPeriod             time.Duration
+}
+
+// NewModelEndpointRolloutService creates a rollout service. gateEvaluator can be nil, in which case
+// only rollouts without metric gates are accepted.
+func NewModelEndpointRolloutService(storage storage.ModelEndpointRolloutStorage,
+	modelEndpointsService ModelEndpointsService,
+	modelsService ModelsService,
+	versionEndpointStorage storage.VersionEndpointStorage,
+	gateEvaluator RolloutGateEvaluator,
+	leader LeaderElector,
+	clock clock.Clock,
+	syncPeriod time.Duration) ModelEndpointRolloutService {
+	return &modelEndpointRolloutService{
+		storage:                storage,
+		modelEndpointsService:  modelEndpointsService,
+		modelsService:          modelsService,
+		versionEndpointStorage: versionEndpointStorage,
+		gateEvaluator:          gateEvaluator,
+		leader:                 leader,
+		clock:                  clock,
+		syncPeriod:             syncPeriod,
+	}
+}
+
+func (s *modelEndpointRolloutService) ListRollouts(ctx context.Context, modelEndpointId models.Id) ([]*models.ModelEndpointRollout, error) {
+	return s.storage.List(modelEndpointId)
+}
+
+func (s *modelEndpointRolloutService) FindById(ctx context.Context, id models.Id) (*models.ModelEndpointRollout, error) {
+	return s.storage.Get(id)
+}
+
+func (s *modelEndpointRolloutService) FindProgressingRollout(ctx context.Context, modelEndpointId models.Id) (*models.ModelEndpointRollout, error) {
+	rollouts, err := s.storage.List(modelEndpointId)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list rollouts of model endpoint %s", modelEndpointId)
+	}
+
+	for _, rollout := range rollouts {
+		if !rollout.Status.IsTerminal() {
+			return rollout, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *modelEndpointRolloutService) StartRollout(ctx context.Context, model *models.Model, modelEndpoint *models.ModelEndpoint, rollout *models.ModelEndpointRollout) (*models.ModelEndpointRollout, error) {
+	if err := rollout.Validate(); err != nil {
+		return nil, &models.InvalidRolloutError{Reason: err.Error()}
+	}
+
+	if len(rollout.Gates) > 0 && s.gateEvaluator == nil {
+		return nil, models.NewInvalidRolloutError("metric gates are not supported since no metrics provider is configured")
+	}
+
+	if modelEndpoint.Status != models.EndpointServing || modelEndpoint.Rule == nil || len(modelEndpoint.Rule.Destination) == 0 {
+		return nil, models.NewInvalidRolloutError("model endpoint %s is not serving any traffic", modelEndpoint.Id)
+	}
+
+	progressing, err := s.FindProgressingRollout(ctx, modelEndpoint.Id)
+	if err != nil {
+		return nil, err
+	}
+	if progressing != nil {
+		return nil, models.NewInvalidRolloutError("rollout %s is still progressing for model endpoint %s", progressing.Id, modelEndpoint.Id)
+	}
+
+	target, err := s.versionEndpointStorage.Get(rollout.VersionEndpointId)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, models.NewInvalidRolloutError("version endpoint %s not found", rollout.VersionEndpointId)
+		}
+		return nil, errors.Wrapf(err, "unable to find version endpoint %s", rollout.VersionEndpointId)
+	}
+
+	if target.VersionModelId != model.Id || target.EnvironmentName != modelEndpoint.EnvironmentName {
+		return nil, models.NewInvalidRolloutError("version endpoint %s doesn't belong to model %s in environment %s", target.Id, model.Name, modelEndpoint.EnvironmentName)
+	}
+
+	if target.Status != models.EndpointRunning && target.Status != models.EndpointServing {
+		return nil, models.NewInvalidRolloutError("version endpoint %s is not running, but %s", target.Id, target.Status)
+	}
+
+	if target.IsClusterLocal() {
+		return nil, models.NewInvalidRolloutError("version endpoint %s is only reachable inside its cluster", target.Id)
+	}
+
+	previousRule, err := s.refreshRule(modelEndpoint.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	rollout.ModelId = model.Id
+	rollout.ModelEndpointId = modelEndpoint.Id
+	rollout.PreviousRule = previousRule
+	rollout.CurrentStep = 0
+	rollout.Status = models.RolloutProgressing
+	rollout.Message = ""
+	if err := s.storage.Save(rollout); err != nil {
+		return nil, errors.Wrapf(err, "failed to save rollout")
+	}
+
+	if err := s.nextStep(ctx, model, modelEndpoint, target, rollout); err != nil {
+		rollout.Status = models.RolloutFailed
+		rollout.Message = err.Error()
+		if err := s.storage.Save(rollout); err != nil {
+			log.Errorf("failed to update rollout %s: %v", rollout.Id, err)
+		}
+		return nil, err
+	}
+
+	return rollout, nil
+}
+
+func (s *modelEndpointRolloutService) RollbackRollout(ctx context.Context, model *models.Model, rollout *models.ModelEndpointRollout, reason string) (*models.ModelEndpointRollout, error) {
+	if rollout.Status.IsTerminal() {
+		return nil, fmt.Errorf("rollout %s is already %s", rollout.Id, rollout.Status)
+	}
+
+	modelEndpoint, err := s.modelEndpointsService.FindById(ctx, rollout.ModelEndpointId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.rollback(ctx, model, modelEndpoint, rollout, reason); err != nil {
+		return nil, err
+	}
+	return rollout, nil
+}
+
+func (s *modelEndpointRolloutService) Run(stopCh <-chan struct{}) {
+	wait.Until(s.progressRollouts, s.syncPeriod, stopCh)
+}
+
+func (s *modelEndpointRolloutService) progressRollouts() {
+	// only the leader advances the rollouts, so that no step is applied twice
+	if !s.leader.IsLeader() {
+		return
+	}
+
+	rollouts, err := s.storage.ListProgressing()
+	if err != nil {
+		log.Errorf("unable to list progressing rollouts: %v", err)
+		return
+	}
+
+	for _, rollout := range rollouts {
+		if err := s.progress(context.Background(), rollout); err != nil {
+			log.Warnf("unable to progress rollout %s: %v", rollout.Id, err)
+		}
+	}
+}
+
+// progress moves the rollout one step forward once its step interval elapsed, or rolls it back if one of its gates fails.
+func (s *modelEndpointRolloutService) progress(ctx context.Context, rollout *models.ModelEndpointRollout) error {
+	if rollout.NextStepAt != nil && s.clock.Now().Before(*rollout.NextStepAt) {
+		return nil
+	}
+
+	model, err := s.modelsService.FindById(ctx, rollout.ModelId)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find model %s", rollout.ModelId)
+	}
+
+	modelEndpoint, err := s.modelEndpointsService.FindById(ctx, rollout.ModelEndpointId)
+	if err != nil {
+		return err
+	}
+
+	target, err := s.versionEndpointStorage.Get(rollout.VersionEndpointId)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find version endpoint %s", rollout.VersionEndpointId)
+	}
+
+	reason, err := s.checkGates(ctx, model, target, rollout)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		return s.rollback(ctx, model, modelEndpoint, rollout, reason)
+	}
+
+	if rollout.IsLastStep() {
+		return s.promote(model, modelEndpoint, rollout)
+	}
+
+	return s.nextStep(ctx, model, modelEndpoint, target, rollout)
+}
+
+// checkGates returns the reason of failure if the version endpoint doesn't satisfy all gates of the rollout.
+func (s *modelEndpointRolloutService) checkGates(ctx context.Context, model *models.Model, target *models.VersionEndpoint, rollout *models.ModelEndpointRollout) (string, error) {
+	if target.Status != models.EndpointRunning && target.Status != models.EndpointServing {
+		return fmt.Sprintf("version endpoint %s is %s", target.Id, target.Status), nil
+	}
+
+	for _, gate := range rollout.Gates {
+		value, err := s.gateEvaluator.EvaluateGate(ctx, model, target, gate)
+		if err != nil {
+			return "", errors.Wrapf(err, "unable to evaluate %s gate", gate.MetricType)
+		}
+
+		if !gate.IsSatisfied(value) {
+			return fmt.Sprintf("%s gate failed at %d%% traffic: observed %f, threshold %f", gate.MetricType, rollout.CurrentWeight(), value, gate.Threshold), nil
+		}
+	}
+
+	return "", nil
+}
+
+func (s *modelEndpointRolloutService) nextStep(ctx context.Context, model *models.Model, modelEndpoint *models.ModelEndpoint, target *models.VersionEndpoint, rollout *models.ModelEndpointRollout) error {
+	interval, err := rollout.Interval()
+	if err != nil {
+		return err
+	}
+
+	fromStep, previousNextStepAt, previousMessage := rollout.CurrentStep, rollout.NextStepAt, rollout.Message
+	weight := rollout.Steps[fromStep]
+	nextStepAt := s.clock.Now().Add(interval)
+	rollout.CurrentStep++
+	rollout.NextStepAt = &nextStepAt
+	rollout.Message = fmt.Sprintf("routing %d%% of traffic to version endpoint %s", weight, target.Id)
+
+	claimed, err := s.storage.Claim(rollout, fromStep)
+	if err != nil {
+		return errors.Wrapf(err, "failed to claim rollout %s", rollout.Id)
+	}
+	if !claimed {
+		log.Infof("rollout %s of model endpoint %s changed concurrently, skipping step %d", rollout.Id, modelEndpoint.Id, rollout.CurrentStep)
+		return nil
+	}
+
+	if err := s.applyRule(ctx, model, modelEndpoint, rollout.RuleForWeight(target, weight)); err != nil {
+		// the step is released, so that it's applied again on the next sync
+		rollout.CurrentStep, rollout.NextStepAt, rollout.Message = fromStep, previousNextStepAt, previousMessage
+		if _, err := s.storage.Claim(rollout, fromStep+1); err != nil {
+			log.Errorf("failed to release step %d of rollout %s: %v", fromStep+1, rollout.Id, err)
+		}
+		return err
+	}
+
+	target.Status = models.EndpointServing
+	if err := s.versionEndpointStorage.Save(target); err != nil {
+		return errors.Wrapf(err, "failed to update version endpoint %s", target.Id)
+	}
+
+	log.Infof("rollout %s of model endpoint %s: %s", rollout.Id, modelEndpoint.Id, rollout.Message)
+	return nil
+}
+
+func (s *modelEndpointRolloutService) promote(model *models.Model, modelEndpoint *models.ModelEndpoint, rollout *models.ModelEndpointRollout) error {
+	rollout.Status = models.RolloutPromoted
+	rollout.Message = fmt.Sprintf("version endpoint %s is serving 100%% of traffic", rollout.VersionEndpointId)
+
+	claimed, err := s.storage.Claim(rollout, rollout.CurrentStep)
+	if err != nil {
+		return errors.Wrapf(err, "failed to claim rollout %s", rollout.Id)
+	}
+	if !claimed {
+		log.Infof("rollout %s of model endpoint %s changed concurrently, skipping its promotion", rollout.Id, modelEndpoint.Id)
+		return nil
+	}
+	rolloutCounter.WithLabelValues(model.Project.Name, model.Name, string(rollout.Status)).Inc()
+	log.Infof("rollout %s of model endpoint %s: %s", rollout.Id, modelEndpoint.Id, rollout.Message)
+
+	for _, dest := range rollout.PreviousRule.Destination {
+		// Destinations still targeted by a match keep serving traffic
+		if dest.VersionEndpointID == rollout.VersionEndpointId || matchesHaveDestination(rollout.PreviousRule, dest.VersionEndpointID) {
+			continue
+		}
+
+		versionEndpoint, err := s.versionEndpointStorage.Get(dest.VersionEndpointID)
+		if err != nil {
+			return errors.Wrapf(err, "unable to find version endpoint %s", dest.VersionEndpointID)
+		}
+
+		versionEndpoint.Status = models.EndpointRunning
+		if err := s.versionEndpointStorage.Save(versionEndpoint); err != nil {
+			return errors.Wrapf(err, "failed to update version endpoint %s", versionEndpoint.Id)
+		}
+	}
+	return nil
+}
+
+// rollback restores the previous traffic rule of the model endpoint. It returns models.InvalidRolloutError if the
+// rollout was changed concurrently, in which case the rule isn't touched.
+func (s *modelEndpointRolloutService) rollback(ctx context.Context, model *models.Model, modelEndpoint *models.ModelEndpoint, rollout *models.ModelEndpointRollout, reason string) error {
+	rollout.Status = models.RolloutRolledBack
+	rollout.Message = reason
+
+	claimed, err := s.storage.Claim(rollout, rollout.CurrentStep)
+	if err != nil {
+		return errors.Wrapf(err, "failed to claim rollout %s", rollout.Id)
+	}
+	if !claimed {
+		return models.NewInvalidRolloutError("rollout %s changed while rolling it back, please retry", rollout.Id)
+	}
+
+	log.Warnf("rolling back rollout %s of model endpoint %s: %s", rollout.Id, modelEndpoint.Id, reason)
+
+	previousRule, err := s.refreshRule(rollout.PreviousRule)
+	if err == nil {
+		err = s.applyRule(ctx, model, modelEndpoint, previousRule)
+	}
+	if err != nil {
+		rollout.Status = models.RolloutFailed
+		rollout.Message = fmt.Sprintf("%s; unable to restore previous traffic rule: %v", reason, err)
+		if err := s.storage.Save(rollout); err != nil {
+			return errors.Wrapf(err, "failed to save rollout %s", rollout.Id)
+		}
+	} else if !ruleHasDestination(previousRule, rollout.VersionEndpointId) {
+		target, err := s.versionEndpointStorage.Get(rollout.VersionEndpointId)
+		if err == nil && target.Status == models.EndpointServing {
+			target.Status = models.EndpointRunning
+			err = s.versionEndpointStorage.Save(target)
+		}
+		if err != nil {
+			log.Warnf("unable to update status of version endpoint %s: %v", rollout.VersionEndpointId, err)
+		}
+	}
+
+	rolloutCounter.WithLabelValues(model.Project.Name, model.Name, string(rollout.Status)).Inc()
+	return nil
+}
+
+// applyRule updates the model endpoint's VirtualService with the given traffic rule and saves it.
+func (s *modelEndpointRolloutService) applyRule(ctx context.Context, model *models.Model, modelEndpoint *models.ModelEndpoint, rule *models.ModelEndpointRule) error {
+	modelEndpoint.Rule = rule
+	updated, err := s.modelEndpointsService.UpdateEndpoint(ctx, model, modelEndpoint)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.modelEndpointsService.Save(ctx, updated); err != nil {
+		return errors.Wrapf(err, "failed to save model endpoint %s", modelEndpoint.Id)
+	}
+	return nil
+}
+
+// refreshRule returns a copy of the rule where the version endpoints are reloaded from storage.
+func (s *modelEndpointRolloutService) refreshRule(rule *models.ModelEndpointRule) (*models.ModelEndpointRule, error) {
+	destinations, err := s.refreshDestinations(rule.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshed := &models.ModelEndpointRule{Destination: destinations}
+	for _, mirror := range rule.MirrorTargets() {
+		versionEndpoint, err := s.versionEndpointStorage.Get(mirror.VersionEndpointID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to find version endpoint %s", mirror.VersionEndpointID)
+		}
+
+		refreshed.Mirrors = append(refreshed.Mirrors, &models.ModelEndpointRuleMirror{
+			VersionEndpointID: mirror.VersionEndpointID,
+			VersionEndpoint:   versionEndpoint,
+			Percentage:        mirror.Percentage,
+		})
+	}
+	for _, match := range rule.Matches {
+		matchDestinations, err := s.refreshDestinations(match.Destination)
+		if err != nil {
+			return nil, err
+		}
+
+		refreshed.Matches = append(refreshed.Matches, &models.ModelEndpointRuleMatch{
+			Conditions:  match.Conditions,
+			Destination: matchDestinations,
+		})
+	}
+	return refreshed, nil
+}
+
+func (s *modelEndpointRolloutService) refreshDestinations(destinations []*models.ModelEndpointRuleDestination) ([]*models.ModelEndpointRuleDestination, error) {
+	var refreshed []*models.ModelEndpointRuleDestination
+	for _, dest := range destinations {
+		versionEndpoint, err := s.versionEndpointStorage.Get(dest.VersionEndpointID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to find version endpoint %s", dest.VersionEndpointID)
+		}
+
+		refreshed = append(refreshed, &models.ModelEndpointRuleDestination{
+			VersionEndpointID: dest.VersionEndpointID,
+			VersionEndpoint:   versionEndpoint,
+			Weight:            dest.Weight,
+		})
+	}
+	return refreshed, nil
+}
+
+func ruleHasDestination(rule *models.ModelEndpointRule, versionEndpointId uuid.UUID) bool {
+	for _, dest := range rule.AllDestinations() {
+		if dest.VersionEndpointID == versionEndpointId {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesHaveDestination(rule *models.ModelEndpointRule, versionEndpointId uuid.UUID) bool {
+	for _, match := range rule.Matches {
+		for _, dest := range match.Destination {
+			if dest.VersionEndpointID == versionEndpointId {
+				return true
+			}
+		}
+	}
+	return false
+}
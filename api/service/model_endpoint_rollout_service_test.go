@@ -0,0 +1,367 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/gojek/merlin/mlp"
+	"github.com/gojek/merlin/models"
+	storageMock "github.com/gojek/merlin/storage/mocks"
+)
+
+type fakeModelEndpointsService struct {
+	ModelEndpointsService
+	endpoint *models.ModelEndpoint
+}
+
+func (s *fakeModelEndpointsService) FindById(ctx context.Context, id models.Id) (*models.ModelEndpoint, error) {
+	return s.endpoint, nil
+}
+
+func (s *fakeModelEndpointsService) UpdateEndpoint(ctx context.Context, model *models.Model, endpoint *models.ModelEndpoint) (*models.ModelEndpoint, error) {
+	return endpoint, nil
+}
+
+func (s *fakeModelEndpointsService) Save(ctx context.Context, endpoint *models.ModelEndpoint) (*models.ModelEndpoint, error) {
+	s.endpoint = endpoint
+	return endpoint, nil
+}
+
+type fakeModelsService struct {
+	ModelsService
+	model *models.Model
+}
+
+func (s *fakeModelsService) FindById(ctx context.Context, modelId models.Id) (*models.Model, error) {
+	return s.model, nil
+}
+
+type fakeGateEvaluator struct {
+	value float64
+}
+
+func (e *fakeGateEvaluator) EvaluateGate(ctx context.Context, model *models.Model, versionEndpoint *models.VersionEndpoint, gate *models.RolloutGate) (float64, error) {
+	return e.value, nil
+}
+
+func weightsOf(rule *models.ModelEndpointRule) map[uuid.UUID]int32 {
+	weights := map[uuid.UUID]int32{}
+	for _, dest := range rule.Destination {
+		weights[dest.VersionEndpointID] = dest.Weight
+	}
+	return weights
+}
+
+func TestModelEndpointRolloutService_StartRollout(t *testing.T) {
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	model := &models.Model{Id: 1, Name: "my-model", Project: mlp.Project{Name: "my-project"}}
+	previousId := uuid.New()
+	targetId := uuid.New()
+
+	tests := []struct {
+		name          string
+		rollout       *models.ModelEndpointRollout
+		existing      []*models.ModelEndpointRollout
+		targetEnv     string
+		targetUrl     string
+		gateEvaluator RolloutGateEvaluator
+		wantErr       bool
+	}{
+		{
+			name:      "success",
+			rollout:   &models.ModelEndpointRollout{VersionEndpointId: targetId, Steps: models.RolloutSteps{10, 100}, StepInterval: "10m"},
+			targetEnv: "env",
+		},
+		{
+			name: "gates without metrics provider",
+			rollout: &models.ModelEndpointRollout{
+				VersionEndpointId: targetId,
+				Steps:             models.RolloutSteps{10, 100},
+				StepInterval:      "10m",
+				Gates:             models.RolloutGates{{MetricType: models.AlertConditionTypeErrorRate, Threshold: 1}},
+			},
+			targetEnv: "env",
+			wantErr:   true,
+		},
+		{
+			name:      "another rollout is progressing",
+			rollout:   &models.ModelEndpointRollout{VersionEndpointId: targetId, Steps: models.RolloutSteps{10, 100}, StepInterval: "10m"},
+			existing:  []*models.ModelEndpointRollout{{Id: 2, Status: models.RolloutProgressing}},
+			targetEnv: "env",
+			wantErr:   true,
+		},
+		{
+			name:      "version endpoint in another environment",
+			rollout:   &models.ModelEndpointRollout{VersionEndpointId: targetId, Steps: models.RolloutSteps{10, 100}, StepInterval: "10m"},
+			targetEnv: "other-env",
+			wantErr:   true,
+		},
+		{
+			name:      "version endpoint only reachable inside its cluster",
+			rollout:   &models.ModelEndpointRollout{VersionEndpointId: targetId, Steps: models.RolloutSteps{10, 100}, StepInterval: "10m"},
+			targetEnv: "env",
+			targetUrl: "http://my-model-1.my-project.svc.cluster.local/v1/models/my-model-1",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := &models.VersionEndpoint{Id: previousId, VersionModelId: 1, EnvironmentName: "env", Status: models.EndpointServing}
+			target := &models.VersionEndpoint{Id: targetId, VersionModelId: 1, EnvironmentName: tt.targetEnv, Url: tt.targetUrl, Status: models.EndpointRunning}
+			modelEndpoint := &models.ModelEndpoint{
+				Id:              1,
+				ModelId:         1,
+				EnvironmentName: "env",
+				Status:          models.EndpointServing,
+				Rule: &models.ModelEndpointRule{
+					Destination: []*models.ModelEndpointRuleDestination{{VersionEndpointID: previousId, VersionEndpoint: previous, Weight: 100}},
+				},
+			}
+
+			rolloutStorage := &storageMock.ModelEndpointRolloutStorage{}
+			rolloutStorage.On("List", models.Id(1)).Return(tt.existing, nil)
+			rolloutStorage.On("Save", mock.Anything).Return(nil)
+			rolloutStorage.On("Claim", mock.Anything, 0).Return(true, nil)
+
+			versionEndpointStorage := &storageMock.VersionEndpointStorage{}
+			versionEndpointStorage.On("Get", previousId).Return(previous, nil)
+			versionEndpointStorage.On("Get", targetId).Return(target, nil)
+			versionEndpointStorage.On("Save", mock.Anything).Return(nil)
+
+			modelEndpointsService := &fakeModelEndpointsService{endpoint: modelEndpoint}
+			svc := NewModelEndpointRolloutService(rolloutStorage, modelEndpointsService, &fakeModelsService{model: model},
+				versionEndpointStorage, tt.gateEvaluator, nil, clock.NewFakeClock(now), time.Minute)
+
+			rollout, err := svc.StartRollout(context.Background(), model, modelEndpoint, tt.rollout)
+			if tt.wantErr {
+				assert.IsType(t, &models.InvalidRolloutError{}, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, models.RolloutProgressing, rollout.Status)
+			assert.Equal(t, 1, rollout.CurrentStep)
+			assert.Equal(t, now.Add(10*time.Minute), *rollout.NextStepAt)
+			assert.Equal(t, map[uuid.UUID]int32{previousId: 100}, weightsOf(rollout.PreviousRule))
+			assert.Equal(t, map[uuid.UUID]int32{targetId: 10, previousId: 90}, weightsOf(modelEndpointsService.endpoint.Rule))
+			assert.Equal(t, models.EndpointServing, target.Status)
+		})
+	}
+}
+
+func TestModelEndpointRolloutService_progress(t *testing.T) {
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	model := &models.Model{Id: 1, Name: "my-model", Project: mlp.Project{Name: "my-project"}}
+	previousId := uuid.New()
+	targetId := uuid.New()
+
+	tests := []struct {
+		name            string
+		currentStep     int
+		nextStepAt      time.Time
+		gateValue       float64
+		wantStatus      models.RolloutStatus
+		wantStep        int
+		wantWeights     map[uuid.UUID]int32
+		wantTarget      models.EndpointStatus
+		wantPrevious    models.EndpointStatus
+		concurrent      bool
+		wantNoOperation bool
+	}{
+		{
+			name:            "step interval not elapsed",
+			currentStep:     1,
+			nextStepAt:      now.Add(time.Minute),
+			wantStatus:      models.RolloutProgressing,
+			wantStep:        1,
+			wantWeights:     map[uuid.UUID]int32{targetId: 10, previousId: 90},
+			wantTarget:      models.EndpointServing,
+			wantPrevious:    models.EndpointServing,
+			wantNoOperation: true,
+		},
+		{
+			name:         "gates pass and moves to next step",
+			currentStep:  1,
+			nextStepAt:   now,
+			gateValue:    0.5,
+			wantStatus:   models.RolloutProgressing,
+			wantStep:     2,
+			wantWeights:  map[uuid.UUID]int32{targetId: 50, previousId: 50},
+			wantTarget:   models.EndpointServing,
+			wantPrevious: models.EndpointServing,
+		},
+		{
+			name:         "rollout changed concurrently",
+			currentStep:  1,
+			nextStepAt:   now,
+			gateValue:    0.5,
+			concurrent:   true,
+			wantStatus:   models.RolloutProgressing,
+			wantStep:     2,
+			wantWeights:  map[uuid.UUID]int32{targetId: 10, previousId: 90},
+			wantTarget:   models.EndpointServing,
+			wantPrevious: models.EndpointServing,
+		},
+		{
+			name:         "gate fails and rolls back",
+			currentStep:  1,
+			nextStepAt:   now,
+			gateValue:    5,
+			wantStatus:   models.RolloutRolledBack,
+			wantStep:     1,
+			wantWeights:  map[uuid.UUID]int32{previousId: 100},
+			wantTarget:   models.EndpointRunning,
+			wantPrevious: models.EndpointServing,
+		},
+		{
+			name:         "last step passes and promotes",
+			currentStep:  3,
+			nextStepAt:   now,
+			gateValue:    0.5,
+			wantStatus:   models.RolloutPromoted,
+			wantStep:     3,
+			wantWeights:  map[uuid.UUID]int32{targetId: 100},
+			wantTarget:   models.EndpointServing,
+			wantPrevious: models.EndpointRunning,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := &models.VersionEndpoint{Id: previousId, VersionModelId: 1, EnvironmentName: "env", Status: models.EndpointServing}
+			target := &models.VersionEndpoint{Id: targetId, VersionModelId: 1, EnvironmentName: "env", Status: models.EndpointServing}
+
+			rollout := &models.ModelEndpointRollout{
+				Id:                1,
+				ModelId:           1,
+				ModelEndpointId:   1,
+				VersionEndpointId: targetId,
+				PreviousRule: &models.ModelEndpointRule{
+					Destination: []*models.ModelEndpointRuleDestination{{VersionEndpointID: previousId, VersionEndpoint: previous, Weight: 100}},
+				},
+				Steps:        models.RolloutSteps{10, 50, 100},
+				StepInterval: "10m",
+				Gates:        models.RolloutGates{{MetricType: models.AlertConditionTypeErrorRate, Threshold: 1}},
+				CurrentStep:  tt.currentStep,
+				Status:       models.RolloutProgressing,
+				NextStepAt:   &tt.nextStepAt,
+			}
+			modelEndpoint := &models.ModelEndpoint{
+				Id:     1,
+				Status: models.EndpointServing,
+				Rule:   rollout.RuleForWeight(target, rollout.CurrentWeight()),
+			}
+
+			rolloutStorage := &storageMock.ModelEndpointRolloutStorage{}
+			rolloutStorage.On("Claim", rollout, tt.currentStep).Return(!tt.concurrent, nil)
+
+			versionEndpointStorage := &storageMock.VersionEndpointStorage{}
+			versionEndpointStorage.On("Get", previousId).Return(previous, nil)
+			versionEndpointStorage.On("Get", targetId).Return(target, nil)
+			versionEndpointStorage.On("Save", mock.Anything).Return(nil)
+
+			modelEndpointsService := &fakeModelEndpointsService{endpoint: modelEndpoint}
+			svc := &modelEndpointRolloutService{
+				storage:                rolloutStorage,
+				modelEndpointsService:  modelEndpointsService,
+				modelsService:          &fakeModelsService{model: model},
+				versionEndpointStorage: versionEndpointStorage,
+				gateEvaluator:          &fakeGateEvaluator{value: tt.gateValue},
+				clock:                  clock.NewFakeClock(now),
+			}
+
+			err := svc.progress(context.Background(), rollout)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.wantStatus, rollout.Status)
+			assert.Equal(t, tt.wantStep, rollout.CurrentStep)
+			assert.Equal(t, tt.wantWeights, weightsOf(modelEndpointsService.endpoint.Rule))
+			assert.Equal(t, tt.wantTarget, target.Status)
+			assert.Equal(t, tt.wantPrevious, previous.Status)
+			if tt.wantNoOperation {
+				rolloutStorage.AssertNotCalled(t, "Claim", rollout, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestModelEndpointRolloutService_RollbackRolloutChangedConcurrently(t *testing.T) {
+	model := &models.Model{Id: 1, Name: "my-model", Project: mlp.Project{Name: "my-project"}}
+	previousId := uuid.New()
+	targetId := uuid.New()
+	previous := &models.VersionEndpoint{Id: previousId, VersionModelId: 1, EnvironmentName: "env", Status: models.EndpointServing}
+	target := &models.VersionEndpoint{Id: targetId, VersionModelId: 1, EnvironmentName: "env", Status: models.EndpointServing}
+
+	rollout := &models.ModelEndpointRollout{
+		Id:                1,
+		ModelId:           1,
+		ModelEndpointId:   1,
+		VersionEndpointId: targetId,
+		PreviousRule: &models.ModelEndpointRule{
+			Destination: []*models.ModelEndpointRuleDestination{{VersionEndpointID: previousId, VersionEndpoint: previous, Weight: 100}},
+		},
+		Steps:        models.RolloutSteps{10, 50, 100},
+		StepInterval: "10m",
+		CurrentStep:  1,
+		Status:       models.RolloutProgressing,
+	}
+	modelEndpoint := &models.ModelEndpoint{
+		Id:     1,
+		Status: models.EndpointServing,
+		Rule:   rollout.RuleForWeight(target, 50),
+	}
+
+	// the leader moved the rollout to its next step since it was read
+	rolloutStorage := &storageMock.ModelEndpointRolloutStorage{}
+	rolloutStorage.On("Claim", rollout, 1).Return(false, nil)
+
+	modelEndpointsService := &fakeModelEndpointsService{endpoint: modelEndpoint}
+	svc := &modelEndpointRolloutService{
+		storage:               rolloutStorage,
+		modelEndpointsService: modelEndpointsService,
+		modelsService:         &fakeModelsService{model: model},
+	}
+
+	_, err := svc.RollbackRollout(context.Background(), model, rollout, "rolled back manually")
+	assert.IsType(t, &models.InvalidRolloutError{}, err)
+	assert.Equal(t, map[uuid.UUID]int32{targetId: 50, previousId: 50}, weightsOf(modelEndpointsService.endpoint.Rule))
+	rolloutStorage.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+type fakeLeader bool
+
+func (l fakeLeader) IsLeader() bool {
+	return bool(l)
+}
+
+func TestModelEndpointRolloutService_progressRolloutsOnlyOnLeader(t *testing.T) {
+	rolloutStorage := &storageMock.ModelEndpointRolloutStorage{}
+	rolloutStorage.On("ListProgressing").Return([]*models.ModelEndpointRollout{}, nil)
+
+	svc := &modelEndpointRolloutService{storage: rolloutStorage, leader: fakeLeader(false)}
+	svc.progressRollouts()
+	rolloutStorage.AssertNotCalled(t, "ListProgressing")
+
+	svc.leader = fakeLeader(true)
+	svc.progressRollouts()
+	rolloutStorage.AssertCalled(t, "ListProgressing")
+}
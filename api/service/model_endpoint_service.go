@@ -19,17 +19,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/gogo/protobuf/types"
+	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 	networking "istio.io/api/networking/v1alpha3"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/gojek/merlin/istio"
 	"github.com/gojek/merlin/istio/client-go/pkg/apis/networking/v1alpha3"
 	"github.com/gojek/merlin/log"
 	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
 )
 
 const (
@@ -37,7 +45,8 @@ const (
 	defaultIstioGateway = "istio-ingressgateway.istio-system.svc.cluster.local"
 
 	defaultMatchURIPrefix = "/v1/predict"
-	predictPathSuffix     = ":predict"
+	explainMatchURIPrefix = "/v1/explain"
+	shadowHostSuffix      = "-shadow"
 
 	labelTeamName         = "gojek.com/team"
 	labelStreamName       = "gojek.com/stream"
@@ -57,6 +66,8 @@ type ModelEndpointsService interface {
 
 	DeployEndpoint(ctx context.Context, model *models.Model, endpoint *models.ModelEndpoint) (*models.ModelEndpoint, error)
 	UpdateEndpoint(ctx context.Context, model *models.Model, endpoint *models.ModelEndpoint) (*models.ModelEndpoint, error)
+	// RenderEndpoint renders the Istio resources that DeployEndpoint and UpdateEndpoint would apply, without applying them
+	RenderEndpoint(ctx context.Context, model *models.Model, endpoint *models.ModelEndpoint) (*models.DryRunResult, error)
 
 	UndeployEndpoint(ctx context.Context, model *models.Model, endpoint *models.ModelEndpoint) (*models.ModelEndpoint, error)
 }
@@ -145,6 +156,17 @@ func (s *modelEndpointsService) DeployEndpoint(ctx context.Context, model *model
 		return nil, fmt.Errorf("unable to find istio client for environment: %s", endpoint.EnvironmentName)
 	}
 
+	// Deploy the DestinationRules and the shadow VirtualService first so that requests are routed as soon as the VirtualService is created
+	if err := s.applyDestinationRules(ctx, istioClient, model, endpoint); err != nil {
+		log.Errorf("failed to apply DestinationRule: %v", err)
+		return nil, errors.Wrapf(err, "failed to apply DestinationRule resource on cluster")
+	}
+
+	if err := s.applyShadowVirtualService(ctx, istioClient, model, endpoint, vs.Spec.Hosts[0]); err != nil {
+		log.Errorf("failed to apply shadow VirtualService: %v", err)
+		return nil, errors.Wrapf(err, "failed to apply shadow VirtualService resource on cluster")
+	}
+
 	// Deploy Istio's VirtualService
 	vs, err = istioClient.CreateVirtualService(ctx, model.Project.Name, vs)
 	if err != nil {
@@ -175,6 +197,16 @@ func (s *modelEndpointsService) UpdateEndpoint(ctx context.Context, model *model
 		return nil, fmt.Errorf("unable to find istio client for environment: %s", endpoint.EnvironmentName)
 	}
 
+	if err := s.applyDestinationRules(ctx, istioClient, model, endpoint); err != nil {
+		log.Errorf("failed to apply DestinationRule: %v", err)
+		return nil, errors.Wrapf(err, "failed to apply DestinationRule resource on cluster")
+	}
+
+	if err := s.applyShadowVirtualService(ctx, istioClient, model, endpoint, vs.Spec.Hosts[0]); err != nil {
+		log.Errorf("failed to apply shadow VirtualService: %v", err)
+		return nil, errors.Wrapf(err, "failed to apply shadow VirtualService resource on cluster")
+	}
+
 	// Update Istio's VirtualService
 	vs, err = istioClient.PatchVirtualService(ctx, model.Project.Name, vs)
 	if err != nil {
@@ -182,6 +214,12 @@ func (s *modelEndpointsService) UpdateEndpoint(ctx context.Context, model *model
 		return nil, errors.Wrapf(err, "Failed to update VirtualService resource on cluster")
 	}
 
+	// The DestinationRules of the version endpoints which aren't routed to anymore, or of the removed policy, are deleted
+	if err := s.deleteDestinationRules(ctx, istioClient, model, endpoint); err != nil {
+		log.Errorf("failed to delete DestinationRule: %v", err)
+		return nil, errors.Wrapf(err, "failed to delete DestinationRule resource on cluster")
+	}
+
 	// Save to database
 	vsJSON, _ := json.Marshal(vs)
 	log.Infof("VirtualService updated: %s", vsJSON)
@@ -192,6 +230,39 @@ func (s *modelEndpointsService) UpdateEndpoint(ctx context.Context, model *model
 	return endpoint, nil
 }
 
+func (s *modelEndpointsService) RenderEndpoint(ctx context.Context, model *models.Model, endpoint *models.ModelEndpoint) (*models.DryRunResult, error) {
+	vs, err := s.createVirtualService(model, endpoint)
+	if err != nil {
+		log.Errorf("failed to create VirtualService specification: %v", err)
+		return nil, errors.Wrapf(err, "failed to create VirtualService specification")
+	}
+
+	if _, ok := s.istioClients[endpoint.EnvironmentName]; !ok {
+		log.Errorf("unable to find istio client for environment: %s", endpoint.EnvironmentName)
+		return nil, fmt.Errorf("unable to find istio client for environment: %s", endpoint.EnvironmentName)
+	}
+
+	// The resources are listed in the order they are applied
+	var objs []runtime.Object
+	for _, dr := range s.createDestinationRules(model, endpoint) {
+		dr.SetGroupVersionKind(v1alpha3.SchemeGroupVersion.WithKind("DestinationRule"))
+		objs = append(objs, dr)
+	}
+
+	shadowVs, err := s.createShadowVirtualService(model, endpoint, vs.Spec.Hosts[0])
+	if err != nil {
+		log.Errorf("failed to create shadow VirtualService specification: %v", err)
+		return nil, errors.Wrapf(err, "failed to create shadow VirtualService specification")
+	}
+	if shadowVs != nil {
+		shadowVs.SetGroupVersionKind(v1alpha3.SchemeGroupVersion.WithKind("VirtualService"))
+		objs = append(objs, shadowVs)
+	}
+
+	vs.SetGroupVersionKind(v1alpha3.SchemeGroupVersion.WithKind("VirtualService"))
+	return models.NewDryRunResult(append(objs, vs)...)
+}
+
 func (s *modelEndpointsService) UndeployEndpoint(ctx context.Context, model *models.Model, endpoint *models.ModelEndpoint) (*models.ModelEndpoint, error) {
 	istioClient, ok := s.istioClients[endpoint.EnvironmentName]
 	if !ok {
@@ -206,11 +277,20 @@ func (s *modelEndpointsService) UndeployEndpoint(ctx context.Context, model *mod
 		return nil, errors.Wrapf(err, "failed to delete VirtualService resource on cluster")
 	}
 
+	if err := s.deleteShadowVirtualService(ctx, istioClient, model); err != nil {
+		log.Errorf("failed to delete shadow VirtualService: %v", err)
+		return nil, errors.Wrapf(err, "failed to delete shadow VirtualService resource on cluster")
+	}
+
 	endpoint.Status = models.EndpointTerminated
+	if err := s.deleteDestinationRules(ctx, istioClient, model, endpoint); err != nil {
+		log.Errorf("failed to delete DestinationRule: %v", err)
+		return nil, errors.Wrapf(err, "failed to delete DestinationRule resource on cluster")
+	}
 	return endpoint, nil
 }
 
-func (s *modelEndpointsService) createVirtualService(model *models.Model, endpoint *models.ModelEndpoint) (*v1alpha3.VirtualService, error) {
+func (s *modelEndpointsService) createLabels(model *models.Model) map[string]string {
 	var labels = map[string]string{
 		labelTeamName:         model.Project.Team,
 		labelStreamName:       model.Project.Stream,
@@ -222,36 +302,145 @@ func (s *modelEndpointsService) createVirtualService(model *models.Model, endpoi
 	for _, label := range model.Project.Labels {
 		labels[labelUsersHeading+label.Key] = label.Value
 	}
+	return labels
+}
 
+func (s *modelEndpointsService) createVirtualService(model *models.Model, endpoint *models.ModelEndpoint) (*v1alpha3.VirtualService, error) {
 	vs := &v1alpha3.VirtualService{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      model.Name,
 			Namespace: model.Project.Name,
-			Labels:    labels,
+			Labels:    s.createLabels(model),
 		},
 		Spec: networking.VirtualService{},
 	}
 
+	modelEndpointHost, versionEndpointPath, httpRouteDestinations, err := s.createHTTPRouteDestinations(model, endpoint.Rule.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	mirrorDestination := &networking.Destination{}
+	var mirrorPercent *types.UInt32Value
+	if mirrors := activeMirrors(endpoint.Rule); len(mirrors) > 0 {
+		// Mirrored requests go back through the gateway with the "-shadow" suffix that Envoy appends to their Host,
+		// and are routed to the shadow version endpoints by the shadow VirtualService
+		mirrorDestination = &networking.Destination{
+			Host: defaultIstioGateway,
+		}
+		mirrorPercent = &types.UInt32Value{Value: uint32(mirrors[0].Percentage)}
+		if len(mirrors) > 1 {
+			// every request is mirrored to the chain of hops sampling the requests of each shadow version endpoint
+			mirrorPercent = &types.UInt32Value{Value: 100}
+		}
+	}
+
+	vs.Spec.Hosts = []string{modelEndpointHost}
+
+	vs.Spec.Gateways = []string{defaultGateway}
+
+	// Routes of the matches are evaluated in order before the default route
+	for _, match := range endpoint.Rule.Matches {
+		_, matchPath, matchDestinations, err := s.createHTTPRouteDestinations(model, match.Destination)
+		if err != nil {
+			return nil, err
+		}
+
+		httpMatch := createHTTPMatchRequest()
+		for _, condition := range match.Conditions {
+			switch condition.Type {
+			case models.RuleMatchHeader:
+				if httpMatch.Headers == nil {
+					httpMatch.Headers = map[string]*networking.StringMatch{}
+				}
+				httpMatch.Headers[strings.ToLower(condition.Name)] = createStringMatch(condition.GetMatchType(), condition.Value)
+			case models.RuleMatchCookie:
+				if httpMatch.Headers == nil {
+					httpMatch.Headers = map[string]*networking.StringMatch{}
+				}
+				httpMatch.Headers["cookie"] = createStringMatch(models.StringMatchRegex, cookieRegex(condition.Name, condition.Value))
+			case models.RuleMatchQuery:
+				if httpMatch.QueryParams == nil {
+					httpMatch.QueryParams = map[string]*networking.StringMatch{}
+				}
+				httpMatch.QueryParams[condition.Name] = createStringMatch(condition.GetMatchType(), condition.Value)
+			default:
+				return nil, fmt.Errorf("unsupported match condition type: %s", condition.Type)
+			}
+		}
+
+		vs.Spec.Http = append(vs.Spec.Http, &networking.HTTPRoute{
+			Match: []*networking.HTTPMatchRequest{httpMatch},
+			Rewrite: &networking.HTTPRewrite{
+				Uri: matchPath,
+			},
+
+			Route:         matchDestinations,
+			Mirror:        mirrorDestination,
+			MirrorPercent: mirrorPercent,
+		})
+	}
+
+	vs.Spec.Http = append(vs.Spec.Http, &networking.HTTPRoute{
+		Match: []*networking.HTTPMatchRequest{createHTTPMatchRequest()},
+		Rewrite: &networking.HTTPRewrite{
+			Uri: versionEndpointPath,
+		},
+
+		Route:         httpRouteDestinations,
+		Mirror:        mirrorDestination,
+		MirrorPercent: mirrorPercent,
+	})
+
+	// Explanations are only routed when every version endpoint of the default route has an explainer
+	if hasExplainers(endpoint.Rule.Destination) {
+		destinations := endpoint.Rule.Destination
+		explainerURL, err := url.Parse(destinations[len(destinations)-1].VersionEndpoint.ExplainerUrl)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse explainer url")
+		}
+
+		vs.Spec.Http = append(vs.Spec.Http, &networking.HTTPRoute{
+			Match: []*networking.HTTPMatchRequest{createURIPrefixMatchRequest(explainMatchURIPrefix)},
+			Rewrite: &networking.HTTPRewrite{
+				Uri: explainerURL.Path,
+			},
+
+			Route: httpRouteDestinations,
+		})
+	}
+
+	if endpoint.TrafficPolicy != nil {
+		for _, route := range vs.Spec.Http {
+			applyTrafficPolicy(route, endpoint.TrafficPolicy)
+		}
+	}
+
+	return vs, nil
+}
+
+// createHTTPRouteDestinations returns the model endpoint host, the predict path and the route destinations of the version endpoints.
+func (s *modelEndpointsService) createHTTPRouteDestinations(model *models.Model, destinations []*models.ModelEndpointRuleDestination) (string, string, []*networking.HTTPRouteDestination, error) {
 	modelEndpointHost := ""
 	versionEndpointPath := ""
 
 	var httpRouteDestinations []*networking.HTTPRouteDestination
-	for _, destination := range endpoint.Rule.Destination {
+	for _, destination := range destinations {
 		versionEndpoint := destination.VersionEndpoint
 
-		if versionEndpoint.Status != models.EndpointRunning {
-			return nil, fmt.Errorf("Version Endpoint (%s) is not running, but %s", versionEndpoint.Id, versionEndpoint.Status)
+		if versionEndpoint.Status != models.EndpointRunning && !versionEndpoint.IsServing() {
+			return "", "", nil, fmt.Errorf("Version Endpoint (%s) is not running, but %s", versionEndpoint.Id, versionEndpoint.Status)
 		}
 
 		meURL, err := s.parseModelEndpointHost(model, versionEndpoint)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to parse Version Endpoint URL (%s): %s, %s", versionEndpoint.Id, versionEndpoint.Url, err)
+			return "", "", nil, fmt.Errorf("Failed to parse Version Endpoint URL (%s): %s, %s", versionEndpoint.Id, versionEndpoint.Url, err)
 		}
 		modelEndpointHost = meURL
 
-		vePath, err := s.parseVersionEndpointPath(versionEndpoint)
+		vePath, err := s.predictPath(model, versionEndpoint)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to parse Version Endpoint Path (%s): %s, %s", versionEndpoint.Id, versionEndpoint.Url, err)
+			return "", "", nil, fmt.Errorf("Failed to parse Version Endpoint Path (%s): %s, %s", versionEndpoint.Id, versionEndpoint.Url, err)
 		}
 		versionEndpointPath = vePath
 
@@ -270,44 +459,338 @@ func (s *modelEndpointsService) createVirtualService(model *models.Model, endpoi
 		httpRouteDestinations = append(httpRouteDestinations, httpRouteDest)
 	}
 
-	if !strings.HasSuffix(versionEndpointPath, predictPathSuffix) {
-		versionEndpointPath += predictPathSuffix
+	return modelEndpointHost, versionEndpointPath, httpRouteDestinations, nil
+}
+
+func createHTTPMatchRequest() *networking.HTTPMatchRequest {
+	return createURIPrefixMatchRequest(defaultMatchURIPrefix)
+}
+
+func createURIPrefixMatchRequest(prefix string) *networking.HTTPMatchRequest {
+	return &networking.HTTPMatchRequest{
+		Uri: &networking.StringMatch{
+			MatchType: &networking.StringMatch_Prefix{
+				Prefix: prefix,
+			},
+		},
 	}
+}
 
-	mirrorDestination := &networking.Destination{}
-	if endpoint.Rule.Mirror != nil {
-		mirrorDestination = &networking.Destination{
-			Host: endpoint.Rule.Mirror.ServiceName,
+func createAuthorityMatchRequest(authority string) *networking.HTTPMatchRequest {
+	return &networking.HTTPMatchRequest{
+		Authority: &networking.StringMatch{
+			MatchType: &networking.StringMatch_Exact{Exact: authority},
+		},
+	}
+}
+
+func hasExplainers(destinations []*models.ModelEndpointRuleDestination) bool {
+	if len(destinations) == 0 {
+		return false
+	}
+	for _, destination := range destinations {
+		if destination.VersionEndpoint.ExplainerUrl == "" {
+			return false
 		}
 	}
+	return true
+}
 
-	vs.Spec.Hosts = []string{modelEndpointHost}
+func createStringMatch(matchType models.StringMatchType, value string) *networking.StringMatch {
+	switch matchType {
+	case models.StringMatchPrefix:
+		return &networking.StringMatch{MatchType: &networking.StringMatch_Prefix{Prefix: value}}
+	case models.StringMatchRegex:
+		return &networking.StringMatch{MatchType: &networking.StringMatch_Regex{Regex: value}}
+	default:
+		return &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: value}}
+	}
+}
 
-	vs.Spec.Gateways = []string{defaultGateway}
+// cookieRegex returns the regex matching a cookie header containing the given cookie
+func cookieRegex(name, value string) string {
+	return fmt.Sprintf(`^(.*?;\s*)?(%s=%s)(;.*)?$`, regexp.QuoteMeta(name), regexp.QuoteMeta(value))
+}
+
+// applyTrafficPolicy sets the timeout and retries of the route.
+func applyTrafficPolicy(route *networking.HTTPRoute, policy *models.TrafficPolicy) {
+	route.Timeout = durationProto(policy.Timeout)
+
+	if policy.Retries != nil {
+		route.Retries = &networking.HTTPRetry{
+			Attempts:      policy.Retries.Attempts,
+			PerTryTimeout: durationProto(policy.Retries.PerTryTimeout),
+			RetryOn:       policy.Retries.RetryOnString(),
+		}
+	}
+}
 
-	vs.Spec.Http = []*networking.HTTPRoute{
-		&networking.HTTPRoute{
-			Match: []*networking.HTTPMatchRequest{
-				&networking.HTTPMatchRequest{
-					Uri: &networking.StringMatch{
-						MatchType: &networking.StringMatch_Prefix{
-							Prefix: defaultMatchURIPrefix,
+// createDestinationRules returns the DestinationRules applying the outlier detection and connection pool settings of the model endpoint,
+// or none if it has no such settings. Each version endpoint the model endpoint routes to has its own DestinationRule attached to
+// the host of the version endpoint, so that the settings don't apply to the ingress gateway shared by all model endpoints.
+func (s *modelEndpointsService) createDestinationRules(model *models.Model, endpoint *models.ModelEndpoint) []*v1alpha3.DestinationRule {
+	policy := endpoint.TrafficPolicy
+	if endpoint.Status == models.EndpointTerminated || !policy.HasDestinationPolicy() {
+		return nil
+	}
+
+	trafficPolicy := &networking.TrafficPolicy{}
+	if policy.OutlierDetection != nil {
+		trafficPolicy.OutlierDetection = &networking.OutlierDetection{
+			ConsecutiveErrors:  policy.OutlierDetection.ConsecutiveErrors,
+			Interval:           durationProto(policy.OutlierDetection.Interval),
+			BaseEjectionTime:   durationProto(policy.OutlierDetection.BaseEjectionTime),
+			MaxEjectionPercent: policy.OutlierDetection.MaxEjectionPercent,
+		}
+	}
+
+	if pool := policy.ConnectionPool; pool != nil {
+		trafficPolicy.ConnectionPool = &networking.ConnectionPoolSettings{
+			Tcp: &networking.ConnectionPoolSettings_TCPSettings{
+				MaxConnections: pool.MaxConnections,
+				ConnectTimeout: durationProto(pool.ConnectTimeout),
+			},
+			Http: &networking.ConnectionPoolSettings_HTTPSettings{
+				Http1MaxPendingRequests:  pool.HTTP1MaxPendingRequests,
+				Http2MaxRequests:         pool.HTTP2MaxRequests,
+				MaxRequestsPerConnection: pool.MaxRequestsPerConnection,
+				MaxRetries:               pool.MaxRetries,
+			},
+		}
+	}
+
+	var rules []*v1alpha3.DestinationRule
+	seen := map[uuid.UUID]bool{}
+	for _, destination := range endpoint.Rule.AllDestinations() {
+		versionEndpoint := destination.VersionEndpoint
+		if versionEndpoint == nil || seen[versionEndpoint.Id] {
+			continue
+		}
+		seen[versionEndpoint.Id] = true
+
+		rules = append(rules, &v1alpha3.DestinationRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      versionEndpoint.InferenceServiceName,
+				Namespace: model.Project.Name,
+				Labels:    s.createLabels(model),
+			},
+			Spec: networking.DestinationRule{
+				Host:          versionEndpoint.ServiceName,
+				TrafficPolicy: trafficPolicy,
+			},
+		})
+	}
+	return rules
+}
+
+// applyDestinationRules creates or updates the DestinationRules of the model endpoint if it has outlier detection or connection pool settings.
+func (s *modelEndpointsService) applyDestinationRules(ctx context.Context, istioClient istio.Client, model *models.Model, endpoint *models.ModelEndpoint) error {
+	for _, dr := range s.createDestinationRules(model, endpoint) {
+		_, err := istioClient.PatchDestinationRule(ctx, model.Project.Name, dr)
+		if kerrors.IsNotFound(err) {
+			_, err = istioClient.CreateDestinationRule(ctx, model.Project.Name, dr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteDestinationRules deletes the DestinationRules of the model which aren't needed by the model endpoint anymore,
+// i.e. all of them once the model endpoint is terminated or doesn't have outlier detection or connection pool settings.
+func (s *modelEndpointsService) deleteDestinationRules(ctx context.Context, istioClient istio.Client, model *models.Model, endpoint *models.ModelEndpoint) error {
+	needed := map[string]bool{}
+	for _, dr := range s.createDestinationRules(model, endpoint) {
+		needed[dr.Name] = true
+	}
+
+	selector := fmt.Sprintf("%s=%s,%s=merlin", labelAppName, model.Name, labelOrchestratorName)
+	rules, err := istioClient.ListDestinationRules(ctx, model.Project.Name, selector)
+	if err != nil {
+		return err
+	}
+
+	for _, dr := range rules.Items {
+		if needed[dr.Name] {
+			continue
+		}
+
+		err := istioClient.DeleteDestinationRule(ctx, model.Project.Name, dr.Name)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// durationProto converts a validated duration, returning nil if it's empty.
+func durationProto(value string) *types.Duration {
+	d, err := time.ParseDuration(value)
+	if value == "" || err != nil {
+		return nil
+	}
+	return types.DurationProto(d)
+}
+
+// createShadowVirtualService returns the VirtualService routing mirrored requests to the shadow version endpoints, or nil if there is none.
+// A single shadow version endpoint is mirrored to directly. Istio mirrors a route to a single destination, so multiple shadow version
+// endpoints are sampled independently by a chain of hops: every mirrored request is passed from hop to hop, and each hop mirrors its
+// percentage of the requests to one shadow version endpoint. The last hop ends at a redirect answered by the gateway itself.
+func (s *modelEndpointsService) createShadowVirtualService(model *models.Model, endpoint *models.ModelEndpoint, modelEndpointHost string) (*v1alpha3.VirtualService, error) {
+	mirrors := activeMirrors(endpoint.Rule)
+	if len(mirrors) == 0 {
+		return nil, nil
+	}
+
+	vs := &v1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      shadowVirtualServiceName(model),
+			Namespace: model.Project.Name,
+			Labels:    s.createLabels(model),
+		},
+		Spec: networking.VirtualService{
+			Gateways: []string{defaultGateway},
+		},
+	}
+
+	host := modelEndpointHost + shadowHostSuffix
+	if len(mirrors) == 1 {
+		route, err := s.createShadowRoute(model, host, mirrors[0].VersionEndpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		vs.Spec.Hosts = []string{host}
+		vs.Spec.Http = []*networking.HTTPRoute{route}
+		return vs, nil
+	}
+
+	for i, mirror := range mirrors {
+		nextHost := fmt.Sprintf("%s%s-%d", modelEndpointHost, shadowHostSuffix, i+1)
+		if i+1 == len(mirrors) {
+			nextHost = modelEndpointHost + shadowHostSuffix + "-end"
+		}
+
+		// Mirrored requests of the hop go back through the gateway with the "-shadow" suffix appended to the hop's Host
+		shadowRoute, err := s.createShadowRoute(model, host+shadowHostSuffix, mirror.VersionEndpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		hopRoute := &networking.HTTPRoute{
+			Match: []*networking.HTTPMatchRequest{createAuthorityMatchRequest(host)},
+			Route: []*networking.HTTPRouteDestination{
+				{
+					Destination: &networking.Destination{
+						Host: defaultIstioGateway,
+					},
+					Headers: &networking.Headers{
+						Request: &networking.Headers_HeaderOperations{
+							Set: map[string]string{"Host": nextHost},
 						},
 					},
+					Weight: 100,
 				},
 			},
-			Rewrite: &networking.HTTPRewrite{
-				Uri: versionEndpointPath,
+			Mirror: &networking.Destination{
+				Host: defaultIstioGateway,
 			},
+			MirrorPercent: &types.UInt32Value{Value: uint32(mirror.Percentage)},
+		}
 
-			Route:  httpRouteDestinations,
-			Mirror: mirrorDestination,
-		},
+		vs.Spec.Hosts = append(vs.Spec.Hosts, host, host+shadowHostSuffix)
+		vs.Spec.Http = append(vs.Spec.Http, hopRoute, shadowRoute)
+		host = nextHost
 	}
 
+	vs.Spec.Hosts = append(vs.Spec.Hosts, host)
+	vs.Spec.Http = append(vs.Spec.Http, &networking.HTTPRoute{
+		Match: []*networking.HTTPMatchRequest{createAuthorityMatchRequest(host)},
+		Redirect: &networking.HTTPRedirect{
+			Uri: "/",
+		},
+	})
+
 	return vs, nil
 }
 
+// createShadowRoute returns the route sending the mirrored requests with the given authority to the shadow version endpoint.
+func (s *modelEndpointsService) createShadowRoute(model *models.Model, authority string, versionEndpoint *models.VersionEndpoint) (*networking.HTTPRoute, error) {
+	vePath, err := s.predictPath(model, versionEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse Version Endpoint Path (%s): %s, %s", versionEndpoint.Id, versionEndpoint.Url, err)
+	}
+
+	return &networking.HTTPRoute{
+		Match: []*networking.HTTPMatchRequest{createAuthorityMatchRequest(authority)},
+		Rewrite: &networking.HTTPRewrite{
+			Uri: vePath,
+		},
+		Route: []*networking.HTTPRouteDestination{
+			{
+				Destination: &networking.Destination{
+					Host: defaultIstioGateway,
+				},
+				Headers: &networking.Headers{
+					Request: &networking.Headers_HeaderOperations{
+						Set: map[string]string{"Host": versionEndpoint.ServiceName},
+					},
+				},
+				Weight: 100,
+			},
+		},
+	}, nil
+}
+
+// applyShadowVirtualService creates, updates or deletes the shadow VirtualService of the model endpoint according to its mirrors.
+func (s *modelEndpointsService) applyShadowVirtualService(ctx context.Context, istioClient istio.Client, model *models.Model, endpoint *models.ModelEndpoint, modelEndpointHost string) error {
+	vs, err := s.createShadowVirtualService(model, endpoint, modelEndpointHost)
+	if err != nil {
+		return err
+	}
+
+	if vs == nil {
+		return s.deleteShadowVirtualService(ctx, istioClient, model)
+	}
+
+	_, err = istioClient.PatchVirtualService(ctx, model.Project.Name, vs)
+	if kerrors.IsNotFound(err) {
+		_, err = istioClient.CreateVirtualService(ctx, model.Project.Name, vs)
+	}
+	return err
+}
+
+func (s *modelEndpointsService) deleteShadowVirtualService(ctx context.Context, istioClient istio.Client, model *models.Model) error {
+	err := istioClient.DeleteVirtualService(ctx, model.Project.Name, shadowVirtualServiceName(model))
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func shadowVirtualServiceName(model *models.Model) string {
+	return model.Name + shadowHostSuffix
+}
+
+// activeMirrors returns the mirrors of the rule whose version endpoint is deployed, ordered by decreasing percentage.
+func activeMirrors(rule *models.ModelEndpointRule) []*models.ModelEndpointRuleMirror {
+	var mirrors []*models.ModelEndpointRuleMirror
+	for _, mirror := range rule.MirrorTargets() {
+		versionEndpoint := mirror.VersionEndpoint
+		if versionEndpoint == nil || (versionEndpoint.Status != models.EndpointRunning && !versionEndpoint.IsServing()) {
+			log.Warnf("skipping mirror to version endpoint %s which is not deployed", mirror.VersionEndpointID)
+			continue
+		}
+		mirrors = append(mirrors, mirror)
+	}
+
+	sort.SliceStable(mirrors, func(i, j int) bool {
+		return mirrors[i].Percentage > mirrors[j].Percentage
+	})
+	return mirrors
+}
+
 func (s *modelEndpointsService) parseModelEndpointHost(model *models.Model, versionEndpoint *models.VersionEndpoint) (string, error) {
 	veURL, err := url.Parse(versionEndpoint.Url)
 	if err != nil {
@@ -327,6 +810,20 @@ func (s *modelEndpointsService) parseModelEndpointHost(model *models.Model, vers
 	return modelEndpointHost, nil
 }
 
+// predictPath returns the path of the prediction requests of the version endpoint, as defined by the model type.
+func (s *modelEndpointsService) predictPath(model *models.Model, versionEndpoint *models.VersionEndpoint) (string, error) {
+	handler, err := modeltype.Get(model.Type)
+	if err != nil {
+		return "", err
+	}
+
+	vePath, err := s.parseVersionEndpointPath(versionEndpoint)
+	if err != nil {
+		return "", err
+	}
+	return handler.PredictPath(vePath), nil
+}
+
 func (s *modelEndpointsService) parseVersionEndpointPath(versionEndpoint *models.VersionEndpoint) (string, error) {
 	veURL, err := url.Parse(versionEndpoint.Url)
 	if err != nil {
@@ -17,13 +17,18 @@ package service
 import (
 	"context"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gogo/protobuf/types"
 	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/mock"
 	networking "istio.io/api/networking/v1alpha3"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/gojek/merlin/istio"
 	"github.com/gojek/merlin/istio/client-go/pkg/apis/networking/v1alpha3"
@@ -208,6 +213,485 @@ func Test_createVirtualService(t *testing.T) {
 	}
 }
 
+func Test_createVirtualServiceWithMatches(t *testing.T) {
+	db, _, _ := sqlmock.New()
+	defer db.Close()
+
+	mockDB, _ := gorm.Open("postgres", db)
+
+	uuid2, _ := uuid.NewUUID()
+	versionEndpoint2 := &models.VersionEndpoint{
+		Id:                   uuid2,
+		Status:               models.EndpointRunning,
+		Url:                  "http://version-2.project-1.mlp.io/v1/models/version-2:predict",
+		ServiceName:          "version-2-abcde",
+		InferenceServiceName: "version-2",
+		Namespace:            "project-1",
+	}
+
+	endpoint := &models.ModelEndpoint{
+		ModelId: 1,
+		Rule: &models.ModelEndpointRule{
+			Destination: modelEndpointRequest1.Rule.Destination,
+			Matches: []*models.ModelEndpointRuleMatch{
+				{
+					Conditions: []*models.ModelEndpointRuleMatchCondition{
+						{Type: models.RuleMatchHeader, Name: "X-Variant", MatchType: models.StringMatchPrefix, Value: "beta"},
+						{Type: models.RuleMatchQuery, Name: "debug", Value: "true"},
+					},
+					Destination: []*models.ModelEndpointRuleDestination{
+						{VersionEndpointID: uuid2, VersionEndpoint: versionEndpoint2, Weight: 100},
+					},
+				},
+				{
+					Conditions: []*models.ModelEndpointRuleMatchCondition{
+						{Type: models.RuleMatchCookie, Name: "variant", Value: "v2.beta"},
+					},
+					Destination: []*models.ModelEndpointRuleDestination{
+						{VersionEndpointID: uuid2, VersionEndpoint: versionEndpoint2, Weight: 100},
+					},
+				},
+			},
+		},
+		EnvironmentName: env.Name,
+	}
+
+	route := func(match *networking.HTTPMatchRequest, versionEndpoint *models.VersionEndpoint, path string) *networking.HTTPRoute {
+		return &networking.HTTPRoute{
+			Match: []*networking.HTTPMatchRequest{match},
+			Route: []*networking.HTTPRouteDestination{
+				{
+					Destination: &networking.Destination{
+						Host: defaultIstioGateway,
+					},
+					Headers: &networking.Headers{
+						Request: &networking.Headers_HeaderOperations{
+							Set: map[string]string{"Host": versionEndpoint.ServiceName},
+						},
+					},
+					Weight: 100,
+				},
+			},
+			Rewrite: &networking.HTTPRewrite{
+				Uri: path,
+			},
+			Mirror: &networking.Destination{},
+		}
+	}
+	uriMatch := &networking.StringMatch{
+		MatchType: &networking.StringMatch_Prefix{
+			Prefix: defaultMatchURIPrefix,
+		},
+	}
+
+	want := []*networking.HTTPRoute{
+		route(&networking.HTTPMatchRequest{
+			Uri: uriMatch,
+			Headers: map[string]*networking.StringMatch{
+				"x-variant": {MatchType: &networking.StringMatch_Prefix{Prefix: "beta"}},
+			},
+			QueryParams: map[string]*networking.StringMatch{
+				"debug": {MatchType: &networking.StringMatch_Exact{Exact: "true"}},
+			},
+		}, versionEndpoint2, "/v1/models/version-2:predict"),
+		route(&networking.HTTPMatchRequest{
+			Uri: uriMatch,
+			Headers: map[string]*networking.StringMatch{
+				"cookie": {MatchType: &networking.StringMatch_Regex{Regex: `^(.*?;\s*)?(variant=v2\.beta)(;.*)?$`}},
+			},
+		}, versionEndpoint2, "/v1/models/version-2:predict"),
+		route(&networking.HTTPMatchRequest{Uri: uriMatch}, versionEndpoint1, "/v1/models/version-1:predict"),
+	}
+
+	s := newModelEndpointsService(map[string]istio.Client{env.Name: &mocks.Client{}}, mockDB, "staging")
+	vs, err := s.createVirtualService(model1, endpoint)
+	if err != nil {
+		t.Fatalf("modelEndpointsService.createVirtualService() error = %v", err)
+	}
+	if !reflect.DeepEqual(vs.Spec.Http, want) {
+		t.Errorf(`modelEndpointsService.createVirtualService() = %v, want %v`, vs.Spec.Http, want)
+	}
+}
+
+func Test_createShadowVirtualService(t *testing.T) {
+	db, _, _ := sqlmock.New()
+	defer db.Close()
+
+	mockDB, _ := gorm.Open("postgres", db)
+
+	newVersionEndpoint := func(name string, status models.EndpointStatus) *models.VersionEndpoint {
+		id, _ := uuid.NewUUID()
+		return &models.VersionEndpoint{
+			Id:                   id,
+			Status:               status,
+			Url:                  "http://" + name + ".project-1.mlp.io/v1/models/" + name + ":predict",
+			ServiceName:          name + "-abcde",
+			InferenceServiceName: name,
+			Namespace:            "project-1",
+		}
+	}
+	versionEndpoint2 := newVersionEndpoint("version-2", models.EndpointRunning)
+	versionEndpoint3 := newVersionEndpoint("version-3", models.EndpointRunning)
+	versionEndpoint4 := newVersionEndpoint("version-4", models.EndpointFailed)
+
+	endpoint := &models.ModelEndpoint{
+		ModelId: 1,
+		Rule: &models.ModelEndpointRule{
+			Destination: modelEndpointRequest1.Rule.Destination,
+			Mirrors: []*models.ModelEndpointRuleMirror{
+				{VersionEndpointID: versionEndpoint3.Id, VersionEndpoint: versionEndpoint3, Percentage: 10},
+				{VersionEndpointID: versionEndpoint4.Id, VersionEndpoint: versionEndpoint4, Percentage: 50},
+				{VersionEndpointID: versionEndpoint2.Id, VersionEndpoint: versionEndpoint2, Percentage: 30},
+			},
+		},
+		EnvironmentName: env.Name,
+	}
+
+	shadowRoute := func(authority string, versionEndpoint *models.VersionEndpoint) *networking.HTTPRoute {
+		return &networking.HTTPRoute{
+			Match: []*networking.HTTPMatchRequest{
+				{Authority: &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: authority}}},
+			},
+			Rewrite: &networking.HTTPRewrite{
+				Uri: "/v1/models/" + versionEndpoint.InferenceServiceName + ":predict",
+			},
+			Route: []*networking.HTTPRouteDestination{
+				{
+					Destination: &networking.Destination{
+						Host: defaultIstioGateway,
+					},
+					Headers: &networking.Headers{
+						Request: &networking.Headers_HeaderOperations{
+							Set: map[string]string{"Host": versionEndpoint.ServiceName},
+						},
+					},
+					Weight: 100,
+				},
+			},
+		}
+	}
+	hopRoute := func(authority, nextAuthority string, percentage uint32) *networking.HTTPRoute {
+		return &networking.HTTPRoute{
+			Match: []*networking.HTTPMatchRequest{
+				{Authority: &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: authority}}},
+			},
+			Route: []*networking.HTTPRouteDestination{
+				{
+					Destination: &networking.Destination{
+						Host: defaultIstioGateway,
+					},
+					Headers: &networking.Headers{
+						Request: &networking.Headers_HeaderOperations{
+							Set: map[string]string{"Host": nextAuthority},
+						},
+					},
+					Weight: 100,
+				},
+			},
+			Mirror:        &networking.Destination{Host: defaultIstioGateway},
+			MirrorPercent: &types.UInt32Value{Value: percentage},
+		}
+	}
+
+	s := newModelEndpointsService(map[string]istio.Client{env.Name: &mocks.Client{}}, mockDB, "staging")
+
+	vs, err := s.createVirtualService(model1, endpoint)
+	if err != nil {
+		t.Fatalf("modelEndpointsService.createVirtualService() error = %v", err)
+	}
+	route := vs.Spec.Http[0]
+	if !reflect.DeepEqual(route.Mirror, &networking.Destination{Host: defaultIstioGateway}) || !reflect.DeepEqual(route.MirrorPercent, &types.UInt32Value{Value: 100}) {
+		t.Errorf("modelEndpointsService.createVirtualService() mirror = %v %v, want 100%% to %s", route.Mirror, route.MirrorPercent, defaultIstioGateway)
+	}
+
+	shadow, err := s.createShadowVirtualService(model1, endpoint, vs.Spec.Hosts[0])
+	if err != nil {
+		t.Fatalf("modelEndpointsService.createShadowVirtualService() error = %v", err)
+	}
+	want := &v1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "model-1-shadow",
+			Namespace: model1.Project.Name,
+			Labels:    vs.ObjectMeta.Labels,
+		},
+		Spec: networking.VirtualService{
+			Hosts: []string{
+				"model-1.project-1.mlp.io-shadow",
+				"model-1.project-1.mlp.io-shadow-shadow",
+				"model-1.project-1.mlp.io-shadow-1",
+				"model-1.project-1.mlp.io-shadow-1-shadow",
+				"model-1.project-1.mlp.io-shadow-end",
+			},
+			Gateways: []string{defaultGateway},
+			Http: []*networking.HTTPRoute{
+				hopRoute("model-1.project-1.mlp.io-shadow", "model-1.project-1.mlp.io-shadow-1", 30),
+				shadowRoute("model-1.project-1.mlp.io-shadow-shadow", versionEndpoint2),
+				hopRoute("model-1.project-1.mlp.io-shadow-1", "model-1.project-1.mlp.io-shadow-end", 10),
+				shadowRoute("model-1.project-1.mlp.io-shadow-1-shadow", versionEndpoint3),
+				{
+					Match: []*networking.HTTPMatchRequest{
+						{Authority: &networking.StringMatch{MatchType: &networking.StringMatch_Exact{Exact: "model-1.project-1.mlp.io-shadow-end"}}},
+					},
+					Redirect: &networking.HTTPRedirect{Uri: "/"},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(shadow, want) {
+		t.Errorf("modelEndpointsService.createShadowVirtualService() = %v, want %v", shadow, want)
+	}
+
+	endpoint.Rule.Mirrors = endpoint.Rule.Mirrors[:2]
+	vs, err = s.createVirtualService(model1, endpoint)
+	if err != nil {
+		t.Fatalf("modelEndpointsService.createVirtualService() error = %v", err)
+	}
+	route = vs.Spec.Http[0]
+	if !reflect.DeepEqual(route.MirrorPercent, &types.UInt32Value{Value: 10}) {
+		t.Errorf("modelEndpointsService.createVirtualService() mirror percent = %v, want 10%%", route.MirrorPercent)
+	}
+
+	shadow, err = s.createShadowVirtualService(model1, endpoint, vs.Spec.Hosts[0])
+	if err != nil {
+		t.Fatalf("modelEndpointsService.createShadowVirtualService() error = %v", err)
+	}
+	want.Spec.Hosts = []string{"model-1.project-1.mlp.io-shadow"}
+	want.Spec.Http = []*networking.HTTPRoute{shadowRoute("model-1.project-1.mlp.io-shadow", versionEndpoint3)}
+	if !reflect.DeepEqual(shadow, want) {
+		t.Errorf("modelEndpointsService.createShadowVirtualService() = %v, want %v", shadow, want)
+	}
+
+	shadow, err = s.createShadowVirtualService(model1, modelEndpointRequest1, vs.Spec.Hosts[0])
+	if err != nil || shadow != nil {
+		t.Errorf("modelEndpointsService.createShadowVirtualService() = %v, %v, want no shadow VirtualService", shadow, err)
+	}
+}
+
+func Test_modelEndpointsService_applyShadowVirtualService(t *testing.T) {
+	versionEndpoint2 := &models.VersionEndpoint{
+		Id:          uuid.New(),
+		Status:      models.EndpointRunning,
+		Url:         "http://version-2.project-1.mlp.io/v1/models/version-2:predict",
+		ServiceName: "version-2-abcde",
+	}
+	endpoint := &models.ModelEndpoint{
+		Rule: &models.ModelEndpointRule{
+			Destination: modelEndpointRequest1.Rule.Destination,
+			Mirrors: []*models.ModelEndpointRuleMirror{
+				{VersionEndpointID: versionEndpoint2.Id, VersionEndpoint: versionEndpoint2, Percentage: 100},
+			},
+		},
+		EnvironmentName: env.Name,
+	}
+
+	mockIstio := &mocks.Client{}
+	s := newModelEndpointsService(map[string]istio.Client{env.Name: mockIstio}, nil, "staging")
+	shadow, _ := s.createShadowVirtualService(model1, endpoint, "model-1.project-1.mlp.io")
+
+	notFound := kerrors.NewNotFound(schema.GroupResource{Resource: "virtualservices"}, "model-1-shadow")
+	mockIstio.On("PatchVirtualService", context.Background(), "project-1", shadow).Return(nil, notFound)
+	mockIstio.On("CreateVirtualService", context.Background(), "project-1", shadow).Return(shadow, nil)
+	mockIstio.On("DeleteVirtualService", context.Background(), "project-1", "model-1-shadow").Return(notFound)
+
+	if err := s.applyShadowVirtualService(context.Background(), mockIstio, model1, endpoint, "model-1.project-1.mlp.io"); err != nil {
+		t.Errorf("modelEndpointsService.applyShadowVirtualService() error = %v", err)
+	}
+	mockIstio.AssertCalled(t, "CreateVirtualService", context.Background(), "project-1", shadow)
+
+	if err := s.applyShadowVirtualService(context.Background(), mockIstio, model1, modelEndpointRequest1, "model-1.project-1.mlp.io"); err != nil {
+		t.Errorf("modelEndpointsService.applyShadowVirtualService() error = %v", err)
+	}
+	mockIstio.AssertCalled(t, "DeleteVirtualService", context.Background(), "project-1", "model-1-shadow")
+}
+
+func Test_createVirtualServiceWithTrafficPolicy(t *testing.T) {
+	endpoint := &models.ModelEndpoint{
+		ModelId: 1,
+		Rule:    modelEndpointRequest1.Rule,
+		TrafficPolicy: &models.TrafficPolicy{
+			Timeout: "2s",
+			Retries: &models.RetryPolicy{
+				Attempts:      3,
+				PerTryTimeout: "500ms",
+				RetryOn:       []string{"5xx", "connect-failure"},
+			},
+			OutlierDetection: &models.OutlierDetection{
+				ConsecutiveErrors:  5,
+				Interval:           "10s",
+				BaseEjectionTime:   "30s",
+				MaxEjectionPercent: 50,
+			},
+			ConnectionPool: &models.ConnectionPool{
+				MaxConnections:   100,
+				HTTP2MaxRequests: 1000,
+			},
+		},
+		EnvironmentName: env.Name,
+	}
+
+	s := newModelEndpointsService(map[string]istio.Client{env.Name: &mocks.Client{}}, nil, "staging")
+	vs, err := s.createVirtualService(model1, endpoint)
+	if err != nil {
+		t.Fatalf("modelEndpointsService.createVirtualService() error = %v", err)
+	}
+
+	route := vs.Spec.Http[0]
+	if !reflect.DeepEqual(route.Timeout, &types.Duration{Seconds: 2}) {
+		t.Errorf("route timeout = %v, want 2s", route.Timeout)
+	}
+	wantRetries := &networking.HTTPRetry{Attempts: 3, PerTryTimeout: &types.Duration{Nanos: 500000000}, RetryOn: "5xx,connect-failure"}
+	if !reflect.DeepEqual(route.Retries, wantRetries) {
+		t.Errorf("route retries = %v, want %v", route.Retries, wantRetries)
+	}
+	if host := route.Route[0].Destination.Host; host != defaultIstioGateway {
+		t.Errorf("route destination host = %s, want %s", host, defaultIstioGateway)
+	}
+
+	want := []*v1alpha3.DestinationRule{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "version-1",
+				Namespace: "project-1",
+				Labels:    vs.ObjectMeta.Labels,
+			},
+			Spec: networking.DestinationRule{
+				Host: "version-1-abcde",
+				TrafficPolicy: &networking.TrafficPolicy{
+					OutlierDetection: &networking.OutlierDetection{
+						ConsecutiveErrors:  5,
+						Interval:           &types.Duration{Seconds: 10},
+						BaseEjectionTime:   &types.Duration{Seconds: 30},
+						MaxEjectionPercent: 50,
+					},
+					ConnectionPool: &networking.ConnectionPoolSettings{
+						Tcp:  &networking.ConnectionPoolSettings_TCPSettings{MaxConnections: 100},
+						Http: &networking.ConnectionPoolSettings_HTTPSettings{Http2MaxRequests: 1000},
+					},
+				},
+			},
+		},
+	}
+	if dr := s.createDestinationRules(model1, endpoint); !reflect.DeepEqual(dr, want) {
+		t.Errorf("modelEndpointsService.createDestinationRules() = %v, want %v", dr, want)
+	}
+
+	endpoint.Status = models.EndpointTerminated
+	if dr := s.createDestinationRules(model1, endpoint); dr != nil {
+		t.Errorf("modelEndpointsService.createDestinationRules() = %v, want nil", dr)
+	}
+
+	endpoint.Status = models.EndpointServing
+	endpoint.TrafficPolicy = &models.TrafficPolicy{Timeout: "1s"}
+	if dr := s.createDestinationRules(model1, endpoint); dr != nil {
+		t.Errorf("modelEndpointsService.createDestinationRules() = %v, want nil", dr)
+	}
+}
+
+func Test_modelEndpointsService_deleteDestinationRules(t *testing.T) {
+	endpoint := &models.ModelEndpoint{
+		ModelId: 1,
+		Rule:    modelEndpointRequest1.Rule,
+		TrafficPolicy: &models.TrafficPolicy{
+			ConnectionPool: &models.ConnectionPool{MaxConnections: 100},
+		},
+		Status:          models.EndpointServing,
+		EnvironmentName: env.Name,
+	}
+
+	mockIstio := &mocks.Client{}
+	mockIstio.On("ListDestinationRules", context.Background(), "project-1", "gojek.com/app=model-1,gojek.com/orchestrator=merlin").
+		Return(&v1alpha3.DestinationRuleList{
+			Items: []v1alpha3.DestinationRule{
+				{ObjectMeta: metav1.ObjectMeta{Name: "version-1"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "version-2"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "model-1"}},
+			},
+		}, nil)
+	mockIstio.On("DeleteDestinationRule", context.Background(), "project-1", mock.Anything).Return(nil)
+
+	s := newModelEndpointsService(map[string]istio.Client{env.Name: mockIstio}, nil, "staging")
+	if err := s.deleteDestinationRules(context.Background(), mockIstio, model1, endpoint); err != nil {
+		t.Fatalf("modelEndpointsService.deleteDestinationRules() error = %v", err)
+	}
+	mockIstio.AssertNumberOfCalls(t, "DeleteDestinationRule", 2)
+	mockIstio.AssertCalled(t, "DeleteDestinationRule", context.Background(), "project-1", "version-2")
+	mockIstio.AssertCalled(t, "DeleteDestinationRule", context.Background(), "project-1", "model-1")
+	mockIstio.AssertNotCalled(t, "DeleteDestinationRule", context.Background(), "project-1", "version-1")
+}
+
+func Test_createVirtualServiceWithExplainer(t *testing.T) {
+	versionEndpoint := *versionEndpoint1
+	versionEndpoint.ExplainerUrl = "http://version-1.project-1.mlp.io/v1/models/version-1:explain"
+	endpoint := &models.ModelEndpoint{
+		ModelId: 1,
+		Rule: &models.ModelEndpointRule{
+			Destination: []*models.ModelEndpointRuleDestination{
+				{
+					VersionEndpointID: uuid1,
+					VersionEndpoint:   &versionEndpoint,
+					Weight:            int32(100),
+				},
+			},
+		},
+		EnvironmentName: env.Name,
+	}
+
+	s := newModelEndpointsService(map[string]istio.Client{env.Name: &mocks.Client{}}, nil, "staging")
+	vs, err := s.createVirtualService(model1, endpoint)
+	if err != nil {
+		t.Fatalf("modelEndpointsService.createVirtualService() error = %v", err)
+	}
+
+	if len(vs.Spec.Http) != 2 {
+		t.Fatalf("got %d routes, want 2", len(vs.Spec.Http))
+	}
+	route := vs.Spec.Http[1]
+	if prefix := route.Match[0].Uri.GetPrefix(); prefix != explainMatchURIPrefix {
+		t.Errorf("explain route prefix = %s, want %s", prefix, explainMatchURIPrefix)
+	}
+	if uri := route.Rewrite.Uri; uri != "/v1/models/version-1:explain" {
+		t.Errorf("explain route rewrite = %s, want /v1/models/version-1:explain", uri)
+	}
+	if !reflect.DeepEqual(route.Route, vs.Spec.Http[0].Route) {
+		t.Errorf("explain route destinations = %v, want %v", route.Route, vs.Spec.Http[0].Route)
+	}
+
+	// No explain route when a version endpoint of the default route has no explainer
+	vs, _ = s.createVirtualService(model1, modelEndpointRequest1)
+	if len(vs.Spec.Http) != 1 {
+		t.Errorf("got %d routes, want 1", len(vs.Spec.Http))
+	}
+}
+
+func Test_createVirtualServiceWithCustomModel(t *testing.T) {
+	model := *model1
+	model.Type = models.ModelTypeCustom
+	versionEndpoint := *versionEndpoint1
+	versionEndpoint.Url = "http://version-1.project-1.mlp.io/v2/models/version-1/infer"
+	endpoint := &models.ModelEndpoint{
+		ModelId: 1,
+		Rule: &models.ModelEndpointRule{
+			Destination: []*models.ModelEndpointRuleDestination{
+				{
+					VersionEndpointID: uuid1,
+					VersionEndpoint:   &versionEndpoint,
+					Weight:            int32(100),
+				},
+			},
+		},
+		EnvironmentName: env.Name,
+	}
+
+	s := newModelEndpointsService(map[string]istio.Client{env.Name: &mocks.Client{}}, nil, "staging")
+	vs, err := s.createVirtualService(&model, endpoint)
+	if err != nil {
+		t.Fatalf("modelEndpointsService.createVirtualService() error = %v", err)
+	}
+	if uri := vs.Spec.Http[0].Rewrite.Uri; uri != "/v2/models/version-1/infer" {
+		t.Errorf("route rewrite = %s, want /v2/models/version-1/infer", uri)
+	}
+}
+
 func Test_modelEndpointsService_DeployEndpoint(t *testing.T) {
 	db, _, _ := sqlmock.New()
 	defer db.Close()
@@ -244,6 +728,7 @@ func Test_modelEndpointsService_DeployEndpoint(t *testing.T) {
 
 				mockIstio := s.istioClients[env.Name].(*mocks.Client)
 				mockIstio.On("CreateVirtualService", context.Background(), "project-1", vs).Return(vs, nil)
+				mockIstio.On("DeleteVirtualService", context.Background(), "project-1", "model-1-shadow").Return(nil)
 			},
 			args{
 				context.Background(),
@@ -265,6 +750,7 @@ func Test_modelEndpointsService_DeployEndpoint(t *testing.T) {
 
 				mockIstio := s.istioClients[env.Name].(*mocks.Client)
 				mockIstio.On("CreateVirtualService", context.Background(), "project-1", vs).Return(vs, nil)
+				mockIstio.On("DeleteVirtualService", context.Background(), "project-1", "model-1-shadow").Return(nil)
 			},
 			args{
 				context.Background(),
@@ -329,6 +815,8 @@ func Test_modelEndpointsService_UpdateEndpoint(t *testing.T) {
 
 				mockIstio := s.istioClients[env.Name].(*mocks.Client)
 				mockIstio.On("PatchVirtualService", context.Background(), "project-1", vs).Return(vs, nil)
+				mockIstio.On("DeleteVirtualService", context.Background(), "project-1", "model-1-shadow").Return(nil)
+				mockIstio.On("ListDestinationRules", context.Background(), "project-1", mock.Anything).Return(&v1alpha3.DestinationRuleList{}, nil)
 			},
 			args{
 				context.Background(),
@@ -350,6 +838,8 @@ func Test_modelEndpointsService_UpdateEndpoint(t *testing.T) {
 
 				mockIstio := s.istioClients[env.Name].(*mocks.Client)
 				mockIstio.On("PatchVirtualService", context.Background(), "project-1", vs).Return(vs, nil)
+				mockIstio.On("DeleteVirtualService", context.Background(), "project-1", "model-1-shadow").Return(nil)
+				mockIstio.On("ListDestinationRules", context.Background(), "project-1", mock.Anything).Return(&v1alpha3.DestinationRuleList{}, nil)
 			},
 			args{
 				context.Background(),
@@ -378,6 +868,46 @@ func Test_modelEndpointsService_UpdateEndpoint(t *testing.T) {
 	}
 }
 
+func Test_modelEndpointsService_RenderEndpoint(t *testing.T) {
+	endpoint := &models.ModelEndpoint{
+		ModelId: 1,
+		Rule:    modelEndpointRequest1.Rule,
+		TrafficPolicy: &models.TrafficPolicy{
+			ConnectionPool: &models.ConnectionPool{MaxConnections: 100},
+		},
+		EnvironmentName: env.Name,
+	}
+
+	// the resources are rendered without calling the istio client
+	mockIstio := &mocks.Client{}
+	s := newModelEndpointsService(map[string]istio.Client{env.Name: mockIstio}, nil, "staging")
+	result, err := s.RenderEndpoint(context.Background(), model1, endpoint)
+	if err != nil {
+		t.Fatalf("modelEndpointsService.RenderEndpoint() error = %v", err)
+	}
+
+	var kinds, names []string
+	for _, manifest := range result.Manifests {
+		kinds = append(kinds, manifest.Kind)
+		names = append(names, manifest.Namespace+"/"+manifest.Name)
+	}
+	if want := []string{"DestinationRule", "VirtualService"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("rendered kinds = %v, want %v", kinds, want)
+	}
+	if want := []string{"project-1/version-1", "project-1/model-1"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("rendered names = %v, want %v", names, want)
+	}
+	if !strings.Contains(result.Manifests[1].Yaml, "apiVersion: networking.istio.io/v1alpha3") {
+		t.Errorf("rendered VirtualService = %s, want the apiVersion of istio", result.Manifests[1].Yaml)
+	}
+	mockIstio.AssertExpectations(t)
+
+	_, err = s.RenderEndpoint(context.Background(), model1, modelEndpointRequestWrongEnvironment)
+	if err == nil {
+		t.Errorf("modelEndpointsService.RenderEndpoint() of unknown environment, want error")
+	}
+}
+
 func Test_modelEndpointsService_UndeployEndpoint(t *testing.T) {
 	modelEndpointResponseTerminated := modelEndpointResponse1
 	modelEndpointResponseTerminated.Status = models.EndpointTerminated
@@ -415,6 +945,8 @@ func Test_modelEndpointsService_UndeployEndpoint(t *testing.T) {
 			func(s *modelEndpointsService) {
 				mockIstio := s.istioClients[env.Name].(*mocks.Client)
 				mockIstio.On("DeleteVirtualService", context.Background(), "project-1", "model-1").Return(nil)
+				mockIstio.On("DeleteVirtualService", context.Background(), "project-1", "model-1-shadow").Return(nil)
+				mockIstio.On("ListDestinationRules", context.Background(), "project-1", mock.Anything).Return(&v1alpha3.DestinationRuleList{}, nil)
 			},
 			args{
 				context.Background(),
@@ -434,6 +966,8 @@ func Test_modelEndpointsService_UndeployEndpoint(t *testing.T) {
 			func(s *modelEndpointsService) {
 				mockIstio := s.istioClients[env.Name].(*mocks.Client)
 				mockIstio.On("DeleteVirtualService", context.Background(), "project-1", "model-1").Return(nil)
+				mockIstio.On("DeleteVirtualService", context.Background(), "project-1", "model-1-shadow").Return(nil)
+				mockIstio.On("ListDestinationRules", context.Background(), "project-1", mock.Anything).Return(&v1alpha3.DestinationRuleList{}, nil)
 			},
 			args{
 				context.Background(),
@@ -15,10 +15,11 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"time"
 
-	"github.com/jinzhu/copier"
 	"github.com/pkg/errors"
 	"github.com/prometheus/common/log"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -28,6 +29,7 @@ import (
 	"github.com/gojek/merlin/imagebuilder"
 	"github.com/gojek/merlin/mlp"
 	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
 	"github.com/gojek/merlin/storage"
 )
 
@@ -38,10 +40,14 @@ type PredictionJobService interface {
 	ListPredictionJobs(project mlp.Project, query *ListPredictionJobQuery) ([]*models.PredictionJob, error)
 	// CreatePredictionJob creates and start a new prediction job from the given model version
 	CreatePredictionJob(env *models.Environment, model *models.Model, version *models.Version, predictionJob *models.PredictionJob) (*models.PredictionJob, error)
+	// RenderPredictionJob renders the resources that CreatePredictionJob would submit, without saving nor submitting the prediction job
+	RenderPredictionJob(env *models.Environment, model *models.Model, version *models.Version, predictionJob *models.PredictionJob) (*models.DryRunResult, error)
 	// ListContainers return all containers which used for the given model version
 	ListContainers(env *models.Environment, model *models.Model, version *models.Version, predictionJob *models.PredictionJob) ([]*models.Container, error)
 	// StopPredictionJob deletes the spark application resource and cleans up the resource
 	StopPredictionJob(env *models.Environment, model *models.Model, version *models.Version, id models.Id) (*models.PredictionJob, error)
+
+	DeploymentTaskHandler
 }
 
 // ListPredictionJobQuery represent query string for list prediction job api
@@ -58,12 +64,14 @@ type predictionJobService struct {
 	store            storage.PredictionJobStorage
 	imageBuilder     imagebuilder.ImageBuilder
 	batchControllers map[string]batch.Controller
+	taskQueue        *DeploymentTaskQueue
+	quotaService     QuotaService
 	clock            clock2.Clock
 	environmentLabel string
 }
 
-func NewPredictionJobService(batchControllers map[string]batch.Controller, imageBuilder imagebuilder.ImageBuilder, store storage.PredictionJobStorage, clock clock2.Clock, environmentLabel string) PredictionJobService {
-	return &predictionJobService{store: store, imageBuilder: imageBuilder, batchControllers: batchControllers, clock: clock, environmentLabel: environmentLabel}
+func NewPredictionJobService(batchControllers map[string]batch.Controller, imageBuilder imagebuilder.ImageBuilder, store storage.PredictionJobStorage, taskQueue *DeploymentTaskQueue, quotaService QuotaService, clock clock2.Clock, environmentLabel string) PredictionJobService {
+	return &predictionJobService{store: store, imageBuilder: imageBuilder, batchControllers: batchControllers, taskQueue: taskQueue, quotaService: quotaService, clock: clock, environmentLabel: environmentLabel}
 }
 
 // GetPredictionJob return prediction job with given ID
@@ -90,6 +98,54 @@ func (p *predictionJobService) ListPredictionJobs(project mlp.Project, query *Li
 // The method directly return a prediction job in pending state and execution happens asynchronously
 // Use GetPredictionJOb / ListPredictionJobs to get the status of the prediction job
 func (p *predictionJobService) CreatePredictionJob(env *models.Environment, model *models.Model, version *models.Version, predictionJob *models.PredictionJob) (*models.PredictionJob, error) {
+	predictionJob, err := p.preparePredictionJob(env, model, version, predictionJob)
+	if err != nil {
+		return nil, err
+	}
+
+	// the active jobs are counted and the job saved under the lock of the quota, so that concurrent creations can't exceed it
+	if err := p.quotaService.SavePredictionJob(model.ProjectId, predictionJob); err != nil {
+		return nil, err
+	}
+
+	err = p.taskQueue.Enqueue(&models.DeploymentTask{
+		Type:            models.SubmitPredictionJobTask,
+		PredictionJobId: &predictionJob.Id,
+		Payload:         models.NewDeploymentTaskPayload(model, version, ""),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed enqueueing prediction job")
+	}
+	return predictionJob, nil
+}
+
+// RenderPredictionJob renders the resources that the submission of the prediction job would create,
+// using the image that would be built. The prediction job is neither saved nor submitted.
+func (p *predictionJobService) RenderPredictionJob(env *models.Environment, model *models.Model, version *models.Version, predictionJob *models.PredictionJob) (*models.DryRunResult, error) {
+	predictionJob, err := p.preparePredictionJob(env, model, version, predictionJob)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.quotaService.CheckPredictionJob(model.ProjectId, env.Name); err != nil {
+		return nil, err
+	}
+
+	ctl, ok := p.batchControllers[env.Name]
+	if !ok {
+		log.Errorf("environment %s is not found", env.Name)
+		return nil, fmt.Errorf("environment %s is not found", env.Name)
+	}
+
+	predictionJob.Config.ImageRef = p.imageBuilder.ImageRef(model.Project, model, version)
+	objs, err := ctl.Render(predictionJob, model.Project.Name)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewDryRunResult(objs...)
+}
+
+// preparePredictionJob names the prediction job and applies the defaults of the environment, then checks that it can be submitted.
+func (p *predictionJobService) preparePredictionJob(env *models.Environment, model *models.Model, version *models.Version, predictionJob *models.PredictionJob) (*models.PredictionJob, error) {
 	jobName := fmt.Sprintf("%s-%s-%s", model.Name, version.Id, strconv.FormatInt(p.clock.Now().UnixNano(), 10)[:13])
 
 	predictionJob.Name = jobName
@@ -111,30 +167,61 @@ func (p *predictionJobService) CreatePredictionJob(env *models.Environment, mode
 	if err := p.validate(model, version, predictionJob); err != nil {
 		return nil, err
 	}
+	if ctl, ok := p.batchControllers[env.Name]; ok {
+		if err := ctl.Validate(predictionJob); err != nil {
+			return nil, err
+		}
+	}
+
+	return predictionJob, nil
+}
 
-	if err := p.store.Save(predictionJob); err != nil {
-		return nil, errors.Wrapf(err, "failed saving prediction job")
+// ExecuteDeploymentTask builds the image of the prediction job and submits it to the batch controller
+func (p *predictionJobService) ExecuteDeploymentTask(ctx context.Context, task *models.DeploymentTask) error {
+	if task.PredictionJobId == nil || task.Payload == nil {
+		return fmt.Errorf("deployment task %s has no prediction job", task.Id)
 	}
 
-	// Run asynchronously
-	go func() {
-		// copy to avoid data race
-		job := new(models.PredictionJob)
-		_ = copier.Copy(job, predictionJob)
+	job, err := p.store.Get(*task.PredictionJobId)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find prediction job %s", task.PredictionJobId)
+	}
 
-		err := p.doCreatePredictionJob(env, model, version, job)
-		if err != nil {
-			batch.BatchCounter.WithLabelValues(model.Project.Name, model.Name, string(models.JobFailedSubmission)).Inc()
-			predictionJob.Status = models.JobFailedSubmission
-			predictionJob.Error = err.Error()
-			if err := p.store.Save(predictionJob); err != nil {
-				log.Warnf("failed updating prediction job: %v", err)
-			}
+	model := task.Payload.GetModel()
+	err = p.doCreatePredictionJob(ctx, job.Environment, model, task.Payload.Version, job)
+	if ctx.Err() != nil {
+		// the task has been claimed by another replica, which owns the prediction job from now on
+		return errLeaseLost
+	}
+	if err != nil {
+		job.Status = models.JobFailedSubmission
+		job.Error = err.Error()
+		if err := p.store.Save(job); err != nil {
+			log.Warnf("failed updating prediction job: %v", err)
 		}
+	}
 
-		batch.BatchCounter.WithLabelValues(model.Project.Name, model.Name, string(job.Status)).Inc()
-	}()
-	return predictionJob, nil
+	batch.BatchCounter.WithLabelValues(model.Project.Name, model.Name, string(job.Status)).Inc()
+	return err
+}
+
+// ReconcileOrphans marks pending prediction jobs without any unfinished deployment task as failed submission
+func (p *predictionJobService) ReconcileOrphans(ctx context.Context, updatedBefore time.Time) error {
+	jobs, err := p.store.ListOrphanedPending(updatedBefore)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		log.Warnf("marking orphaned pending prediction job %s as failed submission", job.Id)
+
+		job.Status = models.JobFailedSubmission
+		job.Error = "submission was interrupted, please recreate the prediction job"
+		if err := p.store.Save(job); err != nil {
+			return errors.Wrapf(err, "failed updating prediction job %s", job.Id)
+		}
+	}
+	return nil
 }
 
 func (p *predictionJobService) ListContainers(env *models.Environment, model *models.Model, version *models.Version, predictionJob *models.PredictionJob) ([]*models.Container, error) {
@@ -143,7 +230,7 @@ func (p *predictionJobService) ListContainers(env *models.Environment, model *mo
 		return nil, fmt.Errorf("unable to find batch controller for environment %s", env.Name)
 	}
 	containers := make([]*models.Container, 0)
-	if model.Type == models.ModelTypePyFuncV2 {
+	if handler, err := modeltype.Get(model.Type); err == nil && handler.SupportsBatch() {
 		imgBuilderContainers, err := p.imageBuilder.GetContainers(model.Project, model, version)
 		if err != nil {
 			return nil, err
@@ -160,10 +247,13 @@ func (p *predictionJobService) ListContainers(env *models.Environment, model *mo
 	return containers, nil
 }
 
-func (p *predictionJobService) doCreatePredictionJob(env *models.Environment, model *models.Model, version *models.Version, job *models.PredictionJob) error {
+func (p *predictionJobService) doCreatePredictionJob(ctx context.Context, env *models.Environment, model *models.Model, version *models.Version, job *models.PredictionJob) error {
 	project := model.Project
 
 	// build image
+	if err := checkLease(ctx); err != nil {
+		return err
+	}
 	imageRef, err := p.imageBuilder.BuildImage(project, model, version)
 	if err != nil {
 		return err
@@ -177,6 +267,9 @@ func (p *predictionJobService) doCreatePredictionJob(env *models.Environment, mo
 	}
 
 	// submit spark application
+	if err := checkLease(ctx); err != nil {
+		return err
+	}
 	return ctl.Submit(job, project.Name)
 }
 
@@ -229,9 +322,14 @@ func (p *predictionJobService) applyDefaults(env *models.Environment, job *model
 }
 
 func (p *predictionJobService) validate(model *models.Model, _ *models.Version, job *models.PredictionJob) error {
-	if model.Type != models.ModelTypePyFuncV2 {
+	if handler, err := modeltype.Get(model.Type); err != nil || !handler.SupportsBatch() {
 		return fmt.Errorf("model type %s is not yet supported", model.Type)
 	}
+	for _, ev := range job.Config.EnvVars {
+		if ev.IsSecret() {
+			return fmt.Errorf("environment variable '%s' can't reference a secret in a prediction job", ev.Name)
+		}
+	}
 	if job.Config.ResourceRequest.ExecutorReplica < 0 {
 		return fmt.Errorf("invalid executor replica: %d", job.Config.ResourceRequest.ExecutorReplica)
 	}
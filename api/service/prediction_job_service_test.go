@@ -15,18 +15,25 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/jinzhu/copier"
+	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/clock"
 
 	"github.com/gojek/merlin/batch"
 	"github.com/gojek/merlin/batch/mocks"
+	"github.com/gojek/merlin/config"
 	imageBuilderMock "github.com/gojek/merlin/imagebuilder/mocks"
 	"github.com/gojek/merlin/mlp"
 	"github.com/gojek/merlin/models"
@@ -121,7 +128,7 @@ var (
 )
 
 func TestGetPredictionJob(t *testing.T) {
-	svc, _, _, mockStorage := newMockPredictionJobService()
+	svc, _, _, mockStorage, _, _ := newMockPredictionJobService()
 	mockStorage.On("Get", job.Id).Return(job, nil)
 	j, err := svc.GetPredictionJob(predJobEnv, model, version, job.Id)
 	assert.NoError(t, err)
@@ -131,7 +138,7 @@ func TestGetPredictionJob(t *testing.T) {
 
 func TestListPredictionJob(t *testing.T) {
 	jobs := []*models.PredictionJob{job}
-	svc, _, _, mockStorage := newMockPredictionJobService()
+	svc, _, _, mockStorage, _, _ := newMockPredictionJobService()
 	query := &ListPredictionJobQuery{
 		Id:        1,
 		Name:      "test",
@@ -158,30 +165,192 @@ func TestListPredictionJob(t *testing.T) {
 }
 
 func TestCreatePredictionJob(t *testing.T) {
-	svc, mockControllers, mockImageBuilder, mockStorage := newMockPredictionJobService()
+	svc, mockControllers, _, mockStorage, mockTaskStorage, mockQuotaStorage := newMockPredictionJobService()
 
-	// test positive case
-	savedJob := new(models.PredictionJob)
-	err := copier.Copy(savedJob, job)
-	savedJob.Config.ImageRef = imageRef
-
-	mockStorage.On("Save", job).Return(nil)
-	mockImageBuilder.On("BuildImage", project, model, version).Return(imageRef, nil)
-	mockController := mockControllers[envName]
-	mockController.(*mocks.Controller).On("Submit", savedJob, project.Name).Return(nil)
+	mockControllers[envName].(*mocks.Controller).On("Validate", job).Return(nil)
+	mockQuotaStorage.On("Get", model.ProjectId, predJobEnv.Name).Return(nil, gorm.ErrRecordNotFound)
+	mockQuotaStorage.On("SavePredictionJob", model.ProjectId, job, mock.Anything).Return(nil)
+	mockTaskStorage.On("Save", mock.Anything).Return(nil)
 
 	j, err := svc.CreatePredictionJob(predJobEnv, model, version, reqJob)
-	time.Sleep(10 * time.Millisecond)
 	assert.NoError(t, err)
 	assert.Equal(t, job, j)
 
+	mockTaskStorage.AssertNumberOfCalls(t, "Save", 1)
+	task := mockTaskStorage.Calls[0].Arguments[0].(*models.DeploymentTask)
+	assert.Equal(t, models.SubmitPredictionJobTask, task.Type)
+	assert.Equal(t, models.TaskPending, task.Status)
+	assert.Equal(t, job.Id, *task.PredictionJobId)
+	assert.Equal(t, model.Name, task.Payload.Model.Name)
+	assert.Equal(t, version.Id, task.Payload.Version.Id)
+
+	mockQuotaStorage.AssertExpectations(t)
+	mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestCreatePredictionJob_QuotaExceeded(t *testing.T) {
+	svc, mockControllers, _, mockStorage, mockTaskStorage, mockQuotaStorage := newMockPredictionJobService()
+
+	mockControllers[envName].(*mocks.Controller).On("Validate", mock.Anything).Return(nil)
+	maxPredictionJobs := 2
+	mockQuotaStorage.On("Get", model.ProjectId, predJobEnv.Name).Return(&models.ProjectQuota{
+		Limits: models.QuotaLimits{PredictionJobs: &maxPredictionJobs},
+	}, nil)
+	mockQuotaStorage.On("SavePredictionJob", model.ProjectId, mock.Anything, mock.Anything).Return(
+		func(_ models.Id, _ *models.PredictionJob, check func(int) error) error {
+			return check(2)
+		})
+
+	_, err := svc.CreatePredictionJob(predJobEnv, model, version, reqJob)
+	assert.Equal(t, &models.QuotaExceededError{Resource: models.QuotaPredictionJobs, Usage: "2", RequestedUsage: "3", Limit: "2"}, err)
+	mockQuotaStorage.AssertNotCalled(t, "CountActivePredictionJobs", mock.Anything, mock.Anything)
+
+	mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+	mockTaskStorage.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestCreatePredictionJob_InvalidNodePool(t *testing.T) {
+	svc, mockControllers, _, mockStorage, mockTaskStorage, mockQuotaStorage := newMockPredictionJobService()
+
+	validateErr := errors.New("node pool gpu is not available in the environment")
+	mockControllers[envName].(*mocks.Controller).On("Validate", mock.Anything).Return(validateErr)
+
+	_, err := svc.CreatePredictionJob(predJobEnv, model, version, reqJob)
+	assert.Equal(t, validateErr, err)
+
+	mockQuotaStorage.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+	mockTaskStorage.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestRenderPredictionJob(t *testing.T) {
+	svc, mockControllers, mockImageBuilder, mockStorage, mockTaskStorage, mockQuotaStorage := newMockPredictionJobService()
+
+	mockQuotaStorage.On("Get", model.ProjectId, predJobEnv.Name).Return(nil, gorm.ErrRecordNotFound)
+	mockImageBuilder.On("ImageRef", project, model, version).Return(imageRef)
+	renderedJob := new(models.PredictionJob)
+	_ = copier.Copy(renderedJob, job)
+	renderedJob.Config.ImageRef = imageRef
+	jobSpec := &v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: job.Name, Namespace: project.Name},
+	}
+	mockController := mockControllers[envName].(*mocks.Controller)
+	mockController.On("Validate", mock.Anything).Return(nil)
+	mockController.On("Render", renderedJob, project.Name).Return([]runtime.Object{jobSpec}, nil)
+
+	req := new(models.PredictionJob)
+	_ = copier.Copy(req, reqJob)
+	result, err := svc.RenderPredictionJob(predJobEnv, model, version, req)
+	assert.NoError(t, err)
+	assert.Len(t, result.Manifests, 1)
+	assert.Equal(t, "ConfigMap", result.Manifests[0].Kind)
+	assert.Equal(t, job.Name, result.Manifests[0].Name)
+
+	// the prediction job is neither saved nor submitted
+	mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+	mockTaskStorage.AssertNotCalled(t, "Save", mock.Anything)
+	mockImageBuilder.AssertNotCalled(t, "BuildImage", mock.Anything, mock.Anything, mock.Anything)
+	mockController.AssertNotCalled(t, "Submit", mock.Anything, mock.Anything)
+}
+
+func TestExecuteDeploymentTask(t *testing.T) {
+	tests := []struct {
+		name          string
+		buildImageErr error
+		submitErr     error
+		wantStatus    models.State
+	}{
+		{
+			name:       "success",
+			wantStatus: models.JobPending,
+		},
+		{
+			name:          "failed building image",
+			buildImageErr: errors.New("failed building image"),
+			wantStatus:    models.JobFailedSubmission,
+		},
+		{
+			name:       "failed submitting job",
+			submitErr:  errors.New("failed submitting job"),
+			wantStatus: models.JobFailedSubmission,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, mockControllers, mockImageBuilder, mockStorage, _, _ := newMockPredictionJobService()
+
+			storedJob := new(models.PredictionJob)
+			_ = copier.Copy(storedJob, job)
+			savedJob := new(models.PredictionJob)
+			_ = copier.Copy(savedJob, job)
+			savedJob.Config.ImageRef = imageRef
+
+			matchVersion := mock.MatchedBy(func(v *models.Version) bool { return v.Id == version.Id })
+			mockStorage.On("Get", job.Id).Return(storedJob, nil)
+			mockStorage.On("Save", mock.Anything).Return(nil)
+			mockImageBuilder.On("BuildImage", project, model, matchVersion).Return(imageRef, tt.buildImageErr)
+			mockController := mockControllers[envName]
+			mockController.(*mocks.Controller).On("Submit", savedJob, project.Name).Return(tt.submitErr)
+
+			task := &models.DeploymentTask{
+				Type:            models.SubmitPredictionJobTask,
+				PredictionJobId: &job.Id,
+				Payload:         models.NewDeploymentTaskPayload(model, version, ""),
+			}
+			err := svc.ExecuteDeploymentTask(context.Background(), task)
+			if tt.wantStatus == models.JobFailedSubmission {
+				assert.Error(t, err)
+				mockStorage.AssertCalled(t, "Save", storedJob)
+			} else {
+				assert.NoError(t, err)
+				mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+			}
+			assert.Equal(t, tt.wantStatus, storedJob.Status)
+			mockImageBuilder.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExecuteDeploymentTask_LeaseLost(t *testing.T) {
+	svc, mockControllers, mockImageBuilder, mockStorage, _, _ := newMockPredictionJobService()
+
+	storedJob := new(models.PredictionJob)
+	_ = copier.Copy(storedJob, job)
+	mockStorage.On("Get", job.Id).Return(storedJob, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := svc.ExecuteDeploymentTask(ctx, &models.DeploymentTask{
+		Type:            models.SubmitPredictionJobTask,
+		PredictionJobId: &job.Id,
+		Payload:         models.NewDeploymentTaskPayload(model, version, ""),
+	})
+	assert.Equal(t, errLeaseLost, err)
+
+	mockImageBuilder.AssertNotCalled(t, "BuildImage", mock.Anything, mock.Anything, mock.Anything)
+	mockControllers[envName].(*mocks.Controller).AssertNotCalled(t, "Submit", mock.Anything, mock.Anything)
+	mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+	assert.Equal(t, job.Status, storedJob.Status)
+}
+
+func TestReconcileOrphans(t *testing.T) {
+	svc, _, _, mockStorage, _, _ := newMockPredictionJobService()
+
+	orphanedJob := &models.PredictionJob{Id: 1, Status: models.JobPending}
+	updatedBefore := now.Add(-10 * time.Minute)
+	mockStorage.On("ListOrphanedPending", updatedBefore).Return([]*models.PredictionJob{orphanedJob}, nil)
+	mockStorage.On("Save", orphanedJob).Return(nil)
+
+	err := svc.ReconcileOrphans(context.Background(), updatedBefore)
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobFailedSubmission, orphanedJob.Status)
 	mockStorage.AssertExpectations(t)
-	mockImageBuilder.AssertExpectations(t)
-	mockController.(*mocks.Controller).AssertExpectations(t)
 }
 
 func TestStopPredictionJob(t *testing.T) {
-	svc, mockControllers, mockImageBuilder, mockStorage := newMockPredictionJobService()
+	svc, mockControllers, mockImageBuilder, mockStorage, _, _ := newMockPredictionJobService()
 
 	// test positive case
 	savedJob := new(models.PredictionJob)
@@ -246,7 +415,7 @@ func TestInvalidResourceRequest(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			svc, _, _, _ := newMockPredictionJobService()
+			svc, _, _, _, _, _ := newMockPredictionJobService()
 			reqJob.Config = &models.Config{
 				ResourceRequest: test.resourceRequest,
 			}
@@ -332,7 +501,7 @@ func TestPredictionJobService_ListContainers(t *testing.T) {
 		imgBuilder.On("GetContainers", mock.Anything, mock.Anything, mock.Anything).
 			Return(tt.mock.imageBuilderContainer, nil)
 
-		svc, mockControllers, _, _ := newMockPredictionJobService()
+		svc, mockControllers, _, _, _, _ := newMockPredictionJobService()
 		mockController := mockControllers[tt.args.env.Name]
 		mockController.(*mocks.Controller).On("GetContainers", "my-project", "prediction-job-id=2").Return(tt.mock.modelContainers, nil)
 
@@ -352,13 +521,16 @@ func TestPredictionJobService_ListContainers(t *testing.T) {
 	}
 }
 
-func newMockPredictionJobService() (PredictionJobService, map[string]batch.Controller, *imageBuilderMock.ImageBuilder, *storageMock.PredictionJobStorage) {
+func newMockPredictionJobService() (PredictionJobService, map[string]batch.Controller, *imageBuilderMock.ImageBuilder, *storageMock.PredictionJobStorage, *storageMock.DeploymentTaskStorage, *storageMock.ProjectQuotaStorage) {
 	mockController := &mocks.Controller{}
 	mockControllers := map[string]batch.Controller{
 		predJobEnv.Name: mockController,
 	}
 	mockImageBuilder := &imageBuilderMock.ImageBuilder{}
 	mockStorage := &storageMock.PredictionJobStorage{}
+	mockTaskStorage := &storageMock.DeploymentTaskStorage{}
+	mockQuotaStorage := &storageMock.ProjectQuotaStorage{}
 	mockClock := clock.NewFakeClock(now)
-	return NewPredictionJobService(mockControllers, mockImageBuilder, mockStorage, mockClock, environmentLabel), mockControllers, mockImageBuilder, mockStorage
+	taskQueue := NewDeploymentTaskQueue(mockTaskStorage, "test", config.DeploymentQueueConfig{MaxAttempts: 3}, mockClock)
+	return NewPredictionJobService(mockControllers, mockImageBuilder, mockStorage, taskQueue, NewQuotaService(mockQuotaStorage, nil), mockClock, environmentLabel), mockControllers, mockImageBuilder, mockStorage, mockTaskStorage, mockQuotaStorage
 }
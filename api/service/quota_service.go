@@ -0,0 +1,201 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/storage"
+)
+
+// QuotaService manages the resource quotas of projects and enforces them on deployments
+type QuotaService interface {
+	// GetQuota return the quota of a project in an environment along with its usage, the default quota of the environment applies if the project has none
+	GetQuota(projectId models.Id, environmentName string) (*models.QuotaStatus, error)
+	// SetQuota creates or replaces the quota of a project in an environment
+	SetQuota(projectId models.Id, environmentName string, limits models.QuotaLimits) (*models.ProjectQuota, error)
+	// DeleteQuota deletes the quota of a project in an environment, reverting it to the default quota of the environment
+	DeleteQuota(projectId models.Id, environmentName string) error
+	// CheckEndpoint returns a QuotaExceededError if deploying the version endpoint would exceed the quota of the project
+	CheckEndpoint(projectId models.Id, endpoint *models.VersionEndpoint) error
+	// SaveEndpoint saves the version endpoint to be deployed, or returns a QuotaExceededError without saving it if its
+	// deployment would exceed the quota of the project. The quota is locked while checking and saving the endpoint.
+	SaveEndpoint(projectId models.Id, endpoint *models.VersionEndpoint) error
+	// CheckPredictionJob returns a QuotaExceededError if starting a prediction job would exceed the quota of the project
+	CheckPredictionJob(projectId models.Id, environmentName string) error
+	// SavePredictionJob saves the prediction job to be submitted, or returns a QuotaExceededError without saving it if
+	// starting it would exceed the quota of the project. The quota is locked while checking and saving the job.
+	SavePredictionJob(projectId models.Id, job *models.PredictionJob) error
+}
+
+type quotaService struct {
+	storage       storage.ProjectQuotaStorage
+	defaultQuotas map[string]models.QuotaLimits
+}
+
+// NewQuotaService creates a quota service falling back to the default quotas, keyed by environment name, for projects without their own quota
+func NewQuotaService(storage storage.ProjectQuotaStorage, defaultQuotas map[string]models.QuotaLimits) QuotaService {
+	return &quotaService{storage: storage, defaultQuotas: defaultQuotas}
+}
+
+func (q *quotaService) GetQuota(projectId models.Id, environmentName string) (*models.QuotaStatus, error) {
+	limits, isDefault, err := q.getLimits(projectId, environmentName)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := q.getUsage(projectId, environmentName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.QuotaStatus{
+		EnvironmentName: environmentName,
+		Limits:          limits,
+		Usage:           usage,
+		IsDefault:       isDefault,
+	}, nil
+}
+
+func (q *quotaService) SetQuota(projectId models.Id, environmentName string, limits models.QuotaLimits) (*models.ProjectQuota, error) {
+	quota, err := q.storage.Get(projectId, environmentName)
+	if err != nil {
+		if !gorm.IsRecordNotFoundError(err) {
+			return nil, err
+		}
+		quota = &models.ProjectQuota{
+			ProjectId:       projectId,
+			EnvironmentName: environmentName,
+		}
+	}
+
+	quota.Limits = limits
+	return q.storage.Save(quota)
+}
+
+func (q *quotaService) DeleteQuota(projectId models.Id, environmentName string) error {
+	return q.storage.Delete(projectId, environmentName)
+}
+
+func (q *quotaService) CheckEndpoint(projectId models.Id, endpoint *models.VersionEndpoint) error {
+	limits, _, err := q.getLimits(projectId, endpoint.EnvironmentName)
+	if err != nil || limits.IsUnlimited() {
+		return err
+	}
+
+	endpoints, err := q.storage.ListActiveEndpoints(projectId, endpoint.EnvironmentName)
+	if err != nil {
+		return err
+	}
+	return checkEndpoint(limits, endpoints, endpoint)
+}
+
+func (q *quotaService) SaveEndpoint(projectId models.Id, endpoint *models.VersionEndpoint) error {
+	limits, _, err := q.getLimits(projectId, endpoint.EnvironmentName)
+	if err != nil {
+		return err
+	}
+
+	return q.storage.SaveEndpoint(projectId, endpoint, func(active []*models.VersionEndpoint) error {
+		return checkEndpoint(limits, active, endpoint)
+	})
+}
+
+// checkEndpoint checks the usage of the active endpoints of the project after the deployment of the endpoint
+func checkEndpoint(limits models.QuotaLimits, active []*models.VersionEndpoint, endpoint *models.VersionEndpoint) error {
+	// Prediction jobs are left out as they aren't affected by the deployment
+	before, after := &models.QuotaUsage{}, &models.QuotaUsage{}
+	modelBefore, modelAfter := 0, 1
+	for _, ep := range active {
+		before.AddEndpoint(ep)
+		if ep.VersionModelId == endpoint.VersionModelId {
+			modelBefore++
+		}
+		if ep.Id != endpoint.Id {
+			after.AddEndpoint(ep)
+			if ep.VersionModelId == endpoint.VersionModelId {
+				modelAfter++
+			}
+		}
+	}
+	after.AddEndpoint(endpoint)
+
+	if err := limits.CheckIncrease(before, after); err != nil {
+		return err
+	}
+	return limits.CheckModelEndpointsIncrease(modelBefore, modelAfter)
+}
+
+func (q *quotaService) CheckPredictionJob(projectId models.Id, environmentName string) error {
+	limits, _, err := q.getLimits(projectId, environmentName)
+	if err != nil || limits.PredictionJobs == nil {
+		return err
+	}
+
+	count, err := q.storage.CountActivePredictionJobs(projectId, environmentName)
+	if err != nil {
+		return err
+	}
+
+	return checkPredictionJob(limits, count)
+}
+
+func (q *quotaService) SavePredictionJob(projectId models.Id, job *models.PredictionJob) error {
+	limits, _, err := q.getLimits(projectId, job.EnvironmentName)
+	if err != nil {
+		return err
+	}
+
+	return q.storage.SavePredictionJob(projectId, job, func(active int) error {
+		return checkPredictionJob(limits, active)
+	})
+}
+
+// checkPredictionJob checks the number of active prediction jobs of the project after starting another one
+func checkPredictionJob(limits models.QuotaLimits, active int) error {
+	return limits.CheckIncrease(&models.QuotaUsage{PredictionJobs: active}, &models.QuotaUsage{PredictionJobs: active + 1})
+}
+
+// getLimits return the quota limits of the project and whether they are the default ones of the environment
+func (q *quotaService) getLimits(projectId models.Id, environmentName string) (models.QuotaLimits, bool, error) {
+	quota, err := q.storage.Get(projectId, environmentName)
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return q.defaultQuotas[environmentName], true, nil
+		}
+		return models.QuotaLimits{}, false, err
+	}
+	return quota.Limits, false, nil
+}
+
+// getUsage return the resources used by the project in the environment
+func (q *quotaService) getUsage(projectId models.Id, environmentName string) (*models.QuotaUsage, error) {
+	endpoints, err := q.storage.ListActiveEndpoints(projectId, environmentName)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := q.storage.CountActivePredictionJobs(projectId, environmentName)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &models.QuotaUsage{PredictionJobs: jobs}
+	for _, endpoint := range endpoints {
+		usage.AddEndpoint(endpoint)
+	}
+	return usage, nil
+}
@@ -0,0 +1,352 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+// +build unit
+
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/storage/mocks"
+)
+
+// newUnlimitedQuotaService returns a quota service for projects without any quota, saving the endpoints to endpointStorage
+func newUnlimitedQuotaService(endpointStorage *mocks.VersionEndpointStorage) QuotaService {
+	mockQuotaStorage := &mocks.ProjectQuotaStorage{}
+	mockQuotaStorage.On("Get", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	mockQuotaStorage.On("SaveEndpoint", mock.Anything, mock.Anything, mock.Anything).Return(
+		func(_ models.Id, endpoint *models.VersionEndpoint, check func([]*models.VersionEndpoint) error) error {
+			if err := check(nil); err != nil {
+				return err
+			}
+			return endpointStorage.Save(endpoint)
+		})
+	return NewQuotaService(mockQuotaStorage, nil)
+}
+
+func quotaTestEndpoint(id uuid.UUID, maxReplica int, cpu, memory string) *models.VersionEndpoint {
+	return &models.VersionEndpoint{
+		Id:              id,
+		EnvironmentName: "env1",
+		ResourceRequest: &models.ResourceRequest{
+			MinReplica:    1,
+			MaxReplica:    maxReplica,
+			CpuRequest:    resource.MustParse(cpu),
+			MemoryRequest: resource.MustParse(memory),
+		},
+	}
+}
+
+func TestQuotaService_GetQuota(t *testing.T) {
+	maxEndpoints := 5
+	projectLimits := models.QuotaLimits{Endpoints: &maxEndpoints}
+	defaultCpu := resource.MustParse("10")
+	defaultLimits := models.QuotaLimits{Cpu: &defaultCpu}
+
+	tests := []struct {
+		name          string
+		quota         *models.ProjectQuota
+		quotaErr      error
+		wantLimits    models.QuotaLimits
+		wantIsDefault bool
+		wantErr       bool
+	}{
+		{
+			name:       "project quota",
+			quota:      &models.ProjectQuota{ProjectId: 1, EnvironmentName: "env1", Limits: projectLimits},
+			wantLimits: projectLimits,
+		},
+		{
+			name:          "default quota",
+			quotaErr:      gorm.ErrRecordNotFound,
+			wantLimits:    defaultLimits,
+			wantIsDefault: true,
+		},
+		{
+			name:     "storage error",
+			quotaErr: errors.New("db is down"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockQuotaStorage := &mocks.ProjectQuotaStorage{}
+			mockQuotaStorage.On("Get", models.Id(1), "env1").Return(tt.quota, tt.quotaErr)
+			mockQuotaStorage.On("ListActiveEndpoints", models.Id(1), "env1").Return([]*models.VersionEndpoint{
+				quotaTestEndpoint(uuid.New(), 2, "500m", "1Gi"),
+				quotaTestEndpoint(uuid.New(), 3, "1", "512Mi"),
+			}, nil)
+			mockQuotaStorage.On("CountActivePredictionJobs", models.Id(1), "env1").Return(1, nil)
+
+			quotaSvc := NewQuotaService(mockQuotaStorage, map[string]models.QuotaLimits{"env1": defaultLimits})
+			status, err := quotaSvc.GetQuota(1, "env1")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "env1", status.EnvironmentName)
+			assert.Equal(t, tt.wantLimits, status.Limits)
+			assert.Equal(t, tt.wantIsDefault, status.IsDefault)
+			assert.Equal(t, 2, status.Usage.Endpoints)
+			assert.Equal(t, 5, status.Usage.Replicas)
+			assert.Equal(t, "4", status.Usage.Cpu.String())
+			assert.Equal(t, "3584Mi", status.Usage.Memory.String())
+			assert.Equal(t, 1, status.Usage.PredictionJobs)
+		})
+	}
+}
+
+func TestQuotaService_SetQuota(t *testing.T) {
+	maxEndpoints := 5
+	limits := models.QuotaLimits{Endpoints: &maxEndpoints}
+
+	t.Run("create", func(t *testing.T) {
+		mockQuotaStorage := &mocks.ProjectQuotaStorage{}
+		mockQuotaStorage.On("Get", models.Id(1), "env1").Return(nil, gorm.ErrRecordNotFound)
+		mockQuotaStorage.On("Save", mock.Anything).Return(func(quota *models.ProjectQuota) *models.ProjectQuota { return quota }, nil)
+
+		quota, err := NewQuotaService(mockQuotaStorage, nil).SetQuota(1, "env1", limits)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.ProjectQuota{ProjectId: 1, EnvironmentName: "env1", Limits: limits}, quota)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		mockQuotaStorage := &mocks.ProjectQuotaStorage{}
+		mockQuotaStorage.On("Get", models.Id(1), "env1").Return(&models.ProjectQuota{Id: 3, ProjectId: 1, EnvironmentName: "env1"}, nil)
+		mockQuotaStorage.On("Save", mock.Anything).Return(func(quota *models.ProjectQuota) *models.ProjectQuota { return quota }, nil)
+
+		quota, err := NewQuotaService(mockQuotaStorage, nil).SetQuota(1, "env1", limits)
+		assert.NoError(t, err)
+		assert.Equal(t, &models.ProjectQuota{Id: 3, ProjectId: 1, EnvironmentName: "env1", Limits: limits}, quota)
+	})
+}
+
+func TestQuotaService_CheckEndpoint(t *testing.T) {
+	existingId := uuid.New()
+	existing := []*models.VersionEndpoint{
+		quotaTestEndpoint(existingId, 2, "1", "1Gi"),
+		quotaTestEndpoint(uuid.New(), 2, "1", "1Gi"),
+	}
+	maxEndpoints := 2
+	maxReplicas := 5
+	maxCpu := resource.MustParse("5")
+
+	tests := []struct {
+		name     string
+		limits   models.QuotaLimits
+		endpoint *models.VersionEndpoint
+		wantErr  *models.QuotaExceededError
+	}{
+		{
+			name:     "unlimited",
+			endpoint: quotaTestEndpoint(uuid.New(), 10, "10", "10Gi"),
+		},
+		{
+			name:     "new endpoint exceeding endpoint count",
+			limits:   models.QuotaLimits{Endpoints: &maxEndpoints},
+			endpoint: quotaTestEndpoint(uuid.New(), 1, "1", "1Gi"),
+			wantErr:  &models.QuotaExceededError{Resource: models.QuotaEndpoints, Usage: "2", RequestedUsage: "3", Limit: "2"},
+		},
+		{
+			name:     "redeployed endpoint within endpoint count",
+			limits:   models.QuotaLimits{Endpoints: &maxEndpoints},
+			endpoint: quotaTestEndpoint(existingId, 1, "1", "1Gi"),
+		},
+		{
+			name:     "new endpoint exceeding endpoints per model",
+			limits:   models.QuotaLimits{EndpointsPerModel: &maxEndpoints},
+			endpoint: quotaTestEndpoint(uuid.New(), 1, "1", "1Gi"),
+			wantErr:  &models.QuotaExceededError{Resource: models.QuotaEndpointsPerModel, Usage: "2", RequestedUsage: "3", Limit: "2"},
+		},
+		{
+			name:   "new endpoint of another model within endpoints per model",
+			limits: models.QuotaLimits{EndpointsPerModel: &maxEndpoints},
+			endpoint: func() *models.VersionEndpoint {
+				endpoint := quotaTestEndpoint(uuid.New(), 1, "1", "1Gi")
+				endpoint.VersionModelId = 2
+				return endpoint
+			}(),
+		},
+		{
+			name:     "scaled up endpoint exceeding replicas",
+			limits:   models.QuotaLimits{Replicas: &maxReplicas},
+			endpoint: quotaTestEndpoint(existingId, 4, "1", "1Gi"),
+			wantErr:  &models.QuotaExceededError{Resource: models.QuotaReplicas, Usage: "4", RequestedUsage: "6", Limit: "5"},
+		},
+		{
+			name:     "scaled up endpoint exceeding cpu",
+			limits:   models.QuotaLimits{Cpu: &maxCpu},
+			endpoint: quotaTestEndpoint(existingId, 2, "2", "1Gi"),
+			wantErr:  &models.QuotaExceededError{Resource: models.QuotaCpu, Usage: "4", RequestedUsage: "6", Limit: "5"},
+		},
+		{
+			name: "scaled down endpoint still exceeding lowered quota",
+			limits: models.QuotaLimits{Replicas: func() *int {
+				replicas := 2
+				return &replicas
+			}()},
+			endpoint: quotaTestEndpoint(existingId, 1, "1", "1Gi"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockQuotaStorage := &mocks.ProjectQuotaStorage{}
+			mockQuotaStorage.On("Get", models.Id(1), "env1").Return(&models.ProjectQuota{Limits: tt.limits}, nil)
+			mockQuotaStorage.On("ListActiveEndpoints", models.Id(1), "env1").Return(existing, nil)
+
+			err := NewQuotaService(mockQuotaStorage, nil).CheckEndpoint(1, tt.endpoint)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestQuotaService_SaveEndpoint(t *testing.T) {
+	existing := []*models.VersionEndpoint{
+		quotaTestEndpoint(uuid.New(), 2, "1", "1Gi"),
+		quotaTestEndpoint(uuid.New(), 2, "1", "1Gi"),
+	}
+	maxEndpoints := 3
+	maxCpu := resource.MustParse("5")
+	limits := models.QuotaLimits{Endpoints: &maxEndpoints, Cpu: &maxCpu}
+
+	tests := []struct {
+		name     string
+		endpoint *models.VersionEndpoint
+		wantErr  error
+	}{
+		{
+			name:     "within quota",
+			endpoint: quotaTestEndpoint(uuid.New(), 1, "1", "1Gi"),
+		},
+		{
+			name:     "quota exceeded",
+			endpoint: quotaTestEndpoint(uuid.New(), 2, "1", "1Gi"),
+			wantErr:  &models.QuotaExceededError{Resource: models.QuotaCpu, Usage: "4", RequestedUsage: "6", Limit: "5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockQuotaStorage := &mocks.ProjectQuotaStorage{}
+			mockQuotaStorage.On("Get", models.Id(1), "env1").Return(&models.ProjectQuota{Limits: limits}, nil)
+			mockQuotaStorage.On("SaveEndpoint", models.Id(1), tt.endpoint, mock.Anything).Return(
+				func(_ models.Id, _ *models.VersionEndpoint, check func([]*models.VersionEndpoint) error) error {
+					return check(existing)
+				})
+
+			err := NewQuotaService(mockQuotaStorage, nil).SaveEndpoint(1, tt.endpoint)
+			assert.Equal(t, tt.wantErr, err)
+			mockQuotaStorage.AssertNotCalled(t, "ListActiveEndpoints", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestQuotaService_CheckPredictionJob(t *testing.T) {
+	maxPredictionJobs := 2
+
+	tests := []struct {
+		name      string
+		limits    models.QuotaLimits
+		activeJob int
+		wantErr   error
+	}{
+		{
+			name:      "unlimited",
+			activeJob: 10,
+		},
+		{
+			name:      "within quota",
+			limits:    models.QuotaLimits{PredictionJobs: &maxPredictionJobs},
+			activeJob: 1,
+		},
+		{
+			name:      "quota exceeded",
+			limits:    models.QuotaLimits{PredictionJobs: &maxPredictionJobs},
+			activeJob: 2,
+			wantErr:   &models.QuotaExceededError{Resource: models.QuotaPredictionJobs, Usage: "2", RequestedUsage: "3", Limit: "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockQuotaStorage := &mocks.ProjectQuotaStorage{}
+			mockQuotaStorage.On("Get", models.Id(1), "env1").Return(nil, gorm.ErrRecordNotFound)
+			mockQuotaStorage.On("CountActivePredictionJobs", models.Id(1), "env1").Return(tt.activeJob, nil)
+
+			err := NewQuotaService(mockQuotaStorage, map[string]models.QuotaLimits{"env1": tt.limits}).CheckPredictionJob(1, "env1")
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestQuotaService_SavePredictionJob(t *testing.T) {
+	maxPredictionJobs := 2
+	job := &models.PredictionJob{Name: "job", EnvironmentName: "env1"}
+
+	tests := []struct {
+		name      string
+		limits    models.QuotaLimits
+		activeJob int
+		wantErr   error
+	}{
+		{
+			name:      "unlimited",
+			activeJob: 10,
+		},
+		{
+			name:      "within quota",
+			limits:    models.QuotaLimits{PredictionJobs: &maxPredictionJobs},
+			activeJob: 1,
+		},
+		{
+			name:      "quota exceeded",
+			limits:    models.QuotaLimits{PredictionJobs: &maxPredictionJobs},
+			activeJob: 2,
+			wantErr:   &models.QuotaExceededError{Resource: models.QuotaPredictionJobs, Usage: "2", RequestedUsage: "3", Limit: "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockQuotaStorage := &mocks.ProjectQuotaStorage{}
+			mockQuotaStorage.On("Get", models.Id(1), "env1").Return(&models.ProjectQuota{Limits: tt.limits}, nil)
+			mockQuotaStorage.On("SavePredictionJob", models.Id(1), job, mock.Anything).Return(
+				func(_ models.Id, _ *models.PredictionJob, check func(int) error) error {
+					return check(tt.activeJob)
+				})
+
+			err := NewQuotaService(mockQuotaStorage, nil).SavePredictionJob(1, job)
+			assert.Equal(t, tt.wantErr, err)
+			mockQuotaStorage.AssertNotCalled(t, "CountActivePredictionJobs", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/storage"
+)
+
+type ScalingScheduleService interface {
+	// ListSchedules returns all scaling schedules of a version endpoint
+	ListSchedules(ctx context.Context, versionEndpointId uuid.UUID) ([]*models.ScalingSchedule, error)
+	// FindById returns the scaling schedule with given ID
+	FindById(ctx context.Context, id models.Id) (*models.ScalingSchedule, error)
+	// SaveSchedule validates and saves the scaling schedule of the version endpoint
+	SaveSchedule(ctx context.Context, model *models.Model, endpoint *models.VersionEndpoint, schedule *models.ScalingSchedule) (*models.ScalingSchedule, error)
+	// DeleteSchedule deletes the scaling schedule, the replicas it applied are kept
+	DeleteSchedule(ctx context.Context, schedule *models.ScalingSchedule) error
+	// ApplySchedules scales the version endpoints whose schedules fired since they were last applied, or since their
+	// creation, and not after now. Only the latest time each schedule fired is applied.
+	ApplySchedules(ctx context.Context, now time.Time) error
+}
+
+type scalingScheduleService struct {
+	storage          storage.ScalingScheduleStorage
+	endpointsService EndpointsService
+	modelsService    ModelsService
+	quotaService     QuotaService
+	eventStorage     storage.VersionEndpointEventStorage
+}
+
+func NewScalingScheduleService(storage storage.ScalingScheduleStorage,
+	endpointsService EndpointsService,
+	modelsService ModelsService,
+	quotaService QuotaService,
+	eventStorage storage.VersionEndpointEventStorage) ScalingScheduleService {
+	return &scalingScheduleService{
+		storage:          storage,
+		endpointsService: endpointsService,
+		modelsService:    modelsService,
+		quotaService:     quotaService,
+		eventStorage:     eventStorage,
+	}
+}
+
+func (s *scalingScheduleService) ListSchedules(ctx context.Context, versionEndpointId uuid.UUID) ([]*models.ScalingSchedule, error) {
+	return s.storage.List(versionEndpointId)
+}
+
+func (s *scalingScheduleService) FindById(ctx context.Context, id models.Id) (*models.ScalingSchedule, error) {
+	return s.storage.Get(id)
+}
+
+func (s *scalingScheduleService) SaveSchedule(ctx context.Context, model *models.Model, endpoint *models.VersionEndpoint, schedule *models.ScalingSchedule) (*models.ScalingSchedule, error) {
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+
+	// the replicas of the schedule must fit in the quota of the project once applied
+	scaled := *endpoint
+	scaled.ResourceRequest = schedule.ApplyTo(endpoint.ResourceRequest)
+	if err := s.quotaService.CheckEndpoint(model.ProjectId, &scaled); err != nil {
+		return nil, err
+	}
+
+	schedule.VersionEndpointId = endpoint.Id
+	if err := s.storage.Save(schedule); err != nil {
+		return nil, errors.Wrapf(err, "failed to save scaling schedule")
+	}
+	return schedule, nil
+}
+
+func (s *scalingScheduleService) DeleteSchedule(ctx context.Context, schedule *models.ScalingSchedule) error {
+	return s.storage.Delete(schedule)
+}
+
+// dueSchedule is a scaling schedule along with the time at which it fired
+type dueSchedule struct {
+	schedule *models.ScalingSchedule
+	firedAt  time.Time
+}
+
+func (s *scalingScheduleService) ApplySchedules(ctx context.Context, now time.Time) error {
+	schedules, err := s.storage.ListAll()
+	if err != nil {
+		return errors.Wrapf(err, "unable to list scaling schedules")
+	}
+
+	// only the latest schedule fired for each version endpoint is applied
+	var endpointIds []uuid.UUID
+	var superseded []*dueSchedule
+	dueSchedules := make(map[uuid.UUID]*dueSchedule)
+	for _, schedule := range schedules {
+		// the times the schedule fired while merlin was down are caught up with
+		since := schedule.CreatedAt
+		if schedule.LastAppliedAt != nil {
+			since = *schedule.LastAppliedAt
+		}
+		firedAt, fired, err := schedule.LastFired(since, now)
+		if err != nil {
+			log.Warnf("unable to evaluate scaling schedule %s: %v", schedule.Id, err)
+			continue
+		}
+		if !fired {
+			continue
+		}
+
+		due := &dueSchedule{schedule: schedule, firedAt: firedAt}
+		latest, ok := dueSchedules[schedule.VersionEndpointId]
+		if !ok {
+			endpointIds = append(endpointIds, schedule.VersionEndpointId)
+		} else if firedAt.Before(latest.firedAt) {
+			superseded = append(superseded, due)
+			continue
+		} else {
+			superseded = append(superseded, latest)
+		}
+		dueSchedules[schedule.VersionEndpointId] = due
+	}
+
+	// the superseded schedules are marked as applied so that they don't fire again once the latest one is applied
+	for _, due := range superseded {
+		if _, err := s.storage.Claim(due.schedule, due.firedAt); err != nil {
+			log.Warnf("unable to skip scaling schedule %s: %v", due.schedule.Id, err)
+		}
+	}
+
+	for _, endpointId := range endpointIds {
+		if err := s.apply(ctx, endpointId, dueSchedules[endpointId]); err != nil {
+			log.Warnf("unable to apply scaling schedule %s: %v", dueSchedules[endpointId].schedule.Id, err)
+		}
+	}
+	return nil
+}
+
+// apply scales the version endpoint to the replicas of the schedule and records the outcome as an event of the endpoint.
+// The schedule is claimed first so that only one replica of merlin applies it, and a failed schedule isn't retried
+// until it fires again.
+func (s *scalingScheduleService) apply(ctx context.Context, endpointId uuid.UUID, due *dueSchedule) error {
+	schedule := due.schedule
+
+	claimed, err := s.storage.Claim(schedule, due.firedAt)
+	if err != nil {
+		return errors.Wrapf(err, "unable to claim scaling schedule %s", schedule.Id)
+	}
+	if !claimed {
+		return nil
+	}
+
+	endpoint, err := s.endpointsService.FindById(endpointId)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find version endpoint %s", endpointId)
+	}
+	// the schedules of undeployed endpoints are dormant until the endpoint is deployed again
+	if endpoint.Status != models.EndpointRunning && endpoint.Status != models.EndpointServing {
+		return nil
+	}
+
+	deploying, err := s.endpointsService.IsDeploying(endpoint)
+	if err != nil {
+		return err
+	}
+
+	var scaleErr error
+	if deploying {
+		scaleErr = errors.New("the endpoint is being deployed")
+	} else {
+		model, err := s.modelsService.FindById(ctx, endpoint.VersionModelId)
+		if err != nil {
+			return errors.Wrapf(err, "unable to find model %s", endpoint.VersionModelId)
+		}
+		_, scaleErr = s.endpointsService.ScaleEndpoint(model, endpoint, schedule.MinReplica, schedule.MaxReplica, schedule.Description())
+	}
+
+	if scaleErr != nil {
+		s.recordEvent(endpoint, models.EventTypeWarning, models.EventReasonScaleFailed, fmt.Sprintf("unable to apply %s: %v", schedule.Description(), scaleErr))
+	} else {
+		log.Infof("version endpoint %s scaled by %s", endpoint.Id, schedule.Description())
+		s.recordEvent(endpoint, models.EventTypeNormal, models.EventReasonScaled, fmt.Sprintf("applied %s", schedule.Description()))
+	}
+	return nil
+}
+
+func (s *scalingScheduleService) recordEvent(endpoint *models.VersionEndpoint, eventType models.EventType, reason, message string) {
+	if err := s.eventStorage.Save(models.NewVersionEndpointEvent(endpoint, eventType, reason, message)); err != nil {
+		log.Warnf("unable to record event %s of version endpoint %s: %v", reason, endpoint.Id, err)
+	}
+}
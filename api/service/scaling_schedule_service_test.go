@@ -0,0 +1,249 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+// +build unit
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/gojek/merlin/models"
+	storageMock "github.com/gojek/merlin/storage/mocks"
+)
+
+// scaleCall records the replicas requested to fakeEndpointsService.ScaleEndpoint
+type scaleCall struct {
+	endpointId             uuid.UUID
+	minReplica, maxReplica int
+	reason                 string
+}
+
+type fakeEndpointsService struct {
+	EndpointsService
+	endpoints map[uuid.UUID]*models.VersionEndpoint
+	deploying bool
+	scaleErr  error
+	scaled    []scaleCall
+}
+
+func (s *fakeEndpointsService) FindById(id uuid.UUID) (*models.VersionEndpoint, error) {
+	return s.endpoints[id], nil
+}
+
+func (s *fakeEndpointsService) IsDeploying(endpoint *models.VersionEndpoint) (bool, error) {
+	return s.deploying, nil
+}
+
+func (s *fakeEndpointsService) ScaleEndpoint(model *models.Model, endpoint *models.VersionEndpoint, minReplica, maxReplica int, reason string) (*models.VersionEndpoint, error) {
+	if s.scaleErr != nil {
+		return nil, s.scaleErr
+	}
+	s.scaled = append(s.scaled, scaleCall{endpoint.Id, minReplica, maxReplica, reason})
+	return endpoint, nil
+}
+
+func TestScalingScheduleService_SaveSchedule(t *testing.T) {
+	model := &models.Model{Id: 1, ProjectId: 1}
+	endpoint := &models.VersionEndpoint{
+		Id:              uuid.New(),
+		EnvironmentName: "env",
+		ResourceRequest: &models.ResourceRequest{
+			MinReplica:    1,
+			MaxReplica:    2,
+			CpuRequest:    resource.MustParse("1"),
+			MemoryRequest: resource.MustParse("1Gi"),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		schedule *models.ScalingSchedule
+		maxCpu   string
+		wantErr  bool
+	}{
+		{
+			name:     "saved",
+			schedule: &models.ScalingSchedule{Schedule: "0 7 * * 1-5", Timezone: "Asia/Jakarta", MinReplica: 2, MaxReplica: 4},
+		},
+		{
+			name:     "invalid schedule",
+			schedule: &models.ScalingSchedule{Schedule: "every morning", MinReplica: 2, MaxReplica: 4},
+			wantErr:  true,
+		},
+		{
+			name:     "quota exceeded",
+			schedule: &models.ScalingSchedule{Schedule: "0 7 * * 1-5", MinReplica: 2, MaxReplica: 4},
+			maxCpu:   "3",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotaService := newUnlimitedQuotaService(nil)
+			if tt.maxCpu != "" {
+				maxCpu := resource.MustParse(tt.maxCpu)
+				mockQuotaStorage := &storageMock.ProjectQuotaStorage{}
+				mockQuotaStorage.On("Get", models.Id(1), "env").Return(&models.ProjectQuota{Limits: models.QuotaLimits{Cpu: &maxCpu}}, nil)
+				mockQuotaStorage.On("ListActiveEndpoints", models.Id(1), "env").Return([]*models.VersionEndpoint{endpoint}, nil)
+				quotaService = NewQuotaService(mockQuotaStorage, nil)
+			}
+
+			mockStorage := &storageMock.ScalingScheduleStorage{}
+			mockStorage.On("Save", mock.Anything).Return(nil)
+
+			svc := NewScalingScheduleService(mockStorage, &fakeEndpointsService{}, &fakeModelsService{}, quotaService, nil)
+			schedule, err := svc.SaveSchedule(context.Background(), model, endpoint, tt.schedule)
+			if tt.wantErr {
+				assert.Error(t, err)
+				mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, endpoint.Id, schedule.VersionEndpointId)
+			mockStorage.AssertCalled(t, "Save", tt.schedule)
+		})
+	}
+}
+
+func TestScalingScheduleService_ApplySchedules(t *testing.T) {
+	// a monday, 07:00 in Asia/Jakarta
+	now := time.Date(2020, 10, 5, 0, 0, 0, 0, time.UTC)
+	since := now.Add(-10 * time.Minute)
+	lastMonday := now.Add(-7 * 24 * time.Hour)
+	model := &models.Model{Id: 1, Name: "model"}
+
+	endpointId := uuid.New()
+	created := models.CreatedUpdated{CreatedAt: since}
+	scaleUp := &models.ScalingSchedule{Id: 1, VersionEndpointId: endpointId, Schedule: "0 7 * * 1-5", Timezone: "Asia/Jakarta", MinReplica: 4, MaxReplica: 10, CreatedUpdated: created}
+	earlier := &models.ScalingSchedule{Id: 2, VersionEndpointId: endpointId, Schedule: "55 23 * * *", MinReplica: 2, MaxReplica: 4, CreatedUpdated: created}
+	scaleDown := &models.ScalingSchedule{Id: 3, VersionEndpointId: endpointId, Schedule: "0 22 * * *", Timezone: "Asia/Jakarta", MinReplica: 1, MaxReplica: 2, CreatedUpdated: created}
+
+	tests := []struct {
+		name        string
+		schedules   []*models.ScalingSchedule
+		claimedBy   bool
+		status      models.EndpointStatus
+		deploying   bool
+		scaleErr    error
+		wantScaled  []scaleCall
+		wantEvent   string
+		wantClaimed map[models.Id]time.Time
+	}{
+		{
+			name:        "latest schedule fired is applied",
+			schedules:   []*models.ScalingSchedule{scaleUp, earlier, scaleDown},
+			status:      models.EndpointServing,
+			wantScaled:  []scaleCall{{endpointId, 4, 10, scaleUp.Description()}},
+			wantEvent:   models.EventReasonScaled,
+			wantClaimed: map[models.Id]time.Time{1: now, 2: now.Add(-5 * time.Minute)},
+		},
+		{
+			name:      "schedule already applied",
+			schedules: []*models.ScalingSchedule{{Id: 1, VersionEndpointId: endpointId, Schedule: "0 7 * * 1-5", Timezone: "Asia/Jakarta", MinReplica: 4, MaxReplica: 10, LastAppliedAt: &now}},
+			status:    models.EndpointServing,
+		},
+		{
+			name:        "schedule missed while merlin was down",
+			schedules:   []*models.ScalingSchedule{{Id: 1, VersionEndpointId: endpointId, Schedule: "0 7 * * 1-5", Timezone: "Asia/Jakarta", MinReplica: 4, MaxReplica: 10, LastAppliedAt: &lastMonday}},
+			status:      models.EndpointRunning,
+			wantScaled:  []scaleCall{{endpointId, 4, 10, scaleUp.Description()}},
+			wantEvent:   models.EventReasonScaled,
+			wantClaimed: map[models.Id]time.Time{1: now},
+		},
+		{
+			name:        "schedule claimed by another replica",
+			schedules:   []*models.ScalingSchedule{scaleUp},
+			claimedBy:   true,
+			status:      models.EndpointServing,
+			wantClaimed: map[models.Id]time.Time{1: now},
+		},
+		{
+			name:        "endpoint not deployed",
+			schedules:   []*models.ScalingSchedule{scaleUp},
+			status:      models.EndpointTerminated,
+			wantClaimed: map[models.Id]time.Time{1: now},
+		},
+		{
+			name:        "endpoint being deployed",
+			schedules:   []*models.ScalingSchedule{scaleUp},
+			status:      models.EndpointRunning,
+			deploying:   true,
+			wantEvent:   models.EventReasonScaleFailed,
+			wantClaimed: map[models.Id]time.Time{1: now},
+		},
+		{
+			name:        "scaling failed",
+			schedules:   []*models.ScalingSchedule{scaleUp},
+			status:      models.EndpointRunning,
+			scaleErr:    errors.New("error scaling inference service"),
+			wantEvent:   models.EventReasonScaleFailed,
+			wantClaimed: map[models.Id]time.Time{1: now},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedules := make([]*models.ScalingSchedule, len(tt.schedules))
+			for i, schedule := range tt.schedules {
+				s := *schedule
+				schedules[i] = &s
+			}
+
+			mockStorage := &storageMock.ScalingScheduleStorage{}
+			mockStorage.On("ListAll").Return(schedules, nil)
+			mockStorage.On("Claim", mock.Anything, mock.Anything).Return(!tt.claimedBy, nil)
+			mockEventStorage := &storageMock.VersionEndpointEventStorage{}
+			mockEventStorage.On("Save", mock.Anything).Return(nil)
+			endpointsService := &fakeEndpointsService{
+				endpoints: map[uuid.UUID]*models.VersionEndpoint{endpointId: {Id: endpointId, VersionModelId: 1, Status: tt.status}},
+				deploying: tt.deploying,
+				scaleErr:  tt.scaleErr,
+			}
+
+			svc := NewScalingScheduleService(mockStorage, endpointsService, &fakeModelsService{model: model}, newUnlimitedQuotaService(nil), mockEventStorage)
+			assert.NoError(t, svc.ApplySchedules(context.Background(), now))
+
+			assert.Equal(t, tt.wantScaled, endpointsService.scaled)
+			if tt.wantEvent == "" {
+				mockEventStorage.AssertNotCalled(t, "Save", mock.Anything)
+			} else {
+				event := mockEventStorage.Calls[0].Arguments[0].(*models.VersionEndpointEvent)
+				assert.Equal(t, tt.wantEvent, event.Reason)
+				assert.Equal(t, endpointId, event.VersionEndpointId)
+			}
+
+			claimed := make(map[models.Id]time.Time)
+			for _, call := range mockStorage.Calls {
+				if call.Method == "Claim" {
+					claimed[call.Arguments[0].(*models.ScalingSchedule).Id] = call.Arguments[1].(time.Time)
+				}
+			}
+			assert.Len(t, claimed, len(tt.wantClaimed))
+			for id, firedAt := range tt.wantClaimed {
+				assert.True(t, firedAt.Equal(claimed[id]), "schedule %d claimed at %v", id, claimed[id])
+			}
+			mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+		})
+	}
+}
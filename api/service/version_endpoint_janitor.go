@@ -0,0 +1,224 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/storage"
+)
+
+// EndpointTrafficEvaluator returns the request rate received by a version endpoint over a time window.
+type EndpointTrafficEvaluator interface {
+	EvaluateThroughput(ctx context.Context, model *models.Model, versionEndpoint *models.VersionEndpoint, window time.Duration) (float64, error)
+}
+
+// EndpointJanitor undeploys the version endpoints whose ttl elapsed or which haven't received any request for
+// longer than their idle timeout. A warning event is recorded a grace period before an endpoint is undeployed.
+type EndpointJanitor interface {
+	// Run periodically checks the lifetime of the version endpoints until stopCh is closed
+	Run(stopCh <-chan struct{})
+}
+
+type endpointJanitor struct {
+	storage               storage.VersionEndpointStorage
+	eventStorage          storage.VersionEndpointEventStorage
+	endpointsService      EndpointsService
+	modelsService         ModelsService
+	versionsService       VersionsService
+	modelEndpointsService ModelEndpointsService
+	trafficEvaluator      EndpointTrafficEvaluator
+	leader                LeaderElector
+	clock                 clock.Clock
+	syncPeriod            time.Duration
+	gracePeriod           time.Duration
+}
+
+// NewEndpointJanitor creates a janitor. trafficEvaluator can be nil, in which case the idle timeouts of
+// the version endpoints aren't enforced.
+func NewEndpointJanitor(storage storage.VersionEndpointStorage,
+	eventStorage storage.VersionEndpointEventStorage,
+	endpointsService EndpointsService,
+	modelsService ModelsService,
+	versionsService VersionsService,
+	modelEndpointsService ModelEndpointsService,
+	trafficEvaluator EndpointTrafficEvaluator,
+	leader LeaderElector,
+	clock clock.Clock,
+	janitorConfig config.EndpointJanitorConfig) EndpointJanitor {
+	return &endpointJanitor{
+		storage:               storage,
+		eventStorage:          eventStorage,
+		endpointsService:      endpointsService,
+		modelsService:         modelsService,
+		versionsService:       versionsService,
+		modelEndpointsService: modelEndpointsService,
+		trafficEvaluator:      trafficEvaluator,
+		leader:                leader,
+		clock:                 clock,
+		syncPeriod:            janitorConfig.SyncPeriod,
+		gracePeriod:           janitorConfig.GracePeriod,
+	}
+}
+
+func (j *endpointJanitor) Run(stopCh <-chan struct{}) {
+	wait.Until(j.cleanUp, j.syncPeriod, stopCh)
+}
+
+func (j *endpointJanitor) cleanUp() {
+	// only the leader checks the endpoints, so that they aren't warned about nor undeployed twice
+	if !j.leader.IsLeader() {
+		return
+	}
+
+	endpoints, err := j.storage.ListWithLifetime()
+	if err != nil {
+		log.Errorf("unable to list version endpoints with a lifetime: %v", err)
+		return
+	}
+
+	for _, endpoint := range endpoints {
+		if err := j.check(context.Background(), endpoint); err != nil {
+			log.Warnf("unable to check the lifetime of version endpoint %s: %v", endpoint.Id, err)
+		}
+	}
+}
+
+// check warns that the endpoint is expired, then undeploys it once the grace period elapsed.
+func (j *endpointJanitor) check(ctx context.Context, endpoint *models.VersionEndpoint) error {
+	now := j.clock.Now()
+
+	reason, err := j.expiryReason(ctx, endpoint, now)
+	if err != nil {
+		return err
+	}
+
+	// endpoints serving the traffic of a model endpoint are kept until they're removed from its rule
+	if reason != "" {
+		referenced, err := j.isReferenced(ctx, endpoint)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			reason = ""
+		}
+	}
+
+	if reason == "" {
+		// the endpoint received requests or started serving a model endpoint since it was warned
+		if endpoint.ExpiryWarnedAt != nil {
+			endpoint.ExpiryWarnedAt = nil
+			return j.storage.Save(endpoint)
+		}
+		return nil
+	}
+
+	deploying, err := j.endpointsService.IsDeploying(endpoint)
+	if err != nil || deploying {
+		return err
+	}
+
+	if endpoint.ExpiryWarnedAt == nil {
+		undeployAt := now.Add(j.gracePeriod)
+		j.recordEvent(endpoint, models.EventTypeWarning, models.EventReasonExpiring,
+			fmt.Sprintf("%s, the endpoint will be undeployed at %s", reason, undeployAt.Format(time.RFC3339)))
+
+		endpoint.ExpiryWarnedAt = &now
+		return j.storage.Save(endpoint)
+	}
+	if now.Before(endpoint.ExpiryWarnedAt.Add(j.gracePeriod)) {
+		return nil
+	}
+
+	return j.undeploy(ctx, endpoint, reason)
+}
+
+// expiryReason returns why the endpoint should be undeployed, empty if it's not expired.
+func (j *endpointJanitor) expiryReason(ctx context.Context, endpoint *models.VersionEndpoint, now time.Time) (string, error) {
+	if expiresAt := endpoint.ExpiresAt(); expiresAt != nil && !now.Before(*expiresAt) {
+		return fmt.Sprintf("ttl of %s elapsed since its deployment", endpoint.TTL), nil
+	}
+
+	// the endpoint must have been deployed for its whole idle timeout
+	idleTimeout := endpoint.IdleTimeoutDuration()
+	if idleTimeout == 0 || j.trafficEvaluator == nil || endpoint.DeployedAt == nil || now.Before(endpoint.DeployedAt.Add(idleTimeout)) {
+		return "", nil
+	}
+
+	model, err := j.modelsService.FindById(ctx, endpoint.VersionModelId)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to find model %s", endpoint.VersionModelId)
+	}
+
+	throughput, err := j.trafficEvaluator.EvaluateThroughput(ctx, model, endpoint, idleTimeout)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to evaluate the traffic of version endpoint %s", endpoint.Id)
+	}
+	if throughput > 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("no request received for %s", endpoint.IdleTimeout), nil
+}
+
+// isReferenced returns true if a serving model endpoint routes or mirrors traffic to the endpoint.
+func (j *endpointJanitor) isReferenced(ctx context.Context, endpoint *models.VersionEndpoint) (bool, error) {
+	modelEndpoints, err := j.modelEndpointsService.ListModelEndpoints(ctx, endpoint.VersionModelId)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to list model endpoints of model %s", endpoint.VersionModelId)
+	}
+
+	for _, modelEndpoint := range modelEndpoints {
+		if modelEndpoint.Status == models.EndpointServing && modelEndpoint.Rule != nil && modelEndpoint.Rule.HasVersionEndpoint(endpoint.Id) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (j *endpointJanitor) undeploy(ctx context.Context, endpoint *models.VersionEndpoint, reason string) error {
+	model, err := j.modelsService.FindById(ctx, endpoint.VersionModelId)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find model %s", endpoint.VersionModelId)
+	}
+
+	version, err := j.versionsService.FindById(ctx, model.Id, endpoint.VersionId, config.MonitoringConfig{})
+	if err != nil {
+		return errors.Wrapf(err, "unable to find version %s of model %s", endpoint.VersionId, model.Name)
+	}
+
+	log.Infof("undeploying version endpoint %s: %s", endpoint.Id, reason)
+	endpoint.Message = fmt.Sprintf("undeployed by merlin: %s", reason)
+	if _, err := j.endpointsService.UndeployEndpoint(endpoint.Environment, model, version, endpoint); err != nil {
+		return err
+	}
+
+	j.recordEvent(endpoint, models.EventTypeNormal, models.EventReasonExpired, fmt.Sprintf("undeployed the endpoint: %s", reason))
+	return nil
+}
+
+func (j *endpointJanitor) recordEvent(endpoint *models.VersionEndpoint, eventType models.EventType, reason, message string) {
+	if err := j.eventStorage.Save(models.NewVersionEndpointEvent(endpoint, eventType, reason, message)); err != nil {
+		log.Warnf("unable to record event %s of version endpoint %s: %v", reason, endpoint.Id, err)
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unit
+// +build unit
+
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/gojek/merlin/config"
+	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/service"
+	"github.com/gojek/merlin/service/mocks"
+	storageMock "github.com/gojek/merlin/storage/mocks"
+)
+
+func TestEndpointJanitor_Run(t *testing.T) {
+	now := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	model := &models.Model{Id: 1, Name: "my-model"}
+	version := &models.Version{Id: 1, ModelId: 1}
+	endpointId := uuid.New()
+
+	tests := []struct {
+		name           string
+		leader         bool
+		ttl            string
+		idleTimeout    string
+		deployedAt     time.Time
+		expiryWarnedAt *time.Time
+		throughput     float64
+		deploying      bool
+		modelEndpoint  *models.ModelEndpoint
+		wantChecked    bool
+		wantEvent      string
+		wantWarned     bool
+		wantUndeployed bool
+	}{
+		{
+			name:           "endpoints aren't checked by other replicas than the leader",
+			ttl:            "24h",
+			deployedAt:     now.Add(-25 * time.Hour),
+			expiryWarnedAt: timePtr(now.Add(-time.Hour)),
+			wantWarned:     true,
+		},
+		{
+			name:        "ttl not elapsed",
+			leader:      true,
+			ttl:         "24h",
+			deployedAt:  now.Add(-time.Hour),
+			wantChecked: true,
+		},
+		{
+			name:        "ttl elapsed and warns",
+			leader:      true,
+			ttl:         "24h",
+			deployedAt:  now.Add(-25 * time.Hour),
+			wantChecked: true,
+			wantEvent:   models.EventReasonExpiring,
+			wantWarned:  true,
+		},
+		{
+			name:           "grace period not elapsed",
+			leader:         true,
+			ttl:            "24h",
+			deployedAt:     now.Add(-25 * time.Hour),
+			expiryWarnedAt: timePtr(now.Add(-30 * time.Minute)),
+			wantChecked:    true,
+			wantWarned:     true,
+		},
+		{
+			name:           "grace period elapsed and undeploys",
+			leader:         true,
+			ttl:            "24h",
+			deployedAt:     now.Add(-25 * time.Hour),
+			expiryWarnedAt: timePtr(now.Add(-time.Hour)),
+			wantChecked:    true,
+			wantEvent:      models.EventReasonExpired,
+			wantWarned:     true,
+			wantUndeployed: true,
+		},
+		{
+			name:           "deploying endpoint is skipped",
+			leader:         true,
+			ttl:            "24h",
+			deployedAt:     now.Add(-25 * time.Hour),
+			expiryWarnedAt: timePtr(now.Add(-time.Hour)),
+			deploying:      true,
+			wantChecked:    true,
+			wantWarned:     true,
+		},
+		{
+			name:           "endpoint referenced by a serving model endpoint is kept",
+			leader:         true,
+			ttl:            "24h",
+			deployedAt:     now.Add(-25 * time.Hour),
+			expiryWarnedAt: timePtr(now.Add(-time.Hour)),
+			modelEndpoint: &models.ModelEndpoint{
+				Status: models.EndpointServing,
+				Rule: &models.ModelEndpointRule{
+					Destination: []*models.ModelEndpointRuleDestination{{VersionEndpointID: endpointId, Weight: 100}},
+				},
+			},
+			wantChecked: true,
+		},
+		{
+			name:       "endpoint referenced by a terminated model endpoint expires",
+			leader:     true,
+			ttl:        "24h",
+			deployedAt: now.Add(-25 * time.Hour),
+			modelEndpoint: &models.ModelEndpoint{
+				Status: models.EndpointTerminated,
+				Rule: &models.ModelEndpointRule{
+					Destination: []*models.ModelEndpointRuleDestination{{VersionEndpointID: endpointId, Weight: 100}},
+				},
+			},
+			wantChecked: true,
+			wantEvent:   models.EventReasonExpiring,
+			wantWarned:  true,
+		},
+		{
+			name:        "idle timeout not elapsed since deployment",
+			leader:      true,
+			idleTimeout: "2h",
+			deployedAt:  now.Add(-time.Hour),
+			wantChecked: true,
+		},
+		{
+			name:        "idle endpoint warns",
+			leader:      true,
+			idleTimeout: "2h",
+			deployedAt:  now.Add(-3 * time.Hour),
+			wantChecked: true,
+			wantEvent:   models.EventReasonExpiring,
+			wantWarned:  true,
+		},
+		{
+			name:           "endpoint receiving requests again is no longer warned",
+			leader:         true,
+			idleTimeout:    "2h",
+			deployedAt:     now.Add(-3 * time.Hour),
+			expiryWarnedAt: timePtr(now.Add(-30 * time.Minute)),
+			throughput:     0.1,
+			wantChecked:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &models.VersionEndpoint{
+				Id:              endpointId,
+				VersionId:       1,
+				VersionModelId:  1,
+				EnvironmentName: "env",
+				Environment:     &models.Environment{Name: "env"},
+				Status:          models.EndpointRunning,
+				TTL:             tt.ttl,
+				IdleTimeout:     tt.idleTimeout,
+				DeployedAt:      &tt.deployedAt,
+				ExpiryWarnedAt:  tt.expiryWarnedAt,
+			}
+
+			versionEndpointStorage := &storageMock.VersionEndpointStorage{}
+			versionEndpointStorage.On("ListWithLifetime").Return([]*models.VersionEndpoint{endpoint}, nil)
+			versionEndpointStorage.On("Save", endpoint).Return(nil)
+
+			var events []*models.VersionEndpointEvent
+			eventStorage := &storageMock.VersionEndpointEventStorage{}
+			eventStorage.On("Save", mock.Anything).Run(func(args mock.Arguments) {
+				events = append(events, args.Get(0).(*models.VersionEndpointEvent))
+			}).Return(nil)
+
+			endpointsService := &mocks.EndpointsService{}
+			endpointsService.On("IsDeploying", endpoint).Return(tt.deploying, nil)
+			endpointsService.On("UndeployEndpoint", endpoint.Environment, model, version, endpoint).
+				Run(func(args mock.Arguments) {
+					endpoint.Status = models.EndpointTerminated
+				}).
+				Return(endpoint, nil)
+
+			modelsService := &mocks.ModelsService{}
+			modelsService.On("FindById", mock.Anything, models.Id(1)).Return(model, nil)
+
+			versionsService := &mocks.VersionsService{}
+			versionsService.On("FindById", mock.Anything, models.Id(1), models.Id(1), config.MonitoringConfig{}).Return(version, nil)
+
+			var modelEndpoints []*models.ModelEndpoint
+			if tt.modelEndpoint != nil {
+				modelEndpoints = append(modelEndpoints, tt.modelEndpoint)
+			}
+			modelEndpointsService := &mocks.ModelEndpointsService{}
+			modelEndpointsService.On("ListModelEndpoints", mock.Anything, models.Id(1)).Return(modelEndpoints, nil)
+
+			trafficEvaluator := &mocks.EndpointTrafficEvaluator{}
+			trafficEvaluator.On("EvaluateThroughput", mock.Anything, model, endpoint, 2*time.Hour).Return(tt.throughput, nil)
+
+			// the janitor stops once it checked the endpoints a single time
+			stopCh := make(chan struct{})
+			leader := &mocks.LeaderElector{}
+			leader.On("IsLeader").Run(func(args mock.Arguments) {
+				close(stopCh)
+			}).Return(tt.leader)
+
+			janitor := service.NewEndpointJanitor(versionEndpointStorage, eventStorage, endpointsService, modelsService,
+				versionsService, modelEndpointsService, trafficEvaluator, leader, clock.NewFakeClock(now),
+				config.EndpointJanitorConfig{Enabled: true, SyncPeriod: time.Minute, GracePeriod: time.Hour})
+			janitor.Run(stopCh)
+
+			if tt.wantChecked {
+				versionEndpointStorage.AssertCalled(t, "ListWithLifetime")
+			} else {
+				versionEndpointStorage.AssertNotCalled(t, "ListWithLifetime")
+			}
+			if tt.wantEvent != "" {
+				assert.Len(t, events, 1)
+				assert.Equal(t, tt.wantEvent, events[0].Reason)
+			} else {
+				assert.Empty(t, events)
+			}
+			assert.Equal(t, tt.wantWarned, endpoint.ExpiryWarnedAt != nil)
+			if tt.wantUndeployed {
+				endpointsService.AssertCalled(t, "UndeployEndpoint", endpoint.Environment, model, version, endpoint)
+				assert.Equal(t, models.EndpointTerminated, endpoint.Status)
+			} else {
+				endpointsService.AssertNotCalled(t, "UndeployEndpoint", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
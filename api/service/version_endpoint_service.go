@@ -15,16 +15,23 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/gojek/merlin/cluster"
 	"github.com/gojek/merlin/config"
 	"github.com/gojek/merlin/imagebuilder"
 	"github.com/gojek/merlin/log"
+	"github.com/gojek/merlin/mlp"
 	"github.com/gojek/merlin/models"
+	"github.com/gojek/merlin/modeltype"
+	_ "github.com/gojek/merlin/modeltype/builtin"
 	"github.com/gojek/merlin/storage"
 )
 
@@ -44,10 +51,27 @@ func init() {
 type EndpointsService interface {
 	ListEndpoints(model *models.Model, version *models.Version) ([]*models.VersionEndpoint, error)
 	FindById(uuid2 uuid.UUID) (*models.VersionEndpoint, error)
-	DeployEndpoint(environment *models.Environment, model *models.Model, version *models.Version, endpoint *models.VersionEndpoint) (*models.VersionEndpoint, error)
+	// DeployEndpoint applies the new configuration to the endpoint and enqueues its deployment requested by the user
+	DeployEndpoint(environment *models.Environment, model *models.Model, version *models.Version, endpoint *models.VersionEndpoint, user string) (*models.VersionEndpoint, error)
+	// RenderEndpoint renders the resources that DeployEndpoint would apply, without saving nor deploying the endpoint
+	RenderEndpoint(environment *models.Environment, model *models.Model, version *models.Version, endpoint *models.VersionEndpoint) (*models.DryRunResult, error)
 	UndeployEndpoint(environment *models.Environment, model *models.Model, version *models.Version, endpoint *models.VersionEndpoint) (*models.VersionEndpoint, error)
-	CountEndpoints(environment *models.Environment, model *models.Model) (int, error)
 	ListContainers(model *models.Model, version *models.Version, id uuid.UUID) ([]*models.Container, error)
+	// ListEndpointEvents lists events recorded for the version endpoint, the most recent first
+	ListEndpointEvents(id uuid.UUID) ([]*models.VersionEndpointEvent, error)
+	// IsDeploying returns true if the endpoint has an unfinished deployment task
+	IsDeploying(endpoint *models.VersionEndpoint) (bool, error)
+	// RedeployEndpoint re-applies the last deployed configuration of the endpoint
+	RedeployEndpoint(endpoint *models.VersionEndpoint) error
+	// ListRevisions lists the recorded deployments of the version endpoint along with their changes, the latest revision first
+	ListRevisions(id uuid.UUID) ([]*models.DeploymentRevision, error)
+	// RollbackEndpoint redeploys the version endpoint with the spec recorded by one of its revisions
+	RollbackEndpoint(model *models.Model, version *models.Version, endpoint *models.VersionEndpoint, revision int, user string) (*models.VersionEndpoint, error)
+	// ScaleEndpoint sets the min and max replicas of a running endpoint without redeploying it and records the change
+	// in the deployment history along with its reason
+	ScaleEndpoint(model *models.Model, endpoint *models.VersionEndpoint, minReplica, maxReplica int, reason string) (*models.VersionEndpoint, error)
+
+	DeploymentTaskHandler
 }
 
 const defaultWorkers = 1
@@ -55,23 +79,38 @@ const defaultWorkers = 1
 type endpointService struct {
 	clusterControllers map[string]cluster.Controller
 	imageBuilder       imagebuilder.ImageBuilder
+	transformerBuilder imagebuilder.ImageBuilder
 	storage            storage.VersionEndpointStorage
 	deploymentStorage  storage.DeploymentStorage
+	eventStorage       storage.VersionEndpointEventStorage
+	taskQueue          *DeploymentTaskQueue
+	quotaService       QuotaService
+	mlpAPIClient       mlp.APIClient
 	environment        string
 	monitoringConfig   config.MonitoringConfig
 }
 
 func NewEndpointService(clusterControllers map[string]cluster.Controller,
 	imageBuilder imagebuilder.ImageBuilder,
+	transformerBuilder imagebuilder.ImageBuilder,
 	storage storage.VersionEndpointStorage,
 	deploymentStorage storage.DeploymentStorage,
+	eventStorage storage.VersionEndpointEventStorage,
+	taskQueue *DeploymentTaskQueue,
+	quotaService QuotaService,
+	mlpAPIClient mlp.APIClient,
 	environment string,
 	monitoringConfig config.MonitoringConfig) EndpointsService {
 	return &endpointService{
 		clusterControllers: clusterControllers,
 		imageBuilder:       imageBuilder,
+		transformerBuilder: transformerBuilder,
 		storage:            storage,
 		deploymentStorage:  deploymentStorage,
+		eventStorage:       eventStorage,
+		taskQueue:          taskQueue,
+		quotaService:       quotaService,
+		mlpAPIClient:       mlpAPIClient,
 		environment:        environment,
 		monitoringConfig:   monitoringConfig,
 	}
@@ -90,13 +129,90 @@ func (k *endpointService) FindById(uuid uuid.UUID) (*models.VersionEndpoint, err
 	return k.storage.Get(uuid)
 }
 
-func (k *endpointService) DeployEndpoint(environment *models.Environment, model *models.Model, version *models.Version, newEndpoint *models.VersionEndpoint) (*models.VersionEndpoint, error) {
-	ctl, ok := k.clusterControllers[environment.Name]
-	if !ok {
+func (k *endpointService) DeployEndpoint(environment *models.Environment, model *models.Model, version *models.Version, newEndpoint *models.VersionEndpoint, user string) (*models.VersionEndpoint, error) {
+	endpoint, err := k.prepareEndpoint(environment, model, version, newEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	previousStatus := endpoint.Status
+	endpoint.Status = models.EndpointPending
+	endpoint.MarkDeployed(time.Now())
+
+	err = k.quotaService.SaveEndpoint(model.ProjectId, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := models.NewDeploymentTaskPayload(model, version, previousStatus)
+	payload.User = user
+	err = k.taskQueue.Enqueue(&models.DeploymentTask{
+		Type:              models.DeployVersionEndpointTask,
+		VersionEndpointId: &endpoint.Id,
+		Payload:           payload,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to enqueue deployment of endpoint %s", endpoint.Id)
+	}
+
+	return endpoint, nil
+}
+
+// RenderEndpoint renders the resources that the deployment of the endpoint would apply, using the image that would be built.
+// The endpoint is neither saved nor deployed.
+func (k *endpointService) RenderEndpoint(environment *models.Environment, model *models.Model, version *models.Version, newEndpoint *models.VersionEndpoint) (*models.DryRunResult, error) {
+	endpoint, err := k.prepareEndpoint(environment, model, version, newEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if err := k.quotaService.CheckEndpoint(model.ProjectId, endpoint); err != nil {
+		return nil, err
+	}
+
+	handler, err := modeltype.Get(model.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	modelOpt := handler.ModelOption(version)
+	if handler.RequiresImageBuild() {
+		modelOpt.PyFuncImageName = k.imageBuilder.ImageRef(model.Project, model, version)
+	}
+	if endpoint.Transformer != nil && endpoint.Transformer.Enabled && endpoint.Transformer.TransformerType == models.PyFuncTransformerType {
+		modelOpt.TransformerImageName = k.transformerBuilder.ImageRef(model.Project, model, version)
+	}
+
+	modelService := models.NewService(model, version, modelOpt, endpoint, k.environment)
+	modelService.Secrets, err = k.resolveSecrets(context.Background(), model, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// rendering doesn't call the cluster, so it only fails on the configuration of the endpoint, e.g. when the
+	// options of the model service with its image don't pass the validation
+	objs, err := k.clusterControllers[environment.Name].Render(modelService)
+	if err != nil {
+		return nil, models.NewInvalidEndpointError("invalid endpoint configuration: %v", err)
+	}
+	return models.NewDryRunResult(objs...)
+}
+
+// prepareEndpoint applies the new configuration to the endpoint of the environment, creating it if needed,
+// and checks that it can be deployed.
+func (k *endpointService) prepareEndpoint(environment *models.Environment, model *models.Model, version *models.Version, newEndpoint *models.VersionEndpoint) (*models.VersionEndpoint, error) {
+	if _, ok := k.clusterControllers[environment.Name]; !ok {
 		return nil, fmt.Errorf("unable to find cluster controller for environment %s", environment.Name)
 	}
 
-	endpoint, ok := version.GetEndpointByEnvironmentName(environment.Name)
+	handler, err := modeltype.Get(model.Type)
+	if err != nil {
+		return nil, err
+	}
+	if !handler.SupportsOnline() {
+		return nil, fmt.Errorf("model type %s can't be deployed as a version endpoint", model.Type)
+	}
+
+	endpoint, _ := version.GetEndpointByEnvironmentName(environment.Name)
 	if endpoint == nil {
 		endpoint = models.NewVersionEndpoint(environment, model.Project, model, version, k.monitoringConfig)
 	}
@@ -109,95 +225,508 @@ func (k *endpointService) DeployEndpoint(environment *models.Environment, model
 		endpoint.ResourceRequest = newEndpoint.ResourceRequest
 	}
 
-	// Configure environment variables for Pyfunc model
-	if model.Type == models.ModelTypePyFunc {
-		pyfuncDefaultEnvVars := models.PyfuncDefaultEnvVars(*model, *version, defaultWorkers)
+	// the autoscaling policy is replaced as a whole, so that it can be removed to use the default one of the environment
+	endpoint.AutoscalingPolicy = newEndpoint.AutoscalingPolicy
+
+	if newEndpoint.Transformer != nil {
+		transformer := newEndpoint.Transformer
+		if transformer.TransformerType == models.PyFuncTransformerType {
+			if err := transformer.EnvVars.CheckForProtectedEnvVars(); err != nil {
+				return nil, err
+			}
+			transformer.EnvVars = models.MergeEnvVars(models.PyfuncTransformerDefaultEnvVars(*model, *version, defaultWorkers), transformer.EnvVars)
+		}
+		endpoint.Transformer = transformer
+	}
+
+	if newEndpoint.Explainer != nil {
+		endpoint.Explainer = newEndpoint.Explainer
+	}
+
+	// the node pool is replaced, so that it can be removed to use the default one of the environment
+	endpoint.NodePool = newEndpoint.NodePool
 
+	// the lifetime is replaced, so that it can be removed to keep the endpoint deployed, within the maximums of the environment
+	endpoint.TTL = newEndpoint.TTL
+	endpoint.IdleTimeout = newEndpoint.IdleTimeout
+	environment.EndpointLifetime.ApplyDefaults(endpoint)
+	if err := environment.EndpointLifetime.Validate(endpoint); err != nil {
+		return nil, err
+	}
+
+	// Configure environment variables of the model type, e.g. the pyfunc server settings
+	if defaultEnvVars := handler.DefaultEnvVars(*model, *version); len(defaultEnvVars) > 0 {
 		// This section is for:
 		// 1. backward-compatibility
 		// 2. when user didn't specify any env vars config at the first time
 		if len(endpoint.EnvVars) == 0 {
-			endpoint.EnvVars = pyfuncDefaultEnvVars
+			endpoint.EnvVars = defaultEnvVars
 		}
 
 		if len(newEndpoint.EnvVars) > 0 {
 			if err := newEndpoint.EnvVars.CheckForProtectedEnvVars(); err != nil {
 				return nil, err
 			}
-			endpoint.EnvVars = models.MergeEnvVars(pyfuncDefaultEnvVars, newEndpoint.EnvVars)
+			endpoint.EnvVars = models.MergeEnvVars(defaultEnvVars, newEndpoint.EnvVars)
+		}
+	} else if len(newEndpoint.EnvVars) > 0 {
+		endpoint.EnvVars = newEndpoint.EnvVars
+	}
+
+	// validate the endpoint against the environment's bounds before accepting it, instead of failing the deployment
+	modelService := models.NewService(model, version, handler.ModelOption(version), endpoint, k.environment)
+	if err := k.clusterControllers[environment.Name].Validate(modelService); err != nil {
+		return nil, models.NewInvalidEndpointError("invalid endpoint configuration: %v", err)
+	}
+	return endpoint, nil
+}
+
+func (k *endpointService) UndeployEndpoint(environment *models.Environment, model *models.Model, version *models.Version, endpoint *models.VersionEndpoint) (*models.VersionEndpoint, error) {
+	if _, ok := k.clusterControllers[environment.Name]; !ok {
+		return nil, fmt.Errorf("unable to find cluster controller for environment %s", environment.Name)
+	}
+
+	// the undeployment is queued after the unfinished deployments of the endpoint, so that it can't be overridden by them
+	err := k.taskQueue.Enqueue(&models.DeploymentTask{
+		Type:              models.UndeployVersionEndpointTask,
+		VersionEndpointId: &endpoint.Id,
+		Payload:           models.NewDeploymentTaskPayload(model, version, endpoint.Status),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to enqueue undeployment of endpoint %s", endpoint.Id)
+	}
+
+	return endpoint, nil
+}
+
+func (k *endpointService) ListEndpointEvents(id uuid.UUID) ([]*models.VersionEndpointEvent, error) {
+	return k.eventStorage.ListEvents(id)
+}
+
+func (k *endpointService) IsDeploying(endpoint *models.VersionEndpoint) (bool, error) {
+	task, err := k.taskQueue.FindLatest(endpoint.Id)
+	if err != nil {
+		return false, err
+	}
+	return task != nil && !task.IsFinished(), nil
+}
+
+// RedeployEndpoint enqueues a new deployment using the payload of the last deployment task of the endpoint
+func (k *endpointService) RedeployEndpoint(endpoint *models.VersionEndpoint) error {
+	task, err := k.taskQueue.FindLatest(endpoint.Id)
+	if err != nil {
+		return err
+	}
+	if task == nil || task.Type != models.DeployVersionEndpointTask || task.Payload == nil {
+		return fmt.Errorf("no deployment of version endpoint %s has been recorded", endpoint.Id)
+	}
+	if !task.IsFinished() {
+		// the endpoint is already being deployed
+		return nil
+	}
+
+	// the deployment is triggered by merlin instead of the user of the last deployment
+	payload := *task.Payload
+	payload.PreviousStatus = endpoint.Status
+	payload.User = ""
+	return k.taskQueue.Enqueue(&models.DeploymentTask{
+		Type:              models.DeployVersionEndpointTask,
+		VersionEndpointId: &endpoint.Id,
+		Payload:           &payload,
+	})
+}
+
+func (k *endpointService) ListRevisions(id uuid.UUID) ([]*models.DeploymentRevision, error) {
+	deployments, err := k.deploymentStorage.ListRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]*models.DeploymentRevision, len(deployments))
+	for i, deployment := range deployments {
+		var previous *models.DeploymentSpec
+		if i+1 < len(deployments) {
+			previous = deployments[i+1].Spec
 		}
+
+		changes, err := deployment.Spec.Diff(previous)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to compare revision %d of version endpoint %s", deployment.Revision, id)
+		}
+		revisions[i] = &models.DeploymentRevision{Deployment: deployment, Changes: changes}
+	}
+	return revisions, nil
+}
+
+// RollbackEndpoint applies the spec of the revision to the endpoint and enqueues its deployment.
+// The images recorded by the revision are deployed as is.
+func (k *endpointService) RollbackEndpoint(model *models.Model, version *models.Version, endpoint *models.VersionEndpoint, revision int, user string) (*models.VersionEndpoint, error) {
+	ctl, ok := k.clusterControllers[endpoint.EnvironmentName]
+	if !ok {
+		return nil, fmt.Errorf("unable to find cluster controller for environment %s", endpoint.EnvironmentName)
+	}
+
+	deployment, err := k.deploymentStorage.GetRevision(endpoint.Id, revision)
+	if err != nil {
+		return nil, err
 	}
 
 	previousStatus := endpoint.Status
+	deployment.Spec.ApplyTo(endpoint)
+
+	// the bounds of the environment may have changed since the revision was deployed
+	if endpoint.Environment != nil {
+		if err := endpoint.Environment.EndpointLifetime.Validate(endpoint); err != nil {
+			return nil, models.NewInvalidEndpointError("invalid endpoint configuration: %v", err)
+		}
+	}
+	modelService := models.NewService(model, version, deployment.Spec.Options, endpoint, k.environment)
+	if err := ctl.Validate(modelService); err != nil {
+		return nil, models.NewInvalidEndpointError("invalid endpoint configuration: %v", err)
+	}
+
 	endpoint.Status = models.EndpointPending
+	endpoint.MarkDeployed(time.Now())
+
+	err = k.quotaService.SaveEndpoint(model.ProjectId, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := models.NewDeploymentTaskPayload(model, version, previousStatus)
+	payload.Options = deployment.Spec.Options
+	payload.User = user
+	err = k.taskQueue.Enqueue(&models.DeploymentTask{
+		Type:              models.DeployVersionEndpointTask,
+		VersionEndpointId: &endpoint.Id,
+		Payload:           payload,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to enqueue rollback of endpoint %s to revision %d", endpoint.Id, revision)
+	}
+
+	return endpoint, nil
+}
+
+// ScaleEndpoint patches the replicas of the inference service of the endpoint in place, the images and the rest of
+// its configuration are kept as deployed.
+func (k *endpointService) ScaleEndpoint(model *models.Model, endpoint *models.VersionEndpoint, minReplica, maxReplica int, reason string) (*models.VersionEndpoint, error) {
+	ctl, ok := k.clusterControllers[endpoint.EnvironmentName]
+	if !ok {
+		return nil, fmt.Errorf("unable to find cluster controller for environment %s", endpoint.EnvironmentName)
+	}
+	if endpoint.Status != models.EndpointRunning && endpoint.Status != models.EndpointServing {
+		return nil, fmt.Errorf("version endpoint %s is not running, current status: %s", endpoint.Id, endpoint.Status)
+	}
+
+	resourceRequest := &models.ResourceRequest{}
+	if endpoint.ResourceRequest != nil {
+		*resourceRequest = *endpoint.ResourceRequest
+	}
+	resourceRequest.MinReplica = minReplica
+	resourceRequest.MaxReplica = maxReplica
 
-	err := k.storage.Save(endpoint)
+	// the scaled endpoint is saved before scaling its inference service so that its replicas are counted in the quota
+	scaled := *endpoint
+	scaled.ResourceRequest = resourceRequest
+	if err := k.quotaService.SaveEndpoint(model.ProjectId, &scaled); err != nil {
+		return nil, err
+	}
+
+	err := ctl.Scale(&models.Service{
+		Name:              scaled.InferenceServiceName,
+		Namespace:         scaled.Namespace,
+		ResourceRequest:   scaled.ResourceRequest,
+		AutoscalingPolicy: scaled.AutoscalingPolicy,
+	})
 	if err != nil {
+		if saveErr := k.storage.Save(endpoint); saveErr != nil {
+			log.Errorf("unable to restore the replicas of version endpoint %s: %v", endpoint.Id, saveErr)
+		}
 		return nil, err
 	}
 
-	go func() {
-		log.Infof("creating deployment for model %s version %s with endpoint id: %s", model.Name, version.Id, endpoint.Id)
-
-		// copy endpoint to avoid race condition
-		ep := *endpoint
-		ep.Status = models.EndpointFailed
-		defer func() {
-			deploymentCounter.WithLabelValues(model.Project.Name, model.Name, string(ep.Status)).Inc()
-
-			// record the deployment result
-			if _, err := k.deploymentStorage.Save(&models.Deployment{
-				ProjectId:         model.ProjectId,
-				VersionModelId:    model.Id,
-				VersionId:         version.Id,
-				VersionEndpointId: ep.Id,
-				Status:            ep.Status,
-				Error:             ep.Message,
-			}); err != nil {
-				log.Warnf("unable to insert deployment history", err)
+	// the scaled endpoint keeps the images of its last deployment
+	var options *models.ModelOption
+	lastSucceeded, err := k.deploymentStorage.GetLastSucceededRevision(scaled.Id)
+	if err == nil && lastSucceeded.Spec != nil {
+		options = lastSucceeded.Spec.Options
+	} else if err != nil && !gorm.IsRecordNotFoundError(err) {
+		log.Warnf("unable to find the last succeeded revision of version endpoint %s: %v", scaled.Id, err)
+	}
+
+	deployment := &models.Deployment{
+		ProjectId:         model.ProjectId,
+		VersionModelId:    scaled.VersionModelId,
+		VersionId:         scaled.VersionId,
+		VersionEndpointId: scaled.Id,
+		EnvironmentName:   scaled.EnvironmentName,
+		Status:            scaled.Status,
+		Reason:            reason,
+		Spec:              models.NewDeploymentSpec(&scaled, options),
+	}
+	if _, err := k.deploymentStorage.Save(deployment); err != nil {
+		log.Warnf("unable to insert deployment history: %v", err)
+	}
+
+	return &scaled, nil
+}
+
+// ExecuteDeploymentTask deploys or undeploys the version endpoint of the task
+func (k *endpointService) ExecuteDeploymentTask(ctx context.Context, task *models.DeploymentTask) error {
+	if task.VersionEndpointId == nil || task.Payload == nil {
+		return fmt.Errorf("deployment task %s has no version endpoint", task.Id)
+	}
+
+	endpoint, err := k.storage.Get(*task.VersionEndpointId)
+	if err != nil {
+		return errors.Wrapf(err, "unable to find version endpoint %s", task.VersionEndpointId)
+	}
+
+	switch task.Type {
+	case models.DeployVersionEndpointTask:
+		return k.deploy(ctx, endpoint, task.Payload)
+	case models.UndeployVersionEndpointTask:
+		return k.undeploy(ctx, endpoint, task.Payload.GetModel(), task.Payload.Version)
+	default:
+		return fmt.Errorf("unsupported deployment task type: %s", task.Type)
+	}
+}
+
+// ReconcileOrphans resumes the deployment of pending version endpoints without any unfinished deployment task.
+// The endpoints whose deployment can't be resumed, because their last task was abandoned or there is no recorded
+// deployment to resume, are marked as failed. The interrupted deployments recorded in the deployment history are
+// marked as failed too, a resumed deployment is recorded again.
+func (k *endpointService) ReconcileOrphans(ctx context.Context, updatedBefore time.Time) error {
+	failed, err := k.deploymentStorage.FailOrphanedPending(updatedBefore, "deployment was interrupted")
+	if err != nil {
+		return errors.Wrap(err, "unable to update interrupted deployments")
+	}
+	if failed > 0 {
+		log.Warnf("marked %d interrupted deployments as failed", failed)
+	}
+
+	endpoints, err := k.storage.ListOrphanedPending(updatedBefore)
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range endpoints {
+		task, err := k.taskQueue.FindLatest(endpoint.Id)
+		if err != nil {
+			return errors.Wrapf(err, "unable to find deployment task of version endpoint %s", endpoint.Id)
+		}
+
+		if task != nil && task.Type == models.DeployVersionEndpointTask && task.Payload != nil && !task.IsExhausted() {
+			log.Warnf("resuming deployment of orphaned pending version endpoint %s", endpoint.Id)
+
+			err := k.taskQueue.Enqueue(&models.DeploymentTask{
+				Type:              models.DeployVersionEndpointTask,
+				VersionEndpointId: &endpoint.Id,
+				Payload:           task.Payload,
+			})
+			if err != nil {
+				return errors.Wrapf(err, "failed to enqueue deployment of endpoint %s", endpoint.Id)
 			}
+			continue
+		}
+
+		log.Warnf("marking orphaned pending version endpoint %s as failed", endpoint.Id)
+
+		endpoint.Status = models.EndpointFailed
+		endpoint.Message = "deployment was interrupted, please redeploy the endpoint"
+		if err := k.storage.Save(endpoint); err != nil {
+			return errors.Wrapf(err, "unable to update version endpoint %s", endpoint.Id)
+		}
+	}
+	return nil
+}
+
+// deploy deploys the version endpoint, building the images unless the options of a recorded revision are given.
+// If the deployment of a running endpoint fails, the last succeeded revision is restored.
+func (k *endpointService) deploy(ctx context.Context, ep *models.VersionEndpoint, payload *models.DeploymentTaskPayload) error {
+	model, version, previousStatus := payload.GetModel(), payload.Version, payload.PreviousStatus
+
+	err := k.deployRevision(ctx, ep, payload, payload.Options)
+	if err == nil || ctx.Err() != nil || (previousStatus != models.EndpointRunning && previousStatus != models.EndpointServing) {
+		return err
+	}
+
+	lastSucceeded, findErr := k.deploymentStorage.GetLastSucceededRevision(ep.Id)
+	if findErr != nil {
+		if !gorm.IsRecordNotFoundError(findErr) {
+			log.Warnf("unable to find the last succeeded revision of version endpoint %s: %v", ep.Id, findErr)
+		}
+		return err
+	}
+
+	log.Warnf("deployment of version endpoint %s failed, restoring revision %d", ep.Id, lastSucceeded.Revision)
+	failure := ep.Message
+	lastSucceeded.Spec.ApplyTo(ep)
+	if restoreErr := k.deployRevision(ctx, ep, payload, lastSucceeded.Spec.Options); restoreErr != nil {
+		log.Errorf("unable to restore revision %d of version endpoint %s: %v", lastSucceeded.Revision, ep.Id, restoreErr)
+		return err
+	}
+
+	ep.Message = fmt.Sprintf("deployment failed and revision %d has been restored: %s", lastSucceeded.Revision, failure)
+	if saveErr := k.storage.Save(ep); saveErr != nil {
+		log.Errorf("unable to update endpoint message for model: %s, version: %s, reason: %v", model.Name, version.Id, saveErr)
+	}
+	return err
+}
+
+// deployRevision stops between its steps once ctx is cancelled, i.e. once the deployment task has been claimed by
+// another replica, and leaves the endpoint to that replica.
+func (k *endpointService) deployRevision(ctx context.Context, ep *models.VersionEndpoint, payload *models.DeploymentTaskPayload, options *models.ModelOption) error {
+	model, version, previousStatus := payload.GetModel(), payload.Version, payload.PreviousStatus
+	log.Infof("creating deployment for model %s version %s with endpoint id: %s", model.Name, version.Id, ep.Id)
+
+	// record the start of the deployment, its duration is measured until the result is recorded
+	modelOpt := options
+	deployment := &models.Deployment{
+		ProjectId:         model.ProjectId,
+		VersionModelId:    model.Id,
+		VersionId:         version.Id,
+		VersionEndpointId: ep.Id,
+		EnvironmentName:   ep.EnvironmentName,
+		DeployedBy:        payload.User,
+		Status:            models.EndpointPending,
+		Spec:              models.NewDeploymentSpec(ep, modelOpt),
+	}
+	if _, err := k.deploymentStorage.Save(deployment); err != nil {
+		log.Warnf("unable to insert deployment history: %v", err)
+	}
 
-			if err := k.storage.Save(&ep); err != nil {
-				log.Errorf("unable to update endpoint status for model: %s, version: %s, reason: %v", model.Name, version.Id, err)
+	ep.Status = models.EndpointFailed
+	defer func() {
+		if ctx.Err() != nil {
+			deployment.Status = models.EndpointFailed
+			deployment.Error = errLeaseLost.Error()
+			if _, err := k.deploymentStorage.Save(deployment); err != nil {
+				log.Warnf("unable to update deployment history: %v", err)
 			}
-		}()
+			return
+		}
+
+		deploymentCounter.WithLabelValues(model.Project.Name, model.Name, string(ep.Status)).Inc()
+
+		// record the deployment result
+		deployment.Status = ep.Status
+		deployment.Error = ep.Message
+		deployment.Spec = models.NewDeploymentSpec(ep, modelOpt)
+		if _, err := k.deploymentStorage.Save(deployment); err != nil {
+			log.Warnf("unable to update deployment history: %v", err)
+		}
+
+		if err := k.storage.Save(ep); err != nil {
+			log.Errorf("unable to update endpoint status for model: %s, version: %s, reason: %v", model.Name, version.Id, err)
+		}
+	}()
+
+	ctl, ok := k.clusterControllers[ep.EnvironmentName]
+	if !ok {
+		ep.Message = fmt.Sprintf("unable to find cluster controller for environment %s", ep.EnvironmentName)
+		return errors.New(ep.Message)
+	}
+
+	handler, err := modeltype.Get(model.Type)
+	if err != nil {
+		ep.Message = err.Error()
+		return err
+	}
 
-		modelOpt := &models.ModelOption{}
-		if model.Type == models.ModelTypePyFunc {
+	if modelOpt == nil {
+		modelOpt = handler.ModelOption(version)
+		if handler.RequiresImageBuild() {
+			if err := checkLease(ctx); err != nil {
+				return err
+			}
 			imageRef, err := k.imageBuilder.BuildImage(model.Project, model, version)
 			modelOpt.PyFuncImageName = imageRef
 			if err != nil {
 				ep.Message = err.Error()
-				return
+				return err
 			}
-		} else if model.Type == models.ModelTypePyTorch {
-			modelOpt = models.NewPyTorchModelOption(version)
 		}
+	}
 
-		modelService := models.NewService(model, version, modelOpt, endpoint.ResourceRequest, endpoint.EnvVars, k.environment)
-		svc, err := ctl.Deploy(modelService)
+	if ep.Transformer != nil && ep.Transformer.Enabled && ep.Transformer.TransformerType == models.PyFuncTransformerType && modelOpt.TransformerImageName == "" {
+		if err := checkLease(ctx); err != nil {
+			return err
+		}
+		imageRef, err := k.transformerBuilder.BuildImage(model.Project, model, version)
 		if err != nil {
-			log.Errorf("unable to deploy version endpoint for model: %s, version: %s, reason: %v", model.Name, version.Id, err)
-			ep.Message = err.Error()
-			return
+			ep.Message = fmt.Sprintf("unable to build transformer image: %v", err)
+			return err
 		}
+		modelOpt.TransformerImageName = imageRef
+	}
+
+	modelService := models.NewService(model, version, modelOpt, ep, k.environment)
+	modelService.Secrets, err = k.resolveSecrets(ctx, model, ep)
+	if err != nil {
+		ep.Message = err.Error()
+		return err
+	}
 
-		ep.Url = svc.Url
-		if previousStatus == models.EndpointServing {
-			ep.Status = models.EndpointServing
-		} else {
-			ep.Status = models.EndpointRunning
+	if err := checkLease(ctx); err != nil {
+		return err
+	}
+	svc, err := ctl.Deploy(modelService)
+	if err != nil {
+		log.Errorf("unable to deploy version endpoint for model: %s, version: %s, reason: %v", model.Name, version.Id, err)
+		ep.Message = err.Error()
+		return err
+	}
+
+	ep.Url = svc.Url
+	ep.ExplainerUrl = svc.ExplainerUrl
+	if previousStatus == models.EndpointServing {
+		ep.Status = models.EndpointServing
+	} else {
+		ep.Status = models.EndpointRunning
+	}
+	ep.ServiceName = svc.ServiceName
+	ep.Message = ""
+	return nil
+}
+
+// resolveSecrets fetches the data of the project secrets referenced by the environment variables of the endpoint.
+func (k *endpointService) resolveSecrets(ctx context.Context, model *models.Model, endpoint *models.VersionEndpoint) (map[string]string, error) {
+	names := endpoint.EnvVars.SecretNames()
+	if transformer := endpoint.Transformer; transformer != nil && transformer.Enabled {
+		names = append(names, transformer.EnvVars.SecretNames()...)
+	}
+	if explainer := endpoint.Explainer; explainer != nil && explainer.Enabled {
+		names = append(names, explainer.EnvVars.SecretNames()...)
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	secrets := make(map[string]string, len(names))
+	for _, name := range names {
+		if _, ok := secrets[name]; ok {
+			continue
 		}
-		ep.ServiceName = svc.ServiceName
-	}()
 
-	return endpoint, nil
+		secret, err := k.mlpAPIClient.GetPlainSecretByNameAndProjectID(ctx, name, int32(model.ProjectId))
+		if err != nil {
+			return nil, fmt.Errorf("secret %s is not found within %s project: %s", name, model.Project.Name, err)
+		}
+		secrets[name] = secret.Data
+	}
+	return secrets, nil
 }
 
-func (k *endpointService) UndeployEndpoint(environment *models.Environment, model *models.Model, version *models.Version, endpoint *models.VersionEndpoint) (*models.VersionEndpoint, error) {
-	ctl, ok := k.clusterControllers[environment.Name]
+func (k *endpointService) undeploy(ctx context.Context, endpoint *models.VersionEndpoint, model *models.Model, version *models.Version) error {
+	ctl, ok := k.clusterControllers[endpoint.EnvironmentName]
 	if !ok {
-		return nil, fmt.Errorf("unable to find cluster controller for environment %s", environment.Name)
+		return fmt.Errorf("unable to find cluster controller for environment %s", endpoint.EnvironmentName)
+	}
+
+	if err := checkLease(ctx); err != nil {
+		return err
 	}
 
 	modelService := &models.Service{
@@ -207,21 +736,11 @@ func (k *endpointService) UndeployEndpoint(environment *models.Environment, mode
 
 	_, err := ctl.Delete(modelService)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	endpoint.Status = models.EndpointTerminated
-	err = k.storage.Save(endpoint)
-	if err != nil {
-		return nil, err
-	}
-
-	return endpoint, nil
-}
-
-// CountEndpoints count number of running/pending version endpoint of a model within an environment
-func (k *endpointService) CountEndpoints(environment *models.Environment, model *models.Model) (int, error) {
-	return k.storage.CountEndpoints(environment, model)
+	return k.storage.Save(endpoint)
 }
 
 // ListContainers list all containers belong to the given version endpoint
@@ -237,7 +756,7 @@ func (k *endpointService) ListContainers(model *models.Model, version *models.Ve
 	}
 
 	containers := make([]*models.Container, 0)
-	if model.Type == models.ModelTypePyFunc {
+	if handler, err := modeltype.Get(model.Type); err == nil && handler.RequiresImageBuild() {
 		imgBuilderContainers, err := k.imageBuilder.GetContainers(model.Project, model, version)
 		if err != nil {
 			return nil, err
@@ -246,6 +765,15 @@ func (k *endpointService) ListContainers(model *models.Model, version *models.Ve
 		containers = append(containers, imgBuilderContainers...)
 	}
 
+	if ve.Transformer != nil && ve.Transformer.Enabled && ve.Transformer.TransformerType == models.PyFuncTransformerType {
+		transformerBuilderContainers, err := k.transformerBuilder.GetContainers(model.Project, model, version)
+		if err != nil {
+			return nil, err
+		}
+
+		containers = append(containers, transformerBuilderContainers...)
+	}
+
 	modelContainers, err := ctl.GetContainers(model.Project.Name, models.OnlineInferencePodLabelSelector(model.Name, version.Id.String()))
 	if err != nil {
 		return nil, err
@@ -17,6 +17,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
@@ -25,10 +26,16 @@ import (
 	clusterMock "github.com/gojek/merlin/cluster/mocks"
 	imageBuilderMock "github.com/gojek/merlin/imagebuilder/mocks"
 	"github.com/gojek/merlin/mlp"
+	mlpMock "github.com/gojek/merlin/mlp/mocks"
 
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
 
 	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
@@ -61,7 +68,7 @@ func TestDeployEndpoint(t *testing.T) {
 		},
 	}
 	project := mlp.Project{Name: "project"}
-	model := &models.Model{Name: "model", Project: project}
+	model := &models.Model{Name: "model", Project: project, Type: models.ModelTypeTensorflow}
 	version := &models.Version{Id: 1}
 
 	iSvcName := fmt.Sprintf("%s-%d", model.Name, version.Id)
@@ -171,6 +178,7 @@ func TestDeployEndpoint(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			envController := &clusterMock.Controller{}
+			envController.On("Validate", mock.Anything).Return(nil)
 			if tt.wantDeployError {
 				envController.On("Deploy", mock.Anything).
 					Return(nil, errors.New("error deploying"))
@@ -205,12 +213,13 @@ func TestDeployEndpoint(t *testing.T) {
 				},
 			}
 
-			controllers := map[string]cluster.Controller{env.Name: envController}
-			endpointSvc := NewEndpointService(controllers, imgBuilder, mockStorage, mockDeploymentStorage, mockCfg.Environment, mockCfg.FeatureToggleConfig.MonitoringConfig)
-			e, err := endpointSvc.DeployEndpoint(tt.args.environment, tt.args.model, tt.args.version, tt.args.endpoint)
+			mockTaskStorage := &mocks.DeploymentTaskStorage{}
+			mockTaskStorage.On("Save", mock.Anything).Return(nil)
+			taskQueue := NewDeploymentTaskQueue(mockTaskStorage, "test", config.DeploymentQueueConfig{MaxAttempts: 3}, clock.NewFakeClock(time.Now()))
 
-			// delay to make second save happen before checking
-			time.Sleep(20 * time.Millisecond)
+			controllers := map[string]cluster.Controller{env.Name: envController}
+			endpointSvc := NewEndpointService(controllers, imgBuilder, nil, mockStorage, mockDeploymentStorage, nil, taskQueue, newUnlimitedQuotaService(mockStorage), nil, mockCfg.Environment, mockCfg.FeatureToggleConfig.MonitoringConfig)
+			e, err := endpointSvc.DeployEndpoint(tt.args.environment, tt.args.model, tt.args.version, tt.args.endpoint, "user@example.com")
 
 			assert.NoError(t, err)
 			assert.Equal(t, "", e.Url)
@@ -224,8 +233,23 @@ func TestDeployEndpoint(t *testing.T) {
 				assert.Equal(t, e.ResourceRequest, tt.args.environment.DefaultResourceRequest)
 			}
 
+			mockTaskStorage.AssertNumberOfCalls(t, "Save", 1)
+			task := mockTaskStorage.Calls[0].Arguments[0].(*models.DeploymentTask)
+			assert.Equal(t, models.DeployVersionEndpointTask, task.Type)
+			assert.Equal(t, models.TaskPending, task.Status)
+			assert.Equal(t, e.Id, *task.VersionEndpointId)
+
+			// execute the task as it would be by the deployment task worker
+			mockStorage.On("Get", e.Id).Return(e, nil)
+			err = endpointSvc.ExecuteDeploymentTask(context.Background(), task)
+			if tt.wantDeployError || tt.wantBuildImageError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
 			mockStorage.AssertNumberOfCalls(t, "Save", 2)
-			savedEndpoint := mockStorage.Calls[1].Arguments[0].(*models.VersionEndpoint)
+			savedEndpoint := mockStorage.Calls[2].Arguments[0].(*models.VersionEndpoint)
 			assert.Equal(t, tt.args.model.Id, savedEndpoint.VersionModelId)
 			assert.Equal(t, tt.args.version.Id, savedEndpoint.VersionId)
 			assert.Equal(t, tt.args.model.Project.Name, savedEndpoint.Namespace)
@@ -354,7 +378,7 @@ func TestListContainers(t *testing.T) {
 		mockStorage.On("Get", mock.Anything).Return(tt.mock.versionEndpoint, nil)
 		mockDeploymentStorage.On("Save", mock.Anything).Return(nil, nil)
 
-		endpointSvc := NewEndpointService(controllers, imgBuilder, mockStorage, mockDeploymentStorage, cfg.Environment, cfg.FeatureToggleConfig.MonitoringConfig)
+		endpointSvc := NewEndpointService(controllers, imgBuilder, nil, mockStorage, mockDeploymentStorage, nil, nil, nil, nil, cfg.Environment, cfg.FeatureToggleConfig.MonitoringConfig)
 
 		containers, err := endpointSvc.ListContainers(tt.args.model, tt.args.version, tt.args.id)
 		if !tt.wantError {
@@ -371,3 +395,771 @@ func TestListContainers(t *testing.T) {
 		assert.Equal(t, expContainer, len(containers))
 	}
 }
+
+func TestRedeployEndpoint(t *testing.T) {
+	endpoint := &models.VersionEndpoint{Id: uuid.New(), Status: models.EndpointServing}
+	payload := &models.DeploymentTaskPayload{
+		Model:   &models.Model{Id: 1, Name: "model"},
+		Version: &models.Version{Id: 1, ModelId: 1},
+	}
+
+	tests := []struct {
+		name          string
+		latestTask    *models.DeploymentTask
+		wantDeploying bool
+		wantEnqueue   bool
+		wantError     bool
+	}{
+		{
+			name:       "no deployment recorded",
+			latestTask: nil,
+			wantError:  true,
+		},
+		{
+			name:       "latest task is undeployment",
+			latestTask: &models.DeploymentTask{Type: models.UndeployVersionEndpointTask, Status: models.TaskSucceeded, Payload: payload},
+			wantError:  true,
+		},
+		{
+			name:          "deployment in progress",
+			latestTask:    &models.DeploymentTask{Type: models.DeployVersionEndpointTask, Status: models.TaskRunning, Payload: payload},
+			wantDeploying: true,
+		},
+		{
+			name:        "redeploy last deployment",
+			latestTask:  &models.DeploymentTask{Type: models.DeployVersionEndpointTask, Status: models.TaskSucceeded, Payload: payload},
+			wantEnqueue: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTaskStorage := &mocks.DeploymentTaskStorage{}
+			mockTaskStorage.On("FindLatest", endpoint.Id).Return(tt.latestTask, nil)
+			mockTaskStorage.On("Save", mock.Anything).Return(nil)
+			taskQueue := NewDeploymentTaskQueue(mockTaskStorage, "test", config.DeploymentQueueConfig{MaxAttempts: 3}, clock.NewFakeClock(time.Now()))
+			endpointSvc := NewEndpointService(nil, nil, nil, nil, nil, nil, taskQueue, nil, nil, "dev", config.MonitoringConfig{})
+
+			deploying, err := endpointSvc.IsDeploying(endpoint)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDeploying, deploying)
+
+			err = endpointSvc.RedeployEndpoint(endpoint)
+			if tt.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tt.wantEnqueue {
+				mockTaskStorage.AssertNumberOfCalls(t, "Save", 1)
+				task := mockTaskStorage.Calls[2].Arguments[0].(*models.DeploymentTask)
+				assert.Equal(t, models.DeployVersionEndpointTask, task.Type)
+				assert.Equal(t, models.TaskPending, task.Status)
+				assert.Equal(t, endpoint.Id, *task.VersionEndpointId)
+				assert.Equal(t, payload.Version, task.Payload.Version)
+				assert.Equal(t, models.EndpointServing, task.Payload.PreviousStatus)
+			} else {
+				mockTaskStorage.AssertNotCalled(t, "Save", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestDeployEndpoint_Secrets(t *testing.T) {
+	env := &models.Environment{
+		Name: "env1",
+		DefaultResourceRequest: &models.ResourceRequest{
+			MinReplica:    1,
+			MaxReplica:    1,
+			CpuRequest:    resource.MustParse("1"),
+			MemoryRequest: resource.MustParse("1Gi"),
+		},
+	}
+	project := mlp.Project{Id: 1, Name: "project"}
+	model := &models.Model{Name: "model", ProjectId: 1, Project: project, Type: models.ModelTypeCustom}
+	version := &models.Version{
+		Id:              1,
+		CustomPredictor: &models.CustomPredictor{Image: "gojek/my-model:1"},
+	}
+
+	tests := []struct {
+		name        string
+		secretErr   error
+		wantSecrets map[string]string
+		wantStatus  models.EndpointStatus
+	}{
+		{
+			name:        "secret resolved",
+			wantSecrets: map[string]string{"api-key": "my-api-key", "explainer-key": "my-explainer-key"},
+			wantStatus:  models.EndpointRunning,
+		},
+		{
+			name:       "secret not found",
+			secretErr:  errors.New("not found"),
+			wantStatus: models.EndpointFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envController := &clusterMock.Controller{}
+			envController.On("Validate", mock.Anything).Return(nil)
+			envController.On("Deploy", mock.Anything).Return(&models.Service{Name: "model-1", Namespace: project.Name}, nil)
+
+			mlpAPIClient := &mlpMock.APIClient{}
+			mlpAPIClient.On("GetPlainSecretByNameAndProjectID", context.Background(), "api-key", int32(1)).
+				Return(mlp.Secret{Name: "api-key", Data: "my-api-key"}, tt.secretErr)
+			mlpAPIClient.On("GetPlainSecretByNameAndProjectID", context.Background(), "explainer-key", int32(1)).
+				Return(mlp.Secret{Name: "explainer-key", Data: "my-explainer-key"}, nil)
+
+			mockStorage := &mocks.VersionEndpointStorage{}
+			mockStorage.On("Save", mock.Anything).Return(nil)
+			mockDeploymentStorage := &mocks.DeploymentStorage{}
+			mockDeploymentStorage.On("Save", mock.Anything).Return(nil, nil)
+			mockTaskStorage := &mocks.DeploymentTaskStorage{}
+			mockTaskStorage.On("Save", mock.Anything).Return(nil)
+			taskQueue := NewDeploymentTaskQueue(mockTaskStorage, "test", config.DeploymentQueueConfig{MaxAttempts: 3}, clock.NewFakeClock(time.Now()))
+
+			controllers := map[string]cluster.Controller{env.Name: envController}
+			endpointSvc := NewEndpointService(controllers, nil, nil, mockStorage, mockDeploymentStorage, nil, taskQueue, newUnlimitedQuotaService(mockStorage), mlpAPIClient, "dev", config.MonitoringConfig{})
+			e, err := endpointSvc.DeployEndpoint(env, model, version, &models.VersionEndpoint{
+				EnvVars: models.EnvVars{
+					{Name: "API_KEY", SecretName: "api-key"},
+					{Name: "API_KEY_COPY", SecretName: "api-key"},
+				},
+				Explainer: &models.Explainer{
+					Enabled:       true,
+					ExplainerType: models.CustomExplainerType,
+					Image:         "gojek/my-explainer:1",
+					EnvVars:       models.EnvVars{{Name: "API_KEY", SecretName: "explainer-key"}},
+				},
+			}, "user@example.com")
+			assert.NoError(t, err)
+
+			task := mockTaskStorage.Calls[0].Arguments[0].(*models.DeploymentTask)
+			mockStorage.On("Get", e.Id).Return(e, nil)
+			err = endpointSvc.ExecuteDeploymentTask(context.Background(), task)
+
+			savedEndpoint := mockStorage.Calls[2].Arguments[0].(*models.VersionEndpoint)
+			assert.Equal(t, tt.wantStatus, savedEndpoint.Status)
+			if tt.secretErr != nil {
+				assert.Error(t, err)
+				mlpAPIClient.AssertNumberOfCalls(t, "GetPlainSecretByNameAndProjectID", 1)
+				envController.AssertNotCalled(t, "Deploy", mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			// each secret is fetched once
+			mlpAPIClient.AssertNumberOfCalls(t, "GetPlainSecretByNameAndProjectID", 2)
+			modelService := envController.Calls[1].Arguments[0].(*models.Service)
+			assert.Equal(t, tt.wantSecrets, modelService.Secrets)
+			// only the secret references are stored
+			for _, ev := range savedEndpoint.EnvVars {
+				assert.Empty(t, ev.Value)
+			}
+		})
+	}
+}
+
+func TestDeployEndpoint_QuotaExceeded(t *testing.T) {
+	env := &models.Environment{
+		Name: "env1",
+		DefaultResourceRequest: &models.ResourceRequest{
+			MinReplica:    1,
+			MaxReplica:    2,
+			CpuRequest:    resource.MustParse("1"),
+			MemoryRequest: resource.MustParse("1Gi"),
+		},
+	}
+	project := mlp.Project{Id: 1, Name: "project"}
+	model := &models.Model{Name: "model", ProjectId: 1, Project: project, Type: models.ModelTypeSkLearn}
+	version := &models.Version{Id: 1}
+
+	maxCpu := resource.MustParse("3")
+	mockQuotaStorage := &mocks.ProjectQuotaStorage{}
+	mockQuotaStorage.On("Get", models.Id(1), env.Name).Return(&models.ProjectQuota{Limits: models.QuotaLimits{Cpu: &maxCpu}}, nil)
+	active := []*models.VersionEndpoint{
+		{Id: uuid.New(), EnvironmentName: env.Name, ResourceRequest: env.DefaultResourceRequest},
+	}
+	mockQuotaStorage.On("SaveEndpoint", models.Id(1), mock.Anything, mock.Anything).Return(
+		func(_ models.Id, _ *models.VersionEndpoint, check func([]*models.VersionEndpoint) error) error {
+			return check(active)
+		})
+	mockStorage := &mocks.VersionEndpointStorage{}
+
+	envController := &clusterMock.Controller{}
+	envController.On("Validate", mock.Anything).Return(nil)
+	controllers := map[string]cluster.Controller{env.Name: envController}
+	endpointSvc := NewEndpointService(controllers, nil, nil, mockStorage, nil, nil, nil, NewQuotaService(mockQuotaStorage, nil), nil, "dev", config.MonitoringConfig{})
+	_, err := endpointSvc.DeployEndpoint(env, model, version, &models.VersionEndpoint{}, "user@example.com")
+	assert.Equal(t, &models.QuotaExceededError{Resource: models.QuotaCpu, Usage: "2", RequestedUsage: "4", Limit: "3"}, err)
+	mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestDeployEndpoint_InvalidEndpoint(t *testing.T) {
+	env := &models.Environment{Name: "env1"}
+	project := mlp.Project{Id: 1, Name: "project"}
+	model := &models.Model{Name: "model", ProjectId: 1, Project: project, Type: models.ModelTypeSkLearn}
+	version := &models.Version{Id: 1}
+	newEndpoint := &models.VersionEndpoint{
+		AutoscalingPolicy: &models.AutoscalingPolicy{MetricType: models.AutoscalingMetricRPS, TargetValue: 1000},
+	}
+
+	envController := &clusterMock.Controller{}
+	envController.On("Validate", mock.Anything).Return(cluster.ErrInvalidAutoscalingPolicy)
+	mockStorage := &mocks.VersionEndpointStorage{}
+
+	controllers := map[string]cluster.Controller{env.Name: envController}
+	endpointSvc := NewEndpointService(controllers, nil, nil, mockStorage, nil, nil, nil, newUnlimitedQuotaService(mockStorage), nil, "dev", config.MonitoringConfig{})
+	_, err := endpointSvc.DeployEndpoint(env, model, version, newEndpoint, "user@example.com")
+	assert.Equal(t, models.NewInvalidEndpointError("invalid endpoint configuration: %v", cluster.ErrInvalidAutoscalingPolicy), err)
+	mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+
+	modelService := envController.Calls[0].Arguments[0].(*models.Service)
+	assert.Equal(t, newEndpoint.AutoscalingPolicy, modelService.AutoscalingPolicy)
+}
+
+// TestDeployEndpoint_RemoveSettings checks that the settings of the endpoint missing from the request are removed,
+// so that the defaults of the environment are used.
+func TestDeployEndpoint_RemoveSettings(t *testing.T) {
+	env := &models.Environment{Name: "env1"}
+	project := mlp.Project{Id: 1, Name: "project"}
+	model := &models.Model{Name: "model", ProjectId: 1, Project: project, Type: models.ModelTypeSkLearn}
+	version := &models.Version{Id: 1}
+	version.Endpoints = []*models.VersionEndpoint{
+		{
+			Id:                uuid.New(),
+			EnvironmentName:   env.Name,
+			Status:            models.EndpointRunning,
+			AutoscalingPolicy: &models.AutoscalingPolicy{MetricType: models.AutoscalingMetricRPS, TargetValue: 100},
+			NodePool:          "highmem",
+			TTL:               "24h",
+			IdleTimeout:       "6h",
+		},
+	}
+
+	envController := &clusterMock.Controller{}
+	envController.On("Validate", mock.Anything).Return(nil)
+	mockStorage := &mocks.VersionEndpointStorage{}
+	mockStorage.On("Save", mock.Anything).Return(nil)
+	mockTaskStorage := &mocks.DeploymentTaskStorage{}
+	mockTaskStorage.On("Save", mock.Anything).Return(nil)
+	taskQueue := NewDeploymentTaskQueue(mockTaskStorage, "test", config.DeploymentQueueConfig{MaxAttempts: 3}, clock.NewFakeClock(time.Now()))
+
+	controllers := map[string]cluster.Controller{env.Name: envController}
+	endpointSvc := NewEndpointService(controllers, nil, nil, mockStorage, nil, nil, taskQueue, newUnlimitedQuotaService(mockStorage), nil, "dev", config.MonitoringConfig{})
+	endpoint, err := endpointSvc.DeployEndpoint(env, model, version, &models.VersionEndpoint{}, "user@example.com")
+	assert.NoError(t, err)
+	assert.Nil(t, endpoint.AutoscalingPolicy)
+	assert.Empty(t, endpoint.NodePool)
+	assert.Empty(t, endpoint.TTL)
+	assert.Empty(t, endpoint.IdleTimeout)
+}
+
+func TestRenderEndpoint(t *testing.T) {
+	env := &models.Environment{
+		Name: "env1",
+		DefaultResourceRequest: &models.ResourceRequest{
+			MinReplica:    1,
+			MaxReplica:    1,
+			CpuRequest:    resource.MustParse("1"),
+			MemoryRequest: resource.MustParse("1Gi"),
+		},
+	}
+	project := mlp.Project{Id: 1, Name: "project"}
+	model := &models.Model{Name: "model", ProjectId: 1, Project: project, Type: models.ModelTypePyFunc}
+	version := &models.Version{Id: 1}
+	inferenceService := &v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "model-1", Namespace: project.Name},
+	}
+
+	tests := []struct {
+		name        string
+		validateErr error
+		renderErr   error
+		want        *models.DryRunResult
+		wantErr     error
+	}{
+		{
+			name: "rendered",
+			want: &models.DryRunResult{
+				Manifests: []*models.Manifest{
+					{
+						Kind:      "ConfigMap",
+						Name:      "model-1",
+						Namespace: project.Name,
+						Yaml:      "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  creationTimestamp: null\n  name: model-1\n  namespace: project\n",
+					},
+				},
+			},
+		},
+		{
+			name:        "invalid resource request",
+			validateErr: cluster.ErrInsufficientCpu,
+			wantErr:     models.NewInvalidEndpointError("invalid endpoint configuration: %v", cluster.ErrInsufficientCpu),
+		},
+		{
+			name:      "render error",
+			renderErr: cluster.ErrSecretMountNotSupported,
+			wantErr:   models.NewInvalidEndpointError("invalid endpoint configuration: %v", cluster.ErrSecretMountNotSupported),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envController := &clusterMock.Controller{}
+			envController.On("Validate", mock.Anything).Return(tt.validateErr)
+			envController.On("Render", mock.Anything).Return([]runtime.Object{inferenceService}, tt.renderErr)
+			imgBuilder := &imageBuilderMock.ImageBuilder{}
+			imgBuilder.On("ImageRef", project, model, version).Return("gojek/model:1")
+
+			// the endpoint is neither saved nor enqueued
+			controllers := map[string]cluster.Controller{env.Name: envController}
+			endpointSvc := NewEndpointService(controllers, imgBuilder, nil, nil, nil, nil, nil, newUnlimitedQuotaService(nil), nil, "dev", config.MonitoringConfig{})
+			result, err := endpointSvc.RenderEndpoint(env, model, version, &models.VersionEndpoint{})
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, result)
+
+			envController.AssertNotCalled(t, "Deploy", mock.Anything)
+			if tt.validateErr != nil {
+				envController.AssertNotCalled(t, "Render", mock.Anything)
+				return
+			}
+
+			modelService := envController.Calls[1].Arguments[0].(*models.Service)
+			assert.Equal(t, "gojek/model:1", modelService.Options.PyFuncImageName)
+			assert.Equal(t, env.DefaultResourceRequest, modelService.ResourceRequest)
+			imgBuilder.AssertNotCalled(t, "BuildImage", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDeployEndpoint_RestoreLastSucceededRevision(t *testing.T) {
+	project := mlp.Project{Id: 1, Name: "project"}
+	model := &models.Model{Name: "model", ProjectId: 1, Project: project, Type: models.ModelTypeCustom}
+	version := &models.Version{
+		Id:              1,
+		CustomPredictor: &models.CustomPredictor{Image: "gojek/my-model:2"},
+	}
+
+	lastSucceeded := &models.Deployment{
+		Revision: 2,
+		Status:   models.EndpointRunning,
+		Spec: &models.DeploymentSpec{
+			EnvVars: models.EnvVars{{Name: "WORKERS", Value: "1"}},
+			Options: &models.ModelOption{CustomPredictor: &models.CustomPredictor{Image: "gojek/my-model:1"}},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		previousStatus   models.EndpointStatus
+		lastSucceeded    *models.Deployment
+		lastSucceededErr error
+		wantStatus       models.EndpointStatus
+		wantEnvVars      models.EnvVars
+		wantDeployCalls  int
+	}{
+		{
+			name:            "restored",
+			previousStatus:  models.EndpointServing,
+			lastSucceeded:   lastSucceeded,
+			wantStatus:      models.EndpointServing,
+			wantEnvVars:     models.EnvVars{{Name: "WORKERS", Value: "1"}},
+			wantDeployCalls: 2,
+		},
+		{
+			name:             "no succeeded revision",
+			previousStatus:   models.EndpointRunning,
+			lastSucceededErr: gorm.ErrRecordNotFound,
+			wantStatus:       models.EndpointFailed,
+			wantEnvVars:      models.EnvVars{{Name: "WORKERS", Value: "2"}},
+			wantDeployCalls:  1,
+		},
+		{
+			name:            "endpoint was not running",
+			previousStatus:  models.EndpointFailed,
+			wantStatus:      models.EndpointFailed,
+			wantEnvVars:     models.EnvVars{{Name: "WORKERS", Value: "2"}},
+			wantDeployCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &models.VersionEndpoint{
+				Id:              uuid.New(),
+				EnvironmentName: "env1",
+				Status:          models.EndpointPending,
+				EnvVars:         models.EnvVars{{Name: "WORKERS", Value: "2"}},
+			}
+
+			envController := &clusterMock.Controller{}
+			envController.On("Validate", mock.Anything).Return(nil)
+			envController.On("Deploy", mock.Anything).Return(nil, errors.New("timeout")).Once()
+			envController.On("Deploy", mock.Anything).Return(&models.Service{Name: "model-1", Namespace: project.Name}, nil)
+
+			mockStorage := &mocks.VersionEndpointStorage{}
+			mockStorage.On("Get", endpoint.Id).Return(endpoint, nil)
+			mockStorage.On("Save", mock.Anything).Return(nil)
+			mockDeploymentStorage := &mocks.DeploymentStorage{}
+			mockDeploymentStorage.On("Save", mock.Anything).Return(nil, nil)
+			mockDeploymentStorage.On("GetLastSucceededRevision", endpoint.Id).Return(tt.lastSucceeded, tt.lastSucceededErr)
+
+			controllers := map[string]cluster.Controller{"env1": envController}
+			endpointSvc := NewEndpointService(controllers, nil, nil, mockStorage, mockDeploymentStorage, nil, nil, nil, nil, "dev", config.MonitoringConfig{})
+
+			payload := models.NewDeploymentTaskPayload(model, version, tt.previousStatus)
+			payload.User = "user@example.com"
+			err := endpointSvc.ExecuteDeploymentTask(context.Background(), &models.DeploymentTask{
+				Type:              models.DeployVersionEndpointTask,
+				VersionEndpointId: &endpoint.Id,
+				Payload:           payload,
+			})
+			assert.Error(t, err)
+
+			assert.Equal(t, tt.wantStatus, endpoint.Status)
+			assert.Equal(t, tt.wantEnvVars, endpoint.EnvVars)
+			envController.AssertNumberOfCalls(t, "Deploy", tt.wantDeployCalls)
+
+			failed := mockDeploymentStorage.Calls[1].Arguments[0].(*models.Deployment)
+			assert.Equal(t, models.EndpointFailed, failed.Status)
+			assert.Equal(t, "timeout", failed.Error)
+			assert.Equal(t, "env1", failed.EnvironmentName)
+			assert.Equal(t, "user@example.com", failed.DeployedBy)
+			assert.Equal(t, "gojek/my-model:2", failed.Spec.Options.CustomPredictor.Image)
+
+			if tt.wantDeployCalls == 1 {
+				return
+			}
+
+			assert.Equal(t, "deployment failed and revision 2 has been restored: timeout", endpoint.Message)
+			modelService := envController.Calls[1].Arguments[0].(*models.Service)
+			assert.Equal(t, "gojek/my-model:1", modelService.Options.CustomPredictor.Image)
+			restored := mockDeploymentStorage.Calls[4].Arguments[0].(*models.Deployment)
+			assert.Equal(t, models.EndpointServing, restored.Status)
+			assert.Equal(t, lastSucceeded.Spec.EnvVars, restored.Spec.EnvVars)
+		})
+	}
+}
+
+func TestDeployEndpoint_LeaseLost(t *testing.T) {
+	project := mlp.Project{Id: 1, Name: "project"}
+	model := &models.Model{Name: "model", ProjectId: 1, Project: project, Type: models.ModelTypeCustom}
+	version := &models.Version{Id: 1, CustomPredictor: &models.CustomPredictor{Image: "gojek/my-model:1"}}
+	endpoint := &models.VersionEndpoint{Id: uuid.New(), EnvironmentName: "env1", Status: models.EndpointPending}
+
+	envController := &clusterMock.Controller{}
+	mockStorage := &mocks.VersionEndpointStorage{}
+	mockStorage.On("Get", endpoint.Id).Return(endpoint, nil)
+	mockDeploymentStorage := &mocks.DeploymentStorage{}
+	mockDeploymentStorage.On("Save", mock.Anything).Return(nil, nil)
+
+	controllers := map[string]cluster.Controller{"env1": envController}
+	endpointSvc := NewEndpointService(controllers, nil, nil, mockStorage, mockDeploymentStorage, nil, nil, nil, nil, "dev", config.MonitoringConfig{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := endpointSvc.ExecuteDeploymentTask(ctx, &models.DeploymentTask{
+		Type:              models.DeployVersionEndpointTask,
+		VersionEndpointId: &endpoint.Id,
+		Payload:           models.NewDeploymentTaskPayload(model, version, models.EndpointServing),
+	})
+	assert.Equal(t, errLeaseLost, err)
+
+	envController.AssertNotCalled(t, "Deploy", mock.Anything)
+	mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+	interrupted := mockDeploymentStorage.Calls[1].Arguments[0].(*models.Deployment)
+	assert.Equal(t, models.EndpointFailed, interrupted.Status)
+	assert.Equal(t, errLeaseLost.Error(), interrupted.Error)
+}
+
+func TestUndeployEndpoint(t *testing.T) {
+	env := &models.Environment{Name: "env1"}
+	model := &models.Model{Id: 1, Name: "model"}
+	version := &models.Version{Id: 1, ModelId: 1}
+	endpoint := &models.VersionEndpoint{Id: uuid.New(), EnvironmentName: "env1", Status: models.EndpointRunning}
+
+	envController := &clusterMock.Controller{}
+	mockTaskStorage := &mocks.DeploymentTaskStorage{}
+	mockTaskStorage.On("Save", mock.Anything).Return(nil)
+	taskQueue := NewDeploymentTaskQueue(mockTaskStorage, "test", config.DeploymentQueueConfig{MaxAttempts: 3}, clock.NewFakeClock(time.Now()))
+
+	controllers := map[string]cluster.Controller{"env1": envController}
+	endpointSvc := NewEndpointService(controllers, nil, nil, nil, nil, nil, taskQueue, nil, nil, "dev", config.MonitoringConfig{})
+
+	undeployed, err := endpointSvc.UndeployEndpoint(env, model, version, endpoint)
+	assert.NoError(t, err)
+	assert.Equal(t, endpoint, undeployed)
+
+	envController.AssertNotCalled(t, "Delete", mock.Anything)
+	task := mockTaskStorage.Calls[0].Arguments[0].(*models.DeploymentTask)
+	assert.Equal(t, models.UndeployVersionEndpointTask, task.Type)
+	assert.Equal(t, models.TaskPending, task.Status)
+	assert.Equal(t, endpoint.Id, *task.VersionEndpointId)
+	assert.Equal(t, models.EndpointRunning, task.Payload.PreviousStatus)
+}
+
+func TestReconcileOrphanedEndpoints(t *testing.T) {
+	payload := &models.DeploymentTaskPayload{
+		Model:   &models.Model{Id: 1, Name: "model"},
+		Version: &models.Version{Id: 1, ModelId: 1},
+	}
+
+	tests := []struct {
+		name        string
+		latestTask  *models.DeploymentTask
+		wantEnqueue bool
+	}{
+		{
+			name:       "no deployment recorded",
+			latestTask: nil,
+		},
+		{
+			name:       "deployment abandoned",
+			latestTask: &models.DeploymentTask{Type: models.DeployVersionEndpointTask, Status: models.TaskRunning, Attempts: 4, MaxAttempts: 3, Payload: payload},
+		},
+		{
+			name:        "deployment interrupted",
+			latestTask:  &models.DeploymentTask{Type: models.DeployVersionEndpointTask, Status: models.TaskFailed, Attempts: 1, MaxAttempts: 3, Payload: payload},
+			wantEnqueue: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updatedBefore := time.Now()
+			endpoint := &models.VersionEndpoint{Id: uuid.New(), Status: models.EndpointPending}
+
+			mockStorage := &mocks.VersionEndpointStorage{}
+			mockStorage.On("ListOrphanedPending", updatedBefore).Return([]*models.VersionEndpoint{endpoint}, nil)
+			mockStorage.On("Save", mock.Anything).Return(nil)
+			mockDeploymentStorage := &mocks.DeploymentStorage{}
+			mockDeploymentStorage.On("FailOrphanedPending", updatedBefore, "deployment was interrupted").Return(int64(1), nil)
+			mockTaskStorage := &mocks.DeploymentTaskStorage{}
+			mockTaskStorage.On("FindLatest", endpoint.Id).Return(tt.latestTask, nil)
+			mockTaskStorage.On("Save", mock.Anything).Return(nil)
+			taskQueue := NewDeploymentTaskQueue(mockTaskStorage, "test", config.DeploymentQueueConfig{MaxAttempts: 3}, clock.NewFakeClock(time.Now()))
+			endpointSvc := NewEndpointService(nil, nil, nil, mockStorage, mockDeploymentStorage, nil, taskQueue, nil, nil, "dev", config.MonitoringConfig{})
+
+			err := endpointSvc.ReconcileOrphans(context.Background(), updatedBefore)
+			assert.NoError(t, err)
+			mockDeploymentStorage.AssertExpectations(t)
+
+			if tt.wantEnqueue {
+				assert.Equal(t, models.EndpointPending, endpoint.Status)
+				mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+				task := mockTaskStorage.Calls[1].Arguments[0].(*models.DeploymentTask)
+				assert.Equal(t, models.DeployVersionEndpointTask, task.Type)
+				assert.Equal(t, models.TaskPending, task.Status)
+				assert.Equal(t, endpoint.Id, *task.VersionEndpointId)
+				assert.Equal(t, payload, task.Payload)
+			} else {
+				assert.Equal(t, models.EndpointFailed, endpoint.Status)
+				mockStorage.AssertCalled(t, "Save", endpoint)
+				mockTaskStorage.AssertNotCalled(t, "Save", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestRollbackEndpoint(t *testing.T) {
+	model := &models.Model{Name: "model", Project: mlp.Project{Id: 1, Name: "project"}}
+	version := &models.Version{Id: 1}
+	revision := &models.Deployment{
+		Revision: 1,
+		Status:   models.EndpointRunning,
+		Spec: &models.DeploymentSpec{
+			EnvVars:  models.EnvVars{{Name: "WORKERS", Value: "1"}},
+			NodePool: "highmem",
+			Options:  &models.ModelOption{PyFuncImageName: "gojek/model:1"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		revisionErr error
+		validateErr error
+		wantError   bool
+	}{
+		{
+			name: "rolled back",
+		},
+		{
+			name:        "revision not found",
+			revisionErr: gorm.ErrRecordNotFound,
+			wantError:   true,
+		},
+		{
+			name:        "revision out of the bounds of the environment",
+			validateErr: errors.New("requested cpu exceeds the maximum"),
+			wantError:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &models.VersionEndpoint{
+				Id:              uuid.New(),
+				EnvironmentName: "env1",
+				Status:          models.EndpointServing,
+				EnvVars:         models.EnvVars{{Name: "WORKERS", Value: "2"}},
+			}
+
+			mockStorage := &mocks.VersionEndpointStorage{}
+			mockStorage.On("Save", mock.Anything).Return(nil)
+			mockDeploymentStorage := &mocks.DeploymentStorage{}
+			mockDeploymentStorage.On("GetRevision", endpoint.Id, 1).Return(revision, tt.revisionErr)
+			mockTaskStorage := &mocks.DeploymentTaskStorage{}
+			mockTaskStorage.On("Save", mock.Anything).Return(nil)
+			taskQueue := NewDeploymentTaskQueue(mockTaskStorage, "test", config.DeploymentQueueConfig{MaxAttempts: 3}, clock.NewFakeClock(time.Now()))
+
+			ctl := &clusterMock.Controller{}
+			ctl.On("Validate", mock.Anything).Return(tt.validateErr)
+			controllers := map[string]cluster.Controller{"env1": ctl}
+			endpointSvc := NewEndpointService(controllers, nil, nil, mockStorage, mockDeploymentStorage, nil, taskQueue, newUnlimitedQuotaService(mockStorage), nil, "dev", config.MonitoringConfig{})
+
+			_, err := endpointSvc.RollbackEndpoint(model, version, endpoint, 1, "user@example.com")
+			if tt.wantError {
+				assert.Error(t, err)
+				if tt.validateErr != nil {
+					var invalidErr *models.InvalidEndpointError
+					assert.True(t, errors.As(err, &invalidErr))
+				}
+				mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+				mockTaskStorage.AssertNotCalled(t, "Save", mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, models.EndpointPending, endpoint.Status)
+			assert.Equal(t, revision.Spec.EnvVars, endpoint.EnvVars)
+			assert.Equal(t, "highmem", endpoint.NodePool)
+
+			task := mockTaskStorage.Calls[0].Arguments[0].(*models.DeploymentTask)
+			assert.Equal(t, models.DeployVersionEndpointTask, task.Type)
+			assert.Equal(t, models.EndpointServing, task.Payload.PreviousStatus)
+			assert.Equal(t, revision.Spec.Options, task.Payload.Options)
+		})
+	}
+}
+
+func TestScaleEndpoint(t *testing.T) {
+	model := &models.Model{Name: "model", ProjectId: 1, Project: mlp.Project{Id: 1, Name: "project"}}
+	options := &models.ModelOption{PyFuncImageName: "gojek/model:1"}
+
+	tests := []struct {
+		name      string
+		status    models.EndpointStatus
+		scaleErr  error
+		wantError bool
+	}{
+		{
+			name:   "scaled",
+			status: models.EndpointServing,
+		},
+		{
+			name:      "endpoint not running",
+			status:    models.EndpointPending,
+			wantError: true,
+		},
+		{
+			name:      "scaling failed",
+			status:    models.EndpointRunning,
+			scaleErr:  cluster.ErrUnableToScaleInferenceService,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &models.VersionEndpoint{
+				Id:                   uuid.New(),
+				VersionId:            1,
+				VersionModelId:       1,
+				EnvironmentName:      "env1",
+				Namespace:            "project",
+				InferenceServiceName: "model-1",
+				Status:               tt.status,
+				ResourceRequest: &models.ResourceRequest{
+					MinReplica:    1,
+					MaxReplica:    2,
+					CpuRequest:    resource.MustParse("1"),
+					MemoryRequest: resource.MustParse("1Gi"),
+				},
+			}
+
+			controller := &clusterMock.Controller{}
+			controller.On("Scale", mock.Anything).Return(tt.scaleErr)
+			mockStorage := &mocks.VersionEndpointStorage{}
+			mockStorage.On("Save", mock.Anything).Return(nil)
+			mockDeploymentStorage := &mocks.DeploymentStorage{}
+			mockDeploymentStorage.On("GetLastSucceededRevision", endpoint.Id).Return(&models.Deployment{Revision: 1, Spec: &models.DeploymentSpec{Options: options}}, nil)
+			mockDeploymentStorage.On("Save", mock.Anything).Return(&models.Deployment{}, nil)
+
+			controllers := map[string]cluster.Controller{"env1": controller}
+			endpointSvc := NewEndpointService(controllers, nil, nil, mockStorage, mockDeploymentStorage, nil, nil, newUnlimitedQuotaService(mockStorage), nil, "dev", config.MonitoringConfig{})
+
+			scaled, err := endpointSvc.ScaleEndpoint(model, endpoint, 4, 10, "scaling schedule 1")
+			if tt.wantError {
+				assert.Error(t, err)
+				if tt.scaleErr != nil {
+					// the scaled endpoint is saved before scaling and restored when scaling failed
+					mockStorage.AssertNumberOfCalls(t, "Save", 2)
+					assert.Equal(t, endpoint, mockStorage.Calls[1].Arguments[0])
+				} else {
+					mockStorage.AssertNotCalled(t, "Save", mock.Anything)
+				}
+				mockDeploymentStorage.AssertNotCalled(t, "Save", mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, 4, scaled.ResourceRequest.MinReplica)
+			assert.Equal(t, 10, scaled.ResourceRequest.MaxReplica)
+			assert.Equal(t, endpoint.ResourceRequest.CpuRequest, scaled.ResourceRequest.CpuRequest)
+			assert.Equal(t, 1, endpoint.ResourceRequest.MinReplica)
+
+			modelService := controller.Calls[0].Arguments[0].(*models.Service)
+			assert.Equal(t, "model-1", modelService.Name)
+			assert.Equal(t, "project", modelService.Namespace)
+			assert.Equal(t, 10, modelService.ResourceRequest.MaxReplica)
+
+			deployment := mockDeploymentStorage.Calls[1].Arguments[0].(*models.Deployment)
+			assert.Equal(t, models.EndpointServing, deployment.Status)
+			assert.Equal(t, "scaling schedule 1", deployment.Reason)
+			assert.Equal(t, 4, deployment.Spec.ResourceRequest.MinReplica)
+			assert.Equal(t, options, deployment.Spec.Options)
+		})
+	}
+}
+
+func TestListRevisions(t *testing.T) {
+	id := uuid.New()
+	deployments := []*models.Deployment{
+		{
+			Revision: 2,
+			Status:   models.EndpointRunning,
+			Spec:     &models.DeploymentSpec{EnvVars: models.EnvVars{{Name: "WORKERS", Value: "2"}}},
+		},
+		{
+			Revision: 1,
+			Status:   models.EndpointRunning,
+			Spec:     &models.DeploymentSpec{EnvVars: models.EnvVars{{Name: "WORKERS", Value: "1"}}},
+		},
+	}
+
+	mockDeploymentStorage := &mocks.DeploymentStorage{}
+	mockDeploymentStorage.On("ListRevisions", id).Return(deployments, nil)
+	endpointSvc := NewEndpointService(nil, nil, nil, nil, mockDeploymentStorage, nil, nil, nil, nil, "dev", config.MonitoringConfig{})
+
+	revisions, err := endpointSvc.ListRevisions(id)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Equal(t, 2, revisions[0].Revision)
+	assert.Equal(t, []models.SpecChange{{Field: "env_vars.WORKERS.value", From: "1", To: "2"}}, revisions[0].Changes)
+	assert.Equal(t, []models.SpecChange{{Field: "env_vars.WORKERS.value", To: "1"}}, revisions[1].Changes)
+}
@@ -15,17 +15,55 @@
 package storage
 
 import (
-	"github.com/gojek/merlin/models"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
+
+	"github.com/gojek/merlin/models"
 )
 
+// DeploymentFilter selects the deployments to be listed, a zero value field doesn't filter the deployments
+type DeploymentFilter struct {
+	ProjectId       models.Id
+	ModelId         models.Id
+	VersionId       models.Id
+	EnvironmentName string
+	Status          models.EndpointStatus
+	// Start and End restrict the deployments to the ones started within the time range
+	Start time.Time
+	End   time.Time
+	// Limit and Offset page the listed deployments, a zero limit lists all of them
+	Limit  int
+	Offset int
+}
+
 type DeploymentStorage interface {
 	// ListInModel return all deployment within a model
 	ListInModel(model *models.Model) ([]*models.Deployment, error)
-	// Save save the deployment to underlying storage
+	// List return the deployments matching the filter, the latest first
+	List(filter DeploymentFilter) ([]*models.Deployment, error)
+	// Stats return the statistics of all the deployments matching the filter, regardless of its limit and offset.
+	// The changes recorded with a reason, e.g. the scaling of a version endpoint by a schedule, aren't counted.
+	Stats(filter DeploymentFilter) (*models.DeploymentStats, error)
+	// Save save the deployment to underlying storage, a deployment with spec is assigned the next revision of its version endpoint
 	Save(deployment *models.Deployment) (*models.Deployment, error)
+	// ListRevisions return the deployments of a version endpoint having a recorded spec, the latest revision first
+	ListRevisions(versionEndpointId uuid.UUID) ([]*models.Deployment, error)
+	// GetRevision return the deployment of a version endpoint with the given revision
+	GetRevision(versionEndpointId uuid.UUID, revision int) (*models.Deployment, error)
+	// GetLastSucceededRevision return the latest deployment of a version endpoint which has made the endpoint ready
+	GetLastSucceededRevision(versionEndpointId uuid.UUID) (*models.Deployment, error)
+	// ListLastSucceededBefore return the latest succeeded deployment started before the given time of each version
+	// endpoint of the project, or of all projects if the project id is zero. The endpoints terminated or failed before
+	// that time are left out.
+	ListLastSucceededBefore(projectId models.Id, before time.Time) ([]*models.Deployment, error)
 	// GetFirstSuccessModelVersionPerModel Return mapping of model id and the first model version with a successful model version
 	GetFirstSuccessModelVersionPerModel() (map[models.Id]models.Id, error)
+	// FailOrphanedPending marks as failed the pending deployments last updated before the given time, which have been
+	// superseded by a newer deployment of their version endpoint or whose endpoint has no unfinished deployment task.
+	// It returns the number of deployments marked as failed.
+	FailOrphanedPending(updatedBefore time.Time, message string) (int64, error)
 }
 
 type deploymentStorage struct {
@@ -42,11 +80,131 @@ func (d *deploymentStorage) ListInModel(model *models.Model) ([]*models.Deployme
 	return deployments, err
 }
 
+func (d *deploymentStorage) List(filter DeploymentFilter) ([]*models.Deployment, error) {
+	query := d.filter(filter).Order("created_at desc")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit).Offset(filter.Offset)
+	}
+
+	var deployments []*models.Deployment
+	err := query.Find(&deployments).Error
+	return deployments, err
+}
+
+func (d *deploymentStorage) Stats(filter DeploymentFilter) (*models.DeploymentStats, error) {
+	stats := &models.DeploymentStats{}
+	// the changes made by merlin, e.g. by scaling schedules, are recorded with a reason and aren't deployments
+	err := d.filter(filter).Model(&models.Deployment{}).
+		Select(`count(*) as total,
+			count(*) filter (where status in ('running', 'serving')) as succeeded,
+			count(*) filter (where status not in ('pending', 'running', 'serving')) as failed,
+			coalesce(avg(extract(epoch from updated_at - created_at)) filter (where status in ('running', 'serving')), 0) as mean_time_to_ready_seconds`).
+		Where("coalesce(reason, '') = ''").
+		Scan(stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats.SetSuccessRate()
+	return stats, nil
+}
+
+// filter returns the query of the deployments matching the filter, regardless of its limit and offset
+func (d *deploymentStorage) filter(filter DeploymentFilter) *gorm.DB {
+	query := d.db.Where(&models.Deployment{
+		ProjectId:       filter.ProjectId,
+		VersionModelId:  filter.ModelId,
+		VersionId:       filter.VersionId,
+		EnvironmentName: filter.EnvironmentName,
+		Status:          filter.Status,
+	})
+	if !filter.Start.IsZero() {
+		query = query.Where("created_at >= ?", filter.Start)
+	}
+	if !filter.End.IsZero() {
+		query = query.Where("created_at < ?", filter.End)
+	}
+	return query
+}
+
 func (d *deploymentStorage) Save(deployment *models.Deployment) (*models.Deployment, error) {
-	err := d.db.Save(deployment).Error
+	if deployment.Revision != 0 || deployment.Spec == nil {
+		err := d.db.Save(deployment).Error
+		return deployment, err
+	}
+
+	tx := d.db.Begin()
+	defer tx.RollbackUnlessCommitted()
+
+	// the version endpoint is locked until the deployment is saved, so that the concurrent deployments
+	// of the endpoint are assigned distinct revisions
+	err := tx.Exec("SELECT 1 FROM version_endpoints WHERE id = ? FOR UPDATE", deployment.VersionEndpointId).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var latest struct {
+		Revision int
+	}
+	err = tx.Table("deployments").
+		Select("coalesce(max(revision), 0) as revision").
+		Where("version_endpoint_id = ?", deployment.VersionEndpointId).
+		Scan(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+	deployment.Revision = latest.Revision + 1
+
+	err = tx.Save(deployment).Error
+	if err == nil {
+		err = tx.Commit().Error
+	}
+	if err != nil {
+		// the revision is assigned again when the deployment is saved again
+		deployment.Revision = 0
+	}
 	return deployment, err
 }
 
+func (d *deploymentStorage) ListRevisions(versionEndpointId uuid.UUID) ([]*models.Deployment, error) {
+	var deployments []*models.Deployment
+	err := d.db.Where("version_endpoint_id = ? AND spec IS NOT NULL", versionEndpointId).
+		Order("revision desc").
+		Find(&deployments).Error
+	return deployments, err
+}
+
+func (d *deploymentStorage) GetRevision(versionEndpointId uuid.UUID, revision int) (*models.Deployment, error) {
+	deployment := &models.Deployment{}
+	err := d.db.Where("version_endpoint_id = ? AND revision = ? AND spec IS NOT NULL", versionEndpointId, revision).
+		First(deployment).Error
+	return deployment, err
+}
+
+func (d *deploymentStorage) GetLastSucceededRevision(versionEndpointId uuid.UUID) (*models.Deployment, error) {
+	deployment := &models.Deployment{}
+	err := d.db.Where("version_endpoint_id = ? AND spec IS NOT NULL AND (status = 'running' or status = 'serving')", versionEndpointId).
+		Order("revision desc").
+		First(deployment).Error
+	return deployment, err
+}
+
+func (d *deploymentStorage) ListLastSucceededBefore(projectId models.Id, before time.Time) ([]*models.Deployment, error) {
+	query := d.db.
+		Select("DISTINCT ON (deployments.version_endpoint_id) deployments.*").
+		Joins("JOIN version_endpoints ON version_endpoints.id = deployments.version_endpoint_id").
+		Where("deployments.status IN (?) AND deployments.created_at < ?", []models.EndpointStatus{models.EndpointRunning, models.EndpointServing}, before).
+		Where("version_endpoints.status NOT IN (?) OR version_endpoints.updated_at >= ?", []models.EndpointStatus{models.EndpointTerminated, models.EndpointFailed}, before).
+		Order("deployments.version_endpoint_id, deployments.created_at desc")
+	if projectId != 0 {
+		query = query.Where("deployments.project_id = ?", projectId)
+	}
+
+	var deployments []*models.Deployment
+	err := query.Find(&deployments).Error
+	return deployments, err
+}
+
 func (d *deploymentStorage) GetFirstSuccessModelVersionPerModel() (map[models.Id]models.Id, error) {
 	rows, err := d.db.Table("deployments").
 		Select("version_model_id , min(version_id)").
@@ -70,3 +228,16 @@ func (d *deploymentStorage) GetFirstSuccessModelVersionPerModel() (map[models.Id
 	}
 	return resultMap, nil
 }
+
+func (d *deploymentStorage) FailOrphanedPending(updatedBefore time.Time, message string) (int64, error) {
+	// the time of the last update is kept, it's the last known progress of the deployment
+	result := d.db.Model(&models.Deployment{}).
+		Where("deployments.status = ? AND deployments.updated_at < ?", models.EndpointPending, updatedBefore).
+		Where(`EXISTS (SELECT 1 FROM deployments newer WHERE newer.version_endpoint_id = deployments.version_endpoint_id AND newer.id > deployments.id)
+			OR NOT EXISTS (SELECT 1 FROM deployment_tasks WHERE deployment_tasks.version_endpoint_id = deployments.version_endpoint_id AND deployment_tasks.status IN ('pending', 'running'))`).
+		UpdateColumns(map[string]interface{}{
+			"status": models.EndpointFailed,
+			"error":  message,
+		})
+	return result.RowsAffected, result.Error
+}
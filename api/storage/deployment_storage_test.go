@@ -17,7 +17,9 @@
 package storage
 
 import (
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
@@ -192,3 +194,393 @@ func TestDeploymentStorage_GetFirstSuccessModelVersionPerModel(t *testing.T) {
 		assert.Equal(t, v2.Id, resultMap[m.Id])
 	})
 }
+
+func TestDeploymentStorage_Revisions(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		deploymentStorage := NewDeploymentStorage(db)
+		isDefaultTrue := true
+
+		p := mlp.Project{
+			Name:              "project",
+			MlflowTrackingUrl: "http://mlflow:5000",
+		}
+		db.Create(&p)
+
+		m := models.Model{
+			Id:           1,
+			ProjectId:    models.Id(p.Id),
+			ExperimentId: 1,
+			Name:         "model",
+			Type:         models.ModelTypeSkLearn,
+		}
+		db.Create(&m)
+
+		v := models.Version{
+			ModelId:     m.Id,
+			RunId:       "1",
+			ArtifactUri: "gcs:/mlp/1/1",
+		}
+		db.Create(&v)
+
+		env1 := models.Environment{
+			Name:      "env1",
+			Cluster:   "k8s",
+			IsDefault: &isDefaultTrue,
+		}
+		db.Create(&env1)
+
+		e := models.VersionEndpoint{
+			Id:              uuid.New(),
+			VersionId:       v.Id,
+			VersionModelId:  m.Id,
+			Status:          "pending",
+			EnvironmentName: env1.Name,
+		}
+		db.Create(&e)
+
+		newDeployment := func(status models.EndpointStatus, workers string) *models.Deployment {
+			return &models.Deployment{
+				ProjectId:         models.Id(p.Id),
+				VersionId:         v.Id,
+				VersionModelId:    m.Id,
+				VersionEndpointId: e.Id,
+				Status:            status,
+				Spec: &models.DeploymentSpec{
+					EnvVars: models.EnvVars{{Name: "WORKERS", Value: workers}},
+				},
+			}
+		}
+
+		// deployment made before revisions were recorded
+		_, err := deploymentStorage.Save(&models.Deployment{
+			ProjectId:         models.Id(p.Id),
+			VersionId:         v.Id,
+			VersionModelId:    m.Id,
+			VersionEndpointId: e.Id,
+			Status:            models.EndpointRunning,
+		})
+		assert.NoError(t, err)
+
+		deploy1, err := deploymentStorage.Save(newDeployment(models.EndpointRunning, "1"))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, deploy1.Revision)
+
+		deploy2, err := deploymentStorage.Save(newDeployment(models.EndpointFailed, "2"))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, deploy2.Revision)
+
+		revisions, err := deploymentStorage.ListRevisions(e.Id)
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 2)
+		assert.Equal(t, 2, revisions[0].Revision)
+		assert.Equal(t, "2", revisions[0].Spec.EnvVars[0].Value)
+
+		revision, err := deploymentStorage.GetRevision(e.Id, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", revision.Spec.EnvVars[0].Value)
+
+		_, err = deploymentStorage.GetRevision(e.Id, 3)
+		assert.True(t, gorm.IsRecordNotFoundError(err))
+
+		lastSucceeded, err := deploymentStorage.GetLastSucceededRevision(e.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, lastSucceeded.Revision)
+
+		// concurrent deployments are assigned distinct revisions
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := deploymentStorage.Save(newDeployment(models.EndpointRunning, "3"))
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		revisions, err = deploymentStorage.ListRevisions(e.Id)
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 7)
+		for i, revision := range revisions {
+			assert.Equal(t, 7-i, revision.Revision)
+		}
+	})
+}
+
+func TestDeploymentStorage_ListWithFilter(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		deploymentStorage := NewDeploymentStorage(db)
+		isDefaultTrue := true
+
+		p := mlp.Project{
+			Name:              "project",
+			MlflowTrackingUrl: "http://mlflow:5000",
+		}
+		db.Create(&p)
+
+		m := models.Model{
+			Id:           1,
+			ProjectId:    models.Id(p.Id),
+			ExperimentId: 1,
+			Name:         "model",
+			Type:         models.ModelTypeSkLearn,
+		}
+		db.Create(&m)
+
+		v := models.Version{
+			ModelId:     m.Id,
+			RunId:       "1",
+			ArtifactUri: "gcs:/mlp/1/1",
+		}
+		db.Create(&v)
+
+		env1 := models.Environment{
+			Name:      "env1",
+			Cluster:   "k8s",
+			IsDefault: &isDefaultTrue,
+		}
+		db.Create(&env1)
+
+		e := models.VersionEndpoint{
+			Id:              uuid.New(),
+			VersionId:       v.Id,
+			VersionModelId:  m.Id,
+			Status:          "pending",
+			EnvironmentName: env1.Name,
+		}
+		db.Create(&e)
+
+		start := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+		for i, status := range []models.EndpointStatus{models.EndpointRunning, models.EndpointFailed, models.EndpointFailed} {
+			createdAt := start.Add(time.Duration(i) * time.Hour)
+			_, err := deploymentStorage.Save(&models.Deployment{
+				ProjectId:         models.Id(p.Id),
+				VersionId:         v.Id,
+				VersionModelId:    m.Id,
+				VersionEndpointId: e.Id,
+				EnvironmentName:   env1.Name,
+				Status:            status,
+				CreatedUpdated:    models.CreatedUpdated{CreatedAt: createdAt, UpdatedAt: createdAt.Add(time.Minute)},
+			})
+			assert.NoError(t, err)
+		}
+
+		deployments, err := deploymentStorage.List(DeploymentFilter{ProjectId: models.Id(p.Id)})
+		assert.NoError(t, err)
+		assert.Len(t, deployments, 3)
+		assert.Equal(t, start.Add(2*time.Hour), deployments[0].CreatedAt.UTC())
+		assert.Equal(t, 60.0, deployments[0].DurationSeconds)
+
+		deployments, err = deploymentStorage.List(DeploymentFilter{
+			ModelId:         m.Id,
+			VersionId:       v.Id,
+			EnvironmentName: env1.Name,
+			Status:          models.EndpointFailed,
+			Start:           start,
+			End:             start.Add(2 * time.Hour),
+		})
+		assert.NoError(t, err)
+		assert.Len(t, deployments, 1)
+
+		deployments, err = deploymentStorage.List(DeploymentFilter{EnvironmentName: "env2"})
+		assert.NoError(t, err)
+		assert.Len(t, deployments, 0)
+
+		deployments, err = deploymentStorage.List(DeploymentFilter{ModelId: m.Id, Limit: 1, Offset: 1})
+		assert.NoError(t, err)
+		assert.Len(t, deployments, 1)
+		assert.Equal(t, start.Add(time.Hour), deployments[0].CreatedAt.UTC())
+
+		// the scaling of the endpoint by a schedule isn't a deployment
+		scaledAt := start.Add(3 * time.Hour)
+		_, err = deploymentStorage.Save(&models.Deployment{
+			ProjectId:         models.Id(p.Id),
+			VersionId:         v.Id,
+			VersionModelId:    m.Id,
+			VersionEndpointId: e.Id,
+			EnvironmentName:   env1.Name,
+			Status:            models.EndpointRunning,
+			Reason:            "scaling schedule 1",
+			CreatedUpdated:    models.CreatedUpdated{CreatedAt: scaledAt, UpdatedAt: scaledAt},
+		})
+		assert.NoError(t, err)
+
+		// the statistics cover all the deployments regardless of the page
+		stats, err := deploymentStorage.Stats(DeploymentFilter{ModelId: m.Id, Limit: 1, Offset: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, &models.DeploymentStats{
+			Total:                  3,
+			Succeeded:              1,
+			Failed:                 2,
+			SuccessRate:            1.0 / 3.0,
+			MeanTimeToReadySeconds: 60,
+		}, stats)
+
+		stats, err = deploymentStorage.Stats(DeploymentFilter{EnvironmentName: "env2"})
+		assert.NoError(t, err)
+		assert.Equal(t, &models.DeploymentStats{}, stats)
+	})
+}
+
+func TestDeploymentStorage_FailOrphanedPending(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		deploymentStorage := NewDeploymentStorage(db)
+		isDefaultTrue := true
+
+		p := mlp.Project{
+			Name:              "project",
+			MlflowTrackingUrl: "http://mlflow:5000",
+		}
+		db.Create(&p)
+
+		m := models.Model{
+			Id:           1,
+			ProjectId:    models.Id(p.Id),
+			ExperimentId: 1,
+			Name:         "model",
+			Type:         models.ModelTypeSkLearn,
+		}
+		db.Create(&m)
+
+		v := models.Version{
+			ModelId:     m.Id,
+			RunId:       "1",
+			ArtifactUri: "gcs:/mlp/1/1",
+		}
+		db.Create(&v)
+
+		env1 := models.Environment{
+			Name:      "env1",
+			Cluster:   "k8s",
+			IsDefault: &isDefaultTrue,
+		}
+		db.Create(&env1)
+
+		newEndpoint := func() models.VersionEndpoint {
+			e := models.VersionEndpoint{
+				Id:              uuid.New(),
+				VersionId:       v.Id,
+				VersionModelId:  m.Id,
+				Status:          models.EndpointPending,
+				EnvironmentName: env1.Name,
+			}
+			db.Create(&e)
+			return e
+		}
+		newDeployment := func(e models.VersionEndpoint, status models.EndpointStatus) *models.Deployment {
+			d, err := deploymentStorage.Save(&models.Deployment{
+				ProjectId:         models.Id(p.Id),
+				VersionId:         v.Id,
+				VersionModelId:    m.Id,
+				VersionEndpointId: e.Id,
+				EnvironmentName:   env1.Name,
+				Status:            status,
+			})
+			assert.NoError(t, err)
+			return d
+		}
+
+		// the deployment task of the endpoint was interrupted
+		orphaned := newDeployment(newEndpoint(), models.EndpointPending)
+
+		// the deployment task of the endpoint was retried
+		retried := newEndpoint()
+		superseded := newDeployment(retried, models.EndpointPending)
+		inProgress := newDeployment(retried, models.EndpointPending)
+		db.Create(&models.DeploymentTask{Type: models.DeployVersionEndpointTask, Status: models.TaskRunning, VersionEndpointId: &retried.Id})
+
+		failed, err := deploymentStorage.FailOrphanedPending(time.Now().Add(time.Minute), "deployment was interrupted")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), failed)
+
+		for _, d := range []*models.Deployment{orphaned, superseded, inProgress} {
+			assert.NoError(t, db.First(d, d.Id).Error)
+		}
+		assert.Equal(t, models.EndpointFailed, orphaned.Status)
+		assert.Equal(t, "deployment was interrupted", orphaned.Error)
+		assert.Equal(t, models.EndpointFailed, superseded.Status)
+		assert.Equal(t, models.EndpointPending, inProgress.Status)
+	})
+}
+
+func TestDeploymentStorage_ListLastSucceededBefore(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		deploymentStorage := NewDeploymentStorage(db)
+		isDefaultTrue := true
+
+		p := mlp.Project{
+			Name:              "project",
+			MlflowTrackingUrl: "http://mlflow:5000",
+		}
+		db.Create(&p)
+
+		m := models.Model{
+			Id:           1,
+			ProjectId:    models.Id(p.Id),
+			ExperimentId: 1,
+			Name:         "model",
+			Type:         models.ModelTypeSkLearn,
+		}
+		db.Create(&m)
+
+		v := models.Version{
+			ModelId:     m.Id,
+			RunId:       "1",
+			ArtifactUri: "gcs:/mlp/1/1",
+		}
+		db.Create(&v)
+
+		env1 := models.Environment{
+			Name:      "env1",
+			Cluster:   "k8s",
+			IsDefault: &isDefaultTrue,
+		}
+		db.Create(&env1)
+
+		before := time.Now().Add(-time.Hour)
+		newEndpoint := func(status models.EndpointStatus, updatedAt time.Time) models.VersionEndpoint {
+			e := models.VersionEndpoint{
+				Id:              uuid.New(),
+				VersionId:       v.Id,
+				VersionModelId:  m.Id,
+				Status:          status,
+				EnvironmentName: env1.Name,
+			}
+			db.Create(&e)
+			db.Model(&e).UpdateColumn("updated_at", updatedAt)
+			return e
+		}
+		newDeployment := func(e models.VersionEndpoint, status models.EndpointStatus, createdAt time.Time) *models.Deployment {
+			d := &models.Deployment{
+				ProjectId:         models.Id(p.Id),
+				VersionId:         v.Id,
+				VersionModelId:    m.Id,
+				VersionEndpointId: e.Id,
+				EnvironmentName:   env1.Name,
+				Status:            status,
+			}
+			db.Create(d)
+			db.Model(d).UpdateColumn("created_at", createdAt)
+			return d
+		}
+
+		running := newEndpoint(models.EndpointRunning, time.Now())
+		newDeployment(running, models.EndpointRunning, before.Add(-3*time.Hour))
+		lastSucceeded := newDeployment(running, models.EndpointRunning, before.Add(-2*time.Hour))
+		newDeployment(running, models.EndpointFailed, before.Add(-time.Hour))
+		newDeployment(running, models.EndpointRunning, before.Add(time.Minute))
+
+		// terminated before the given time
+		terminated := newEndpoint(models.EndpointTerminated, before.Add(-time.Hour))
+		newDeployment(terminated, models.EndpointRunning, before.Add(-2*time.Hour))
+
+		deployments, err := deploymentStorage.ListLastSucceededBefore(models.Id(p.Id), before)
+		assert.NoError(t, err)
+		assert.Len(t, deployments, 1)
+		assert.Equal(t, lastSucceeded.Id, deployments[0].Id)
+
+		deployments, err = deploymentStorage.ListLastSucceededBefore(models.Id(p.Id)+1, before)
+		assert.NoError(t, err)
+		assert.Empty(t, deployments)
+	})
+}
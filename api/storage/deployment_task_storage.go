@@ -0,0 +1,107 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+
+	"github.com/gojek/merlin/models"
+)
+
+const claimDeploymentTaskQuery = `
+UPDATE deployment_tasks
+SET status = 'running', owner = ?, lease_expires_at = ?, attempts = attempts + 1, updated_at = ?
+WHERE id = (
+	SELECT id FROM deployment_tasks t
+	WHERE (t.status = 'pending' OR (t.status = 'running' AND t.lease_expires_at < ?))
+	-- tasks of the same version endpoint are executed in order
+	AND NOT EXISTS (
+		SELECT 1 FROM deployment_tasks prev
+		WHERE prev.version_endpoint_id = t.version_endpoint_id AND prev.id < t.id AND prev.status IN ('pending', 'running')
+	)
+	ORDER BY t.id
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING *`
+
+type DeploymentTaskStorage interface {
+	// Save saves the task to underlying storage
+	Save(task *models.DeploymentTask) error
+	// Claim leases the oldest pending task, or running task whose lease has expired, to the owner.
+	// It returns nil if there is no task to be claimed.
+	Claim(owner string, now time.Time, lease time.Duration) (*models.DeploymentTask, error)
+	// Heartbeat extends the lease of a running task. It returns false if the task is no longer owned by the owner.
+	Heartbeat(id models.Id, owner string, leaseExpiresAt time.Time) (bool, error)
+	// FindLatest returns the most recent task of a version endpoint, or nil if there is none
+	FindLatest(versionEndpointId uuid.UUID) (*models.DeploymentTask, error)
+	// Finish updates the final status and error of a task, if it's still owned by the task's owner
+	Finish(task *models.DeploymentTask) error
+}
+
+type deploymentTaskStorage struct {
+	db *gorm.DB
+}
+
+func NewDeploymentTaskStorage(db *gorm.DB) DeploymentTaskStorage {
+	return &deploymentTaskStorage{db: db}
+}
+
+func (s *deploymentTaskStorage) Save(task *models.DeploymentTask) error {
+	return s.db.Save(task).Error
+}
+
+func (s *deploymentTaskStorage) Claim(owner string, now time.Time, lease time.Duration) (*models.DeploymentTask, error) {
+	task := &models.DeploymentTask{}
+	err := s.db.Raw(claimDeploymentTaskQuery, owner, now.Add(lease), now, now).Scan(task).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (s *deploymentTaskStorage) Heartbeat(id models.Id, owner string, leaseExpiresAt time.Time) (bool, error) {
+	result := s.db.Model(&models.DeploymentTask{}).
+		Where("id = ? AND owner = ? AND status = ?", id, owner, models.TaskRunning).
+		Update("lease_expires_at", leaseExpiresAt)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (s *deploymentTaskStorage) Finish(task *models.DeploymentTask) error {
+	return s.db.Model(&models.DeploymentTask{}).
+		Where("id = ? AND owner = ?", task.Id, task.Owner).
+		Updates(map[string]interface{}{
+			"status": task.Status,
+			"error":  task.Error,
+		}).Error
+}
+
+func (s *deploymentTaskStorage) FindLatest(versionEndpointId uuid.UUID) (*models.DeploymentTask, error) {
+	task := &models.DeploymentTask{}
+	err := s.db.Where("version_endpoint_id = ?", versionEndpointId.String()).Order("id DESC").First(task).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
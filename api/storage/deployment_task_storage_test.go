@@ -0,0 +1,112 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration_local || integration
+// +build integration_local integration
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojek/merlin/it/database"
+	"github.com/gojek/merlin/models"
+)
+
+func Test_deploymentTaskStorage_Claim(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		versionEndpoints := populateVersionEndpointTable(db)
+		taskStorage := NewDeploymentTaskStorage(db)
+
+		deploy := &models.DeploymentTask{
+			Type:              models.DeployVersionEndpointTask,
+			VersionEndpointId: &versionEndpoints[0].Id,
+			Payload:           &models.DeploymentTaskPayload{},
+			Status:            models.TaskPending,
+			MaxAttempts:       3,
+		}
+		undeploy := &models.DeploymentTask{
+			Type:              models.UndeployVersionEndpointTask,
+			VersionEndpointId: &versionEndpoints[0].Id,
+			Payload:           &models.DeploymentTaskPayload{},
+			Status:            models.TaskPending,
+			MaxAttempts:       3,
+		}
+		assert.NoError(t, taskStorage.Save(deploy))
+		assert.NoError(t, taskStorage.Save(undeploy))
+
+		now := time.Now()
+		claimed, err := taskStorage.Claim("owner-1", now, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, deploy.Id, claimed.Id)
+		assert.Equal(t, models.TaskRunning, claimed.Status)
+		assert.Equal(t, "owner-1", claimed.Owner)
+		assert.Equal(t, 1, claimed.Attempts)
+
+		// undeploy waits until the deploy of the same version endpoint is finished
+		claimed, err = taskStorage.Claim("owner-2", now, time.Minute)
+		assert.NoError(t, err)
+		assert.Nil(t, claimed)
+
+		// the lease of owner-1 has expired, so the deploy is claimed again
+		claimed, err = taskStorage.Claim("owner-2", now.Add(2*time.Minute), time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, deploy.Id, claimed.Id)
+		assert.Equal(t, "owner-2", claimed.Owner)
+		assert.Equal(t, 2, claimed.Attempts)
+
+		owned, err := taskStorage.Heartbeat(deploy.Id, "owner-1", now.Add(3*time.Minute))
+		assert.NoError(t, err)
+		assert.False(t, owned)
+
+		owned, err = taskStorage.Heartbeat(deploy.Id, "owner-2", now.Add(3*time.Minute))
+		assert.NoError(t, err)
+		assert.True(t, owned)
+
+		claimed.Status = models.TaskSucceeded
+		assert.NoError(t, taskStorage.Finish(claimed))
+
+		claimed, err = taskStorage.Claim("owner-2", now.Add(2*time.Minute), time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, undeploy.Id, claimed.Id)
+	})
+}
+
+func Test_versionEndpointStorage_ListOrphanedPending(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		versionEndpoints := populateVersionEndpointTable(db)
+		taskStorage := NewDeploymentTaskStorage(db)
+		endpointStorage := NewVersionEndpointStorage(db)
+
+		assert.NoError(t, taskStorage.Save(&models.DeploymentTask{
+			Type:              models.DeployVersionEndpointTask,
+			VersionEndpointId: &versionEndpoints[0].Id,
+			Payload:           &models.DeploymentTaskPayload{},
+			Status:            models.TaskPending,
+		}))
+
+		orphans, err := endpointStorage.ListOrphanedPending(time.Now().Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Len(t, orphans, 1)
+		assert.Equal(t, versionEndpoints[2].Id, orphans[0].Id)
+
+		orphans, err = endpointStorage.ListOrphanedPending(time.Now().Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Len(t, orphans, 0)
+	})
+}
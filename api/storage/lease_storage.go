@@ -0,0 +1,46 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+const acquireLeaseQuery = `
+INSERT INTO leases (name, owner, expires_at, updated_at) VALUES (?, ?, ?, ?)
+ON CONFLICT (name) DO UPDATE
+SET owner = EXCLUDED.owner, expires_at = EXCLUDED.expires_at, updated_at = EXCLUDED.updated_at
+WHERE leases.owner = EXCLUDED.owner OR leases.expires_at < ?`
+
+type LeaseStorage interface {
+	// Acquire acquires the lease with given name for the owner, or extends it if the owner already holds it.
+	// It returns false if the lease is held by another owner and hasn't expired.
+	Acquire(name string, owner string, now time.Time, duration time.Duration) (bool, error)
+}
+
+type leaseStorage struct {
+	db *gorm.DB
+}
+
+func NewLeaseStorage(db *gorm.DB) LeaseStorage {
+	return &leaseStorage{db: db}
+}
+
+func (s *leaseStorage) Acquire(name string, owner string, now time.Time, duration time.Duration) (bool, error) {
+	result := s.db.Exec(acquireLeaseQuery, name, owner, now.Add(duration), now, now)
+	return result.RowsAffected > 0, result.Error
+}
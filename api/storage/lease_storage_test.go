@@ -0,0 +1,62 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration_local || integration
+// +build integration_local integration
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojek/merlin/it/database"
+)
+
+func Test_leaseStorage_Acquire(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		leaseStorage := NewLeaseStorage(db)
+		now := time.Now()
+
+		acquired, err := leaseStorage.Acquire("leader", "owner-1", now, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+
+		// the lease of owner-1 hasn't expired yet
+		acquired, err = leaseStorage.Acquire("leader", "owner-2", now.Add(30*time.Second), time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, acquired)
+
+		// owner-1 extends its lease
+		acquired, err = leaseStorage.Acquire("leader", "owner-1", now.Add(30*time.Second), time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+
+		acquired, err = leaseStorage.Acquire("leader", "owner-2", now.Add(time.Minute), time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, acquired)
+
+		// the lease of owner-1 has expired, so owner-2 takes it over
+		acquired, err = leaseStorage.Acquire("leader", "owner-2", now.Add(2*time.Minute), time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+
+		acquired, err = leaseStorage.Acquire("another-lease", "owner-1", now, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, acquired)
+	})
+}
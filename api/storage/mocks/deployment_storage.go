@@ -4,6 +4,9 @@ package mocks
 
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/gojek/merlin/models"
+import storage "github.com/gojek/merlin/storage"
+import time "time"
+import uuid "github.com/google/uuid"
 
 // DeploymentStorage is an autogenerated mock type for the DeploymentStorage type
 type DeploymentStorage struct {
@@ -33,6 +36,75 @@ func (_m *DeploymentStorage) GetFirstSuccessModelVersionPerModel() (map[models.I
 	return r0, r1
 }
 
+// GetLastSucceededRevision provides a mock function with given fields: versionEndpointId
+func (_m *DeploymentStorage) GetLastSucceededRevision(versionEndpointId uuid.UUID) (*models.Deployment, error) {
+	ret := _m.Called(versionEndpointId)
+
+	var r0 *models.Deployment
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.Deployment); ok {
+		r0 = rf(versionEndpointId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Deployment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(versionEndpointId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRevision provides a mock function with given fields: versionEndpointId, revision
+func (_m *DeploymentStorage) GetRevision(versionEndpointId uuid.UUID, revision int) (*models.Deployment, error) {
+	ret := _m.Called(versionEndpointId, revision)
+
+	var r0 *models.Deployment
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) *models.Deployment); ok {
+		r0 = rf(versionEndpointId, revision)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Deployment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(versionEndpointId, revision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: filter
+func (_m *DeploymentStorage) List(filter storage.DeploymentFilter) ([]*models.Deployment, error) {
+	ret := _m.Called(filter)
+
+	var r0 []*models.Deployment
+	if rf, ok := ret.Get(0).(func(storage.DeploymentFilter) []*models.Deployment); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Deployment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(storage.DeploymentFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListInModel provides a mock function with given fields: model
 func (_m *DeploymentStorage) ListInModel(model *models.Model) ([]*models.Deployment, error) {
 	ret := _m.Called(model)
@@ -56,6 +128,29 @@ func (_m *DeploymentStorage) ListInModel(model *models.Model) ([]*models.Deploym
 	return r0, r1
 }
 
+// ListRevisions provides a mock function with given fields: versionEndpointId
+func (_m *DeploymentStorage) ListRevisions(versionEndpointId uuid.UUID) ([]*models.Deployment, error) {
+	ret := _m.Called(versionEndpointId)
+
+	var r0 []*models.Deployment
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []*models.Deployment); ok {
+		r0 = rf(versionEndpointId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Deployment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(versionEndpointId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: deployment
 func (_m *DeploymentStorage) Save(deployment *models.Deployment) (*models.Deployment, error) {
 	ret := _m.Called(deployment)
@@ -78,3 +173,70 @@ func (_m *DeploymentStorage) Save(deployment *models.Deployment) (*models.Deploy
 
 	return r0, r1
 }
+
+// Stats provides a mock function with given fields: filter
+func (_m *DeploymentStorage) Stats(filter storage.DeploymentFilter) (*models.DeploymentStats, error) {
+	ret := _m.Called(filter)
+
+	var r0 *models.DeploymentStats
+	if rf, ok := ret.Get(0).(func(storage.DeploymentFilter) *models.DeploymentStats); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DeploymentStats)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(storage.DeploymentFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FailOrphanedPending provides a mock function with given fields: updatedBefore, message
+func (_m *DeploymentStorage) FailOrphanedPending(updatedBefore time.Time, message string) (int64, error) {
+	ret := _m.Called(updatedBefore, message)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(time.Time, string) int64); ok {
+		r0 = rf(updatedBefore, message)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time, string) error); ok {
+		r1 = rf(updatedBefore, message)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListLastSucceededBefore provides a mock function with given fields: projectId, before
+func (_m *DeploymentStorage) ListLastSucceededBefore(projectId models.Id, before time.Time) ([]*models.Deployment, error) {
+	ret := _m.Called(projectId, before)
+
+	var r0 []*models.Deployment
+	if rf, ok := ret.Get(0).(func(models.Id, time.Time) []*models.Deployment); ok {
+		r0 = rf(projectId, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Deployment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Id, time.Time) error); ok {
+		r1 = rf(projectId, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
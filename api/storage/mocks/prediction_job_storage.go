@@ -4,6 +4,7 @@ package mocks
 
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/gojek/merlin/models"
+import time "time"
 
 // PredictionJobStorage is an autogenerated mock type for the PredictionJobStorage type
 type PredictionJobStorage struct {
@@ -79,6 +80,52 @@ func (_m *PredictionJobStorage) List(query *models.PredictionJob) ([]*models.Pre
 	return r0, r1
 }
 
+// ListOrphanedPending provides a mock function with given fields: updatedBefore
+func (_m *PredictionJobStorage) ListOrphanedPending(updatedBefore time.Time) ([]*models.PredictionJob, error) {
+	ret := _m.Called(updatedBefore)
+
+	var r0 []*models.PredictionJob
+	if rf, ok := ret.Get(0).(func(time.Time) []*models.PredictionJob); ok {
+		r0 = rf(updatedBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PredictionJob)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(updatedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListRunWithin provides a mock function with given fields: projectId, start, end
+func (_m *PredictionJobStorage) ListRunWithin(projectId models.Id, start time.Time, end time.Time) ([]*models.PredictionJob, error) {
+	ret := _m.Called(projectId, start, end)
+
+	var r0 []*models.PredictionJob
+	if rf, ok := ret.Get(0).(func(models.Id, time.Time, time.Time) []*models.PredictionJob); ok {
+		r0 = rf(projectId, start, end)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PredictionJob)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(models.Id, time.Time, time.Time) error); ok {
+		r1 = rf(projectId, start, end)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: predictionJob
 func (_m *PredictionJobStorage) Save(predictionJob *models.PredictionJob) error {
 	ret := _m.Called(predictionJob)
@@ -4,7 +4,7 @@ package mocks
 
 import mock "github.com/stretchr/testify/mock"
 import models "github.com/gojek/merlin/models"
-
+import time "time"
 import uuid "github.com/google/uuid"
 
 // VersionEndpointStorage is an autogenerated mock type for the VersionEndpointStorage type
@@ -12,20 +12,22 @@ type VersionEndpointStorage struct {
 	mock.Mock
 }
 
-// CountEndpoints provides a mock function with given fields: environment, model
-func (_m *VersionEndpointStorage) CountEndpoints(environment *models.Environment, model *models.Model) (int, error) {
-	ret := _m.Called(environment, model)
+// Get provides a mock function with given fields: _a0
+func (_m *VersionEndpointStorage) Get(_a0 uuid.UUID) (*models.VersionEndpoint, error) {
+	ret := _m.Called(_a0)
 
-	var r0 int
-	if rf, ok := ret.Get(0).(func(*models.Environment, *models.Model) int); ok {
-		r0 = rf(environment, model)
+	var r0 *models.VersionEndpoint
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.VersionEndpoint); ok {
+		r0 = rf(_a0)
 	} else {
-		r0 = ret.Get(0).(int)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.VersionEndpoint)
+		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*models.Environment, *models.Model) error); ok {
-		r1 = rf(environment, model)
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -33,13 +35,13 @@ func (_m *VersionEndpointStorage) CountEndpoints(environment *models.Environment
 	return r0, r1
 }
 
-// Get provides a mock function with given fields: _a0
-func (_m *VersionEndpointStorage) Get(_a0 uuid.UUID) (*models.VersionEndpoint, error) {
-	ret := _m.Called(_a0)
+// GetByInferenceService provides a mock function with given fields: environmentName, namespace, inferenceServiceName
+func (_m *VersionEndpointStorage) GetByInferenceService(environmentName string, namespace string, inferenceServiceName string) (*models.VersionEndpoint, error) {
+	ret := _m.Called(environmentName, namespace, inferenceServiceName)
 
 	var r0 *models.VersionEndpoint
-	if rf, ok := ret.Get(0).(func(uuid.UUID) *models.VersionEndpoint); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(string, string, string) *models.VersionEndpoint); ok {
+		r0 = rf(environmentName, namespace, inferenceServiceName)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.VersionEndpoint)
@@ -47,8 +49,31 @@ func (_m *VersionEndpointStorage) Get(_a0 uuid.UUID) (*models.VersionEndpoint, e
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(environmentName, namespace, inferenceServiceName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListByIds provides a mock function with given fields: ids
+func (_m *VersionEndpointStorage) ListByIds(ids []uuid.UUID) ([]*models.VersionEndpoint, error) {
+	ret := _m.Called(ids)
+
+	var r0 []*models.VersionEndpoint
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []*models.VersionEndpoint); ok {
+		r0 = rf(ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.VersionEndpoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(ids)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -79,6 +104,52 @@ func (_m *VersionEndpointStorage) ListEndpoints(model *models.Model, version *mo
 	return r0, r1
 }
 
+// ListOrphanedPending provides a mock function with given fields: updatedBefore
+func (_m *VersionEndpointStorage) ListOrphanedPending(updatedBefore time.Time) ([]*models.VersionEndpoint, error) {
+	ret := _m.Called(updatedBefore)
+
+	var r0 []*models.VersionEndpoint
+	if rf, ok := ret.Get(0).(func(time.Time) []*models.VersionEndpoint); ok {
+		r0 = rf(updatedBefore)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.VersionEndpoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(updatedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWithLifetime provides a mock function with given fields:
+func (_m *VersionEndpointStorage) ListWithLifetime() ([]*models.VersionEndpoint, error) {
+	ret := _m.Called()
+
+	var r0 []*models.VersionEndpoint
+	if rf, ok := ret.Get(0).(func() []*models.VersionEndpoint); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.VersionEndpoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: endpoint
 func (_m *VersionEndpointStorage) Save(endpoint *models.VersionEndpoint) error {
 	ret := _m.Called(endpoint)
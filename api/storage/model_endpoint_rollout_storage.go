@@ -0,0 +1,89 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"github.com/jinzhu/gorm"
+
+	"github.com/gojek/merlin/models"
+)
+
+type ModelEndpointRolloutStorage interface {
+	// List returns all rollouts of a model endpoint, latest first
+	List(modelEndpointId models.Id) ([]*models.ModelEndpointRollout, error)
+	// Get returns the rollout with given ID
+	Get(id models.Id) (*models.ModelEndpointRollout, error)
+	// ListProgressing returns all rollouts which are still shifting traffic
+	ListProgressing() ([]*models.ModelEndpointRollout, error)
+	// Save saves the rollout to underlying storage
+	Save(rollout *models.ModelEndpointRollout) error
+	// Claim saves the status, step and message of the rollout only if it's still progressing at fromStep, so that a
+	// step, a promotion or a rollback isn't applied over a concurrent change, e.g. a manual rollback on another
+	// replica of merlin. It returns true if the rollout was claimed.
+	Claim(rollout *models.ModelEndpointRollout, fromStep int) (bool, error)
+}
+
+type modelEndpointRolloutStorage struct {
+	db *gorm.DB
+}
+
+func NewModelEndpointRolloutStorage(db *gorm.DB) ModelEndpointRolloutStorage {
+	return &modelEndpointRolloutStorage{db: db}
+}
+
+func (s *modelEndpointRolloutStorage) List(modelEndpointId models.Id) (rollouts []*models.ModelEndpointRollout, err error) {
+	err = s.db.
+		Where("model_endpoint_id = ?", modelEndpointId).
+		Order("id desc").
+		Find(&rollouts).
+		Error
+	return
+}
+
+func (s *modelEndpointRolloutStorage) Get(id models.Id) (*models.ModelEndpointRollout, error) {
+	var rollout models.ModelEndpointRollout
+	if err := s.db.Where("id = ?", id).First(&rollout).Error; err != nil {
+		return nil, err
+	}
+	return &rollout, nil
+}
+
+func (s *modelEndpointRolloutStorage) ListProgressing() (rollouts []*models.ModelEndpointRollout, err error) {
+	err = s.db.
+		Where("status = ?", models.RolloutProgressing).
+		Order("id").
+		Find(&rollouts).
+		Error
+	return
+}
+
+func (s *modelEndpointRolloutStorage) Save(rollout *models.ModelEndpointRollout) error {
+	return s.db.Save(rollout).Error
+}
+
+func (s *modelEndpointRolloutStorage) Claim(rollout *models.ModelEndpointRollout, fromStep int) (bool, error) {
+	result := s.db.Model(rollout).
+		Where("status = ? AND current_step = ?", models.RolloutProgressing, fromStep).
+		Updates(map[string]interface{}{
+			"status":       rollout.Status,
+			"current_step": rollout.CurrentStep,
+			"message":      rollout.Message,
+			"next_step_at": rollout.NextStepAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
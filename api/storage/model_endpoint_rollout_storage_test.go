@@ -0,0 +1,153 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration_local || integration
+// +build integration_local integration
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojek/merlin/it/database"
+	"github.com/gojek/merlin/models"
+)
+
+func populateModelEndpointRolloutTable(db *gorm.DB) []*models.ModelEndpointRollout {
+	versionEndpoints := populateVersionEndpointTable(db)
+
+	modelEndpoint := &models.ModelEndpoint{
+		Id:              1,
+		ModelId:         versionEndpoints[0].VersionModelId,
+		Status:          models.EndpointServing,
+		EnvironmentName: versionEndpoints[0].EnvironmentName,
+	}
+	db.Create(modelEndpoint)
+
+	previousRule := &models.ModelEndpointRule{
+		Destination: []*models.ModelEndpointRuleDestination{
+			{VersionEndpointID: versionEndpoints[1].Id, Weight: 100},
+		},
+	}
+
+	rollouts := []*models.ModelEndpointRollout{
+		{
+			ModelId:           modelEndpoint.ModelId,
+			ModelEndpointId:   modelEndpoint.Id,
+			VersionEndpointId: versionEndpoints[0].Id,
+			PreviousRule:      previousRule,
+			Steps:             models.RolloutSteps{10, 100},
+			StepInterval:      "10m",
+			CurrentStep:       2,
+			Status:            models.RolloutPromoted,
+		},
+		{
+			ModelId:           modelEndpoint.ModelId,
+			ModelEndpointId:   modelEndpoint.Id,
+			VersionEndpointId: versionEndpoints[0].Id,
+			PreviousRule:      previousRule,
+			Steps:             models.RolloutSteps{10, 50, 100},
+			StepInterval:      "10m",
+			Gates: models.RolloutGates{
+				{MetricType: models.AlertConditionTypeErrorRate, Threshold: 1},
+			},
+			CurrentStep: 1,
+			Status:      models.RolloutProgressing,
+		},
+	}
+	for _, rollout := range rollouts {
+		db.Create(rollout)
+	}
+	return rollouts
+}
+
+func Test_modelEndpointRolloutStorage_List(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		rollouts := populateModelEndpointRolloutTable(db)
+
+		rolloutStorage := NewModelEndpointRolloutStorage(db)
+
+		actual, err := rolloutStorage.List(models.Id(1))
+		assert.NoError(t, err)
+		assert.Len(t, actual, 2)
+		assert.Equal(t, rollouts[1].Id, actual[0].Id)
+		assert.Equal(t, models.RolloutSteps{10, 50, 100}, actual[0].Steps)
+		assert.Equal(t, models.AlertConditionTypeErrorRate, actual[0].Gates[0].MetricType)
+		assert.Equal(t, int32(100), actual[0].PreviousRule.Destination[0].Weight)
+	})
+}
+
+func Test_modelEndpointRolloutStorage_ListProgressing(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		rollouts := populateModelEndpointRolloutTable(db)
+
+		rolloutStorage := NewModelEndpointRolloutStorage(db)
+
+		actual, err := rolloutStorage.ListProgressing()
+		assert.NoError(t, err)
+		assert.Len(t, actual, 1)
+		assert.Equal(t, rollouts[1].Id, actual[0].Id)
+
+		actual[0].Status = models.RolloutRolledBack
+		assert.NoError(t, rolloutStorage.Save(actual[0]))
+
+		actual, err = rolloutStorage.ListProgressing()
+		assert.NoError(t, err)
+		assert.Len(t, actual, 0)
+
+		rollout, err := rolloutStorage.Get(rollouts[1].Id)
+		assert.NoError(t, err)
+		assert.Equal(t, models.RolloutRolledBack, rollout.Status)
+	})
+}
+
+func Test_modelEndpointRolloutStorage_Claim(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		rollouts := populateModelEndpointRolloutTable(db)
+
+		rolloutStorage := NewModelEndpointRolloutStorage(db)
+
+		// the leader moves the rollout to its next step
+		leaderCopy, err := rolloutStorage.Get(rollouts[1].Id)
+		assert.NoError(t, err)
+		leaderCopy.CurrentStep = 2
+		claimed, err := rolloutStorage.Claim(leaderCopy, 1)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		// a rollback from the step read before can't claim the rollout anymore
+		rollbackCopy := *rollouts[1]
+		rollbackCopy.Status = models.RolloutRolledBack
+		claimed, err = rolloutStorage.Claim(&rollbackCopy, 1)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		rollout, err := rolloutStorage.Get(rollouts[1].Id)
+		assert.NoError(t, err)
+		assert.Equal(t, models.RolloutProgressing, rollout.Status)
+		assert.Equal(t, 2, rollout.CurrentStep)
+
+		// finished rollouts can't be claimed
+		rollout.Status = models.RolloutRolledBack
+		claimed, err = rolloutStorage.Claim(rollout, 2)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+		claimed, err = rolloutStorage.Claim(rollout, 2)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+	})
+}
@@ -15,6 +15,8 @@
 package storage
 
 import (
+	"time"
+
 	"github.com/gojek/merlin/models"
 	"github.com/jinzhu/gorm"
 )
@@ -29,6 +31,10 @@ type PredictionJobStorage interface {
 	// GetFirstSuccessModelVersionPerModel get first model version resulting in a successful batch prediction job
 	// GetFirstSuccessModelVersionPerModel get first model version resulting in a successful batch prediction job
 	GetFirstSuccessModelVersionPerModel() (map[models.Id]models.Id, error)
+	// ListOrphanedPending list pending prediction jobs last updated before the given time without any unfinished deployment task
+	ListOrphanedPending(updatedBefore time.Time) ([]*models.PredictionJob, error)
+	// ListRunWithin list prediction jobs which have run within the time range, in all projects if the project id is zero
+	ListRunWithin(projectId models.Id, start, end time.Time) ([]*models.PredictionJob, error)
 }
 
 type predictionJobStorage struct {
@@ -84,6 +90,29 @@ func (p *predictionJobStorage) GetFirstSuccessModelVersionPerModel() (map[models
 	return resultMap, nil
 }
 
+// ListOrphanedPending list pending prediction jobs last updated before the given time without any unfinished deployment task
+func (p *predictionJobStorage) ListOrphanedPending(updatedBefore time.Time) (predictionJobs []*models.PredictionJob, err error) {
+	err = p.query().
+		Where("status = ? AND updated_at < ?", models.JobPending, updatedBefore).
+		Where("NOT EXISTS (SELECT 1 FROM deployment_tasks WHERE deployment_tasks.prediction_job_id = prediction_jobs.id AND deployment_tasks.status IN ('pending', 'running'))").
+		Find(&predictionJobs).Error
+	return
+}
+
+// ListRunWithin list prediction jobs which have run within the time range, in all projects if the project id is zero
+func (p *predictionJobStorage) ListRunWithin(projectId models.Id, start, end time.Time) (predictionJobs []*models.PredictionJob, err error) {
+	running := []models.State{models.JobRunning, models.JobTerminating}
+	finished := []models.State{models.JobCompleted, models.JobFailed, models.JobTerminated}
+	query := p.db.
+		Where("created_at < ?", end).
+		Where("status IN (?) OR (status IN (?) AND updated_at >= ?)", running, finished, start)
+	if projectId != 0 {
+		query = query.Where("project_id = ?", projectId)
+	}
+	err = query.Find(&predictionJobs).Error
+	return
+}
+
 func (p *predictionJobStorage) query() *gorm.DB {
 	return p.db.
 		Preload("Environment")
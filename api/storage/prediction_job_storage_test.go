@@ -332,3 +332,50 @@ func TestPredictionJobStorage_List(t *testing.T) {
 		assert.Len(t, jobs, 2)
 	})
 }
+
+func TestPredictionJobStorage_ListRunWithin(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		endpoints := populateVersionEndpointTable(db)
+		var m models.Model
+		db.First(&m, endpoints[0].VersionModelId)
+
+		start := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+		end := start.Add(24 * time.Hour)
+		newJob := func(status models.State, createdAt, updatedAt time.Time) *models.PredictionJob {
+			job := &models.PredictionJob{
+				Name:            "job",
+				VersionId:       endpoints[0].VersionId,
+				VersionModelId:  m.Id,
+				ProjectId:       m.ProjectId,
+				EnvironmentName: "env1",
+				Status:          status,
+			}
+			job.CreatedAt = createdAt
+			job.UpdatedAt = updatedAt
+			db.Create(job)
+			return job
+		}
+
+		running := newJob(models.JobRunning, start.Add(-48*time.Hour), start.Add(-47*time.Hour))
+		completedWithin := newJob(models.JobCompleted, start.Add(-time.Hour), start.Add(time.Hour))
+		newJob(models.JobCompleted, start.Add(-3*time.Hour), start.Add(-2*time.Hour))
+		newJob(models.JobFailedSubmission, start.Add(time.Hour), start.Add(time.Hour))
+		newJob(models.JobRunning, end.Add(time.Hour), end.Add(time.Hour))
+
+		predJobStore := NewPredictionJobStorage(db)
+		jobs, err := predJobStore.ListRunWithin(m.ProjectId, start, end)
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 2)
+		ids := []models.Id{jobs[0].Id, jobs[1].Id}
+		assert.Contains(t, ids, running.Id)
+		assert.Contains(t, ids, completedWithin.Id)
+
+		jobs, err = predJobStore.ListRunWithin(0, start, end)
+		assert.NoError(t, err)
+		assert.Len(t, jobs, 2)
+
+		jobs, err = predJobStore.ListRunWithin(m.ProjectId+1, start, end)
+		assert.NoError(t, err)
+		assert.Empty(t, jobs)
+	})
+}
@@ -0,0 +1,158 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/gojek/merlin/models"
+)
+
+type ProjectQuotaStorage interface {
+	// List return the quotas of a project in all environments
+	List(projectId models.Id) ([]*models.ProjectQuota, error)
+	// Get return the quota of a project in an environment
+	Get(projectId models.Id, environmentName string) (*models.ProjectQuota, error)
+	// Save save the project quota to underlying storage
+	Save(quota *models.ProjectQuota) (*models.ProjectQuota, error)
+	// Delete delete the quota of a project in an environment
+	Delete(projectId models.Id, environmentName string) error
+	// ListActiveEndpoints return the pending, running and serving version endpoints of a project in an environment
+	ListActiveEndpoints(projectId models.Id, environmentName string) ([]*models.VersionEndpoint, error)
+	// SaveEndpoint save the version endpoint if check accepts the active endpoints of the project in the environment of
+	// the endpoint. The endpoints are listed and the endpoint saved in one transaction holding a lock on the quota of
+	// the project in the environment, so that concurrent deployments can't exceed the quota together.
+	SaveEndpoint(projectId models.Id, endpoint *models.VersionEndpoint, check func(active []*models.VersionEndpoint) error) error
+	// CountActivePredictionJobs return the number of pending and running prediction jobs of a project in an environment
+	CountActivePredictionJobs(projectId models.Id, environmentName string) (int, error)
+	// SavePredictionJob save the prediction job if check accepts the number of active prediction jobs of the project in
+	// the environment of the job. The jobs are counted and the job saved in one transaction holding the same lock as
+	// SaveEndpoint, so that concurrent job creations can't exceed the quota together.
+	SavePredictionJob(projectId models.Id, job *models.PredictionJob, check func(active int) error) error
+}
+
+type projectQuotaStorage struct {
+	db *gorm.DB
+}
+
+func NewProjectQuotaStorage(db *gorm.DB) ProjectQuotaStorage {
+	return &projectQuotaStorage{db: db}
+}
+
+func (p *projectQuotaStorage) List(projectId models.Id) ([]*models.ProjectQuota, error) {
+	var quotas []*models.ProjectQuota
+	err := p.db.Where("project_id = ?", projectId).Order("environment_name").Find(&quotas).Error
+	return quotas, err
+}
+
+func (p *projectQuotaStorage) Get(projectId models.Id, environmentName string) (*models.ProjectQuota, error) {
+	var quota models.ProjectQuota
+	if err := p.db.Where("project_id = ? AND environment_name = ?", projectId, environmentName).First(&quota).Error; err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+func (p *projectQuotaStorage) Save(quota *models.ProjectQuota) (*models.ProjectQuota, error) {
+	if err := p.db.Save(quota).Error; err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+func (p *projectQuotaStorage) Delete(projectId models.Id, environmentName string) error {
+	return p.db.Where("project_id = ? AND environment_name = ?", projectId, environmentName).Delete(&models.ProjectQuota{}).Error
+}
+
+func (p *projectQuotaStorage) ListActiveEndpoints(projectId models.Id, environmentName string) ([]*models.VersionEndpoint, error) {
+	return listActiveEndpoints(p.db, projectId, environmentName)
+}
+
+func (p *projectQuotaStorage) SaveEndpoint(projectId models.Id, endpoint *models.VersionEndpoint, check func(active []*models.VersionEndpoint) error) error {
+	tx := p.db.Begin()
+	defer tx.RollbackUnlessCommitted()
+
+	if err := lockQuota(tx, projectId, endpoint.EnvironmentName); err != nil {
+		return err
+	}
+
+	endpoints, err := listActiveEndpoints(tx, projectId, endpoint.EnvironmentName)
+	if err != nil {
+		return err
+	}
+	if err := check(endpoints); err != nil {
+		return err
+	}
+
+	if err := tx.Save(endpoint).Error; err != nil {
+		return err
+	}
+	return tx.Commit().Error
+}
+
+func listActiveEndpoints(db *gorm.DB, projectId models.Id, environmentName string) ([]*models.VersionEndpoint, error) {
+	var endpoints []*models.VersionEndpoint
+	err := db.
+		Joins("JOIN models on models.id = version_endpoints.version_model_id").
+		Select("version_endpoints.*").
+		Where("models.project_id = ? AND version_endpoints.environment_name = ?", projectId, environmentName).
+		Where("version_endpoints.status IN (?)", []models.EndpointStatus{models.EndpointPending, models.EndpointRunning, models.EndpointServing}).
+		Find(&endpoints).Error
+	return endpoints, err
+}
+
+func (p *projectQuotaStorage) CountActivePredictionJobs(projectId models.Id, environmentName string) (int, error) {
+	return countActivePredictionJobs(p.db, projectId, environmentName)
+}
+
+func (p *projectQuotaStorage) SavePredictionJob(projectId models.Id, job *models.PredictionJob, check func(active int) error) error {
+	tx := p.db.Begin()
+	defer tx.RollbackUnlessCommitted()
+
+	if err := lockQuota(tx, projectId, job.EnvironmentName); err != nil {
+		return err
+	}
+
+	count, err := countActivePredictionJobs(tx, projectId, job.EnvironmentName)
+	if err != nil {
+		return err
+	}
+	if err := check(count); err != nil {
+		return err
+	}
+
+	if err := tx.Save(job).Error; err != nil {
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// lockQuota locks the quota of the project in the environment until the transaction ends
+func lockQuota(tx *gorm.DB, projectId models.Id, environmentName string) error {
+	lockKey := fmt.Sprintf("project_quota/%d/%s", projectId, environmentName)
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", lockKey).Error
+}
+
+func countActivePredictionJobs(db *gorm.DB, projectId models.Id, environmentName string) (int, error) {
+	var count int
+	err := db.
+		Model(&models.PredictionJob{}).
+		Where("project_id = ? AND environment_name = ?", projectId, environmentName).
+		Where("status IN (?)", []models.State{models.JobPending, models.JobRunning}).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration_local || integration
+// +build integration_local integration
+
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojek/merlin/it/database"
+	"github.com/gojek/merlin/models"
+)
+
+func TestProjectQuotaStorage_SaveAndDelete(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		endpoints := populateVersionEndpointTable(db)
+		var model models.Model
+		db.First(&model, endpoints[0].VersionModelId)
+
+		quotaStorage := NewProjectQuotaStorage(db)
+		maxEndpoints := 5
+		quota, err := quotaStorage.Save(&models.ProjectQuota{
+			ProjectId:       model.ProjectId,
+			EnvironmentName: "env1",
+			Limits:          models.QuotaLimits{Endpoints: &maxEndpoints},
+		})
+		assert.NoError(t, err)
+		assert.NotZero(t, quota.Id)
+
+		quota, err = quotaStorage.Get(model.ProjectId, "env1")
+		assert.NoError(t, err)
+		assert.Equal(t, &maxEndpoints, quota.Limits.Endpoints)
+		assert.Nil(t, quota.Limits.Cpu)
+
+		_, err = quotaStorage.Get(model.ProjectId, "env2")
+		assert.True(t, gorm.IsRecordNotFoundError(err))
+
+		quotas, err := quotaStorage.List(model.ProjectId)
+		assert.NoError(t, err)
+		assert.Len(t, quotas, 1)
+
+		assert.NoError(t, quotaStorage.Delete(model.ProjectId, "env1"))
+		_, err = quotaStorage.Get(model.ProjectId, "env1")
+		assert.True(t, gorm.IsRecordNotFoundError(err))
+	})
+}
+
+func TestProjectQuotaStorage_Usage(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		endpoints := populateVersionEndpointTable(db)
+		var model models.Model
+		db.First(&model, endpoints[0].VersionModelId)
+
+		for _, status := range []models.State{models.JobPending, models.JobRunning, models.JobCompleted} {
+			db.Create(&models.PredictionJob{
+				Name:            "job",
+				VersionId:       endpoints[0].VersionId,
+				VersionModelId:  model.Id,
+				ProjectId:       model.ProjectId,
+				EnvironmentName: "env1",
+				Status:          status,
+			})
+		}
+
+		quotaStorage := NewProjectQuotaStorage(db)
+		active, err := quotaStorage.ListActiveEndpoints(model.ProjectId, "env1")
+		assert.NoError(t, err)
+		assert.Len(t, active, 1)
+		assert.Equal(t, endpoints[0].Id, active[0].Id)
+
+		count, err := quotaStorage.CountActivePredictionJobs(model.ProjectId, "env1")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		count, err = quotaStorage.CountActivePredictionJobs(model.ProjectId, "env2")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestProjectQuotaStorage_SaveEndpoint(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		endpoints := populateVersionEndpointTable(db)
+		var model models.Model
+		db.First(&model, endpoints[0].VersionModelId)
+
+		quotaStorage := NewProjectQuotaStorage(db)
+		endpoint := endpoints[0]
+		endpoint.TTL = "24h"
+		err := quotaStorage.SaveEndpoint(model.ProjectId, endpoint, func(active []*models.VersionEndpoint) error {
+			assert.Len(t, active, 1)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		var saved models.VersionEndpoint
+		db.First(&saved, "id = ?", endpoint.Id)
+		assert.Equal(t, "24h", saved.TTL)
+
+		endpoint.TTL = "48h"
+		quotaErr := errors.New("quota exceeded")
+		err = quotaStorage.SaveEndpoint(model.ProjectId, endpoint, func(active []*models.VersionEndpoint) error {
+			return quotaErr
+		})
+		assert.Equal(t, quotaErr, err)
+
+		db.First(&saved, "id = ?", endpoint.Id)
+		assert.Equal(t, "24h", saved.TTL)
+	})
+}
+
+func TestProjectQuotaStorage_SavePredictionJob(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		endpoints := populateVersionEndpointTable(db)
+		var model models.Model
+		db.First(&model, endpoints[0].VersionModelId)
+
+		newJob := func(name string) *models.PredictionJob {
+			return &models.PredictionJob{
+				Name:            name,
+				VersionId:       endpoints[0].VersionId,
+				VersionModelId:  model.Id,
+				ProjectId:       model.ProjectId,
+				EnvironmentName: "env1",
+				Status:          models.JobPending,
+			}
+		}
+
+		quotaStorage := NewProjectQuotaStorage(db)
+		err := quotaStorage.SavePredictionJob(model.ProjectId, newJob("job-1"), func(active int) error {
+			assert.Equal(t, 0, active)
+			return nil
+		})
+		assert.NoError(t, err)
+
+		quotaErr := errors.New("quota exceeded")
+		err = quotaStorage.SavePredictionJob(model.ProjectId, newJob("job-2"), func(active int) error {
+			assert.Equal(t, 1, active)
+			return quotaErr
+		})
+		assert.Equal(t, quotaErr, err)
+
+		count, err := quotaStorage.CountActivePredictionJobs(model.ProjectId, "env1")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
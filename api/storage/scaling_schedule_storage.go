@@ -0,0 +1,91 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+
+	"github.com/gojek/merlin/models"
+)
+
+type ScalingScheduleStorage interface {
+	// List returns all scaling schedules of a version endpoint
+	List(versionEndpointId uuid.UUID) ([]*models.ScalingSchedule, error)
+	// ListAll returns the scaling schedules of all version endpoints
+	ListAll() ([]*models.ScalingSchedule, error)
+	// Get returns the scaling schedule with given ID
+	Get(id models.Id) (*models.ScalingSchedule, error)
+	// Save saves the scaling schedule to underlying storage
+	Save(schedule *models.ScalingSchedule) error
+	// Delete deletes the scaling schedule from underlying storage
+	Delete(schedule *models.ScalingSchedule) error
+	// Claim sets the time at which the scaling schedule was last applied to the time it fired, unless it has already
+	// been applied at that time, e.g. by another replica of merlin. It returns true if the schedule was claimed.
+	Claim(schedule *models.ScalingSchedule, firedAt time.Time) (bool, error)
+}
+
+type scalingScheduleStorage struct {
+	db *gorm.DB
+}
+
+func NewScalingScheduleStorage(db *gorm.DB) ScalingScheduleStorage {
+	return &scalingScheduleStorage{db: db}
+}
+
+func (s *scalingScheduleStorage) List(versionEndpointId uuid.UUID) (schedules []*models.ScalingSchedule, err error) {
+	err = s.db.
+		Where("version_endpoint_id = ?", versionEndpointId).
+		Order("id").
+		Find(&schedules).
+		Error
+	return
+}
+
+func (s *scalingScheduleStorage) ListAll() (schedules []*models.ScalingSchedule, err error) {
+	err = s.db.
+		Order("id").
+		Find(&schedules).
+		Error
+	return
+}
+
+func (s *scalingScheduleStorage) Get(id models.Id) (*models.ScalingSchedule, error) {
+	var schedule models.ScalingSchedule
+	if err := s.db.Where("id = ?", id).First(&schedule).Error; err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+func (s *scalingScheduleStorage) Save(schedule *models.ScalingSchedule) error {
+	return s.db.Save(schedule).Error
+}
+
+func (s *scalingScheduleStorage) Delete(schedule *models.ScalingSchedule) error {
+	return s.db.Delete(schedule).Error
+}
+
+func (s *scalingScheduleStorage) Claim(schedule *models.ScalingSchedule, firedAt time.Time) (bool, error) {
+	result := s.db.Model(schedule).
+		Where("last_applied_at IS NULL OR last_applied_at < ?", firedAt).
+		UpdateColumn("last_applied_at", firedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
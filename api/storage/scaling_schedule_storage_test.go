@@ -0,0 +1,135 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration_local || integration
+// +build integration_local integration
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojek/merlin/it/database"
+	"github.com/gojek/merlin/models"
+)
+
+func populateScalingScheduleTable(db *gorm.DB) []*models.ScalingSchedule {
+	versionEndpoints := populateVersionEndpointTable(db)
+
+	schedules := []*models.ScalingSchedule{
+		{
+			VersionEndpointId: versionEndpoints[0].Id,
+			Schedule:          "0 7 * * 1-5",
+			Timezone:          "Asia/Jakarta",
+			MinReplica:        4,
+			MaxReplica:        10,
+		},
+		{
+			VersionEndpointId: versionEndpoints[0].Id,
+			Schedule:          "0 22 * * *",
+			Timezone:          "Asia/Jakarta",
+			MinReplica:        1,
+			MaxReplica:        2,
+		},
+		{
+			VersionEndpointId: versionEndpoints[1].Id,
+			Schedule:          "@daily",
+			MinReplica:        0,
+			MaxReplica:        1,
+		},
+	}
+	for _, schedule := range schedules {
+		db.Create(schedule)
+	}
+	return schedules
+}
+
+func Test_scalingScheduleStorage_List(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		schedules := populateScalingScheduleTable(db)
+
+		scheduleStorage := NewScalingScheduleStorage(db)
+
+		actual, err := scheduleStorage.List(schedules[0].VersionEndpointId)
+		assert.NoError(t, err)
+		assert.Len(t, actual, 2)
+		assert.Equal(t, schedules[0].Id, actual[0].Id)
+		assert.Equal(t, "Asia/Jakarta", actual[0].Timezone)
+
+		actual, err = scheduleStorage.ListAll()
+		assert.NoError(t, err)
+		assert.Len(t, actual, 3)
+	})
+}
+
+func Test_scalingScheduleStorage_SaveAndDelete(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		schedules := populateScalingScheduleTable(db)
+
+		scheduleStorage := NewScalingScheduleStorage(db)
+
+		schedule, err := scheduleStorage.Get(schedules[1].Id)
+		assert.NoError(t, err)
+		assert.Nil(t, schedule.LastAppliedAt)
+
+		appliedAt := schedule.CreatedAt
+		schedule.LastAppliedAt = &appliedAt
+		assert.NoError(t, scheduleStorage.Save(schedule))
+
+		schedule, err = scheduleStorage.Get(schedules[1].Id)
+		assert.NoError(t, err)
+		assert.NotNil(t, schedule.LastAppliedAt)
+
+		assert.NoError(t, scheduleStorage.Delete(schedule))
+
+		_, err = scheduleStorage.Get(schedules[1].Id)
+		assert.Error(t, err)
+	})
+}
+
+func Test_scalingScheduleStorage_Claim(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		schedules := populateScalingScheduleTable(db)
+
+		scheduleStorage := NewScalingScheduleStorage(db)
+		firedAt := time.Date(2020, 10, 5, 0, 0, 0, 0, time.UTC)
+
+		schedule, err := scheduleStorage.Get(schedules[0].Id)
+		assert.NoError(t, err)
+		claimed, err := scheduleStorage.Claim(schedule, firedAt)
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		// another replica can't claim the schedule for the same time
+		schedule, err = scheduleStorage.Get(schedules[0].Id)
+		assert.NoError(t, err)
+		assert.True(t, firedAt.Equal(*schedule.LastAppliedAt))
+		claimed, err = scheduleStorage.Claim(schedule, firedAt)
+		assert.NoError(t, err)
+		assert.False(t, claimed)
+
+		claimed, err = scheduleStorage.Claim(schedule, firedAt.Add(24*time.Hour))
+		assert.NoError(t, err)
+		assert.True(t, claimed)
+
+		// the other schedules are left as is
+		schedule, err = scheduleStorage.Get(schedules[1].Id)
+		assert.NoError(t, err)
+		assert.Nil(t, schedule.LastAppliedAt)
+	})
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"github.com/google/uuid"
+	"github.com/jinzhu/gorm"
+
+	"github.com/gojek/merlin/models"
+)
+
+type VersionEndpointEventStorage interface {
+	// ListEvents lists events of a version endpoint, the most recent first
+	ListEvents(versionEndpointId uuid.UUID) ([]*models.VersionEndpointEvent, error)
+	// Save saves the event to underlying storage
+	Save(event *models.VersionEndpointEvent) error
+}
+
+type versionEndpointEventStorage struct {
+	db *gorm.DB
+}
+
+func NewVersionEndpointEventStorage(db *gorm.DB) VersionEndpointEventStorage {
+	return &versionEndpointEventStorage{db: db}
+}
+
+func (s *versionEndpointEventStorage) ListEvents(versionEndpointId uuid.UUID) (events []*models.VersionEndpointEvent, err error) {
+	err = s.db.Where("version_endpoint_id = ?", versionEndpointId.String()).
+		Order("created_at DESC, id DESC").
+		Find(&events).Error
+	return
+}
+
+func (s *versionEndpointEventStorage) Save(event *models.VersionEndpointEvent) error {
+	return s.db.Save(event).Error
+}
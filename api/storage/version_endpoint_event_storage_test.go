@@ -0,0 +1,49 @@
+// Copyright 2020 The Merlin Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build integration_local || integration
+// +build integration_local integration
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojek/merlin/it/database"
+	"github.com/gojek/merlin/models"
+)
+
+func TestVersionEndpointEventStorage_ListEvents(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		endpoints := populateVersionEndpointTable(db)
+		eventStorage := NewVersionEndpointEventStorage(db)
+
+		deleted := models.NewVersionEndpointEvent(endpoints[0], models.EventTypeWarning, models.EventReasonInferenceServiceDeleted, "inference service was deleted")
+		reapplied := models.NewVersionEndpointEvent(endpoints[0], models.EventTypeNormal, models.EventReasonReapplied, "re-applying")
+		other := models.NewVersionEndpointEvent(endpoints[1], models.EventTypeWarning, models.EventReasonInferenceServiceNotReady, "not ready")
+		for _, event := range []*models.VersionEndpointEvent{deleted, reapplied, other} {
+			assert.NoError(t, eventStorage.Save(event))
+		}
+
+		events, err := eventStorage.ListEvents(endpoints[0].Id)
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, reapplied.Id, events[0].Id)
+		assert.Equal(t, deleted.Id, events[1].Id)
+		assert.Equal(t, models.EventTypeWarning, events[1].Type)
+	})
+}
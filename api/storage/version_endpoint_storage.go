@@ -15,6 +15,8 @@
 package storage
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jinzhu/gorm"
 
@@ -24,8 +26,15 @@ import (
 type VersionEndpointStorage interface {
 	ListEndpoints(model *models.Model, version *models.Version) (endpoints []*models.VersionEndpoint, err error)
 	Get(uuid.UUID) (*models.VersionEndpoint, error)
+	// ListByIds returns the endpoints with the given ids
+	ListByIds(ids []uuid.UUID) ([]*models.VersionEndpoint, error)
+	// GetByInferenceService returns the endpoint deployed as the given inference service in the environment
+	GetByInferenceService(environmentName, namespace, inferenceServiceName string) (*models.VersionEndpoint, error)
 	Save(endpoint *models.VersionEndpoint) error
-	CountEndpoints(environment *models.Environment, model *models.Model) (int, error)
+	// ListOrphanedPending returns pending endpoints last updated before the given time without any unfinished deployment task
+	ListOrphanedPending(updatedBefore time.Time) ([]*models.VersionEndpoint, error)
+	// ListWithLifetime returns running and serving endpoints with a ttl or an idle timeout
+	ListWithLifetime() ([]*models.VersionEndpoint, error)
 }
 
 type versionEndpointStorage struct {
@@ -49,17 +58,43 @@ func (v *versionEndpointStorage) Get(uuid uuid.UUID) (*models.VersionEndpoint, e
 	return ve, nil
 }
 
+func (v *versionEndpointStorage) ListByIds(ids []uuid.UUID) (endpoints []*models.VersionEndpoint, err error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	err = v.query().Where("version_endpoints.id IN (?)", ids).Find(&endpoints).Error
+	return
+}
+
+func (v *versionEndpointStorage) GetByInferenceService(environmentName, namespace, inferenceServiceName string) (*models.VersionEndpoint, error) {
+	ve := &models.VersionEndpoint{}
+	err := v.query().
+		Where("version_endpoints.environment_name = ? AND version_endpoints.namespace = ? AND version_endpoints.inference_service_name = ?", environmentName, namespace, inferenceServiceName).
+		First(&ve).Error
+	if err != nil {
+		return nil, err
+	}
+	return ve, nil
+}
+
 func (v *versionEndpointStorage) Save(endpoint *models.VersionEndpoint) error {
 	return v.db.Save(&endpoint).Error
 }
 
-func (v *versionEndpointStorage) CountEndpoints(environment *models.Environment, model *models.Model) (int, error) {
-	var count int
-	err := v.query().
-		Model(&models.VersionEndpoint{}).
-		Where("version_endpoints.environment_name = ? AND version_endpoints.version_model_id = ? AND version_endpoints.status IN ('pending', 'running', 'serving')", environment.Name, model.Id).
-		Count(&count).Error
-	return count, err
+func (v *versionEndpointStorage) ListOrphanedPending(updatedBefore time.Time) (endpoints []*models.VersionEndpoint, err error) {
+	err = v.query().
+		Where("version_endpoints.status = ? AND version_endpoints.updated_at < ?", models.EndpointPending, updatedBefore).
+		Where("NOT EXISTS (SELECT 1 FROM deployment_tasks WHERE deployment_tasks.version_endpoint_id = version_endpoints.id AND deployment_tasks.status IN ('pending', 'running'))").
+		Find(&endpoints).Error
+	return
+}
+
+func (v *versionEndpointStorage) ListWithLifetime() (endpoints []*models.VersionEndpoint, err error) {
+	err = v.query().
+		Where("version_endpoints.status IN (?)", []models.EndpointStatus{models.EndpointRunning, models.EndpointServing}).
+		Where("COALESCE(version_endpoints.ttl, '') <> '' OR COALESCE(version_endpoints.idle_timeout, '') <> ''").
+		Find(&endpoints).Error
+	return
 }
 
 func (v *versionEndpointStorage) query() *gorm.DB {
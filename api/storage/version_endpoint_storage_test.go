@@ -42,6 +42,40 @@ func TestVersionEndpointsStorage_Get(t *testing.T) {
 	})
 }
 
+func TestVersionEndpointsStorage_ListByIds(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		endpoints := populateVersionEndpointTable(db)
+		endpointSvc := NewVersionEndpointStorage(db)
+
+		actual, err := endpointSvc.ListByIds([]uuid.UUID{endpoints[0].Id, endpoints[2].Id})
+		assert.NoError(t, err)
+		assert.Len(t, actual, 2)
+		assert.NotNil(t, actual[0].Environment)
+
+		actual, err = endpointSvc.ListByIds(nil)
+		assert.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+}
+
+func TestVersionEndpointsStorage_GetByInferenceService(t *testing.T) {
+	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
+		endpoints := populateVersionEndpointTable(db)
+		endpoints[2].Namespace = "project"
+		endpoints[2].InferenceServiceName = "model-1"
+		db.Save(endpoints[2])
+
+		endpointSvc := NewVersionEndpointStorage(db)
+
+		actualEndpoint, err := endpointSvc.GetByInferenceService("env2", "project", "model-1")
+		assert.NoError(t, err)
+		assert.Equal(t, endpoints[2].Id, actualEndpoint.Id)
+
+		_, err = endpointSvc.GetByInferenceService("env1", "project", "model-1")
+		assert.True(t, gorm.IsRecordNotFoundError(err))
+	})
+}
+
 func TestVersionEndpointsStorage_ListEndpoints(t *testing.T) {
 	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
 		endpoints := populateVersionEndpointTable(db)
@@ -54,20 +88,26 @@ func TestVersionEndpointsStorage_ListEndpoints(t *testing.T) {
 	})
 }
 
-func TestVersionsService_CountEndpoints(t *testing.T) {
+func TestVersionEndpointsStorage_ListWithLifetime(t *testing.T) {
 	database.WithTestDatabase(t, func(t *testing.T, db *gorm.DB) {
-		populateVersionEndpointTable(db)
+		endpoints := populateVersionEndpointTable(db)
+
+		endpoints[0].Status = models.EndpointRunning
+		endpoints[0].TTL = "72h"
+		db.Save(endpoints[0])
+		endpoints[1].IdleTimeout = "24h"
+		db.Save(endpoints[1])
+		endpoints[2].Status = models.EndpointServing
+		db.Save(endpoints[2])
 
 		endpointSvc := NewVersionEndpointStorage(db)
 
-		count, err := endpointSvc.CountEndpoints(&models.Environment{
-			Name: "env1",
-		}, &models.Model{
-			Id:   1,
-			Name: "model",
-		})
+		actualEndpoints, err := endpointSvc.ListWithLifetime()
 		assert.NoError(t, err)
-		assert.Equal(t, 1, count)
+		assert.Len(t, actualEndpoints, 1)
+		assert.Equal(t, endpoints[0].Id, actualEndpoints[0].Id)
+		assert.Equal(t, "72h", actualEndpoints[0].TTL)
+		assert.Equal(t, "env1", actualEndpoints[0].Environment.Name)
 	})
 }
 
@@ -0,0 +1,37 @@
+-- Copyright 2020 The Merlin Authors
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--      http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TYPE rollout_status as ENUM ('progressing', 'promoted', 'rolled_back', 'failed');
+
+CREATE TABLE IF NOT EXISTS model_endpoint_rollouts
+(
+    id                  serial PRIMARY KEY,
+    model_id            integer REFERENCES models (id) NOT NULL,
+    model_endpoint_id   integer REFERENCES model_endpoints (id) NOT NULL,
+    version_endpoint_id uuid REFERENCES version_endpoints (id) NOT NULL,
+    previous_rule       jsonb,
+    steps               jsonb,
+    step_interval       varchar(32),
+    gates               jsonb,
+    current_step        integer        NOT NULL default 0,
+    status              rollout_status NOT NULL default 'progressing',
+    message             text,
+    next_step_at        timestamp,
+    created_at          timestamp      NOT NULL default current_timestamp,
+    updated_at          timestamp      NOT NULL default current_timestamp
+);
+
+CREATE INDEX model_endpoint_rollouts_idx_1 ON model_endpoint_rollouts (
+    model_endpoint_id, status
+);
@@ -0,0 +1,41 @@
+-- Copyright 2020 The Merlin Authors
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--      http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TYPE deployment_task_type as ENUM ('deploy_version_endpoint', 'undeploy_version_endpoint', 'submit_prediction_job');
+CREATE TYPE deployment_task_status as ENUM ('pending', 'running', 'succeeded', 'failed');
+
+CREATE TABLE IF NOT EXISTS deployment_tasks
+(
+    id                  serial PRIMARY KEY,
+    type                deployment_task_type   NOT NULL,
+    version_endpoint_id uuid,
+    prediction_job_id   integer,
+    payload             jsonb,
+    status              deployment_task_status NOT NULL default 'pending',
+    attempts            integer                NOT NULL default 0,
+    max_attempts        integer                NOT NULL default 3,
+    owner               varchar(128),
+    lease_expires_at    timestamp,
+    error               text,
+    created_at          timestamp              NOT NULL default current_timestamp,
+    updated_at          timestamp              NOT NULL default current_timestamp,
+    CONSTRAINT deployment_tasks_version_endpoint_fkey
+        FOREIGN KEY (version_endpoint_id) REFERENCES version_endpoints (id),
+    CONSTRAINT deployment_tasks_prediction_job_fkey
+        FOREIGN KEY (prediction_job_id) REFERENCES prediction_jobs (id)
+);
+
+CREATE INDEX deployment_tasks_idx_1 ON deployment_tasks (status, lease_expires_at);
+CREATE INDEX deployment_tasks_idx_2 ON deployment_tasks (version_endpoint_id);
+CREATE INDEX deployment_tasks_idx_3 ON deployment_tasks (prediction_job_id);
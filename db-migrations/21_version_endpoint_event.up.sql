@@ -0,0 +1,30 @@
+-- Copyright 2020 The Merlin Authors
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--      http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TYPE version_endpoint_event_type as ENUM ('normal', 'warning');
+
+CREATE TABLE IF NOT EXISTS version_endpoint_events
+(
+    id                  serial PRIMARY KEY,
+    version_endpoint_id uuid                        NOT NULL,
+    type                version_endpoint_event_type NOT NULL default 'normal',
+    reason              varchar(64)                 NOT NULL,
+    message             text,
+    created_at          timestamp                   NOT NULL default current_timestamp,
+    updated_at          timestamp                   NOT NULL default current_timestamp,
+    CONSTRAINT version_endpoint_events_version_endpoint_fkey
+        FOREIGN KEY (version_endpoint_id) REFERENCES version_endpoints (id)
+);
+
+CREATE INDEX version_endpoint_events_idx_1 ON version_endpoint_events (version_endpoint_id, created_at);
@@ -0,0 +1,19 @@
+-- Copyright 2020 The Merlin Authors
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--      http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE deployments ADD COLUMN revision integer NOT NULL DEFAULT 0;
+ALTER TABLE deployments ADD COLUMN spec jsonb;
+
+-- the deployments made before the revisions were recorded keep the revision 0
+CREATE UNIQUE INDEX deployments_version_endpoint_id_revision_idx ON deployments (version_endpoint_id, revision) WHERE revision > 0;
@@ -0,0 +1,24 @@
+-- Copyright 2020 The Merlin Authors
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--      http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE deployments ADD COLUMN environment_name varchar(50);
+ALTER TABLE deployments ADD COLUMN deployed_by varchar(255);
+
+UPDATE deployments
+SET environment_name = version_endpoints.environment_name
+FROM version_endpoints
+WHERE deployments.version_endpoint_id = version_endpoints.id;
+
+CREATE INDEX deployments_project_id_created_at_idx ON deployments (project_id, created_at);
+CREATE INDEX deployments_version_model_id_created_at_idx ON deployments (version_model_id, created_at);
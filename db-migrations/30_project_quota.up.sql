@@ -0,0 +1,28 @@
+-- Copyright 2020 The Merlin Authors
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--      http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TABLE IF NOT EXISTS project_quotas
+(
+    id               serial PRIMARY KEY,
+    project_id       integer     NOT NULL,
+    environment_name varchar(50) NOT NULL,
+    limits           jsonb       NOT NULL,
+    created_at       timestamp   NOT NULL default current_timestamp,
+    updated_at       timestamp   NOT NULL default current_timestamp,
+    CONSTRAINT project_quotas_project_fkey
+        FOREIGN KEY (project_id) REFERENCES projects (id),
+    CONSTRAINT project_quotas_environment_name_fkey
+        FOREIGN KEY (environment_name) REFERENCES environments (name),
+    UNIQUE (project_id, environment_name)
+);
@@ -0,0 +1,30 @@
+-- Copyright 2020 The Merlin Authors
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--      http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+CREATE TABLE IF NOT EXISTS scaling_schedules
+(
+    id                  serial PRIMARY KEY,
+    version_endpoint_id uuid REFERENCES version_endpoints (id) ON DELETE CASCADE NOT NULL,
+    schedule            varchar(128) NOT NULL,
+    timezone            varchar(64),
+    min_replica         integer      NOT NULL,
+    max_replica         integer      NOT NULL,
+    last_applied_at     timestamp,
+    created_at          timestamp    NOT NULL default current_timestamp,
+    updated_at          timestamp    NOT NULL default current_timestamp
+);
+
+CREATE INDEX scaling_schedules_version_endpoint_id_idx ON scaling_schedules (version_endpoint_id);
+
+ALTER TABLE deployments ADD COLUMN reason text;
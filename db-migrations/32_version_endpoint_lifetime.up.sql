@@ -0,0 +1,20 @@
+-- Copyright 2020 The Merlin Authors
+--
+-- Licensed under the Apache License, Version 2.0 (the "License");
+-- you may not use this file except in compliance with the License.
+-- You may obtain a copy of the License at
+--
+--      http://www.apache.org/licenses/LICENSE-2.0
+--
+-- Unless required by applicable law or agreed to in writing, software
+-- distributed under the License is distributed on an "AS IS" BASIS,
+-- WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+-- See the License for the specific language governing permissions and
+-- limitations under the License.
+
+ALTER TABLE environments ADD COLUMN endpoint_lifetime jsonb;
+
+ALTER TABLE version_endpoints ADD COLUMN ttl varchar(32);
+ALTER TABLE version_endpoints ADD COLUMN idle_timeout varchar(32);
+ALTER TABLE version_endpoints ADD COLUMN deployed_at timestamp;
+ALTER TABLE version_endpoints ADD COLUMN expiry_warned_at timestamp;